@@ -0,0 +1,96 @@
+package binding
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	ginbinding "github.com/gin-gonic/gin/binding"
+
+	"github.com/mildsunup/higo/errors"
+)
+
+// shapingValidator 在校验前规整请求结构体的 Gin 校验器
+type shapingValidator struct {
+	next ginbinding.StructValidator
+}
+
+// Validator 包装 Gin 校验器：先按 shape 标签规整，再交给 next 校验（next 为 nil 时仅规整）
+func Validator(next ginbinding.StructValidator) ginbinding.StructValidator {
+	if sv, ok := next.(*shapingValidator); ok {
+		return sv
+	}
+	return &shapingValidator{next: next}
+}
+
+func (v *shapingValidator) ValidateStruct(obj any) error {
+	if err := Shape(obj); err != nil {
+		return err
+	}
+	if v.next == nil {
+		return nil
+	}
+	return v.next.ValidateStruct(obj)
+}
+
+func (v *shapingValidator) Engine() any {
+	if v.next == nil {
+		return nil
+	}
+	return v.next.Engine()
+}
+
+var installOnce sync.Once
+
+// Install 替换 Gin 的全局校验器，使 ShouldBind 系列在校验前按 shape 标签规整；可重复调用。
+// 应在初始化阶段（注册路由前）调用，Bind 首次调用时也会自动安装。
+func Install() {
+	installOnce.Do(func() {
+		ginbinding.Validator = Validator(ginbinding.Validator)
+	})
+}
+
+// Bind 按 Content-Type 绑定 Gin 请求、规整并校验，失败时返回 InvalidArgument 错误
+//
+//	var req ListUsersRequest
+//	if err := binding.Bind(c, &req); err != nil {
+//	    render.JSON(c, nil, err)
+//	    return
+//	}
+func Bind(c *gin.Context, obj any) error {
+	Install()
+	if err := c.ShouldBind(obj); err != nil {
+		return errors.ErrInvalidArgument(err.Error())
+	}
+	return nil
+}
+
+// BindQuery 绑定 Gin 查询参数、规整并校验
+func BindQuery(c *gin.Context, obj any) error {
+	Install()
+	if err := c.ShouldBindQuery(obj); err != nil {
+		return errors.ErrInvalidArgument(err.Error())
+	}
+	return nil
+}
+
+// DecodeJSON 解码 net/http 请求体、规整并使用 Gin 校验器校验，失败时返回 InvalidArgument 错误
+func DecodeJSON(r *http.Request, obj any) error {
+	if err := json.NewDecoder(r.Body).Decode(obj); err != nil {
+		return errors.ErrInvalidArgument("invalid json body: " + err.Error())
+	}
+	validator := ginbinding.Validator
+	if sv, ok := validator.(*shapingValidator); ok {
+		validator = sv.next
+	}
+	if err := Shape(obj); err != nil {
+		return errors.ErrInvalidArgument(err.Error())
+	}
+	if validator != nil {
+		if err := validator.ValidateStruct(obj); err != nil {
+			return errors.ErrInvalidArgument(err.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+// Package binding 在请求绑定与校验之间按声明规整输入。
+//
+// 通过 shape 标签声明去空白、大小写折叠与默认值，处理器收到的即是规整后的数据，
+// 不再在各个控制器里散落 strings.TrimSpace：
+//
+//	type ListUsersRequest struct {
+//	    Keyword  string   `form:"q" shape:"collapse"`
+//	    Email    string   `form:"email" shape:"trim,lower" binding:"omitempty,email"`
+//	    Country  string   `form:"country" shape:"trim,upper,default=CN" binding:"len=2"`
+//	    Tags     []string `form:"tag" shape:"trim,lower"`
+//	    PageSize int      `form:"page_size" shape:"default=20" binding:"max=100"`
+//	}
+//
+// Install 包装 Gin 的全局校验器，使 ShouldBind 系列先规整再校验（binding 标签在规整后的值上生效）；
+// Bind / BindQuery 绑定失败时返回 InvalidArgument 错误，DecodeJSON 用于 net/http 处理器。
+//
+// 默认值在规整后仍为零值时填充，因此无法区分显式传入的零值；需要区分时使用指针字段。
+package binding
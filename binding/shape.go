@@ -0,0 +1,261 @@
+package binding
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TagName 规整规则的结构体标签名
+const TagName = "shape"
+
+type op uint8
+
+const (
+	opTrim op = 1 << iota
+	opCollapse
+	opLower
+	opUpper
+)
+
+// fieldPlan 单个字段的规整规则
+type fieldPlan struct {
+	index  int
+	ops    op
+	def    reflect.Value // 默认值，无效表示未设置
+	nested bool          // 字段（或其元素）为结构体，需递归
+}
+
+type plan struct {
+	fields []fieldPlan
+	err    error
+}
+
+var plans sync.Map // reflect.Type -> *plan
+
+// Shape 按 shape 标签原地规整结构体（需为指针），递归处理嵌套结构体、结构体指针与切片：
+//
+//	trim       去除首尾空白
+//	collapse   首尾去空白并将连续空白压缩为一个空格
+//	lower      转为小写
+//	upper      转为大写
+//	default=V  规整后仍为零值时设为 V（必须是最后一项，V 可包含逗号）
+//
+// 文本规则作用于字符串、字符串指针与字符串切片；默认值支持字符串、整数、浮点、布尔与 time.Duration。
+// 非指针（切片除外）或非结构体参数直接忽略。
+func Shape(obj any) error {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Pointer && v.Kind() != reflect.Slice {
+		return nil
+	}
+	return walk(v)
+}
+
+func walk(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return walk(v.Elem())
+	case reflect.Struct:
+		if !v.CanSet() {
+			return nil
+		}
+		return apply(v)
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			if err := walk(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func apply(v reflect.Value) error {
+	p := planFor(v.Type())
+	if p.err != nil {
+		return p.err
+	}
+	for _, f := range p.fields {
+		fv := v.Field(f.index)
+		if f.ops != 0 {
+			shapeText(fv, f.ops)
+		}
+		if f.def.IsValid() && fv.IsZero() {
+			if fv.Kind() == reflect.Pointer {
+				ptr := reflect.New(fv.Type().Elem())
+				ptr.Elem().Set(f.def)
+				fv.Set(ptr)
+			} else {
+				fv.Set(f.def)
+			}
+		}
+		if f.nested {
+			if err := walk(fv); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func shapeText(v reflect.Value, ops op) {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(transform(v.String(), ops))
+	case reflect.Pointer:
+		if !v.IsNil() {
+			shapeText(v.Elem(), ops)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			shapeText(v.Index(i), ops)
+		}
+	}
+}
+
+func transform(s string, ops op) string {
+	if ops&opCollapse != 0 {
+		s = strings.Join(strings.Fields(s), " ")
+	} else if ops&opTrim != 0 {
+		s = strings.TrimSpace(s)
+	}
+	if ops&opLower != 0 {
+		s = strings.ToLower(s)
+	}
+	if ops&opUpper != 0 {
+		s = strings.ToUpper(s)
+	}
+	return s
+}
+
+func planFor(t reflect.Type) *plan {
+	if p, ok := plans.Load(t); ok {
+		return p.(*plan)
+	}
+	p := buildPlan(t)
+	actual, _ := plans.LoadOrStore(t, p)
+	return actual.(*plan)
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+func buildPlan(t reflect.Type) *plan {
+	p := &plan{}
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag := sf.Tag.Get(TagName)
+		if tag == "-" {
+			continue
+		}
+		f := fieldPlan{index: i, nested: hasStruct(sf.Type)}
+		if tag != "" {
+			if err := parseTag(&f, sf.Type, tag); err != nil {
+				p.err = fmt.Errorf("binding: %s.%s: %w", t.Name(), sf.Name, err)
+				return p
+			}
+		}
+		if f.ops != 0 || f.def.IsValid() || f.nested {
+			p.fields = append(p.fields, f)
+		}
+	}
+	return p
+}
+
+func parseTag(f *fieldPlan, t reflect.Type, tag string) error {
+	for tag = strings.TrimSpace(tag); tag != ""; tag = strings.TrimSpace(tag) {
+		var item string
+		if strings.HasPrefix(tag, "default=") {
+			item, tag = tag, ""
+		} else {
+			item, tag, _ = strings.Cut(tag, ",")
+		}
+		switch name, value, _ := strings.Cut(item, "="); strings.TrimSpace(name) {
+		case "trim":
+			f.ops |= opTrim
+		case "collapse":
+			f.ops |= opCollapse
+		case "lower":
+			f.ops |= opLower
+		case "upper":
+			f.ops |= opUpper
+		case "default":
+			def, err := parseDefault(t, value)
+			if err != nil {
+				return err
+			}
+			f.def = def
+		case "":
+		default:
+			return fmt.Errorf("unknown rule %q", name)
+		}
+	}
+	if f.ops != 0 && !isText(t) {
+		return fmt.Errorf("text rules require a string field, got %s", t)
+	}
+	return nil
+}
+
+// parseDefault 将默认值解析为字段（指针字段为其元素）类型的值
+func parseDefault(t reflect.Type, s string) (reflect.Value, error) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	v := reflect.New(t).Elem()
+	var err error
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(s)
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		if t == reflect.TypeFor[time.Duration]() {
+			var d time.Duration
+			d, err = time.ParseDuration(s)
+			n = int64(d)
+		} else {
+			n, err = strconv.ParseInt(s, 10, t.Bits())
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		n, err = strconv.ParseUint(s, 10, t.Bits())
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		var n float64
+		n, err = strconv.ParseFloat(s, t.Bits())
+		v.SetFloat(n)
+	default:
+		return reflect.Value{}, fmt.Errorf("default not supported for %s", t)
+	}
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("invalid default %q: %w", s, err)
+	}
+	return v, nil
+}
+
+func isText(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.String
+}
+
+// hasStruct 类型（或其指针、切片元素）是否为需要递归的结构体
+func hasStruct(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t != timeType
+}
@@ -0,0 +1,110 @@
+package binding
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mildsunup/higo/errors"
+)
+
+type address struct {
+	City string `json:"city" shape:"collapse"`
+}
+
+type createUser struct {
+	Name     string        `json:"name" form:"name" shape:"collapse" binding:"required"`
+	Email    string        `json:"email" form:"email" shape:"trim, lower"`
+	Country  *string       `json:"country" shape:"trim,upper,default=CN"`
+	Tags     []string      `json:"tags" form:"tag" shape:"trim,lower"`
+	PageSize int           `json:"page_size" form:"page_size" shape:"default=20" binding:"max=100"`
+	Timeout  time.Duration `json:"timeout" shape:"default=1m30s"`
+	Note     string        `json:"note" shape:"default=a,b"`
+	Raw      string        `json:"raw" shape:"-"`
+	Address  *address      `json:"address"`
+	Others   []address     `json:"others"`
+}
+
+func TestShape(t *testing.T) {
+	req := createUser{
+		Name:    "  Ada \t Lovelace ",
+		Email:   " Ada@Example.COM ",
+		Tags:    []string{" Go ", "API"},
+		Raw:     "  keep  ",
+		Address: &address{City: " New   York "},
+		Others:  []address{{City: " a  b "}},
+	}
+	if err := Shape(&req); err != nil {
+		t.Fatal(err)
+	}
+	if req.Name != "Ada Lovelace" || req.Email != "ada@example.com" || req.Raw != "  keep  " {
+		t.Errorf("text = %q %q %q", req.Name, req.Email, req.Raw)
+	}
+	if req.Country == nil || *req.Country != "CN" || req.PageSize != 20 || req.Timeout != 90*time.Second || req.Note != "a,b" {
+		t.Errorf("defaults = %v %d %v %q", req.Country, req.PageSize, req.Timeout, req.Note)
+	}
+	if !slices.Equal(req.Tags, []string{"go", "api"}) || req.Address.City != "New York" || req.Others[0].City != "a b" {
+		t.Errorf("nested = %v %q %q", req.Tags, req.Address.City, req.Others[0].City)
+	}
+
+	cn := " us "
+	req = createUser{Country: &cn, PageSize: 5}
+	_ = Shape(&req)
+	if *req.Country != "US" || req.PageSize != 5 {
+		t.Errorf("explicit = %q %d", *req.Country, req.PageSize)
+	}
+}
+
+func TestShape_InvalidTag(t *testing.T) {
+	var bad struct {
+		N int `shape:"trim"`
+	}
+	if err := Shape(&bad); err == nil || !strings.Contains(err.Error(), "text rules") {
+		t.Errorf("Shape() error = %v", err)
+	}
+	var badDefault struct {
+		N int `shape:"default=x"`
+	}
+	if err := Shape(&badDefault); err == nil {
+		t.Error("Shape() with invalid default should fail")
+	}
+}
+
+func TestBind(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	var got createUser
+	r.POST("/users", func(c *gin.Context) {
+		got = createUser{}
+		if err := Bind(c, &got); err != nil {
+			c.Status(errors.GetHTTPStatus(err))
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	do := func(body string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+	if code := do(`{"name":"  Ada  ","email":" A@B.C "}`); code != http.StatusNoContent || got.Name != "Ada" || got.PageSize != 20 {
+		t.Errorf("code = %d, got = %+v", code, got)
+	}
+	// 规整后为空，required 校验失败
+	if code := do(`{"name":"   "}`); code != http.StatusBadRequest {
+		t.Errorf("blank name code = %d, want 400", code)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":" x ","page_size":500}`))
+	if err := DecodeJSON(req, &createUser{}); !errors.IsCode(err, errors.InvalidArgument) {
+		t.Errorf("DecodeJSON() error = %v", err)
+	}
+}
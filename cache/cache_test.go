@@ -2,8 +2,16 @@ package cache
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/mildsunup/higo/observability"
 )
 
 func TestMemory_GetSet(t *testing.T) {
@@ -263,3 +271,100 @@ func TestSerializer_JSON(t *testing.T) {
 		t.Errorf("expected 'test', got %s", result.Name)
 	}
 }
+
+func TestHotKeyDetector(t *testing.T) {
+	d := NewHotKeyDetector(HotKeyConfig{TopK: 2, Threshold: 50})
+
+	for i := 0; i < 100; i++ {
+		d.Record("hot")
+		if i%10 == 0 {
+			d.Record("warm")
+		}
+	}
+	d.Record("cold")
+
+	if !d.IsHot("hot") {
+		t.Error("expected hot key to be detected")
+	}
+	if d.IsHot("warm") {
+		t.Error("warm key should be below threshold")
+	}
+
+	top := d.TopK()
+	if len(top) != 2 || top[0].Key != "hot" || top[0].Count < 100 {
+		t.Errorf("unexpected top keys: %+v", top)
+	}
+	if hot := d.HotKeys(); len(hot) != 1 || hot[0].Key != "hot" {
+		t.Errorf("unexpected hot keys: %+v", hot)
+	}
+}
+
+func TestHotKeyed_Handler(t *testing.T) {
+	hk := NewHotKeyed(nil, "main", HotKeyConfig{TopK: 4, Threshold: 10})
+	for i := 0; i < 20; i++ {
+		hk.Detector().Record("hot")
+	}
+	hk.Detector().Record("cold")
+
+	get := func(target string) HotKeyReport {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		hk.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s = %d", target, rec.Code)
+		}
+		var report HotKeyReport
+		if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+			t.Fatal(err)
+		}
+		return report
+	}
+
+	if r := get("/"); r.Name != "main" || r.Threshold != 10 || len(r.Keys) != 1 || r.Keys[0].Key != "hot" {
+		t.Fatalf("hot keys = %+v", r)
+	}
+	if r := get("/?all=true"); len(r.Keys) != 2 {
+		t.Fatalf("candidates = %+v", r)
+	}
+
+	rec := httptest.NewRecorder()
+	hk.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("POST = %d", rec.Code)
+	}
+}
+
+func TestHotKeyed_MetricsBounded(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	hk := NewHotKeyed(nil, "main", HotKeyConfig{TopK: 3, Threshold: 1}, WithHotKeyMetrics(observability.NewPrometheusProvider(reg)))
+
+	// 大量不同键轮换成为热点，序列数仍不超过 TopK
+	for i := 0; i < 50; i++ {
+		for j := 0; j < 5; j++ {
+			hk.Detector().Record(fmt.Sprintf("k%d", i))
+		}
+		hk.UpdateHotKeys()
+	}
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var series int
+	for _, f := range families {
+		if f.GetName() != "cache_hot_key_access" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			series++
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "key" {
+					t.Fatal("gauge labelled by key")
+				}
+			}
+		}
+	}
+	if series != 3 {
+		t.Fatalf("series = %d, want 3", series)
+	}
+}
@@ -5,10 +5,22 @@
 //   - 缓存穿透/击穿/雪崩防护
 //   - 序列化策略（JSON/MessagePack）
 //   - 统计信息（命中率、键数量）
+//   - 客户端分片（一致性哈希 / Rendezvous，见 hashring 包）
+//   - 热点键检测（采样 + LFU 草图 Top-K），可自动提升到本地缓存
 //
 // 使用示例：
 //
 //	c := cache.NewRedis(client, cache.WithPrefix("app:"))
 //	err := c.Set(ctx, "key", value, time.Hour)
 //	err = c.Get(ctx, "key", &dest)
+//
+//	// 热点检测，热点键自动提升到本地缓存
+//	hk := cache.NewHotKeyed(c, "main", cache.DefaultHotKeyConfig(),
+//	    cache.WithPromotion(l1, 5*time.Second),
+//	    cache.WithHotKeyMetrics(metricsProvider),
+//	)
+//	hot := hk.Detector().HotKeys()
+//
+//	// 热点查询挂载到运行时管理端，指标按排名导出（需周期调用 UpdateHotKeys）
+//	runtime.WithAdmin(cfg.Admin, runtime.WithAdminHandler("/cache/hotkeys", hk.Handler()))
 package cache
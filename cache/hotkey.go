@@ -0,0 +1,317 @@
+package cache
+
+import (
+	"context"
+	"hash/maphash"
+	"math/rand/v2"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mildsunup/higo/observability"
+	"github.com/mildsunup/higo/response"
+)
+
+// HotKey 热点键
+type HotKey struct {
+	Key   string `json:"key"`
+	Count uint64 `json:"count"` // 估算访问次数（已按采样率放大）
+}
+
+// HotKeyConfig 热点检测配置
+type HotKeyConfig struct {
+	SampleRate    float64       // 采样率 (0, 1]，默认 1
+	TopK          int           // 跟踪的热点数量，默认 16
+	Width         int           // 频率草图宽度，默认 4096
+	Threshold     uint64        // 判定为热点的估算次数，默认 1000
+	DecayInterval time.Duration // 衰减周期（计数减半），默认 1 分钟
+}
+
+// DefaultHotKeyConfig 默认热点检测配置
+func DefaultHotKeyConfig() HotKeyConfig {
+	return HotKeyConfig{
+		SampleRate:    1,
+		TopK:          16,
+		Width:         4096,
+		Threshold:     1000,
+		DecayInterval: time.Minute,
+	}
+}
+
+const sketchDepth = 4
+
+// HotKeyDetector 基于采样与 Count-Min 草图（LFU sketch）的热点键检测器
+type HotKeyDetector struct {
+	cfg   HotKeyConfig
+	seeds [sketchDepth]maphash.Seed
+
+	mu        sync.Mutex
+	sketch    [sketchDepth][]uint32
+	top       map[string]uint64 // 候选热点（采样计数）
+	lastDecay time.Time
+}
+
+// NewHotKeyDetector 创建热点检测器
+func NewHotKeyDetector(cfg HotKeyConfig) *HotKeyDetector {
+	def := DefaultHotKeyConfig()
+	if cfg.SampleRate <= 0 || cfg.SampleRate > 1 {
+		cfg.SampleRate = def.SampleRate
+	}
+	if cfg.TopK <= 0 {
+		cfg.TopK = def.TopK
+	}
+	if cfg.Width <= 0 {
+		cfg.Width = def.Width
+	}
+	if cfg.Threshold == 0 {
+		cfg.Threshold = def.Threshold
+	}
+	if cfg.DecayInterval <= 0 {
+		cfg.DecayInterval = def.DecayInterval
+	}
+
+	d := &HotKeyDetector{
+		cfg:       cfg,
+		top:       make(map[string]uint64, cfg.TopK),
+		lastDecay: time.Now(),
+	}
+	for i := range d.seeds {
+		d.seeds[i] = maphash.MakeSeed()
+		d.sketch[i] = make([]uint32, cfg.Width)
+	}
+	return d
+}
+
+// Record 记录一次访问，返回该键当前是否为热点
+func (d *HotKeyDetector) Record(key string) bool {
+	if d.cfg.SampleRate < 1 && rand.Float64() >= d.cfg.SampleRate {
+		return d.IsHot(key)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.maybeDecay()
+
+	est := uint32(0)
+	for i := range d.sketch {
+		idx := maphash.String(d.seeds[i], key) % uint64(d.cfg.Width)
+		if d.sketch[i][idx] < ^uint32(0) {
+			d.sketch[i][idx]++
+		}
+		if i == 0 || d.sketch[i][idx] < est {
+			est = d.sketch[i][idx]
+		}
+	}
+	d.offer(key, uint64(est))
+
+	return d.scale(uint64(est)) >= d.cfg.Threshold
+}
+
+// IsHot 判断键是否为热点
+func (d *HotKeyDetector) IsHot(key string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	n, ok := d.top[key]
+	return ok && d.scale(n) >= d.cfg.Threshold
+}
+
+// TopK 返回按估算次数降序的热点候选
+func (d *HotKeyDetector) TopK() []HotKey {
+	d.mu.Lock()
+	keys := make([]HotKey, 0, len(d.top))
+	for k, n := range d.top {
+		keys = append(keys, HotKey{Key: k, Count: d.scale(n)})
+	}
+	d.mu.Unlock()
+
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Count > keys[j].Count })
+	return keys
+}
+
+// HotKeys 返回超过阈值的热点键
+func (d *HotKeyDetector) HotKeys() []HotKey {
+	all := d.TopK()
+	n := sort.Search(len(all), func(i int) bool { return all[i].Count < d.cfg.Threshold })
+	return all[:n]
+}
+
+// offer 更新候选集合，满员时替换最小者
+func (d *HotKeyDetector) offer(key string, est uint64) {
+	if _, ok := d.top[key]; ok || len(d.top) < d.cfg.TopK {
+		d.top[key] = est
+		return
+	}
+
+	minKey, minCount := "", ^uint64(0)
+	for k, n := range d.top {
+		if n < minCount {
+			minKey, minCount = k, n
+		}
+	}
+	if est > minCount {
+		delete(d.top, minKey)
+		d.top[key] = est
+	}
+}
+
+// maybeDecay 周期性将所有计数减半，使热点随时间迁移
+func (d *HotKeyDetector) maybeDecay() {
+	if time.Since(d.lastDecay) < d.cfg.DecayInterval {
+		return
+	}
+	d.lastDecay = time.Now()
+
+	for i := range d.sketch {
+		for j := range d.sketch[i] {
+			d.sketch[i][j] >>= 1
+		}
+	}
+	for k, n := range d.top {
+		if n >>= 1; n == 0 {
+			delete(d.top, k)
+		} else {
+			d.top[k] = n
+		}
+	}
+}
+
+func (d *HotKeyDetector) scale(n uint64) uint64 {
+	return uint64(float64(n) / d.cfg.SampleRate)
+}
+
+// HotKeyed 热点检测装饰器
+// 记录每次 Get 的键；启用提升时，热点键优先从本地 L1 读取，未命中则回源并写入 L1。
+type HotKeyed struct {
+	cache      Cache
+	name       string
+	detector   *HotKeyDetector
+	l1         Cache
+	promoteTTL time.Duration
+	gauge      observability.Gauge
+}
+
+// HotKeyOption 热点装饰器选项
+type HotKeyOption func(*HotKeyed)
+
+// WithPromotion 热点键自动提升到本地缓存
+func WithPromotion(l1 Cache, ttl time.Duration) HotKeyOption {
+	return func(h *HotKeyed) {
+		h.l1 = l1
+		h.promoteTTL = ttl
+	}
+}
+
+// WithHotKeyMetrics 导出热点指标
+// 按排名（1..TopK）而非键名打标签，序列数有界；具体键名通过 Handler 查询。
+func WithHotKeyMetrics(p observability.MetricsProvider) HotKeyOption {
+	return func(h *HotKeyed) {
+		h.gauge = p.Gauge("cache_hot_key_access", "Estimated access count of hot cache keys by rank", "name", "rank")
+	}
+}
+
+// NewHotKeyed 创建热点检测装饰器
+func NewHotKeyed(cache Cache, name string, cfg HotKeyConfig, opts ...HotKeyOption) *HotKeyed {
+	h := &HotKeyed{
+		cache:      cache,
+		name:       name,
+		detector:   NewHotKeyDetector(cfg),
+		promoteTTL: 5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+func (h *HotKeyed) Get(ctx context.Context, key string, dest any) error {
+	hot := h.detector.Record(key)
+	if !hot || h.l1 == nil {
+		return h.cache.Get(ctx, key, dest)
+	}
+
+	if err := h.l1.Get(ctx, key, dest); err == nil {
+		return nil
+	}
+	if err := h.cache.Get(ctx, key, dest); err != nil {
+		return err
+	}
+	_ = h.l1.Set(ctx, key, dest, h.promoteTTL)
+	return nil
+}
+
+func (h *HotKeyed) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	if h.l1 != nil {
+		_ = h.l1.Delete(ctx, key)
+	}
+	return h.cache.Set(ctx, key, value, ttl)
+}
+
+func (h *HotKeyed) Delete(ctx context.Context, keys ...string) error {
+	if h.l1 != nil {
+		_ = h.l1.Delete(ctx, keys...)
+	}
+	return h.cache.Delete(ctx, keys...)
+}
+
+func (h *HotKeyed) Exists(ctx context.Context, key string) (bool, error) {
+	return h.cache.Exists(ctx, key)
+}
+
+func (h *HotKeyed) Close() error  { return h.cache.Close() }
+func (h *HotKeyed) Unwrap() Cache { return h.cache }
+
+// Detector 返回热点检测器
+func (h *HotKeyed) Detector() *HotKeyDetector { return h.detector }
+
+// UpdateHotKeys 更新热点指标，需周期调用
+// 前 TopK 个排名各占一个序列，热点不足时剩余排名置零。
+func (h *HotKeyed) UpdateHotKeys() {
+	if h.gauge == nil {
+		return
+	}
+	hot := h.detector.HotKeys()
+	for rank := 1; rank <= h.detector.cfg.TopK; rank++ {
+		var n uint64
+		if rank <= len(hot) {
+			n = hot[rank-1].Count
+		}
+		h.gauge.Set(float64(n), h.name, strconv.Itoa(rank))
+	}
+}
+
+// HotKeyReport 热点查询结果
+type HotKeyReport struct {
+	Name      string   `json:"name"`
+	Threshold uint64   `json:"threshold"`
+	Keys      []HotKey `json:"keys"`
+}
+
+// Handler 返回热点查询接口，可挂载到运行时管理端：
+//
+//	GET /            超过阈值的热点键
+//	GET /?all=true   全部候选（含未达阈值者）
+//
+// 示例：runtime.WithAdmin(cfg, runtime.WithAdminHandler("/cache/hotkeys", hk.Handler()))
+func (h *HotKeyed) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		keys := h.detector.HotKeys()
+		if all, _ := strconv.ParseBool(r.URL.Query().Get("all")); all {
+			keys = h.detector.TopK()
+		}
+		response.WriteJSON(w, http.StatusOK, HotKeyReport{
+			Name:      h.name,
+			Threshold: h.detector.cfg.Threshold,
+			Keys:      keys,
+		})
+	})
+}
+
+var _ Cache = (*HotKeyed)(nil)
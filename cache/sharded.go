@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mildsunup/higo/hashring"
+)
+
+// Sharded 客户端分片缓存，按键哈希路由到多个实例
+type Sharded struct {
+	shards map[string]Cache
+	picker hashring.Picker
+}
+
+// NewSharded 创建分片缓存，picker 为空时使用一致性哈希环
+//
+//	c := cache.NewSharded(map[string]cache.Cache{
+//	    "redis-a": cache.NewRedis(cfgA),
+//	    "redis-b": cache.NewRedis(cfgB),
+//	}, nil)
+func NewSharded(shards map[string]Cache, picker hashring.Picker) *Sharded {
+	if picker == nil {
+		picker = hashring.New()
+	}
+	for name := range shards {
+		picker.Add(name)
+	}
+	return &Sharded{shards: shards, picker: picker}
+}
+
+// Shard 返回键所属的实例
+func (s *Sharded) Shard(key string) (Cache, error) {
+	name, ok := s.picker.Get(key)
+	if !ok {
+		return nil, fmt.Errorf("cache: no shard available")
+	}
+	c, ok := s.shards[name]
+	if !ok {
+		return nil, fmt.Errorf("cache: unknown shard %q", name)
+	}
+	return c, nil
+}
+
+func (s *Sharded) Get(ctx context.Context, key string, dest any) error {
+	c, err := s.Shard(key)
+	if err != nil {
+		return err
+	}
+	return c.Get(ctx, key, dest)
+}
+
+func (s *Sharded) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	c, err := s.Shard(key)
+	if err != nil {
+		return err
+	}
+	return c.Set(ctx, key, value, ttl)
+}
+
+func (s *Sharded) Delete(ctx context.Context, keys ...string) error {
+	groups := make(map[Cache][]string)
+	for _, key := range keys {
+		c, err := s.Shard(key)
+		if err != nil {
+			return err
+		}
+		groups[c] = append(groups[c], key)
+	}
+
+	var errs []error
+	for c, ks := range groups {
+		if err := c.Delete(ctx, ks...); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Sharded) Exists(ctx context.Context, key string) (bool, error) {
+	c, err := s.Shard(key)
+	if err != nil {
+		return false, err
+	}
+	return c.Exists(ctx, key)
+}
+
+func (s *Sharded) Close() error {
+	var errs []error
+	for _, c := range s.shards {
+		if err := c.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+var _ Cache = (*Sharded)(nil)
@@ -18,6 +18,7 @@ type Config struct {
 	Observability ObservabilityConfig `yaml:"observability" mapstructure:"observability"`
 	Storage       StorageConfig       `yaml:"storage" mapstructure:"storage"`
 	MQ            MQConfig            `yaml:"mq" mapstructure:"mq"`
+	Middleware    MiddlewareConfig    `yaml:"middleware" mapstructure:"middleware"`
 }
 
 // AppConfig 应用程序基础配置
@@ -88,6 +89,11 @@ type MetricsConfig struct {
 	Enabled bool   `yaml:"enabled" mapstructure:"enabled"`
 	Path    string `yaml:"path" mapstructure:"path"`
 	Port    string `yaml:"port" mapstructure:"port"`
+
+	Instance    string            `yaml:"instance" mapstructure:"instance"`         // 实例标识，为空时使用主机名
+	ConstLabels map[string]string `yaml:"const_labels" mapstructure:"const_labels"` // 附加到所有指标的常量标签
+	Discovery   bool              `yaml:"discovery" mapstructure:"discovery"`       // 提供抓取目标描述（http_sd）
+	PortName    string            `yaml:"port_name" mapstructure:"port_name"`       // ServiceMonitor 端口名
 }
 
 // TracingConfig 追踪配置
@@ -111,13 +117,25 @@ type StorageConfig struct {
 
 // MySQLStorageConfig MySQL 配置
 type MySQLStorageConfig struct {
-	Enabled         bool          `yaml:"enabled" mapstructure:"enabled"`
-	DSN             string        `yaml:"dsn" mapstructure:"dsn"`
-	Replicas        []string      `yaml:"replicas" mapstructure:"replicas"`
-	MaxOpenConns    int           `yaml:"max_open_conns" mapstructure:"max_open_conns"`
-	MaxIdleConns    int           `yaml:"max_idle_conns" mapstructure:"max_idle_conns"`
-	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" mapstructure:"conn_max_lifetime"`
-	LogLevel        int           `yaml:"log_level" mapstructure:"log_level"`
+	Enabled         bool                  `yaml:"enabled" mapstructure:"enabled"`
+	DSN             string                `yaml:"dsn" mapstructure:"dsn"`
+	Replicas        []string              `yaml:"replicas" mapstructure:"replicas"`
+	MaxOpenConns    int                   `yaml:"max_open_conns" mapstructure:"max_open_conns"`
+	MaxIdleConns    int                   `yaml:"max_idle_conns" mapstructure:"max_idle_conns"`
+	ConnMaxLifetime time.Duration         `yaml:"conn_max_lifetime" mapstructure:"conn_max_lifetime"`
+	LogLevel        int                   `yaml:"log_level" mapstructure:"log_level"`
+	SQLCommenter    bool                  `yaml:"sql_commenter" mapstructure:"sql_commenter"` // 为 SQL 追加 traceparent、route、application 注释
+	Sharding        []MySQLShardingConfig `yaml:"sharding" mapstructure:"sharding"`           // 分表/分库规则
+}
+
+// MySQLShardingConfig MySQL 分表/分库规则
+type MySQLShardingConfig struct {
+	Table          string `yaml:"table" mapstructure:"table"`
+	ShardKey       string `yaml:"shard_key" mapstructure:"shard_key"`
+	ShardCount     int    `yaml:"shard_count" mapstructure:"shard_count"`
+	Algorithm      string `yaml:"algorithm" mapstructure:"algorithm"` // mod（默认）或 hash
+	DatabaseCount  int    `yaml:"database_count" mapstructure:"database_count"`
+	DatabaseFormat string `yaml:"database_format" mapstructure:"database_format"` // 如 "order_db_%d"
 }
 
 // RedisStorageConfig Redis 配置
@@ -158,6 +176,24 @@ type ElasticsearchStorageConfig struct {
 	Password  string   `yaml:"password" mapstructure:"password"`
 }
 
+// MiddlewareConfig 中间件栈配置，按列表顺序装配
+type MiddlewareConfig struct {
+	HTTP []MiddlewareSpec `yaml:"http" mapstructure:"http"`
+	GRPC []MiddlewareSpec `yaml:"grpc" mapstructure:"grpc"`
+}
+
+// MiddlewareSpec 单个中间件配置
+type MiddlewareSpec struct {
+	Name    string         `yaml:"name" mapstructure:"name"`
+	Enabled *bool          `yaml:"enabled" mapstructure:"enabled"` // 未设置时默认启用
+	Params  map[string]any `yaml:"params" mapstructure:"params"`
+}
+
+// IsEnabled 是否启用
+func (s MiddlewareSpec) IsEnabled() bool {
+	return s.Enabled == nil || *s.Enabled
+}
+
 // MQConfig 消息队列配置
 type MQConfig struct {
 	Kafka    KafkaConfig    `yaml:"kafka" mapstructure:"kafka"`
@@ -323,8 +359,9 @@ func Default() *Config {
 		},
 		Observability: ObservabilityConfig{
 			Metrics: MetricsConfig{
-				Enabled: true,
-				Path:    "/metrics",
+				Enabled:  true,
+				Path:     "/metrics",
+				PortName: "metrics",
 			},
 			Tracing: TracingConfig{
 				Enabled:    false,
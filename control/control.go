@@ -0,0 +1,343 @@
+package control
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mildsunup/higo/logger"
+	"github.com/mildsunup/higo/resilience"
+)
+
+var (
+	// ErrUnknownTarget 目标未注册
+	ErrUnknownTarget = errors.New("control: unknown target")
+	// ErrInvalidValue 取值不合法
+	ErrInvalidValue = errors.New("control: invalid value")
+)
+
+// 熔断器控制模式
+const (
+	BreakerAuto   = "auto"   // 按失败阈值自动熔断
+	BreakerOpen   = "open"   // 强制打开，拒绝全部请求
+	BreakerClosed = "closed" // 强制关闭，放行全部请求
+)
+
+// Sampler 可调整采样率的采样器，observability.DynamicSampler 实现该接口
+type Sampler interface {
+	Ratio() float64
+	SetRatio(ratio float64) error
+}
+
+// Limiter 可调整阈值的限流器，resilience.TokenBucket 实现该接口
+type Limiter interface {
+	Limit() (rate float64, burst int)
+	SetLimit(rate float64, burst int)
+}
+
+// Breaker 可强制开关的熔断器，resilience.CircuitBreaker 实现该接口
+type Breaker interface {
+	State() resilience.CircuitState
+	Forced() (resilience.CircuitState, bool)
+	ForceOpen()
+	ForceClose()
+	Reset()
+}
+
+// Limit 限流阈值
+type Limit struct {
+	Rate  float64 `json:"rate" mapstructure:"rate"`             // 每秒补充令牌数
+	Burst int     `json:"burst,omitempty" mapstructure:"burst"` // 桶容量，0 表示保持不变
+}
+
+// Settings 变更请求，仅包含需要修改的项
+type Settings struct {
+	Sampling map[string]float64 `json:"sampling,omitempty" mapstructure:"sampling"`   // 采样器名称 → 采样率
+	Limits   map[string]Limit   `json:"limits,omitempty" mapstructure:"limits"`       // 限流器名称 → 阈值
+	Breakers map[string]string  `json:"breakers,omitempty" mapstructure:"breakers"`   // 熔断器名称 → auto/open/closed
+	LogLevel string             `json:"log_level,omitempty" mapstructure:"log_level"` // debug/info/warn/error
+}
+
+// BreakerStatus 熔断器状态
+type BreakerStatus struct {
+	Mode  string `json:"mode"`  // auto/open/closed
+	State string `json:"state"` // closed/open/half-open
+}
+
+// Snapshot 当前取值
+type Snapshot struct {
+	Sampling map[string]float64       `json:"sampling"`
+	Limits   map[string]Limit         `json:"limits"`
+	Breakers map[string]BreakerStatus `json:"breakers"`
+	LogLevel string                   `json:"log_level,omitempty"`
+}
+
+// Change 单项变更
+type Change struct {
+	Target string `json:"target"` // 如 sampling.tracing、limits.api、breakers.payment、log_level
+	From   string `json:"from"`
+	To     string `json:"to"`
+}
+
+// AuditEntry 审计记录
+type AuditEntry struct {
+	Time    time.Time `json:"time"`
+	Actor   string    `json:"actor"`          // 操作者，管理接口取自已认证身份，配置订阅为提供者名称
+	Source  string    `json:"source"`         // admin、config 或调用方自定义
+	Note    string    `json:"note,omitempty"` // 调用方附注（管理接口的 X-Actor 请求头），未经认证
+	Changes []Change  `json:"changes"`
+}
+
+// Option 控制面选项
+type Option func(*Plane)
+
+// WithLevelController 设置日志级别控制器，未设置时不支持修改日志级别
+func WithLevelController(lc logger.LevelController) Option {
+	return func(p *Plane) { p.level = lc }
+}
+
+// WithLogger 设置日志，每次变更以 Warn 级别记录
+func WithLogger(log logger.Logger) Option {
+	return func(p *Plane) { p.log = log }
+}
+
+// WithAuditSize 设置内存中保留的审计记录数，默认 200
+func WithAuditSize(n int) Option {
+	return func(p *Plane) { p.auditSize = n }
+}
+
+// WithAuditSink 设置审计记录的持久化回调（如写入审计表或消息队列），在变更生效后同步调用
+func WithAuditSink(fn func(ctx context.Context, entry AuditEntry)) Option {
+	return func(p *Plane) { p.sink = fn }
+}
+
+// Plane 控制面，并发安全
+type Plane struct {
+	mu        sync.Mutex
+	samplers  map[string]Sampler
+	limiters  map[string]Limiter
+	breakers  map[string]Breaker
+	level     logger.LevelController
+	log       logger.Logger
+	sink      func(ctx context.Context, entry AuditEntry)
+	auditSize int
+	audit     []AuditEntry
+	now       func() time.Time
+}
+
+// New 创建控制面
+func New(opts ...Option) *Plane {
+	p := &Plane{
+		samplers:  make(map[string]Sampler),
+		limiters:  make(map[string]Limiter),
+		breakers:  make(map[string]Breaker),
+		log:       logger.Nop(),
+		auditSize: 200,
+		now:       time.Now,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// AddSampler 注册采样器
+func (p *Plane) AddSampler(name string, s Sampler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.samplers[name] = s
+}
+
+// AddLimiter 注册限流器
+func (p *Plane) AddLimiter(name string, l Limiter) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.limiters[name] = l
+}
+
+// AddBreaker 注册熔断器
+func (p *Plane) AddBreaker(name string, b Breaker) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.breakers[name] = b
+}
+
+// Snapshot 返回当前取值
+func (p *Plane) Snapshot() Snapshot {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := Snapshot{
+		Sampling: make(map[string]float64, len(p.samplers)),
+		Limits:   make(map[string]Limit, len(p.limiters)),
+		Breakers: make(map[string]BreakerStatus, len(p.breakers)),
+	}
+	for name, sm := range p.samplers {
+		s.Sampling[name] = sm.Ratio()
+	}
+	for name, l := range p.limiters {
+		rate, burst := l.Limit()
+		s.Limits[name] = Limit{Rate: rate, Burst: burst}
+	}
+	for name, b := range p.breakers {
+		s.Breakers[name] = BreakerStatus{Mode: breakerMode(b), State: b.State().String()}
+	}
+	if p.level != nil {
+		s.LogLevel = p.level.Level().String()
+	}
+	return s
+}
+
+// Apply 校验并应用变更，任一项不合法时不做任何修改。
+// 返回本次的审计记录，取值均未变化时返回 nil
+func (p *Plane) Apply(ctx context.Context, actor, source string, s Settings) (*AuditEntry, error) {
+	return p.apply(ctx, AuditEntry{Actor: actor, Source: source}, s)
+}
+
+// apply 应用变更，entry 提供操作者、来源与附注
+func (p *Plane) apply(ctx context.Context, entry AuditEntry, s Settings) (*AuditEntry, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	changes, apply, err := p.plan(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	for _, fn := range apply {
+		fn()
+	}
+
+	entry.Time, entry.Changes = p.now(), changes
+	if p.auditSize > 0 {
+		if len(p.audit) >= p.auditSize {
+			p.audit = slices.Delete(p.audit, 0, len(p.audit)-p.auditSize+1)
+		}
+		p.audit = append(p.audit, entry)
+	}
+	for _, c := range changes {
+		p.log.Warn(ctx, "control setting changed",
+			logger.String("target", c.Target), logger.String("from", c.From), logger.String("to", c.To),
+			logger.String("actor", entry.Actor), logger.String("source", entry.Source))
+	}
+	if p.sink != nil {
+		p.sink(ctx, entry)
+	}
+	return &entry, nil
+}
+
+// plan 校验变更并生成变更项与执行函数，调用方需持有 p.mu
+func (p *Plane) plan(s Settings) ([]Change, []func(), error) {
+	var (
+		changes []Change
+		apply   []func()
+	)
+	for _, name := range slices.Sorted(maps.Keys(s.Sampling)) {
+		ratio := s.Sampling[name]
+		sm, ok := p.samplers[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: sampler %q", ErrUnknownTarget, name)
+		}
+		if ratio < 0 || ratio > 1 {
+			return nil, nil, fmt.Errorf("%w: sample ratio %v out of range [0, 1]", ErrInvalidValue, ratio)
+		}
+		if old := sm.Ratio(); old != ratio {
+			changes = append(changes, Change{Target: "sampling." + name, From: formatFloat(old), To: formatFloat(ratio)})
+			apply = append(apply, func() { _ = sm.SetRatio(ratio) })
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(s.Limits)) {
+		limit := s.Limits[name]
+		l, ok := p.limiters[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: limiter %q", ErrUnknownTarget, name)
+		}
+		if limit.Rate <= 0 || limit.Burst < 0 {
+			return nil, nil, fmt.Errorf("%w: limit for %q requires rate > 0 and burst >= 0", ErrInvalidValue, name)
+		}
+		rate, burst := l.Limit()
+		if limit.Burst == 0 {
+			limit.Burst = burst
+		}
+		if rate != limit.Rate || burst != limit.Burst {
+			changes = append(changes, Change{Target: "limits." + name, From: formatLimit(rate, burst), To: formatLimit(limit.Rate, limit.Burst)})
+			apply = append(apply, func() { l.SetLimit(limit.Rate, limit.Burst) })
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(s.Breakers)) {
+		mode := s.Breakers[name]
+		b, ok := p.breakers[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: breaker %q", ErrUnknownTarget, name)
+		}
+		var fn func()
+		switch mode {
+		case BreakerAuto:
+			fn = b.Reset
+		case BreakerOpen:
+			fn = b.ForceOpen
+		case BreakerClosed:
+			fn = b.ForceClose
+		default:
+			return nil, nil, fmt.Errorf("%w: breaker mode %q", ErrInvalidValue, mode)
+		}
+		if old := breakerMode(b); old != mode {
+			changes = append(changes, Change{Target: "breakers." + name, From: old, To: mode})
+			apply = append(apply, fn)
+		}
+	}
+	if s.LogLevel != "" {
+		if p.level == nil {
+			return nil, nil, fmt.Errorf("%w: log level", ErrUnknownTarget)
+		}
+		switch s.LogLevel {
+		case "debug", "info", "warn", "warning", "error":
+		default:
+			return nil, nil, fmt.Errorf("%w: log level %q", ErrInvalidValue, s.LogLevel)
+		}
+		level := logger.ParseLevel(s.LogLevel)
+		if old := p.level.Level(); old != level {
+			changes = append(changes, Change{Target: "log_level", From: old.String(), To: level.String()})
+			apply = append(apply, func() { p.level.SetLevel(level) })
+		}
+	}
+	return changes, apply, nil
+}
+
+// Audit 返回最近的审计记录（从新到旧），limit <= 0 时返回全部
+func (p *Plane) Audit(limit int) []AuditEntry {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := slices.Clone(p.audit)
+	slices.Reverse(out)
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+func breakerMode(b Breaker) string {
+	state, ok := b.Forced()
+	switch {
+	case !ok:
+		return BreakerAuto
+	case state == resilience.CircuitOpen:
+		return BreakerOpen
+	default:
+		return BreakerClosed
+	}
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func formatLimit(rate float64, burst int) string {
+	return formatFloat(rate) + "/s burst " + strconv.Itoa(burst)
+}
@@ -0,0 +1,256 @@
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mildsunup/higo/logger"
+	mw "github.com/mildsunup/higo/middleware"
+	"github.com/mildsunup/higo/observability"
+	"github.com/mildsunup/higo/resilience"
+)
+
+type levelController struct{ level logger.Level }
+
+func (l *levelController) Level() logger.Level         { return l.level }
+func (l *levelController) SetLevel(level logger.Level) { l.level = level }
+
+func newPlane(t *testing.T, opts ...Option) (*Plane, *resilience.TokenBucket, *resilience.CircuitBreaker, *observability.DynamicSampler) {
+	t.Helper()
+	p := New(append([]Option{WithLevelController(&levelController{level: logger.InfoLevel})}, opts...)...)
+	tb := resilience.NewTokenBucket(10, 10)
+	cb := resilience.NewCircuitBreaker(resilience.WithFailureThreshold(1))
+	s := observability.NewDynamicSampler("parent", 0.1)
+	p.AddLimiter("api", tb)
+	p.AddBreaker("payment", cb)
+	p.AddSampler("tracing", s)
+	return p, tb, cb, s
+}
+
+func TestApply(t *testing.T) {
+	var sunk []AuditEntry
+	p, tb, cb, s := newPlane(t, WithAuditSink(func(_ context.Context, e AuditEntry) { sunk = append(sunk, e) }))
+	ctx := context.Background()
+
+	entry, err := p.Apply(ctx, "alice", "admin", Settings{
+		Sampling: map[string]float64{"tracing": 1},
+		Limits:   map[string]Limit{"api": {Rate: 100}},
+		Breakers: map[string]string{"payment": BreakerOpen},
+		LogLevel: "debug",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entry.Changes) != 4 || entry.Actor != "alice" || entry.Source != "admin" {
+		t.Fatalf("unexpected entry %+v", entry)
+	}
+	if s.Ratio() != 1 {
+		t.Fatalf("ratio = %v", s.Ratio())
+	}
+	if rate, burst := tb.Limit(); rate != 100 || burst != 10 {
+		t.Fatalf("limit = %v/%d", rate, burst)
+	}
+	if err := cb.Execute(ctx, func(context.Context) error { return nil }); !errors.Is(err, resilience.ErrCircuitOpen) {
+		t.Fatalf("forced open breaker returned %v", err)
+	}
+	if got := p.Snapshot(); got.LogLevel != "debug" || got.Breakers["payment"].Mode != BreakerOpen {
+		t.Fatalf("unexpected snapshot %+v", got)
+	}
+	if len(sunk) != 1 {
+		t.Fatalf("sink called %d times", len(sunk))
+	}
+
+	// 未变化的项不产生审计记录
+	entry, err = p.Apply(ctx, "bob", "admin", Settings{Sampling: map[string]float64{"tracing": 1}})
+	if err != nil || entry != nil {
+		t.Fatalf("no-op apply = %+v, %v", entry, err)
+	}
+
+	// 强制关闭后失败不再触发熔断
+	if _, err := p.Apply(ctx, "bob", "admin", Settings{Breakers: map[string]string{"payment": BreakerClosed}}); err != nil {
+		t.Fatal(err)
+	}
+	_ = cb.Execute(ctx, func(context.Context) error { return errors.New("boom") })
+	if cb.State() != resilience.CircuitClosed {
+		t.Fatalf("forced closed breaker state = %v", cb.State())
+	}
+	if _, err := p.Apply(ctx, "bob", "admin", Settings{Breakers: map[string]string{"payment": BreakerAuto}}); err != nil {
+		t.Fatal(err)
+	}
+	_ = cb.Execute(ctx, func(context.Context) error { return errors.New("boom") })
+	if cb.State() != resilience.CircuitOpen {
+		t.Fatalf("auto breaker state = %v", cb.State())
+	}
+
+	audit := p.Audit(0)
+	if len(audit) != 3 || audit[0].Changes[0].To != BreakerAuto {
+		t.Fatalf("unexpected audit %+v", audit)
+	}
+}
+
+func TestApplyValidatesAll(t *testing.T) {
+	p, _, _, s := newPlane(t)
+	ctx := context.Background()
+
+	_, err := p.Apply(ctx, "alice", "admin", Settings{
+		Sampling: map[string]float64{"tracing": 0.5},
+		Breakers: map[string]string{"missing": BreakerOpen},
+	})
+	if !errors.Is(err, ErrUnknownTarget) {
+		t.Fatalf("err = %v", err)
+	}
+	if s.Ratio() != 0.1 {
+		t.Fatalf("partial apply: ratio = %v", s.Ratio())
+	}
+
+	for _, bad := range []Settings{
+		{Sampling: map[string]float64{"tracing": 2}},
+		{Limits: map[string]Limit{"api": {Rate: 0}}},
+		{Breakers: map[string]string{"payment": "half"}},
+		{LogLevel: "trace"},
+	} {
+		if _, err := p.Apply(ctx, "alice", "admin", bad); !errors.Is(err, ErrInvalidValue) {
+			t.Fatalf("%+v: err = %v", bad, err)
+		}
+	}
+	if len(p.Audit(0)) != 0 {
+		t.Fatal("failed applies must not be audited")
+	}
+}
+
+func TestAuditSize(t *testing.T) {
+	p, _, _, _ := newPlane(t, WithAuditSize(2))
+	for _, r := range []float64{0.2, 0.3, 0.4} {
+		if _, err := p.Apply(context.Background(), "alice", "admin", Settings{Sampling: map[string]float64{"tracing": r}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	audit := p.Audit(0)
+	if len(audit) != 2 || audit[0].Changes[0].To != "0.4" || audit[1].Changes[0].To != "0.3" {
+		t.Fatalf("unexpected audit %+v", audit)
+	}
+	if len(p.Audit(1)) != 1 {
+		t.Fatal("limit not applied")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	p, _, _, _ := newPlane(t)
+	h := p.Handler()
+
+	req := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"sampling":{"tracing":0.5},"log_level":"warn"}`))
+	req = req.WithContext(mw.WithValue(req.Context(), mw.UserIDKey, uint64(42)))
+	req.Header.Set(ActorHeader, "alice")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	var resp struct {
+		Changes  []Change           `json:"changes"`
+		Sampling map[string]float64 `json:"sampling"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Changes) != 2 || resp.Sampling["tracing"] != 0.5 {
+		t.Fatalf("unexpected response %s", rec.Body)
+	}
+
+	for body, want := range map[string]int{
+		`{"breakers":{"missing":"open"}}`: http.StatusNotFound,
+		`{"sampling":{"tracing":-1}}`:     http.StatusBadRequest,
+		`{"unknown":1}`:                   http.StatusBadRequest,
+	} {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(body))
+		req.Header.Set("Accept", "application/problem+json")
+		h.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Fatalf("%s: status = %d, want %d", body, rec.Code, want)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+			t.Fatalf("%s: content type = %q", body, ct)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/audit", nil))
+	var audit []AuditEntry
+	if err := json.Unmarshal(rec.Body.Bytes(), &audit); err != nil {
+		t.Fatal(err)
+	}
+	if len(audit) != 1 || audit[0].Actor != "user:42" || audit[0].Note != "alice" {
+		t.Fatalf("unexpected audit %s", rec.Body)
+	}
+}
+
+func TestHandler_Actor(t *testing.T) {
+	p, _, _, _ := newPlane(t)
+	h := p.Handler()
+
+	patch := func(rate string, ctx func(*http.Request) *http.Request) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"sampling":{"tracing":`+rate+`}}`))
+		req.RemoteAddr = "203.0.113.7:5000"
+		req.Header.Set(ActorHeader, "root")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, ctx(req))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+		}
+	}
+	patch("0.2", func(r *http.Request) *http.Request { return r })
+	patch("0.3", func(r *http.Request) *http.Request {
+		return r.WithContext(mw.WithValue(r.Context(), mw.SubjectKey, "admin-token"))
+	})
+
+	// X-Actor 不能冒充身份
+	audit := p.Audit(0)
+	if audit[1].Actor != "anonymous@203.0.113.7" || audit[0].Actor != "admin-token" || audit[0].Note != "root" {
+		t.Fatalf("unexpected audit %+v", audit)
+	}
+}
+
+type provider struct {
+	data     map[string]any
+	onChange chan func()
+}
+
+func (p *provider) Name() string                                 { return "test" }
+func (p *provider) Load(context.Context) (map[string]any, error) { return p.data, nil }
+func (p *provider) Watch(ctx context.Context, onChange func()) error {
+	p.onChange <- onChange
+	return nil
+}
+
+func TestWatch(t *testing.T) {
+	p, tb, _, _ := newPlane(t)
+	prov := &provider{
+		data: map[string]any{"control": map[string]any{
+			"limits": map[string]any{"api": map[string]any{"rate": "50", "burst": 5}},
+		}},
+		onChange: make(chan func(), 1),
+	}
+	if err := p.Watch(context.Background(), prov, "control"); err != nil {
+		t.Fatal(err)
+	}
+	if rate, burst := tb.Limit(); rate != 50 || burst != 5 {
+		t.Fatalf("limit = %v/%d", rate, burst)
+	}
+
+	prov.data = map[string]any{"control": map[string]any{"log_level": "error"}}
+	(<-prov.onChange)()
+	if got := p.Snapshot().LogLevel; got != "error" {
+		t.Fatalf("log level = %s", got)
+	}
+	audit := p.Audit(0)
+	if len(audit) != 2 || audit[0].Actor != "test" || audit[0].Source != "config" {
+		t.Fatalf("unexpected audit %+v", audit)
+	}
+}
@@ -0,0 +1,24 @@
+// Package control 提供运行时控制面：不重启地调整追踪采样率、限流阈值、熔断强制开关与日志级别，
+// 并审计每次变更的操作者、来源与前后取值。
+//
+// 变更可来自管理接口（Handler）或远程配置订阅（Watch），两者使用相同的 Settings 结构，
+// 仅包含需要修改的项，未出现的项保持不变。
+//
+// 使用示例：
+//
+//	plane := control.New(control.WithLevelController(log.(logger.LevelController)), control.WithLogger(log))
+//	plane.AddSampler("tracing", obs.Sampler())
+//	plane.AddLimiter("api", limiter)
+//	plane.AddBreaker("payment", breaker)
+//
+//	app := runtime.New(cfg, runtime.WithAdmin(adminCfg,
+//	    runtime.WithAdminHandler("/control/", http.StripPrefix("/control", plane.Handler()))))
+//
+//	// 可选：订阅远程配置中的 control 段
+//	_ = plane.Watch(ctx, config.NewRemoteProvider(config.RemoteEtcd3, "http://etcd:2379", "/app/config"), "control")
+//
+// 修改请求（审计的操作者取自认证身份，X-Actor 仅作为附注）：
+//
+//	curl -X PATCH -H 'Authorization: Bearer <token>' -H 'X-Actor: alice' localhost:6060/control/ \
+//	    -d '{"sampling":{"tracing":1},"breakers":{"payment":"open"},"log_level":"debug"}'
+package control
@@ -0,0 +1,81 @@
+package control
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/mildsunup/higo/errors"
+	mw "github.com/mildsunup/higo/middleware"
+	"github.com/mildsunup/higo/response"
+)
+
+// ActorHeader 管理接口中操作者附注的请求头，仅记入审计的 Note，不作为身份
+const ActorHeader = "X-Actor"
+
+// Handler 返回控制面管理接口，挂载时需去掉前缀：
+//
+//	GET   /         当前取值
+//	PATCH /         应用变更，请求体为 Settings，PUT 同上
+//	GET   /audit    最近的审计记录（从新到旧），?limit=50
+//
+// 操作者取自已认证身份：用户 ID（user:<id>）或认证主体（如 runtime 管理令牌），
+// 均缺失时记录为 anonymous@<客户端地址>。
+func (p *Plane) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, _ *http.Request) {
+		response.WriteJSON(w, http.StatusOK, p.Snapshot())
+	})
+	mux.HandleFunc("PATCH /{$}", p.update)
+	mux.HandleFunc("PUT /{$}", p.update)
+	mux.HandleFunc("GET /audit", func(w http.ResponseWriter, r *http.Request) {
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		response.WriteJSON(w, http.StatusOK, p.Audit(limit))
+	})
+	return mux
+}
+
+func (p *Plane) update(w http.ResponseWriter, r *http.Request) {
+	var s Settings
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&s); err != nil {
+		response.WriteError(w, r, errors.Wrap(err, errors.InvalidArgument, "control: invalid body: "+err.Error()))
+		return
+	}
+	entry, err := p.apply(r.Context(), AuditEntry{Actor: actor(r), Source: "admin", Note: r.Header.Get(ActorHeader)}, s)
+	switch {
+	case errors.Is(err, ErrUnknownTarget):
+		response.WriteError(w, r, errors.Wrap(err, errors.NotFound, err.Error()))
+	case err != nil:
+		response.WriteError(w, r, errors.Wrap(err, errors.InvalidArgument, err.Error()))
+	default:
+		resp := struct {
+			Changes []Change `json:"changes"`
+			Snapshot
+		}{Changes: []Change{}, Snapshot: p.Snapshot()}
+		if entry != nil {
+			resp.Changes = entry.Changes
+		}
+		response.WriteJSON(w, http.StatusOK, resp)
+	}
+}
+
+func actor(r *http.Request) string {
+	ctx := r.Context()
+	if id, ok := mw.GetUserID(ctx); ok {
+		return "user:" + strconv.FormatUint(id, 10)
+	}
+	if s := mw.GetSubject(ctx); s != "" {
+		return s
+	}
+	host := mw.GetClientIP(ctx)
+	if host == "" {
+		var err error
+		if host, _, err = net.SplitHostPort(r.RemoteAddr); err != nil {
+			host = r.RemoteAddr
+		}
+	}
+	return "anonymous@" + host
+}
@@ -0,0 +1,60 @@
+package control
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/mildsunup/higo/config"
+	"github.com/mildsunup/higo/logger"
+)
+
+// Watch 订阅配置提供者中 key 对应的段（如 "control"，支持 "a.b" 嵌套），立即应用一次并在配置变更时重新应用。
+// 段的结构同 Settings，操作者记录为提供者名称、来源为 config；取值未变化的项不会产生审计记录。
+// 远程配置与管理接口修改同一项时以最后一次为准。注意 viper 远程提供者会将键名转为小写，注册名称应使用小写。
+// 首次加载失败时返回错误，后续失败仅记录日志
+func (p *Plane) Watch(ctx context.Context, provider config.Provider, key string) error {
+	if err := p.load(ctx, provider, key); err != nil {
+		return err
+	}
+	go func() {
+		_ = provider.Watch(ctx, func() {
+			if err := p.load(ctx, provider, key); err != nil {
+				p.log.Error(ctx, "control: apply config failed", logger.String("provider", provider.Name()), logger.Err(err))
+			}
+		})
+	}()
+	return nil
+}
+
+func (p *Plane) load(ctx context.Context, provider config.Provider, key string) error {
+	data, err := provider.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("control: load %s: %w", provider.Name(), err)
+	}
+	var section any = data
+	for part := range strings.SplitSeq(key, ".") {
+		m, ok := section.(map[string]any)
+		if !ok {
+			section = nil
+			break
+		}
+		section = m[part]
+	}
+	if section == nil {
+		return nil
+	}
+
+	var s Settings
+	dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{Result: &s, WeaklyTypedInput: true})
+	if err != nil {
+		return fmt.Errorf("control: create decoder: %w", err)
+	}
+	if err := dec.Decode(section); err != nil {
+		return fmt.Errorf("control: decode %s: %w", key, err)
+	}
+	_, err = p.Apply(ctx, provider.Name(), "config", s)
+	return err
+}
@@ -0,0 +1,61 @@
+package ddd
+
+import (
+	"context"
+	"time"
+)
+
+// Audit 审计字段混入，嵌入持久化模型记录创建/修改人与时间
+//
+//	type UserPO struct {
+//	    ID uint64
+//	    ddd.Audit
+//	}
+type Audit struct {
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	CreatedBy string `gorm:"size:64"`
+	UpdatedBy string `gorm:"size:64"`
+}
+
+// Auditable 可审计对象
+type Auditable interface {
+	StampCreated(actor string, at time.Time)
+	StampUpdated(actor string, at time.Time)
+}
+
+// StampCreated 填充创建审计字段
+func (a *Audit) StampCreated(actor string, at time.Time) {
+	a.CreatedAt, a.CreatedBy = at, actor
+	a.UpdatedAt, a.UpdatedBy = at, actor
+}
+
+// StampUpdated 填充修改审计字段
+func (a *Audit) StampUpdated(actor string, at time.Time) {
+	a.UpdatedAt, a.UpdatedBy = at, actor
+}
+
+type actorKey struct{}
+
+// WithActor 设置当前操作人
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext 获取当前操作人
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// StampCreate 根据 context 中的操作人填充创建审计字段（供非 GORM 仓储使用）
+func StampCreate(ctx context.Context, a Auditable) {
+	a.StampCreated(ActorFromContext(ctx), time.Now())
+}
+
+// StampUpdate 根据 context 中的操作人填充修改审计字段
+func StampUpdate(ctx context.Context, a Auditable) {
+	a.StampUpdated(ActorFromContext(ctx), time.Now())
+}
+
+var _ Auditable = (*Audit)(nil)
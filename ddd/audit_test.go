@@ -0,0 +1,33 @@
+package ddd
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAudit_Stamp(t *testing.T) {
+	if ActorFromContext(context.Background()) != "" {
+		t.Fatal("actor without WithActor")
+	}
+
+	var a Audit
+	StampCreate(WithActor(context.Background(), "alice"), &a)
+	if a.CreatedBy != "alice" || a.UpdatedBy != "alice" || a.CreatedAt.IsZero() || !a.UpdatedAt.Equal(a.CreatedAt) {
+		t.Fatalf("created = %+v", a)
+	}
+
+	created := a.CreatedAt
+	a.StampUpdated("bob", created.Add(time.Minute))
+	if a.CreatedBy != "alice" || !a.CreatedAt.Equal(created) {
+		t.Fatalf("update changed creation fields: %+v", a)
+	}
+	if a.UpdatedBy != "bob" || !a.UpdatedAt.Equal(created.Add(time.Minute)) {
+		t.Fatalf("updated = %+v", a)
+	}
+
+	StampUpdate(WithActor(context.Background(), "carol"), &a)
+	if a.UpdatedBy != "carol" || !a.UpdatedAt.After(created) || a.CreatedBy != "alice" {
+		t.Fatalf("stamp update = %+v", a)
+	}
+}
@@ -0,0 +1,242 @@
+package ddd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrDispatcherClosed 分发器已关闭
+var ErrDispatcherClosed = errors.New("ddd: event dispatcher closed")
+
+// EventSource 事件来源（通常是聚合根）
+type EventSource interface {
+	PullEvents() []DomainEvent
+}
+
+// EventDispatcher 进程内领域事件分发器
+// 在 MQ 发布之前/之外，将事件分发给本地处理器；
+// 同步处理器在 Dispatch 中依次执行，异步处理器交由 worker 池执行。
+type EventDispatcher struct {
+	mu       sync.RWMutex
+	handlers []registeredHandler
+	queue    chan asyncJob
+	wg       sync.WaitGroup
+	inflight sync.WaitGroup // 进行中的 Dispatch，Close 等其结束后才关闭队列
+	pending  atomic.Int64   // 已入队但尚未处理完成的异步任务数
+	closed   bool
+	workers  int
+	size     int
+	onError  func(ctx context.Context, event DomainEvent, err error)
+}
+
+type registeredHandler struct {
+	accept  func(DomainEvent) bool
+	handler EventHandler
+	async   bool
+}
+
+type asyncJob struct {
+	ctx     context.Context
+	event   DomainEvent
+	handler EventHandler
+}
+
+// DispatcherOption 分发器选项
+type DispatcherOption func(*EventDispatcher)
+
+// WithDispatchWorkers 设置异步 worker 数量
+func WithDispatchWorkers(n int) DispatcherOption {
+	return func(d *EventDispatcher) {
+		if n > 0 {
+			d.workers = n
+		}
+	}
+}
+
+// WithDispatchQueueSize 设置异步队列长度
+func WithDispatchQueueSize(n int) DispatcherOption {
+	return func(d *EventDispatcher) {
+		if n >= 0 {
+			d.size = n
+		}
+	}
+}
+
+// WithDispatchErrorHandler 设置异步处理失败回调
+func WithDispatchErrorHandler(fn func(ctx context.Context, event DomainEvent, err error)) DispatcherOption {
+	return func(d *EventDispatcher) {
+		d.onError = fn
+	}
+}
+
+// NewEventDispatcher 创建事件分发器
+func NewEventDispatcher(opts ...DispatcherOption) *EventDispatcher {
+	d := &EventDispatcher{
+		workers: 4,
+		size:    1024,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	d.queue = make(chan asyncJob, d.size)
+	for i := 0; i < d.workers; i++ {
+		d.wg.Add(1)
+		go d.worker()
+	}
+	return d
+}
+
+// HandlerOption 处理器注册选项
+type HandlerOption func(*registeredHandler)
+
+// Async 异步执行处理器
+func Async() HandlerOption {
+	return func(h *registeredHandler) {
+		h.async = true
+	}
+}
+
+// On 按事件类型注册处理器
+//
+//	ddd.On(dispatcher, func(ctx context.Context, e UserCreated) error { ... })
+func On[E DomainEvent](d *EventDispatcher, handler func(ctx context.Context, event E) error, opts ...HandlerOption) {
+	d.register(registeredHandler{
+		accept: func(event DomainEvent) bool {
+			_, ok := event.(E)
+			return ok
+		},
+		handler: func(ctx context.Context, event DomainEvent) error {
+			return handler(ctx, event.(E))
+		},
+	}, opts)
+}
+
+// Subscribe 按事件名注册处理器
+func (d *EventDispatcher) Subscribe(eventName string, handler EventHandler) {
+	d.register(registeredHandler{
+		accept:  func(event DomainEvent) bool { return event.EventName() == eventName },
+		handler: handler,
+	}, nil)
+}
+
+func (d *EventDispatcher) register(h registeredHandler, opts []HandlerOption) {
+	for _, opt := range opts {
+		opt(&h)
+	}
+	d.mu.Lock()
+	d.handlers = append(d.handlers, h)
+	d.mu.Unlock()
+}
+
+// Publish 分发事件（实现 EventPublisher）
+func (d *EventDispatcher) Publish(ctx context.Context, events ...DomainEvent) error {
+	return d.Dispatch(ctx, events...)
+}
+
+// Dispatch 分发事件
+// 同步处理器的错误合并返回；异步处理器的错误交由 ErrorHandler。
+func (d *EventDispatcher) Dispatch(ctx context.Context, events ...DomainEvent) error {
+	// 处理器在锁外执行：处理器内可注册处理器或再次分发，队列满时阻塞也不会挡住 Close
+	d.mu.RLock()
+	if d.closed {
+		d.mu.RUnlock()
+		return ErrDispatcherClosed
+	}
+	handlers := slices.Clone(d.handlers)
+	d.inflight.Add(1)
+	d.mu.RUnlock()
+	defer d.inflight.Done()
+
+	var errs []error
+	for _, event := range events {
+		for _, h := range handlers {
+			if !h.accept(event) {
+				continue
+			}
+			if h.async {
+				d.pending.Add(1)
+				d.queue <- asyncJob{ctx: context.WithoutCancel(ctx), event: event, handler: h.handler}
+				continue
+			}
+			if err := safeHandle(ctx, h.handler, event); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// DispatchFrom 拉取聚合事件并分发，应在事务提交后调用
+func (d *EventDispatcher) DispatchFrom(ctx context.Context, sources ...EventSource) error {
+	var events []DomainEvent
+	for _, src := range sources {
+		events = append(events, src.PullEvents()...)
+	}
+	if len(events) == 0 {
+		return nil
+	}
+	return d.Dispatch(ctx, events...)
+}
+
+// Close 停止接收事件并等待异步处理完成
+func (d *EventDispatcher) Close() error {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return nil
+	}
+	d.closed = true
+	d.mu.Unlock()
+
+	d.inflight.Wait()
+	close(d.queue)
+	d.wg.Wait()
+	return nil
+}
+
+// Flush 等待已入队的异步处理完成（不停止接收事件），ctx 到期时返回 ctx 错误，
+// 与 Pending 一起满足 runtime.Flusher
+func (d *EventDispatcher) Flush(ctx context.Context) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for d.pending.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// Pending 尚未处理完成的异步任务数
+func (d *EventDispatcher) Pending() int {
+	return int(d.pending.Load())
+}
+
+func (d *EventDispatcher) worker() {
+	defer d.wg.Done()
+	for job := range d.queue {
+		if err := safeHandle(job.ctx, job.handler, job.event); err != nil && d.onError != nil {
+			d.onError(job.ctx, job.event, err)
+		}
+		d.pending.Add(-1)
+	}
+}
+
+func safeHandle(ctx context.Context, handler EventHandler, event DomainEvent) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("ddd: event handler panic on %s: %v", event.EventName(), r)
+		}
+	}()
+	return handler(ctx, event)
+}
+
+var _ EventBus = (*EventDispatcher)(nil)
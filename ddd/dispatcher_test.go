@@ -0,0 +1,128 @@
+package ddd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type userCreated struct{ EventBase }
+
+func TestDispatcher_HandlerMayRegister(t *testing.T) {
+	d := NewEventDispatcher()
+	defer d.Close()
+
+	var nested int
+	On(d, func(ctx context.Context, e userCreated) error {
+		// 处理器内注册新处理器，持锁执行时会死锁
+		On(d, func(context.Context, userCreated) error {
+			nested++
+			return nil
+		})
+		return nil
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- d.Dispatch(context.Background(), userCreated{NewEventBase("user.created", "1", "user")})
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("dispatch deadlocked")
+	}
+
+	if err := d.Dispatch(context.Background(), userCreated{NewEventBase("user.created", "1", "user")}); err != nil {
+		t.Fatal(err)
+	}
+	if nested != 1 {
+		t.Fatalf("nested handler ran %d times", nested)
+	}
+}
+
+func TestDispatcher_CloseWaitsForInflightDispatch(t *testing.T) {
+	d := NewEventDispatcher(WithDispatchWorkers(1), WithDispatchQueueSize(0))
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	handled := make(chan struct{}, 1)
+	On(d, func(context.Context, userCreated) error {
+		close(started)
+		<-release
+		return nil
+	})
+	On(d, func(context.Context, userCreated) error {
+		handled <- struct{}{}
+		return nil
+	}, Async())
+
+	go func() { _ = d.Dispatch(context.Background(), userCreated{NewEventBase("user.created", "1", "user")}) }()
+	<-started
+
+	closed := make(chan struct{})
+	go func() {
+		_ = d.Close()
+		close(closed)
+	}()
+	eventuallyClosed := func() bool {
+		d.mu.RLock()
+		defer d.mu.RUnlock()
+		return d.closed
+	}
+	for !eventuallyClosed() {
+		time.Sleep(time.Millisecond)
+	}
+	if err := d.Dispatch(context.Background(), userCreated{}); !errors.Is(err, ErrDispatcherClosed) {
+		t.Fatalf("dispatch after close = %v", err)
+	}
+
+	// 进行中的分发可继续入队，Close 等待其完成后再关闭队列
+	close(release)
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("close did not return")
+	}
+	select {
+	case <-handled:
+	default:
+		t.Fatal("async handler of in-flight dispatch was dropped")
+	}
+}
+
+func TestDispatcher_FlushWaitsForAsyncHandlers(t *testing.T) {
+	d := NewEventDispatcher(WithDispatchWorkers(1))
+	defer d.Close()
+
+	release := make(chan struct{})
+	On(d, func(context.Context, userCreated) error {
+		<-release
+		return nil
+	}, Async())
+	for range 3 {
+		if err := d.Dispatch(context.Background(), userCreated{NewEventBase("user.created", "1", "user")}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := d.Pending(); n != 3 {
+		t.Fatalf("pending = %d", n)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := d.Flush(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("flush with blocked handlers = %v", err)
+	}
+
+	close(release)
+	if err := d.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := d.Pending(); n != 0 {
+		t.Fatalf("pending after flush = %d", n)
+	}
+}
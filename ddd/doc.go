@@ -1,13 +1,13 @@
 // Package ddd 提供领域驱动设计（DDD）战术模式基础设施。
 //
 // 核心概念：
-//   - Entity（实体）
+//   - Entity（实体），Audit 审计字段混入
 //   - ValueObject（值对象）
 //   - AggregateRoot（聚合根）
-//   - DomainEvent（领域事件）
-//   - Repository（仓储）
-//   - Specification（规约）
-//   - UnitOfWork（工作单元）
+//   - DomainEvent（领域事件），EventDispatcher 进程内分发
+//   - Repository（仓储），支持软删除（SoftDelete）
+//   - Specification（规约），可编译为 GORM 条件与 MongoDB 过滤器
+//   - UnitOfWork（工作单元），事务经 context 传递，提交后统一收集聚合事件
 //   - DomainService（领域服务）
 //
 // 使用示例：
@@ -0,0 +1,47 @@
+package ddd
+
+import (
+	"context"
+	"time"
+)
+
+// SoftDelete 软删除字段混入
+type SoftDelete struct {
+	DeletedAt *time.Time `gorm:"index"`
+}
+
+// SoftDeletable 支持软删除的对象
+type SoftDeletable interface {
+	IsDeleted() bool
+	MarkDeleted(at time.Time)
+	Restore()
+}
+
+// IsDeleted 是否已删除
+func (s *SoftDelete) IsDeleted() bool { return s.DeletedAt != nil }
+
+// MarkDeleted 标记删除
+func (s *SoftDelete) MarkDeleted(at time.Time) { s.DeletedAt = &at }
+
+// Restore 恢复
+func (s *SoftDelete) Restore() { s.DeletedAt = nil }
+
+// SoftDeleteRepository 支持软删除的仓储
+// Delete 仅做标记，常规查询自动排除已删除记录。
+type SoftDeleteRepository[T any, ID Identifier] interface {
+	Repository[T, ID]
+	// FindIncludingDeleted 查找（包含已删除）
+	FindIncludingDeleted(ctx context.Context, id ID) (*T, error)
+	// Restore 恢复已删除记录
+	Restore(ctx context.Context, id ID) error
+}
+
+// DeletedAtField 软删除字段名
+const DeletedAtField = "deleted_at"
+
+// NotDeleted 未删除规约，可下推到数据库
+func NotDeleted[T SoftDeletable]() Specification[T] {
+	return Where(DeletedAtField, OpIsNull, nil, func(e T) bool { return !e.IsDeleted() })
+}
+
+var _ SoftDeletable = (*SoftDelete)(nil)
@@ -0,0 +1,37 @@
+package ddd
+
+import (
+	"testing"
+	"time"
+)
+
+type softDeletedItem struct {
+	Name string
+	SoftDelete
+}
+
+func TestNotDeleted(t *testing.T) {
+	spec := NotDeleted[*softDeletedItem]()
+	item := &softDeletedItem{Name: "pen"}
+
+	if !spec.IsSatisfiedBy(item) {
+		t.Fatal("live item excluded")
+	}
+	item.MarkDeleted(time.Now())
+	if spec.IsSatisfiedBy(item) || !item.IsDeleted() {
+		t.Fatal("deleted item matched")
+	}
+	item.Restore()
+	if !spec.IsSatisfiedBy(item) {
+		t.Fatal("restored item excluded")
+	}
+
+	// 与其他规约组合后仍可下推
+	c, ok := CriteriaOf(And(spec, Eq("name", "pen", func(i *softDeletedItem) string { return i.Name })))
+	if !ok || c.Op != OpAnd || len(c.Children) != 2 {
+		t.Fatalf("criteria = %+v, %v", c, ok)
+	}
+	if first := c.Children[0]; first.Op != OpIsNull || first.Field != DeletedAtField {
+		t.Fatalf("not deleted criteria = %+v", first)
+	}
+}
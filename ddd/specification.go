@@ -1,5 +1,10 @@
 package ddd
 
+import (
+	"cmp"
+	"slices"
+)
+
 // Specification 规约接口
 // 用于封装业务规则，支持组合
 type Specification[T any] interface {
@@ -18,45 +23,164 @@ func Spec[T any](fn func(T) bool) Specification[T] {
 
 // And 与组合
 func And[T any](left, right Specification[T]) Specification[T] {
-	return SpecFunc[T](func(entity T) bool {
-		return left.IsSatisfiedBy(entity) && right.IsSatisfiedBy(entity)
-	})
+	return &compositeSpec[T]{op: OpAnd, specs: []Specification[T]{left, right}}
 }
 
 // Or 或组合
 func Or[T any](left, right Specification[T]) Specification[T] {
-	return SpecFunc[T](func(entity T) bool {
-		return left.IsSatisfiedBy(entity) || right.IsSatisfiedBy(entity)
-	})
+	return &compositeSpec[T]{op: OpOr, specs: []Specification[T]{left, right}}
 }
 
 // Not 取反
 func Not[T any](spec Specification[T]) Specification[T] {
-	return SpecFunc[T](func(entity T) bool {
-		return !spec.IsSatisfiedBy(entity)
-	})
+	return &compositeSpec[T]{op: OpNot, specs: []Specification[T]{spec}}
 }
 
 // All 全部满足
 func All[T any](specs ...Specification[T]) Specification[T] {
-	return SpecFunc[T](func(entity T) bool {
-		for _, spec := range specs {
-			if !spec.IsSatisfiedBy(entity) {
-				return false
-			}
-		}
-		return true
-	})
+	return &compositeSpec[T]{op: OpAnd, specs: specs}
 }
 
 // Any 任一满足
 func Any[T any](specs ...Specification[T]) Specification[T] {
-	return SpecFunc[T](func(entity T) bool {
-		for _, spec := range specs {
+	return &compositeSpec[T]{op: OpOr, specs: specs}
+}
+
+// ========== 可查询规约 ==========
+
+// Operator 条件操作符
+type Operator string
+
+const (
+	OpEq     Operator = "eq"
+	OpNe     Operator = "ne"
+	OpGt     Operator = "gt"
+	OpGte    Operator = "gte"
+	OpLt     Operator = "lt"
+	OpLte    Operator = "lte"
+	OpIn     Operator = "in"
+	OpLike   Operator = "like"
+	OpIsNull Operator = "is_null"
+
+	OpAnd Operator = "and"
+	OpOr  Operator = "or"
+	OpNot Operator = "not"
+)
+
+// Criteria 查询条件树，由存储层编译为 SQL / MongoDB 过滤器
+type Criteria struct {
+	Op       Operator
+	Field    string
+	Value    any
+	Children []Criteria
+}
+
+// Queryable 可下推到数据库的规约
+type Queryable interface {
+	Criteria() (Criteria, bool)
+}
+
+// CriteriaOf 提取规约的查询条件，存在不可查询的子规约时返回 false
+func CriteriaOf[T any](spec Specification[T]) (Criteria, bool) {
+	q, ok := spec.(Queryable)
+	if !ok {
+		return Criteria{}, false
+	}
+	return q.Criteria()
+}
+
+// fieldSpec 字段条件规约
+type fieldSpec[T any] struct {
+	criteria Criteria
+	fn       func(T) bool
+}
+
+func (s *fieldSpec[T]) IsSatisfiedBy(entity T) bool { return s.fn(entity) }
+
+func (s *fieldSpec[T]) Criteria() (Criteria, bool) { return s.criteria, true }
+
+// Where 创建字段条件规约，fn 用于内存判断
+func Where[T any](field string, op Operator, value any, fn func(T) bool) Specification[T] {
+	return &fieldSpec[T]{
+		criteria: Criteria{Op: op, Field: field, Value: value},
+		fn:       fn,
+	}
+}
+
+// Eq 等于
+func Eq[T any, V comparable](field string, value V, get func(T) V) Specification[T] {
+	return Where(field, OpEq, value, func(e T) bool { return get(e) == value })
+}
+
+// Ne 不等于
+func Ne[T any, V comparable](field string, value V, get func(T) V) Specification[T] {
+	return Where(field, OpNe, value, func(e T) bool { return get(e) != value })
+}
+
+// Gt 大于
+func Gt[T any, V cmp.Ordered](field string, value V, get func(T) V) Specification[T] {
+	return Where(field, OpGt, value, func(e T) bool { return get(e) > value })
+}
+
+// Gte 大于等于
+func Gte[T any, V cmp.Ordered](field string, value V, get func(T) V) Specification[T] {
+	return Where(field, OpGte, value, func(e T) bool { return get(e) >= value })
+}
+
+// Lt 小于
+func Lt[T any, V cmp.Ordered](field string, value V, get func(T) V) Specification[T] {
+	return Where(field, OpLt, value, func(e T) bool { return get(e) < value })
+}
+
+// Lte 小于等于
+func Lte[T any, V cmp.Ordered](field string, value V, get func(T) V) Specification[T] {
+	return Where(field, OpLte, value, func(e T) bool { return get(e) <= value })
+}
+
+// In 属于集合
+func In[T any, V comparable](field string, values []V, get func(T) V) Specification[T] {
+	vals := make([]any, len(values))
+	for i, v := range values {
+		vals[i] = v
+	}
+	return Where(field, OpIn, vals, func(e T) bool { return slices.Contains(values, get(e)) })
+}
+
+// compositeSpec 组合规约
+type compositeSpec[T any] struct {
+	op    Operator
+	specs []Specification[T]
+}
+
+func (s *compositeSpec[T]) IsSatisfiedBy(entity T) bool {
+	switch s.op {
+	case OpNot:
+		return !s.specs[0].IsSatisfiedBy(entity)
+	case OpOr:
+		for _, spec := range s.specs {
 			if spec.IsSatisfiedBy(entity) {
 				return true
 			}
 		}
 		return false
-	})
+	default:
+		for _, spec := range s.specs {
+			if !spec.IsSatisfiedBy(entity) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func (s *compositeSpec[T]) Criteria() (Criteria, bool) {
+	c := Criteria{Op: s.op, Children: make([]Criteria, 0, len(s.specs))}
+	for _, spec := range s.specs {
+		child, ok := CriteriaOf(spec)
+		if !ok {
+			return Criteria{}, false
+		}
+		c.Children = append(c.Children, child)
+	}
+	return c, true
 }
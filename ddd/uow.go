@@ -1,6 +1,9 @@
 package ddd
 
-import "context"
+import (
+	"context"
+	"sync"
+)
 
 // UnitOfWork 工作单元接口
 // 用于管理事务边界，确保聚合的一致性
@@ -62,3 +65,109 @@ func TransactionalWithResult[T any](ctx context.Context, uow UnitOfWork, fn func
 
 	return result, nil
 }
+
+// EventCollector 收集事务内触达的聚合，提交后统一拉取事件
+type EventCollector struct {
+	mu      sync.Mutex
+	sources []EventSource
+	seen    map[EventSource]struct{}
+	events  []DomainEvent // Mark 时从已登记聚合暂存的事件
+}
+
+// CollectorMark 收集器位置标记（用于回滚到保存点）
+type CollectorMark struct {
+	sources int
+	events  int
+}
+
+// NewEventCollector 创建事件收集器
+func NewEventCollector() *EventCollector {
+	return &EventCollector{seen: make(map[EventSource]struct{})}
+}
+
+type eventCollectorKey struct{}
+
+// WithEventCollector 设置事件收集器到 context
+func WithEventCollector(ctx context.Context, c *EventCollector) context.Context {
+	return context.WithValue(ctx, eventCollectorKey{}, c)
+}
+
+// EventCollectorFromContext 从 context 获取事件收集器
+func EventCollectorFromContext(ctx context.Context) (*EventCollector, bool) {
+	c, ok := ctx.Value(eventCollectorKey{}).(*EventCollector)
+	return c, ok
+}
+
+// Track 登记被触达的聚合，context 中无收集器时忽略
+func Track(ctx context.Context, sources ...EventSource) {
+	if c, ok := EventCollectorFromContext(ctx); ok {
+		c.Track(sources...)
+	}
+}
+
+// Track 登记聚合（重复登记忽略）
+func (c *EventCollector) Track(sources ...EventSource) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, src := range sources {
+		if _, ok := c.seen[src]; ok {
+			continue
+		}
+		c.seen[src] = struct{}{}
+		c.sources = append(c.sources, src)
+	}
+}
+
+// Len 已登记聚合数量
+func (c *EventCollector) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.sources)
+}
+
+// Mark 记录当前位置
+// 已登记聚合的待发布事件被暂存，之后产生的事件可由 Truncate 丢弃。
+func (c *EventCollector) Mark() CollectorMark {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, src := range c.sources {
+		c.events = append(c.events, src.PullEvents()...)
+	}
+	return CollectorMark{sources: len(c.sources), events: len(c.events)}
+}
+
+// Truncate 回滚到标记位置：丢弃标记后登记的聚合，
+// 以及标记前已登记聚合在标记后产生的事件
+func (c *EventCollector) Truncate(m CollectorMark) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, src := range c.sources[:min(m.sources, len(c.sources))] {
+		src.PullEvents()
+	}
+	if m.events < len(c.events) {
+		c.events = c.events[:m.events]
+	}
+	if m.sources >= len(c.sources) {
+		return
+	}
+	for _, src := range c.sources[m.sources:] {
+		delete(c.seen, src)
+	}
+	c.sources = c.sources[:m.sources]
+}
+
+// Pull 拉取所有聚合的事件并清空登记
+func (c *EventCollector) Pull() []DomainEvent {
+	c.mu.Lock()
+	sources := c.sources
+	events := c.events
+	c.sources = nil
+	c.events = nil
+	c.seen = make(map[EventSource]struct{})
+	c.mu.Unlock()
+
+	for _, src := range sources {
+		events = append(events, src.PullEvents()...)
+	}
+	return events
+}
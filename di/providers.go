@@ -11,6 +11,7 @@ import (
 	"github.com/mildsunup/higo/config"
 	pkglogger "github.com/mildsunup/higo/logger"
 	"github.com/mildsunup/higo/observability"
+	"github.com/mildsunup/higo/runtime"
 	"github.com/mildsunup/higo/storage"
 	"github.com/mildsunup/higo/storage/mongodb"
 	"github.com/mildsunup/higo/storage/mysql"
@@ -28,6 +29,19 @@ func ProvideLogger(cfg *config.Config) (pkglogger.Logger, error) {
 	})
 }
 
+// ProvideAppInfo 由 config.App 生成应用元数据，配合 runtime.WithAppInfo 使用
+func ProvideAppInfo(cfg *config.Config) runtime.AppInfo {
+	return runtime.NewAppInfo(cfg.App.Name, cfg.App.Env, cfg.App.Version)
+}
+
+// ProvideApp 创建应用运行时，元数据（含实例 ID）使用 ProvideAppInfo 的结果，
+// 与心跳、/version 等共享同一实例标识
+func ProvideApp(cfg *config.Config, log pkglogger.Logger, info runtime.AppInfo) *runtime.App {
+	rc := runtime.DefaultConfig()
+	rc.Name, rc.Env, rc.Version = cfg.App.Name, cfg.App.Env, cfg.App.Version
+	return runtime.New(rc, runtime.WithLogger(log), runtime.WithAppInfo(info))
+}
+
 // ProvideAOPChain provides AOP interceptor chain with default aspects
 func ProvideAOPChain(log pkglogger.Logger) *aop.Chain {
 	return aop.NewChain(
@@ -39,8 +53,12 @@ func ProvideAOPChain(log pkglogger.Logger) *aop.Chain {
 
 // ProvideObservability provides observability instance
 func ProvideObservability(cfg *config.Config) (*observability.Observability, error) {
+	serviceName := cfg.Observability.Tracing.ServiceName
+	if serviceName == "" {
+		serviceName = cfg.App.Name
+	}
 	return observability.New(observability.Config{
-		ServiceName:    cfg.Observability.Tracing.ServiceName,
+		ServiceName:    serviceName,
 		ServiceVersion: cfg.App.Version,
 		Environment:    cfg.App.Env,
 		Tracing: observability.TracingConfig{
@@ -52,8 +70,13 @@ func ProvideObservability(cfg *config.Config) (*observability.Observability, err
 			Ratio:    cfg.Observability.Tracing.SampleRate,
 		},
 		Metrics: observability.MetricsConfig{
-			Enabled: true,
-			Path:    "/metrics",
+			Enabled:       true,
+			Path:          cfg.Observability.Metrics.Path,
+			Instance:      cfg.Observability.Metrics.Instance,
+			ConstLabels:   cfg.Observability.Metrics.ConstLabels,
+			Discovery:     cfg.Observability.Metrics.Discovery,
+			DiscoveryPath: "/metrics/targets",
+			PortName:      cfg.Observability.Metrics.PortName,
 		},
 	})
 }
@@ -77,6 +100,20 @@ func ProvideMySQL(cfg *config.Config, tp trace.TracerProvider) *mysql.Storage {
 		ConnMaxLifetime: cfg.Storage.MySQL.ConnMaxLifetime,
 		LogLevel:        logger.LogLevel(cfg.Storage.MySQL.LogLevel),
 		Replicas:        cfg.Storage.MySQL.Replicas,
+		SQLCommenter: mysql.SQLCommenterConfig{
+			Enabled:     cfg.Storage.MySQL.SQLCommenter,
+			Application: cfg.App.Name,
+		},
+	}
+	for _, sc := range cfg.Storage.MySQL.Sharding {
+		mysqlConfig.Sharding = append(mysqlConfig.Sharding, mysql.ShardingConfig{
+			Table:          sc.Table,
+			ShardKey:       sc.ShardKey,
+			ShardCount:     sc.ShardCount,
+			Algorithm:      sc.Algorithm,
+			DatabaseCount:  sc.DatabaseCount,
+			DatabaseFormat: sc.DatabaseFormat,
+		})
 	}
 
 	var opts []mysql.Option
@@ -215,6 +252,8 @@ var ConfigSet = wire.NewSet(
 // InfraSet 是基础设施的 Provider Set
 var InfraSet = wire.NewSet(
 	ProvideLogger,
+	ProvideAppInfo,
+	ProvideApp,
 	ProvideObservability,
 	ProvideTracerProvider,
 	ProvideStorageMetrics,
@@ -0,0 +1,19 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/mildsunup/higo/config"
+	pkglogger "github.com/mildsunup/higo/logger"
+)
+
+func TestProvideApp_SharesAppInfo(t *testing.T) {
+	cfg := &config.Config{App: config.AppConfig{Name: "billing", Env: "prod", Version: "v1.2.3"}}
+	info := ProvideAppInfo(cfg)
+	app := ProvideApp(cfg, pkglogger.Nop(), info)
+
+	got := app.Info()
+	if got.InstanceID != info.InstanceID || got.Name != "billing" || got.Version != "v1.2.3" {
+		t.Fatalf("app info = %+v, want %+v", got, info)
+	}
+}
@@ -0,0 +1,283 @@
+// Package capture 提供按采样记录完整请求/响应的调试抓包。
+//
+// 默认关闭，通过管理接口按时间窗口开启（如 10 分钟、采样 10%），被采样的 HTTP/gRPC 交互
+// （头、体，敏感字段脱敏，体按上限截断）写入有界环形缓冲并保留最近 Retention 内的记录，
+// 用于排查难以复现的客户端问题，而无需全局开启调试日志。
+//
+//	rec := capture.New(capture.WithSampleRate(0.1), capture.WithRetention(15*time.Minute))
+//	router.Use(rec.Gin())
+//	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(rec.UnaryInterceptor()))
+//
+//	runtime.WithAdmin(cfg.Admin,
+//	    runtime.WithAdminHandler("/capture/", http.StripPrefix("/capture", rec.Handler())))
+//
+//	// curl -X POST 'admin:9091/capture/enable?for=10m&rate=0.2'
+//	// curl 'admin:9091/capture/?since=5m&status=500'
+package capture
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mildsunup/higo/utils"
+)
+
+// Exchange 一次被记录的请求/响应
+type Exchange struct {
+	ID              string              `json:"id"`
+	Protocol        string              `json:"protocol"` // http / grpc
+	Time            time.Time           `json:"time"`
+	Duration        time.Duration       `json:"duration"`
+	Method          string              `json:"method"` // HTTP 方法或 gRPC 完整方法名
+	Path            string              `json:"path,omitempty"`
+	Query           string              `json:"query,omitempty"`
+	Status          int                 `json:"status"` // HTTP 状态码或 gRPC 状态码
+	Error           string              `json:"error,omitempty"`
+	RemoteAddr      string              `json:"remote_addr,omitempty"`
+	RequestHeaders  map[string][]string `json:"request_headers,omitempty"`
+	RequestBody     string              `json:"request_body,omitempty"`
+	ResponseHeaders map[string][]string `json:"response_headers,omitempty"`
+	ResponseBody    string              `json:"response_body,omitempty"`
+	Truncated       bool                `json:"truncated,omitempty"` // 请求或响应体超过上限被截断
+}
+
+// Recorder 调试抓包记录器
+type Recorder struct {
+	mu      sync.Mutex
+	entries []Exchange // 环形缓冲
+	next    int
+	full    bool
+	until   time.Time // 开启截止时间，零值表示关闭
+	rate    float64
+
+	retention     time.Duration
+	maxBody       int
+	redactHeaders []string
+	redactBody    *regexp.Regexp
+	redactForm    *regexp.Regexp
+	now           func() time.Time
+}
+
+// Option 记录器选项
+type Option func(*Recorder)
+
+// WithCapacity 设置最多保留的记录数，默认 500
+func WithCapacity(n int) Option {
+	return func(r *Recorder) {
+		if n > 0 {
+			r.entries = make([]Exchange, n)
+		}
+	}
+}
+
+// WithSampleRate 设置默认采样率（0,1]，默认 0.01；开启时可通过接口覆盖
+func WithSampleRate(rate float64) Option {
+	return func(r *Recorder) {
+		if rate > 0 && rate <= 1 {
+			r.rate = rate
+		}
+	}
+}
+
+// WithRetention 设置记录保留时长，默认 15 分钟
+func WithRetention(d time.Duration) Option {
+	return func(r *Recorder) {
+		if d > 0 {
+			r.retention = d
+		}
+	}
+}
+
+// WithMaxBody 设置每个请求/响应体记录的最大字节数，默认 64KiB
+func WithMaxBody(n int) Option {
+	return func(r *Recorder) {
+		if n > 0 {
+			r.maxBody = n
+		}
+	}
+}
+
+// WithRedactHeaders 追加需脱敏的头（大小写不敏感），默认包含 Authorization、Cookie、Set-Cookie、X-Api-Key
+func WithRedactHeaders(names ...string) Option {
+	return func(r *Recorder) {
+		for _, n := range names {
+			r.redactHeaders = append(r.redactHeaders, http.CanonicalHeaderKey(n))
+		}
+	}
+}
+
+// WithRedactFields 追加需脱敏的 JSON/表单字段名片段（大小写不敏感），
+// 默认包含 password、secret、token、apikey、api_key、credential、card、cvv
+func WithRedactFields(fragments ...string) Option {
+	return func(r *Recorder) {
+		r.setRedactFields(append(defaultRedactFields, fragments...))
+	}
+}
+
+// WithEnabledFor 创建后即开启 d 时长（如在预发环境常开）
+func WithEnabledFor(d time.Duration) Option {
+	return func(r *Recorder) {
+		r.until = r.now().Add(d)
+	}
+}
+
+var defaultRedactFields = []string{"password", "secret", "token", "apikey", "api_key", "credential", "card", "cvv"}
+
+// New 创建记录器，默认关闭
+func New(opts ...Option) *Recorder {
+	r := &Recorder{
+		entries:       make([]Exchange, 500),
+		rate:          0.01,
+		retention:     15 * time.Minute,
+		maxBody:       64 << 10,
+		redactHeaders: []string{"Authorization", "Cookie", "Set-Cookie", "X-Api-Key", "Proxy-Authorization"},
+		now:           time.Now,
+	}
+	r.setRedactFields(defaultRedactFields)
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+func (r *Recorder) setRedactFields(fragments []string) {
+	quoted := make([]string, len(fragments))
+	for i, f := range fragments {
+		quoted[i] = regexp.QuoteMeta(f)
+	}
+	alt := strings.Join(quoted, "|")
+	// JSON："password": "..." -> "password": "******"（截断的 JSON 同样适用）
+	r.redactBody = regexp.MustCompile(`(?i)("[^"]*(?:` + alt + `)[^"]*"\s*:\s*)(?:"(?:[^"\\]|\\.)*"?|[-\w.+]+)`)
+	// 表单与查询串：password=... -> password=******
+	r.redactForm = regexp.MustCompile(`(?i)((?:^|&)[^=&]*(?:` + alt + `)[^=&]*=)[^&]*`)
+}
+
+// Enable 开启抓包 d 时长（d ≤ 0 时为 1 小时），rate 为 0 时使用默认采样率
+func (r *Recorder) Enable(d time.Duration, rate float64) {
+	if d <= 0 {
+		d = time.Hour
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.until = r.now().Add(d)
+	if rate > 0 && rate <= 1 {
+		r.rate = rate
+	}
+}
+
+// Disable 关闭抓包，已记录的数据保留到过期
+func (r *Recorder) Disable() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.until = time.Time{}
+}
+
+// Enabled 是否处于开启状态
+func (r *Recorder) Enabled() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.now().Before(r.until)
+}
+
+// sample 本次交互是否需要记录
+func (r *Recorder) sample() bool {
+	r.mu.Lock()
+	on, rate := r.now().Before(r.until), r.rate
+	r.mu.Unlock()
+	return on && rand.Float64() < rate
+}
+
+// record 写入一条记录，覆盖最旧的记录
+func (r *Recorder) record(e Exchange) {
+	e.ID = utils.ULID()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[r.next] = e
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// List 返回最近 since 内（不超过保留时长）的记录，按时间倒序；since ≤ 0 表示整个保留期
+func (r *Recorder) List(since time.Duration) []Exchange {
+	if since <= 0 || since > r.retention {
+		since = r.retention
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cutoff := r.now().Add(-since)
+	n := r.next
+	if r.full {
+		n = len(r.entries)
+	}
+	list := make([]Exchange, 0, n)
+	for i := range n {
+		e := r.entries[(r.next-1-i+len(r.entries))%len(r.entries)]
+		if e.Time.Before(cutoff) {
+			break
+		}
+		list = append(list, e)
+	}
+	return list
+}
+
+// Get 按 ID 获取记录
+func (r *Recorder) Get(id string) (Exchange, bool) {
+	for _, e := range r.List(0) {
+		if e.ID == id {
+			return e, true
+		}
+	}
+	return Exchange{}, false
+}
+
+// Clear 清空记录
+func (r *Recorder) Clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	clear(r.entries)
+	r.next, r.full = 0, false
+}
+
+// headers 复制并脱敏头
+func (r *Recorder) headers(h map[string][]string) map[string][]string {
+	if len(h) == 0 {
+		return nil
+	}
+	out := make(map[string][]string, len(h))
+	for k, v := range h {
+		if slices.Contains(r.redactHeaders, http.CanonicalHeaderKey(k)) {
+			out[k] = []string{"******"}
+			continue
+		}
+		out[k] = slices.Clone(v)
+	}
+	return out
+}
+
+// body 脱敏并转为可读文本，二进制内容只记录类型
+func (r *Recorder) body(b []byte, contentType string) string {
+	if len(b) == 0 {
+		return ""
+	}
+	if !utf8.Valid(b) && !strings.Contains(contentType, "json") {
+		return "<binary " + http.DetectContentType(b) + ">"
+	}
+	s := string(b)
+	if strings.Contains(contentType, "x-www-form-urlencoded") {
+		return r.redactForm.ReplaceAllString(s, `${1}******`)
+	}
+	return r.redactBody.ReplaceAllString(s, `${1}"******"`)
+}
+
+// query 脱敏查询串
+func (r *Recorder) query(q string) string {
+	return r.redactForm.ReplaceAllString(q, `${1}******`)
+}
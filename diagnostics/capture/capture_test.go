@@ -0,0 +1,112 @@
+package capture
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRecorder_Gin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := New(WithSampleRate(1), WithMaxBody(64))
+	r := gin.New()
+	r.Use(rec.Gin())
+	r.POST("/login", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.JSON(http.StatusUnauthorized, gin.H{"echo_len": len(body), "token": "t-123"})
+	})
+
+	do := func() {
+		req := httptest.NewRequest(http.MethodPost, "/login?api_key=k1&x=1", strings.NewReader(`{"user":"ada","password":"hunter2","pin":1234}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer abc")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	do()
+	if got := rec.List(0); len(got) != 0 {
+		t.Fatalf("disabled recorder captured %d entries", len(got))
+	}
+
+	rec.Enable(time.Minute, 0)
+	do()
+	list := rec.List(0)
+	if len(list) != 1 {
+		t.Fatalf("List() = %d entries, want 1", len(list))
+	}
+	e := list[0]
+	if e.Status != http.StatusUnauthorized || e.Path != "/login" || e.Query != "api_key=******&x=1" {
+		t.Errorf("exchange = %+v", e)
+	}
+	if e.RequestHeaders["Authorization"][0] != "******" {
+		t.Errorf("authorization not redacted: %v", e.RequestHeaders)
+	}
+	if strings.Contains(e.RequestBody, "hunter2") || !strings.Contains(e.RequestBody, `"user":"ada"`) {
+		t.Errorf("request body = %s", e.RequestBody)
+	}
+	if !strings.Contains(e.ResponseBody, `"echo_len":46`) || strings.Contains(e.ResponseBody, "t-123") {
+		t.Errorf("response body = %s", e.ResponseBody)
+	}
+	if got, ok := rec.Get(e.ID); !ok || got.ID != e.ID {
+		t.Error("Get() did not find entry")
+	}
+}
+
+func TestRecorder_Middleware(t *testing.T) {
+	rec := New(WithSampleRate(1), WithMaxBody(8), WithCapacity(2), WithEnabledFor(time.Minute))
+	h := rec.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = io.WriteString(w, "0123456789")
+	}))
+	for range 3 {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items", nil))
+	}
+	list := rec.List(0)
+	if len(list) != 2 {
+		t.Fatalf("List() = %d entries, want capacity 2", len(list))
+	}
+	if e := list[0]; e.Status != http.StatusCreated || e.ResponseBody != "01234567" || !e.Truncated {
+		t.Errorf("exchange = %+v", e)
+	}
+
+	rec.now = func() time.Time { return time.Now().Add(time.Hour) }
+	if len(rec.List(0)) != 0 || rec.Enabled() {
+		t.Error("entries should expire after retention and capture should turn off")
+	}
+}
+
+func TestRecorder_Handler(t *testing.T) {
+	rec := New()
+	h := rec.Handler()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/enable?for=5m&rate=0.5", nil))
+	var s recorderStatus
+	_ = json.NewDecoder(w.Body).Decode(&s)
+	if w.Code != http.StatusOK || !s.Enabled || s.SampleRate != 0.5 {
+		t.Errorf("enable = %d %+v", w.Code, s)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/enable?rate=2", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("invalid rate code = %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("get missing code = %d", w.Code)
+	}
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/disable", nil))
+	if rec.Enabled() {
+		t.Error("disable did not turn off capture")
+	}
+}
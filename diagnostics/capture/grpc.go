@@ -0,0 +1,81 @@
+package capture
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// UnaryInterceptor 返回 gRPC 一元拦截器，请求与响应消息以 protojson 记录
+func (r *Recorder) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !r.sample() {
+			return handler(ctx, req)
+		}
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		e := r.grpcExchange(ctx, info.FullMethod, start, err)
+		var reqTrunc, respTrunc bool
+		e.RequestBody, reqTrunc = r.message(req)
+		if err == nil {
+			e.ResponseBody, respTrunc = r.message(resp)
+		}
+		e.Truncated = reqTrunc || respTrunc
+		r.record(e)
+		return resp, err
+	}
+}
+
+// StreamInterceptor 返回 gRPC 流拦截器，只记录元数据、状态与耗时（不记录消息）
+func (r *Recorder) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !r.sample() {
+			return handler(srv, ss)
+		}
+		start := time.Now()
+		err := handler(srv, ss)
+		r.record(r.grpcExchange(ss.Context(), info.FullMethod, start, err))
+		return err
+	}
+}
+
+func (r *Recorder) grpcExchange(ctx context.Context, method string, start time.Time, err error) Exchange {
+	e := Exchange{
+		Protocol: "grpc",
+		Time:     start,
+		Duration: time.Since(start),
+		Method:   method,
+		Status:   int(status.Code(err)),
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		e.RequestHeaders = r.headers(md)
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		e.RemoteAddr = p.Addr.String()
+	}
+	return e
+}
+
+// message 将消息编码为脱敏后的文本，超过上限时截断
+func (r *Recorder) message(m any) (string, bool) {
+	var b []byte
+	if pm, ok := m.(proto.Message); ok {
+		b, _ = protojson.Marshal(pm)
+	} else if m != nil {
+		b = fmt.Appendf(nil, "%+v", m)
+	}
+	buf := limitedBuffer{max: r.maxBody}
+	_, _ = buf.Write(b)
+	return r.body(buf.Bytes(), "application/json"), buf.truncated
+}
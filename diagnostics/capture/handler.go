@@ -0,0 +1,107 @@
+package capture
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mildsunup/higo/errors"
+	"github.com/mildsunup/higo/response"
+)
+
+// Handler 返回抓包管理接口，挂载时需去掉前缀：
+//
+//	GET    /          列出最近的记录，支持 ?since=5m&protocol=http&method=POST&path=/orders&status=500 过滤
+//	GET    /status    开启状态
+//	GET    /{id}      查看单条记录
+//	POST   /enable    开启抓包，?for=10m&rate=0.1（默认 1 小时、默认采样率）
+//	POST   /disable   关闭抓包
+//	DELETE /          清空记录
+//
+// 记录包含请求/响应内容，接口应挂载在管理端口或受保护的路由下。
+func (r *Recorder) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", r.list)
+	mux.HandleFunc("DELETE /{$}", func(w http.ResponseWriter, _ *http.Request) {
+		r.Clear()
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, _ *http.Request) {
+		response.WriteJSON(w, http.StatusOK, r.status())
+	})
+	mux.HandleFunc("POST /enable", r.enable)
+	mux.HandleFunc("POST /disable", func(w http.ResponseWriter, _ *http.Request) {
+		r.Disable()
+		response.WriteJSON(w, http.StatusOK, r.status())
+	})
+	mux.HandleFunc("GET /{id}", func(w http.ResponseWriter, req *http.Request) {
+		e, ok := r.Get(req.PathValue("id"))
+		if !ok {
+			response.WriteError(w, req, errors.New(errors.NotFound, "capture: not found"))
+			return
+		}
+		response.WriteJSON(w, http.StatusOK, e)
+	})
+	return mux
+}
+
+type recorderStatus struct {
+	Enabled    bool      `json:"enabled"`
+	Until      time.Time `json:"until,omitzero"`
+	SampleRate float64   `json:"sample_rate"`
+	Entries    int       `json:"entries"`
+}
+
+func (r *Recorder) status() recorderStatus {
+	entries := len(r.List(0))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := recorderStatus{SampleRate: r.rate, Entries: entries}
+	if r.now().Before(r.until) {
+		s.Enabled, s.Until = true, r.until
+	}
+	return s
+}
+
+func (r *Recorder) list(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+	var since time.Duration
+	if v := q.Get("since"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			response.WriteError(w, req, errors.New(errors.InvalidArgument, "capture: invalid since"))
+			return
+		}
+		since = d
+	}
+	list := slices.DeleteFunc(r.List(since), func(e Exchange) bool {
+		return q.Has("protocol") && e.Protocol != q.Get("protocol") ||
+			q.Has("method") && !strings.EqualFold(e.Method, q.Get("method")) ||
+			q.Has("path") && !strings.HasPrefix(e.Path, q.Get("path")) ||
+			q.Has("status") && strconv.Itoa(e.Status) != q.Get("status")
+	})
+	response.WriteJSON(w, http.StatusOK, list)
+}
+
+func (r *Recorder) enable(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+	var d time.Duration
+	var rate float64
+	var err error
+	if v := q.Get("for"); v != "" {
+		if d, err = time.ParseDuration(v); err != nil {
+			response.WriteError(w, req, errors.New(errors.InvalidArgument, "capture: invalid duration"))
+			return
+		}
+	}
+	if v := q.Get("rate"); v != "" {
+		if rate, err = strconv.ParseFloat(v, 64); err != nil || rate <= 0 || rate > 1 {
+			response.WriteError(w, req, errors.New(errors.InvalidArgument, "capture: rate must be in (0, 1]"))
+			return
+		}
+	}
+	r.Enable(d, rate)
+	response.WriteJSON(w, http.StatusOK, r.status())
+}
@@ -0,0 +1,143 @@
+package capture
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Gin 返回 Gin 中间件，开启期间按采样率记录请求与响应
+func (r *Recorder) Gin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !r.sample() {
+			c.Next()
+			return
+		}
+		start := time.Now()
+		reqBody := r.readBody(c.Request)
+		w := &ginWriter{ResponseWriter: c.Writer, buf: limitedBuffer{max: r.maxBody}}
+		c.Writer = w
+		c.Next()
+
+		e := r.httpExchange(c.Request, start, reqBody, w.Status(), w.Header(), &w.buf)
+		if len(c.Errors) > 0 {
+			e.Error = c.Errors.String()
+		}
+		r.record(e)
+	}
+}
+
+// Middleware 返回 net/http 中间件，开启期间按采样率记录请求与响应
+func (r *Recorder) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !r.sample() {
+			next.ServeHTTP(w, req)
+			return
+		}
+		start := time.Now()
+		reqBody := r.readBody(req)
+		rw := &responseWriter{ResponseWriter: w, status: http.StatusOK, buf: limitedBuffer{max: r.maxBody}}
+		next.ServeHTTP(rw, req)
+		r.record(r.httpExchange(req, start, reqBody, rw.status, w.Header(), &rw.buf))
+	})
+}
+
+func (r *Recorder) httpExchange(req *http.Request, start time.Time, reqBody limitedBuffer, status int, respHeader http.Header, respBody *limitedBuffer) Exchange {
+	return Exchange{
+		Protocol:        "http",
+		Time:            start,
+		Duration:        time.Since(start),
+		Method:          req.Method,
+		Path:            req.URL.Path,
+		Query:           r.query(req.URL.RawQuery),
+		Status:          status,
+		RemoteAddr:      req.RemoteAddr,
+		RequestHeaders:  r.headers(req.Header),
+		RequestBody:     r.body(reqBody.Bytes(), req.Header.Get("Content-Type")),
+		ResponseHeaders: r.headers(respHeader),
+		ResponseBody:    r.body(respBody.Bytes(), respHeader.Get("Content-Type")),
+		Truncated:       reqBody.truncated || respBody.truncated,
+	}
+}
+
+// readBody 读取不超过上限的请求体并还原 req.Body，处理器仍能读取完整内容
+func (r *Recorder) readBody(req *http.Request) limitedBuffer {
+	buf := limitedBuffer{max: r.maxBody}
+	if req.Body == nil || req.Body == http.NoBody {
+		return buf
+	}
+	head, err := io.ReadAll(io.LimitReader(req.Body, int64(r.maxBody)+1))
+	req.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), req.Body), req.Body}
+	if err == nil {
+		_, _ = buf.Write(head)
+	}
+	return buf
+}
+
+// limitedBuffer 只保留前 max 字节的缓冲
+type limitedBuffer struct {
+	data      []byte
+	max       int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - len(b.data); room < len(p) {
+		b.data = append(b.data, p[:max(room, 0)]...)
+		b.truncated = true
+	} else {
+		b.data = append(b.data, p...)
+	}
+	return len(p), nil
+}
+
+// Bytes 返回缓冲内容，截断时去掉末尾不完整的 UTF-8 字符
+func (b *limitedBuffer) Bytes() []byte {
+	data := b.data
+	if b.truncated {
+		for i := 0; i < utf8.UTFMax-1 && len(data) > 0 && !utf8.Valid(data); i++ {
+			data = data[:len(data)-1]
+		}
+	}
+	return data
+}
+
+type ginWriter struct {
+	gin.ResponseWriter
+	buf limitedBuffer
+}
+
+func (w *ginWriter) Write(p []byte) (int, error) {
+	_, _ = w.buf.Write(p)
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *ginWriter) WriteString(s string) (int, error) {
+	_, _ = w.buf.Write([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	buf    limitedBuffer
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	_, _ = w.buf.Write(p)
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *responseWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
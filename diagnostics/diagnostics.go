@@ -0,0 +1,259 @@
+package diagnostics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mildsunup/higo/logger"
+	"github.com/mildsunup/higo/storage"
+)
+
+// ErrUnsupportedVersion 依赖版本低于最低支持版本
+var ErrUnsupportedVersion = errors.New("diagnostics: unsupported dependency version")
+
+// VersionProvider 服务端版本提供者，storage.VersionProvider 与 mq.VersionProvider 均满足
+type VersionProvider interface {
+	ServerVersion(ctx context.Context) (string, error)
+}
+
+// VersionFunc 函数式版本提供者
+type VersionFunc func(ctx context.Context) (string, error)
+
+func (f VersionFunc) ServerVersion(ctx context.Context) (string, error) { return f(ctx) }
+
+// Status 检查结果
+type Status string
+
+const (
+	StatusOK    Status = "ok"
+	StatusWarn  Status = "warn"  // 低于最低支持版本
+	StatusError Status = "error" // 无法获取版本
+)
+
+// Result 单个依赖的诊断结果
+type Result struct {
+	Name       string `json:"name"`
+	Kind       string `json:"kind"`
+	Version    string `json:"version,omitempty"`
+	MinVersion string `json:"min_version,omitempty"`
+	Status     Status `json:"status"`
+	Message    string `json:"message,omitempty"`
+}
+
+// Report 诊断报告
+type Report struct {
+	GeneratedAt  time.Time `json:"generated_at"`
+	Dependencies []Result  `json:"dependencies"`
+}
+
+// Healthy 所有依赖均满足最低版本
+func (r Report) Healthy() bool {
+	for _, d := range r.Dependencies {
+		if d.Status != StatusOK {
+			return false
+		}
+	}
+	return true
+}
+
+type dependency struct {
+	name, kind string
+	provider   VersionProvider
+	minVersion string
+}
+
+// Option 诊断选项
+type Option func(*Reporter)
+
+// WithTimeout 设置单个依赖的查询超时，默认 5s
+func WithTimeout(d time.Duration) Option {
+	return func(r *Reporter) {
+		r.timeout = d
+	}
+}
+
+// WithStrict 启动检查发现不支持的版本时返回错误，阻止启动
+func WithStrict() Option {
+	return func(r *Reporter) {
+		r.strict = true
+	}
+}
+
+// Reporter 依赖版本诊断
+type Reporter struct {
+	mu      sync.RWMutex
+	deps    []dependency
+	timeout time.Duration
+	strict  bool
+}
+
+// NewReporter 创建诊断器
+func NewReporter(opts ...Option) *Reporter {
+	r := &Reporter{timeout: 5 * time.Second}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Add 添加依赖，minVersion 为空表示不做版本检查
+func (r *Reporter) Add(name, kind string, p VersionProvider, minVersion string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deps = append(r.deps, dependency{name: name, kind: kind, provider: p, minVersion: minVersion})
+}
+
+// AddStorages 添加存储管理器中支持版本查询的存储
+func (r *Reporter) AddStorages(m storage.Manager, minVersions map[storage.Type]string) {
+	for _, name := range m.List() {
+		s, ok := m.Get(name)
+		if !ok {
+			continue
+		}
+		if p, ok := s.(storage.VersionProvider); ok {
+			r.Add(name, string(s.Type()), p, minVersions[s.Type()])
+		}
+	}
+}
+
+// Run 并发采集所有依赖版本
+func (r *Reporter) Run(ctx context.Context) Report {
+	r.mu.RLock()
+	deps := append([]dependency(nil), r.deps...)
+	r.mu.RUnlock()
+
+	results := make([]Result, len(deps))
+	var wg sync.WaitGroup
+	for i, d := range deps {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = r.check(ctx, d)
+		}()
+	}
+	wg.Wait()
+
+	return Report{GeneratedAt: time.Now(), Dependencies: results}
+}
+
+func (r *Reporter) check(ctx context.Context, d dependency) Result {
+	res := Result{Name: d.name, Kind: d.kind, MinVersion: d.minVersion, Status: StatusOK}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	version, err := d.provider.ServerVersion(ctx)
+	if err != nil {
+		res.Status = StatusError
+		res.Message = err.Error()
+		return res
+	}
+	res.Version = version
+
+	if d.minVersion != "" && CompareVersions(version, d.minVersion) < 0 {
+		res.Status = StatusWarn
+		res.Message = fmt.Sprintf("version %s is below minimum supported %s", version, d.minVersion)
+	}
+	return res
+}
+
+// Log 输出诊断报告
+func Log(ctx context.Context, l logger.Logger, report Report) {
+	for _, d := range report.Dependencies {
+		fields := []logger.Field{
+			logger.String("dependency", d.Name),
+			logger.String("kind", d.Kind),
+			logger.String("version", d.Version),
+		}
+		if d.MinVersion != "" {
+			fields = append(fields, logger.String("min_version", d.MinVersion))
+		}
+
+		switch d.Status {
+		case StatusOK:
+			l.Info(ctx, "dependency version", fields...)
+		default:
+			l.Warn(ctx, "dependency version check failed", append(fields, logger.String("reason", d.Message))...)
+		}
+	}
+}
+
+// StartupHook 返回启动钩子：采集并输出诊断报告，严格模式下版本不满足时返回错误
+//
+//	app.OnAfterStart(reporter.StartupHook(log))
+func (r *Reporter) StartupHook(l logger.Logger) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		report := r.Run(ctx)
+		Log(ctx, l, report)
+		if !r.strict {
+			return nil
+		}
+		for _, d := range report.Dependencies {
+			if d.Status == StatusWarn {
+				return fmt.Errorf("%w: %s %s < %s", ErrUnsupportedVersion, d.Name, d.Version, d.MinVersion)
+			}
+		}
+		return nil
+	}
+}
+
+// Handler 返回输出诊断报告的 HTTP 处理器
+func (r *Reporter) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		report := r.Run(req.Context())
+		w.Header().Set("Content-Type", "application/json")
+		if !report.Healthy() {
+			w.Header().Set("X-Dependency-Warnings", "true")
+		}
+		_ = json.NewEncoder(w).Encode(report)
+	})
+}
+
+// CompareVersions 比较版本号，忽略前缀 v 与非数字后缀（如 8.0.35-log）
+// 返回 -1、0、1，缺失的分量视为 0。
+func CompareVersions(a, b string) int {
+	pa, pb := versionParts(a), versionParts(b)
+	for i := range max(len(pa), len(pb)) {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionParts(v string) []int {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	var parts []int
+	for _, seg := range strings.Split(v, ".") {
+		end := 0
+		for end < len(seg) && seg[end] >= '0' && seg[end] <= '9' {
+			end++
+		}
+		if end == 0 {
+			break
+		}
+		n, _ := strconv.Atoi(seg[:end])
+		parts = append(parts, n)
+		if end < len(seg) {
+			break // 遇到后缀即停止
+		}
+	}
+	return parts
+}
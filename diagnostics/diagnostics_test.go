@@ -0,0 +1,43 @@
+package diagnostics
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"8.0.35-log", "8.0", 1},
+		{"5.7.44", "8.0", -1},
+		{"7.2.4", "7.2.4", 0},
+		{"v3.5", "3.5.0", 0},
+		{"2.8.1", "2.10", -1},
+	}
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestReporter_Run(t *testing.T) {
+	r := NewReporter(WithStrict())
+	r.Add("db", "mysql", VersionFunc(func(context.Context) (string, error) { return "8.0.35", nil }), "8.0")
+	r.Add("cache", "redis", VersionFunc(func(context.Context) (string, error) { return "5.0.7", nil }), "6.2")
+	r.Add("es", "elasticsearch", VersionFunc(func(context.Context) (string, error) { return "", errors.New("down") }), "")
+
+	report := r.Run(context.Background())
+	want := []Status{StatusOK, StatusWarn, StatusError}
+	for i, d := range report.Dependencies {
+		if d.Status != want[i] {
+			t.Errorf("%s: status = %s, want %s", d.Name, d.Status, want[i])
+		}
+	}
+	if report.Healthy() {
+		t.Error("report should not be healthy")
+	}
+}
@@ -0,0 +1,17 @@
+// Package diagnostics 提供依赖版本诊断。
+//
+// 启动时采集已连接依赖（MySQL/Redis/Kafka/Elasticsearch 等）的服务端版本，
+// 与声明的最低支持版本比对，低于下限时输出警告，使环境不一致的问题尽早暴露。
+//
+// 使用示例：
+//
+//	reporter := diagnostics.NewReporter()
+//	reporter.AddStorages(storageManager, map[storage.Type]string{
+//	    storage.TypeMySQL: "8.0",
+//	    storage.TypeRedis: "6.2",
+//	})
+//	reporter.Add("kafka", "kafka", kafkaClient, "2.8")
+//
+//	app.OnAfterStart(reporter.StartupHook(log))
+//	mux.Handle("/debug/dependencies", reporter.Handler())
+package diagnostics
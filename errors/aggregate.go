@@ -0,0 +1,97 @@
+package errors
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Aggregate 批量操作的聚合错误：按条目索引记录失败，可并发写入
+type Aggregate struct {
+	mu    sync.Mutex
+	total int
+	errs  map[int]error
+}
+
+// NewAggregate 创建聚合错误，total 为条目总数
+func NewAggregate(total int) *Aggregate {
+	return &Aggregate{total: total, errs: make(map[int]error)}
+}
+
+// Add 记录第 index 项的错误，err 为 nil 时忽略
+func (a *Aggregate) Add(index int, err error) {
+	if err == nil {
+		return
+	}
+	a.mu.Lock()
+	a.errs[index] = err
+	a.mu.Unlock()
+}
+
+// Get 返回第 index 项的错误
+func (a *Aggregate) Get(index int) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.errs[index]
+}
+
+// Total 条目总数
+func (a *Aggregate) Total() int { return a.total }
+
+// Failed 失败条目数
+func (a *Aggregate) Failed() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.errs)
+}
+
+// Partial 部分成功（至少一项成功且至少一项失败）
+func (a *Aggregate) Partial() bool {
+	n := a.Failed()
+	return n > 0 && n < a.total
+}
+
+// Indexes 失败条目索引（升序）
+func (a *Aggregate) Indexes() []int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	idx := make([]int, 0, len(a.errs))
+	for i := range a.errs {
+		idx = append(idx, i)
+	}
+	slices.Sort(idx)
+	return idx
+}
+
+// ErrOrNil 无失败时返回 nil，便于直接作为 error 返回
+func (a *Aggregate) ErrOrNil() error {
+	if a == nil || a.Failed() == 0 {
+		return nil
+	}
+	return a
+}
+
+func (a *Aggregate) Error() string {
+	idx := a.Indexes()
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d of %d items failed", len(idx), a.total)
+	for i, n := range idx {
+		if i == 3 {
+			fmt.Fprintf(&b, "; and %d more", len(idx)-i)
+			break
+		}
+		fmt.Fprintf(&b, "; [%d] %v", n, a.Get(n))
+	}
+	return b.String()
+}
+
+// Unwrap 按索引顺序返回各项错误，支持 errors.Is / errors.As
+func (a *Aggregate) Unwrap() []error {
+	idx := a.Indexes()
+	errs := make([]error, len(idx))
+	for i, n := range idx {
+		errs[i] = a.Get(n)
+	}
+	return errs
+}
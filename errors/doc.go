@@ -4,6 +4,9 @@
 //   - 错误码定义（HTTP/gRPC）
 //   - 错误构造器
 //   - 错误响应转换
+//   - 聚合错误（Aggregate）：批量操作按条目索引记录失败
+//   - 重试建议（WithRetryAfter）：HTTP 输出 Retry-After 头，gRPC 附带 RetryInfo 详情
+//   - 字段错误（WithFieldError）：记录在元数据 field_errors 中，Protobuf 详情见 proto/higo/errors/v1
 //
 // 使用示例：
 //
@@ -3,9 +3,17 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"math"
 	"runtime"
+	"time"
 )
 
+// MetaRetryAfter 重试等待秒数的元数据键
+const MetaRetryAfter = "retry_after"
+
+// MetaFieldErrors 字段级校验错误的元数据键，值为 map[string]string（字段路径 -> 错误描述）
+const MetaFieldErrors = "field_errors"
+
 // Error 应用错误
 type Error struct {
 	code     Code
@@ -13,6 +21,8 @@ type Error struct {
 	cause    error
 	metadata map[string]any
 	stack    []uintptr
+
+	retryAfter time.Duration
 }
 
 // New 创建错误
@@ -116,6 +126,31 @@ func (e *Error) WithMetadata(meta map[string]any) *Error {
 	return e
 }
 
+// WithRetryAfter 设置建议的重试等待时间：HTTP 响应输出 Retry-After 头，gRPC 附带 RetryInfo 详情，
+// 元数据 retry_after 记录向上取整的秒数
+func (e *Error) WithRetryAfter(d time.Duration) *Error {
+	if d <= 0 {
+		return e
+	}
+	e.retryAfter = d
+	return e.WithMeta(MetaRetryAfter, int(math.Ceil(d.Seconds())))
+}
+
+// WithFieldError 添加字段级校验错误，记录在元数据 field_errors 中
+func (e *Error) WithFieldError(field, description string) *Error {
+	fields, _ := e.GetMeta(MetaFieldErrors).(map[string]string)
+	if fields == nil {
+		fields = make(map[string]string)
+	}
+	fields[field] = description
+	return e.WithMeta(MetaFieldErrors, fields)
+}
+
+// RetryAfter 返回建议的重试等待时间，未设置时为 0
+func (e *Error) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
 // Metadata 返回元数据
 func (e *Error) Metadata() map[string]any {
 	return e.metadata
@@ -224,6 +259,15 @@ func GetMessage(err error) string {
 	return err.Error()
 }
 
+// GetRetryAfter 从错误链中提取建议的重试等待时间
+func GetRetryAfter(err error) (time.Duration, bool) {
+	var e *Error
+	if errors.As(err, &e) && e.retryAfter > 0 {
+		return e.retryAfter, true
+	}
+	return 0, false
+}
+
 // IsCode 检查错误是否为指定错误码
 func IsCode(err error, code Code) bool {
 	return GetCode(err) == code
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"google.golang.org/grpc/codes"
 )
@@ -246,3 +247,49 @@ func TestRegisterCode(t *testing.T) {
 		t.Errorf("expected message 'custom error', got %s", CustomCode.Message())
 	}
 }
+
+func TestAggregate(t *testing.T) {
+	agg := NewAggregate(3)
+	if agg.ErrOrNil() != nil {
+		t.Fatal("empty aggregate should be nil")
+	}
+
+	notFound := ErrNotFound("missing")
+	agg.Add(2, notFound)
+	agg.Add(0, fmt.Errorf("boom"))
+	agg.Add(1, nil)
+
+	err := agg.ErrOrNil()
+	if err == nil || !agg.Partial() || agg.Failed() != 2 {
+		t.Fatalf("unexpected aggregate state: %v", err)
+	}
+	if !Is(err, notFound) {
+		t.Error("aggregate should unwrap to item errors")
+	}
+	if got := err.Error(); got != "2 of 3 items failed; [0] boom; [2] missing" {
+		t.Errorf("unexpected message: %s", got)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	err := New(ResourceExhausted, "slow down").WithRetryAfter(1500 * time.Millisecond)
+
+	if d, ok := GetRetryAfter(fmt.Errorf("wrapped: %w", err)); !ok || d != 1500*time.Millisecond {
+		t.Fatalf("GetRetryAfter = %v, %v", d, ok)
+	}
+	if got := err.GetMeta(MetaRetryAfter); got != 2 {
+		t.Errorf("retry_after meta = %v, want 2", got)
+	}
+
+	st := ToGRPCStatus(err)
+	if st.Code() != codes.ResourceExhausted || len(st.Details()) != 1 {
+		t.Fatalf("unexpected status: %v %v", st.Code(), st.Details())
+	}
+	back := FromGRPCError(st.Err())
+	if back.RetryAfter() != 1500*time.Millisecond || back.Code() != ResourceExhausted {
+		t.Errorf("round trip lost retry info: %v %v", back.Code(), back.RetryAfter())
+	}
+	if _, ok := GetRetryAfter(New(Internal, "boom")); ok {
+		t.Error("error without retry-after should report none")
+	}
+}
@@ -1,8 +1,10 @@
 package errors
 
 import (
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
 // ToGRPCStatus 转换为 gRPC Status
@@ -13,12 +15,23 @@ func ToGRPCStatus(err error) *status.Status {
 
 	var e *Error
 	if As(err, &e) {
-		return status.New(e.code.GRPCCode(), e.message)
+		st := status.New(e.code.GRPCCode(), e.message)
+		if e.retryAfter > 0 {
+			if withInfo, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(e.retryAfter)}); err == nil {
+				st = withInfo
+			}
+		}
+		return st
 	}
 
 	return status.New(codes.Unknown, err.Error())
 }
 
+// GRPCStatus 实现 gRPC status 转换接口，handler 直接返回 *Error 时 gRPC 自动使用对应状态码与详情
+func (e *Error) GRPCStatus() *status.Status {
+	return ToGRPCStatus(e)
+}
+
 // FromGRPCStatus 从 gRPC Status 创建错误
 func FromGRPCStatus(s *status.Status) *Error {
 	if s == nil || s.Code() == codes.OK {
@@ -26,7 +39,13 @@ func FromGRPCStatus(s *status.Status) *Error {
 	}
 
 	code := grpcCodeToCode(s.Code())
-	return New(code, s.Message())
+	e := New(code, s.Message())
+	for _, d := range s.Details() {
+		if info, ok := d.(*errdetails.RetryInfo); ok {
+			e.WithRetryAfter(info.GetRetryDelay().AsDuration())
+		}
+	}
+	return e
 }
 
 // FromGRPCError 从 gRPC 错误创建错误
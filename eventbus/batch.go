@@ -0,0 +1,75 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mildsunup/higo/mq"
+)
+
+// PublishResult 批量发布中单个事件的结果，与输入顺序一致
+type PublishResult struct {
+	Event any
+	Err   error
+}
+
+// PublishBatch 批量发布事件
+// 按主题分组，底层生产者实现 mq.BatchProducer 且缓冲为空时使用批量发送，否则逐条发送；
+// 返回逐条结果，调用方可仅重试失败项。任一事件失败时 error 非空。
+func (b *Bus) PublishBatch(ctx context.Context, events ...any) ([]PublishResult, error) {
+	results := make([]PublishResult, len(events))
+	groups := make(map[string][]int)
+	msgs := make([]BufferedMessage, len(events))
+	finishes := make([]func(error), len(events))
+
+	for i, event := range events {
+		results[i].Event = event
+		data, topic, headers, err := b.prepare(event)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		msgs[i] = BufferedMessage{Topic: topic, Value: data, Headers: headers}
+		finishes[i] = b.finishPublish(eventNameOf(event))
+		groups[topic] = append(groups[topic], i)
+	}
+
+	batcher, canBatch := b.batchProducer()
+	if b.buffer != nil && b.buffer.Len() > 0 {
+		canBatch = false // 逐条发送以排在缓冲之后，保证发布顺序
+	}
+	for topic, idxs := range groups {
+		if !canBatch {
+			for _, i := range idxs {
+				results[i].Err = b.send(ctx, msgs[i])
+			}
+			continue
+		}
+
+		batch := make([]mq.BatchMessage, len(idxs))
+		for j, i := range idxs {
+			batch[j] = mq.BatchMessage{Value: msgs[i].Value, Headers: msgs[i].Headers}
+		}
+		for j, r := range batcher.PublishBatch(ctx, topic, batch) {
+			if r.Err != nil {
+				// 批量失败的单条消息走常规重试与缓冲
+				results[idxs[j]].Err = b.send(ctx, msgs[idxs[j]])
+			}
+		}
+	}
+
+	var errs []error
+	for i, r := range results {
+		if finishes[i] != nil {
+			finishes[i](r.Err)
+		}
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("event %d: %w", i, r.Err))
+		}
+	}
+	if len(errs) > 0 {
+		return results, fmt.Errorf("eventbus: %d of %d events failed: %w", len(errs), len(events), errors.Join(errs...))
+	}
+	return results, nil
+}
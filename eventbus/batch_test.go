@@ -0,0 +1,124 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mildsunup/higo/mq"
+)
+
+// fakeBatchProducer 支持批量发布的生产者，按消息体注入批量与单条发送失败
+type fakeBatchProducer struct {
+	captureProducer
+	mu          sync.Mutex
+	batches     map[string]int // 主题 -> 批量调用次数
+	failBatch   string         // 批量发送中失败的消息体片段
+	failPublish string         // 单条发送中失败的消息体片段
+}
+
+func (p *fakeBatchProducer) Publish(ctx context.Context, topic string, value []byte, opts ...mq.PublishOption) (*mq.PublishResult, error) {
+	if p.failPublish != "" && strings.Contains(string(value), p.failPublish) {
+		return nil, errors.New("publish failed")
+	}
+	return p.captureProducer.Publish(ctx, topic, value, opts...)
+}
+
+func (p *fakeBatchProducer) PublishBatch(ctx context.Context, topic string, msgs []mq.BatchMessage) []mq.BatchResult {
+	p.mu.Lock()
+	p.batches[topic]++
+	p.mu.Unlock()
+	results := make([]mq.BatchResult, len(msgs))
+	for i, m := range msgs {
+		if p.failBatch != "" && strings.Contains(string(m.Value), p.failBatch) {
+			results[i].Err = errors.New("batch item failed")
+			continue
+		}
+		results[i].Result, results[i].Err = p.captureProducer.Publish(ctx, topic, m.Value, mq.WithHeaders(m.Headers))
+	}
+	return results
+}
+
+// unencodable 无法序列化的事件
+type unencodable struct {
+	C chan int
+}
+
+func (unencodable) EventName() string { return "unencodable" }
+
+func TestPublishBatch_GroupsByTopic(t *testing.T) {
+	p := &fakeBatchProducer{batches: map[string]int{}}
+	bus := New(p, WithTopicFunc(func(e Event) string { return "topic." + e.EventName() }))
+
+	events := []any{orderPaid{OrderID: "o-1"}, &orderShipped{OrderID: "o-1"}, orderPaid{OrderID: "o-2"}}
+	results, err := bus.PublishBatch(context.Background(), events...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, r := range results {
+		if r.Event != events[i] || r.Err != nil {
+			t.Fatalf("result %d = %+v", i, r)
+		}
+	}
+	if p.batches["topic.order.paid"] != 1 || p.batches["topic.order.shipped"] != 1 || len(p.published()) != 3 {
+		t.Fatalf("batches = %v, published = %d", p.batches, len(p.published()))
+	}
+}
+
+func TestPublishBatch_PartialFailure(t *testing.T) {
+	p := &fakeBatchProducer{batches: map[string]int{}, failBatch: "o-", failPublish: "o-3"}
+	bus := New(p)
+
+	// 批量失败的消息逐条重发：o-1、o-2 成功，o-3 仍失败；无法编码的事件不发送
+	events := []any{orderPaid{OrderID: "o-1"}, orderPaid{OrderID: "o-2"}, orderPaid{OrderID: "o-3"}, unencodable{}}
+	results, err := bus.PublishBatch(context.Background(), events...)
+	if err == nil || !strings.Contains(err.Error(), "2 of 4 events failed") {
+		t.Fatalf("err = %v", err)
+	}
+	for i, failed := range []bool{false, false, true, true} {
+		if (results[i].Err != nil) != failed {
+			t.Errorf("result %d err = %v, want failed = %v", i, results[i].Err, failed)
+		}
+	}
+	if len(p.published()) != 2 {
+		t.Fatalf("published = %d", len(p.published()))
+	}
+}
+
+func TestPublishBatch_WithoutBatchProducer(t *testing.T) {
+	p := &captureProducer{}
+	bus := New(p)
+	results, err := bus.PublishBatch(context.Background(), orderPaid{OrderID: "o-1"}, orderPaid{OrderID: "o-2"})
+	if err != nil || len(results) != 2 || len(p.published()) != 2 {
+		t.Fatalf("results = %+v, err = %v, published = %d", results, err, len(p.published()))
+	}
+}
+
+func TestPublishBatch_QueuesBehindBuffer(t *testing.T) {
+	p := &fakeBatchProducer{batches: map[string]int{}, failPublish: "o-1"}
+	bus := New(p, WithBuffer(NewMemoryBuffer(10), time.Hour))
+	defer bus.Close(context.Background())
+
+	if err := bus.Publish(context.Background(), orderPaid{OrderID: "o-1"}); err != nil {
+		t.Fatal(err)
+	}
+	p.failPublish = ""
+
+	// 缓冲非空时批量事件逐条排在缓冲之后
+	if _, err := bus.PublishBatch(context.Background(), orderPaid{OrderID: "o-2"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.batches) != 0 || bus.Pending() != 2 {
+		t.Fatalf("batches = %v, pending = %d", p.batches, bus.Pending())
+	}
+	if err := bus.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	sent := p.published()
+	if len(sent) != 2 || !strings.Contains(string(sent[0].Value), "o-1") || !strings.Contains(string(sent[1].Value), "o-2") {
+		t.Fatalf("published = %+v", sent)
+	}
+}
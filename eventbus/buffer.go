@@ -0,0 +1,324 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mildsunup/higo/resilience"
+)
+
+// ErrBufferFull 本地缓冲已满
+var ErrBufferFull = errors.New("eventbus: buffer full")
+
+// BufferedMessage 待补发的消息
+type BufferedMessage struct {
+	Topic   string            `json:"topic"`
+	Value   []byte            `json:"value"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// Buffer 发布失败消息的本地缓冲（FIFO）
+type Buffer interface {
+	// Push 追加消息，已满时返回 ErrBufferFull
+	Push(msg BufferedMessage) error
+	// Peek 查看队首消息
+	Peek() (BufferedMessage, bool, error)
+	// Remove 移除队首消息
+	Remove() error
+	// Len 当前消息数
+	Len() int
+}
+
+// WithPublishRetry 发布失败时按指数退避重试
+//
+//	eventbus.WithPublishRetry(
+//	    resilience.WithMaxAttempts(3),
+//	    resilience.WithDelay(100*time.Millisecond),
+//	    resilience.WithJitter(0.2),
+//	)
+func WithPublishRetry(opts ...resilience.RetryOption) Option {
+	return func(b *Bus) {
+		b.retry = resilience.NewRetry(opts...)
+	}
+}
+
+// WithBuffer 重试后仍失败的消息写入本地缓冲，由后台协程按 interval 补发
+// 缓冲非空时新发布的消息直接排在缓冲之后，由补发协程按序发送，保证发布顺序，
+// 代价是传输恢复后最多延迟一个 interval。
+func WithBuffer(buf Buffer, interval time.Duration) Option {
+	return func(b *Bus) {
+		if interval <= 0 {
+			interval = time.Second
+		}
+		b.buffer = buf
+		b.flushInterval = interval
+	}
+}
+
+// send 发布消息，失败时重试并在启用缓冲时转存
+func (b *Bus) send(ctx context.Context, msg BufferedMessage) error {
+	if b.buffer != nil && b.buffer.Len() > 0 {
+		if err := b.buffer.Push(msg); err != nil {
+			return fmt.Errorf("eventbus: queue behind buffered messages: %w", err)
+		}
+		return nil
+	}
+
+	publish := func(ctx context.Context) error {
+		_, err := b.producer.Publish(ctx, msg.Topic, msg.Value, b.publishOptions(msg)...)
+		return err
+	}
+
+	var err error
+	if b.retry != nil {
+		err = b.retry.Execute(ctx, publish)
+	} else {
+		err = publish(ctx)
+	}
+	if err == nil || b.buffer == nil {
+		return err
+	}
+
+	if perr := b.buffer.Push(msg); perr != nil {
+		return fmt.Errorf("eventbus: publish failed and buffering failed: %w", errors.Join(err, perr))
+	}
+	return nil
+}
+
+// startFlusher 启动后台补发协程
+func (b *Bus) startFlusher() {
+	ctx, cancel := context.WithCancel(context.Background())
+	b.stopFlush = cancel
+	b.flushDone = make(chan struct{})
+
+	go func() {
+		defer close(b.flushDone)
+		ticker := time.NewTicker(b.flushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_ = b.Flush(ctx)
+			}
+		}
+	}()
+}
+
+// Flush 按顺序补发缓冲中的消息，遇到失败即停止并保留剩余消息
+func (b *Bus) Flush(ctx context.Context) error {
+	if b.buffer == nil {
+		return nil
+	}
+
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+
+	for {
+		msg, ok, err := b.buffer.Peek()
+		if err != nil || !ok {
+			return err
+		}
+		if _, err := b.producer.Publish(ctx, msg.Topic, msg.Value, b.publishOptions(msg)...); err != nil {
+			return fmt.Errorf("eventbus: flush buffer: %w", err)
+		}
+		if err := b.buffer.Remove(); err != nil {
+			return err
+		}
+	}
+}
+
+// Pending 缓冲中待补发的消息数；与 Flush 一起满足 runtime.Flusher，
+// 可通过 app.AddFlusher 在停止阶段补发缓冲并统计未能补发的消息
+func (b *Bus) Pending() int {
+	if b.buffer == nil {
+		return 0
+	}
+	return b.buffer.Len()
+}
+
+// Close 停止后台补发并尽力清空缓冲
+func (b *Bus) Close(ctx context.Context) error {
+	if b.stopFlush == nil {
+		return nil
+	}
+	b.stopFlush()
+	<-b.flushDone
+	return b.Flush(ctx)
+}
+
+// --- 内存缓冲 ---
+
+// MemoryBuffer 有界内存缓冲，进程退出时丢失
+type MemoryBuffer struct {
+	mu       sync.Mutex
+	msgs     []BufferedMessage
+	capacity int
+}
+
+// NewMemoryBuffer 创建内存缓冲
+func NewMemoryBuffer(capacity int) *MemoryBuffer {
+	return &MemoryBuffer{capacity: capacity}
+}
+
+func (m *MemoryBuffer) Push(msg BufferedMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.msgs) >= m.capacity {
+		return ErrBufferFull
+	}
+	m.msgs = append(m.msgs, msg)
+	return nil
+}
+
+func (m *MemoryBuffer) Peek() (BufferedMessage, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.msgs) == 0 {
+		return BufferedMessage{}, false, nil
+	}
+	return m.msgs[0], true, nil
+}
+
+func (m *MemoryBuffer) Remove() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.msgs) > 0 {
+		m.msgs[0] = BufferedMessage{}
+		m.msgs = m.msgs[1:]
+	}
+	return nil
+}
+
+func (m *MemoryBuffer) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.msgs)
+}
+
+// --- 磁盘缓冲 ---
+
+// FileBuffer 有界磁盘缓冲，每条消息一个文件，进程重启后继续补发
+// 无法解码的消息文件重命名为 .bad 隔离，不阻塞后续消息。
+type FileBuffer struct {
+	dir      string
+	capacity int
+
+	mu    sync.Mutex
+	files []string // 按序号升序
+	seq   uint64
+}
+
+// NewFileBuffer 创建磁盘缓冲，加载目录中已有的消息
+func NewFileBuffer(dir string, capacity int) (*FileBuffer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("eventbus: create buffer dir: %w", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("eventbus: read buffer dir: %w", err)
+	}
+
+	f := &FileBuffer{dir: dir, capacity: capacity}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".msg") {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, ".msg"), 10, 64)
+		if err != nil {
+			continue
+		}
+		f.files = append(f.files, name)
+		f.seq = max(f.seq, seq)
+	}
+	sort.Strings(f.files)
+	return f, nil
+}
+
+func (f *FileBuffer) Push(msg BufferedMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("eventbus: encode buffered message: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.files) >= f.capacity {
+		return ErrBufferFull
+	}
+
+	f.seq++
+	name := fmt.Sprintf("%020d.msg", f.seq)
+	tmp := filepath.Join(f.dir, name+".tmp")
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("eventbus: write buffered message: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(f.dir, name)); err != nil {
+		return fmt.Errorf("eventbus: write buffered message: %w", err)
+	}
+	f.files = append(f.files, name)
+	return nil
+}
+
+func (f *FileBuffer) Peek() (BufferedMessage, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for len(f.files) > 0 {
+		var msg BufferedMessage
+		path := filepath.Join(f.dir, f.files[0])
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			f.files = f.files[1:]
+			continue
+		}
+		if err != nil {
+			return msg, false, fmt.Errorf("eventbus: read buffered message: %w", err)
+		}
+		if err := json.Unmarshal(data, &msg); err == nil {
+			return msg, true, nil
+		}
+		if err := os.Rename(path, path+".bad"); err != nil {
+			return msg, false, fmt.Errorf("eventbus: quarantine buffered message %s: %w", f.files[0], err)
+		}
+		f.files = f.files[1:]
+	}
+	return BufferedMessage{}, false, nil
+}
+
+func (f *FileBuffer) Remove() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.files) == 0 {
+		return nil
+	}
+	if err := os.Remove(filepath.Join(f.dir, f.files[0])); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("eventbus: remove buffered message: %w", err)
+	}
+	f.files = f.files[1:]
+	return nil
+}
+
+func (f *FileBuffer) Len() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.files)
+}
+
+var (
+	_ Buffer = (*MemoryBuffer)(nil)
+	_ Buffer = (*FileBuffer)(nil)
+)
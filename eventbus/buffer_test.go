@@ -0,0 +1,230 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mildsunup/higo/mq"
+)
+
+// flakyProducer 可切换故障的生产者，记录成功发布的订单号
+type flakyProducer struct {
+	mq.Producer
+	mu     sync.Mutex
+	down   bool
+	orders []string
+}
+
+func (p *flakyProducer) setDown(down bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.down = down
+}
+
+func (p *flakyProducer) published() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.orders)
+}
+
+func (p *flakyProducer) Publish(_ context.Context, _ string, value []byte, _ ...mq.PublishOption) (*mq.PublishResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.down {
+		return nil, errors.New("broker unavailable")
+	}
+	var env struct {
+		Payload orderPaid `json:"payload"`
+	}
+	if err := json.Unmarshal(value, &env); err != nil {
+		return nil, err
+	}
+	p.orders = append(p.orders, env.Payload.OrderID)
+	return &mq.PublishResult{}, nil
+}
+
+func TestBuffer_FlushKeepsPublishOrder(t *testing.T) {
+	ctx := context.Background()
+	producer := &flakyProducer{down: true}
+	bus := New(producer, WithBuffer(NewMemoryBuffer(10), time.Hour))
+	defer bus.Close(ctx)
+
+	// 传输故障时写入缓冲，发布本身成功
+	for _, id := range []string{"o-1", "o-2"} {
+		if err := bus.Publish(ctx, orderPaid{OrderID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if bus.Pending() != 2 || len(producer.published()) != 0 {
+		t.Fatalf("pending = %d, published = %v", bus.Pending(), producer.published())
+	}
+
+	// 传输恢复后缓冲未清空前，新消息排在缓冲之后
+	producer.setDown(false)
+	if err := bus.Publish(ctx, orderPaid{OrderID: "o-3"}); err != nil {
+		t.Fatal(err)
+	}
+	if bus.Pending() != 3 || len(producer.published()) != 0 {
+		t.Fatalf("pending = %d, published = %v", bus.Pending(), producer.published())
+	}
+
+	if err := bus.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := producer.published(); !slices.Equal(got, []string{"o-1", "o-2", "o-3"}) || bus.Pending() != 0 {
+		t.Fatalf("published = %v, pending = %d", got, bus.Pending())
+	}
+
+	// 缓冲清空后直接发布
+	if err := bus.Publish(ctx, orderPaid{OrderID: "o-4"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := producer.published(); len(got) != 4 || bus.Pending() != 0 {
+		t.Fatalf("published = %v, pending = %d", got, bus.Pending())
+	}
+}
+
+func TestBuffer_FlushStopsOnFailure(t *testing.T) {
+	ctx := context.Background()
+	producer := &flakyProducer{down: true}
+	bus := New(producer, WithBuffer(NewMemoryBuffer(1), time.Hour))
+	defer bus.Close(ctx)
+
+	if err := bus.Publish(ctx, orderPaid{OrderID: "o-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := bus.Publish(ctx, orderPaid{OrderID: "o-2"}); !errors.Is(err, ErrBufferFull) {
+		t.Fatalf("full buffer err = %v", err)
+	}
+	if err := bus.Flush(ctx); err == nil || bus.Pending() != 1 {
+		t.Fatalf("flush = %v, pending = %d", err, bus.Pending())
+	}
+}
+
+func TestBuffer_CloseFlushes(t *testing.T) {
+	producer := &flakyProducer{down: true}
+	bus := New(producer, WithBuffer(NewMemoryBuffer(10), time.Hour))
+	if err := bus.Publish(context.Background(), orderPaid{OrderID: "o-1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	producer.setDown(false)
+	if err := bus.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := producer.published(); !slices.Equal(got, []string{"o-1"}) || bus.Pending() != 0 {
+		t.Fatalf("published = %v, pending = %d", got, bus.Pending())
+	}
+}
+
+func TestBuffer_BackgroundFlush(t *testing.T) {
+	producer := &flakyProducer{down: true}
+	bus := New(producer, WithBuffer(NewMemoryBuffer(10), 10*time.Millisecond))
+	defer bus.Close(context.Background())
+	if err := bus.Publish(context.Background(), orderPaid{OrderID: "o-1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	producer.setDown(false)
+	deadline := time.Now().Add(2 * time.Second)
+	for bus.Pending() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("buffer not flushed in background")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := producer.published(); !slices.Equal(got, []string{"o-1"}) {
+		t.Fatalf("published = %v", got)
+	}
+}
+
+func TestFileBuffer_ReloadFromDisk(t *testing.T) {
+	dir := t.TempDir()
+	buf, err := NewFileBuffer(dir, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, topic := range []string{"a", "b"} {
+		if err := buf.Push(BufferedMessage{Topic: topic, Value: []byte(topic), Headers: map[string]string{"k": topic}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 重启后按原顺序继续，新消息排在已有消息之后
+	buf, err = NewFileBuffer(dir, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := buf.Push(BufferedMessage{Topic: "c"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := buf.Push(BufferedMessage{Topic: "d"}); !errors.Is(err, ErrBufferFull) {
+		t.Fatalf("push over capacity = %v", err)
+	}
+
+	var topics []string
+	for {
+		msg, ok, err := buf.Peek()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			break
+		}
+		if msg.Topic == "b" && (string(msg.Value) != "b" || msg.Headers["k"] != "b") {
+			t.Fatalf("reloaded message = %+v", msg)
+		}
+		topics = append(topics, msg.Topic)
+		if err := buf.Remove(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !slices.Equal(topics, []string{"a", "b", "c"}) || buf.Len() != 0 {
+		t.Fatalf("topics = %v, len = %d", topics, buf.Len())
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatalf("files left after removal: %d", len(entries))
+	}
+}
+
+func TestFileBuffer_QuarantinesUndecodable(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "00000000000000000001.msg"), []byte("{truncated"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	buf, err := NewFileBuffer(dir, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := buf.Push(BufferedMessage{Topic: "orders"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 损坏的队首文件被隔离，不阻塞后续消息
+	msg, ok, err := buf.Peek()
+	if err != nil || !ok || msg.Topic != "orders" {
+		t.Fatalf("peek = %+v, %v, %v", msg, ok, err)
+	}
+	if buf.Len() != 1 {
+		t.Fatalf("len = %d", buf.Len())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "00000000000000000001.msg.bad")); err != nil {
+		t.Fatalf("quarantined file: %v", err)
+	}
+
+	// 隔离文件重启后不再加载
+	buf, err = NewFileBuffer(dir, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 1 {
+		t.Fatalf("reloaded len = %d", buf.Len())
+	}
+}
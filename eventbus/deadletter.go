@@ -0,0 +1,158 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/mildsunup/higo/mq"
+)
+
+// 死信消息头
+const (
+	HeaderDLQError    = "x-dlq-error"     // 失败原因
+	HeaderDLQAttempts = "x-dlq-attempts"  // 已尝试次数，0 表示无法解码
+	HeaderDLQTopic    = "x-dlq-topic"     // 原主题
+	HeaderDLQFailedAt = "x-dlq-failed-at" // 失败时间（RFC3339）
+	HeaderDLQReason   = "x-dlq-reason"    // 转投原因
+)
+
+// 转投原因
+const (
+	ReasonExhausted   = "exhausted"   // 重试耗尽
+	ReasonParked      = "parked"      // 处理器要求隔离（Park）
+	ReasonUndecodable = "undecodable" // 无法解码
+)
+
+// DeadLetter 死信事件
+type DeadLetter struct {
+	Topic    string            // 原主题
+	Reason   string            // 转投原因
+	Error    string            // 失败原因
+	Attempts int               // 已尝试次数
+	FailedAt time.Time         // 失败时间
+	Headers  map[string]string // 原始消息头
+	Value    []byte            // 原始消息体
+}
+
+// ParseDeadLetter 从死信消息解析失败信息
+func ParseDeadLetter(msg *mq.Message) DeadLetter {
+	dl := DeadLetter{
+		Topic:   msg.Headers[HeaderDLQTopic],
+		Reason:  msg.Headers[HeaderDLQReason],
+		Error:   msg.Headers[HeaderDLQError],
+		Headers: make(map[string]string, len(msg.Headers)),
+		Value:   msg.Value,
+	}
+	dl.Attempts, _ = strconv.Atoi(msg.Headers[HeaderDLQAttempts])
+	dl.FailedAt, _ = time.Parse(time.RFC3339, msg.Headers[HeaderDLQFailedAt])
+	for k, v := range msg.Headers {
+		if !strings.HasPrefix(k, "x-dlq-") {
+			dl.Headers[k] = v
+		}
+	}
+	return dl
+}
+
+// deadLetter 将失败消息转投死信主题，未配置死信主题时返回原错误交由 MQ 重投
+func (b *Bus) deadLetter(ctx context.Context, topic string, msg *mq.Message, cause error, attempts int, reason string) error {
+	if b.config.DeadLetterTopic == "" {
+		return cause
+	}
+
+	headers := make(map[string]string, len(msg.Headers)+4)
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	headers[HeaderDLQError] = cause.Error()
+	headers[HeaderDLQAttempts] = strconv.Itoa(attempts)
+	headers[HeaderDLQTopic] = topic
+	headers[HeaderDLQFailedAt] = time.Now().UTC().Format(time.RFC3339)
+	headers[HeaderDLQReason] = reason
+
+	opts := []mq.PublishOption{mq.WithHeaders(headers)}
+	if msg.Key != "" {
+		opts = append(opts, mq.WithKey(msg.Key))
+	}
+	if _, err := b.producer.Publish(ctx, b.config.DeadLetterTopic, msg.Value, opts...); err != nil {
+		return fmt.Errorf("eventbus: publish dead letter: %w (cause: %w)", err, cause)
+	}
+	return nil
+}
+
+// ReplayOption 重放选项
+type ReplayOption func(*replayConfig)
+
+type replayConfig struct {
+	filter func(DeadLetter) bool
+	group  string
+}
+
+// WithReplayFilter 仅重放满足条件的死信
+func WithReplayFilter(fn func(DeadLetter) bool) ReplayOption {
+	return func(c *replayConfig) {
+		c.filter = fn
+	}
+}
+
+// WithReplayGroup 设置重放使用的消费组，默认 "<group>-replay"
+func WithReplayGroup(group string) ReplayOption {
+	return func(c *replayConfig) {
+		c.group = group
+	}
+}
+
+// Replay 将死信事件重新投递回原主题，阻塞直到 ctx 结束，返回重放数量
+// 未通过过滤的死信会被确认并丢弃，如需保留请在过滤前自行转存。
+//
+//	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+//	defer cancel()
+//	n, err := bus.Replay(ctx, eventbus.WithReplayFilter(func(dl eventbus.DeadLetter) bool {
+//	    return dl.Topic == "orders"
+//	}))
+func (b *Bus) Replay(ctx context.Context, opts ...ReplayOption) (int, error) {
+	if b.consumer == nil {
+		return 0, ErrNoConsumer
+	}
+	if b.config.DeadLetterTopic == "" {
+		return 0, fmt.Errorf("eventbus: dead letter topic not configured")
+	}
+
+	cfg := replayConfig{group: b.config.Group + "-replay"}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	var replayed atomic.Int64
+	handler := func(ctx context.Context, msg *mq.Message) error {
+		dl := ParseDeadLetter(msg)
+		if dl.Topic == "" {
+			return nil
+		}
+		if cfg.filter != nil && !cfg.filter(dl) {
+			return nil
+		}
+
+		pubOpts := []mq.PublishOption{mq.WithHeaders(dl.Headers)}
+		if msg.Key != "" {
+			pubOpts = append(pubOpts, mq.WithKey(msg.Key))
+		}
+		if _, err := b.producer.Publish(ctx, dl.Topic, dl.Value, pubOpts...); err != nil {
+			return fmt.Errorf("eventbus: replay to %s: %w", dl.Topic, err)
+		}
+		replayed.Add(1)
+		return nil
+	}
+
+	if err := b.consumer.Subscribe(ctx, b.config.DeadLetterTopic, handler, mq.WithGroup(cfg.group)); err != nil {
+		return 0, fmt.Errorf("eventbus: subscribe dead letter: %w", err)
+	}
+	<-ctx.Done()
+	if err := b.consumer.Unsubscribe(b.config.DeadLetterTopic); err != nil {
+		return int(replayed.Load()), fmt.Errorf("eventbus: unsubscribe dead letter: %w", err)
+	}
+	return int(replayed.Load()), nil
+}
@@ -0,0 +1,186 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mildsunup/higo/mq"
+	"github.com/mildsunup/higo/mq/memory"
+)
+
+// capturedMessage 生产者收到的一次发布
+type capturedMessage struct {
+	Topic string
+	Value []byte
+	mq.PublishOptions
+}
+
+// captureProducer 记录发布的消息
+type captureProducer struct {
+	mq.Producer
+	mu   sync.Mutex
+	msgs []capturedMessage
+}
+
+func (p *captureProducer) Publish(_ context.Context, topic string, value []byte, opts ...mq.PublishOption) (*mq.PublishResult, error) {
+	m := capturedMessage{Topic: topic, Value: value}
+	for _, opt := range opts {
+		opt(&m.PublishOptions)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.msgs = append(p.msgs, m)
+	return &mq.PublishResult{}, nil
+}
+
+func (p *captureProducer) published() []capturedMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]capturedMessage(nil), p.msgs...)
+}
+
+// newFailingBus 订阅 orderPaid 的处理器按 fail 返回错误，死信发往 captureProducer
+func newFailingBus(t *testing.T, fail func() error, opts ...HandlerOption) (*Bus, *captureProducer, *atomic.Int32) {
+	t.Helper()
+	client := memory.New("test")
+	t.Cleanup(func() { _ = client.Close() })
+	producer := &captureProducer{}
+	bus := New(producer, WithConsumer(client), WithDeadLetter("orders.dlq"))
+
+	var calls atomic.Int32
+	if err := Subscribe(context.Background(), bus, func(context.Context, orderPaid) error {
+		calls.Add(1)
+		return fail()
+	}, opts...); err != nil {
+		t.Fatal(err)
+	}
+	return bus, producer, &calls
+}
+
+func TestDeadLetter_Exhausted(t *testing.T) {
+	bus, producer, calls := newFailingBus(t, func() error { return errors.New("boom") }, WithHandlerRetry(3, time.Millisecond))
+	msg, _ := preparedMessage(t, bus, orderPaid{OrderID: "o-1"})
+	msg.Key = "o-1"
+	msg.Headers = map[string]string{"tenant": "acme"}
+
+	if err := bus.dispatcher(msg.Topic)(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("handler calls = %d", calls.Load())
+	}
+
+	sent := producer.published()
+	if len(sent) != 1 || sent[0].Topic != "orders.dlq" || sent[0].Key != "o-1" {
+		t.Fatalf("dead letters = %+v", sent)
+	}
+	dl := ParseDeadLetter(&mq.Message{Value: sent[0].Value, Headers: sent[0].Headers})
+	if dl.Topic != msg.Topic || dl.Reason != ReasonExhausted || dl.Attempts != 3 || !strings.Contains(dl.Error, "boom") {
+		t.Fatalf("dead letter = %+v", dl)
+	}
+	if dl.FailedAt.IsZero() || string(dl.Value) != string(msg.Value) {
+		t.Fatalf("dead letter = %+v", dl)
+	}
+	// 原始消息头保留，死信消息头不出现在 Headers 中
+	if len(dl.Headers) != 1 || dl.Headers["tenant"] != "acme" {
+		t.Fatalf("original headers = %v", dl.Headers)
+	}
+}
+
+func TestDeadLetter_Undecodable(t *testing.T) {
+	bus, producer, calls := newFailingBus(t, func() error { return nil })
+	if err := bus.dispatcher("domain-events")(context.Background(), &mq.Message{Value: []byte("not json")}); err != nil {
+		t.Fatal(err)
+	}
+
+	sent := producer.published()
+	if len(sent) != 1 || calls.Load() != 0 {
+		t.Fatalf("dead letters = %d, calls = %d", len(sent), calls.Load())
+	}
+	if dl := ParseDeadLetter(&mq.Message{Headers: sent[0].Headers}); dl.Reason != ReasonUndecodable || dl.Attempts != 0 {
+		t.Fatalf("dead letter = %+v", dl)
+	}
+}
+
+func TestDeadLetter_NotConfigured(t *testing.T) {
+	client := memory.New("test")
+	defer client.Close()
+	bus := New(client, WithConsumer(client))
+	if err := Subscribe(context.Background(), bus, func(context.Context, orderPaid) error {
+		return errors.New("boom")
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 未配置死信主题时返回错误交由 MQ 重投
+	msg, _ := preparedMessage(t, bus, orderPaid{OrderID: "o-1"})
+	if err := bus.dispatcher(msg.Topic)(context.Background(), msg); err == nil || err.Error() != "boom" {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := bus.Replay(context.Background()); err == nil {
+		t.Fatal("replay without dead letter topic should fail")
+	}
+}
+
+func TestBus_Replay(t *testing.T) {
+	ctx := context.Background()
+	client := memory.New("test")
+	defer client.Close()
+	bus := New(client, WithConsumer(client), WithDeadLetter("orders.dlq"), WithGroup("billing"))
+
+	replayed := make(chan *mq.Message, 2)
+	if err := client.Subscribe(ctx, "orders", func(_ context.Context, msg *mq.Message) error {
+		replayed <- msg
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	replayCtx, cancel := context.WithCancel(ctx)
+	type result struct {
+		n   int
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		n, err := bus.Replay(replayCtx, WithReplayFilter(func(dl DeadLetter) bool { return dl.Reason == ReasonExhausted }))
+		done <- result{n, err}
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	for _, reason := range []string{ReasonParked, ReasonExhausted} {
+		if _, err := client.Publish(ctx, "orders.dlq", []byte(reason), mq.WithKey("o-1"), mq.WithHeaders(map[string]string{
+			"tenant":        "acme",
+			HeaderDLQTopic:  "orders",
+			HeaderDLQReason: reason,
+			HeaderDLQError:  "boom",
+		})); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	select {
+	case msg := <-replayed:
+		if string(msg.Value) != ReasonExhausted || msg.Key != "o-1" || msg.Headers["tenant"] != "acme" || msg.Headers[HeaderDLQError] != "" {
+			t.Fatalf("replayed = %+v", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("dead letter not replayed")
+	}
+
+	cancel()
+	res := <-done
+	if res.err != nil || res.n != 1 {
+		t.Fatalf("replay = %d, %v", res.n, res.err)
+	}
+	select {
+	case msg := <-replayed:
+		t.Fatalf("filtered dead letter replayed: %s", msg.Value)
+	default:
+	}
+}
@@ -0,0 +1,43 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mildsunup/higo/idempotent"
+)
+
+// ErrEventInProgress 同一事件正在被其他消费者处理，返回给 MQ 稍后重投，不转投死信
+var ErrEventInProgress = errors.New("eventbus: event is being processed")
+
+// WithDeduplication 启用消费去重：按信封 ID 与处理器标识记录已处理事件
+// 结合 Redis 缓存使用可在多实例间共享已处理集合，TTL 由 idempotent.Config 控制。
+// 未设置 WithIDFunc 时信封 ID 默认使用 UUID；仅负责发布的 Bus 未启用去重，需自行设置 WithIDFunc，
+// 否则事件没有 ID，消费端无法去重。
+//
+//	dedupe := idempotent.New(cache.NewRedis(redisCfg), idempotent.Config{TTL: 24 * time.Hour})
+//	bus := eventbus.New(producer, eventbus.WithConsumer(consumer), eventbus.WithDeduplication(dedupe))
+func WithDeduplication(h *idempotent.Handler) Option {
+	return func(b *Bus) {
+		b.dedupe = h
+	}
+}
+
+// invokeOnce 去重执行处理器；无信封 ID 的事件无法去重，直接执行
+// 已处理完成的事件跳过；正在被其他消费者处理的事件返回 ErrEventInProgress，
+// 由 MQ 重投后再判断，避免对方中途崩溃时记录滞留导致事件丢失。
+func (b *Bus) invokeOnce(ctx context.Context, id string, sub *subscription, event any) error {
+	if b.dedupe == nil || id == "" {
+		return sub.invoke(ctx, event)
+	}
+
+	key := "eventbus:" + sub.id + ":" + id
+	_, err := b.dedupe.Execute(ctx, key, "", func() ([]byte, error) {
+		return nil, sub.invoke(ctx, event)
+	})
+	if errors.Is(err, idempotent.ErrDuplicateRequest) {
+		return Retryable(fmt.Errorf("%w: %s", ErrEventInProgress, id))
+	}
+	return err
+}
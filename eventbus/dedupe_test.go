@@ -0,0 +1,134 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/mildsunup/higo/cache"
+	"github.com/mildsunup/higo/idempotent"
+	"github.com/mildsunup/higo/mq"
+	"github.com/mildsunup/higo/mq/memory"
+)
+
+func newDedupeBus(t *testing.T, opts ...Option) (*Bus, cache.Cache, *atomic.Int32) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	store := cache.NewRedisFromClient(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	client := memory.New("test")
+	t.Cleanup(func() { _ = client.Close() })
+
+	opts = append([]Option{WithConsumer(client), WithDeduplication(idempotent.New(store, idempotent.Config{TTL: time.Hour}))}, opts...)
+	bus := New(client, opts...)
+	var calls atomic.Int32
+	if err := Subscribe(context.Background(), bus, func(context.Context, orderPaid) error {
+		calls.Add(1)
+		return nil
+	}, WithHandlerID("billing")); err != nil {
+		t.Fatal(err)
+	}
+	return bus, store, &calls
+}
+
+func preparedMessage(t *testing.T, bus *Bus, event any) (*mq.Message, string) {
+	t.Helper()
+	data, topic, headers, err := bus.prepare(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := &mq.Message{Topic: topic, Value: data, Headers: headers}
+	env, err := decodeMessage(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return msg, env.ID
+}
+
+func TestDedupe_DefaultEnvelopeID(t *testing.T) {
+	bus, _, _ := newDedupeBus(t)
+	_, first := preparedMessage(t, bus, orderPaid{OrderID: "o-1"})
+	_, second := preparedMessage(t, bus, orderPaid{OrderID: "o-1"})
+	if first == "" || first == second {
+		t.Fatalf("ids = %q, %q", first, second)
+	}
+
+	// 显式设置的 ID 函数优先
+	bus, _, _ = newDedupeBus(t, WithIDFunc(func() string { return "fixed" }))
+	if _, id := preparedMessage(t, bus, orderPaid{}); id != "fixed" {
+		t.Fatalf("id = %q", id)
+	}
+
+	// 未启用去重时不生成 ID
+	if _, id := preparedMessage(t, New(memory.New("test")), orderPaid{}); id != "" {
+		t.Fatalf("id without dedupe = %q", id)
+	}
+}
+
+func TestDedupe_SkipsCompleted(t *testing.T) {
+	bus, _, calls := newDedupeBus(t)
+	msg, _ := preparedMessage(t, bus, orderPaid{OrderID: "o-1"})
+	handle := bus.dispatcher(msg.Topic)
+
+	for range 2 {
+		if err := handle(context.Background(), msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("handler calls = %d, want 1", calls.Load())
+	}
+}
+
+func TestDedupe_InProgressIsRedelivered(t *testing.T) {
+	ctx := context.Background()
+	dlq := &recordingProducer{}
+	bus, store, calls := newDedupeBus(t, WithDeadLetter("orders.dlq"))
+	bus.producer = dlq
+	msg, id := preparedMessage(t, bus, orderPaid{OrderID: "o-1"})
+	handle := bus.dispatcher(msg.Topic)
+	key := "eventbus:billing:" + id
+
+	// 其他消费者处理中：返回错误交由 MQ 重投，不转投死信、不执行处理器
+	if err := store.Set(ctx, key, idempotent.Record{Status: idempotent.StatusPending}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	err := handle(ctx, msg)
+	if !errors.Is(err, ErrEventInProgress) || DispositionOf(err) != DispositionRetry {
+		t.Fatalf("in-progress err = %v", err)
+	}
+	if calls.Load() != 0 || dlq.count.Load() != 0 {
+		t.Fatalf("calls = %d, dead letters = %d", calls.Load(), dlq.count.Load())
+	}
+
+	// 对方完成后重投的消息被跳过
+	if err := store.Set(ctx, key, idempotent.Record{Status: idempotent.StatusCompleted}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := handle(ctx, msg); err != nil || calls.Load() != 0 {
+		t.Fatalf("completed: err = %v, calls = %d", err, calls.Load())
+	}
+
+	// 对方失败清除记录后重投的消息正常处理
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if err := handle(ctx, msg); err != nil || calls.Load() != 1 {
+		t.Fatalf("released: err = %v, calls = %d", err, calls.Load())
+	}
+}
+
+// recordingProducer 记录发布次数的生产者
+type recordingProducer struct {
+	mq.Producer
+	count atomic.Int32
+}
+
+func (p *recordingProducer) Publish(context.Context, string, []byte, ...mq.PublishOption) (*mq.PublishResult, error) {
+	p.count.Add(1)
+	return &mq.PublishResult{}, nil
+}
@@ -1,9 +1,15 @@
-// Package eventbus 提供领域事件发布能力。
+// Package eventbus 提供领域事件发布与订阅能力。
 //
 // 核心功能：
 //   - 基于消息队列的事件发布
-//   - 同步/异步发布模式
+//   - 同步/异步发布模式、批量发布（逐条结果）
 //   - 事件信封封装
+//   - CloudEvents 1.0 信封（结构化/二进制模式，Kafka/AMQP 协议绑定），消费端自动识别
+//   - 强类型订阅（按事件名解析具体类型，处理器级并发与重试）
+//   - 发布重试（指数退避 + 抖动）与本地缓冲（内存/磁盘），后台补发
+//   - 处理器错误策略：Retryable（退避重试）、Fatal（丢弃并计数）、Park（隔离到死信）
+//   - 消费去重（基于 idempotent，按信封 ID + 处理器标识）
+//   - 死信主题（重试耗尽或无法解码时转投，附带失败信息）与重放
 //
 // 使用示例：
 //
@@ -19,4 +25,35 @@
 //	// 发布事件
 //	err := bus.Publish(ctx, myEvent)
 //	bus.PublishAsync(ctx, myEvent)
+//
+//	// 使用 CloudEvents 格式
+//	bus := eventbus.New(mqProducer, eventbus.WithFormat(eventbus.CloudEvents))
+//	bus := eventbus.New(mqProducer, eventbus.WithFormat(eventbus.CloudEventsFormat{
+//	    Mode: eventbus.Binary, Binding: eventbus.AMQPBinding, Source: "order-svc",
+//	}))
+//
+//	// 订阅事件
+//	bus := eventbus.New(mqProducer, eventbus.WithConsumer(mqConsumer), eventbus.WithGroup("order-svc"))
+//	err := eventbus.Subscribe(ctx, bus, func(ctx context.Context, e OrderCreated) error {
+//	    return handle(e)
+//	}, eventbus.WithHandlerConcurrency(4), eventbus.WithHandlerRetry(3, time.Second))
+//
+//	// 发布重试与本地缓冲
+//	buf, _ := eventbus.NewFileBuffer("/var/lib/app/eventbus", 10000)
+//	bus := eventbus.New(mqProducer,
+//	    eventbus.WithPublishRetry(resilience.WithMaxAttempts(3), resilience.WithJitter(0.2)),
+//	    eventbus.WithBuffer(buf, time.Second),
+//	)
+//	defer bus.Close(ctx)
+//
+//	// 死信与重放
+//	bus := eventbus.New(mqProducer, eventbus.WithConsumer(mqConsumer), eventbus.WithDeadLetter("domain-events.dlq"))
+//	n, err := bus.Replay(ctx)
+//
+//	// 链路追踪与指标
+//	bus := eventbus.New(mqProducer, eventbus.WithTracer(otel.Tracer("eventbus")), eventbus.WithMetrics(metrics))
+//
+//	// 测试：同步分发并断言已发布事件（见 eventbustest 子包）
+//	bus := eventbustest.New()
+//	eventbustest.AssertPublished(t, bus, func(e OrderCreated) bool { return e.OrderID == "o-1" })
 package eventbus
@@ -3,11 +3,16 @@ package eventbus
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/mildsunup/higo/idempotent"
 	"github.com/mildsunup/higo/mq"
+	"github.com/mildsunup/higo/resilience"
 )
 
 // Event 领域事件接口
@@ -31,9 +36,11 @@ type Envelope struct {
 
 // Config 配置
 type Config struct {
-	Topic     string             // 事件主题，默认 "domain-events"
-	IDFunc    func() string      // ID 生成函数
-	TopicFunc func(Event) string // 按事件类型路由主题
+	Topic           string             // 事件主题，默认 "domain-events"
+	IDFunc          func() string      // ID 生成函数，启用去重时默认 UUID
+	TopicFunc       func(Event) string // 按事件类型路由主题
+	Group           string             // 订阅消费组
+	DeadLetterTopic string             // 死信主题，处理器重试耗尽后转投
 }
 
 // DefaultConfig 默认配置
@@ -46,7 +53,23 @@ func DefaultConfig() Config {
 // Bus 事件总线
 type Bus struct {
 	producer mq.Producer
+	consumer mq.Consumer
 	config   Config
+	format   Format
+	registry *Registry
+
+	mu     sync.RWMutex
+	routes map[string]*topicRoute
+
+	dedupe        *idempotent.Handler
+	metrics       *busMetrics
+	tracer        trace.Tracer
+	retry         *resilience.Retry
+	buffer        Buffer
+	flushInterval time.Duration
+	flushMu       sync.Mutex
+	stopFlush     context.CancelFunc
+	flushDone     chan struct{}
 }
 
 // Option 事件总线选项
@@ -73,6 +96,34 @@ func WithTopicFunc(fn func(Event) string) Option {
 	}
 }
 
+// WithConsumer 设置消费者，启用 Subscribe
+func WithConsumer(consumer mq.Consumer) Option {
+	return func(b *Bus) {
+		b.consumer = consumer
+	}
+}
+
+// WithGroup 设置订阅消费组
+func WithGroup(group string) Option {
+	return func(b *Bus) {
+		b.config.Group = group
+	}
+}
+
+// WithDeadLetter 设置死信主题
+func WithDeadLetter(topic string) Option {
+	return func(b *Bus) {
+		b.config.DeadLetterTopic = topic
+	}
+}
+
+// WithRegistry 设置事件类型注册表（多个 Bus 共享时使用）
+func WithRegistry(r *Registry) Option {
+	return func(b *Bus) {
+		b.registry = r
+	}
+}
+
 // New 创建事件总线
 func New(producer mq.Producer, opts ...Option) *Bus {
 	b := &Bus{
@@ -80,63 +131,87 @@ func New(producer mq.Producer, opts ...Option) *Bus {
 		config: Config{
 			Topic: "domain-events",
 		},
+		format:   Native,
+		registry: NewRegistry(),
+		routes:   make(map[string]*topicRoute),
 	}
 
 	for _, opt := range opts {
 		opt(b)
 	}
+	if b.dedupe != nil && b.config.IDFunc == nil {
+		b.config.IDFunc = uuid.NewString
+	}
+	b.instrumentTransport()
+
+	if b.buffer != nil {
+		b.startFlusher()
+	}
 
 	return b
 }
 
 // Publish 同步发布事件
 func (b *Bus) Publish(ctx context.Context, event any) error {
-	data, topic, err := b.prepare(event)
+	data, topic, headers, err := b.prepare(event)
 	if err != nil {
 		return err
 	}
-	_, err = b.producer.Publish(ctx, topic, data)
+	msg := BufferedMessage{Topic: topic, Value: data, Headers: headers}
+	finish := b.finishPublish(eventNameOf(event))
+	err = b.send(ctx, msg)
+	finish(err)
 	return err
 }
 
 // PublishAsync 异步发布事件（不阻塞调用方）
 func (b *Bus) PublishAsync(ctx context.Context, event any) {
-	data, topic, err := b.prepare(event)
+	data, topic, headers, err := b.prepare(event)
 	if err != nil {
 		return
 	}
-	b.producer.PublishAsync(ctx, topic, data, nil)
+	msg := BufferedMessage{Topic: topic, Value: data, Headers: headers}
+	finish := b.finishPublish(eventNameOf(event))
+	if b.retry == nil && b.buffer == nil {
+		b.producer.PublishAsync(ctx, topic, data, func(_ *mq.PublishResult, err error) { finish(err) }, b.publishOptions(msg)...)
+		return
+	}
+	go func() { finish(b.send(context.WithoutCancel(ctx), msg)) }()
 }
 
-func (b *Bus) prepare(event any) ([]byte, string, error) {
+func (b *Bus) publishOptions(msg BufferedMessage) []mq.PublishOption {
+	if len(msg.Headers) == 0 {
+		return nil
+	}
+	return []mq.PublishOption{mq.WithHeaders(msg.Headers)}
+}
+
+func (b *Bus) prepare(event any) ([]byte, string, map[string]string, error) {
 	envelope := Envelope{
 		Payload:   event,
 		Timestamp: time.Now(),
 	}
 
-	if e, ok := event.(Event); ok {
-		envelope.Name = e.EventName()
-	} else {
-		envelope.Name = fmt.Sprintf("%T", event)
-	}
+	envelope.Name = eventNameOf(event)
 
 	if b.config.IDFunc != nil {
 		envelope.ID = b.config.IDFunc()
 	}
 
-	data, err := json.Marshal(envelope)
+	data, headers, err := b.format.Encode(envelope)
 	if err != nil {
-		return nil, "", fmt.Errorf("eventbus: marshal failed: %w", err)
+		return nil, "", nil, fmt.Errorf("eventbus: marshal failed: %w", err)
 	}
 
-	topic := b.config.Topic
-	if b.config.TopicFunc != nil {
-		if e, ok := event.(Event); ok {
-			topic = b.config.TopicFunc(e)
-		}
-	}
+	return data, b.topicFor(event), headers, nil
+}
 
-	return data, topic, nil
+// eventNameOf 事件名，未实现 Event 时使用类型名
+func eventNameOf(event any) string {
+	if e, ok := event.(Event); ok {
+		return e.EventName()
+	}
+	return fmt.Sprintf("%T", event)
 }
 
 // Noop 空实现
@@ -0,0 +1,185 @@
+// Package eventbustest 提供内存测试总线，应用服务测试无需启动消息中间件即可断言发布的事件。
+//
+//	bus := eventbustest.New()
+//	svc := NewOrderService(bus)
+//	svc.Create(ctx, ...)
+//	eventbustest.AssertPublished(t, bus, func(e OrderCreated) bool { return e.OrderID == "o-1" })
+package eventbustest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mildsunup/higo/eventbus"
+	"github.com/mildsunup/higo/mq"
+)
+
+// PublishedMessage 测试总线捕获的已发布消息
+type PublishedMessage struct {
+	Topic     string
+	ID        string
+	Name      string
+	Payload   json.RawMessage
+	Headers   map[string]string
+	Timestamp time.Time
+}
+
+// Bus 内存测试总线
+// 捕获所有发布的事件信封，并在 Publish 调用内同步分发给 eventbus.Subscribe 注册的处理器。
+type Bus struct {
+	*eventbus.Bus
+	transport *syncTransport
+	registry  *eventbus.Registry // 断言时解码 payload，与总线自身的注册表无关
+}
+
+// New 创建测试总线
+func New(opts ...eventbus.Option) *Bus {
+	transport := &syncTransport{handlers: make(map[string]mq.Handler)}
+	opts = append([]eventbus.Option{eventbus.WithConsumer(transport)}, opts...)
+	return &Bus{Bus: eventbus.New(transport, opts...), transport: transport, registry: eventbus.NewRegistry()}
+}
+
+// Published 返回已捕获的消息（按发布顺序）
+func (tb *Bus) Published() []PublishedMessage {
+	tb.transport.mu.Lock()
+	defer tb.transport.mu.Unlock()
+	return append([]PublishedMessage(nil), tb.transport.published...)
+}
+
+// HandlerErrors 返回同步分发时处理器返回的错误
+func (tb *Bus) HandlerErrors() []error {
+	tb.transport.mu.Lock()
+	defer tb.transport.mu.Unlock()
+	return append([]error(nil), tb.transport.errs...)
+}
+
+// Reset 清空已捕获的消息与错误，保留订阅
+func (tb *Bus) Reset() {
+	tb.transport.mu.Lock()
+	defer tb.transport.mu.Unlock()
+	tb.transport.published = nil
+	tb.transport.errs = nil
+}
+
+// PublishedEvents 返回已发布的指定类型事件
+func PublishedEvents[E eventbus.Event](tb *Bus) []E {
+	name := eventbus.Register[E](tb.registry)
+	var events []E
+	for _, msg := range tb.Published() {
+		if msg.Name != name {
+			continue
+		}
+		if e, err := tb.registry.Decode(name, msg.Payload); err == nil {
+			events = append(events, e.(E))
+		}
+	}
+	return events
+}
+
+// AssertPublished 断言已发布满足条件的事件并返回首个匹配项，match 为 nil 时匹配任意该类型事件
+func AssertPublished[E eventbus.Event](t testing.TB, tb *Bus, match func(E) bool) E {
+	t.Helper()
+	for _, e := range PublishedEvents[E](tb) {
+		if match == nil || match(e) {
+			return e
+		}
+	}
+	t.Fatalf("eventbus: expected %s to be published, got [%s]", eventbus.Register[E](tb.registry), tb.publishedNames())
+	var zero E
+	return zero
+}
+
+// AssertNotPublished 断言未发布满足条件的事件，match 为 nil 时匹配任意该类型事件
+func AssertNotPublished[E eventbus.Event](t testing.TB, tb *Bus, match func(E) bool) {
+	t.Helper()
+	for _, e := range PublishedEvents[E](tb) {
+		if match == nil || match(e) {
+			t.Fatalf("eventbus: expected %s not to be published", eventbus.Register[E](tb.registry))
+		}
+	}
+}
+
+func (tb *Bus) publishedNames() string {
+	msgs := tb.Published()
+	names := make([]string, len(msgs))
+	for i, msg := range msgs {
+		names[i] = msg.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// syncTransport 同步内存传输，发布即在调用方协程内分发
+type syncTransport struct {
+	mu        sync.Mutex
+	handlers  map[string]mq.Handler
+	published []PublishedMessage
+	errs      []error
+	seq       int
+}
+
+func (s *syncTransport) Publish(ctx context.Context, topic string, value []byte, opts ...mq.PublishOption) (*mq.PublishResult, error) {
+	var o mq.PublishOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	msg := &mq.Message{Topic: topic, Key: o.Key, Value: value, Headers: o.Headers, Timestamp: time.Now()}
+	recorded := PublishedMessage{Topic: topic, Headers: o.Headers, Timestamp: msg.Timestamp}
+	if env, err := eventbus.DecodeEnvelope(msg); err == nil {
+		recorded.ID = env.ID
+		recorded.Name = env.Name
+		recorded.Payload, _ = env.Payload.(json.RawMessage)
+	}
+
+	s.mu.Lock()
+	s.seq++
+	offset := int64(s.seq)
+	s.published = append(s.published, recorded)
+	handler := s.handlers[topic]
+	s.mu.Unlock()
+
+	if handler != nil {
+		if err := handler(ctx, msg); err != nil {
+			s.mu.Lock()
+			s.errs = append(s.errs, err)
+			s.mu.Unlock()
+		}
+	}
+	return &mq.PublishResult{MessageID: recorded.ID, Offset: offset}, nil
+}
+
+func (s *syncTransport) PublishAsync(ctx context.Context, topic string, value []byte, callback func(*mq.PublishResult, error), opts ...mq.PublishOption) {
+	res, err := s.Publish(ctx, topic, value, opts...)
+	if callback != nil {
+		callback(res, err)
+	}
+}
+
+func (s *syncTransport) Subscribe(ctx context.Context, topic string, handler mq.Handler, opts ...mq.SubscribeOption) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.handlers[topic]; exists {
+		return errors.New("eventbus: topic already subscribed")
+	}
+	s.handlers[topic] = handler
+	return nil
+}
+
+func (s *syncTransport) Unsubscribe(topic string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.handlers, topic)
+	return nil
+}
+
+func (s *syncTransport) Close() error { return nil }
+
+var (
+	_ mq.Producer = (*syncTransport)(nil)
+	_ mq.Consumer = (*syncTransport)(nil)
+)
@@ -0,0 +1,61 @@
+package eventbustest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mildsunup/higo/eventbus"
+)
+
+type orderCreated struct {
+	OrderID string `json:"order_id"`
+}
+
+func (orderCreated) EventName() string { return "order.created" }
+
+type orderCancelled struct{}
+
+func (orderCancelled) EventName() string { return "order.cancelled" }
+
+func TestBus(t *testing.T) {
+	ctx := context.Background()
+	bus := New(eventbus.WithFormat(eventbus.CloudEventsFormat{Source: "orders"}))
+
+	var handled []string
+	if err := eventbus.Subscribe(ctx, bus.Bus, func(_ context.Context, e orderCreated) error {
+		handled = append(handled, e.OrderID)
+		if e.OrderID == "bad" {
+			return errors.New("rejected")
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{"o-1", "bad"} {
+		if err := bus.Publish(ctx, orderCreated{OrderID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Publish 返回前处理器已同步执行
+	if len(handled) != 2 {
+		t.Fatalf("handled = %v", handled)
+	}
+	if e := AssertPublished(t, bus, func(e orderCreated) bool { return e.OrderID == "o-1" }); e.OrderID != "o-1" {
+		t.Fatalf("matched = %+v", e)
+	}
+	AssertNotPublished[orderCancelled](t, bus, nil)
+	if n := len(PublishedEvents[orderCreated](bus)); n != 2 {
+		t.Fatalf("published = %d", n)
+	}
+	if errs := bus.HandlerErrors(); len(errs) != 1 {
+		t.Fatalf("handler errors = %v", errs)
+	}
+
+	bus.Reset()
+	if len(bus.Published()) != 0 || len(bus.HandlerErrors()) != 0 {
+		t.Fatal("reset kept captured state")
+	}
+}
@@ -0,0 +1,188 @@
+package eventbus
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/mildsunup/higo/mq"
+)
+
+// Format 事件信封格式
+// 发布端按选定格式编码；消费端自动识别原生信封与 CloudEvents 两种模式。
+type Format interface {
+	// Encode 编码信封，返回消息体与附加消息头
+	Encode(env Envelope) ([]byte, map[string]string, error)
+}
+
+// Native 原生信封格式（默认）
+var Native Format = nativeFormat{}
+
+// CloudEvents CloudEvents 1.0 结构化模式（application/cloudevents+json）
+var CloudEvents Format = CloudEventsFormat{Mode: Structured}
+
+// CloudEventsBinary CloudEvents 1.0 二进制模式，属性放在 Kafka 消息头
+var CloudEventsBinary Format = CloudEventsFormat{Mode: Binary, Binding: KafkaBinding}
+
+// WithFormat 设置信封格式
+func WithFormat(f Format) Option {
+	return func(b *Bus) {
+		b.format = f
+	}
+}
+
+type nativeFormat struct{}
+
+func (nativeFormat) Encode(env Envelope) ([]byte, map[string]string, error) {
+	data, err := json.Marshal(env)
+	return data, nil, err
+}
+
+// ContentMode CloudEvents 内容模式
+type ContentMode int
+
+const (
+	Structured ContentMode = iota // 属性与数据一起编码在消息体
+	Binary                        // 属性放在消息头，消息体仅为数据
+)
+
+// Binding CloudEvents 协议绑定，决定二进制模式下的消息头前缀
+type Binding string
+
+const (
+	KafkaBinding Binding = "ce_"
+	AMQPBinding  Binding = "cloudEvents:"
+)
+
+const (
+	ceSpecVersion     = "1.0"
+	ceStructuredType  = "application/cloudevents+json"
+	ceDataContentType = "application/json"
+	headerContentType = "content-type"
+	defaultCESource   = "higo"
+	ceAttrSpecVersion = "specversion"
+	ceAttrID          = "id"
+	ceAttrSource      = "source"
+	ceAttrType        = "type"
+	ceAttrTime        = "time"
+)
+
+// CloudEventsFormat CloudEvents 1.0 格式
+type CloudEventsFormat struct {
+	Mode    ContentMode
+	Binding Binding // 二进制模式的协议绑定，默认 Kafka
+	Source  string  // source 属性，默认 "higo"
+}
+
+// cloudEvent 结构化模式的 JSON 表示
+type cloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Time            time.Time       `json:"time,omitzero"`
+	DataContentType string          `json:"datacontenttype,omitempty"`
+	Data            json.RawMessage `json:"data,omitempty"`
+}
+
+// Encode 编码为 CloudEvents
+func (f CloudEventsFormat) Encode(env Envelope) ([]byte, map[string]string, error) {
+	data, err := json.Marshal(env.Payload)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	id := env.ID
+	if id == "" {
+		id = uuid.NewString() // CloudEvents 要求 id 必填
+	}
+	source := f.Source
+	if source == "" {
+		source = defaultCESource
+	}
+
+	if f.Mode == Binary {
+		prefix := string(f.Binding)
+		if prefix == "" {
+			prefix = string(KafkaBinding)
+		}
+		headers := map[string]string{
+			prefix + ceAttrSpecVersion: ceSpecVersion,
+			prefix + ceAttrID:          id,
+			prefix + ceAttrSource:      source,
+			prefix + ceAttrType:        env.Name,
+			prefix + ceAttrTime:        env.Timestamp.UTC().Format(time.RFC3339Nano),
+			headerContentType:          ceDataContentType,
+		}
+		return data, headers, nil
+	}
+
+	body, err := json.Marshal(cloudEvent{
+		SpecVersion:     ceSpecVersion,
+		ID:              id,
+		Source:          source,
+		Type:            env.Name,
+		Time:            env.Timestamp,
+		DataContentType: ceDataContentType,
+		Data:            data,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return body, map[string]string{headerContentType: ceStructuredType}, nil
+}
+
+// DecodeEnvelope 解码消息中的事件信封（自动识别原生与 CloudEvents 格式），Payload 为未解码的 json.RawMessage
+func DecodeEnvelope(msg *mq.Message) (Envelope, error) {
+	env, err := decodeMessage(msg)
+	if err != nil {
+		return Envelope{}, err
+	}
+	return Envelope{ID: env.ID, Name: env.Name, Payload: env.Payload, Timestamp: env.Timestamp}, nil
+}
+
+// decodeMessage 自动识别信封格式并解码
+func decodeMessage(msg *mq.Message) (rawEnvelope, error) {
+	var env rawEnvelope
+
+	for _, binding := range []Binding{KafkaBinding, AMQPBinding} {
+		prefix := string(binding)
+		if msg.Headers[prefix+ceAttrSpecVersion] == "" {
+			continue
+		}
+		env.ID = msg.Headers[prefix+ceAttrID]
+		env.Name = msg.Headers[prefix+ceAttrType]
+		env.Payload = msg.Value
+		if ts := msg.Headers[prefix+ceAttrTime]; ts != "" {
+			t, err := time.Parse(time.RFC3339Nano, ts)
+			if err != nil {
+				return env, fmt.Errorf("eventbus: invalid cloudevents time %q: %w", ts, err)
+			}
+			env.Timestamp = t
+		}
+		return env, nil
+	}
+
+	if strings.HasPrefix(msg.Headers[headerContentType], ceStructuredType) || isStructuredCloudEvent(msg.Value) {
+		var ce cloudEvent
+		if err := json.Unmarshal(msg.Value, &ce); err != nil {
+			return env, fmt.Errorf("eventbus: unmarshal cloudevent: %w", err)
+		}
+		return rawEnvelope{ID: ce.ID, Name: ce.Type, Payload: ce.Data, Timestamp: ce.Time}, nil
+	}
+
+	if err := json.Unmarshal(msg.Value, &env); err != nil {
+		return env, fmt.Errorf("eventbus: unmarshal envelope: %w", err)
+	}
+	return env, nil
+}
+
+func isStructuredCloudEvent(data []byte) bool {
+	var probe struct {
+		SpecVersion string `json:"specversion"`
+	}
+	return json.Unmarshal(data, &probe) == nil && probe.SpecVersion != ""
+}
@@ -0,0 +1,140 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mildsunup/higo/mq"
+	"github.com/mildsunup/higo/mq/memory"
+)
+
+func encodeMessage(t *testing.T, f Format, env Envelope) *mq.Message {
+	t.Helper()
+	data, headers, err := f.Encode(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &mq.Message{Value: data, Headers: headers}
+}
+
+func TestFormat_RoundTrip(t *testing.T) {
+	at := time.Date(2024, 5, 1, 8, 30, 0, 123456789, time.UTC)
+	env := Envelope{ID: "evt-1", Name: "order.paid", Payload: orderPaid{OrderID: "o-1"}, Timestamp: at}
+
+	tests := []struct {
+		name   string
+		format Format
+		header string // 标识格式的消息头
+	}{
+		{"native", Native, ""},
+		{"structured", CloudEvents, headerContentType},
+		{"binary kafka", CloudEventsBinary, "ce_specversion"},
+		{"binary amqp", CloudEventsFormat{Mode: Binary, Binding: AMQPBinding}, "cloudEvents:specversion"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := encodeMessage(t, tt.format, env)
+			if tt.header != "" && msg.Headers[tt.header] == "" {
+				t.Fatalf("headers = %v", msg.Headers)
+			}
+
+			got, err := DecodeEnvelope(msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.ID != "evt-1" || got.Name != "order.paid" || !got.Timestamp.Equal(at) {
+				t.Fatalf("envelope = %+v", got)
+			}
+			var payload orderPaid
+			if err := json.Unmarshal(got.Payload.(json.RawMessage), &payload); err != nil || payload.OrderID != "o-1" {
+				t.Fatalf("payload = %+v, %v", payload, err)
+			}
+		})
+	}
+}
+
+func TestFormat_CloudEventsAttributes(t *testing.T) {
+	env := Envelope{Name: "order.paid", Payload: orderPaid{OrderID: "o-1"}, Timestamp: time.Now()}
+
+	// 结构化模式：缺少 id 时生成，source 默认 higo
+	msg := encodeMessage(t, CloudEvents, env)
+	var ce cloudEvent
+	if err := json.Unmarshal(msg.Value, &ce); err != nil {
+		t.Fatal(err)
+	}
+	if ce.SpecVersion != "1.0" || ce.ID == "" || ce.Source != "higo" || ce.DataContentType != "application/json" {
+		t.Fatalf("cloudevent = %+v", ce)
+	}
+	if msg.Headers[headerContentType] != "application/cloudevents+json" {
+		t.Fatalf("content type = %q", msg.Headers[headerContentType])
+	}
+
+	// 二进制模式：属性在消息头，消息体仅为数据
+	msg = encodeMessage(t, CloudEventsFormat{Mode: Binary, Source: "/orders"}, env)
+	if msg.Headers["ce_id"] == "" || msg.Headers["ce_source"] != "/orders" || msg.Headers["ce_type"] != "order.paid" {
+		t.Fatalf("headers = %v", msg.Headers)
+	}
+	if string(msg.Value) != `{"order_id":"o-1"}` {
+		t.Fatalf("body = %s", msg.Value)
+	}
+}
+
+func TestFormat_DecodeForeignCloudEvents(t *testing.T) {
+	// 其他生产者发出的结构化事件，未带 content-type、id 与 source
+	got, err := DecodeEnvelope(&mq.Message{Value: []byte(`{"specversion":"1.0","type":"order.paid","data":{"order_id":"o-9"}}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != "" || got.Name != "order.paid" || string(got.Payload.(json.RawMessage)) != `{"order_id":"o-9"}` {
+		t.Fatalf("envelope = %+v", got)
+	}
+
+	// 二进制模式缺少 id 与 time
+	got, err = DecodeEnvelope(&mq.Message{
+		Value:   []byte(`{"order_id":"o-9"}`),
+		Headers: map[string]string{"ce_specversion": "1.0", "ce_type": "order.paid"},
+	})
+	if err != nil || got.ID != "" || got.Name != "order.paid" || !got.Timestamp.IsZero() {
+		t.Fatalf("envelope = %+v, %v", got, err)
+	}
+
+	if _, err := DecodeEnvelope(&mq.Message{
+		Headers: map[string]string{"ce_specversion": "1.0", "ce_type": "order.paid", "ce_time": "yesterday"},
+	}); err == nil {
+		t.Fatal("expected invalid time error")
+	}
+	if _, err := DecodeEnvelope(&mq.Message{
+		Value:   []byte("{"),
+		Headers: map[string]string{headerContentType: "application/cloudevents+json"},
+	}); err == nil {
+		t.Fatal("expected unmarshal error")
+	}
+}
+
+func TestFormat_SubscribeBinary(t *testing.T) {
+	ctx := context.Background()
+	client := memory.New("test")
+	defer client.Close()
+	bus := New(client, WithConsumer(client), WithFormat(CloudEventsBinary), WithIDFunc(func() string { return "evt-7" }))
+
+	got := make(chan orderPaid, 1)
+	if err := Subscribe(ctx, bus, func(_ context.Context, e orderPaid) error {
+		got <- e
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := bus.Publish(ctx, orderPaid{OrderID: "o-1"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case e := <-got:
+		if e.OrderID != "o-1" {
+			t.Fatalf("event = %+v", e)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("binary cloudevent not delivered")
+	}
+}
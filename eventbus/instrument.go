@@ -0,0 +1,93 @@
+package eventbus
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/mildsunup/higo/mq"
+)
+
+// WithTracer 启用链路追踪
+// 生产者与消费者实现 mq.Client 时以 mq.Traced 装饰（已装饰的不再重复）：发布时创建 producer span
+// 并将 trace context 注入消息头，消费时提取并创建 consumer span，总线在其上补充事件名。
+func WithTracer(tracer trace.Tracer) Option {
+	return func(b *Bus) {
+		b.tracer = tracer
+	}
+}
+
+// instrumentTransport 按 WithTracer 装饰生产者与消费者
+func (b *Bus) instrumentTransport() {
+	if b.tracer == nil {
+		return
+	}
+	if c, ok := b.producer.(mq.Client); ok {
+		b.producer = withTracing(c, b.tracer)
+	}
+	if c, ok := b.consumer.(mq.Client); ok {
+		b.consumer = withTracing(c, b.tracer)
+	}
+}
+
+func withTracing(c mq.Client, tracer trace.Tracer) mq.Client {
+	for inner := c; ; {
+		switch v := inner.(type) {
+		case *mq.Traced:
+			return c
+		case interface{ Unwrap() mq.Client }:
+			inner = v.Unwrap()
+		default:
+			return mq.NewTraced(c, tracer)
+		}
+	}
+}
+
+// batchProducer 底层生产者支持批量发送时返回 b.producer 的批量接口
+func (b *Bus) batchProducer() (mq.BatchProducer, bool) {
+	inner := b.producer
+	if c, ok := inner.(mq.Client); ok {
+		inner = mq.Unwrap(c)
+	}
+	if _, ok := inner.(mq.BatchProducer); !ok {
+		return nil, false
+	}
+	batcher, ok := b.producer.(mq.BatchProducer)
+	return batcher, ok
+}
+
+// finishPublish 返回发布结束回调，记录发布指标
+func (b *Bus) finishPublish(name string) func(error) {
+	return func(err error) {
+		if b.metrics != nil {
+			b.metrics.published.Inc(name, resultLabel(err))
+		}
+	}
+}
+
+// startConsume 开始消费埋点，在 mq.Traced 创建的 consumer span 上补充事件信息
+func (b *Bus) startConsume(ctx context.Context, name string) func(error) {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("event.name", name))
+	return func(err error) {
+		if b.metrics != nil {
+			b.metrics.consumed.Inc(name, resultLabel(err))
+		}
+	}
+}
+
+// observeHandler 记录处理器耗时与重试次数
+func (m *busMetrics) observeHandler(name, handler string, start time.Time, attempts int) {
+	m.latency.Since(start, name, handler)
+	if attempts > 1 {
+		m.retries.Add(float64(attempts-1), name, handler)
+	}
+}
+
+func resultLabel(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
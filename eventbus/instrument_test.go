@@ -0,0 +1,180 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/mildsunup/higo/mq"
+	"github.com/mildsunup/higo/mq/memory"
+	"github.com/mildsunup/higo/observability"
+)
+
+type orderPaid struct {
+	OrderID string `json:"order_id"`
+}
+
+func (orderPaid) EventName() string { return "order.paid" }
+
+// batchClient 支持批量发布的内存客户端，记录批量消息头
+type batchClient struct {
+	*memory.Client
+	mu      sync.Mutex
+	batches [][]mq.BatchMessage
+}
+
+func (c *batchClient) PublishBatch(ctx context.Context, topic string, msgs []mq.BatchMessage) []mq.BatchResult {
+	c.mu.Lock()
+	c.batches = append(c.batches, msgs)
+	c.mu.Unlock()
+	results := make([]mq.BatchResult, len(msgs))
+	for i, m := range msgs {
+		results[i].Result, results[i].Err = c.Publish(ctx, topic, m.Value, mq.WithHeaders(m.Headers))
+	}
+	return results
+}
+
+func newRecordingTracer() (trace.Tracer, *tracetest.SpanRecorder) {
+	rec := tracetest.NewSpanRecorder()
+	return sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)).Tracer("eventbus"), rec
+}
+
+func counterValue(t *testing.T, reg *prometheus.Registry, name string, labels map[string]string) float64 {
+	t.Helper()
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range families {
+		if f.GetName() != name {
+			continue
+		}
+	next:
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if v, ok := labels[l.GetName()]; ok && v != l.GetValue() {
+					continue next
+				}
+			}
+			return m.GetCounter().GetValue()
+		}
+	}
+	return 0
+}
+
+func TestWithTracer_PropagatesThroughMQ(t *testing.T) {
+	ctx := context.Background()
+	tracer, rec := newRecordingTracer()
+	client := memory.New("test")
+	defer client.Close()
+	reg := prometheus.NewRegistry()
+	// 已由 mq.Traced 装饰的客户端不重复装饰
+	traced := mq.NewTraced(client, tracer)
+	bus := New(traced, WithConsumer(client), WithTracer(tracer), WithMetrics(observability.NewPrometheusProvider(reg)))
+
+	got := make(chan trace.SpanContext, 2)
+	if err := Subscribe(ctx, bus, func(ctx context.Context, e orderPaid) error {
+		got <- trace.SpanContextFromContext(ctx)
+		if e.OrderID == "bad" {
+			return errors.New("rejected")
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	parent, span := tracer.Start(ctx, "POST /orders/pay")
+	for _, id := range []string{"o-1", "bad"} {
+		if err := bus.Publish(parent, orderPaid{OrderID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	span.End()
+
+	for range 2 {
+		select {
+		case sc := <-got:
+			if sc.TraceID() != span.SpanContext().TraceID() {
+				t.Fatal("handler not in the publisher's trace")
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("event not delivered")
+		}
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for counterValue(t, reg, "eventbus_events_consumed_total", map[string]string{"result": "error"}) != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("consume metrics not recorded")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := counterValue(t, reg, "eventbus_events_published_total", map[string]string{"event": "order.paid", "result": "success"}); n != 2 {
+		t.Fatalf("published = %v", n)
+	}
+
+	var publishes, consumes int
+	for _, s := range rec.Ended() {
+		switch s.Name() {
+		case "mq.Publish":
+			publishes++
+			if s.Parent().SpanID() != span.SpanContext().SpanID() {
+				t.Fatal("publish span not a child of the caller span")
+			}
+		case "mq.Consume":
+			consumes++
+			var name string
+			for _, kv := range s.Attributes() {
+				if kv.Key == "event.name" {
+					name = kv.Value.AsString()
+				}
+			}
+			if name != "order.paid" || s.SpanKind() != trace.SpanKindConsumer {
+				t.Fatalf("consume span event = %q, kind = %v", name, s.SpanKind())
+			}
+		}
+	}
+	if publishes != 2 || consumes != 2 {
+		t.Fatalf("publish spans = %d, consume spans = %d", publishes, consumes)
+	}
+}
+
+func TestWithTracer_KeepsBatchProducer(t *testing.T) {
+	ctx := context.Background()
+	tracer, rec := newRecordingTracer()
+	client := &batchClient{Client: memory.New("batch")}
+	defer client.Close()
+	bus := New(client, WithTracer(tracer))
+
+	parent, span := tracer.Start(ctx, "import")
+	results, err := bus.PublishBatch(parent, orderPaid{OrderID: "o-1"}, orderPaid{OrderID: "o-2"})
+	span.End()
+	if err != nil || len(results) != 2 {
+		t.Fatalf("publish batch = %v, %v", results, err)
+	}
+
+	if len(client.batches) != 1 || len(client.batches[0]) != 2 {
+		t.Fatalf("batches = %v", client.batches)
+	}
+	for _, m := range client.batches[0] {
+		if m.Headers["traceparent"] == "" {
+			t.Fatalf("batch message without trace context: %v", m.Headers)
+		}
+	}
+	var batchSpans int
+	for _, s := range rec.Ended() {
+		if s.Name() == "mq.PublishBatch" && s.Parent().SpanID() == span.SpanContext().SpanID() {
+			batchSpans++
+		}
+	}
+	if batchSpans != 1 {
+		t.Fatalf("batch spans = %d", batchSpans)
+	}
+}
@@ -0,0 +1,101 @@
+package eventbus
+
+import (
+	"errors"
+
+	"github.com/mildsunup/higo/observability"
+)
+
+// Disposition 处理失败后的处置方式
+type Disposition int
+
+const (
+	DispositionRetry Disposition = iota // 按处理器重试策略退避重试，耗尽后转投死信
+	DispositionDrop                     // 立即丢弃（确认消息）并记录指标
+	DispositionPark                     // 立即隔离到死信主题，等待人工处理或重放
+)
+
+func (d Disposition) String() string {
+	switch d {
+	case DispositionRetry:
+		return "retry"
+	case DispositionDrop:
+		return "drop"
+	case DispositionPark:
+		return "park"
+	default:
+		return "unknown"
+	}
+}
+
+// HandlerError 带处置方式的处理器错误
+type HandlerError struct {
+	Disposition Disposition
+	Err         error
+}
+
+func (e *HandlerError) Error() string {
+	if e.Err == nil {
+		return e.Disposition.String()
+	}
+	return e.Err.Error()
+}
+
+func (e *HandlerError) Unwrap() error { return e.Err }
+
+// Retryable 标记为可重试错误（未分类错误的默认处置）
+func Retryable(err error) error {
+	return classify(err, DispositionRetry)
+}
+
+// Fatal 标记为不可恢复错误，事件被丢弃
+func Fatal(err error) error {
+	return classify(err, DispositionDrop)
+}
+
+// Park 标记为需隔离的错误，事件转投死信主题
+func Park(err error) error {
+	return classify(err, DispositionPark)
+}
+
+func classify(err error, d Disposition) error {
+	if err == nil {
+		return nil
+	}
+	return &HandlerError{Disposition: d, Err: err}
+}
+
+// DispositionOf 获取错误的处置方式，未分类错误视为可重试
+func DispositionOf(err error) Disposition {
+	var he *HandlerError
+	if errors.As(err, &he) {
+		return he.Disposition
+	}
+	return DispositionRetry
+}
+
+// WithMetrics 启用事件总线指标
+func WithMetrics(p observability.MetricsProvider) Option {
+	return func(b *Bus) {
+		b.metrics = newBusMetrics(p)
+	}
+}
+
+// busMetrics 事件总线指标
+type busMetrics struct {
+	dropped   observability.Counter
+	published observability.Counter
+	consumed  observability.Counter
+	retries   observability.Counter
+	latency   observability.Histogram
+}
+
+func newBusMetrics(p observability.MetricsProvider) *busMetrics {
+	return &busMetrics{
+		dropped:   p.Counter("eventbus_events_dropped_total", "Total events dropped by handler policy", "event"),
+		published: p.Counter("eventbus_events_published_total", "Total events published", "event", "result"),
+		consumed:  p.Counter("eventbus_events_consumed_total", "Total events consumed", "event", "result"),
+		retries:   p.Counter("eventbus_handler_retries_total", "Total handler retry attempts", "event", "handler"),
+		latency:   p.Histogram("eventbus_handler_duration_seconds", "Event handler latency", nil, "event", "handler"),
+	}
+}
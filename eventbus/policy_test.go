@@ -0,0 +1,135 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/mildsunup/higo/mq"
+	"github.com/mildsunup/higo/mq/memory"
+	"github.com/mildsunup/higo/observability"
+)
+
+func TestDispositionOf(t *testing.T) {
+	boom := errors.New("boom")
+	tests := []struct {
+		err  error
+		want Disposition
+	}{
+		{boom, DispositionRetry},
+		{Retryable(boom), DispositionRetry},
+		{Fatal(boom), DispositionDrop},
+		{Park(boom), DispositionPark},
+		{fmt.Errorf("charge: %w", Fatal(boom)), DispositionDrop},
+	}
+	for _, tt := range tests {
+		if got := DispositionOf(tt.err); got != tt.want {
+			t.Errorf("DispositionOf(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+
+	// 分类不改变错误文本与错误链
+	if err := Park(boom); err.Error() != "boom" || !errors.Is(err, boom) {
+		t.Fatalf("park = %v", err)
+	}
+	if Fatal(nil) != nil || (&HandlerError{Disposition: DispositionDrop}).Error() != "drop" {
+		t.Fatal("nil classification")
+	}
+}
+
+func TestPolicy_FatalDropsWithoutRetry(t *testing.T) {
+	client := memory.New("test")
+	defer client.Close()
+	producer := &captureProducer{}
+	reg := prometheus.NewRegistry()
+	bus := New(producer, WithConsumer(client), WithDeadLetter("orders.dlq"), WithMetrics(observability.NewPrometheusProvider(reg)))
+
+	calls := 0
+	if err := Subscribe(context.Background(), bus, func(context.Context, orderPaid) error {
+		calls++
+		return Fatal(errors.New("unknown currency"))
+	}, WithHandlerRetry(5, time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+
+	msg, _ := preparedMessage(t, bus, orderPaid{OrderID: "o-1"})
+	if err := bus.dispatcher(msg.Topic)(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 || len(producer.published()) != 0 {
+		t.Fatalf("calls = %d, dead letters = %d", calls, len(producer.published()))
+	}
+	if v := counterValue(t, reg, "eventbus_events_dropped_total", map[string]string{"event": "order.paid"}); v != 1 {
+		t.Fatalf("dropped = %v", v)
+	}
+}
+
+func TestPolicy_ParkSkipsRetry(t *testing.T) {
+	bus, producer, calls := newFailingBus(t, func() error { return Park(errors.New("manual review")) }, WithHandlerRetry(5, time.Millisecond))
+	msg, _ := preparedMessage(t, bus, orderPaid{OrderID: "o-1"})
+	if err := bus.dispatcher(msg.Topic)(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+
+	sent := producer.published()
+	if calls.Load() != 1 || len(sent) != 1 {
+		t.Fatalf("calls = %d, dead letters = %d", calls.Load(), len(sent))
+	}
+	dl := ParseDeadLetter(&mq.Message{Headers: sent[0].Headers})
+	if dl.Reason != ReasonParked || dl.Attempts != 0 || dl.Error != "manual review" {
+		t.Fatalf("dead letter = %+v", dl)
+	}
+}
+
+func TestPolicy_RetryableRecovers(t *testing.T) {
+	n := 0
+	bus, producer, calls := newFailingBus(t, func() error {
+		if n++; n < 3 {
+			return Retryable(errors.New("timeout"))
+		}
+		return nil
+	}, WithHandlerRetry(3, time.Millisecond))
+	msg, _ := preparedMessage(t, bus, orderPaid{OrderID: "o-1"})
+	if err := bus.dispatcher(msg.Topic)(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 3 || len(producer.published()) != 0 {
+		t.Fatalf("calls = %d, dead letters = %d", calls.Load(), len(producer.published()))
+	}
+}
+
+func TestPolicy_MixedHandlers(t *testing.T) {
+	bus, producer, _ := newFailingBus(t, func() error { return nil })
+	dropped := 0
+	if err := Subscribe(context.Background(), bus, func(context.Context, orderPaid) error {
+		dropped++
+		return Fatal(errors.New("ignored"))
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 其余处理器成功、丢弃的处理器不转投死信
+	msg, _ := preparedMessage(t, bus, orderPaid{OrderID: "o-1"})
+	if err := bus.dispatcher(msg.Topic)(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+	if dropped != 1 || len(producer.published()) != 0 {
+		t.Fatalf("dropped = %d, dead letters = %d", dropped, len(producer.published()))
+	}
+
+	if err := Subscribe(context.Background(), bus, func(context.Context, orderPaid) error {
+		return Park(errors.New("parked"))
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := bus.dispatcher(msg.Topic)(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+	if sent := producer.published(); len(sent) != 1 || ParseDeadLetter(&mq.Message{Headers: sent[0].Headers}).Reason != ReasonParked {
+		t.Fatalf("dead letters = %+v", sent)
+	}
+}
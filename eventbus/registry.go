@@ -0,0 +1,73 @@
+package eventbus
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Registry 事件类型注册表，按事件名解析具体类型
+type Registry struct {
+	mu    sync.RWMutex
+	types map[string]reflect.Type
+}
+
+// NewRegistry 创建事件类型注册表
+func NewRegistry() *Registry {
+	return &Registry{types: make(map[string]reflect.Type)}
+}
+
+// Register 注册事件类型，返回事件名
+func Register[E Event](r *Registry) string {
+	name := eventName[E]()
+	r.mu.Lock()
+	r.types[name] = reflect.TypeFor[E]()
+	r.mu.Unlock()
+	return name
+}
+
+// Lookup 按事件名查找类型
+func (r *Registry) Lookup(name string) (reflect.Type, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.types[name]
+	return t, ok
+}
+
+// Decode 按事件名将 payload 解码为已注册的具体类型
+func (r *Registry) Decode(name string, payload []byte) (any, error) {
+	t, ok := r.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("eventbus: unregistered event %q", name)
+	}
+
+	var ptr reflect.Value
+	if t.Kind() == reflect.Pointer {
+		ptr = reflect.New(t.Elem())
+	} else {
+		ptr = reflect.New(t)
+	}
+	if err := json.Unmarshal(payload, ptr.Interface()); err != nil {
+		return nil, fmt.Errorf("eventbus: decode %s: %w", name, err)
+	}
+
+	if t.Kind() == reflect.Pointer {
+		return ptr.Interface(), nil
+	}
+	return ptr.Elem().Interface(), nil
+}
+
+// eventName 取事件类型的名称
+func eventName[E Event]() string {
+	return zeroEvent[E]().EventName()
+}
+
+// zeroEvent 返回可安全调用方法的零值实例，指针类型分配新的零值对象而非 nil
+func zeroEvent[E Event]() E {
+	var zero E
+	if t := reflect.TypeFor[E](); t.Kind() == reflect.Pointer {
+		zero = reflect.New(t.Elem()).Interface().(E)
+	}
+	return zero
+}
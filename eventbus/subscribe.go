@@ -0,0 +1,245 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/mildsunup/higo/mq"
+	"github.com/mildsunup/higo/resilience"
+)
+
+// ErrNoConsumer 未配置消费者
+var ErrNoConsumer = errors.New("eventbus: consumer not configured")
+
+// HandlerOption 事件处理器选项
+type HandlerOption func(*handlerConfig)
+
+type handlerConfig struct {
+	id          string
+	concurrency int
+	maxAttempts int
+	retryDelay  time.Duration
+}
+
+// WithHandlerConcurrency 设置处理器最大并发数
+func WithHandlerConcurrency(n int) HandlerOption {
+	return func(c *handlerConfig) {
+		if n > 0 {
+			c.concurrency = n
+		}
+	}
+}
+
+// WithHandlerRetry 设置处理器重试（总尝试次数与初始延迟）
+func WithHandlerRetry(maxAttempts int, delay time.Duration) HandlerOption {
+	return func(c *handlerConfig) {
+		c.maxAttempts = maxAttempts
+		c.retryDelay = delay
+	}
+}
+
+// WithHandlerID 设置处理器标识，用于消费去重，默认 "<事件名>#<注册序号>"
+// 多实例部署时各实例的注册顺序应一致，否则请显式指定。
+func WithHandlerID(id string) HandlerOption {
+	return func(c *handlerConfig) {
+		c.id = id
+	}
+}
+
+// subscription 已注册的事件处理器
+type subscription struct {
+	id       string
+	event    string
+	handle   func(ctx context.Context, event any) error
+	sem      chan struct{}
+	retry    *resilience.Retry
+	attempts int
+	metrics  *busMetrics
+}
+
+func (s *subscription) invoke(ctx context.Context, event any) error {
+	select {
+	case s.sem <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-s.sem }()
+
+	start, attempts := time.Now(), 0
+	err := s.retry.Execute(ctx, func(ctx context.Context) error {
+		attempts++
+		return s.handle(ctx, event)
+	})
+	if s.metrics != nil {
+		s.metrics.observeHandler(s.event, s.id, start, attempts)
+	}
+	return err
+}
+
+// topicRoute 单个主题下按事件名分发的处理器
+type topicRoute struct {
+	handlers    map[string][]*subscription
+	concurrency int
+}
+
+// Subscribe 订阅事件，按事件类型分发到强类型处理器
+//
+//	eventbus.Subscribe(ctx, bus, func(ctx context.Context, e OrderCreated) error { ... },
+//	    eventbus.WithHandlerConcurrency(4),
+//	    eventbus.WithHandlerRetry(3, time.Second),
+//	)
+func Subscribe[E Event](ctx context.Context, b *Bus, handler func(ctx context.Context, event E) error, opts ...HandlerOption) error {
+	if b.consumer == nil {
+		return ErrNoConsumer
+	}
+
+	cfg := handlerConfig{concurrency: 1, maxAttempts: 1}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	name := Register[E](b.registry)
+	attempts := max(cfg.maxAttempts, 1)
+	sub := &subscription{
+		id:    cfg.id,
+		event: name,
+		handle: func(ctx context.Context, event any) error {
+			e, ok := event.(E)
+			if !ok {
+				return fmt.Errorf("eventbus: unexpected event type %T for %s", event, name)
+			}
+			return handler(ctx, e)
+		},
+		sem: make(chan struct{}, cfg.concurrency),
+		retry: resilience.NewRetry(
+			resilience.WithMaxAttempts(attempts),
+			resilience.WithDelay(cfg.retryDelay),
+			resilience.WithRetryIf(func(err error) bool {
+				return err != nil && DispositionOf(err) == DispositionRetry
+			}),
+		),
+		attempts: attempts,
+		metrics:  b.metrics,
+	}
+
+	topic := b.topicFor(zeroEvent[E]())
+	return b.addSubscription(ctx, topic, name, sub, cfg.concurrency)
+}
+
+func (b *Bus) addSubscription(ctx context.Context, topic, name string, sub *subscription, concurrency int) error {
+	b.mu.Lock()
+	route, exists := b.routes[topic]
+	if !exists {
+		route = &topicRoute{handlers: make(map[string][]*subscription)}
+		b.routes[topic] = route
+	}
+	if sub.id == "" {
+		sub.id = name + "#" + strconv.Itoa(len(route.handlers[name]))
+	}
+	route.handlers[name] = append(route.handlers[name], sub)
+	route.concurrency = max(route.concurrency, concurrency)
+	b.mu.Unlock()
+
+	if exists {
+		return nil
+	}
+
+	subOpts := []mq.SubscribeOption{mq.WithConcurrency(concurrency)}
+	if b.config.Group != "" {
+		subOpts = append(subOpts, mq.WithGroup(b.config.Group))
+	}
+	if err := b.consumer.Subscribe(ctx, topic, b.dispatcher(topic), subOpts...); err != nil {
+		b.mu.Lock()
+		delete(b.routes, topic)
+		b.mu.Unlock()
+		return fmt.Errorf("eventbus: subscribe %s: %w", topic, err)
+	}
+	return nil
+}
+
+// rawEnvelope 消费端信封，payload 延迟解码
+type rawEnvelope struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Payload   json.RawMessage `json:"payload"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+func (b *Bus) dispatcher(topic string) mq.Handler {
+	return func(ctx context.Context, msg *mq.Message) error {
+		env, err := decodeMessage(msg)
+		if err != nil {
+			return b.deadLetter(ctx, topic, msg, err, 0, ReasonUndecodable)
+		}
+
+		b.mu.RLock()
+		subs := b.routes[topic].handlers[env.Name]
+		b.mu.RUnlock()
+		if len(subs) == 0 {
+			return nil // 本实例未订阅该事件
+		}
+
+		finish := b.startConsume(ctx, env.Name)
+		attempts, reason, err := b.dispatch(ctx, env, subs)
+		finish(err)
+		if err == nil {
+			return nil
+		}
+		if errors.Is(err, ErrEventInProgress) {
+			return err // 仅剩处理中的事件，交由 MQ 重投
+		}
+		return b.deadLetter(ctx, topic, msg, err, attempts, reason)
+	}
+}
+
+// dispatch 解码事件并依次交给处理器，返回需转入死信的错误及其原因
+func (b *Bus) dispatch(ctx context.Context, env rawEnvelope, subs []*subscription) (int, string, error) {
+	event, err := b.registry.Decode(env.Name, env.Payload)
+	if err != nil {
+		return 0, ReasonUndecodable, err
+	}
+
+	var errs, inProgress []error
+	attempts, reason := 0, ReasonParked
+	for _, sub := range subs {
+		err := b.invokeOnce(ctx, env.ID, sub, event)
+		if errors.Is(err, ErrEventInProgress) {
+			inProgress = append(inProgress, err)
+			continue
+		}
+		switch DispositionOf(err) {
+		case DispositionDrop:
+			if b.metrics != nil {
+				b.metrics.dropped.Inc(env.Name)
+			}
+			continue
+		case DispositionRetry:
+			if err != nil {
+				reason = ReasonExhausted
+				attempts = max(attempts, sub.attempts)
+			}
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		// 其余处理器已完成，处理中的事件重投后由去重跳过已完成部分
+		return 0, "", errors.Join(inProgress...)
+	}
+	return attempts, reason, errors.Join(errs...)
+}
+
+// topicFor 计算事件主题
+func (b *Bus) topicFor(event any) string {
+	if b.config.TopicFunc != nil {
+		if e, ok := event.(Event); ok {
+			return b.config.TopicFunc(e)
+		}
+	}
+	return b.config.Topic
+}
@@ -0,0 +1,46 @@
+package eventbus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mildsunup/higo/mq/memory"
+)
+
+type orderShipped struct {
+	OrderID string `json:"order_id"`
+	region  string
+}
+
+// EventName 解引用接收者，nil 指针调用会 panic
+func (e *orderShipped) EventName() string { return "order.shipped" + e.region }
+
+func TestSubscribe_PointerEventWithTopicFunc(t *testing.T) {
+	ctx := context.Background()
+	client := memory.New("test")
+	defer client.Close()
+	bus := New(client, WithConsumer(client), WithTopicFunc(func(e Event) string {
+		return "topic." + e.EventName()
+	}))
+
+	got := make(chan *orderShipped, 1)
+	if err := Subscribe(ctx, bus, func(_ context.Context, e *orderShipped) error {
+		got <- e
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := bus.Publish(ctx, &orderShipped{OrderID: "o-1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case e := <-got:
+		if e.OrderID != "o-1" {
+			t.Fatalf("event = %+v", e)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("event not delivered on the routed topic")
+	}
+}
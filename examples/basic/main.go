@@ -43,7 +43,7 @@ func main() {
 	}, runtime.WithLogger(log))
 
 	// 注册组件
-	app.Register(httpServer, 100)
+	app.Register(httpServer, runtime.Priority(100))
 
 	// 生命周期钩子
 	app.OnAfterStart(func(ctx context.Context) error {
@@ -93,7 +93,7 @@ func withConfig() {
 		ShutdownTimeout: 30 * time.Second,
 	}, runtime.WithLogger(log))
 
-	app.Register(httpServer, 100)
+	app.Register(httpServer, runtime.Priority(100))
 
 	_ = app.Run(context.Background())
 }
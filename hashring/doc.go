@@ -0,0 +1,21 @@
+// Package hashring 提供客户端分片原语。
+//
+// 核心功能：
+//   - 一致性哈希（虚拟节点、权重），节点增删仅迁移少量键
+//   - Rendezvous（HRW）哈希，无需虚拟节点、分布均匀
+//   - 统一的 Picker 接口，可用于缓存分片与分区路由
+//
+// 使用示例：
+//
+//	ring := hashring.New(hashring.WithReplicas(160))
+//	ring.Add("redis-a", "redis-b", "redis-c")
+//	node, ok := ring.Get("user:42")
+//
+//	// 取多个副本节点（去重、按优先级排序）
+//	nodes := ring.GetN("user:42", 2)
+//
+//	// Rendezvous 哈希
+//	hrw := hashring.NewRendezvous()
+//	hrw.Add("p0", "p1", "p2")
+//	partition, _ := hrw.Get("order:1001")
+package hashring
@@ -0,0 +1,301 @@
+package hashring
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Hasher 哈希函数
+type Hasher func(data []byte) uint64
+
+// Picker 节点选择器
+type Picker interface {
+	// Get 选择键所属节点
+	Get(key string) (string, bool)
+	// GetN 按优先级选择最多 n 个不同节点
+	GetN(key string, n int) []string
+	// Add 添加节点
+	Add(nodes ...string)
+	// Remove 移除节点
+	Remove(node string)
+	// Nodes 返回所有节点
+	Nodes() []string
+}
+
+// DefaultHash 默认哈希：FNV-1a 后接 64 位混淆，改善短键分布
+func DefaultHash(data []byte) uint64 {
+	h := fnv.New64a()
+	h.Write(data)
+	return mix64(h.Sum64())
+}
+
+// mix64 murmur3 fmix64
+func mix64(x uint64) uint64 {
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+// Option 选项
+type Option func(*options)
+
+type options struct {
+	replicas int
+	hash     Hasher
+}
+
+// WithReplicas 设置每个节点的虚拟节点数，默认 160
+func WithReplicas(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.replicas = n
+		}
+	}
+}
+
+// WithHasher 设置哈希函数
+func WithHasher(h Hasher) Option {
+	return func(o *options) {
+		if h != nil {
+			o.hash = h
+		}
+	}
+}
+
+func applyOptions(opts []Option) options {
+	o := options{replicas: 160, hash: DefaultHash}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Ring 一致性哈希环
+type Ring struct {
+	opts options
+
+	mu      sync.RWMutex
+	hashes  []uint64          // 有序虚拟节点哈希
+	owners  map[uint64]string // 虚拟节点 -> 节点
+	weights map[string]int
+}
+
+// New 创建一致性哈希环
+func New(opts ...Option) *Ring {
+	return &Ring{
+		opts:    applyOptions(opts),
+		owners:  make(map[uint64]string),
+		weights: make(map[string]int),
+	}
+}
+
+// Add 添加节点（权重 1）
+func (r *Ring) Add(nodes ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, node := range nodes {
+		r.weights[node] = 1
+	}
+	r.rebuild()
+}
+
+// AddWeighted 添加带权重的节点，虚拟节点数按权重倍增
+func (r *Ring) AddWeighted(node string, weight int) {
+	if weight <= 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.weights[node] = weight
+	r.rebuild()
+}
+
+// Remove 移除节点
+func (r *Ring) Remove(node string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.weights, node)
+	r.rebuild()
+}
+
+// Nodes 返回所有节点（已排序）
+func (r *Ring) Nodes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return sortedKeys(r.weights)
+}
+
+// Get 选择键所属节点
+func (r *Ring) Get(key string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(r.hashes) == 0 {
+		return "", false
+	}
+	return r.owners[r.hashes[r.search(key)]], true
+}
+
+// GetN 沿环顺时针选择最多 n 个不同节点
+func (r *Ring) GetN(key string, n int) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(r.hashes) == 0 || n <= 0 {
+		return nil
+	}
+	n = min(n, len(r.weights))
+
+	result := make([]string, 0, n)
+	seen := make(map[string]struct{}, n)
+	for i, start := 0, r.search(key); i < len(r.hashes) && len(result) < n; i++ {
+		node := r.owners[r.hashes[(start+i)%len(r.hashes)]]
+		if _, ok := seen[node]; ok {
+			continue
+		}
+		seen[node] = struct{}{}
+		result = append(result, node)
+	}
+	return result
+}
+
+func (r *Ring) search(key string) int {
+	h := r.opts.hash([]byte(key))
+	i := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= h })
+	if i == len(r.hashes) {
+		i = 0
+	}
+	return i
+}
+
+// rebuild 重建虚拟节点，需持有写锁
+func (r *Ring) rebuild() {
+	r.hashes = r.hashes[:0]
+	clear(r.owners)
+
+	for _, node := range sortedKeys(r.weights) {
+		for i := 0; i < r.opts.replicas*r.weights[node]; i++ {
+			h := r.opts.hash([]byte(node + "#" + strconv.Itoa(i)))
+			if _, exists := r.owners[h]; exists {
+				continue // 哈希冲突时保留先到者，结果与添加顺序无关
+			}
+			r.owners[h] = node
+			r.hashes = append(r.hashes, h)
+		}
+	}
+	sort.Slice(r.hashes, func(i, j int) bool { return r.hashes[i] < r.hashes[j] })
+}
+
+// Rendezvous 最高随机权重（HRW）哈希
+type Rendezvous struct {
+	opts options
+
+	mu    sync.RWMutex
+	nodes []string
+}
+
+// NewRendezvous 创建 Rendezvous 哈希
+func NewRendezvous(opts ...Option) *Rendezvous {
+	return &Rendezvous{opts: applyOptions(opts)}
+}
+
+// Add 添加节点
+func (h *Rendezvous) Add(nodes ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, node := range nodes {
+		if !contains(h.nodes, node) {
+			h.nodes = append(h.nodes, node)
+		}
+	}
+	sort.Strings(h.nodes)
+}
+
+// Remove 移除节点
+func (h *Rendezvous) Remove(node string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, n := range h.nodes {
+		if n == node {
+			h.nodes = append(h.nodes[:i], h.nodes[i+1:]...)
+			return
+		}
+	}
+}
+
+// Nodes 返回所有节点（已排序）
+func (h *Rendezvous) Nodes() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return append([]string(nil), h.nodes...)
+}
+
+// Get 选择得分最高的节点
+func (h *Rendezvous) Get(key string) (string, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var best string
+	var bestScore uint64
+	for _, node := range h.nodes {
+		if s := h.score(key, node); best == "" || s > bestScore {
+			best, bestScore = node, s
+		}
+	}
+	return best, best != ""
+}
+
+// GetN 按得分降序选择最多 n 个节点
+func (h *Rendezvous) GetN(key string, n int) []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if n <= 0 || len(h.nodes) == 0 {
+		return nil
+	}
+
+	type scored struct {
+		node  string
+		score uint64
+	}
+	all := make([]scored, len(h.nodes))
+	for i, node := range h.nodes {
+		all[i] = scored{node, h.score(key, node)}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].score > all[j].score })
+
+	result := make([]string, 0, min(n, len(all)))
+	for _, s := range all[:min(n, len(all))] {
+		result = append(result, s.node)
+	}
+	return result
+}
+
+func (h *Rendezvous) score(key, node string) uint64 {
+	return h.opts.hash([]byte(node + "\x00" + key))
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(s []string, v string) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	_ Picker = (*Ring)(nil)
+	_ Picker = (*Rendezvous)(nil)
+)
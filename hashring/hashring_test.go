@@ -0,0 +1,62 @@
+package hashring
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestRing(t *testing.T) {
+	ring := New()
+	if _, ok := ring.Get("k"); ok {
+		t.Fatal("empty ring should not return a node")
+	}
+
+	ring.Add("a", "b", "c")
+
+	counts := make(map[string]int)
+	before := make(map[string]string)
+	for i := 0; i < 3000; i++ {
+		key := "key:" + strconv.Itoa(i)
+		node, _ := ring.Get(key)
+		counts[node]++
+		before[key] = node
+	}
+	for node, n := range counts {
+		if n < 700 || n > 1300 {
+			t.Errorf("unbalanced distribution for %s: %d", node, n)
+		}
+	}
+
+	ring.Remove("c")
+	for key, node := range before {
+		if node == "c" {
+			continue
+		}
+		if got, _ := ring.Get(key); got != node {
+			t.Fatalf("key %s moved from %s to %s after removing another node", key, node, got)
+		}
+	}
+
+	if nodes := ring.GetN("key:1", 5); len(nodes) != 2 || nodes[0] == nodes[1] {
+		t.Errorf("unexpected GetN result: %v", nodes)
+	}
+}
+
+func TestRendezvous(t *testing.T) {
+	h := NewRendezvous()
+	h.Add("a", "b", "c", "a")
+	if len(h.Nodes()) != 3 {
+		t.Fatalf("expected 3 nodes, got %v", h.Nodes())
+	}
+
+	first, _ := h.Get("order:1")
+	nodes := h.GetN("order:1", 3)
+	if len(nodes) != 3 || nodes[0] != first {
+		t.Errorf("GetN should start with Get result: %v vs %s", nodes, first)
+	}
+
+	h.Remove(nodes[1])
+	if got, _ := h.Get("order:1"); got != first {
+		t.Errorf("removing a lower-ranked node should not move the key")
+	}
+}
@@ -0,0 +1,84 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/mildsunup/higo/cache"
+)
+
+// Checkpoints 断点存储：记录每个导入任务已处理到的行号
+type Checkpoints interface {
+	// Load 返回已处理到的行号，不存在时返回 0
+	Load(ctx context.Context, key string) (int, error)
+	Save(ctx context.Context, key string, line int) error
+	Delete(ctx context.Context, key string) error
+}
+
+// MemoryCheckpoints 内存断点存储，适用于单进程内重试与测试
+type MemoryCheckpoints struct {
+	mu    sync.Mutex
+	lines map[string]int
+}
+
+// NewMemoryCheckpoints 创建内存断点存储
+func NewMemoryCheckpoints() *MemoryCheckpoints {
+	return &MemoryCheckpoints{lines: make(map[string]int)}
+}
+
+func (m *MemoryCheckpoints) Load(_ context.Context, key string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lines[key], nil
+}
+
+func (m *MemoryCheckpoints) Save(_ context.Context, key string, line int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lines[key] = line
+	return nil
+}
+
+func (m *MemoryCheckpoints) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.lines, key)
+	return nil
+}
+
+// cacheCheckpoints 基于缓存（如 Redis）的断点存储
+type cacheCheckpoints struct {
+	cache cache.Cache
+	ttl   time.Duration
+}
+
+// NewCacheCheckpoints 创建基于缓存的断点存储，ttl 为断点保留时间（未完成的导入超过该时间后需从头开始）
+func NewCacheCheckpoints(c cache.Cache, ttl time.Duration) Checkpoints {
+	return &cacheCheckpoints{cache: c, ttl: ttl}
+}
+
+func (c *cacheCheckpoints) Load(ctx context.Context, key string) (int, error) {
+	var line int
+	err := c.cache.Get(ctx, checkpointKey(key), &line)
+	if errors.Is(err, cache.ErrNotFound) {
+		return 0, nil
+	}
+	return line, err
+}
+
+func (c *cacheCheckpoints) Save(ctx context.Context, key string, line int) error {
+	return c.cache.Set(ctx, checkpointKey(key), line, c.ttl)
+}
+
+func (c *cacheCheckpoints) Delete(ctx context.Context, key string) error {
+	return c.cache.Delete(ctx, checkpointKey(key))
+}
+
+func checkpointKey(key string) string { return "importer:checkpoint:" + key }
+
+var (
+	_ Checkpoints = (*MemoryCheckpoints)(nil)
+	_ Checkpoints = (*cacheCheckpoints)(nil)
+)
@@ -0,0 +1,27 @@
+// Package importer 提供批量数据导入（CSV/XLSX）的通用流程。
+//
+// 核心功能：
+//   - 流式数据源：NewCSVSource（自动去除 BOM）、NewXLSXSource（逐行解码工作表，无需第三方库）、NewSliceSource
+//   - 行级校验：Row 的读取方法（Required/Int/Float/Bool/Time）与 Fail 记录错误，按行号与列名汇总
+//   - 错误报告：Report.Errors 与 Report.WriteCSV（row,column,reason），供用户下载修正后重新上传
+//   - 分批事务写入：WithChunkSize + WithUnitOfWork，SaveAll 适配 ddd.Repository
+//   - 进度跟踪：在 ops.Operation 中运行时自动上报进度，可通过 ops 管理接口查看与取消
+//   - 断点续传：WithCheckpoint 记录每批提交后的行号，失败或取消后重新运行从断点继续
+//
+// 使用示例：
+//
+//	imp := importer.New(parseUser, importer.SaveAll(userRepo),
+//	    importer.WithColumns("name", "email"),
+//	    importer.WithUnitOfWork(uow),
+//	    importer.WithCheckpoint(importer.NewCacheCheckpoints(redisCache, 24*time.Hour), uploadID))
+//
+//	op := registry.Go(ctx, "import", func(ctx context.Context, _ *ops.Operation) error {
+//	    src, err := importer.NewXLSXSource(file, size, "")
+//	    if err != nil {
+//	        return err
+//	    }
+//	    report, err := imp.Run(ctx, src)
+//	    saveReport(uploadID, report) // report.WriteCSV 生成错误报告
+//	    return err
+//	}, ops.WithID(uploadID))
+package importer
@@ -0,0 +1,287 @@
+package importer
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mildsunup/higo/ddd"
+	"github.com/mildsunup/higo/logger"
+	"github.com/mildsunup/higo/ops"
+)
+
+var (
+	// ErrNoHeader 数据源缺少表头
+	ErrNoHeader = errors.New("importer: missing header row")
+	// ErrMissingColumns 表头缺少必需列
+	ErrMissingColumns = errors.New("importer: missing required columns")
+	// ErrTooManyErrors 无效行超过上限，导入中止
+	ErrTooManyErrors = errors.New("importer: too many invalid rows")
+)
+
+// maxReportErrors 报告中保留的错误条数上限，超出后仅计数
+const maxReportErrors = 10000
+
+// ParseFunc 将行解析为目标类型，校验错误通过 Row 的读取方法或 Row.Fail 记录
+type ParseFunc[T any] func(r *Row) (T, error)
+
+// WriteFunc 写入一批已通过校验的记录，配置 WithUnitOfWork 时在事务内执行
+type WriteFunc[T any] func(ctx context.Context, batch []T) error
+
+// SaveAll 使用仓储逐条保存（同一事务内），适用于 ddd.Repository
+func SaveAll[T any, ID ddd.Identifier](repo ddd.Repository[T, ID]) WriteFunc[*T] {
+	return func(ctx context.Context, batch []*T) error {
+		for _, e := range batch {
+			if err := repo.Save(ctx, e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// config 导入配置
+type config struct {
+	chunkSize   int
+	maxErrors   int
+	columns     []string
+	uow         ddd.UnitOfWork
+	checkpoints Checkpoints
+	key         string
+	log         logger.Logger
+}
+
+// Option 导入选项
+type Option func(*config)
+
+// WithChunkSize 设置每批写入的行数（默认 500），每批一个事务
+func WithChunkSize(n int) Option {
+	return func(c *config) { c.chunkSize = n }
+}
+
+// WithMaxErrors 无效行超过 n 时中止导入并返回 ErrTooManyErrors（默认 0 不限制，跳过无效行继续导入）
+func WithMaxErrors(n int) Option {
+	return func(c *config) { c.maxErrors = n }
+}
+
+// WithColumns 声明必需列，表头缺少时在读取数据前返回 ErrMissingColumns
+func WithColumns(cols ...string) Option {
+	return func(c *config) { c.columns = cols }
+}
+
+// WithUnitOfWork 每批在事务内写入，批次失败时整批回滚
+func WithUnitOfWork(uow ddd.UnitOfWork) Option {
+	return func(c *config) { c.uow = uow }
+}
+
+// WithCheckpoint 启用断点续传：每批提交后记录已处理的行号，重新运行时跳过已处理的行，成功完成后清除。
+// key 须在多次运行间保持不变（如上传文件 ID）。检查点与批次写入使用同一事务 context，
+// 基于数据库的 Checkpoints 实现可与数据一并提交；其余实现下写入应可重入（如 upsert）。
+func WithCheckpoint(store Checkpoints, key string) Option {
+	return func(c *config) { c.checkpoints, c.key = store, key }
+}
+
+// WithLogger 设置日志
+func WithLogger(l logger.Logger) Option {
+	return func(c *config) { c.log = l }
+}
+
+// Importer 流式导入：逐行读取并校验，按批次写入，无效行收集到报告中
+//
+// 进度写入 context 中的 ops.Operation（通过 ops.Registry.Start/Go 启动时），
+// 操作被取消时停止读取，正在写入的批次随 context 中止（事务回滚），已提交的批次由检查点记录。
+type Importer[T any] struct {
+	parse ParseFunc[T]
+	write WriteFunc[T]
+	cfg   config
+}
+
+// New 创建导入器
+func New[T any](parse ParseFunc[T], write WriteFunc[T], opts ...Option) *Importer[T] {
+	cfg := config{chunkSize: 500, log: logger.Nop()}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.chunkSize <= 0 {
+		cfg.chunkSize = 500
+	}
+	return &Importer[T]{parse: parse, write: write, cfg: cfg}
+}
+
+// Report 导入结果
+type Report struct {
+	Total     int           `json:"total"`                // 本次处理的数据行数
+	Imported  int           `json:"imported"`             // 成功写入的行数
+	Failed    int           `json:"failed"`               // 校验失败的行数
+	Skipped   int           `json:"skipped,omitempty"`    // 续传时跳过的已处理行数
+	ResumedAt int           `json:"resumed_at,omitempty"` // 续传起点（上次处理到的行号）
+	Errors    []RowError    `json:"errors,omitempty"`     // 行级错误，至多保留 10000 条
+	Duration  time.Duration `json:"duration"`
+}
+
+// HasErrors 是否存在无效行
+func (r *Report) HasErrors() bool { return r.Failed > 0 }
+
+// WriteCSV 生成错误报告（row,column,reason），供用户下载修正
+func (r *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"row", "column", "reason"})
+	for _, e := range r.Errors {
+		_ = cw.Write([]string{strconv.Itoa(e.Row), e.Column, e.Reason})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Run 执行导入。写入失败、取消或无效行超限时返回已完成部分的报告与错误；
+// 已提交的批次不会回滚，配置检查点时可重新运行以继续。
+func (im *Importer[T]) Run(ctx context.Context, src Source) (*Report, error) {
+	if c, ok := src.(io.Closer); ok {
+		defer c.Close()
+	}
+	begin := time.Now()
+	report := &Report{}
+	defer func() { report.Duration = time.Since(begin) }()
+
+	header := src.Header()
+	if missing := missingColumns(header, im.cfg.columns); len(missing) > 0 {
+		return report, fmt.Errorf("%w: %s", ErrMissingColumns, strings.Join(missing, ", "))
+	}
+	columns := make(map[string]int, len(header))
+	for i, h := range header {
+		if _, dup := columns[h]; !dup {
+			columns[h] = i
+		}
+	}
+
+	if im.cfg.checkpoints != nil {
+		line, err := im.cfg.checkpoints.Load(ctx, im.cfg.key)
+		if err != nil {
+			return report, fmt.Errorf("importer: load checkpoint: %w", err)
+		}
+		report.ResumedAt = line
+	}
+	op, _ := ops.FromContext(ctx)
+
+	b := &batch[T]{saved: report.ResumedAt}
+	for {
+		if ctx.Err() != nil {
+			return report, context.Cause(ctx)
+		}
+		line, values, err := src.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return report, fmt.Errorf("importer: read: %w", err)
+		}
+		if line <= report.ResumedAt {
+			report.Skipped++
+			continue
+		}
+
+		report.Total++
+		b.last = line
+		row := &Row{line: line, columns: columns, values: values}
+		v, err := im.parse(row)
+		if err == nil {
+			err = row.Err()
+		}
+		if err != nil {
+			report.Failed++
+			if room := maxReportErrors - len(report.Errors); room > 0 {
+				errs := rowErrors(line, err)
+				report.Errors = append(report.Errors, errs[:min(room, len(errs))]...)
+			}
+			if im.cfg.maxErrors > 0 && report.Failed > im.cfg.maxErrors {
+				return report, fmt.Errorf("%w: %d invalid rows", ErrTooManyErrors, report.Failed)
+			}
+			continue
+		}
+
+		if len(b.items) == 0 {
+			b.first = line
+		}
+		b.items = append(b.items, v)
+		if len(b.items) >= im.cfg.chunkSize {
+			if err := im.flush(ctx, b, report, op); err != nil {
+				return report, err
+			}
+		}
+	}
+	if err := im.flush(ctx, b, report, op); err != nil {
+		return report, err
+	}
+
+	if im.cfg.checkpoints != nil {
+		if err := im.cfg.checkpoints.Delete(ctx, im.cfg.key); err != nil {
+			im.cfg.log.Warn(ctx, "importer checkpoint cleanup failed", logger.String("key", im.cfg.key), logger.Err(err))
+		}
+	}
+	im.cfg.log.Info(ctx, "import completed",
+		logger.Int("total", report.Total), logger.Int("imported", report.Imported),
+		logger.Int("failed", report.Failed), logger.Int("skipped", report.Skipped),
+		logger.Duration("duration", time.Since(begin)))
+	return report, nil
+}
+
+// batch 待写入的批次，first/last 为批次覆盖的行号范围（last 含其后的无效行），saved 为已记录的检查点
+type batch[T any] struct {
+	items       []T
+	first, last int
+	saved       int
+}
+
+// flush 写入批次并记录检查点与进度
+func (im *Importer[T]) flush(ctx context.Context, b *batch[T], report *Report, op *ops.Operation) error {
+	if len(b.items) == 0 && (im.cfg.checkpoints == nil || b.last <= b.saved) {
+		return nil
+	}
+	write := func(ctx context.Context) error {
+		if len(b.items) > 0 {
+			if err := im.write(ctx, b.items); err != nil {
+				return fmt.Errorf("importer: write rows %d-%d: %w", b.first, b.last, err)
+			}
+		}
+		if im.cfg.checkpoints != nil {
+			if err := im.cfg.checkpoints.Save(ctx, im.cfg.key, b.last); err != nil {
+				return fmt.Errorf("importer: save checkpoint: %w", err)
+			}
+		}
+		return nil
+	}
+
+	var err error
+	if im.cfg.uow != nil {
+		err = ddd.Transactional(ctx, im.cfg.uow, write)
+	} else {
+		err = write(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	report.Imported += len(b.items)
+	b.items = nil // 不复用底层数组，写入函数可能持有批次
+	b.saved = b.last
+	if op != nil {
+		op.Progress(int64(report.Skipped+report.Total), 0)
+	}
+	return nil
+}
+
+func missingColumns(header, required []string) []string {
+	var missing []string
+	for _, col := range required {
+		if !slices.Contains(header, col) {
+			missing = append(missing, col)
+		}
+	}
+	return missing
+}
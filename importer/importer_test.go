@@ -0,0 +1,212 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type user struct {
+	Name string
+	Age  int64
+}
+
+func parseUser(r *Row) (user, error) {
+	u := user{Name: r.Required("name"), Age: r.Int("age")}
+	if u.Age < 0 {
+		r.Fail("age", "must not be negative")
+	}
+	return u, r.Err()
+}
+
+func TestImporter_CSV(t *testing.T) {
+	data := "\ufeffname,age\nalice,30\n,20\n\nbob,x\ncarol,-1\ndave,40\n"
+	src, err := NewCSVSource(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var batches [][]user
+	imp := New(parseUser, func(_ context.Context, batch []user) error {
+		batches = append(batches, batch)
+		return nil
+	}, WithChunkSize(1))
+
+	report, err := imp.Run(context.Background(), src)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if report.Total != 5 || report.Imported != 2 || report.Failed != 3 {
+		t.Errorf("report = %+v", report)
+	}
+	if len(batches) != 2 || batches[1][0].Name != "dave" {
+		t.Errorf("batches = %v", batches)
+	}
+	want := []RowError{
+		{Row: 3, Column: "name", Reason: "required"},
+		{Row: 5, Column: "age", Reason: `invalid integer "x"`},
+		{Row: 6, Column: "age", Reason: "must not be negative"},
+	}
+	if len(report.Errors) != len(want) {
+		t.Fatalf("errors = %v", report.Errors)
+	}
+	for i, e := range want {
+		if report.Errors[i] != e {
+			t.Errorf("errors[%d] = %v, want %v", i, report.Errors[i], e)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "row,column,reason\n3,name,required\n") {
+		t.Errorf("WriteCSV() = %q", buf.String())
+	}
+}
+
+func TestImporter_MissingColumns(t *testing.T) {
+	imp := New(parseUser, func(context.Context, []user) error { return nil }, WithColumns("name", "email"))
+	_, err := imp.Run(context.Background(), NewSliceSource([]string{"name"}, nil))
+	if !errors.Is(err, ErrMissingColumns) {
+		t.Errorf("Run() error = %v, want ErrMissingColumns", err)
+	}
+}
+
+func TestImporter_Resume(t *testing.T) {
+	rows := [][]string{{"a", "1"}, {"b", "2"}, {"c", "3"}, {"d", "4"}, {"e", "5"}}
+	store := NewMemoryCheckpoints()
+	var written []string
+	fail := true
+	write := func(_ context.Context, batch []user) error {
+		if fail && batch[0].Name == "c" {
+			return errors.New("db down")
+		}
+		for _, u := range batch {
+			written = append(written, u.Name)
+		}
+		return nil
+	}
+	imp := New(parseUser, write, WithChunkSize(2), WithCheckpoint(store, "upload-1"))
+
+	if _, err := imp.Run(context.Background(), NewSliceSource([]string{"name", "age"}, rows)); err == nil {
+		t.Fatal("first Run() should fail")
+	}
+	if line, _ := store.Load(context.Background(), "upload-1"); line != 3 {
+		t.Fatalf("checkpoint = %d, want 3", line)
+	}
+
+	fail = false
+	report, err := imp.Run(context.Background(), NewSliceSource([]string{"name", "age"}, rows))
+	if err != nil {
+		t.Fatalf("second Run() error = %v", err)
+	}
+	if report.Skipped != 2 || report.Imported != 3 || report.ResumedAt != 3 {
+		t.Errorf("report = %+v", report)
+	}
+	if got := strings.Join(written, ""); got != "abcde" {
+		t.Errorf("written = %q, want abcde", got)
+	}
+	if line, _ := store.Load(context.Background(), "upload-1"); line != 0 {
+		t.Errorf("checkpoint after completion = %d, want 0", line)
+	}
+}
+
+// buildXLSX 生成只含一个名为 Users 的工作表的 XLSX
+func buildXLSX(t *testing.T, sheet string) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Users" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships><Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/sharedStrings.xml":       `<sst><si><t>name</t></si><si><t>age</t></si><si><r><t>al</t></r><r><t>ice</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml":   sheet,
+	}
+	for name, content := range files {
+		w, _ := zw.Create(name)
+		_, _ = w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestXLSXSource(t *testing.T) {
+	buf := buildXLSX(t, `<worksheet><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>
+<row r="2"><c r="A2" t="s"><v>2</v></c><c r="B2"><v>30</v></c></row>
+<row r="4"><c r="B4"><v>7.0</v></c></row>
+<row r="5"><c r="A5" t="inlineStr"><is><t>bob</t></is></c></row>
+</sheetData></worksheet>`)
+
+	if _, err := NewXLSXSource(bytes.NewReader(buf.Bytes()), int64(buf.Len()), "Missing"); err == nil {
+		t.Error("NewXLSXSource() with unknown sheet should fail")
+	}
+	src, err := NewXLSXSource(bytes.NewReader(buf.Bytes()), int64(buf.Len()), "Users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []user
+	report, err := New(parseUser, func(_ context.Context, batch []user) error {
+		got = append(got, batch...)
+		return nil
+	}).Run(context.Background(), src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0] != (user{"alice", 30}) || got[1] != (user{"bob", 0}) {
+		t.Errorf("imported = %v", got)
+	}
+	if report.Failed != 1 || report.Errors[0].Row != 4 {
+		t.Errorf("report = %+v", report)
+	}
+}
+
+func TestXLSXSource_RowsWithoutRef(t *testing.T) {
+	buf := buildXLSX(t, `<worksheet><sheetData>
+<row><c t="s"><v>0</v></c><c t="s"><v>1</v></c></row>
+<row><c t="s"><v>2</v></c><c><v>30</v></c></row>
+<row><c t="inlineStr"><is><t>bob</t></is></c><c><v>x</v></c></row>
+</sheetData></worksheet>`)
+	src, err := NewXLSXSource(bytes.NewReader(buf.Bytes()), int64(buf.Len()), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []user
+	report, err := New(parseUser, func(_ context.Context, batch []user) error {
+		got = append(got, batch...)
+		return nil
+	}).Run(context.Background(), src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != (user{"alice", 30}) || report.Skipped != 0 {
+		t.Fatalf("imported = %v, report = %+v", got, report)
+	}
+	if report.Failed != 1 || report.Errors[0].Row != 3 {
+		t.Errorf("report = %+v", report)
+	}
+}
+
+func TestXLSXSource_ColumnLimit(t *testing.T) {
+	if col, err := columnIndex("XFD1"); err != nil || col != maxColumns-1 {
+		t.Fatalf("XFD = %d, %v", col, err)
+	}
+	buf := buildXLSX(t, `<worksheet><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>
+<row r="2"><c r="ZZZZZZZZZZ2"><v>1</v></c></row>
+</sheetData></worksheet>`)
+	src, err := NewXLSXSource(bytes.NewReader(buf.Bytes()), int64(buf.Len()), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := src.Next(); err == nil || !strings.Contains(err.Error(), "XFD") {
+		t.Fatalf("Next() = %v, want column limit error", err)
+	}
+}
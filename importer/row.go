@@ -0,0 +1,166 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RowError 行级错误：行号、列名（整行错误时为空）与原因
+type RowError struct {
+	Row    int    `json:"row"`
+	Column string `json:"column,omitempty"`
+	Reason string `json:"reason"`
+}
+
+func (e RowError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("row %d: %s", e.Row, e.Reason)
+	}
+	return fmt.Sprintf("row %d, column %s: %s", e.Row, e.Column, e.Reason)
+}
+
+// RowErrors 一行中的多个错误
+type RowErrors []RowError
+
+func (e RowErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Row 待解析的行，读取方法在值缺失或格式错误时记录错误并返回零值，解析结束后通过 Err 统一返回：
+//
+//	func parseUser(r *importer.Row) (*User, error) {
+//	    u := &User{Name: r.Required("name"), Age: int(r.Int("age")), Email: r.String("email")}
+//	    if u.Email != "" && !strings.Contains(u.Email, "@") {
+//	        r.Fail("email", "invalid email")
+//	    }
+//	    return u, r.Err()
+//	}
+type Row struct {
+	line    int
+	columns map[string]int
+	values  []string
+	errs    RowErrors
+}
+
+// Line 文件中的行号（1 起始，表头为第 1 行）
+func (r *Row) Line() int { return r.line }
+
+// Has 表头是否包含该列
+func (r *Row) Has(col string) bool {
+	_, ok := r.columns[col]
+	return ok
+}
+
+// String 返回去除首尾空白的值，列不存在时为空
+func (r *Row) String(col string) string {
+	i, ok := r.columns[col]
+	if !ok || i >= len(r.values) {
+		return ""
+	}
+	return strings.TrimSpace(r.values[i])
+}
+
+// Required 返回非空值，为空时记录 "required" 错误
+func (r *Row) Required(col string) string {
+	v := r.String(col)
+	if v == "" {
+		r.Fail(col, "required")
+	}
+	return v
+}
+
+// Int 解析整数，空值返回 0
+func (r *Row) Int(col string) int64 {
+	v := r.String(col)
+	if v == "" {
+		return 0
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		// Excel 中的整数常以 "12.0" 形式出现
+		if f, ferr := strconv.ParseFloat(v, 64); ferr == nil && f == float64(int64(f)) {
+			return int64(f)
+		}
+		r.Fail(col, "invalid integer "+strconv.Quote(v))
+	}
+	return n
+}
+
+// Float 解析浮点数，空值返回 0
+func (r *Row) Float(col string) float64 {
+	v := r.String(col)
+	if v == "" {
+		return 0
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		r.Fail(col, "invalid number "+strconv.Quote(v))
+	}
+	return f
+}
+
+// Bool 解析布尔值（true/false、1/0、yes/no、y/n，不区分大小写），空值返回 false
+func (r *Row) Bool(col string) bool {
+	switch v := strings.ToLower(r.String(col)); v {
+	case "", "false", "0", "no", "n":
+		return false
+	case "true", "1", "yes", "y":
+		return true
+	default:
+		r.Fail(col, "invalid boolean "+strconv.Quote(v))
+		return false
+	}
+}
+
+// Time 按 layout 解析时间，空值返回零值；XLSX 中的日期序列号（如 45292）按 1900 日期系统转换
+func (r *Row) Time(col, layout string) time.Time {
+	v := r.String(col)
+	if v == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(layout, v)
+	if err == nil {
+		return t
+	}
+	if serial, ferr := strconv.ParseFloat(v, 64); ferr == nil && serial > 0 {
+		return excelEpoch.Add(time.Duration(serial * float64(24*time.Hour))).Round(time.Second)
+	}
+	r.Fail(col, "invalid time "+strconv.Quote(v)+", expected "+layout)
+	return time.Time{}
+}
+
+// excelEpoch Excel 1900 日期系统的零点（已计入 1900-02-29 的历史误差）
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// Fail 记录自定义校验错误，col 为空表示整行错误
+func (r *Row) Fail(col, reason string) {
+	r.errs = append(r.errs, RowError{Row: r.line, Column: col, Reason: reason})
+}
+
+// Err 返回已记录的错误（RowErrors），无错误时返回 nil
+func (r *Row) Err() error {
+	if len(r.errs) == 0 {
+		return nil
+	}
+	return r.errs
+}
+
+// rowErrors 将解析函数返回的错误转换为行级错误
+func rowErrors(line int, err error) []RowError {
+	var errs RowErrors
+	if errors.As(err, &errs) {
+		return errs
+	}
+	var one RowError
+	if errors.As(err, &one) {
+		return []RowError{one}
+	}
+	return []RowError{{Row: line, Reason: err.Error()}}
+}
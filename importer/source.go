@@ -0,0 +1,116 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Source 流式数据源：Header 返回表头，Next 逐行返回行号（文件中的 1 起始行号）与单元格，结束时返回 io.EOF
+type Source interface {
+	Header() []string
+	Next() (line int, values []string, err error)
+}
+
+// CSVOption CSV 数据源选项
+type CSVOption func(*csv.Reader)
+
+// WithDelimiter 设置分隔符（默认逗号），如 '\t'、';'
+func WithDelimiter(r rune) CSVOption {
+	return func(cr *csv.Reader) { cr.Comma = r }
+}
+
+// WithLazyQuotes 容忍不规范的引号（Excel 导出的 CSV 常见）
+func WithLazyQuotes() CSVOption {
+	return func(cr *csv.Reader) { cr.LazyQuotes = true }
+}
+
+// csvSource CSV 数据源
+type csvSource struct {
+	r      *csv.Reader
+	header []string
+}
+
+// NewCSVSource 创建 CSV 数据源，首行为表头；自动去除 UTF-8 BOM，列数不一致的行按缺失列为空处理
+func NewCSVSource(r io.Reader, opts ...CSVOption) (Source, error) {
+	br := bufio.NewReader(r)
+	if bom, _ := br.Peek(3); bytes.Equal(bom, []byte{0xEF, 0xBB, 0xBF}) {
+		_, _ = br.Discard(3)
+	}
+	cr := csv.NewReader(br)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = false
+	for _, opt := range opts {
+		opt(cr)
+	}
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrNoHeader
+		}
+		return nil, err
+	}
+	return &csvSource{r: cr, header: normalizeHeader(header)}, nil
+}
+
+func (s *csvSource) Header() []string { return s.header }
+
+func (s *csvSource) Next() (int, []string, error) {
+	for {
+		values, err := s.r.Read()
+		if err != nil {
+			return 0, nil, err
+		}
+		if isBlank(values) {
+			continue
+		}
+		line, _ := s.r.FieldPos(0)
+		return line, values, nil
+	}
+}
+
+// sliceSource 内存数据源
+type sliceSource struct {
+	header []string
+	rows   [][]string
+	next   int
+}
+
+// NewSliceSource 创建内存数据源（行号从 2 开始，与带表头的文件一致），用于测试或已解析的数据
+func NewSliceSource(header []string, rows [][]string) Source {
+	return &sliceSource{header: normalizeHeader(header), rows: rows}
+}
+
+func (s *sliceSource) Header() []string { return s.header }
+
+func (s *sliceSource) Next() (int, []string, error) {
+	for s.next < len(s.rows) {
+		i := s.next
+		s.next++
+		if !isBlank(s.rows[i]) {
+			return i + 2, s.rows[i], nil
+		}
+	}
+	return 0, nil, io.EOF
+}
+
+func normalizeHeader(header []string) []string {
+	header = slices.Clone(header)
+	for i, h := range header {
+		header[i] = strings.TrimSpace(h)
+	}
+	return header
+}
+
+func isBlank(values []string) bool {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,277 @@
+package importer
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// xlsxSource 流式读取 XLSX 工作表：逐行解码 sheet XML，不加载整个工作表
+type xlsxSource struct {
+	file    io.ReadCloser
+	dec     *xml.Decoder
+	strings []string
+	header  []string
+	row     int // 当前行号，<row> 省略 r 属性时按顺序递增
+}
+
+// maxColumns XLSX 最大列数（XFD）
+const maxColumns = 16384
+
+// NewXLSXSource 创建 XLSX 数据源，sheet 为空时读取第一个工作表，首个非空行为表头。
+// 仅读取单元格的值（共享字符串、内联字符串、数字与布尔），公式取缓存结果，日期为 Excel 序列号。
+// 使用完毕后调用 Close（Importer.Run 会自动关闭实现 io.Closer 的数据源）。
+func NewXLSXSource(r io.ReaderAt, size int64, sheet string) (Source, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("importer: open xlsx: %w", err)
+	}
+	target, err := sheetPath(zr, sheet)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := sharedStrings(zr)
+	if err != nil {
+		return nil, err
+	}
+	f, err := zr.Open(target)
+	if err != nil {
+		return nil, fmt.Errorf("importer: open sheet %s: %w", target, err)
+	}
+
+	s := &xlsxSource{file: f, dec: xml.NewDecoder(f), strings: shared}
+	_, header, err := s.Next()
+	if err != nil {
+		_ = f.Close()
+		if errors.Is(err, io.EOF) {
+			return nil, ErrNoHeader
+		}
+		return nil, err
+	}
+	s.header = normalizeHeader(header)
+	return s, nil
+}
+
+func (s *xlsxSource) Header() []string { return s.header }
+
+// Close 关闭工作表
+func (s *xlsxSource) Close() error { return s.file.Close() }
+
+func (s *xlsxSource) Next() (int, []string, error) {
+	for {
+		tok, err := s.dec.Token()
+		if err != nil {
+			return 0, nil, err
+		}
+		if se, ok := tok.(xml.StartElement); ok && se.Name.Local == "row" {
+			line, values, err := s.readRow(se)
+			if err != nil {
+				return 0, nil, fmt.Errorf("importer: read xlsx row: %w", err)
+			}
+			if !isBlank(values) {
+				return line, values, nil
+			}
+		}
+	}
+}
+
+// readRow 解析 <row> 元素，按单元格引用（如 C5）定位列，缺失的单元格为空；
+// 行号取 r 属性（可省略，省略时为上一行加 1）
+func (s *xlsxSource) readRow(row xml.StartElement) (int, []string, error) {
+	if line, err := strconv.Atoi(attr(row, "r")); err == nil && line > 0 {
+		s.row = line
+	} else {
+		s.row++
+	}
+	line := s.row
+	var values []string
+	for {
+		tok, err := s.dec.Token()
+		if err != nil {
+			return 0, nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local != "c" {
+				continue
+			}
+			col, err := columnIndex(attr(t, "r"))
+			if err != nil {
+				return 0, nil, err
+			}
+			if col < 0 {
+				col = len(values)
+			}
+			v, err := s.readCell(t)
+			if err != nil {
+				return 0, nil, err
+			}
+			for len(values) <= col {
+				values = append(values, "")
+			}
+			values[col] = v
+		case xml.EndElement:
+			if t.Name.Local == "row" {
+				return line, values, nil
+			}
+		}
+	}
+}
+
+// readCell 解析 <c> 元素的值
+func (s *xlsxSource) readCell(c xml.StartElement) (string, error) {
+	var cell struct {
+		V  string `xml:"v"`
+		IS struct {
+			T []string `xml:"t"`
+			R []struct {
+				T string `xml:"t"`
+			} `xml:"r"`
+		} `xml:"is"`
+	}
+	if err := s.dec.DecodeElement(&cell, &c); err != nil {
+		return "", err
+	}
+	switch attr(c, "t") {
+	case "s":
+		i, err := strconv.Atoi(strings.TrimSpace(cell.V))
+		if err != nil || i < 0 || i >= len(s.strings) {
+			return "", fmt.Errorf("invalid shared string index %q", cell.V)
+		}
+		return s.strings[i], nil
+	case "inlineStr":
+		var b strings.Builder
+		for _, t := range cell.IS.T {
+			b.WriteString(t)
+		}
+		for _, r := range cell.IS.R {
+			b.WriteString(r.T)
+		}
+		return b.String(), nil
+	case "b":
+		if cell.V == "1" {
+			return "true", nil
+		}
+		return "false", nil
+	default:
+		return cell.V, nil
+	}
+}
+
+// sheetPath 根据工作簿与关系文件解析工作表在压缩包中的路径
+func sheetPath(zr *zip.Reader, name string) (string, error) {
+	var wb struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decodeZipXML(zr, "xl/workbook.xml", &wb); err != nil {
+		return "", err
+	}
+	var rels struct {
+		Rels []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodeZipXML(zr, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return "", err
+	}
+
+	for _, sh := range wb.Sheets {
+		if name != "" && sh.Name != name {
+			continue
+		}
+		for _, rel := range rels.Rels {
+			if rel.ID != sh.RID {
+				continue
+			}
+			if strings.HasPrefix(rel.Target, "/") {
+				return strings.TrimPrefix(rel.Target, "/"), nil
+			}
+			return path.Join("xl", rel.Target), nil
+		}
+	}
+	if name == "" {
+		return "", fmt.Errorf("importer: xlsx has no worksheet")
+	}
+	return "", fmt.Errorf("importer: worksheet %q not found", name)
+}
+
+// sharedStrings 读取共享字符串表，富文本按片段拼接
+func sharedStrings(zr *zip.Reader) ([]string, error) {
+	var sst struct {
+		SI []struct {
+			T string `xml:"t"`
+			R []struct {
+				T string `xml:"t"`
+			} `xml:"r"`
+		} `xml:"si"`
+	}
+	err := decodeZipXML(zr, "xl/sharedStrings.xml", &sst)
+	if errors.Is(err, errZipEntryMissing) {
+		return nil, nil // 无字符串单元格时可省略
+	}
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, len(sst.SI))
+	for i, si := range sst.SI {
+		var b strings.Builder
+		b.WriteString(si.T)
+		for _, r := range si.R {
+			b.WriteString(r.T)
+		}
+		out[i] = b.String()
+	}
+	return out, nil
+}
+
+var errZipEntryMissing = errors.New("zip entry missing")
+
+func decodeZipXML(zr *zip.Reader, name string, v any) error {
+	f, err := zr.Open(name)
+	if err != nil {
+		return fmt.Errorf("importer: xlsx %s: %w", name, errZipEntryMissing)
+	}
+	defer f.Close()
+	if err := xml.NewDecoder(f).Decode(v); err != nil {
+		return fmt.Errorf("importer: xlsx %s: %w", name, err)
+	}
+	return nil
+}
+
+func attr(se xml.StartElement, name string) string {
+	for _, a := range se.Attr {
+		if a.Name.Local == name && a.Name.Space == "" {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// columnIndex 将单元格引用（如 AB12）转换为 0 起始列号，没有列字母时返回 -1，超过 XFD 时返回错误
+func columnIndex(ref string) (int, error) {
+	col := 0
+	n := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+		if col > maxColumns {
+			return 0, fmt.Errorf("cell reference %q exceeds column XFD", ref)
+		}
+		n++
+	}
+	if n == 0 {
+		return -1, nil
+	}
+	return col - 1, nil
+}
@@ -0,0 +1,206 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 服务账号挂载路径
+const (
+	ServiceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+	tokenRefresh      = time.Minute // 投射令牌会轮换，定期重新读取
+)
+
+// ErrNotInCluster 不在 Kubernetes 集群内运行
+var ErrNotInCluster = errors.New("k8s: not running in cluster")
+
+// Config API 客户端配置
+type Config struct {
+	Host       string       // API 地址，如 https://10.0.0.1:443
+	Token      string       // 固定令牌，优先于 TokenFile
+	TokenFile  string       // 令牌文件，定期重新读取
+	CAFile     string       // CA 证书，为空时使用系统证书
+	Namespace  string       // 默认命名空间
+	HTTPClient *http.Client // 自定义 HTTP 客户端，设置后忽略 CAFile
+	Timeout    time.Duration
+}
+
+// Client 精简的 Kubernetes REST 客户端，只覆盖本包用到的资源（Pod、Lease、Event）
+type Client struct {
+	cfg  Config
+	http *http.Client
+
+	mu        sync.Mutex
+	token     string
+	tokenRead time.Time
+}
+
+// InCluster 是否运行在 Kubernetes 集群内
+func InCluster() bool {
+	return os.Getenv("KUBERNETES_SERVICE_HOST") != "" && os.Getenv("KUBERNETES_SERVICE_PORT") != ""
+}
+
+// NewInClusterClient 使用 Pod 挂载的服务账号创建客户端
+func NewInClusterClient() (*Client, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, ErrNotInCluster
+	}
+	ns, _ := os.ReadFile(ServiceAccountDir + "/namespace")
+	return NewClient(Config{
+		Host:      "https://" + net.JoinHostPort(host, port),
+		TokenFile: ServiceAccountDir + "/token",
+		CAFile:    ServiceAccountDir + "/ca.crt",
+		Namespace: strings.TrimSpace(string(ns)),
+	})
+}
+
+// NewClient 创建客户端
+func NewClient(cfg Config) (*Client, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("k8s: host is required")
+	}
+	cfg.Host = strings.TrimSuffix(cfg.Host, "/")
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	c := &Client{cfg: cfg, http: cfg.HTTPClient}
+	if c.http == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		if cfg.CAFile != "" {
+			pem, err := os.ReadFile(cfg.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("k8s: read ca: %w", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, errors.New("k8s: invalid ca certificate")
+			}
+			transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+		}
+		c.http = &http.Client{Transport: transport, Timeout: cfg.Timeout}
+	}
+	return c, nil
+}
+
+// Namespace 返回默认命名空间
+func (c *Client) Namespace() string { return c.cfg.Namespace }
+
+func (c *Client) bearer() (string, error) {
+	if c.cfg.Token != "" {
+		return c.cfg.Token, nil
+	}
+	if c.cfg.TokenFile == "" {
+		return "", nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token == "" || time.Since(c.tokenRead) > tokenRefresh {
+		b, err := os.ReadFile(c.cfg.TokenFile)
+		if err != nil {
+			if c.token != "" {
+				return c.token, nil
+			}
+			return "", fmt.Errorf("k8s: read token: %w", err)
+		}
+		c.token, c.tokenRead = strings.TrimSpace(string(b)), time.Now()
+	}
+	return c.token, nil
+}
+
+// APIError API 返回的错误（metav1.Status）
+type APIError struct {
+	Code    int    `json:"code"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("k8s: %d %s: %s", e.Code, e.Reason, e.Message)
+}
+
+// IsNotFound 资源不存在
+func IsNotFound(err error) bool { return statusCode(err) == http.StatusNotFound }
+
+// IsConflict 资源版本冲突或已存在（乐观并发失败）
+func IsConflict(err error) bool { return statusCode(err) == http.StatusConflict }
+
+func statusCode(err error) int {
+	var e *APIError
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return 0
+}
+
+// do 发送请求，in 非 nil 时编码为 JSON 请求体，out 非 nil 时解码响应
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.cfg.Host+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	token, err := c.bearer()
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("k8s: %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		apiErr := &APIError{}
+		if json.Unmarshal(data, apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = strings.TrimSpace(string(data))
+		}
+		apiErr.Code = resp.StatusCode
+		return apiErr
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// ObjectMeta 资源元数据（仅用到的字段）
+type ObjectMeta struct {
+	Name              string            `json:"name,omitempty"`
+	GenerateName      string            `json:"generateName,omitempty"`
+	Namespace         string            `json:"namespace,omitempty"`
+	UID               string            `json:"uid,omitempty"`
+	ResourceVersion   string            `json:"resourceVersion,omitempty"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	DeletionTimestamp *time.Time        `json:"deletionTimestamp,omitempty"`
+}
@@ -0,0 +1,28 @@
+// Package k8s 提供 Kubernetes 部署所需的运行时集成，直接调用 API Server REST 接口，不依赖 client-go。
+//
+// 功能：
+//   - LeaseLocker：基于 coordination.k8s.io Lease 的 lock.Locker，用于 runtime.WithLeaderElection（替代 Redis）
+//   - TerminationWatcher：检测驱逐、抢占、删除（DisruptionTarget 条件与 deletionTimestamp）及 preStop 钩子，
+//     在 SIGTERM 之前触发排空
+//   - Metadata：读取 downward API 注入的 Pod 元数据，提供日志字段与 OpenTelemetry 资源属性
+//   - Recorder：在当前 Pod 上记录事件，RecordReadiness 在就绪状态变化时记录
+//
+// 使用示例：
+//
+//	meta := k8s.LoadMetadata("")
+//	log = log.With(meta.Fields()...)
+//	obs, _ := observability.New(obsCfg, observability.WithResourceAttributes(meta.Attributes()...))
+//
+//	client, err := k8s.NewInClusterClient()
+//	app := runtime.New(cfg,
+//	    runtime.WithLogger(log),
+//	    runtime.WithLeaderElection(k8s.NewLeaseLocker(client, meta.PodName), runtime.LeaderConfig{}),
+//	)
+//	k8s.RecordReadiness(app, k8s.NewRecorder(client, meta, cfg.Name, log))
+//
+//	ctx, cancel := context.WithCancel(context.Background())
+//	app.Register(k8s.NewTerminationWatcher(client, meta, func(k8s.Termination) { cancel() }))
+//	app.Run(ctx)
+//
+// 所需 RBAC：leases（get、create、update）、pods（get）、events（create）。
+package k8s
@@ -0,0 +1,113 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/mildsunup/higo/logger"
+	"github.com/mildsunup/higo/runtime"
+)
+
+// 事件类型
+const (
+	EventNormal  = "Normal"
+	EventWarning = "Warning"
+)
+
+// Event core/v1 Event（仅用到的字段）
+type Event struct {
+	APIVersion     string          `json:"apiVersion"`
+	Kind           string          `json:"kind"`
+	Metadata       ObjectMeta      `json:"metadata"`
+	InvolvedObject ObjectReference `json:"involvedObject"`
+	Reason         string          `json:"reason"`
+	Message        string          `json:"message"`
+	Type           string          `json:"type"`
+	Source         EventSource     `json:"source"`
+	FirstTimestamp time.Time       `json:"firstTimestamp"`
+	LastTimestamp  time.Time       `json:"lastTimestamp"`
+	Count          int32           `json:"count"`
+}
+
+// ObjectReference 事件关联的对象
+type ObjectReference struct {
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace"`
+	Name       string `json:"name"`
+	UID        string `json:"uid,omitempty"`
+}
+
+// EventSource 事件来源
+type EventSource struct {
+	Component string `json:"component,omitempty"`
+	Host      string `json:"host,omitempty"`
+}
+
+// Recorder 在当前 Pod 上记录事件（kubectl describe pod 可见），服务账号需要 events 资源的 create 权限
+type Recorder struct {
+	client    *Client
+	meta      Metadata
+	component string
+	log       logger.Logger
+}
+
+// NewRecorder 创建事件记录器，component 为事件来源（通常为应用名）
+func NewRecorder(client *Client, meta Metadata, component string, log logger.Logger) *Recorder {
+	if log == nil {
+		log = logger.Nop()
+	}
+	return &Recorder{client: client, meta: meta, component: component, log: log}
+}
+
+// Event 记录事件，失败只记录日志（事件是尽力而为的，不应影响业务流程）
+func (r *Recorder) Event(ctx context.Context, typ, reason, message string) {
+	if r.meta.PodName == "" || r.meta.Namespace == "" {
+		return
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	e := Event{
+		APIVersion: "v1",
+		Kind:       "Event",
+		Metadata:   ObjectMeta{GenerateName: r.meta.PodName + ".", Namespace: r.meta.Namespace},
+		InvolvedObject: ObjectReference{
+			APIVersion: "v1",
+			Kind:       "Pod",
+			Namespace:  r.meta.Namespace,
+			Name:       r.meta.PodName,
+			UID:        r.meta.PodUID,
+		},
+		Reason:         reason,
+		Message:        message,
+		Type:           typ,
+		Source:         EventSource{Component: r.component, Host: r.meta.NodeName},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	path := "/api/v1/namespaces/" + url.PathEscape(r.meta.Namespace) + "/events"
+	if err := r.client.do(ctx, http.MethodPost, path, e, nil); err != nil {
+		r.log.Warn(ctx, "k8s: record event failed", logger.String("reason", reason), logger.Err(err))
+	}
+}
+
+// 就绪状态事件原因
+const (
+	ReasonAppReady    = "AppReady"
+	ReasonAppNotReady = "AppNotReady"
+)
+
+// RecordReadiness 在应用就绪（启动完成）与摘除就绪（预停止开始）时记录事件
+func RecordReadiness(app *runtime.App, r *Recorder) {
+	app.OnAfterStart(func(ctx context.Context) error {
+		info := app.Info()
+		r.Event(ctx, EventNormal, ReasonAppReady, info.Name+" "+info.Version+" is ready")
+		return nil
+	})
+	app.OnPreStop(func(ctx context.Context) error {
+		r.Event(ctx, EventNormal, ReasonAppNotReady, app.Info().Name+" is draining and no longer ready")
+		return nil
+	})
+}
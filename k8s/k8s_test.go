@@ -0,0 +1,213 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mildsunup/higo/lock"
+)
+
+// fakeAPI 内存中的 API Server：Lease 支持 resourceVersion 乐观并发，Pod 只读，Event 记录
+type fakeAPI struct {
+	mu      sync.Mutex
+	leases  map[string]*Lease
+	version int
+	pod     Pod
+	events  []Event
+}
+
+func newFakeAPI(t *testing.T) (*fakeAPI, *Client) {
+	api := &fakeAPI{leases: make(map[string]*Lease)}
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+	c, err := NewClient(Config{Host: srv.URL, Token: "token", Namespace: "default"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return api, c
+}
+
+func (a *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if r.Header.Get("Authorization") != "Bearer token" {
+		writeStatus(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	switch {
+	case strings.Contains(r.URL.Path, "/leases"):
+		a.serveLease(w, r)
+	case strings.HasSuffix(r.URL.Path, "/events") && r.Method == http.MethodPost:
+		var e Event
+		_ = json.NewDecoder(r.Body).Decode(&e)
+		a.events = append(a.events, e)
+		w.WriteHeader(http.StatusCreated)
+	case strings.Contains(r.URL.Path, "/pods/"):
+		_ = json.NewEncoder(w).Encode(a.pod)
+	default:
+		writeStatus(w, http.StatusNotFound, "NotFound")
+	}
+}
+
+func (a *fakeAPI) serveLease(w http.ResponseWriter, r *http.Request) {
+	name := filepath.Base(r.URL.Path)
+	switch r.Method {
+	case http.MethodGet:
+		l, ok := a.leases[name]
+		if !ok {
+			writeStatus(w, http.StatusNotFound, "NotFound")
+			return
+		}
+		_ = json.NewEncoder(w).Encode(l)
+	case http.MethodPost, http.MethodPut:
+		var l Lease
+		_ = json.NewDecoder(r.Body).Decode(&l)
+		cur, exists := a.leases[l.Metadata.Name]
+		if r.Method == http.MethodPost && exists ||
+			r.Method == http.MethodPut && (!exists || cur.Metadata.ResourceVersion != l.Metadata.ResourceVersion) {
+			writeStatus(w, http.StatusConflict, "Conflict")
+			return
+		}
+		a.version++
+		l.Metadata.ResourceVersion = strconv.Itoa(a.version)
+		a.leases[l.Metadata.Name] = &l
+		_ = json.NewEncoder(w).Encode(l)
+	}
+}
+
+func writeStatus(w http.ResponseWriter, code int, reason string) {
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(APIError{Code: code, Reason: reason, Message: strings.ToLower(reason)})
+}
+
+func TestLeaseLocker(t *testing.T) {
+	api, c := newFakeAPI(t)
+	ctx := context.Background()
+	a := NewLeaseLocker(c, "pod-a").NewLock("leader:billing", lock.WithTTL(2*time.Second))
+	b := NewLeaseLocker(c, "pod-b").NewLock("leader:billing", lock.WithTTL(2*time.Second), lock.WithRetryCount(0))
+
+	if ok, err := a.TryLock(ctx); !ok || err != nil {
+		t.Fatalf("a.TryLock = %v, %v", ok, err)
+	}
+	if ok, err := b.TryLock(ctx); ok || err != nil {
+		t.Fatalf("b.TryLock while held = %v, %v", ok, err)
+	}
+	if err := b.Lock(ctx); !errors.Is(err, lock.ErrLockFailed) {
+		t.Fatalf("b.Lock = %v", err)
+	}
+	if err := a.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Refresh(ctx); !errors.Is(err, lock.ErrNotHeld) {
+		t.Fatalf("b.Refresh = %v", err)
+	}
+
+	api.mu.Lock()
+	l := api.leases["leader-billing"]
+	if l == nil || l.Holder() != "pod-a" || *l.Spec.LeaseDurationSeconds != 2 {
+		api.mu.Unlock()
+		t.Fatalf("lease = %+v", l)
+	}
+	// 模拟续约停止后过期
+	past := time.Now().Add(-time.Minute).UTC().Format(microTime)
+	l.Spec.RenewTime = &past
+	api.mu.Unlock()
+
+	if ok, err := b.TryLock(ctx); !ok || err != nil {
+		t.Fatalf("b.TryLock after expiry = %v, %v", ok, err)
+	}
+	if err := a.Refresh(ctx); !errors.Is(err, lock.ErrNotHeld) {
+		t.Fatalf("a.Refresh after takeover = %v", err)
+	}
+	if err := b.Unlock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := a.TryLock(ctx); !ok || err != nil {
+		t.Fatalf("a.TryLock after release = %v, %v", ok, err)
+	}
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if n := *api.leases["leader-billing"].Spec.LeaseTransitions; n != 1 {
+		t.Fatalf("transitions = %d", n)
+	}
+}
+
+func TestLeaseName(t *testing.T) {
+	if got := LeaseName("Leader:Billing/Relay_1"); got != "leader-billing-relay-1" {
+		t.Fatalf("LeaseName = %q", got)
+	}
+}
+
+func TestTerminationWatcher(t *testing.T) {
+	api, c := newFakeAPI(t)
+	got := make(chan Termination, 2)
+	w := NewTerminationWatcher(c, Metadata{PodName: "pod-a", Namespace: "default"},
+		func(t Termination) { got <- t }, WithPollInterval(5*time.Millisecond))
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop(context.Background())
+
+	api.mu.Lock()
+	api.pod.Status.Conditions = []PodCondition{{Type: "DisruptionTarget", Status: "True", Reason: "EvictionByEvictionAPI"}}
+	api.mu.Unlock()
+
+	select {
+	case term := <-got:
+		if term.Reason != "EvictionByEvictionAPI" {
+			t.Fatalf("reason = %q", term.Reason)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("termination not detected")
+	}
+
+	// 只通知一次
+	rec := httptest.NewRecorder()
+	w.PreStopHandler(0).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/prestop", nil))
+	if rec.Code != http.StatusNoContent || len(got) != 0 {
+		t.Fatalf("code = %d, notifications = %d", rec.Code, len(got))
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	api, c := newFakeAPI(t)
+	r := NewRecorder(c, Metadata{PodName: "pod-a", Namespace: "default", PodUID: "uid"}, "billing", nil)
+	r.Event(context.Background(), EventNormal, ReasonAppReady, "ready")
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if len(api.events) != 1 {
+		t.Fatalf("events = %d", len(api.events))
+	}
+	e := api.events[0]
+	if e.InvolvedObject.Name != "pod-a" || e.InvolvedObject.UID != "uid" || e.Reason != ReasonAppReady || e.Metadata.GenerateName != "pod-a." {
+		t.Fatalf("event = %+v", e)
+	}
+}
+
+func TestLoadMetadata(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "labels"), []byte("app=\"billing\"\nversion=\"v1\"\n"), 0o644)
+	t.Setenv(EnvPodName, "billing-7d9f")
+	t.Setenv(EnvPodNamespace, "prod")
+	t.Setenv(EnvNodeName, "node-1")
+
+	m := LoadMetadata(dir)
+	if m.PodName != "billing-7d9f" || m.Namespace != "prod" || m.Labels["app"] != "billing" || m.Labels["version"] != "v1" {
+		t.Fatalf("metadata = %+v", m)
+	}
+	if len(m.Fields()) != 3 || len(m.Attributes()) != 3 {
+		t.Fatalf("fields = %v, attributes = %v", m.Fields(), m.Attributes())
+	}
+}
@@ -0,0 +1,265 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mildsunup/higo/lock"
+)
+
+// microTime Lease 时间字段格式（metav1.MicroTime）
+const microTime = "2006-01-02T15:04:05.000000Z07:00"
+
+// Lease coordination.k8s.io/v1 Lease
+type Lease struct {
+	APIVersion string     `json:"apiVersion"`
+	Kind       string     `json:"kind"`
+	Metadata   ObjectMeta `json:"metadata"`
+	Spec       LeaseSpec  `json:"spec"`
+}
+
+// LeaseSpec 租约内容
+type LeaseSpec struct {
+	HolderIdentity       *string `json:"holderIdentity,omitempty"`
+	LeaseDurationSeconds *int32  `json:"leaseDurationSeconds,omitempty"`
+	AcquireTime          *string `json:"acquireTime,omitempty"`
+	RenewTime            *string `json:"renewTime,omitempty"`
+	LeaseTransitions     *int32  `json:"leaseTransitions,omitempty"`
+}
+
+// Holder 当前持有者，未持有时为空
+func (l *Lease) Holder() string {
+	if l.Spec.HolderIdentity == nil {
+		return ""
+	}
+	return *l.Spec.HolderIdentity
+}
+
+// Expired 租约是否已过期（无持有者或续约时间 + 时长早于 now）
+func (l *Lease) Expired(now time.Time) bool {
+	if l.Holder() == "" || l.Spec.RenewTime == nil || l.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+	renewed, err := time.Parse(microTime, *l.Spec.RenewTime)
+	if err != nil {
+		return true
+	}
+	return now.After(renewed.Add(time.Duration(*l.Spec.LeaseDurationSeconds) * time.Second))
+}
+
+// GetLease 获取租约
+func (c *Client) GetLease(ctx context.Context, namespace, name string) (*Lease, error) {
+	var l Lease
+	if err := c.do(ctx, http.MethodGet, leasePath(namespace, name), nil, &l); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func leasePath(namespace, name string) string {
+	p := "/apis/coordination.k8s.io/v1/namespaces/" + url.PathEscape(namespace) + "/leases"
+	if name != "" {
+		p += "/" + url.PathEscape(name)
+	}
+	return p
+}
+
+// LeaseLocker 基于 coordination.k8s.io Lease 的 lock.Locker，可替代 Redis 用于 runtime 选主：
+//
+//	locker := k8s.NewLeaseLocker(client, meta.PodName)
+//	app := runtime.New(cfg, runtime.WithLeaderElection(locker, runtime.LeaderConfig{}))
+//
+// 锁键转换为合法的资源名（小写，":"、"/" 等替换为 "-"），租约时长取 lock.WithTTL（向上取整到秒）。
+// 服务账号需要 leases 资源的 get、create、update 权限
+type LeaseLocker struct {
+	client    *Client
+	namespace string
+	identity  string
+}
+
+// LeaseOption LeaseLocker 选项
+type LeaseOption func(*LeaseLocker)
+
+// WithLeaseNamespace 设置租约所在命名空间，默认客户端的命名空间
+func WithLeaseNamespace(ns string) LeaseOption {
+	return func(l *LeaseLocker) { l.namespace = ns }
+}
+
+// NewLeaseLocker 创建 Lease 锁工厂，identity 为持有者标识（通常为 Pod 名）
+func NewLeaseLocker(client *Client, identity string, opts ...LeaseOption) *LeaseLocker {
+	l := &LeaseLocker{client: client, namespace: client.Namespace(), identity: identity}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// NewLock 创建租约锁，lock.WithToken 可覆盖持有者标识
+func (l *LeaseLocker) NewLock(key string, opts ...lock.Option) lock.Lock {
+	o := lock.DefaultOptions()
+	o.Token = l.identity
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &leaseLock{
+		client:    l.client,
+		namespace: l.namespace,
+		name:      LeaseName(key),
+		identity:  o.Token,
+		duration:  int32(max((o.TTL+time.Second-1)/time.Second, 1)),
+		opts:      o,
+	}
+}
+
+// LeaseName 将锁键转换为合法的 Lease 名称
+func LeaseName(key string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(key) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '.':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('-')
+		}
+	}
+	name := strings.Trim(b.String(), "-.")
+	if len(name) > 253 {
+		name = strings.Trim(name[:253], "-.")
+	}
+	return name
+}
+
+type leaseLock struct {
+	client    *Client
+	namespace string
+	name      string
+	identity  string
+	duration  int32
+	opts      lock.Options
+}
+
+func (l *leaseLock) Lock(ctx context.Context) error {
+	for attempt := 0; ; attempt++ {
+		ok, err := l.TryLock(ctx)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		if l.opts.RetryCount >= 0 && attempt >= l.opts.RetryCount {
+			return lock.ErrLockFailed
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(l.opts.RetryDelay):
+		}
+	}
+}
+
+// TryLock 租约不存在时创建；已过期或由自己持有时更新，版本冲突视为竞选失败
+func (l *leaseLock) TryLock(ctx context.Context) (bool, error) {
+	now := time.Now()
+	cur, err := l.client.GetLease(ctx, l.namespace, l.name)
+	if IsNotFound(err) {
+		lease := l.lease(nil, now)
+		err = l.client.do(ctx, http.MethodPost, leasePath(l.namespace, ""), lease, nil)
+		if IsConflict(err) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+	if err != nil {
+		return false, err
+	}
+	if cur.Holder() != l.identity && !cur.Expired(now) {
+		return false, nil
+	}
+	err = l.client.do(ctx, http.MethodPut, leasePath(l.namespace, l.name), l.lease(cur, now), nil)
+	if IsConflict(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Refresh 续约，租约已被他人持有时返回 lock.ErrNotHeld
+func (l *leaseLock) Refresh(ctx context.Context) error {
+	cur, err := l.client.GetLease(ctx, l.namespace, l.name)
+	if IsNotFound(err) {
+		return lock.ErrNotHeld
+	}
+	if err != nil {
+		return err
+	}
+	if cur.Holder() != l.identity {
+		return lock.ErrNotHeld
+	}
+	err = l.client.do(ctx, http.MethodPut, leasePath(l.namespace, l.name), l.lease(cur, time.Now()), nil)
+	if IsConflict(err) {
+		return lock.ErrNotHeld
+	}
+	return err
+}
+
+// Unlock 清空持有者，其他实例无需等待过期即可接管
+func (l *leaseLock) Unlock(ctx context.Context) error {
+	cur, err := l.client.GetLease(ctx, l.namespace, l.name)
+	if IsNotFound(err) {
+		return lock.ErrNotHeld
+	}
+	if err != nil {
+		return err
+	}
+	if cur.Holder() != l.identity {
+		return lock.ErrNotHeld
+	}
+	empty, one := "", int32(1)
+	cur.Spec.HolderIdentity = &empty
+	cur.Spec.LeaseDurationSeconds = &one
+	err = l.client.do(ctx, http.MethodPut, leasePath(l.namespace, l.name), cur, nil)
+	if IsConflict(err) {
+		return lock.ErrNotHeld
+	}
+	return err
+}
+
+// lease 构造由自己持有的租约，cur 非 nil 时基于其版本更新
+func (l *leaseLock) lease(cur *Lease, now time.Time) *Lease {
+	ts := now.UTC().Format(microTime)
+	identity, duration := l.identity, l.duration
+	next := &Lease{
+		APIVersion: "coordination.k8s.io/v1",
+		Kind:       "Lease",
+		Metadata:   ObjectMeta{Name: l.name, Namespace: l.namespace},
+	}
+	var transitions int32
+	acquire := ts
+	if cur != nil {
+		next.Metadata = cur.Metadata
+		if cur.Spec.LeaseTransitions != nil {
+			transitions = *cur.Spec.LeaseTransitions
+		}
+		if cur.Holder() == l.identity && cur.Spec.AcquireTime != nil {
+			acquire = *cur.Spec.AcquireTime
+		} else if cur.Holder() != "" {
+			transitions++
+		}
+	}
+	next.Spec = LeaseSpec{
+		HolderIdentity:       &identity,
+		LeaseDurationSeconds: &duration,
+		AcquireTime:          &acquire,
+		RenewTime:            &ts,
+		LeaseTransitions:     &transitions,
+	}
+	return next
+}
+
+var (
+	_ lock.Locker = (*LeaseLocker)(nil)
+	_ lock.Lock   = (*leaseLock)(nil)
+)
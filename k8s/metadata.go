@@ -0,0 +1,140 @@
+package k8s
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+
+	"github.com/mildsunup/higo/logger"
+)
+
+// 默认 downward API 环境变量与卷路径
+const (
+	EnvPodName        = "POD_NAME"
+	EnvPodNamespace   = "POD_NAMESPACE"
+	EnvPodUID         = "POD_UID"
+	EnvPodIP          = "POD_IP"
+	EnvNodeName       = "NODE_NAME"
+	EnvServiceAccount = "POD_SERVICE_ACCOUNT"
+	PodInfoDir        = "/etc/podinfo" // labels、annotations 文件所在目录
+)
+
+// Metadata Pod 元数据，通过 downward API 注入：
+//
+//	env:
+//	  - name: POD_NAME
+//	    valueFrom: {fieldRef: {fieldPath: metadata.name}}
+//	  - name: POD_NAMESPACE
+//	    valueFrom: {fieldRef: {fieldPath: metadata.namespace}}
+//	  - name: POD_UID
+//	    valueFrom: {fieldRef: {fieldPath: metadata.uid}}
+//	  - name: POD_IP
+//	    valueFrom: {fieldRef: {fieldPath: status.podIP}}
+//	  - name: NODE_NAME
+//	    valueFrom: {fieldRef: {fieldPath: spec.nodeName}}
+//	volumes:
+//	  - name: podinfo
+//	    downwardAPI:
+//	      items:
+//	        - {path: labels, fieldRef: {fieldPath: metadata.labels}}
+//	        - {path: annotations, fieldRef: {fieldPath: metadata.annotations}}
+type Metadata struct {
+	PodName        string            `json:"pod_name,omitempty"`
+	Namespace      string            `json:"namespace,omitempty"`
+	PodUID         string            `json:"pod_uid,omitempty"`
+	PodIP          string            `json:"pod_ip,omitempty"`
+	NodeName       string            `json:"node_name,omitempty"`
+	ServiceAccount string            `json:"service_account,omitempty"`
+	Labels         map[string]string `json:"labels,omitempty"`
+	Annotations    map[string]string `json:"annotations,omitempty"`
+}
+
+// LoadMetadata 读取 downward API 注入的元数据：环境变量缺失时 Pod 名回退为主机名、
+// 命名空间回退为服务账号挂载的 namespace 文件；podInfoDir 为空时使用 PodInfoDir
+func LoadMetadata(podInfoDir string) Metadata {
+	if podInfoDir == "" {
+		podInfoDir = PodInfoDir
+	}
+	m := Metadata{
+		PodName:        os.Getenv(EnvPodName),
+		Namespace:      os.Getenv(EnvPodNamespace),
+		PodUID:         os.Getenv(EnvPodUID),
+		PodIP:          os.Getenv(EnvPodIP),
+		NodeName:       os.Getenv(EnvNodeName),
+		ServiceAccount: os.Getenv(EnvServiceAccount),
+		Labels:         readPodInfo(filepath.Join(podInfoDir, "labels")),
+		Annotations:    readPodInfo(filepath.Join(podInfoDir, "annotations")),
+	}
+	if m.PodName == "" && InCluster() {
+		m.PodName, _ = os.Hostname()
+	}
+	if m.Namespace == "" {
+		if ns, err := os.ReadFile(ServiceAccountDir + "/namespace"); err == nil {
+			m.Namespace = strings.TrimSpace(string(ns))
+		}
+	}
+	return m
+}
+
+// readPodInfo 解析 downward API 卷文件（每行 key="value"），文件不存在时返回 nil
+func readPodInfo(path string) map[string]string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	out := make(map[string]string)
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		key, raw, ok := strings.Cut(sc.Text(), "=")
+		if !ok {
+			continue
+		}
+		if v, err := strconv.Unquote(raw); err == nil {
+			raw = v
+		}
+		out[key] = raw
+	}
+	return out
+}
+
+// Fields 日志字段，配合 Logger.With 附加到全部日志：
+//
+//	log = log.With(meta.Fields()...)
+func (m Metadata) Fields() []logger.Field {
+	var fields []logger.Field
+	add := func(key, val string) {
+		if val != "" {
+			fields = append(fields, logger.String(key, val))
+		}
+	}
+	add("k8s.pod", m.PodName)
+	add("k8s.namespace", m.Namespace)
+	add("k8s.node", m.NodeName)
+	add("k8s.pod_ip", m.PodIP)
+	return fields
+}
+
+// Attributes OpenTelemetry 资源属性（k8s.pod.name 等语义约定），
+// 配合 observability.WithResourceAttributes 附加到全部 span
+func (m Metadata) Attributes() []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if m.PodName != "" {
+		attrs = append(attrs, semconv.K8SPodName(m.PodName))
+	}
+	if m.PodUID != "" {
+		attrs = append(attrs, semconv.K8SPodUID(m.PodUID))
+	}
+	if m.Namespace != "" {
+		attrs = append(attrs, semconv.K8SNamespaceName(m.Namespace))
+	}
+	if m.NodeName != "" {
+		attrs = append(attrs, semconv.K8SNodeName(m.NodeName))
+	}
+	return attrs
+}
@@ -0,0 +1,208 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/mildsunup/higo/logger"
+)
+
+// 终止原因
+const (
+	ReasonDeleted = "Deleted" // Pod 被删除（滚动更新、缩容、kubectl delete），deletionTimestamp 已设置
+	ReasonPreStop = "PreStop" // preStop 钩子调用了 PreStopHandler
+	// 其余原因取自 DisruptionTarget 条件，如 EvictionByEvictionAPI、PreemptionByScheduler、
+	// TerminationByKubelet（节点压力驱逐）、DeletionByTaintManager
+)
+
+// Termination 终止通知
+type Termination struct {
+	Reason  string    // 见 ReasonDeleted 等
+	Message string    // DisruptionTarget 条件的说明
+	At      time.Time // 检测时间
+}
+
+// Pod core/v1 Pod（仅用到的字段）
+type Pod struct {
+	Metadata ObjectMeta `json:"metadata"`
+	Status   struct {
+		Phase      string         `json:"phase"`
+		Conditions []PodCondition `json:"conditions"`
+	} `json:"status"`
+}
+
+// PodCondition Pod 条件
+type PodCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// GetPod 获取 Pod
+func (c *Client) GetPod(ctx context.Context, namespace, name string) (*Pod, error) {
+	var p Pod
+	path := "/api/v1/namespaces/" + url.PathEscape(namespace) + "/pods/" + url.PathEscape(name)
+	if err := c.do(ctx, http.MethodGet, path, nil, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// termination 从 Pod 状态判断是否即将终止：DisruptionTarget 条件（驱逐、抢占，通常早于 SIGTERM）
+// 优先于 deletionTimestamp
+func (p *Pod) termination() (Termination, bool) {
+	for _, c := range p.Status.Conditions {
+		if c.Type == "DisruptionTarget" && c.Status == "True" {
+			return Termination{Reason: c.Reason, Message: c.Message, At: time.Now()}, true
+		}
+	}
+	if p.Metadata.DeletionTimestamp != nil {
+		return Termination{Reason: ReasonDeleted, At: time.Now()}, true
+	}
+	return Termination{}, false
+}
+
+// TerminationWatcher 检测当前 Pod 即将被终止（驱逐、抢占、删除）或 preStop 钩子触发，回调只调用一次。
+// 作为 runtime 组件注册，通常在回调中取消 App.Run 的 context，提前摘除就绪并排空，
+// 而不是等到 kubelet 发送 SIGTERM：
+//
+//	ctx, cancel := context.WithCancel(context.Background())
+//	watcher := k8s.NewTerminationWatcher(client, meta, func(t k8s.Termination) {
+//	    log.Warn(ctx, "pod terminating", logger.String("reason", t.Reason))
+//	    cancel()
+//	})
+//	app.Register(watcher)
+//	app.Run(ctx)
+//
+// 服务账号需要 pods 资源的 get 权限；client 为 nil 时只响应 PreStopHandler
+type TerminationWatcher struct {
+	client   *Client
+	meta     Metadata
+	fn       func(Termination)
+	interval time.Duration
+	log      logger.Logger
+
+	once   sync.Once
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// WatcherOption TerminationWatcher 选项
+type WatcherOption func(*TerminationWatcher)
+
+// WithPollInterval 设置 Pod 状态轮询间隔，默认 2 秒
+func WithPollInterval(d time.Duration) WatcherOption {
+	return func(w *TerminationWatcher) { w.interval = d }
+}
+
+// WithWatcherLogger 设置日志
+func WithWatcherLogger(l logger.Logger) WatcherOption {
+	return func(w *TerminationWatcher) { w.log = l }
+}
+
+// NewTerminationWatcher 创建终止检测器
+func NewTerminationWatcher(client *Client, meta Metadata, fn func(Termination), opts ...WatcherOption) *TerminationWatcher {
+	w := &TerminationWatcher{
+		client:   client,
+		meta:     meta,
+		fn:       fn,
+		interval: 2 * time.Second,
+		log:      logger.Nop(),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+func (w *TerminationWatcher) Name() string { return "k8s-termination" }
+
+// Start 开始轮询 Pod 状态，未配置客户端或 Pod 名时不轮询
+func (w *TerminationWatcher) Start(ctx context.Context) error {
+	if w.client == nil || w.meta.PodName == "" || w.meta.Namespace == "" {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.cancel != nil {
+		return nil
+	}
+	loopCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	w.cancel, w.done = cancel, make(chan struct{})
+	go w.run(loopCtx, w.done)
+	return nil
+}
+
+func (w *TerminationWatcher) Stop(ctx context.Context) error {
+	w.mu.Lock()
+	cancel, done := w.cancel, w.done
+	w.cancel, w.done = nil, nil
+	w.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *TerminationWatcher) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		pod, err := w.client.GetPod(ctx, w.meta.Namespace, w.meta.PodName)
+		switch {
+		case err == nil:
+			if t, ok := pod.termination(); ok {
+				w.Notify(t)
+				return
+			}
+		case ctx.Err() == nil:
+			w.log.Warn(ctx, "k8s: get pod failed", logger.String("pod", w.meta.PodName), logger.Err(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Notify 触发终止回调（只生效一次）
+func (w *TerminationWatcher) Notify(t Termination) {
+	w.once.Do(func() {
+		if t.At.IsZero() {
+			t.At = time.Now()
+		}
+		w.fn(t)
+	})
+}
+
+// PreStopHandler preStop HTTP 钩子：触发终止回调，并等待 delay（为负载均衡摘除留出时间）后返回。
+// 挂载在管理端口上，由 Pod 配置调用：
+//
+//	lifecycle:
+//	  preStop:
+//	    httpGet: {path: /prestop, port: admin}
+func (w *TerminationWatcher) PreStopHandler(delay time.Duration) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		w.Notify(Termination{Reason: ReasonPreStop})
+		if delay > 0 {
+			select {
+			case <-time.After(delay):
+			case <-r.Context().Done():
+			}
+		}
+		rw.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -138,6 +138,16 @@ func (l *zapLogger) WithLevel(level Level) Logger {
 	}
 }
 
+// Level 返回当前级别
+func (l *zapLogger) Level() Level {
+	return fromZapLevel(l.level.Level())
+}
+
+// SetLevel 运行时调整级别
+func (l *zapLogger) SetLevel(level Level) {
+	l.level.SetLevel(toZapLevel(level))
+}
+
 func (l *zapLogger) Sync() error {
 	if l.base == nil {
 		return nil
@@ -198,6 +208,19 @@ func toZapLevel(l Level) zapcore.Level {
 	}
 }
 
+func fromZapLevel(l zapcore.Level) Level {
+	switch l {
+	case zapcore.DebugLevel:
+		return DebugLevel
+	case zapcore.WarnLevel:
+		return WarnLevel
+	case zapcore.ErrorLevel:
+		return ErrorLevel
+	default:
+		return InfoLevel
+	}
+}
+
 func toZapField(f Field) zap.Field {
 	switch v := f.Value.(type) {
 	case string:
@@ -240,3 +263,5 @@ func (l *nopLogger) Error(ctx context.Context, msg string, fields ...Field) {}
 func (l *nopLogger) With(fields ...Field) Logger                            { return l }
 func (l *nopLogger) WithLevel(level Level) Logger                           { return l }
 func (l *nopLogger) Sync() error                                            { return nil }
+
+var _ LevelController = (*zapLogger)(nil)
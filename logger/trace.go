@@ -6,11 +6,13 @@ import (
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/mildsunup/higo/middleware"
+	"github.com/mildsunup/higo/resilience"
 )
 
 func init() {
 	RegisterExtractor(TraceExtractor)
 	RegisterExtractor(RequestIDExtractor)
+	RegisterExtractor(AttemptExtractor)
 }
 
 // TraceExtractor 从 context 提取 trace 信息
@@ -34,3 +36,15 @@ func RequestIDExtractor(ctx context.Context) []Field {
 	}
 	return nil
 }
+
+// AttemptExtractor 从 context 提取重试信息，仅在重试时输出
+func AttemptExtractor(ctx context.Context) []Field {
+	a, ok := resilience.AttemptFromContext(ctx)
+	if !ok || !a.IsRetry() {
+		return nil
+	}
+	return []Field{
+		Int("retry_attempt", a.Number),
+		Duration("retry_backoff", a.Backoff),
+	}
+}
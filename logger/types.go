@@ -83,6 +83,12 @@ type Logger interface {
 	Sync() error
 }
 
+// LevelController 支持运行时调整级别的 Logger（zap 实现支持，With 派生的 Logger 共享级别）
+type LevelController interface {
+	Level() Level
+	SetLevel(level Level)
+}
+
 // Config 日志配置
 type Config struct {
 	Level      string `yaml:"level" mapstructure:"level"`             // debug, info, warn, error
@@ -15,6 +15,7 @@ const (
 	TenantIDKey  ctxKey = "tenant_id"
 	ClientIPKey  ctxKey = "client_ip"
 	UserAgentKey ctxKey = "user_agent"
+	SubjectKey   ctxKey = "subject"
 )
 
 // WithValue 设置上下文值
@@ -56,6 +57,12 @@ func GetUserID(ctx context.Context) (uint64, bool) {
 	return GetValue[uint64](ctx, UserIDKey)
 }
 
+// GetSubject 获取已认证主体，用于没有用户 ID 的认证方式（如管理令牌）
+func GetSubject(ctx context.Context) string {
+	v, _ := GetValue[string](ctx, SubjectKey)
+	return v
+}
+
 // GetTenantID 获取租户 ID
 func GetTenantID(ctx context.Context) (string, bool) {
 	return GetValue[string](ctx, TenantIDKey)
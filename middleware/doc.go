@@ -1,12 +1,33 @@
 // Package middleware 提供 HTTP/gRPC 中间件。
 //
 // HTTP 中间件：
-//   - CORS、认证、限流、超时
+//   - CORS、认证、限流、超时、熔断（CircuitBreaker）
 //   - 请求 ID、日志、恢复、幂等性
+//   - 统一错误处理（ErrorHandler）
+//   - 路由元数据注册表（Routes），中间件按元数据决定行为
+//   - 响应 data 字段加密（EncryptResponse）
+//   - 分布式限流（DistributedRateLimiter）：RedisRateLimiter 以 Lua 脚本实现 GCRA / 滑动窗口，多实例共享配额，
+//     按 IP、用户、API Key、路由（KeyBy*）取键，RateLimitTier 选择档位，Redis 不可用时可配置放行或拒绝；
+//     security.TokenBucket 的 Redis 模式同样基于 RedisRateLimiter（AllowN 一次消耗多个配额）
+//
+// 标准库适配（middleware/nethttp）：
+//   - RequestID、Logging、Recovery、RateLimiter、BearerAuth 的 func(http.Handler) http.Handler 版本
+//   - 适用于 http.ServeMux 与 chi
 //
 // gRPC 拦截器：
 //   - 链路追踪、指标采集
 //   - 日志、恢复、认证
+//   - 熔断（UnaryCircuitBreaker / StreamCircuitBreaker）
+//   - 限流（RateLimiter）：trailer 返回 x-ratelimit-* 配额，GetQuota RPC 供 SDK 查询配额
+//
+// 限流配额头（Quota）：HTTP 与 gRPC 统一返回 X-RateLimit-Limit / Remaining / Reset，
+// 被限流时附带 Retry-After。
+//
+// 重试建议：限流与熔断返回的错误携带 errors.WithRetryAfter，ErrorHandler / response.WriteError
+// 将其输出为 Retry-After 头，gRPC 则附带 google.rpc.RetryInfo 详情，客户端可据此退避。
+//
+// 声明式装配：
+//   - Stack 按配置（config.MiddlewareConfig）构建 Gin/gRPC 中间件链，未知名称立即报错
 //
 // 使用示例：
 //
@@ -0,0 +1,62 @@
+package grpc
+
+import (
+	"context"
+	stderrors "errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/mildsunup/higo/errors"
+	"github.com/mildsunup/higo/resilience"
+)
+
+// UnaryCircuitBreaker 一元调用熔断拦截器
+// 服务端故障类状态码（Unknown、Internal、Unavailable、DataLoss、DeadlineExceeded）计为失败；
+// 熔断打开时返回 Unavailable，并附带 RetryInfo 告知距离半开探测的剩余时间。
+func UnaryCircuitBreaker(cb *resilience.CircuitBreaker) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		var (
+			resp       any
+			handlerErr error
+		)
+		err := cb.Execute(ctx, func(ctx context.Context) error {
+			resp, handlerErr = handler(ctx, req)
+			return breakerFailure(handlerErr)
+		})
+		if stderrors.Is(err, resilience.ErrCircuitOpen) {
+			return nil, circuitOpenError(cb, err)
+		}
+		return resp, handlerErr
+	}
+}
+
+// StreamCircuitBreaker 流式调用熔断拦截器（按流计数）
+func StreamCircuitBreaker(cb *resilience.CircuitBreaker) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		var handlerErr error
+		err := cb.Execute(ss.Context(), func(context.Context) error {
+			handlerErr = handler(srv, ss)
+			return breakerFailure(handlerErr)
+		})
+		if stderrors.Is(err, resilience.ErrCircuitOpen) {
+			return circuitOpenError(cb, err)
+		}
+		return handlerErr
+	}
+}
+
+// breakerFailure 仅服务端故障计入熔断，客户端错误（参数、权限等）不计
+func breakerFailure(err error) error {
+	switch status.Code(err) {
+	case codes.Unknown, codes.Internal, codes.Unavailable, codes.DataLoss, codes.DeadlineExceeded:
+		return err
+	default:
+		return nil
+	}
+}
+
+func circuitOpenError(cb *resilience.CircuitBreaker, err error) error {
+	return errors.Wrap(err, errors.Unavailable, "circuit breaker is open").WithRetryAfter(cb.RetryAfter()).GRPCStatus().Err()
+}
@@ -0,0 +1,232 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/mildsunup/higo/errors"
+	mw "github.com/mildsunup/higo/middleware"
+)
+
+// QuotaMethod 配额查询 RPC 的完整方法名
+const QuotaMethod = "/higo.ratelimit.v1.Quota/GetQuota"
+
+// RateLimiterConfig gRPC 限流配置
+type RateLimiterConfig struct {
+	Rate           rate.Limit                                          // 每秒请求数
+	Burst          int                                                 // 突发容量
+	KeyFunc        func(ctx context.Context, fullMethod string) string // 限流键函数，默认对端 IP
+	ExcludeMethods []string                                            // 不限流的方法
+}
+
+// DefaultRateLimiterConfig 默认配置
+func DefaultRateLimiterConfig() RateLimiterConfig {
+	return RateLimiterConfig{
+		Rate:    100,
+		Burst:   200,
+		KeyFunc: PeerKey,
+	}
+}
+
+// PeerKey 以对端 IP 作为限流键
+func PeerKey(ctx context.Context, _ string) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// RateLimiter gRPC 限流器
+// 每次调用在 trailer 中返回 x-ratelimit-limit / x-ratelimit-remaining / x-ratelimit-reset，
+// 被限流时返回 ResourceExhausted 并附带 retry-after，与 HTTP 限流头一致。
+type RateLimiter struct {
+	cfg      RateLimiterConfig
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+	resetAt  time.Time
+}
+
+// NewRateLimiter 创建 gRPC 限流器
+func NewRateLimiter(cfg RateLimiterConfig) *RateLimiter {
+	if cfg.KeyFunc == nil {
+		cfg.KeyFunc = PeerKey
+	}
+	return &RateLimiter{
+		cfg:      cfg,
+		limiters: make(map[string]*rate.Limiter),
+		resetAt:  time.Now().Add(time.Hour),
+	}
+}
+
+func (l *RateLimiter) limiter(key string) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// 定期清理，避免键无限增长
+	if now := time.Now(); now.After(l.resetAt) {
+		clear(l.limiters)
+		l.resetAt = now.Add(time.Hour)
+	}
+	limiter, ok := l.limiters[key]
+	if !ok {
+		limiter = rate.NewLimiter(l.cfg.Rate, l.cfg.Burst)
+		l.limiters[key] = limiter
+	}
+	return limiter
+}
+
+// Quota 返回调用方当前配额，不消耗令牌
+func (l *RateLimiter) Quota(ctx context.Context, fullMethod string) mw.Quota {
+	limiter := l.limiter(l.cfg.KeyFunc(ctx, fullMethod))
+	return mw.NewQuota(limiter.Tokens(), limiter.Burst(), float64(limiter.Limit()))
+}
+
+func (l *RateLimiter) allow(ctx context.Context, fullMethod string) (metadata.MD, error) {
+	limiter := l.limiter(l.cfg.KeyFunc(ctx, fullMethod))
+	allowed := limiter.Allow()
+	quota := mw.NewQuota(limiter.Tokens(), limiter.Burst(), float64(limiter.Limit()))
+	md := QuotaMetadata(quota)
+	if !allowed {
+		return md, errors.New(errors.ResourceExhausted, "rate limit exceeded").WithRetryAfter(quota.RetryAfter).GRPCStatus().Err()
+	}
+	return md, nil
+}
+
+func (l *RateLimiter) skip(fullMethod string) bool {
+	return fullMethod == QuotaMethod || slices.Contains(l.cfg.ExcludeMethods, fullMethod)
+}
+
+// UnaryInterceptor 一元调用限流拦截器
+func (l *RateLimiter) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if l.skip(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		md, err := l.allow(ctx, info.FullMethod)
+		_ = grpc.SetTrailer(ctx, md)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor 流式调用限流拦截器（按流建立计数）
+func (l *RateLimiter) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if l.skip(info.FullMethod) {
+			return handler(srv, ss)
+		}
+		md, err := l.allow(ss.Context(), info.FullMethod)
+		ss.SetTrailer(md)
+		if err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// RegisterQuotaService 注册配额查询服务（higo.ratelimit.v1.Quota/GetQuota）
+// 请求为 google.protobuf.Empty，响应为 google.protobuf.Struct，客户端可用 FetchQuota 调用。
+func (l *RateLimiter) RegisterQuotaService(s grpc.ServiceRegistrar) {
+	s.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "higo.ratelimit.v1.Quota",
+		HandlerType: (*any)(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "GetQuota",
+			Handler: func(_ any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+				in := &emptypb.Empty{}
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				handle := func(ctx context.Context, _ any) (any, error) {
+					return quotaToStruct(l.Quota(ctx, QuotaMethod))
+				}
+				if interceptor == nil {
+					return handle(ctx, in)
+				}
+				return interceptor(ctx, in, &grpc.UnaryServerInfo{FullMethod: QuotaMethod}, handle)
+			},
+		}},
+	}, l)
+}
+
+// FetchQuota 调用配额查询 RPC
+func FetchQuota(ctx context.Context, cc grpc.ClientConnInterface, opts ...grpc.CallOption) (mw.Quota, error) {
+	out := &structpb.Struct{}
+	if err := cc.Invoke(ctx, QuotaMethod, &emptypb.Empty{}, out, opts...); err != nil {
+		return mw.Quota{}, err
+	}
+	f := out.GetFields()
+	return mw.Quota{
+		Limit:      int(f["limit"].GetNumberValue()),
+		Remaining:  int(f["remaining"].GetNumberValue()),
+		Rate:       f["rate"].GetNumberValue(),
+		Reset:      time.Duration(f["reset_seconds"].GetNumberValue() * float64(time.Second)),
+		RetryAfter: time.Duration(f["retry_after_seconds"].GetNumberValue() * float64(time.Second)),
+	}, nil
+}
+
+// QuotaMetadata 将配额转为 gRPC metadata（小写键）
+func QuotaMetadata(q mw.Quota) metadata.MD {
+	md := metadata.MD{}
+	for k, v := range q.Headers() {
+		md.Set(strings.ToLower(k), v)
+	}
+	return md
+}
+
+// QuotaFromMetadata 从响应 trailer 解析配额，客户端可据此提前退避
+//
+//	var trailer metadata.MD
+//	_, err := client.Call(ctx, req, grpc.Trailer(&trailer))
+//	if q, ok := grpcmw.QuotaFromMetadata(trailer); ok && q.Remaining == 0 { ... }
+func QuotaFromMetadata(md metadata.MD) (mw.Quota, bool) {
+	get := func(key string) (int, bool) {
+		vals := md.Get(strings.ToLower(key))
+		if len(vals) == 0 {
+			return 0, false
+		}
+		n, err := strconv.Atoi(vals[0])
+		return n, err == nil
+	}
+	limit, ok := get(mw.HeaderRateLimitLimit)
+	if !ok {
+		return mw.Quota{}, false
+	}
+	remaining, _ := get(mw.HeaderRateLimitRemaining)
+	reset, _ := get(mw.HeaderRateLimitReset)
+	retryAfter, _ := get(mw.HeaderRetryAfter)
+	return mw.Quota{
+		Limit:      limit,
+		Remaining:  remaining,
+		Reset:      time.Duration(reset) * time.Second,
+		RetryAfter: time.Duration(retryAfter) * time.Second,
+	}, true
+}
+
+func quotaToStruct(q mw.Quota) (*structpb.Struct, error) {
+	return structpb.NewStruct(map[string]any{
+		"limit":               q.Limit,
+		"remaining":           q.Remaining,
+		"rate":                q.Rate,
+		"reset_seconds":       q.Reset.Seconds(),
+		"retry_after_seconds": q.RetryAfter.Seconds(),
+	})
+}
@@ -0,0 +1,113 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	mw "github.com/mildsunup/higo/middleware"
+)
+
+// newRateLimitedConn 启动带限流拦截器与配额服务的进程内 gRPC 服务
+func newRateLimitedConn(t *testing.T, l *RateLimiter) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(grpc.UnaryInterceptor(l.UnaryInterceptor()))
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	l.RegisterQuotaService(srv)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	cc, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = cc.Close() })
+	return cc
+}
+
+func TestRateLimiter_QuotaTrailers(t *testing.T) {
+	ctx := context.Background()
+	cc := newRateLimitedConn(t, NewRateLimiter(RateLimiterConfig{Rate: 0.01, Burst: 2}))
+	client := healthpb.NewHealthClient(cc)
+
+	check := func() (metadata.MD, error) {
+		var trailer metadata.MD
+		_, err := client.Check(ctx, &healthpb.HealthCheckRequest{}, grpc.Trailer(&trailer))
+		return trailer, err
+	}
+
+	trailer, err := check()
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, ok := QuotaFromMetadata(trailer)
+	if !ok || q.Limit != 2 || q.Remaining != 1 || q.Reset <= 0 || q.RetryAfter != 0 {
+		t.Fatalf("quota = %+v, %v", q, ok)
+	}
+
+	// 配额查询不消耗令牌
+	quota, err := FetchQuota(ctx, cc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quota.Limit != 2 || quota.Remaining != 1 || quota.Rate != 0.01 {
+		t.Fatalf("fetched quota = %+v", quota)
+	}
+
+	if _, err := check(); err != nil {
+		t.Fatal(err)
+	}
+	trailer, err = check()
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("err = %v", err)
+	}
+	q, ok = QuotaFromMetadata(trailer)
+	if !ok || q.Remaining != 0 || q.RetryAfter <= 0 {
+		t.Fatalf("limited quota = %+v, %v", q, ok)
+	}
+}
+
+func TestRateLimiter_ExcludeMethods(t *testing.T) {
+	cc := newRateLimitedConn(t, NewRateLimiter(RateLimiterConfig{
+		Rate:           0.01,
+		Burst:          1,
+		ExcludeMethods: []string{healthpb.Health_Check_FullMethodName},
+	}))
+	client := healthpb.NewHealthClient(cc)
+	for range 3 {
+		var trailer metadata.MD
+		if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{}, grpc.Trailer(&trailer)); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := QuotaFromMetadata(trailer); ok {
+			t.Fatalf("excluded method returned quota trailer: %v", trailer)
+		}
+	}
+}
+
+func TestQuotaMetadata_RoundTrip(t *testing.T) {
+	md := QuotaMetadata(mw.NewQuota(0.5, 10, 2))
+	if got := md.Get("x-ratelimit-limit"); len(got) != 1 || got[0] != "10" {
+		t.Fatalf("metadata = %v", md)
+	}
+	q, ok := QuotaFromMetadata(md)
+	if !ok || q.Limit != 10 || q.Remaining != 0 || q.RetryAfter.Seconds() != 1 || q.Reset.Seconds() != 5 {
+		t.Fatalf("quota = %+v, %v", q, ok)
+	}
+	if _, ok := QuotaFromMetadata(metadata.MD{}); ok {
+		t.Fatal("quota parsed from empty metadata")
+	}
+}
@@ -0,0 +1,57 @@
+package grpc
+
+import (
+	"github.com/mildsunup/higo/logger"
+	mw "github.com/mildsunup/higo/middleware"
+	"github.com/mildsunup/higo/observability"
+)
+
+// RegisterStack 注册内置 gRPC 拦截器到 Stack
+//
+// 名称：recovery、logging、tracing、metrics。
+func RegisterStack(s *mw.Stack, log logger.Logger, metrics observability.MetricsProvider) *mw.Stack {
+	s.RegisterGRPC("recovery", noParams(mw.GRPCInterceptors{
+		Unary:  UnaryRecovery(log),
+		Stream: StreamRecovery(log),
+	}))
+	s.RegisterGRPC("logging", noParams(mw.GRPCInterceptors{
+		Unary:  UnaryLogging(log),
+		Stream: StreamLogging(log),
+	}))
+
+	s.RegisterGRPC("tracing", func(p mw.Params) (mw.GRPCInterceptors, error) {
+		cfg := DefaultTracingConfig()
+		params := struct {
+			ServiceName string   `yaml:"service_name"`
+			SkipMethods []string `yaml:"skip_methods"`
+		}{cfg.ServiceName, cfg.SkipMethods}
+		if err := p.Decode(&params); err != nil {
+			return mw.GRPCInterceptors{}, err
+		}
+		cfg.ServiceName = params.ServiceName
+		cfg.SkipMethods = params.SkipMethods
+		return mw.GRPCInterceptors{
+			Unary:  UnaryServerTracing(cfg),
+			Stream: StreamServerTracing(cfg),
+		}, nil
+	})
+
+	if metrics != nil {
+		m := NewMetrics(metrics)
+		s.RegisterGRPC("metrics", noParams(mw.GRPCInterceptors{
+			Unary:  m.UnaryInterceptor(),
+			Stream: m.StreamInterceptor(),
+		}))
+	}
+
+	return s
+}
+
+func noParams(ic mw.GRPCInterceptors) mw.GRPCFactory {
+	return func(p mw.Params) (mw.GRPCInterceptors, error) {
+		if err := p.Decode(&struct{}{}); err != nil {
+			return mw.GRPCInterceptors{}, err
+		}
+		return ic, nil
+	}
+}
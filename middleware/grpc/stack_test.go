@@ -0,0 +1,46 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/mildsunup/higo/config"
+	"github.com/mildsunup/higo/logger"
+	mw "github.com/mildsunup/higo/middleware"
+)
+
+func TestRegisterStack(t *testing.T) {
+	s := RegisterStack(mw.NewStack(), logger.Nop(), nil)
+
+	chain, err := s.BuildGRPC([]config.MiddlewareSpec{
+		{Name: "recovery"},
+		{Name: "tracing", Params: map[string]any{"service_name": "orders", "skip_methods": "/grpc.health.v1.Health/Check"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chain.UnaryInterceptors()) != 2 || len(chain.StreamInterceptors()) != 2 {
+		t.Fatalf("unary = %d, stream = %d", len(chain.UnaryInterceptors()), len(chain.StreamInterceptors()))
+	}
+
+	// 链首的 recovery 将 panic 转换为 Internal
+	info := &grpc.UnaryServerInfo{FullMethod: "/orders.v1.Orders/Get"}
+	_, err = chain.UnaryInterceptors()[0](context.Background(), nil, info, func(context.Context, any) (any, error) {
+		panic("boom")
+	})
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("recovered = %v", err)
+	}
+
+	if _, err := s.BuildGRPC([]config.MiddlewareSpec{{Name: "logging", Params: map[string]any{"level": "debug"}}}); err == nil || !strings.Contains(err.Error(), "level") {
+		t.Fatalf("unknown param = %v", err)
+	}
+	if err := s.Validate(config.MiddlewareConfig{GRPC: []config.MiddlewareSpec{{Name: "metrics"}}}); err == nil {
+		t.Fatal("metrics registered without provider")
+	}
+}
@@ -2,10 +2,13 @@ package http
 
 import (
 	"context"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/mildsunup/higo/ddd"
+	mw "github.com/mildsunup/higo/middleware"
 	"github.com/mildsunup/higo/response"
 )
 
@@ -42,6 +45,12 @@ func BearerAuthWithKey(validate TokenValidator, userIDKey string) gin.HandlerFun
 		}
 
 		c.Set(userIDKey, userID)
+
+		// 写入请求 context，供下游（日志、审计字段等）使用
+		ctx := mw.WithValue(c.Request.Context(), mw.UserIDKey, userID)
+		ctx = ddd.WithActor(ctx, strconv.FormatUint(userID, 10))
+		c.Request = c.Request.WithContext(ctx)
+
 		c.Next()
 	}
 }
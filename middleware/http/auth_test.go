@@ -0,0 +1,65 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mildsunup/higo/ddd"
+	mw "github.com/mildsunup/higo/middleware"
+)
+
+func TestBearerAuth(t *testing.T) {
+	validate := func(_ context.Context, token string) (uint64, error) {
+		if token != "good" {
+			return 0, errors.New("expired")
+		}
+		return 42, nil
+	}
+
+	var (
+		actor  string
+		ctxUID uint64
+		ginUID uint64
+	)
+	r := gin.New()
+	r.GET("/me", BearerAuth(validate), func(c *gin.Context) {
+		actor = ddd.ActorFromContext(c.Request.Context())
+		ctxUID, _ = mw.GetUserID(c.Request.Context())
+		ginUID, _ = GetUserID(c)
+	})
+
+	tests := []struct {
+		header string
+		code   int
+	}{
+		{"", http.StatusUnauthorized},
+		{"Basic good", http.StatusUnauthorized},
+		{"Bearer bad", http.StatusUnauthorized},
+		{"bearer good", http.StatusOK},
+	}
+	for _, tt := range tests {
+		actor = ""
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		if tt.header != "" {
+			req.Header.Set("Authorization", tt.header)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Fatalf("%q: status = %d, want %d", tt.header, w.Code, tt.code)
+		}
+		if tt.code != http.StatusOK && actor != "" {
+			t.Fatalf("%q: handler ran", tt.header)
+		}
+	}
+
+	// 认证后的用户同时写入 gin 上下文、请求 context 与审计操作人
+	if actor != "42" || ctxUID != 42 || ginUID != 42 {
+		t.Fatalf("actor = %q, ctx uid = %d, gin uid = %d", actor, ctxUID, ginUID)
+	}
+}
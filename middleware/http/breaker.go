@@ -0,0 +1,31 @@
+package http
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mildsunup/higo/errors"
+	"github.com/mildsunup/higo/resilience"
+)
+
+var errServerFailure = stderrors.New("server error response")
+
+// CircuitBreaker 熔断中间件
+// 5xx 响应计为失败；熔断打开时直接返回 503，并以 Retry-After 告知距离半开探测的剩余时间。
+func CircuitBreaker(cb *resilience.CircuitBreaker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := cb.Execute(c.Request.Context(), func(context.Context) error {
+			c.Next()
+			if c.Writer.Status() >= http.StatusInternalServerError {
+				return errServerFailure
+			}
+			return nil
+		})
+		if errors.Is(err, resilience.ErrCircuitOpen) {
+			writeError(c, errors.Wrap(err, errors.Unavailable, "Service Unavailable").WithRetryAfter(cb.RetryAfter()), false)
+		}
+	}
+}
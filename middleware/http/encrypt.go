@@ -0,0 +1,142 @@
+package http
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mildsunup/higo/errors"
+	"github.com/mildsunup/higo/security"
+)
+
+const (
+	HeaderEncryption      = "X-Encryption"
+	HeaderEncryptionKeyID = "X-Encryption-Key-ID"
+
+	// EncryptionAES256GCM data 字段加密算法标识
+	EncryptionAES256GCM = "AES-256-GCM"
+)
+
+// EncryptConfig 响应加密配置
+type EncryptConfig struct {
+	// KeyFunc 返回当前客户端的密钥（通常来自认证 claims），ok 为 false 时不加密
+	KeyFunc func(c *gin.Context) (keyID string, key []byte, ok bool)
+}
+
+// EncryptResponse 响应 data 字段加密中间件（按需启用）
+//
+// 将统一响应中的 data 字段以 AES-GCM 加密后替换为 base64 字符串，
+// 并设置 X-Encryption / X-Encryption-Key-ID 响应头；其余字段保持明文。
+// 处理器调用 Flush 时视为流式响应，已缓冲内容原样写出，之后不再加密。
+func EncryptResponse(cfg EncryptConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keyID, key, ok := cfg.KeyFunc(c)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		w := &bufferedWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+		if w.streaming {
+			return
+		}
+
+		body, encrypted, err := encryptData(w.body.Bytes(), key)
+		if err != nil {
+			_ = c.Error(err)
+			c.Writer.Header().Del("Content-Length")
+			writeError(c, errors.Wrap(err, errors.Internal, "response encryption failed"), false)
+			return
+		}
+
+		if encrypted {
+			c.Header(HeaderEncryption, EncryptionAES256GCM)
+			c.Header(HeaderEncryptionKeyID, keyID)
+			c.Writer.Header().Del("Content-Length")
+		}
+		c.Writer.WriteHeaderNow()
+		_, _ = c.Writer.Write(body)
+	}
+}
+
+// encryptData 加密 JSON 响应中的 data 字段，非 JSON 对象或无 data 时原样返回
+func encryptData(body, key []byte) ([]byte, bool, error) {
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return body, false, nil
+	}
+	data, ok := envelope["data"]
+	if !ok || bytes.Equal(data, []byte("null")) {
+		return body, false, nil
+	}
+
+	ciphertext, err := security.EncryptAESGCM(key, data, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	encoded, err := json.Marshal(base64.StdEncoding.EncodeToString(ciphertext))
+	if err != nil {
+		return nil, false, err
+	}
+	envelope["data"] = encoded
+
+	out, err := json.Marshal(envelope)
+	if err != nil {
+		return nil, false, err
+	}
+	return out, true, nil
+}
+
+// bufferedWriter 缓冲响应体，延迟写出；Flush 后转为透传
+type bufferedWriter struct {
+	gin.ResponseWriter
+	body      *bytes.Buffer
+	streaming bool
+}
+
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	if w.streaming {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.body.Write(b)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	if w.streaming {
+		return w.ResponseWriter.WriteString(s)
+	}
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {
+	if w.streaming {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+// Flush 流式响应无法整体加密：写出已缓冲内容并转为透传
+func (w *bufferedWriter) Flush() {
+	if !w.streaming {
+		w.streaming = true
+		w.ResponseWriter.WriteHeaderNow()
+		_, _ = w.ResponseWriter.Write(w.body.Bytes())
+		w.body.Reset()
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.body.Len() > 0 || w.ResponseWriter.Written()
+}
+
+func (w *bufferedWriter) Size() int {
+	if w.streaming {
+		return w.ResponseWriter.Size()
+	}
+	return w.body.Len()
+}
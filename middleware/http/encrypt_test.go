@@ -0,0 +1,79 @@
+package http
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mildsunup/higo/security"
+)
+
+func newEncryptRouter(key []byte) *gin.Engine {
+	r := gin.New()
+	r.Use(EncryptResponse(EncryptConfig{KeyFunc: func(*gin.Context) (string, []byte, bool) {
+		return "k1", key, true
+	}}))
+	r.GET("/data", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"code": 0, "data": gin.H{"secret": "s3"}})
+	})
+	r.GET("/stream", func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		_, _ = c.Writer.WriteString("data: 1\n\n")
+		c.Writer.Flush()
+		_, _ = c.Writer.WriteString("data: 2\n\n")
+	})
+	return r
+}
+
+func TestEncryptResponse(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	r := newEncryptRouter(key)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/data", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get(HeaderEncryption) != EncryptionAES256GCM || rec.Header().Get(HeaderEncryptionKeyID) != "k1" {
+		t.Fatalf("response = %d %v", rec.Code, rec.Header())
+	}
+	var body struct {
+		Code int    `json:"code"`
+		Data string `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, _ := base64.StdEncoding.DecodeString(body.Data)
+	plain, err := security.DecryptAESGCM(key, ciphertext, nil)
+	if err != nil || string(plain) != `{"secret":"s3"}` {
+		t.Fatalf("decrypted = %s, %v", plain, err)
+	}
+}
+
+func TestEncryptResponse_FlushStreams(t *testing.T) {
+	r := newEncryptRouter(bytes.Repeat([]byte{7}, 32))
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stream", nil))
+	if !rec.Flushed || rec.Body.String() != "data: 1\n\ndata: 2\n\n" || rec.Header().Get(HeaderEncryption) != "" {
+		t.Fatalf("stream = flushed %v %q %v", rec.Flushed, rec.Body.String(), rec.Header())
+	}
+}
+
+func TestEncryptResponse_Error(t *testing.T) {
+	r := newEncryptRouter([]byte("short"))
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/data", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	var resp ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("body %q: %v", rec.Body.String(), err)
+	}
+	if bytes.Contains(rec.Body.Bytes(), []byte("s3")) || resp.Message == "" {
+		t.Fatalf("error body = %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,115 @@
+package http
+
+import (
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mildsunup/higo/errors"
+	"github.com/mildsunup/higo/logger"
+	mw "github.com/mildsunup/higo/middleware"
+	"github.com/mildsunup/higo/observability"
+	"github.com/mildsunup/higo/response"
+)
+
+// ErrorResponse 错误响应体
+type ErrorResponse struct {
+	errors.Response
+	RequestID string `json:"request_id,omitempty"`
+	TraceID   string `json:"trace_id,omitempty"`
+}
+
+// ErrorHandler 统一错误处理中间件
+//
+// 恢复 panic，并将 handler 通过 c.Error 记录的错误转换为 errors.ToResponse，
+// 按错误码映射 HTTP 状态码；4xx 记录 Warn，5xx 记录 Error。
+// 客户端 Accept 声明 application/problem+json 时输出 RFC 7807 问题详情。
+// 已写出响应体的请求不会被覆盖。
+func ErrorHandler(log logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error(c.Request.Context(), "HTTP panic recovered",
+					logger.Any("panic", r),
+					logger.String("stack", string(debug.Stack())),
+					logger.String("method", c.Request.Method),
+					logger.String("path", c.Request.URL.Path),
+				)
+				// handler 已写出部分响应时不再追加错误体
+				writeError(c, errors.New(errors.Internal, "Internal Server Error"), true)
+			}
+		}()
+
+		c.Next()
+
+		if len(c.Errors) == 0 {
+			return
+		}
+		err := c.Errors.Last().Err
+		logError(c, log, err)
+		writeError(c, err, true)
+	}
+}
+
+// AbortWithError 中止请求并以统一格式返回错误
+func AbortWithError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+func logError(c *gin.Context, log logger.Logger, err error) {
+	ctx := c.Request.Context()
+	status := errors.GetHTTPStatus(err)
+
+	fields := []logger.Field{
+		logger.Int("status", status),
+		logger.Int("code", int(errors.GetCode(err))),
+		logger.String("method", c.Request.Method),
+		logger.String("path", c.Request.URL.Path),
+		logger.Err(err),
+	}
+
+	if status >= http.StatusInternalServerError {
+		log.Error(ctx, "HTTP request failed", fields...)
+	} else {
+		log.Warn(ctx, "HTTP request failed", fields...)
+	}
+}
+
+func writeError(c *gin.Context, err error, keepWritten bool) {
+	if keepWritten && c.Writer.Written() {
+		return
+	}
+	response.SetRetryAfter(c.Writer.Header(), err)
+	if response.WantsProblem(c.Request) {
+		c.Abort()
+		response.WriteError(c.Writer, c.Request, err)
+		return
+	}
+
+	resp := errors.ToResponse(err)
+	status := errors.GetHTTPStatus(err)
+	if status < http.StatusBadRequest {
+		status = http.StatusInternalServerError
+	}
+	// 5xx 不向客户端暴露内部细节
+	if status >= http.StatusInternalServerError {
+		var e *errors.Error
+		if !errors.As(err, &e) {
+			resp.Message = http.StatusText(status)
+		}
+	}
+
+	ctx := c.Request.Context()
+	traceID := mw.GetTraceID(ctx)
+	if traceID == "" {
+		traceID = observability.TraceID(ctx)
+	}
+
+	c.AbortWithStatusJSON(status, ErrorResponse{
+		Response:  resp,
+		RequestID: mw.GetRequestID(ctx),
+		TraceID:   traceID,
+	})
+}
@@ -0,0 +1,125 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mildsunup/higo/errors"
+	"github.com/mildsunup/higo/logger"
+)
+
+// levelLogger 记录 Warn/Error 日志的级别与消息
+type levelLogger struct {
+	logger.Logger
+	mu      sync.Mutex
+	entries []string
+}
+
+func newLevelLogger() *levelLogger { return &levelLogger{Logger: logger.Nop()} }
+
+func (l *levelLogger) Warn(_ context.Context, msg string, _ ...logger.Field) {
+	l.record("warn: " + msg)
+}
+
+func (l *levelLogger) Error(_ context.Context, msg string, _ ...logger.Field) {
+	l.record("error: " + msg)
+}
+
+func (l *levelLogger) record(entry string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, entry)
+}
+
+func (l *levelLogger) last() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.entries) == 0 {
+		return ""
+	}
+	return l.entries[len(l.entries)-1]
+}
+
+func serveWithErrorHandler(log logger.Logger, handler gin.HandlerFunc) *httptest.ResponseRecorder {
+	r := gin.New()
+	r.Use(ErrorHandler(log))
+	r.GET("/", handler)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	return w
+}
+
+func TestErrorHandler_StatusMapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		message string
+		log     string
+	}{
+		{"not found", errors.New(errors.NotFound, "order not found"), http.StatusNotFound, "order not found", "warn: HTTP request failed"},
+		{"invalid argument", errors.New(errors.InvalidArgument, "bad id"), http.StatusBadRequest, "bad id", "warn: HTTP request failed"},
+		{"internal", errors.New(errors.Internal, "db down"), http.StatusInternalServerError, "db down", "error: HTTP request failed"},
+		// 未分类错误按 500 处理，不暴露内部细节
+		{"plain", stderrors.New("dial tcp 10.0.0.1:3306: refused"), http.StatusInternalServerError, "Internal Server Error", "error: HTTP request failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := newLevelLogger()
+			w := serveWithErrorHandler(log, func(c *gin.Context) { AbortWithError(c, tt.err) })
+
+			var body ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if w.Code != tt.status || body.Message != tt.message {
+				t.Fatalf("status = %d, body = %+v", w.Code, body)
+			}
+			if got := log.last(); got != tt.log {
+				t.Fatalf("log = %q, want %q", got, tt.log)
+			}
+		})
+	}
+}
+
+func TestErrorHandler_KeepsWrittenResponse(t *testing.T) {
+	w := serveWithErrorHandler(newLevelLogger(), func(c *gin.Context) {
+		c.String(http.StatusAccepted, "queued")
+		_ = c.Error(errors.New(errors.Internal, "late failure"))
+	})
+	if w.Code != http.StatusAccepted || w.Body.String() != "queued" {
+		t.Fatalf("status = %d, body = %q", w.Code, w.Body.String())
+	}
+}
+
+func TestErrorHandler_Recovery(t *testing.T) {
+	log := newLevelLogger()
+	w := serveWithErrorHandler(log, func(*gin.Context) { panic("boom") })
+	var body ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusInternalServerError || body.Code != int(errors.Internal) {
+		t.Fatalf("status = %d, body = %+v", w.Code, body)
+	}
+	if got := log.last(); got != "error: HTTP panic recovered" {
+		t.Fatalf("log = %q", got)
+	}
+
+	// 已写出部分响应后 panic，不在其后追加错误体
+	w = serveWithErrorHandler(log, func(c *gin.Context) {
+		c.String(http.StatusOK, "partial")
+		panic("boom")
+	})
+	if w.Code != http.StatusOK || w.Body.String() != "partial" || strings.Contains(w.Body.String(), "Internal") {
+		t.Fatalf("status = %d, body = %q", w.Code, w.Body.String())
+	}
+}
@@ -31,6 +31,10 @@ func Logging(log logger.Logger) gin.HandlerFunc {
 			logger.Int("body_size", c.Writer.Size()),
 		}
 
+		if meta, ok := GetRouteMeta(c); ok && meta.AuditCategory != "" {
+			fields = append(fields, logger.String("audit_category", meta.AuditCategory))
+		}
+
 		if len(c.Errors) > 0 {
 			fields = append(fields, logger.String("error", c.Errors.String()))
 		}
@@ -16,8 +16,13 @@ type Metrics struct {
 	RequestSize     observability.Histogram
 	ResponseSize    observability.Histogram
 	ActiveRequests  observability.Gauge
+
+	observers []RequestObserver
 }
 
+// RequestObserver 请求完成后的观察者，如 SLO 追踪（slo.Tracker.ObserveHTTP）
+type RequestObserver func(method, path string, status int, elapsed time.Duration)
+
 // NewMetrics 创建 HTTP 指标
 func NewMetrics(p observability.MetricsProvider) *Metrics {
 	return &Metrics{
@@ -29,6 +34,12 @@ func NewMetrics(p observability.MetricsProvider) *Metrics {
 	}
 }
 
+// OnRequest 添加请求观察者，在记录指标后同步调用
+func (m *Metrics) OnRequest(fns ...RequestObserver) *Metrics {
+	m.observers = append(m.observers, fns...)
+	return m
+}
+
 // Middleware 返回 Gin 指标中间件
 func (m *Metrics) Middleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -44,10 +55,17 @@ func (m *Metrics) Middleware() gin.HandlerFunc {
 
 		c.Next()
 
-		status := strconv.Itoa(c.Writer.Status())
-		m.RequestsTotal.Inc(method, path, status)
+		code := c.Writer.Status()
+		m.RequestsTotal.Inc(method, path, strconv.Itoa(code))
 		m.RequestDuration.Since(start, method, path)
 		m.RequestSize.Observe(float64(c.Request.ContentLength), method, path)
 		m.ResponseSize.Observe(float64(c.Writer.Size()), method, path)
+
+		if len(m.observers) > 0 {
+			elapsed := time.Since(start)
+			for _, fn := range m.observers {
+				fn(method, path, code, elapsed)
+			}
+		}
 	}
 }
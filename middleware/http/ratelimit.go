@@ -1,12 +1,14 @@
 package http
 
 import (
-	"net/http"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/time/rate"
+
+	"github.com/mildsunup/higo/errors"
+	mw "github.com/mildsunup/higo/middleware"
 )
 
 // RateLimiter 限流器配置
@@ -75,11 +77,13 @@ func RateLimiter(cfg RateLimiterConfig) gin.HandlerFunc {
 		key := cfg.KeyFunc(c)
 		limiter := getLimiter(key)
 
-		if !limiter.Allow() {
-			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
-				"code":    429,
-				"message": "Too Many Requests",
-			})
+		allowed := limiter.Allow()
+		quota := mw.NewQuota(limiter.Tokens(), limiter.Burst(), float64(limiter.Limit()))
+		for k, v := range quota.Headers() {
+			c.Header(k, v)
+		}
+		if !allowed {
+			writeError(c, errors.New(errors.ResourceExhausted, "Too Many Requests").WithRetryAfter(quota.RetryAfter), false)
 			return
 		}
 
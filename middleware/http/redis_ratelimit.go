@@ -0,0 +1,133 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mildsunup/higo/errors"
+	mw "github.com/mildsunup/higo/middleware"
+)
+
+// RateLimitKeyFunc 限流键函数，返回空字符串表示不限流
+type RateLimitKeyFunc func(*gin.Context) string
+
+// KeyByIP 按客户端 IP 限流
+func KeyByIP() RateLimitKeyFunc {
+	return func(c *gin.Context) string { return "ip:" + c.ClientIP() }
+}
+
+// KeyByUser 按认证用户限流（BearerAuth 写入的用户 ID），匿名请求按 IP
+func KeyByUser() RateLimitKeyFunc {
+	return func(c *gin.Context) string {
+		if userID, ok := mw.GetUserID(c.Request.Context()); ok {
+			return "user:" + strconv.FormatUint(userID, 10)
+		}
+		return "ip:" + c.ClientIP()
+	}
+}
+
+// KeyByAPIKey 按请求头中的 API Key 限流，键中只保存其哈希；未携带时按 IP
+func KeyByAPIKey(header string) RateLimitKeyFunc {
+	return func(c *gin.Context) string {
+		apiKey := c.GetHeader(header)
+		if apiKey == "" {
+			return "ip:" + c.ClientIP()
+		}
+		sum := sha256.Sum256([]byte(apiKey))
+		return "key:" + hex.EncodeToString(sum[:16])
+	}
+}
+
+// KeyByRoute 按路由模板（方法 + 注册路径）限流，所有调用方共享配额；未匹配路由时不限流
+func KeyByRoute() RateLimitKeyFunc {
+	return func(c *gin.Context) string {
+		if c.FullPath() == "" {
+			return ""
+		}
+		return "route:" + c.Request.Method + ":" + c.FullPath()
+	}
+}
+
+// KeyByComposite 组合多个键函数，如 KeyByComposite(KeyByUser(), KeyByRoute()) 按用户在每个路由上分别限流；
+// 任一键为空时不限流
+func KeyByComposite(fns ...RateLimitKeyFunc) RateLimitKeyFunc {
+	return func(c *gin.Context) string {
+		parts := make([]string, 0, len(fns))
+		for _, fn := range fns {
+			part := fn(c)
+			if part == "" {
+				return ""
+			}
+			parts = append(parts, part)
+		}
+		return strings.Join(parts, "|")
+	}
+}
+
+// DistributedRateLimiterConfig 分布式限流配置
+type DistributedRateLimiterConfig struct {
+	Limiter     *mw.RedisRateLimiter
+	Limit       mw.RateLimit            // 默认规则
+	Tiers       map[string]mw.RateLimit // 按路由元数据 RateLimitTier 选择规则，未配置的档位使用 Limit
+	KeyFunc     RateLimitKeyFunc        // 默认 KeyByIP
+	ExcludeFunc func(*gin.Context) bool // 排除函数
+	FailOpen    bool                    // Redis 不可用时放行（true）或返回 503（false）
+	OnError     func(*gin.Context, error)
+}
+
+// DistributedRateLimiter 基于 Redis 的分布式限流中间件，多实例共享配额。
+// 响应携带 X-RateLimit-Limit / Remaining / Reset，被限流时返回 429 并附带 Retry-After；
+// 需配合 Routes.Middleware 才能按档位限流
+func DistributedRateLimiter(cfg DistributedRateLimiterConfig) gin.HandlerFunc {
+	if cfg.KeyFunc == nil {
+		cfg.KeyFunc = KeyByIP()
+	}
+
+	return func(c *gin.Context) {
+		if cfg.ExcludeFunc != nil && cfg.ExcludeFunc(c) {
+			c.Next()
+			return
+		}
+		key := cfg.KeyFunc(c)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		limit := cfg.Limit
+		if meta, ok := GetRouteMeta(c); ok && meta.RateLimitTier != "" {
+			if tier, ok := cfg.Tiers[meta.RateLimitTier]; ok {
+				limit = tier
+				// 不同档位的规则不同，分开计数
+				key = "tier:" + meta.RateLimitTier + "|" + key
+			}
+		}
+
+		quota, allowed, err := cfg.Limiter.Allow(c.Request.Context(), key, limit)
+		if err != nil {
+			if cfg.OnError != nil {
+				cfg.OnError(c, err)
+			}
+			if cfg.FailOpen {
+				c.Next()
+				return
+			}
+			writeError(c, errors.Wrap(err, errors.Unavailable, "Rate Limiter Unavailable"), false)
+			return
+		}
+
+		for k, v := range quota.Headers() {
+			c.Header(k, v)
+		}
+		if !allowed {
+			writeError(c, errors.New(errors.ResourceExhausted, "Too Many Requests").WithRetryAfter(quota.RetryAfter), false)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,150 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+
+	mw "github.com/mildsunup/higo/middleware"
+)
+
+func init() { gin.SetMode(gin.TestMode) }
+
+func TestRateLimitKeyFuncs(t *testing.T) {
+	r := gin.New()
+	var keys map[string]string
+	r.GET("/orders/:id", func(c *gin.Context) {
+		keys = map[string]string{
+			"ip":        KeyByIP()(c),
+			"user":      KeyByUser()(c),
+			"apikey":    KeyByAPIKey("X-API-Key")(c),
+			"route":     KeyByRoute()(c),
+			"composite": KeyByComposite(KeyByUser(), KeyByRoute())(c),
+		}
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/orders/7", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-API-Key", "secret")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	sum := sha256.Sum256([]byte("secret"))
+	want := map[string]string{
+		"ip":        "ip:10.0.0.1",
+		"user":      "ip:10.0.0.1",
+		"apikey":    "key:" + hex.EncodeToString(sum[:16]),
+		"route":     "route:GET:/orders/:id",
+		"composite": "ip:10.0.0.1|route:GET:/orders/:id",
+	}
+	for name, w := range want {
+		if keys[name] != w {
+			t.Errorf("%s key = %q, want %q", name, keys[name], w)
+		}
+	}
+
+	// 已认证用户按用户 ID；未匹配路由时组合键为空（不限流）
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/missing", nil)
+	c.Request = c.Request.WithContext(mw.WithValue(c.Request.Context(), mw.UserIDKey, uint64(42)))
+	if k := KeyByUser()(c); k != "user:42" {
+		t.Fatalf("user key = %q", k)
+	}
+	if k := KeyByComposite(KeyByUser(), KeyByRoute())(c); k != "" {
+		t.Fatalf("composite key without route = %q", k)
+	}
+}
+
+func newDistributedRouter(t *testing.T, cfg DistributedRateLimiterConfig) (*gin.Engine, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { _ = rdb.Close() })
+	cfg.Limiter = mw.NewRedisRateLimiter(rdb, mw.RedisRateLimiterConfig{})
+
+	routes := NewRoutes()
+	r := gin.New()
+	r.Use(routes.Middleware(), DistributedRateLimiter(cfg))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	routes.Handle(&r.RouterGroup, http.MethodGet, "/free", RouteMeta{}, ok)
+	routes.Handle(&r.RouterGroup, http.MethodGet, "/export", RouteMeta{RateLimitTier: "heavy"}, ok)
+	return r, mr
+}
+
+func serve(r http.Handler, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestDistributedRateLimiter(t *testing.T) {
+	r, mr := newDistributedRouter(t, DistributedRateLimiterConfig{
+		Limit: mw.RateLimit{Limit: 2, Period: time.Minute},
+		Tiers: map[string]mw.RateLimit{"heavy": {Limit: 1, Period: time.Minute}},
+	})
+	mr.SetTime(time.Unix(1_700_000_000, 0))
+
+	rec := serve(r, "/free")
+	if rec.Code != http.StatusOK || rec.Header().Get(mw.HeaderRateLimitLimit) != "2" ||
+		rec.Header().Get(mw.HeaderRateLimitRemaining) != "1" || rec.Header().Get(mw.HeaderRateLimitReset) != "30" {
+		t.Fatalf("first = %d %v", rec.Code, rec.Header())
+	}
+	_ = serve(r, "/free")
+	rec = serve(r, "/free")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get(mw.HeaderRetryAfter) != "30" {
+		t.Fatalf("limited = %d %v", rec.Code, rec.Header())
+	}
+
+	// 档位独立计数，规则按档位
+	rec = serve(r, "/export")
+	if rec.Code != http.StatusOK || rec.Header().Get(mw.HeaderRateLimitLimit) != "1" {
+		t.Fatalf("tier first = %d %v", rec.Code, rec.Header())
+	}
+	if rec = serve(r, "/export"); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("tier second = %d", rec.Code)
+	}
+	if !mr.Exists("ratelimit:tier:heavy|ip:10.0.0.1") || !mr.Exists("ratelimit:ip:10.0.0.1") {
+		t.Fatalf("keys = %v", mr.Keys())
+	}
+}
+
+func TestDistributedRateLimiter_RedisDown(t *testing.T) {
+	for _, failOpen := range []bool{true, false} {
+		var errs int
+		r, mr := newDistributedRouter(t, DistributedRateLimiterConfig{
+			Limit:    mw.PerMinute(1),
+			FailOpen: failOpen,
+			OnError:  func(*gin.Context, error) { errs++ },
+		})
+		mr.Close()
+
+		rec := serve(r, "/free")
+		want := http.StatusServiceUnavailable
+		if failOpen {
+			want = http.StatusOK
+		}
+		if rec.Code != want || errs != 1 {
+			t.Fatalf("failOpen=%v: status = %d, errors = %d", failOpen, rec.Code, errs)
+		}
+	}
+}
+
+func TestDistributedRateLimiter_Exclude(t *testing.T) {
+	r, _ := newDistributedRouter(t, DistributedRateLimiterConfig{
+		Limit:       mw.PerMinute(1),
+		ExcludeFunc: func(c *gin.Context) bool { return c.FullPath() == "/free" },
+	})
+	for range 3 {
+		if rec := serve(r, "/free"); rec.Code != http.StatusOK || rec.Header().Get(mw.HeaderRateLimitLimit) != "" {
+			t.Fatalf("excluded = %d %v", rec.Code, rec.Header())
+		}
+	}
+}
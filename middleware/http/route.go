@@ -0,0 +1,161 @@
+package http
+
+import (
+	"context"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RouteMeta 路由元数据
+type RouteMeta struct {
+	Auth          bool          // 是否需要认证
+	RateLimitTier string        // 限流档位
+	Timeout       time.Duration // 请求超时，0 表示使用默认值
+	Idempotent    bool          // 是否启用幂等
+	AuditCategory string        // 审计分类
+	Extra         map[string]any
+}
+
+type routeMetaKey struct{}
+
+// Routes 路由注册表
+// 注册路由的同时声明元数据，由 Middleware 按匹配的路由写入 context，
+// 下游中间件通过 GetRouteMeta 读取并决定行为。
+type Routes struct {
+	mu    sync.RWMutex
+	metas map[string]RouteMeta
+}
+
+// NewRoutes 创建路由注册表
+func NewRoutes() *Routes {
+	return &Routes{metas: make(map[string]RouteMeta)}
+}
+
+// Handle 注册路由及其元数据
+func (r *Routes) Handle(g *gin.RouterGroup, method, relativePath string, meta RouteMeta, handlers ...gin.HandlerFunc) {
+	fullPath := joinPath(g.BasePath(), relativePath)
+
+	r.mu.Lock()
+	r.metas[routeKey(method, fullPath)] = meta
+	r.mu.Unlock()
+
+	g.Handle(method, relativePath, handlers...)
+}
+
+// Lookup 查询路由元数据
+func (r *Routes) Lookup(method, fullPath string) (RouteMeta, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	meta, ok := r.metas[routeKey(method, fullPath)]
+	return meta, ok
+}
+
+// Middleware 将匹配路由的元数据写入 context，需在依赖元数据的中间件之前注册
+func (r *Routes) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if meta, ok := r.Lookup(c.Request.Method, c.FullPath()); ok {
+			c.Request = c.Request.WithContext(WithRouteMeta(c.Request.Context(), meta))
+		}
+		c.Next()
+	}
+}
+
+// WithRouteMeta 设置路由元数据到 context
+func WithRouteMeta(ctx context.Context, meta RouteMeta) context.Context {
+	return context.WithValue(ctx, routeMetaKey{}, meta)
+}
+
+// RouteMetaFromContext 从 context 获取路由元数据
+func RouteMetaFromContext(ctx context.Context) (RouteMeta, bool) {
+	meta, ok := ctx.Value(routeMetaKey{}).(RouteMeta)
+	return meta, ok
+}
+
+// GetRouteMeta 从 Gin 上下文获取路由元数据
+func GetRouteMeta(c *gin.Context) (RouteMeta, bool) {
+	return RouteMetaFromContext(c.Request.Context())
+}
+
+// When 仅当路由元数据满足条件时执行中间件
+func When(pred func(RouteMeta) bool, h gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if meta, ok := GetRouteMeta(c); ok && pred(meta) {
+			h(c)
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireAuth 对声明 Auth 的路由执行认证
+func RequireAuth(validate TokenValidator) gin.HandlerFunc {
+	return When(func(m RouteMeta) bool { return m.Auth }, BearerAuth(validate))
+}
+
+// RequireIdempotent 对声明 Idempotent 的路由执行幂等控制
+func RequireIdempotent(cfg IdempotentConfig) gin.HandlerFunc {
+	return When(func(m RouteMeta) bool { return m.Idempotent }, Idempotent(cfg))
+}
+
+// RouteTimeout 按路由元数据设置超时，未声明时使用默认值
+func RouteTimeout(defaultTimeout time.Duration) gin.HandlerFunc {
+	fallback := Timeout(defaultTimeout)
+	var mu sync.Mutex
+	handlers := make(map[time.Duration]gin.HandlerFunc)
+
+	return func(c *gin.Context) {
+		meta, ok := GetRouteMeta(c)
+		if !ok || meta.Timeout <= 0 {
+			fallback(c)
+			return
+		}
+
+		mu.Lock()
+		h, ok := handlers[meta.Timeout]
+		if !ok {
+			h = Timeout(meta.Timeout)
+			handlers[meta.Timeout] = h
+		}
+		mu.Unlock()
+		h(c)
+	}
+}
+
+// TieredRateLimiter 按路由限流档位选择限流配置，未声明档位的路由不限流
+func TieredRateLimiter(tiers map[string]RateLimiterConfig) gin.HandlerFunc {
+	limiters := make(map[string]gin.HandlerFunc, len(tiers))
+	for tier, cfg := range tiers {
+		limiters[tier] = RateLimiter(cfg)
+	}
+
+	return func(c *gin.Context) {
+		meta, ok := GetRouteMeta(c)
+		if !ok {
+			c.Next()
+			return
+		}
+		if h, ok := limiters[meta.RateLimitTier]; ok {
+			h(c)
+			return
+		}
+		c.Next()
+	}
+}
+
+func routeKey(method, fullPath string) string {
+	return method + " " + fullPath
+}
+
+func joinPath(base, relative string) string {
+	if relative == "" {
+		return base
+	}
+	joined := path.Join(base, relative)
+	if relative[len(relative)-1] == '/' && joined[len(joined)-1] != '/' {
+		return joined + "/"
+	}
+	return joined
+}
@@ -0,0 +1,150 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRoutes_Middleware(t *testing.T) {
+	routes := NewRoutes()
+	r := gin.New()
+	r.Use(routes.Middleware())
+
+	var got *RouteMeta
+	capture := func(c *gin.Context) {
+		got = nil
+		if meta, ok := GetRouteMeta(c); ok {
+			got = &meta
+		}
+	}
+	api := r.Group("/api/v1")
+	routes.Handle(api, http.MethodPost, "/orders/:id/pay", RouteMeta{Auth: true, AuditCategory: "payment"}, capture)
+	routes.Handle(api, http.MethodGet, "/orders/", RouteMeta{RateLimitTier: "list"}, capture)
+	api.GET("/health", capture)
+
+	serve := func(method, path string) {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, path, nil))
+	}
+
+	// 按路由模板匹配，路径参数不影响查找
+	serve(http.MethodPost, "/api/v1/orders/42/pay")
+	if got == nil || !got.Auth || got.AuditCategory != "payment" {
+		t.Fatalf("pay meta = %+v", got)
+	}
+	serve(http.MethodGet, "/api/v1/orders/")
+	if got == nil || got.RateLimitTier != "list" {
+		t.Fatalf("list meta = %+v", got)
+	}
+	serve(http.MethodGet, "/api/v1/health")
+	if got != nil {
+		t.Fatalf("unregistered route meta = %+v", got)
+	}
+
+	if _, ok := routes.Lookup(http.MethodGet, "/api/v1/orders/:id/pay"); ok {
+		t.Fatal("lookup matched a different method")
+	}
+}
+
+func TestRequireAuth_OnlyDeclaredRoutes(t *testing.T) {
+	routes := NewRoutes()
+	r := gin.New()
+	r.Use(routes.Middleware(), RequireAuth(func(_ context.Context, token string) (uint64, error) {
+		return 7, nil
+	}))
+	ok := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+	routes.Handle(&r.RouterGroup, http.MethodGet, "/me", RouteMeta{Auth: true}, func(c *gin.Context) {
+		if id, _ := GetUserID(c); id != 7 {
+			t.Errorf("user id = %d", id)
+		}
+		c.Status(http.StatusNoContent)
+	})
+	routes.Handle(&r.RouterGroup, http.MethodGet, "/public", RouteMeta{}, ok)
+
+	tests := []struct {
+		path   string
+		token  string
+		status int
+	}{
+		{"/me", "", http.StatusUnauthorized},
+		{"/me", "Bearer t", http.StatusNoContent},
+		{"/public", "", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.token != "" {
+			req.Header.Set("Authorization", tt.token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.status {
+			t.Errorf("%s token=%q status = %d, want %d", tt.path, tt.token, w.Code, tt.status)
+		}
+	}
+}
+
+func TestTieredRateLimiter(t *testing.T) {
+	routes := NewRoutes()
+	r := gin.New()
+	r.Use(routes.Middleware(), TieredRateLimiter(map[string]RateLimiterConfig{
+		"strict": {Rate: 0.001, Burst: 1, KeyFunc: func(*gin.Context) string { return "k" }},
+	}))
+	ok := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+	routes.Handle(&r.RouterGroup, http.MethodGet, "/export", RouteMeta{RateLimitTier: "strict"}, ok)
+	routes.Handle(&r.RouterGroup, http.MethodGet, "/unknown-tier", RouteMeta{RateLimitTier: "missing"}, ok)
+
+	status := func(path string) int {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w.Code
+	}
+	if status("/export") != http.StatusNoContent || status("/export") != http.StatusTooManyRequests {
+		t.Fatal("strict tier not limited after burst")
+	}
+	for range 3 {
+		if code := status("/unknown-tier"); code != http.StatusNoContent {
+			t.Fatalf("unknown tier status = %d", code)
+		}
+	}
+}
+
+func TestRouteTimeout_Deadline(t *testing.T) {
+	routes := NewRoutes()
+	r := gin.New()
+	r.Use(routes.Middleware(), RouteTimeout(time.Minute))
+
+	var deadline time.Duration
+	record := func(c *gin.Context) {
+		if dl, ok := c.Request.Context().Deadline(); ok {
+			deadline = time.Until(dl)
+		}
+	}
+	routes.Handle(&r.RouterGroup, http.MethodGet, "/slow", RouteMeta{Timeout: 50 * time.Millisecond}, record)
+	routes.Handle(&r.RouterGroup, http.MethodGet, "/default", RouteMeta{}, record)
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+	if deadline <= 0 || deadline > 50*time.Millisecond {
+		t.Fatalf("route deadline = %v", deadline)
+	}
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/default", nil))
+	if deadline <= 50*time.Millisecond || deadline > time.Minute {
+		t.Fatalf("default deadline = %v", deadline)
+	}
+}
+
+func TestJoinPath(t *testing.T) {
+	for _, tt := range []struct{ base, rel, want string }{
+		{"/api", "", "/api"},
+		{"/api", "/orders", "/api/orders"},
+		{"/api/", "orders/", "/api/orders/"},
+		{"/", "/", "/"},
+	} {
+		if got := joinPath(tt.base, tt.rel); got != tt.want {
+			t.Errorf("joinPath(%q, %q) = %q, want %q", tt.base, tt.rel, got, tt.want)
+		}
+	}
+}
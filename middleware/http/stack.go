@@ -0,0 +1,92 @@
+package http
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+
+	"github.com/mildsunup/higo/logger"
+	mw "github.com/mildsunup/higo/middleware"
+	"github.com/mildsunup/higo/observability"
+)
+
+// RegisterStack 注册内置 HTTP 中间件到 Stack
+//
+// 名称：request_id、recovery、error_handler、logging、cors、timeout、rate_limit、tracing、metrics。
+func RegisterStack(s *mw.Stack, log logger.Logger, metrics observability.MetricsProvider) *mw.Stack {
+	s.RegisterGin("request_id", noParams(RequestID))
+	s.RegisterGin("recovery", noParams(func() gin.HandlerFunc { return Recovery(log) }))
+	s.RegisterGin("error_handler", noParams(func() gin.HandlerFunc { return ErrorHandler(log) }))
+	s.RegisterGin("logging", noParams(func() gin.HandlerFunc { return Logging(log) }))
+
+	s.RegisterGin("cors", func(p mw.Params) (gin.HandlerFunc, error) {
+		def := DefaultCORSConfig()
+		params := struct {
+			AllowOrigins     []string `yaml:"allow_origins"`
+			AllowMethods     []string `yaml:"allow_methods"`
+			AllowHeaders     []string `yaml:"allow_headers"`
+			ExposeHeaders    []string `yaml:"expose_headers"`
+			AllowCredentials bool     `yaml:"allow_credentials"`
+			MaxAge           int      `yaml:"max_age"`
+		}{def.AllowOrigins, def.AllowMethods, def.AllowHeaders, def.ExposeHeaders, def.AllowCredentials, def.MaxAge}
+		if err := p.Decode(&params); err != nil {
+			return nil, err
+		}
+		return CORS(CORSConfig(params)), nil
+	})
+
+	s.RegisterGin("timeout", func(p mw.Params) (gin.HandlerFunc, error) {
+		params := struct {
+			Timeout time.Duration `yaml:"timeout"`
+		}{Timeout: 30 * time.Second}
+		if err := p.Decode(&params); err != nil {
+			return nil, err
+		}
+		return Timeout(params.Timeout), nil
+	})
+
+	s.RegisterGin("rate_limit", func(p mw.Params) (gin.HandlerFunc, error) {
+		cfg := DefaultRateLimiterConfig()
+		params := struct {
+			Rate  float64 `yaml:"rate"`
+			Burst int     `yaml:"burst"`
+		}{Rate: float64(cfg.Rate), Burst: cfg.Burst}
+		if err := p.Decode(&params); err != nil {
+			return nil, err
+		}
+		cfg.Rate = rate.Limit(params.Rate)
+		cfg.Burst = params.Burst
+		return RateLimiter(cfg), nil
+	})
+
+	s.RegisterGin("tracing", func(p mw.Params) (gin.HandlerFunc, error) {
+		cfg := DefaultTracingConfig()
+		params := struct {
+			ServiceName string   `yaml:"service_name"`
+			SkipPaths   []string `yaml:"skip_paths"`
+		}{cfg.ServiceName, cfg.SkipPaths}
+		if err := p.Decode(&params); err != nil {
+			return nil, err
+		}
+		cfg.ServiceName = params.ServiceName
+		cfg.SkipPaths = params.SkipPaths
+		return Tracing(cfg), nil
+	})
+
+	if metrics != nil {
+		m := NewMetrics(metrics)
+		s.RegisterGin("metrics", noParams(m.Middleware))
+	}
+
+	return s
+}
+
+func noParams(fn func() gin.HandlerFunc) mw.GinFactory {
+	return func(p mw.Params) (gin.HandlerFunc, error) {
+		if err := p.Decode(&struct{}{}); err != nil {
+			return nil, err
+		}
+		return fn(), nil
+	}
+}
@@ -0,0 +1,112 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mildsunup/higo/config"
+	"github.com/mildsunup/higo/logger"
+	mw "github.com/mildsunup/higo/middleware"
+)
+
+func TestRegisterStack_FromConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := `
+app:
+  name: shop
+server:
+  http:
+    enabled: true
+    port: "8080"
+middleware:
+  http:
+    - name: request_id
+    - name: cors
+      params:
+        allow_origins: https://shop.example.com
+        allow_methods: GET
+    - name: rate_limit
+      enabled: false
+      params:
+        rate: 1
+        burst: 1
+    - name: timeout
+      params:
+        timeout: 50ms
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chain, err := RegisterStack(mw.NewStack(), logger.Nop(), nil).BuildGin(cfg.Middleware.HTTP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(chain.Then()); n != 3 {
+		t.Fatalf("chain length = %d, want 3", n)
+	}
+
+	r := gin.New()
+	chain.Apply(r)
+	r.GET("/fast", func(c *gin.Context) { c.Status(http.StatusOK) })
+	var deadline time.Duration
+	r.GET("/deadline", func(c *gin.Context) {
+		if dl, ok := c.Request.Context().Deadline(); ok {
+			deadline = time.Until(dl)
+		}
+	})
+
+	// rate_limit 已禁用：连续请求均放行
+	for range 3 {
+		req := httptest.NewRequest(http.MethodGet, "/fast", nil)
+		req.Header.Set("Origin", "https://shop.example.com")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d", w.Code)
+		}
+		if w.Header().Get(HeaderRequestID) == "" {
+			t.Fatal("request_id not applied")
+		}
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://shop.example.com" {
+			t.Fatalf("allow origin = %q", got)
+		}
+		if got := w.Header().Get("Access-Control-Allow-Methods"); got != "GET" {
+			t.Fatalf("allow methods = %q", got)
+		}
+	}
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/deadline", nil))
+	if deadline <= 0 || deadline > 50*time.Millisecond {
+		t.Fatalf("request deadline = %v, want timeout from params", deadline)
+	}
+}
+
+func TestRegisterStack_RejectsUnknownParams(t *testing.T) {
+	s := RegisterStack(mw.NewStack(), logger.Nop(), nil)
+
+	for _, spec := range []config.MiddlewareSpec{
+		{Name: "recovery", Params: map[string]any{"stack": true}},
+		{Name: "timeout", Params: map[string]any{"timout": "1s"}},
+	} {
+		if _, err := s.BuildGin([]config.MiddlewareSpec{spec}); err == nil || !strings.Contains(err.Error(), spec.Name) {
+			t.Errorf("%s: build = %v", spec.Name, err)
+		}
+	}
+
+	// 未提供 metrics 时不注册 metrics
+	if err := s.Validate(config.MiddlewareConfig{HTTP: []config.MiddlewareSpec{{Name: "metrics"}}}); err == nil {
+		t.Fatal("metrics registered without provider")
+	}
+}
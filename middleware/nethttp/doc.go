@@ -0,0 +1,24 @@
+// Package nethttp 提供标准库 net/http 形式的中间件。
+//
+// 中间件签名为 func(http.Handler) http.Handler，可直接用于 Go 1.22+ 的 http.ServeMux
+// 以及 chi（r.Use）等兼容标准签名的路由；行为与 middleware/http 中的 Gin 版本一致，
+// 共享请求 ID 头、context 键与 TokenValidator 等约定。
+//
+// 使用示例：
+//
+//	mux := http.NewServeMux()
+//	mux.HandleFunc("GET /users/{id}", getUser)
+//
+//	handler := nethttp.Chain(
+//	    nethttp.RealIP("10.0.0.0/8"), // 仅信任来自负载均衡网段的 X-Forwarded-For
+//	    nethttp.RequestID(),
+//	    nethttp.Recovery(log),
+//	    nethttp.Logging(log),
+//	    nethttp.RateLimiter(nethttp.DefaultRateLimiterConfig()),
+//	)(mux)
+//
+//	// chi
+//	r := chi.NewRouter()
+//	r.Use(nethttp.RequestID(), nethttp.Logging(log))
+//	r.With(nethttp.BearerAuth(validate)).Get("/me", me)
+package nethttp
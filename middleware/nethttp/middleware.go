@@ -0,0 +1,222 @@
+package nethttp
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
+
+	"github.com/mildsunup/higo/ddd"
+	"github.com/mildsunup/higo/errors"
+	"github.com/mildsunup/higo/logger"
+	mw "github.com/mildsunup/higo/middleware"
+	httpmw "github.com/mildsunup/higo/middleware/http"
+	"github.com/mildsunup/higo/resilience"
+	"github.com/mildsunup/higo/response"
+)
+
+// RequestID 请求 ID 中间件
+func RequestID() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(httpmw.HeaderRequestID)
+			if requestID == "" {
+				requestID = uuid.New().String()
+			}
+
+			ctx := mw.WithValue(r.Context(), mw.RequestIDKey, requestID)
+			w.Header().Set(httpmw.HeaderRequestID, requestID)
+
+			if sc := trace.SpanFromContext(ctx).SpanContext(); sc.IsValid() {
+				ctx = mw.WithValue(ctx, mw.TraceIDKey, sc.TraceID().String())
+				ctx = mw.WithValue(ctx, mw.SpanIDKey, sc.SpanID().String())
+				w.Header().Set(httpmw.HeaderTraceID, sc.TraceID().String())
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Recovery panic 恢复中间件
+func Recovery(log logger.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				if rec := recover(); rec != nil {
+					if rec == http.ErrAbortHandler {
+						panic(rec)
+					}
+					log.Error(r.Context(), "HTTP panic recovered",
+						logger.Any("panic", rec),
+						logger.String("stack", string(debug.Stack())),
+						logger.String("method", r.Method),
+						logger.String("path", r.URL.Path),
+						logger.String("client_ip", ClientIP(r)),
+					)
+					response.WriteError(w, r, errors.New(errors.Internal, "Internal Server Error"))
+				}
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Logging 日志中间件
+func Logging(log logger.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &statusRecorder{ResponseWriter: w}
+
+			next.ServeHTTP(rec, r)
+
+			status := rec.Status()
+			fields := []logger.Field{
+				logger.String("method", r.Method),
+				logger.String("path", r.URL.Path),
+				logger.String("query", r.URL.RawQuery),
+				logger.Int("status", status),
+				logger.Duration("latency", time.Since(start)),
+				logger.String("client_ip", ClientIP(r)),
+				logger.Int("body_size", rec.size),
+			}
+			if r.Pattern != "" {
+				fields = append(fields, logger.String("route", r.Pattern))
+			}
+
+			ctx := r.Context()
+			switch {
+			case status >= 500:
+				log.Error(ctx, "HTTP request", fields...)
+			case status >= 400:
+				log.Warn(ctx, "HTTP request", fields...)
+			default:
+				log.Info(ctx, "HTTP request", fields...)
+			}
+		})
+	}
+}
+
+// RateLimiterConfig 限流配置
+type RateLimiterConfig struct {
+	Rate        rate.Limit                 // 每秒请求数
+	Burst       int                        // 突发容量
+	KeyFunc     func(*http.Request) string // 限流键函数
+	ExcludeFunc func(*http.Request) bool   // 排除函数
+}
+
+// DefaultRateLimiterConfig 默认配置
+func DefaultRateLimiterConfig() RateLimiterConfig {
+	return RateLimiterConfig{
+		Rate:    100,
+		Burst:   200,
+		KeyFunc: ClientIP,
+	}
+}
+
+// RateLimiter 限流中间件
+func RateLimiter(cfg RateLimiterConfig) Middleware {
+	if cfg.KeyFunc == nil {
+		cfg.KeyFunc = ClientIP
+	}
+
+	var (
+		mu       sync.Mutex
+		limiters = make(map[string]*rate.Limiter)
+		resetAt  = time.Now().Add(time.Hour)
+	)
+	getLimiter := func(key string) *rate.Limiter {
+		mu.Lock()
+		defer mu.Unlock()
+		// 定期清理，避免键无限增长
+		if now := time.Now(); now.After(resetAt) {
+			clear(limiters)
+			resetAt = now.Add(time.Hour)
+		}
+		limiter, ok := limiters[key]
+		if !ok {
+			limiter = rate.NewLimiter(cfg.Rate, cfg.Burst)
+			limiters[key] = limiter
+		}
+		return limiter
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if cfg.ExcludeFunc != nil && cfg.ExcludeFunc(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			limiter := getLimiter(cfg.KeyFunc(r))
+			allowed := limiter.Allow()
+			quota := mw.NewQuota(limiter.Tokens(), limiter.Burst(), float64(limiter.Limit()))
+			for k, v := range quota.Headers() {
+				w.Header().Set(k, v)
+			}
+			if !allowed {
+				response.WriteError(w, r, errors.New(errors.ResourceExhausted, "Too Many Requests").WithRetryAfter(quota.RetryAfter))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// CircuitBreaker 熔断中间件，5xx 响应计为失败；熔断打开时返回 503 并附带 Retry-After
+func CircuitBreaker(cb *resilience.CircuitBreaker) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			err := cb.Execute(r.Context(), func(context.Context) error {
+				rec := &statusRecorder{ResponseWriter: w}
+				next.ServeHTTP(rec, r)
+				if rec.Status() >= http.StatusInternalServerError {
+					return errServerFailure
+				}
+				return nil
+			})
+			if errors.Is(err, resilience.ErrCircuitOpen) {
+				response.WriteError(w, r, errors.Wrap(err, errors.Unavailable, "Service Unavailable").WithRetryAfter(cb.RetryAfter()))
+			}
+		})
+	}
+}
+
+var errServerFailure = stderrors.New("server error response")
+
+// BearerAuth Bearer Token 认证中间件，用户 ID 写入请求 context（middleware.GetUserID 读取）
+func BearerAuth(validate httpmw.TokenValidator) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authHeader := r.Header.Get("Authorization")
+			if authHeader == "" {
+				writeJSON(w, http.StatusUnauthorized, response.Response[any]{Code: 401, Message: "missing authorization header"})
+				return
+			}
+
+			scheme, token, ok := strings.Cut(authHeader, " ")
+			if !ok || !strings.EqualFold(scheme, "Bearer") {
+				writeJSON(w, http.StatusUnauthorized, response.Response[any]{Code: 401, Message: "invalid authorization format"})
+				return
+			}
+
+			userID, err := validate(r.Context(), token)
+			if err != nil {
+				writeJSON(w, http.StatusUnauthorized, response.Response[any]{Code: 401, Message: "invalid token"})
+				return
+			}
+
+			ctx := mw.WithValue(r.Context(), mw.UserIDKey, userID)
+			ctx = ddd.WithActor(ctx, strconv.FormatUint(userID, 10))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
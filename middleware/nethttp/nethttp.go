@@ -0,0 +1,150 @@
+package nethttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+
+	mw "github.com/mildsunup/higo/middleware"
+)
+
+// Middleware 标准库中间件，与 chi 的中间件类型一致
+type Middleware = func(http.Handler) http.Handler
+
+// Chain 组合中间件，按参数顺序由外到内执行
+func Chain(mws ...Middleware) Middleware {
+	return func(next http.Handler) http.Handler {
+		for i := len(mws) - 1; i >= 0; i-- {
+			next = mws[i](next)
+		}
+		return next
+	}
+}
+
+// ClientIP 获取客户端 IP：经 RealIP 解析过的请求返回解析结果，否则返回直连对端地址。
+// 未配置可信代理时不读取 X-Forwarded-For / X-Real-IP，避免客户端伪造来源绕过按 IP 限流
+func ClientIP(r *http.Request) string {
+	if ip := mw.GetClientIP(r.Context()); ip != "" {
+		return ip
+	}
+	return remoteIP(r)
+}
+
+// RealIP 解析客户端 IP 并写入请求 context（ClientIP、middleware.GetClientIP 读取），应置于中间件链最外层。
+// 仅当直连对端属于 trustedProxies（IP 或 CIDR，如 "10.0.0.0/8"）时才采信转发头：
+// X-Forwarded-For 自右向左跳过可信代理后的第一个地址，其次 X-Real-IP。无效的代理配置会 panic
+//
+//	handler := nethttp.Chain(nethttp.RealIP("10.0.0.0/8", "127.0.0.1"), nethttp.Logging(log))(mux)
+func RealIP(trustedProxies ...string) Middleware {
+	trusted := make([]netip.Prefix, 0, len(trustedProxies))
+	for _, p := range trustedProxies {
+		prefix, err := netip.ParsePrefix(p)
+		if err != nil {
+			addr, aerr := netip.ParseAddr(p)
+			if aerr != nil {
+				panic(fmt.Sprintf("nethttp: invalid trusted proxy %q", p))
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		trusted = append(trusted, prefix.Masked())
+	}
+	isTrusted := func(ip string) bool {
+		addr, err := netip.ParseAddr(ip)
+		if err != nil {
+			return false
+		}
+		addr = addr.Unmap()
+		return slices.ContainsFunc(trusted, func(p netip.Prefix) bool { return p.Contains(addr) })
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := remoteIP(r)
+			if isTrusted(ip) {
+				ip = forwardedIP(r, ip, isTrusted)
+			}
+			next.ServeHTTP(w, r.WithContext(mw.WithValue(r.Context(), mw.ClientIPKey, ip)))
+		})
+	}
+}
+
+// forwardedIP 从转发头中取最近一个不可信的地址，全部可信时取最左侧地址
+func forwardedIP(r *http.Request, remote string, isTrusted func(string) bool) string {
+	var hops []string
+	for _, h := range r.Header.Values("X-Forwarded-For") {
+		for part := range strings.SplitSeq(h, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				hops = append(hops, part)
+			}
+		}
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		if _, err := netip.ParseAddr(hops[i]); err != nil {
+			break // 无法解析的地址之前的内容不可信
+		}
+		if !isTrusted(hops[i]) || i == 0 {
+			return hops[i]
+		}
+	}
+	if len(hops) > 0 {
+		return remote
+	}
+	if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); ip != "" {
+		if _, err := netip.ParseAddr(ip); err == nil {
+			return ip
+		}
+	}
+	return remote
+}
+
+// remoteIP 直连对端地址
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(strings.TrimSpace(r.RemoteAddr))
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// statusRecorder 记录响应状态码与大小
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	size   int
+}
+
+func (w *statusRecorder) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusRecorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.size += n
+	return n, err
+}
+
+// Unwrap 供 http.ResponseController 访问底层 ResponseWriter
+func (w *statusRecorder) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+func (w *statusRecorder) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,105 @@
+package nethttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mildsunup/higo/logger"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name    string
+		trusted []string
+		remote  string
+		xff     []string
+		realIP  string
+		want    string
+	}{
+		{name: "no proxy config ignores headers", remote: "203.0.113.9:1234", xff: []string{"1.2.3.4"}, realIP: "5.6.7.8", want: "203.0.113.9"},
+		{name: "untrusted peer ignores headers", trusted: []string{"10.0.0.0/8"}, remote: "203.0.113.9:1234", xff: []string{"1.2.3.4"}, want: "203.0.113.9"},
+		{name: "trusted peer", trusted: []string{"10.0.0.0/8"}, remote: "10.0.0.2:80", xff: []string{"1.2.3.4"}, want: "1.2.3.4"},
+		{name: "spoofed leftmost entry skipped", trusted: []string{"10.0.0.0/8"}, remote: "10.0.0.2:80", xff: []string{"6.6.6.6, 1.2.3.4, 10.0.0.1"}, want: "1.2.3.4"},
+		{name: "multiple headers", trusted: []string{"10.0.0.0/8"}, remote: "10.0.0.2:80", xff: []string{"6.6.6.6", "1.2.3.4"}, want: "1.2.3.4"},
+		{name: "all hops trusted", trusted: []string{"10.0.0.0/8"}, remote: "10.0.0.2:80", xff: []string{"10.1.1.1, 10.0.0.1"}, want: "10.1.1.1"},
+		{name: "garbage hop", trusted: []string{"10.0.0.0/8"}, remote: "10.0.0.2:80", xff: []string{"1.2.3.4, nonsense"}, want: "10.0.0.2"},
+		{name: "x-real-ip", trusted: []string{"127.0.0.1"}, remote: "127.0.0.1:80", realIP: "1.2.3.4", want: "1.2.3.4"},
+		{name: "ipv6 peer", trusted: []string{"::1"}, remote: "[::1]:80", xff: []string{"2001:db8::1"}, want: "2001:db8::1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			h := RealIP(tt.trusted...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = ClientIP(r)
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remote
+			for _, v := range tt.xff {
+				req.Header.Add("X-Forwarded-For", v)
+			}
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), req)
+			if got != tt.want {
+				t.Errorf("ClientIP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientIP_WithoutRealIP(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "203.0.113.9:1234"
+	req.Header.Set("X-Forwarded-For", "1.2.3.4")
+	if got := ClientIP(req); got != "203.0.113.9" {
+		t.Fatalf("ClientIP = %q", got)
+	}
+}
+
+func TestRealIP_InvalidProxy(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for invalid proxy")
+		}
+	}()
+	RealIP("not-an-ip")
+}
+
+func TestRecovery(t *testing.T) {
+	h := Chain(RequestID(), Recovery(logger.Nop()))(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set("Accept", "application/problem+json")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Fatalf("content type = %q", ct)
+	}
+	var body map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body["status"] != float64(500) || body["instance"] != "/orders" || body["request_id"] == nil {
+		t.Fatalf("problem = %v", body)
+	}
+}
+
+func TestRecovery_AbortHandler(t *testing.T) {
+	h := Recovery(logger.Nop())(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	defer func() {
+		if recover() != http.ErrAbortHandler {
+			t.Fatal("ErrAbortHandler must propagate")
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
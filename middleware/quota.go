@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"math"
+	"strconv"
+	"time"
+)
+
+// 限流配额头（gRPC 中以小写形式放入 trailer）
+const (
+	HeaderRateLimitLimit     = "X-RateLimit-Limit"
+	HeaderRateLimitRemaining = "X-RateLimit-Remaining"
+	HeaderRateLimitReset     = "X-RateLimit-Reset" // 令牌桶恢复满额的秒数
+	HeaderRetryAfter         = "Retry-After"
+)
+
+// Quota 限流配额快照
+type Quota struct {
+	Limit      int           `json:"limit"`                 // 突发容量
+	Remaining  int           `json:"remaining"`             // 当前可用请求数
+	Rate       float64       `json:"rate"`                  // 每秒补充数
+	Reset      time.Duration `json:"reset"`                 // 恢复满额所需时间
+	RetryAfter time.Duration `json:"retry_after,omitempty"` // 被限流时下一次可用的等待时间
+}
+
+// NewQuota 由令牌桶状态计算配额
+func NewQuota(tokens float64, burst int, rate float64) Quota {
+	q := Quota{Limit: burst, Rate: rate, Remaining: max(int(math.Floor(tokens)), 0)}
+	if rate > 0 {
+		if missing := float64(burst) - tokens; missing > 0 {
+			q.Reset = time.Duration(missing / rate * float64(time.Second))
+		}
+		if tokens < 1 {
+			q.RetryAfter = time.Duration((1 - tokens) / rate * float64(time.Second))
+		}
+	}
+	return q
+}
+
+// Headers 返回配额头，被限流时包含 Retry-After
+func (q Quota) Headers() map[string]string {
+	h := map[string]string{
+		HeaderRateLimitLimit:     strconv.Itoa(q.Limit),
+		HeaderRateLimitRemaining: strconv.Itoa(q.Remaining),
+		HeaderRateLimitReset:     strconv.Itoa(ceilSeconds(q.Reset)),
+	}
+	if q.RetryAfter > 0 {
+		h[HeaderRetryAfter] = strconv.Itoa(ceilSeconds(q.RetryAfter))
+	}
+	return h
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
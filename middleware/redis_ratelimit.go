@@ -0,0 +1,195 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// RateLimitAlgorithm 分布式限流算法
+type RateLimitAlgorithm string
+
+const (
+	// AlgorithmGCRA 通用信元速率算法：等价于令牌桶，每个键只存一个时间戳，允许 Burst 突发
+	AlgorithmGCRA RateLimitAlgorithm = "gcra"
+	// AlgorithmSlidingWindow 滑动窗口日志：任意 Period 内最多 Limit 次，精确但每个键占用 Limit 个有序集合成员
+	AlgorithmSlidingWindow RateLimitAlgorithm = "sliding_window"
+)
+
+var (
+	// ErrInvalidRateLimit 限流规则无效
+	ErrInvalidRateLimit = errors.New("ratelimit: limit and period must be positive")
+	// ErrRateLimitCost 单次消耗为负或超过容量，永远无法满足
+	ErrRateLimitCost = errors.New("ratelimit: cost must be between 0 and capacity")
+)
+
+// RateLimit 限流规则：每 Period 最多 Limit 次请求
+type RateLimit struct {
+	Limit  int
+	Period time.Duration
+	Burst  int // GCRA 突发容量，0 时等于 Limit；滑动窗口忽略
+}
+
+// PerSecond 每秒 n 次
+func PerSecond(n int) RateLimit {
+	return RateLimit{Limit: n, Period: time.Second}
+}
+
+// PerMinute 每分钟 n 次
+func PerMinute(n int) RateLimit {
+	return RateLimit{Limit: n, Period: time.Minute}
+}
+
+// PerHour 每小时 n 次
+func PerHour(n int) RateLimit {
+	return RateLimit{Limit: n, Period: time.Hour}
+}
+
+func (l RateLimit) burst() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Limit
+}
+
+// Redis Lua 脚本，时间取 Redis 服务端 TIME（微秒），避免各实例时钟偏差
+var (
+	// GCRA：键存储理论到达时间（TAT），一次消耗 ARGV[3] 个配额（0 表示只查询），
+	// 返回 {allowed, remaining, retry_after, reset}
+	gcraScript = redis.NewScript(`
+		redis.replicate_commands()
+		local emission = tonumber(ARGV[1])
+		local tolerance = emission * tonumber(ARGV[2])
+		local cost = tonumber(ARGV[3])
+		local t = redis.call("TIME")
+		local now = t[1] * 1000000 + t[2]
+		local tat = tonumber(redis.call("GET", KEYS[1])) or now
+		if tat < now then
+			tat = now
+		end
+		local new_tat = tat + emission * cost
+		local allow_at = new_tat - tolerance
+		if now < allow_at then
+			return {0, math.max(math.floor((tolerance - (tat - now)) / emission), 0), math.ceil(allow_at - now), math.ceil(tat - now)}
+		end
+		if cost > 0 then
+			redis.call("SET", KEYS[1], new_tat, "PX", math.ceil((new_tat - now) / 1000))
+		end
+		return {1, math.floor((tolerance - (new_tat - now)) / emission), 0, math.ceil(new_tat - now)}
+	`)
+
+	// 滑动窗口日志：有序集合按请求时间记录窗口内的请求，一次消耗 ARGV[4] 个配额，
+	// 返回 {allowed, remaining, retry_after, reset}
+	slidingWindowScript = redis.NewScript(`
+		redis.replicate_commands()
+		local limit = tonumber(ARGV[1])
+		local window = tonumber(ARGV[2])
+		local cost = tonumber(ARGV[4])
+		local t = redis.call("TIME")
+		local now = t[1] * 1000000 + t[2]
+		redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now - window)
+		local count = redis.call("ZCARD", KEYS[1])
+		local allowed = 0
+		if count + cost <= limit then
+			for i = 1, cost do
+				redis.call("ZADD", KEYS[1], now, ARGV[3] .. ":" .. i)
+			end
+			count = count + cost
+			allowed = 1
+		end
+		local retry_after = 0
+		local reset = 0
+		if count > 0 then
+			local newest = redis.call("ZRANGE", KEYS[1], -1, -1, "WITHSCORES")
+			reset = tonumber(newest[2]) + window - now
+			redis.call("PEXPIRE", KEYS[1], math.ceil(reset / 1000))
+			if allowed == 0 then
+				local freed = redis.call("ZRANGE", KEYS[1], count + cost - limit - 1, count + cost - limit - 1, "WITHSCORES")
+				retry_after = tonumber(freed[2]) + window - now
+			end
+		end
+		return {allowed, math.max(limit - count, 0), math.ceil(retry_after), math.ceil(reset)}
+	`)
+)
+
+// RedisRateLimiterConfig 分布式限流器配置
+type RedisRateLimiterConfig struct {
+	Algorithm RateLimitAlgorithm // 默认 GCRA
+	Prefix    string             // 键前缀，默认 "ratelimit:"
+}
+
+// RedisRateLimiter 基于 Redis 的分布式限流器，多实例共享配额。
+// 每次判定是一次原子的 Lua 脚本调用，规则随调用传入，同一个限流器可服务不同档位
+type RedisRateLimiter struct {
+	client    redis.Cmdable
+	algorithm RateLimitAlgorithm
+	prefix    string
+}
+
+// NewRedisRateLimiter 创建分布式限流器
+func NewRedisRateLimiter(client redis.Cmdable, cfg RedisRateLimiterConfig) *RedisRateLimiter {
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = AlgorithmGCRA
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = "ratelimit:"
+	}
+	return &RedisRateLimiter{client: client, algorithm: cfg.Algorithm, prefix: cfg.Prefix}
+}
+
+// Allow 按规则消耗一次配额，返回配额快照与是否放行；Redis 不可用时返回错误，由调用方决定放行或拒绝
+func (l *RedisRateLimiter) Allow(ctx context.Context, key string, limit RateLimit) (Quota, bool, error) {
+	return l.AllowN(ctx, key, limit, 1)
+}
+
+// AllowN 按规则一次消耗 n 个配额（n 为 0 时只查询，不消耗），其余同 Allow
+func (l *RedisRateLimiter) AllowN(ctx context.Context, key string, limit RateLimit, n int) (Quota, bool, error) {
+	if limit.Limit <= 0 || limit.Period <= 0 {
+		return Quota{}, false, ErrInvalidRateLimit
+	}
+
+	var (
+		capacity int
+		res      []int64
+		err      error
+	)
+	switch l.algorithm {
+	case AlgorithmSlidingWindow:
+		capacity = limit.Limit
+		if n < 0 || n > capacity {
+			return Quota{}, false, ErrRateLimitCost
+		}
+		res, err = slidingWindowScript.Run(ctx, l.client, []string{l.prefix + key},
+			limit.Limit, limit.Period.Microseconds(), uuid.NewString(), n).Int64Slice()
+	case AlgorithmGCRA:
+		capacity = limit.burst()
+		if n < 0 || n > capacity {
+			return Quota{}, false, ErrRateLimitCost
+		}
+		emission := float64(limit.Period.Microseconds()) / float64(limit.Limit)
+		res, err = gcraScript.Run(ctx, l.client, []string{l.prefix + key}, emission, capacity, n).Int64Slice()
+	default:
+		return Quota{}, false, fmt.Errorf("ratelimit: unknown algorithm %q", l.algorithm)
+	}
+	if err != nil {
+		return Quota{}, false, fmt.Errorf("ratelimit: %w", err)
+	}
+
+	q := Quota{
+		Limit:      capacity,
+		Remaining:  int(res[1]),
+		Rate:       float64(limit.Limit) / limit.Period.Seconds(),
+		RetryAfter: time.Duration(res[2]) * time.Microsecond,
+		Reset:      time.Duration(res[3]) * time.Microsecond,
+	}
+	return q, res[0] == 1, nil
+}
+
+// Reset 清除键的限流状态
+func (l *RedisRateLimiter) Reset(ctx context.Context, key string) error {
+	return l.client.Del(ctx, l.prefix+key).Err()
+}
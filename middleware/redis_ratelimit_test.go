@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newRedisLimiter(t *testing.T, algorithm RateLimitAlgorithm) (*RedisRateLimiter, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	return NewRedisRateLimiter(rdb, RedisRateLimiterConfig{Algorithm: algorithm}), mr
+}
+
+func allow(t *testing.T, l *RedisRateLimiter, key string, limit RateLimit, n int) (Quota, bool) {
+	t.Helper()
+	q, ok, err := l.AllowN(context.Background(), key, limit, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return q, ok
+}
+
+func TestRedisRateLimiter_GCRA(t *testing.T) {
+	l, mr := newRedisLimiter(t, AlgorithmGCRA)
+	t0 := time.Unix(1_700_000_000, 0)
+	mr.SetTime(t0)
+	// 每 100ms 一个配额，突发 2
+	limit := RateLimit{Limit: 10, Period: time.Second, Burst: 2}
+
+	q, ok := allow(t, l, "k", limit, 1)
+	if !ok || q.Limit != 2 || q.Remaining != 1 || q.Rate != 10 || q.Reset != 100*time.Millisecond {
+		t.Fatalf("first = %+v, %v", q, ok)
+	}
+	if q, ok = allow(t, l, "k", limit, 1); !ok || q.Remaining != 0 || q.Reset != 200*time.Millisecond {
+		t.Fatalf("second = %+v, %v", q, ok)
+	}
+	q, ok = allow(t, l, "k", limit, 1)
+	if ok || q.Remaining != 0 || q.RetryAfter != 100*time.Millisecond || q.Reset != 200*time.Millisecond {
+		t.Fatalf("denied = %+v, %v", q, ok)
+	}
+
+	mr.SetTime(t0.Add(150 * time.Millisecond))
+	if q, ok = allow(t, l, "k", limit, 0); !ok || q.Remaining != 1 {
+		t.Fatalf("peek = %+v, %v", q, ok)
+	}
+	if q, ok = allow(t, l, "k", limit, 1); !ok || q.Remaining != 0 || q.Reset != 150*time.Millisecond {
+		t.Fatalf("after refill = %+v, %v", q, ok)
+	}
+
+	// 长时间空闲后恢复满额，一次消耗多个
+	mr.SetTime(t0.Add(time.Hour))
+	if q, ok = allow(t, l, "k", limit, 2); !ok || q.Remaining != 0 {
+		t.Fatalf("cost 2 = %+v, %v", q, ok)
+	}
+	if _, _, err := l.AllowN(context.Background(), "k", limit, 3); !errors.Is(err, ErrRateLimitCost) {
+		t.Fatalf("cost above burst = %v", err)
+	}
+}
+
+func TestRedisRateLimiter_SlidingWindow(t *testing.T) {
+	l, mr := newRedisLimiter(t, AlgorithmSlidingWindow)
+	t0 := time.Unix(1_700_000_000, 0)
+	limit := RateLimit{Limit: 3, Period: 10 * time.Second}
+
+	for i := range 3 {
+		mr.SetTime(t0.Add(time.Duration(i) * time.Second))
+		q, ok := allow(t, l, "k", limit, 1)
+		if !ok || q.Limit != 3 || q.Remaining != 2-i || q.Reset != 10*time.Second {
+			t.Fatalf("request %d = %+v, %v", i, q, ok)
+		}
+	}
+
+	// 最早的请求在 t0+10s 滑出窗口
+	mr.SetTime(t0.Add(3 * time.Second))
+	q, ok := allow(t, l, "k", limit, 1)
+	if ok || q.Remaining != 0 || q.RetryAfter != 7*time.Second || q.Reset != 9*time.Second {
+		t.Fatalf("denied = %+v, %v", q, ok)
+	}
+	mr.SetTime(t0.Add(10 * time.Second))
+	if q, ok = allow(t, l, "k", limit, 1); !ok || q.Remaining != 0 {
+		t.Fatalf("after slide = %+v, %v", q, ok)
+	}
+
+	// 一次消耗多个时，需等待足够多的旧请求滑出
+	mr.SetTime(t0)
+	if q, ok = allow(t, l, "multi", limit, 2); !ok || q.Remaining != 1 {
+		t.Fatalf("cost 2 = %+v, %v", q, ok)
+	}
+	mr.SetTime(t0.Add(4 * time.Second))
+	if q, ok = allow(t, l, "multi", limit, 2); ok || q.RetryAfter != 6*time.Second {
+		t.Fatalf("cost 2 denied = %+v, %v", q, ok)
+	}
+
+	if err := l.Reset(context.Background(), "k"); err != nil {
+		t.Fatal(err)
+	}
+	if mr.Exists("ratelimit:k") {
+		t.Fatal("reset kept state")
+	}
+}
+
+func TestRedisRateLimiter_Invalid(t *testing.T) {
+	l, _ := newRedisLimiter(t, AlgorithmGCRA)
+	if _, _, err := l.Allow(context.Background(), "k", RateLimit{Limit: 0, Period: time.Second}); !errors.Is(err, ErrInvalidRateLimit) {
+		t.Fatalf("zero limit = %v", err)
+	}
+	bad := NewRedisRateLimiter(l.client, RedisRateLimiterConfig{Algorithm: "leaky"})
+	if _, _, err := bad.Allow(context.Background(), "k", PerSecond(1)); err == nil {
+		t.Fatal("unknown algorithm accepted")
+	}
+}
@@ -0,0 +1,175 @@
+package middleware
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mitchellh/mapstructure"
+	"google.golang.org/grpc"
+
+	"github.com/mildsunup/higo/config"
+)
+
+// Params 中间件参数
+type Params map[string]any
+
+// Decode 将参数解码到结构体（yaml tag），存在未知参数时返回错误
+//
+// 切片参数整体替换目标中的默认值，而不是按下标覆盖。
+func (p Params) Decode(target any) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           target,
+		TagName:          "yaml",
+		WeaklyTypedInput: true,
+		ErrorUnused:      true,
+		ZeroFields:       true,
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToSliceHookFunc(","),
+		),
+	})
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(map[string]any(p))
+}
+
+// GinFactory 根据参数创建 Gin 中间件
+type GinFactory func(p Params) (gin.HandlerFunc, error)
+
+// GRPCInterceptors gRPC 拦截器对，任一可为 nil
+type GRPCInterceptors struct {
+	Unary  grpc.UnaryServerInterceptor
+	Stream grpc.StreamServerInterceptor
+}
+
+// GRPCFactory 根据参数创建 gRPC 拦截器
+type GRPCFactory func(p Params) (GRPCInterceptors, error)
+
+// Stack 声明式中间件栈构建器
+// 按名称注册中间件工厂，再由配置决定启用哪些中间件、参数和顺序。
+type Stack struct {
+	mu   sync.RWMutex
+	gin  map[string]GinFactory
+	grpc map[string]GRPCFactory
+}
+
+// NewStack 创建中间件栈构建器
+func NewStack() *Stack {
+	return &Stack{
+		gin:  make(map[string]GinFactory),
+		grpc: make(map[string]GRPCFactory),
+	}
+}
+
+// RegisterGin 注册 Gin 中间件工厂
+func (s *Stack) RegisterGin(name string, f GinFactory) *Stack {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gin[name] = f
+	return s
+}
+
+// RegisterGRPC 注册 gRPC 拦截器工厂
+func (s *Stack) RegisterGRPC(name string, f GRPCFactory) *Stack {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.grpc[name] = f
+	return s
+}
+
+// Validate 校验配置中的中间件名称（包括未启用的），未知名称返回错误
+func (s *Stack) Validate(cfg config.MiddlewareConfig) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, spec := range cfg.HTTP {
+		if _, ok := s.gin[spec.Name]; !ok {
+			return fmt.Errorf("middleware: unknown http middleware %q (available: %v)", spec.Name, sortedKeys(s.gin))
+		}
+	}
+	for _, spec := range cfg.GRPC {
+		if _, ok := s.grpc[spec.Name]; !ok {
+			return fmt.Errorf("middleware: unknown grpc middleware %q (available: %v)", spec.Name, sortedKeys(s.grpc))
+		}
+	}
+	return nil
+}
+
+// BuildGin 按配置构建 Gin 中间件链
+func (s *Stack) BuildGin(specs []config.MiddlewareSpec) (*GinChain, error) {
+	if err := s.Validate(config.MiddlewareConfig{HTTP: specs}); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	chain := NewGinChain()
+	for _, spec := range specs {
+		if !spec.IsEnabled() {
+			continue
+		}
+		h, err := s.gin[spec.Name](Params(spec.Params))
+		if err != nil {
+			return nil, fmt.Errorf("middleware: build http middleware %q: %w", spec.Name, err)
+		}
+		chain.Use(h)
+	}
+	return chain, nil
+}
+
+// BuildGRPC 按配置构建 gRPC 拦截器链
+func (s *Stack) BuildGRPC(specs []config.MiddlewareSpec) (*GRPCChain, error) {
+	if err := s.Validate(config.MiddlewareConfig{GRPC: specs}); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	chain := NewGRPCChain()
+	for _, spec := range specs {
+		if !spec.IsEnabled() {
+			continue
+		}
+		ic, err := s.grpc[spec.Name](Params(spec.Params))
+		if err != nil {
+			return nil, fmt.Errorf("middleware: build grpc middleware %q: %w", spec.Name, err)
+		}
+		if ic.Unary != nil {
+			chain.UseUnary(ic.Unary)
+		}
+		if ic.Stream != nil {
+			chain.UseStream(ic.Stream)
+		}
+	}
+	return chain, nil
+}
+
+// Build 按配置构建 Gin 与 gRPC 中间件链
+func (s *Stack) Build(cfg config.MiddlewareConfig) (*GinChain, *GRPCChain, error) {
+	if err := s.Validate(cfg); err != nil {
+		return nil, nil, err
+	}
+	ginChain, err := s.BuildGin(cfg.HTTP)
+	if err != nil {
+		return nil, nil, err
+	}
+	grpcChain, err := s.BuildGRPC(cfg.GRPC)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ginChain, grpcChain, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
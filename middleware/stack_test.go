@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+
+	"github.com/mildsunup/higo/config"
+)
+
+func init() { gin.SetMode(gin.TestMode) }
+
+// tagParams tag 中间件参数
+type tagParams struct {
+	Tag     string        `yaml:"tag"`
+	Delay   time.Duration `yaml:"delay"`
+	Methods []string      `yaml:"methods"`
+}
+
+func tagStack(trace *[]string) *Stack {
+	tag := func(p Params) (gin.HandlerFunc, error) {
+		var params tagParams
+		if err := p.Decode(&params); err != nil {
+			return nil, err
+		}
+		return func(c *gin.Context) {
+			*trace = append(*trace, params.Tag)
+			c.Next()
+		}, nil
+	}
+	return NewStack().
+		RegisterGin("first", tag).
+		RegisterGin("second", tag).
+		RegisterGin("broken", func(Params) (gin.HandlerFunc, error) { return nil, errors.New("missing key") })
+}
+
+func TestParams_Decode(t *testing.T) {
+	var got tagParams
+	err := Params{"tag": 7, "delay": "150ms", "methods": "GET,POST"}.Decode(&got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Tag != "7" || got.Delay != 150*time.Millisecond || !slices.Equal(got.Methods, []string{"GET", "POST"}) {
+		t.Fatalf("decoded = %+v", got)
+	}
+
+	// 拼写错误的参数不能被静默忽略
+	if err := (Params{"tga": "x"}).Decode(&got); err == nil || !strings.Contains(err.Error(), "tga") {
+		t.Fatalf("unknown param = %v", err)
+	}
+}
+
+func TestStack_BuildGin(t *testing.T) {
+	var trace []string
+	s := tagStack(&trace)
+	off := false
+
+	chain, err := s.BuildGin([]config.MiddlewareSpec{
+		{Name: "second", Params: map[string]any{"tag": "b"}},
+		{Name: "broken", Enabled: &off},
+		{Name: "first", Params: map[string]any{"tag": "a"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := gin.New()
+	chain.Apply(r)
+	r.GET("/", func(c *gin.Context) { trace = append(trace, "handler") })
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	// 按配置顺序装配，禁用的中间件不调用工厂
+	if want := []string{"b", "a", "handler"}; !slices.Equal(trace, want) {
+		t.Fatalf("trace = %v, want %v", trace, want)
+	}
+}
+
+func TestStack_BuildErrors(t *testing.T) {
+	var trace []string
+	s := tagStack(&trace)
+	off := false
+
+	tests := []struct {
+		name  string
+		specs []config.MiddlewareSpec
+		want  string
+	}{
+		{"unknown", []config.MiddlewareSpec{{Name: "frist"}}, `unknown http middleware "frist" (available: [broken first second])`},
+		{"unknown disabled", []config.MiddlewareSpec{{Name: "frist", Enabled: &off}}, `unknown http middleware "frist"`},
+		{"factory error", []config.MiddlewareSpec{{Name: "broken"}}, `build http middleware "broken": missing key`},
+		{"bad params", []config.MiddlewareSpec{{Name: "first", Params: map[string]any{"delay": "soon"}}}, `build http middleware "first"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain, err := s.BuildGin(tt.specs)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("build = %v, want %q", err, tt.want)
+			}
+			if chain != nil {
+				t.Fatal("chain returned with error")
+			}
+		})
+	}
+}
+
+func TestStack_BuildGRPC(t *testing.T) {
+	var trace []string
+	unary := func(tag string) grpc.UnaryServerInterceptor {
+		return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, h grpc.UnaryHandler) (any, error) {
+			trace = append(trace, tag)
+			return h(ctx, req)
+		}
+	}
+	stream := func(_ any, _ grpc.ServerStream, _ *grpc.StreamServerInfo, _ grpc.StreamHandler) error { return nil }
+
+	s := NewStack().
+		RegisterGRPC("auth", func(Params) (GRPCInterceptors, error) {
+			return GRPCInterceptors{Unary: unary("auth")}, nil
+		}).
+		RegisterGRPC("logging", func(Params) (GRPCInterceptors, error) {
+			return GRPCInterceptors{Unary: unary("logging"), Stream: stream}, nil
+		})
+
+	chain, err := s.BuildGRPC([]config.MiddlewareSpec{{Name: "logging"}, {Name: "auth"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// nil 拦截器不进入链
+	if len(chain.UnaryInterceptors()) != 2 || len(chain.StreamInterceptors()) != 1 {
+		t.Fatalf("unary = %d, stream = %d", len(chain.UnaryInterceptors()), len(chain.StreamInterceptors()))
+	}
+	for _, ic := range chain.UnaryInterceptors() {
+		_, _ = ic(context.Background(), nil, &grpc.UnaryServerInfo{}, func(context.Context, any) (any, error) { return nil, nil })
+	}
+	if !slices.Equal(trace, []string{"logging", "auth"}) {
+		t.Fatalf("trace = %v", trace)
+	}
+}
+
+func TestStack_BuildValidatesBothSidesFirst(t *testing.T) {
+	built := 0
+	s := NewStack().RegisterGin("request_id", func(Params) (gin.HandlerFunc, error) {
+		built++
+		return func(*gin.Context) {}, nil
+	})
+
+	// gRPC 侧的未知名称在构建任何 HTTP 中间件之前报错
+	_, _, err := s.Build(config.MiddlewareConfig{
+		HTTP: []config.MiddlewareSpec{{Name: "request_id"}},
+		GRPC: []config.MiddlewareSpec{{Name: "recovery"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), `unknown grpc middleware "recovery"`) {
+		t.Fatalf("build = %v", err)
+	}
+	if built != 0 {
+		t.Fatalf("http factory called %d times before validation failed", built)
+	}
+}
+
+func TestParams_DecodeReplacesDefaultSlices(t *testing.T) {
+	got := tagParams{Methods: []string{"GET", "POST", "PUT"}}
+	if err := (Params{"methods": "DELETE"}).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got.Methods, []string{"DELETE"}) {
+		t.Fatalf("methods = %v", got.Methods)
+	}
+}
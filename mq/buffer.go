@@ -0,0 +1,224 @@
+package mq
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrBufferFull 本地缓冲已满
+var ErrBufferFull = errors.New("mq: failover buffer full")
+
+// BufferedMessage 缓冲的待重放消息
+type BufferedMessage struct {
+	Topic   string            `json:"topic"`
+	Key     string            `json:"key,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Value   []byte            `json:"value"`
+	Time    time.Time         `json:"time"`
+}
+
+// Buffer 故障转移时的本地消息缓冲
+type Buffer interface {
+	// Append 追加消息
+	Append(msg BufferedMessage) error
+	// Replay 按写入顺序回放消息，fn 返回错误时停止并返回该错误，已成功回放的消息不会再次回放。
+	// 回放期间追加的消息也会被回放，缓冲清空后返回 nil
+	Replay(ctx context.Context, fn func(BufferedMessage) error) error
+	// Len 待回放的消息数
+	Len() int
+}
+
+// DiskBufferOption 磁盘缓冲选项
+type DiskBufferOption func(*DiskBuffer)
+
+// WithBufferMaxBytes 设置缓冲文件上限，默认 1GiB，超出时 Append 返回 ErrBufferFull
+func WithBufferMaxBytes(n int64) DiskBufferOption {
+	return func(b *DiskBuffer) { b.maxBytes = n }
+}
+
+// WithBufferSync 设置每次追加后是否 fsync，默认 true；关闭后吞吐更高，但主机崩溃可能丢失最近的消息
+func WithBufferSync(sync bool) DiskBufferOption {
+	return func(b *DiskBuffer) { b.sync = sync }
+}
+
+// DiskBuffer 基于本地文件的消息缓冲，并发安全。
+// 消息以 JSON 行追加到 buffer.log，回放进度记录在 buffer.offset，进程重启后从上次进度继续回放；
+// 全部回放后截断文件。回放为至少一次语义，崩溃时最后一条消息可能重复发送
+type DiskBuffer struct {
+	mu       sync.Mutex
+	log      *os.File
+	offset   *os.File
+	pos      int64 // 回放进度
+	size     int64 // 文件大小
+	count    int   // 待回放消息数
+	maxBytes int64
+	sync     bool
+}
+
+// NewDiskBuffer 在 dir 下创建或打开磁盘缓冲
+func NewDiskBuffer(dir string, opts ...DiskBufferOption) (*DiskBuffer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("mq: create buffer dir: %w", err)
+	}
+	b := &DiskBuffer{maxBytes: 1 << 30, sync: true}
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	var err error
+	if b.log, err = os.OpenFile(filepath.Join(dir, "buffer.log"), os.O_RDWR|os.O_CREATE, 0o644); err != nil {
+		return nil, fmt.Errorf("mq: open buffer: %w", err)
+	}
+	if b.offset, err = os.OpenFile(filepath.Join(dir, "buffer.offset"), os.O_RDWR|os.O_CREATE, 0o644); err != nil {
+		_ = b.log.Close()
+		return nil, fmt.Errorf("mq: open buffer offset: %w", err)
+	}
+	if err := b.recover(); err != nil {
+		_ = b.Close()
+		return nil, err
+	}
+	return b, nil
+}
+
+// recover 读取回放进度并统计待回放消息，丢弃崩溃时写入不完整的尾部
+func (b *DiskBuffer) recover() error {
+	var buf [8]byte
+	if n, _ := b.offset.ReadAt(buf[:], 0); n == 8 {
+		b.pos = int64(binary.BigEndian.Uint64(buf[:]))
+	}
+	info, err := b.log.Stat()
+	if err != nil {
+		return fmt.Errorf("mq: stat buffer: %w", err)
+	}
+	b.pos = min(b.pos, info.Size())
+
+	r := bufio.NewReader(io.NewSectionReader(b.log, b.pos, info.Size()-b.pos))
+	end := b.pos
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil {
+			break
+		}
+		end += int64(len(line))
+		b.count++
+	}
+	if end != info.Size() {
+		if err := b.log.Truncate(end); err != nil {
+			return fmt.Errorf("mq: truncate buffer: %w", err)
+		}
+	}
+	b.size = end
+	return nil
+}
+
+// Append 实现 Buffer
+func (b *DiskBuffer) Append(msg BufferedMessage) error {
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("mq: encode buffered message: %w", err)
+	}
+	line = append(line, '\n')
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.size+int64(len(line)) > b.maxBytes {
+		return ErrBufferFull
+	}
+	if _, err := b.log.WriteAt(line, b.size); err != nil {
+		return fmt.Errorf("mq: write buffer: %w", err)
+	}
+	if b.sync {
+		if err := b.log.Sync(); err != nil {
+			return fmt.Errorf("mq: sync buffer: %w", err)
+		}
+	}
+	b.size += int64(len(line))
+	b.count++
+	return nil
+}
+
+// Replay 实现 Buffer
+func (b *DiskBuffer) Replay(ctx context.Context, fn func(BufferedMessage) error) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		line, ok, err := b.next()
+		if err != nil || !ok {
+			return err
+		}
+		var msg BufferedMessage
+		if err := json.Unmarshal(bytes.TrimSuffix(line, []byte{'\n'}), &msg); err == nil {
+			if err := fn(msg); err != nil {
+				return err
+			}
+		}
+		if err := b.advance(int64(len(line))); err != nil {
+			return err
+		}
+	}
+}
+
+// next 读取下一条待回放消息，缓冲已清空时截断文件并返回 false
+func (b *DiskBuffer) next() ([]byte, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.pos >= b.size {
+		if b.size > 0 {
+			if err := b.log.Truncate(0); err != nil {
+				return nil, false, fmt.Errorf("mq: truncate buffer: %w", err)
+			}
+			b.pos, b.size, b.count = 0, 0, 0
+			if err := b.savePos(); err != nil {
+				return nil, false, err
+			}
+		}
+		return nil, false, nil
+	}
+	line, err := bufio.NewReader(io.NewSectionReader(b.log, b.pos, b.size-b.pos)).ReadBytes('\n')
+	if err != nil {
+		return nil, false, fmt.Errorf("mq: read buffer: %w", err)
+	}
+	return line, true, nil
+}
+
+func (b *DiskBuffer) advance(n int64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pos += n
+	b.count--
+	return b.savePos()
+}
+
+func (b *DiskBuffer) savePos() error {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(b.pos))
+	if _, err := b.offset.WriteAt(buf[:], 0); err != nil {
+		return fmt.Errorf("mq: save buffer offset: %w", err)
+	}
+	return nil
+}
+
+// Len 实现 Buffer
+func (b *DiskBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.count
+}
+
+// Close 关闭文件，未回放的消息保留到下次打开
+func (b *DiskBuffer) Close() error {
+	return errors.Join(b.log.Close(), b.offset.Close())
+}
+
+var _ Buffer = (*DiskBuffer)(nil)
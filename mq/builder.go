@@ -2,13 +2,18 @@ package mq
 
 import (
 	"go.opentelemetry.io/otel/trace"
+
+	"github.com/mildsunup/higo/observability"
 )
 
 // Builder MQ 客户端构建器
 type Builder struct {
-	client  Client
-	tracer  trace.Tracer
-	metrics *Metrics
+	client   Client
+	tracer   trace.Tracer
+	metrics  *Metrics
+	stats    observability.MetricsProvider
+	mws      []HandlerMiddleware
+	compress *CompressionConfig
 }
 
 // NewBuilder 创建构建器
@@ -28,10 +33,41 @@ func (b *Builder) WithMetrics(m *Metrics) *Builder {
 	return b
 }
 
-// Build 构建最终客户端（装饰器顺序：Traced -> Metriced）
+// WithStatsMetrics 将客户端累计统计发布到指标提供者
+func (b *Builder) WithStatsMetrics(p observability.MetricsProvider) *Builder {
+	b.stats = p
+	return b
+}
+
+// WithMiddleware 为所有订阅添加处理器中间件，执行时位于追踪与指标之外、订阅级中间件之内
+func (b *Builder) WithMiddleware(mws ...HandlerMiddleware) *Builder {
+	b.mws = append(b.mws, mws...)
+	return b
+}
+
+// WithCompression 启用消息压缩与大小限制，订阅时先重组分片、解压，再执行其他中间件
+func (b *Builder) WithCompression(cfg CompressionConfig) *Builder {
+	b.compress = &cfg
+	return b
+}
+
+// Build 构建最终客户端（装饰器顺序：Compressed -> Intercepted -> Traced -> Metriced）
 func (b *Builder) Build() Client {
 	c := b.client
 
+	if b.stats != nil {
+		if sp, ok := c.(interface {
+			SetMetricsProvider(observability.MetricsProvider)
+		}); ok {
+			sp.SetMetricsProvider(b.stats)
+		}
+	}
+	if b.compress != nil {
+		c = NewCompressed(c, *b.compress)
+	}
+	if len(b.mws) > 0 {
+		c = NewIntercepted(c, b.mws...)
+	}
 	if b.tracer != nil {
 		c = NewTraced(c, b.tracer)
 	}
@@ -50,6 +86,12 @@ func Unwrap(c Client) Client {
 			c = v.Unwrap()
 		case *Metriced:
 			c = v.Unwrap()
+		case *Intercepted:
+			c = v.Unwrap()
+		case *Compressed:
+			c = v.Unwrap()
+		case *Failover:
+			c = v.Unwrap()
 		default:
 			return c
 		}
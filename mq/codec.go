@@ -0,0 +1,137 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// HeaderContentType 消息编码类型消息头，PublishTyped 写入
+const HeaderContentType = "content-type"
+
+// Codec 消息编解码器，topic 供需要按主题区分 schema 的实现（如 RegistryCodec）使用
+type Codec interface {
+	// ContentType 编码类型，如 application/json
+	ContentType() string
+	// Encode 编码消息
+	Encode(ctx context.Context, topic string, v any) ([]byte, error)
+	// Decode 解码消息到 v（指针）
+	Decode(ctx context.Context, topic string, data []byte, v any) error
+}
+
+// JSONCodec JSON 编解码器
+type JSONCodec struct{}
+
+func (JSONCodec) ContentType() string { return "application/json" }
+
+func (JSONCodec) Encode(_ context.Context, _ string, v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONCodec) Decode(_ context.Context, _ string, data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// ProtoCodec Protobuf 编解码器，Decode 的目标可以是 proto.Message 或指向它的指针（nil 时自动分配）
+type ProtoCodec struct{}
+
+func (ProtoCodec) ContentType() string { return "application/x-protobuf" }
+
+func (ProtoCodec) Encode(_ context.Context, _ string, v any) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("mq: protobuf codec: %T is not a proto.Message", v)
+	}
+	return proto.Marshal(m)
+}
+
+func (ProtoCodec) Decode(_ context.Context, _ string, data []byte, v any) error {
+	m, err := protoTarget(v)
+	if err != nil {
+		return err
+	}
+	return proto.Unmarshal(data, m)
+}
+
+// protoTarget 取得解码目标：支持 proto.Message 与 *proto.Message（如 TypedHandler 中的 **pb.Order）
+func protoTarget(v any) (proto.Message, error) {
+	if m, ok := v.(proto.Message); ok {
+		return m, nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() && rv.Elem().Kind() == reflect.Pointer {
+		elem := rv.Elem()
+		if elem.IsNil() {
+			elem.Set(reflect.New(elem.Type().Elem()))
+		}
+		if m, ok := elem.Interface().(proto.Message); ok {
+			return m, nil
+		}
+	}
+	return nil, fmt.Errorf("mq: protobuf codec: %T is not a proto.Message", v)
+}
+
+// AvroCodec Avro 编解码器。为避免强制引入 Avro 依赖，序列化委托给调用方提供的函数
+// （如 hamba/avro 的 Marshal/Unmarshal 绑定解析后的 schema）
+//
+//	schema := avro.MustParse(orderSchema)
+//	codec := mq.NewAvroCodec(orderSchema,
+//		func(v any) ([]byte, error) { return avro.Marshal(schema, v) },
+//		func(data []byte, v any) error { return avro.Unmarshal(schema, data, v) },
+//	)
+type AvroCodec struct {
+	schema    string
+	marshal   func(v any) ([]byte, error)
+	unmarshal func(data []byte, v any) error
+}
+
+// NewAvroCodec 创建 Avro 编解码器，schema 为 Avro schema JSON
+func NewAvroCodec(schema string, marshal func(v any) ([]byte, error), unmarshal func(data []byte, v any) error) *AvroCodec {
+	return &AvroCodec{schema: schema, marshal: marshal, unmarshal: unmarshal}
+}
+
+func (c *AvroCodec) ContentType() string { return "application/avro" }
+
+// Schema 返回 Avro schema，RegistryCodec 未指定 schema 时使用
+func (c *AvroCodec) Schema() Schema { return Schema{Type: SchemaAvro, Definition: c.schema} }
+
+func (c *AvroCodec) Encode(_ context.Context, _ string, v any) ([]byte, error) {
+	return c.marshal(v)
+}
+
+func (c *AvroCodec) Decode(_ context.Context, _ string, data []byte, v any) error {
+	return c.unmarshal(data, v)
+}
+
+// ErrDecode 消息解码失败，由 TypedHandler 返回（包装具体错误）
+var ErrDecode = errors.New("mq: decode message")
+
+// PublishTyped 使用 codec 编码 v 后发布，并写入 content-type 消息头
+//
+//	mq.PublishTyped(ctx, client, mq.ProtoCodec{}, "orders", &pb.OrderCreated{Id: id})
+func PublishTyped[T any](ctx context.Context, p Producer, codec Codec, topic string, v T, opts ...PublishOption) (*PublishResult, error) {
+	data, err := codec.Encode(ctx, topic, v)
+	if err != nil {
+		return nil, fmt.Errorf("mq: encode message for %s: %w", topic, err)
+	}
+	opts = append([]PublishOption{WithHeaders(map[string]string{HeaderContentType: codec.ContentType()})}, opts...)
+	return p.Publish(ctx, topic, data, opts...)
+}
+
+// TypedHandler 返回先用 codec 解码为 T 再调用 fn 的处理器，解码失败返回包装 ErrDecode 的错误
+//
+//	client.Subscribe(ctx, "orders", mq.TypedHandler(mq.ProtoCodec{},
+//		func(ctx context.Context, order *pb.OrderCreated, msg *mq.Message) error { ... }))
+func TypedHandler[T any](codec Codec, fn func(ctx context.Context, v T, msg *Message) error) Handler {
+	return func(ctx context.Context, msg *Message) error {
+		var v T
+		if err := codec.Decode(ctx, msg.Topic, msg.Value, &v); err != nil {
+			return fmt.Errorf("%w %s: %w", ErrDecode, msg.ID, err)
+		}
+		return fn(ctx, v, msg)
+	}
+}
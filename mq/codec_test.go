@@ -0,0 +1,218 @@
+package mq_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/mildsunup/higo/mq"
+	"github.com/mildsunup/higo/mq/memory"
+	"github.com/mildsunup/higo/resilience"
+)
+
+type order struct {
+	ID     string `json:"id"`
+	Amount int    `json:"amount"`
+}
+
+func TestPublishTyped_JSON(t *testing.T) {
+	client := memory.New("test")
+	ctx := context.Background()
+	_ = client.Connect(ctx)
+	defer client.Close()
+
+	got := make(chan order, 1)
+	_ = client.Subscribe(ctx, "orders", mq.TypedHandler(mq.JSONCodec{}, func(ctx context.Context, o order, msg *mq.Message) error {
+		if msg.Headers[mq.HeaderContentType] != "application/json" {
+			t.Errorf("unexpected content type: %q", msg.Headers[mq.HeaderContentType])
+		}
+		got <- o
+		return nil
+	}))
+
+	if _, err := mq.PublishTyped(ctx, client, mq.JSONCodec{}, "orders", order{ID: "o-1", Amount: 42}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case o := <-got:
+		if o.ID != "o-1" || o.Amount != 42 {
+			t.Errorf("unexpected order: %+v", o)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("message not received")
+	}
+
+	h := mq.TypedHandler(mq.JSONCodec{}, func(context.Context, order, *mq.Message) error { return nil })
+	if err := h(ctx, &mq.Message{Value: []byte("{")}); !errors.Is(err, mq.ErrDecode) {
+		t.Errorf("expected decode error, got %v", err)
+	}
+}
+
+// fakeRegistry 内存版 Schema Registry
+type fakeRegistry struct {
+	mu       sync.Mutex
+	subjects map[string]int
+	schemas  []map[string]any
+}
+
+func (f *fakeRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+
+	var body map[string]any
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	switch {
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/versions"):
+		subject := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/subjects/"), "/versions")
+		f.schemas = append(f.schemas, body)
+		f.subjects[subject] = len(f.schemas)
+		_ = json.NewEncoder(w).Encode(map[string]int{"id": len(f.schemas)})
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/subjects/"):
+		id, ok := f.subjects[strings.TrimPrefix(r.URL.Path, "/subjects/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]any{"error_code": 40401, "message": "Subject not found"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]int{"id": id})
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/schemas/ids/"):
+		var id int
+		_, _ = fmt.Sscan(strings.TrimPrefix(r.URL.Path, "/schemas/ids/"), &id)
+		if id < 1 || id > len(f.schemas) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(f.schemas[id-1])
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+const stringValueProto = `syntax = "proto3";
+package google.protobuf;
+
+// 包装类型
+message StringValue {
+  string value = 1;
+}
+`
+
+func TestRegistryCodec_Protobuf(t *testing.T) {
+	fake := &fakeRegistry{subjects: map[string]int{}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	reg, err := mq.NewSchemaRegistry(mq.SchemaRegistryConfig{URL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	codec, err := mq.NewRegistryCodec(mq.ProtoCodec{}, reg,
+		mq.WithSchema(mq.Schema{Type: mq.SchemaProtobuf, Definition: stringValueProto}),
+		mq.WithSubjectNameStrategy(mq.TopicRecordNameStrategy),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	data, err := codec.Encode(ctx, "greetings", wrapperspb.String("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data[0] != 0 || data[4] != 1 || data[5] != 0 {
+		t.Fatalf("unexpected wire header: %v", data[:6])
+	}
+	if _, ok := fake.subjects["greetings-google.protobuf.StringValue"]; !ok {
+		t.Fatalf("unexpected subjects: %v", fake.subjects)
+	}
+
+	var got *wrapperspb.StringValue
+	if err := codec.Decode(ctx, "greetings", data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.GetValue() != "hello" {
+		t.Errorf("unexpected value: %q", got.GetValue())
+	}
+	if err := codec.Decode(ctx, "greetings", []byte("plain"), &got); !errors.Is(err, mq.ErrInvalidWireFormat) {
+		t.Errorf("expected wire format error, got %v", err)
+	}
+}
+
+func TestRegistryCodec_LookupOnly(t *testing.T) {
+	fake := &fakeRegistry{subjects: map[string]int{}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	reg, _ := mq.NewSchemaRegistry(mq.SchemaRegistryConfig{URL: srv.URL})
+	schema := `{"type":"record","name":"Order","namespace":"shop","fields":[{"name":"id","type":"string"}]}`
+	avro := mq.NewAvroCodec(schema,
+		func(v any) ([]byte, error) { return json.Marshal(v) },
+		func(data []byte, v any) error { return json.Unmarshal(data, v) },
+	)
+	codec, err := mq.NewRegistryCodec(avro, reg, mq.WithAutoRegister(false), mq.WithSubjectNameStrategy(mq.RecordNameStrategy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if _, err := codec.Encode(ctx, "orders", order{ID: "o-1"}); !mq.IsSchemaNotFound(err) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	if _, err := reg.Register(ctx, "shop.Order", codec.Schema()); err != nil {
+		t.Fatal(err)
+	}
+	data, err := codec.Encode(ctx, "orders", order{ID: "o-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 新客户端按 ID 拉取 schema 解码
+	fresh, _ := mq.NewSchemaRegistry(mq.SchemaRegistryConfig{URL: srv.URL})
+	decoder, _ := mq.NewRegistryCodec(avro, fresh)
+	var got order
+	if err := decoder.Decode(ctx, "orders", data, &got); err != nil || got.ID != "o-1" {
+		t.Fatalf("Decode = %+v, %v", got, err)
+	}
+}
+
+func TestSchemaRegistry_AttemptHeader(t *testing.T) {
+	fake := &fakeRegistry{subjects: map[string]int{}}
+	var mu sync.Mutex
+	var attempts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts = append(attempts, r.Header.Get(resilience.HeaderAttempt))
+		mu.Unlock()
+		fake.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	reg, _ := mq.NewSchemaRegistry(mq.SchemaRegistryConfig{URL: srv.URL})
+	ctx := context.Background()
+	schema := mq.Schema{Type: mq.SchemaJSON, Definition: `{"type":"object"}`}
+	if _, err := reg.Register(ctx, "orders-value", schema); err != nil {
+		t.Fatal(err)
+	}
+
+	// 重试中的请求携带尝试次数，便于服务端区分重试流量；新客户端无缓存，按 ID 发起请求
+	fresh, _ := mq.NewSchemaRegistry(mq.SchemaRegistryConfig{URL: srv.URL})
+	retryCtx := resilience.WithAttempt(ctx, resilience.Attempt{Number: 2, Backoff: time.Second})
+	if _, err := fresh.SchemaByID(retryCtx, 1); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(attempts) != 2 || attempts[0] != "" || attempts[1] != "2" {
+		t.Fatalf("attempt headers = %q", attempts)
+	}
+}
@@ -0,0 +1,400 @@
+package mq
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+)
+
+// 压缩与分片消息头
+const (
+	HeaderContentEncoding = "content-encoding" // 压缩算法
+	HeaderChunkID         = "x-chunk-id"       // 分片所属消息 ID
+	HeaderChunkIndex      = "x-chunk-index"    // 分片序号，从 0 开始
+	HeaderChunkCount      = "x-chunk-count"    // 分片总数
+)
+
+// Compression 压缩算法
+type Compression string
+
+const (
+	CompressionNone   Compression = "none"
+	CompressionGzip   Compression = "gzip"
+	CompressionZstd   Compression = "zstd"
+	CompressionSnappy Compression = "snappy"
+)
+
+// OversizePolicy 超过最大消息大小时的处理方式
+type OversizePolicy int
+
+const (
+	OversizeReject OversizePolicy = iota // 拒绝发布，返回 ErrMessageTooLarge
+	OversizeChunk                        // 拆分为多条消息，消费端重组
+)
+
+var (
+	// ErrMessageTooLarge 消息（压缩后）超过 MaxMessageSize
+	ErrMessageTooLarge = errors.New("mq: message too large")
+	// ErrUnsupportedCompression 未知的压缩算法
+	ErrUnsupportedCompression = errors.New("mq: unsupported compression")
+)
+
+// WithCompression 指定本条消息的压缩算法（CompressionNone 表示不压缩），覆盖 CompressionConfig 的默认算法；
+// 需要客户端经过 Compressed 装饰（Builder.WithCompression）
+func WithCompression(c Compression) PublishOption {
+	return func(o *PublishOptions) { o.Compression = c }
+}
+
+// CompressionConfig 压缩与消息大小配置
+type CompressionConfig struct {
+	Algorithm           Compression    // 默认压缩算法，为空表示默认不压缩
+	MinSize             int            // 小于该大小的消息不压缩，默认 1KB
+	MaxMessageSize      int            // 压缩后的最大消息大小，0 表示不限制（Kafka 默认 1MB）
+	Oversize            OversizePolicy // 超过 MaxMessageSize 时的处理方式
+	ChunkTimeout        time.Duration  // 分片重组超时，超时未收齐的分片被丢弃，默认 1 分钟
+	MaxDecompressedSize int            // 解压后的最大大小，防止解压炸弹，默认 64MB
+}
+
+func (c *CompressionConfig) defaults() {
+	if c.MinSize <= 0 {
+		c.MinSize = 1 << 10
+	}
+	if c.ChunkTimeout <= 0 {
+		c.ChunkTimeout = time.Minute
+	}
+	if c.MaxDecompressedSize <= 0 {
+		c.MaxDecompressedSize = 64 << 20
+	}
+}
+
+var (
+	zstdEncoder = sync.OnceValue(func() *zstd.Encoder {
+		enc, _ := zstd.NewWriter(nil)
+		return enc
+	})
+	zstdDecoder = sync.OnceValue(func() *zstd.Decoder {
+		dec, _ := zstd.NewReader(nil, zstd.WithDecoderConcurrency(0))
+		return dec
+	})
+)
+
+// Compress 按算法压缩数据
+func Compress(c Compression, data []byte) ([]byte, error) {
+	switch c {
+	case "", CompressionNone:
+		return data, nil
+	case CompressionGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case CompressionZstd:
+		return zstdEncoder().EncodeAll(data, nil), nil
+	case CompressionSnappy:
+		return s2.EncodeSnappy(nil, data), nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedCompression, c)
+}
+
+// Decompress 按算法解压数据，解压后超过 maxSize（> 0 时）返回 ErrMessageTooLarge
+func Decompress(c Compression, data []byte, maxSize int) ([]byte, error) {
+	var (
+		out []byte
+		err error
+	)
+	switch c {
+	case "", CompressionNone:
+		return data, nil
+	case CompressionGzip:
+		var r *gzip.Reader
+		if r, err = gzip.NewReader(bytes.NewReader(data)); err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		var src io.Reader = r
+		if maxSize > 0 {
+			src = io.LimitReader(r, int64(maxSize)+1)
+		}
+		out, err = io.ReadAll(src)
+	case CompressionZstd:
+		out, err = zstdDecoder().DecodeAll(data, nil)
+	case CompressionSnappy:
+		var n int
+		if n, err = s2.DecodedLen(data); err == nil {
+			if maxSize > 0 && n > maxSize {
+				return nil, fmt.Errorf("%w: decompressed %d bytes", ErrMessageTooLarge, n)
+			}
+			out, err = s2.Decode(nil, data)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCompression, c)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("mq: decompress %s: %w", c, err)
+	}
+	if maxSize > 0 && len(out) > maxSize {
+		return nil, fmt.Errorf("%w: decompressed more than %d bytes", ErrMessageTooLarge, maxSize)
+	}
+	return out, nil
+}
+
+// DecompressMiddleware 按 content-encoding 消息头透明解压消息，maxSize 为解压后的大小上限（0 表示不限制）。
+// 处理器收到的是消息副本，原消息不变
+func DecompressMiddleware(maxSize int) HandlerMiddleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *Message) error {
+			enc := Compression(msg.Headers[HeaderContentEncoding])
+			if enc == "" || enc == CompressionNone {
+				return next(ctx, msg)
+			}
+			value, err := Decompress(enc, msg.Value, maxSize)
+			if err != nil {
+				return fmt.Errorf("%w %s: %w", ErrDecode, msg.ID, err)
+			}
+			m := *msg
+			m.Value = value
+			m.Headers = maps.Clone(msg.Headers)
+			delete(m.Headers, HeaderContentEncoding)
+			return next(ctx, &m)
+		}
+	}
+}
+
+// chunkBuffer 重组中的分片
+type chunkBuffer struct {
+	parts    [][]byte
+	received int
+	first    time.Time
+}
+
+// reassembler 分片重组
+type reassembler struct {
+	timeout time.Duration
+
+	mu      sync.Mutex
+	pending map[string]*chunkBuffer
+}
+
+// ChunkMiddleware 重组 OversizeChunk 拆分的消息：收齐全部分片后以完整消息调用处理器，
+// 未收齐时直接确认分片（进程重启会丢失已确认的分片），超过 timeout 未收齐的分片被丢弃
+func ChunkMiddleware(timeout time.Duration) HandlerMiddleware {
+	r := &reassembler{timeout: timeout, pending: make(map[string]*chunkBuffer)}
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *Message) error {
+			id := msg.Headers[HeaderChunkID]
+			if id == "" {
+				return next(ctx, msg)
+			}
+			whole, err := r.add(id, msg)
+			if err != nil || whole == nil {
+				return err
+			}
+			return next(ctx, whole)
+		}
+	}
+}
+
+// add 加入分片，收齐时返回完整消息
+func (r *reassembler) add(id string, msg *Message) (*Message, error) {
+	index, err1 := strconv.Atoi(msg.Headers[HeaderChunkIndex])
+	count, err2 := strconv.Atoi(msg.Headers[HeaderChunkCount])
+	if err1 != nil || err2 != nil || count <= 0 || index < 0 || index >= count {
+		return nil, fmt.Errorf("%w %s: invalid chunk headers", ErrDecode, msg.ID)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for k, b := range r.pending {
+		if now.Sub(b.first) > r.timeout {
+			delete(r.pending, k)
+		}
+	}
+
+	b, ok := r.pending[id]
+	if !ok {
+		b = &chunkBuffer{parts: make([][]byte, count), first: now}
+		r.pending[id] = b
+	}
+	if len(b.parts) != count {
+		return nil, fmt.Errorf("%w %s: chunk count mismatch", ErrDecode, msg.ID)
+	}
+	if b.parts[index] == nil {
+		b.parts[index] = msg.Value
+		b.received++
+	}
+	if b.received < count {
+		return nil, nil
+	}
+	delete(r.pending, id)
+
+	m := *msg
+	m.ID = id
+	m.Value = bytes.Join(b.parts, nil)
+	m.Headers = maps.Clone(msg.Headers)
+	delete(m.Headers, HeaderChunkID)
+	delete(m.Headers, HeaderChunkIndex)
+	delete(m.Headers, HeaderChunkCount)
+	return &m, nil
+}
+
+// chunkHeaderReserve 分片消息为消息头预留的大小
+const chunkHeaderReserve = 512
+
+// Compressed 压缩与消息大小装饰器：发布时按配置压缩并写入 content-encoding，检查消息大小（拒绝或分片）；
+// 订阅时透明重组分片并解压
+type Compressed struct {
+	client     Client
+	cfg        CompressionConfig
+	middleware HandlerMiddleware
+}
+
+// NewCompressed 创建压缩装饰器
+func NewCompressed(c Client, cfg CompressionConfig) *Compressed {
+	cfg.defaults()
+	return &Compressed{
+		client:     c,
+		cfg:        cfg,
+		middleware: Chain(ChunkMiddleware(cfg.ChunkTimeout), DecompressMiddleware(cfg.MaxDecompressedSize)),
+	}
+}
+
+func (c *Compressed) Connect(ctx context.Context) error { return c.client.Connect(ctx) }
+func (c *Compressed) Ping(ctx context.Context) error    { return c.client.Ping(ctx) }
+func (c *Compressed) Name() string                      { return c.client.Name() }
+func (c *Compressed) Type() Type                        { return c.client.Type() }
+func (c *Compressed) State() State                      { return c.client.State() }
+func (c *Compressed) Stats() Stats                      { return c.client.Stats() }
+func (c *Compressed) Unsubscribe(topic string) error    { return c.client.Unsubscribe(topic) }
+func (c *Compressed) Close() error                      { return c.client.Close() }
+func (c *Compressed) Unwrap() Client                    { return c.client }
+
+// encode 压缩消息，返回发布的数据与追加的选项
+func (c *Compressed) encode(value []byte, opts []PublishOption) ([]byte, []PublishOption, error) {
+	var o PublishOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	alg := o.Compression
+	if alg == "" && len(value) >= c.cfg.MinSize {
+		alg = c.cfg.Algorithm
+	}
+	if alg == "" || alg == CompressionNone {
+		return value, opts, nil
+	}
+	data, err := Compress(alg, value)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, append(opts, WithHeaders(map[string]string{HeaderContentEncoding: string(alg)})), nil
+}
+
+func (c *Compressed) oversize(data []byte) bool {
+	return c.cfg.MaxMessageSize > 0 && len(data) > c.cfg.MaxMessageSize
+}
+
+func (c *Compressed) Publish(ctx context.Context, topic string, value []byte, opts ...PublishOption) (*PublishResult, error) {
+	data, opts, err := c.encode(value, opts)
+	if err != nil {
+		return nil, err
+	}
+	if !c.oversize(data) {
+		return c.client.Publish(ctx, topic, data, opts...)
+	}
+	if c.cfg.Oversize != OversizeChunk {
+		return nil, fmt.Errorf("%w: %d bytes exceeds %d", ErrMessageTooLarge, len(data), c.cfg.MaxMessageSize)
+	}
+	return c.publishChunks(ctx, topic, data, opts)
+}
+
+// publishChunks 拆分发布，分片共用消息 Key（未设置时使用分片 ID）以落在同一分区、保持顺序
+func (c *Compressed) publishChunks(ctx context.Context, topic string, data []byte, opts []PublishOption) (*PublishResult, error) {
+	size := max(c.cfg.MaxMessageSize-chunkHeaderReserve, 1)
+	count := (len(data) + size - 1) / size
+	id := uuid.NewString()
+
+	var o PublishOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.Key == "" {
+		opts = append(opts, WithKey(id))
+	}
+
+	var result *PublishResult
+	for i := range count {
+		chunk := data[i*size : min((i+1)*size, len(data))]
+		chunkOpts := append(opts[:len(opts):len(opts)], WithHeaders(map[string]string{
+			HeaderChunkID:    id,
+			HeaderChunkIndex: strconv.Itoa(i),
+			HeaderChunkCount: strconv.Itoa(count),
+		}))
+		res, err := c.client.Publish(ctx, topic, chunk, chunkOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("mq: publish chunk %d/%d: %w", i+1, count, err)
+		}
+		result = res
+	}
+	return result, nil
+}
+
+func (c *Compressed) PublishAsync(ctx context.Context, topic string, value []byte, callback func(*PublishResult, error), opts ...PublishOption) {
+	data, opts, err := c.encode(value, opts)
+	if err == nil && c.oversize(data) {
+		if c.cfg.Oversize == OversizeChunk {
+			go func() {
+				result, err := c.publishChunks(ctx, topic, data, opts)
+				if callback != nil {
+					callback(result, err)
+				}
+			}()
+			return
+		}
+		err = fmt.Errorf("%w: %d bytes exceeds %d", ErrMessageTooLarge, len(data), c.cfg.MaxMessageSize)
+	}
+	if err != nil {
+		if callback != nil {
+			callback(nil, err)
+		}
+		return
+	}
+	c.client.PublishAsync(ctx, topic, data, callback, opts...)
+}
+
+func (c *Compressed) Subscribe(ctx context.Context, topic string, handler Handler, opts ...SubscribeOption) error {
+	return c.client.Subscribe(ctx, topic, c.middleware(handler), opts...)
+}
+
+// Flush 底层客户端实现 AsyncFlusher 时等待异步消息确认
+func (c *Compressed) Flush(ctx context.Context) error {
+	if f, ok := c.client.(AsyncFlusher); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
+// Pending 底层客户端未确认的异步消息数
+func (c *Compressed) Pending() int {
+	if f, ok := c.client.(AsyncFlusher); ok {
+		return f.Pending()
+	}
+	return 0
+}
+
+var _ Client = (*Compressed)(nil)
@@ -0,0 +1,132 @@
+package mq
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 死信消息头，转入死信主题时写入，用于排查与回放
+const (
+	HeaderDLQPrefix        = "x-dlq-"
+	HeaderDLQOriginalTopic = "x-dlq-original-topic" // 原主题
+	HeaderDLQMessageID     = "x-dlq-message-id"     // 原消息 ID
+	HeaderDLQGroup         = "x-dlq-group"          // 消费组
+	HeaderDLQError         = "x-dlq-error"          // 最后一次处理错误
+	HeaderDLQDeliveries    = "x-dlq-deliveries"     // 处理次数
+	HeaderDLQFailedAt      = "x-dlq-failed-at"      // 转入死信的时间（RFC 3339）
+)
+
+// DeadLetterOptions 死信配置
+type DeadLetterOptions struct {
+	Topic         string // 死信主题
+	MaxDeliveries int    // 最多处理次数（含首次），达到后转入死信；0 表示沿用 MaxRetries
+}
+
+// WithDeadLetter 处理重试耗尽后将消息连同失败信息（x-dlq-* 消息头）发布到死信主题并确认原消息，
+// maxDeliveries > 0 时覆盖 MaxRetries（处理次数 = maxDeliveries）。
+// 死信发布失败时按未处理消息对待（各实现的失败语义，如 RabbitMQ 重新入队）
+//
+//	client.Subscribe(ctx, "orders", handler,
+//		mq.WithGroup("billing"),
+//		mq.WithDeadLetter("orders.dlq", 5),
+//	)
+func WithDeadLetter(topic string, maxDeliveries int) SubscribeOption {
+	return func(o *SubscribeOptions) {
+		o.DeadLetter = &DeadLetterOptions{Topic: topic, MaxDeliveries: maxDeliveries}
+		if maxDeliveries > 0 {
+			o.MaxRetries = maxDeliveries - 1
+		}
+	}
+}
+
+// deadLetter 将处理失败的消息连同失败信息发布到死信主题
+func deadLetter(ctx context.Context, p Producer, msg *Message, opts SubscribeOptions, cause error, deliveries int) error {
+	headers := make(map[string]string, len(msg.Headers)+6)
+	maps.Copy(headers, msg.Headers)
+	headers[HeaderDLQOriginalTopic] = originalTopic(msg)
+	headers[HeaderDLQMessageID] = msg.ID
+	headers[HeaderDLQGroup] = opts.Group
+	headers[HeaderDLQError] = cause.Error()
+	headers[HeaderDLQDeliveries] = strconv.Itoa(deliveries)
+	headers[HeaderDLQFailedAt] = time.Now().UTC().Format(time.RFC3339)
+
+	pubOpts := []PublishOption{WithHeaders(headers)}
+	if msg.Key != "" {
+		pubOpts = append(pubOpts, WithKey(msg.Key))
+	}
+	if _, err := p.Publish(ctx, opts.DeadLetter.Topic, msg.Value, pubOpts...); err != nil {
+		return fmt.Errorf("mq: dead letter to %s: %w (handler: %v)", opts.DeadLetter.Topic, err, cause)
+	}
+	return nil
+}
+
+// ReplayOption 死信回放选项
+type ReplayOption func(*replayOptions)
+
+type replayOptions struct {
+	topic string
+	group string
+}
+
+// WithReplayTopic 回放到指定主题，默认回放到 x-dlq-original-topic 记录的原主题
+func WithReplayTopic(topic string) ReplayOption {
+	return func(o *replayOptions) { o.topic = topic }
+}
+
+// WithReplayGroup 回放使用的消费组，默认 "dlq-replay"
+func WithReplayGroup(group string) ReplayOption {
+	return func(o *replayOptions) { o.group = group }
+}
+
+// ReplayHandler 返回将死信消息去除 x-dlq-*、x-retry-* 消息头后重新发布到原主题的处理器，
+// 可自行订阅到死信主题以控制并发、消费组等
+func ReplayHandler(p Producer, opts ...ReplayOption) Handler {
+	var o replayOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return func(ctx context.Context, msg *Message) error {
+		topic := o.topic
+		if topic == "" {
+			topic = msg.Headers[HeaderDLQOriginalTopic]
+		}
+		if topic == "" {
+			return fmt.Errorf("mq: replay %s: missing %s header", msg.ID, HeaderDLQOriginalTopic)
+		}
+		headers := make(map[string]string, len(msg.Headers))
+		for k, v := range msg.Headers {
+			if !strings.HasPrefix(k, HeaderDLQPrefix) && !strings.HasPrefix(k, HeaderRetryPrefix) {
+				headers[k] = v
+			}
+		}
+		pubOpts := []PublishOption{WithHeaders(headers)}
+		if msg.Key != "" {
+			pubOpts = append(pubOpts, WithKey(msg.Key))
+		}
+		_, err := p.Publish(ctx, topic, msg.Value, pubOpts...)
+		return err
+	}
+}
+
+// ReplayDLQ 订阅死信主题并将消息回放到原主题，阻塞直到 ctx 结束后取消订阅。
+// 通常在修复消费逻辑后以带超时的 ctx 运行一次（如运维命令）
+//
+//	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+//	defer cancel()
+//	err := mq.ReplayDLQ(ctx, client, "orders.dlq")
+func ReplayDLQ(ctx context.Context, c Client, dlqTopic string, opts ...ReplayOption) error {
+	o := replayOptions{group: "dlq-replay"}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	// 回放成功后才确认，失败的死信留在死信主题；已确认的不会在下次回放时重复发布
+	if err := c.Subscribe(ctx, dlqTopic, ReplayHandler(c, opts...), WithGroup(o.group), WithAutoAck(false)); err != nil {
+		return fmt.Errorf("mq: replay subscribe %s: %w", dlqTopic, err)
+	}
+	<-ctx.Done()
+	return c.Unsubscribe(dlqTopic)
+}
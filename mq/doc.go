@@ -3,12 +3,23 @@
 // 支持的消息队列：
 //   - Kafka
 //   - RabbitMQ
-//   - Memory（内存队列，用于测试）
+//   - RocketMQ（普通、顺序、延迟与事务消息，WithTag/WithTagFilter 按标签过滤）
+//   - Memory（内存队列，用于测试：支持消费组、重新投递、延迟与暂停/恢复，mqtest 提供一致性测试套件）
 //
 // 核心功能：
 //   - 统一的生产者/消费者接口
 //   - 消息发布/订阅、异步处理
 //   - 链路追踪和指标采集
+//   - 累计统计自动发布到 MetricsProvider，Snapshot 提供每秒速率
+//   - 多集群故障转移：Failover 在主集群不可用时发布到备用集群或 DiskBuffer，恢复后回放缓冲
+//   - 重试：Backoff 退避的进程内重试，WithRetryTopics/SubscribeWithRetry 通过重试主题非阻塞重试
+//   - 死信队列：WithDeadLetter 在重试耗尽后将消息连同失败信息转入死信主题，ReplayDLQ 回放到原主题
+//   - 消费中间件：HandlerMiddleware 链（恢复、日志、追踪、指标、超时、幂等去重），按订阅或在 Builder 中统一应用
+//   - Kafka 幂等/事务生产者：WithTransaction 原子提交发布的消息与消费位移，ExactlyOnce 实现消费-转换-生产恰好一次
+//   - Kafka 分区策略：Config.Partitioner 支持 hash、murmur2（与 Java 客户端一致）、sticky、manual 等，WithPartition 按消息指定分区
+//   - 类型化编解码：Codec（JSON、Protobuf、Avro）配合 PublishTyped/TypedHandler，RegistryCodec 对接 Confluent Schema Registry
+//   - 请求-应答：Request/Requester 以关联 ID 与应答主题实现同步调用，ServeReply 在服务端应答
+//   - 压缩与大小限制：WithCompression/Compressed 按 gzip、zstd、snappy 压缩并在消费时透明解压，超过 MaxMessageSize 的消息拒绝或分片发布后重组
 //
 // 使用示例：
 //
@@ -0,0 +1,399 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mildsunup/higo/observability"
+)
+
+// FailoverState 故障转移状态
+type FailoverState int32
+
+const (
+	FailoverPrimary   FailoverState = iota // 发布到主集群
+	FailoverActive                         // 主集群不可用，发布到备用集群或本地缓冲
+	FailoverReplaying                      // 主集群已恢复，正在回放本地缓冲
+)
+
+func (s FailoverState) String() string {
+	switch s {
+	case FailoverPrimary:
+		return "primary"
+	case FailoverActive:
+		return "failover"
+	case FailoverReplaying:
+		return "replaying"
+	}
+	return "unknown"
+}
+
+// failoverMetrics 故障转移指标
+type failoverMetrics struct {
+	events   observability.Counter
+	messages observability.Counter
+	buffered observability.Gauge
+	state    observability.Gauge
+}
+
+// FailoverOption 故障转移选项
+type FailoverOption func(*Failover)
+
+// WithStandby 设置备用集群，主集群不可用时优先发布到备用集群。
+// 备用集群的消息不会回放到主集群，消费者需同时订阅两个集群
+func WithStandby(p Producer) FailoverOption {
+	return func(f *Failover) { f.standby = p }
+}
+
+// WithFailoverBuffer 设置本地缓冲（如 NewDiskBuffer），主集群与备用集群均不可用时写入缓冲，主集群恢复后按顺序回放
+func WithFailoverBuffer(b Buffer) FailoverOption {
+	return func(f *Failover) { f.buffer = b }
+}
+
+// WithFailoverThreshold 设置连续失败多少次后切换到故障转移状态，默认 3。
+// 未切换前失败的消息同样转发到备用集群或缓冲
+func WithFailoverThreshold(n int) FailoverOption {
+	return func(f *Failover) { f.threshold = int32(n) }
+}
+
+// WithProbeInterval 设置故障转移期间探测主集群的间隔，默认 5 秒
+func WithProbeInterval(d time.Duration) FailoverOption {
+	return func(f *Failover) { f.probeInterval = d }
+}
+
+// WithFailoverIf 设置触发故障转移的错误判断，默认除 context 取消与超时外的错误均触发
+func WithFailoverIf(fn func(error) bool) FailoverOption {
+	return func(f *Failover) { f.failoverIf = fn }
+}
+
+// WithFailoverStateHandler 设置状态变更回调
+func WithFailoverStateHandler(fn func(from, to FailoverState)) FailoverOption {
+	return func(f *Failover) { f.onStateChange = fn }
+}
+
+// WithFailoverMetrics 发布故障转移指标：
+// mq_failover_events_total{client,event}（failover/recover/replay_error）、
+// mq_failover_messages_total{client,target}（standby/buffer/replayed/dropped）、
+// mq_failover_buffered{client}、mq_failover_state{client}
+func WithFailoverMetrics(p observability.MetricsProvider) FailoverOption {
+	return func(f *Failover) {
+		f.metrics = &failoverMetrics{
+			events:   p.Counter("mq_failover_events_total", "MQ failover state transitions", "client", "event"),
+			messages: p.Counter("mq_failover_messages_total", "MQ messages routed away from the primary cluster", "client", "target"),
+			buffered: p.Gauge("mq_failover_buffered", "MQ messages waiting in the local failover buffer", "client"),
+			state:    p.Gauge("mq_failover_state", "MQ failover state (0=primary, 1=failover, 2=replaying)", "client"),
+		}
+	}
+}
+
+// Failover 多集群故障转移装饰器。
+//
+// 发布到主集群失败时，消息转发到备用集群（WithStandby），备用集群也失败或未配置时写入本地缓冲（WithFailoverBuffer），
+// 避免 broker 故障导致事件丢失或请求阻塞；连续失败达到阈值后跳过主集群，后台定期探测，
+// 恢复后先按顺序回放本地缓冲，再切回主集群。订阅与健康检查委托给主集群
+type Failover struct {
+	primary Client
+	standby Producer
+	buffer  Buffer
+
+	threshold     int32
+	probeInterval time.Duration
+	failoverIf    func(error) bool
+	onStateChange func(from, to FailoverState)
+	metrics       *failoverMetrics
+
+	mu       sync.RWMutex // 发布持有读锁，回放结束切回主集群时持有写锁
+	state    atomic.Int32
+	failures atomic.Int32
+	probing  atomic.Bool
+
+	async   sync.WaitGroup
+	pending atomic.Int64
+	stop    chan struct{}
+	once    sync.Once
+}
+
+// NewFailover 创建故障转移装饰器，至少需要配置备用集群或本地缓冲之一
+func NewFailover(primary Client, opts ...FailoverOption) *Failover {
+	f := &Failover{
+		primary:       primary,
+		threshold:     3,
+		probeInterval: 5 * time.Second,
+		failoverIf: func(err error) bool {
+			return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+		},
+		stop: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	f.threshold = max(f.threshold, 1)
+	if f.metrics != nil {
+		f.metrics.state.Set(0, primary.Name())
+	}
+	return f
+}
+
+// FailoverState 返回当前状态
+func (f *Failover) FailoverState() FailoverState {
+	return FailoverState(f.state.Load())
+}
+
+// Buffered 返回本地缓冲中待回放的消息数
+func (f *Failover) Buffered() int {
+	if f.buffer == nil {
+		return 0
+	}
+	return f.buffer.Len()
+}
+
+// Connect 连接主集群与备用集群。主集群连接失败且配置了备用集群或缓冲时进入故障转移状态并返回 nil，后台重试连接
+func (f *Failover) Connect(ctx context.Context) error {
+	if c, ok := f.standby.(Client); ok {
+		if err := c.Connect(ctx); err != nil {
+			return fmt.Errorf("mq: connect standby: %w", err)
+		}
+	}
+	err := f.primary.Connect(ctx)
+	if err != nil && (f.standby != nil || f.buffer != nil) {
+		f.transition(FailoverActive)
+		return nil
+	}
+	return err
+}
+
+func (f *Failover) Publish(ctx context.Context, topic string, value []byte, opts ...PublishOption) (*PublishResult, error) {
+	if f.FailoverState() == FailoverPrimary {
+		res, err := f.primary.Publish(ctx, topic, value, opts...)
+		if err == nil {
+			f.failures.Store(0)
+			return res, nil
+		}
+		if !f.failoverIf(err) || (f.standby == nil && f.buffer == nil) {
+			return nil, err
+		}
+		if f.failures.Add(1) >= f.threshold {
+			f.transition(FailoverActive)
+		}
+		return f.fallback(ctx, topic, value, opts, err)
+	}
+	return f.fallback(ctx, topic, value, opts, nil)
+}
+
+// fallback 发布到备用集群，失败或未配置时写入本地缓冲
+func (f *Failover) fallback(ctx context.Context, topic string, value []byte, opts []PublishOption, cause error) (*PublishResult, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	// 切回主集群的写锁可能在读取状态后才释放，此时直接发布到主集群
+	if f.FailoverState() == FailoverPrimary && cause == nil {
+		return f.primary.Publish(ctx, topic, value, opts...)
+	}
+
+	if f.standby != nil {
+		res, err := f.standby.Publish(ctx, topic, value, opts...)
+		if err == nil {
+			f.count("standby")
+			return res, nil
+		}
+		cause = errors.Join(cause, fmt.Errorf("standby: %w", err))
+	}
+	if f.buffer != nil {
+		o := PublishOptions{}
+		for _, opt := range opts {
+			opt(&o)
+		}
+		err := f.buffer.Append(BufferedMessage{Topic: topic, Key: o.Key, Headers: o.Headers, Value: value, Time: time.Now()})
+		if err == nil {
+			f.count("buffer")
+			f.observeBuffered()
+			return &PublishResult{Partition: -1, Offset: -1}, nil
+		}
+		cause = errors.Join(cause, fmt.Errorf("buffer: %w", err))
+	}
+	f.count("dropped")
+	if cause == nil {
+		cause = errors.New("no fallback available")
+	}
+	return nil, fmt.Errorf("mq: failover publish to %s: %w", topic, cause)
+}
+
+// PublishAsync 在后台 goroutine 中执行 Publish 的故障转移逻辑，可通过 Flush 等待完成
+func (f *Failover) PublishAsync(ctx context.Context, topic string, value []byte, callback func(*PublishResult, error), opts ...PublishOption) {
+	f.pending.Add(1)
+	f.async.Go(func() {
+		defer f.pending.Add(-1)
+		res, err := f.Publish(context.WithoutCancel(ctx), topic, value, opts...)
+		if callback != nil {
+			callback(res, err)
+		}
+	})
+}
+
+// Flush 等待异步发布完成，并等待主集群刷出已提交的异步消息
+func (f *Failover) Flush(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		f.async.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if af, ok := f.primary.(AsyncFlusher); ok {
+		return af.Flush(ctx)
+	}
+	return nil
+}
+
+// Pending 尚未完成的异步发布数
+func (f *Failover) Pending() int {
+	n := int(f.pending.Load())
+	if af, ok := f.primary.(AsyncFlusher); ok {
+		n += af.Pending()
+	}
+	return n
+}
+
+func (f *Failover) transition(to FailoverState) {
+	from := FailoverState(f.state.Swap(int32(to)))
+	if from == to {
+		return
+	}
+	if f.metrics != nil {
+		f.metrics.state.Set(float64(to), f.primary.Name())
+		switch to {
+		case FailoverActive:
+			if from == FailoverPrimary {
+				f.metrics.events.Inc(f.primary.Name(), "failover")
+			}
+		case FailoverPrimary:
+			f.metrics.events.Inc(f.primary.Name(), "recover")
+		}
+	}
+	if f.onStateChange != nil {
+		f.onStateChange(from, to)
+	}
+	if to == FailoverActive && f.probing.CompareAndSwap(false, true) {
+		go f.probe()
+	}
+}
+
+// probe 定期探测主集群，恢复后回放缓冲并切回主集群
+func (f *Failover) probe() {
+	defer f.probing.Store(false)
+	ticker := time.NewTicker(f.probeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-f.stop:
+			return
+		case <-ticker.C:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), f.probeInterval)
+		err := f.check(ctx)
+		cancel()
+		if err != nil {
+			continue
+		}
+		if f.recover() {
+			return
+		}
+	}
+}
+
+func (f *Failover) check(ctx context.Context) error {
+	if f.primary.State() != StateConnected {
+		return f.primary.Connect(ctx)
+	}
+	return f.primary.Ping(ctx)
+}
+
+// recover 回放缓冲并切回主集群，回放失败时回到故障转移状态并返回 false
+func (f *Failover) recover() bool {
+	if f.buffer == nil {
+		f.failures.Store(0)
+		f.transition(FailoverPrimary)
+		return true
+	}
+	f.transition(FailoverReplaying)
+	ctx := context.Background()
+	for {
+		err := f.buffer.Replay(ctx, func(m BufferedMessage) error {
+			if _, err := f.primary.Publish(ctx, m.Topic, m.Value, WithKey(m.Key), WithHeaders(maps.Clone(m.Headers))); err != nil {
+				return err
+			}
+			f.count("replayed")
+			f.observeBuffered()
+			return nil
+		})
+		if err != nil {
+			if f.metrics != nil {
+				f.metrics.events.Inc(f.primary.Name(), "replay_error")
+			}
+			f.transition(FailoverActive)
+			return false
+		}
+
+		// 回放期间可能有新消息写入缓冲，确认清空后再切回主集群
+		f.mu.Lock()
+		if f.buffer.Len() == 0 {
+			f.failures.Store(0)
+			f.transition(FailoverPrimary)
+			f.mu.Unlock()
+			f.observeBuffered()
+			return true
+		}
+		f.mu.Unlock()
+	}
+}
+
+func (f *Failover) count(target string) {
+	if f.metrics != nil {
+		f.metrics.messages.Inc(f.primary.Name(), target)
+	}
+}
+
+func (f *Failover) observeBuffered() {
+	if f.metrics != nil && f.buffer != nil {
+		f.metrics.buffered.Set(float64(f.buffer.Len()), f.primary.Name())
+	}
+}
+
+func (f *Failover) Subscribe(ctx context.Context, topic string, handler Handler, opts ...SubscribeOption) error {
+	return f.primary.Subscribe(ctx, topic, handler, opts...)
+}
+
+func (f *Failover) Unsubscribe(topic string) error { return f.primary.Unsubscribe(topic) }
+func (f *Failover) Ping(ctx context.Context) error { return f.primary.Ping(ctx) }
+func (f *Failover) Name() string                   { return f.primary.Name() }
+func (f *Failover) Type() Type                     { return f.primary.Type() }
+func (f *Failover) State() State                   { return f.primary.State() }
+func (f *Failover) Stats() Stats                   { return f.primary.Stats() }
+func (f *Failover) Unwrap() Client                 { return f.primary }
+
+// Close 停止探测并关闭主集群、备用集群与本地缓冲（实现 io.Closer 时）
+func (f *Failover) Close() error {
+	f.once.Do(func() { close(f.stop) })
+	f.async.Wait()
+	errs := []error{f.primary.Close()}
+	if f.standby != nil {
+		errs = append(errs, f.standby.Close())
+	}
+	if c, ok := f.buffer.(interface{ Close() error }); ok {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
+
+var (
+	_ Client       = (*Failover)(nil)
+	_ AsyncFlusher = (*Failover)(nil)
+)
@@ -0,0 +1,208 @@
+package mq_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mildsunup/higo/mq"
+	"github.com/mildsunup/higo/mq/memory"
+)
+
+// flakyClient 可模拟不可用的客户端，记录成功发布的消息
+type flakyClient struct {
+	*memory.Client
+	down atomic.Bool
+
+	mu   sync.Mutex
+	sent []string
+}
+
+func newFlakyClient(name string) *flakyClient {
+	c := &flakyClient{Client: memory.New(name)}
+	_ = c.Connect(context.Background())
+	return c
+}
+
+func (c *flakyClient) Publish(ctx context.Context, topic string, value []byte, opts ...mq.PublishOption) (*mq.PublishResult, error) {
+	if c.down.Load() {
+		return nil, errors.New("broker unavailable")
+	}
+	c.mu.Lock()
+	c.sent = append(c.sent, string(value))
+	c.mu.Unlock()
+	return c.Client.Publish(ctx, topic, value, opts...)
+}
+
+func (c *flakyClient) Ping(ctx context.Context) error {
+	if c.down.Load() {
+		return errors.New("broker unavailable")
+	}
+	return c.Client.Ping(ctx)
+}
+
+func (c *flakyClient) messages() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.sent...)
+}
+
+func TestFailover_Standby(t *testing.T) {
+	primary, standby := newFlakyClient("primary"), newFlakyClient("standby")
+	f := mq.NewFailover(primary, mq.WithStandby(standby), mq.WithFailoverThreshold(2), mq.WithProbeInterval(10*time.Millisecond))
+	defer f.Close()
+	ctx := context.Background()
+
+	primary.down.Store(true)
+	for _, v := range []string{"a", "b"} {
+		if _, err := f.Publish(ctx, "events", []byte(v)); err != nil {
+			t.Fatalf("publish %s: %v", v, err)
+		}
+	}
+	if f.FailoverState() != mq.FailoverActive {
+		t.Fatalf("state = %v, want failover", f.FailoverState())
+	}
+	if got := standby.messages(); len(got) != 2 {
+		t.Fatalf("standby received %v", got)
+	}
+
+	primary.down.Store(false)
+	waitFor(t, func() bool { return f.FailoverState() == mq.FailoverPrimary })
+	if _, err := f.Publish(ctx, "events", []byte("c")); err != nil {
+		t.Fatal(err)
+	}
+	if got := primary.messages(); len(got) != 1 || got[0] != "c" {
+		t.Fatalf("primary received %v", got)
+	}
+}
+
+func TestFailover_BufferReplay(t *testing.T) {
+	primary := newFlakyClient("primary")
+	buf, err := mq.NewDiskBuffer(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var transitions []string
+	var mu sync.Mutex
+	f := mq.NewFailover(primary,
+		mq.WithFailoverBuffer(buf),
+		mq.WithFailoverThreshold(1),
+		mq.WithProbeInterval(10*time.Millisecond),
+		mq.WithFailoverStateHandler(func(from, to mq.FailoverState) {
+			mu.Lock()
+			transitions = append(transitions, to.String())
+			mu.Unlock()
+		}),
+	)
+	defer f.Close()
+	ctx := context.Background()
+
+	primary.down.Store(true)
+	for _, v := range []string{"1", "2", "3"} {
+		res, err := f.Publish(ctx, "events", []byte(v), mq.WithKey("k"))
+		if err != nil {
+			t.Fatalf("publish %s: %v", v, err)
+		}
+		if res.Offset != -1 {
+			t.Fatalf("buffered result = %+v", res)
+		}
+	}
+	if f.Buffered() != 3 {
+		t.Fatalf("buffered = %d", f.Buffered())
+	}
+
+	primary.down.Store(false)
+	waitFor(t, func() bool { return f.FailoverState() == mq.FailoverPrimary })
+	if got := primary.messages(); len(got) != 3 || got[0] != "1" || got[2] != "3" {
+		t.Fatalf("replayed %v", got)
+	}
+	if f.Buffered() != 0 {
+		t.Fatalf("buffered after replay = %d", f.Buffered())
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(transitions) != 3 || transitions[0] != "failover" || transitions[1] != "replaying" || transitions[2] != "primary" {
+		t.Fatalf("transitions = %v", transitions)
+	}
+}
+
+func TestFailover_NoFallback(t *testing.T) {
+	primary := newFlakyClient("primary")
+	f := mq.NewFailover(primary)
+	defer f.Close()
+
+	primary.down.Store(true)
+	if _, err := f.Publish(context.Background(), "events", []byte("x")); err == nil {
+		t.Fatal("expected error without standby or buffer")
+	}
+}
+
+func TestDiskBuffer_Resume(t *testing.T) {
+	dir := t.TempDir()
+	buf, err := mq.NewDiskBuffer(dir, mq.WithBufferSync(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{"a", "b", "c"} {
+		if err := buf.Append(mq.BufferedMessage{Topic: "t", Value: []byte(v)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stop := errors.New("stop")
+	var replayed []string
+	err = buf.Replay(context.Background(), func(m mq.BufferedMessage) error {
+		if len(replayed) == 1 {
+			return stop
+		}
+		replayed = append(replayed, string(m.Value))
+		return nil
+	})
+	if !errors.Is(err, stop) {
+		t.Fatalf("replay err = %v", err)
+	}
+	_ = buf.Close()
+
+	// 重新打开后从上次进度继续
+	buf, err = mq.NewDiskBuffer(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer buf.Close()
+	if buf.Len() != 2 {
+		t.Fatalf("len after reopen = %d", buf.Len())
+	}
+	if err := buf.Replay(context.Background(), func(m mq.BufferedMessage) error {
+		replayed = append(replayed, string(m.Value))
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(replayed) != 3 || replayed[1] != "b" || replayed[2] != "c" || buf.Len() != 0 {
+		t.Fatalf("replayed = %v, len = %d", replayed, buf.Len())
+	}
+
+	if err := (func() error {
+		small, err := mq.NewDiskBuffer(t.TempDir(), mq.WithBufferMaxBytes(10))
+		if err != nil {
+			return err
+		}
+		defer small.Close()
+		return small.Append(mq.BufferedMessage{Topic: "t", Value: []byte("too large")})
+	})(); !errors.Is(err, mq.ErrBufferFull) {
+		t.Fatalf("err = %v, want ErrBufferFull", err)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -2,8 +2,10 @@ package kafka
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/IBM/sarama"
@@ -27,6 +29,21 @@ type Config struct {
 	SASLMechanism   string        `json:"sasl_mechanism" yaml:"sasl_mechanism"`
 	SASLUser        string        `json:"sasl_user" yaml:"sasl_user"`
 	SASLPassword    string        `json:"sasl_password" yaml:"sasl_password"`
+
+	// Idempotent 启用幂等生产者（broker 按 producer id 与序号去重），要求 RequiredAcks 为 -1，开启后自动设置
+	Idempotent bool `json:"idempotent" yaml:"idempotent"`
+	// TransactionalID 事务 ID，设置后启用 WithTransaction（隐含 Idempotent 与 ReadCommitted），
+	// 同一 ID 的多个实例会相互隔离（fencing），多实例部署时需各自唯一（如附加 Pod 名称）
+	TransactionalID string `json:"transactional_id" yaml:"transactional_id"`
+	// ReadCommitted 消费者只读取已提交事务的消息
+	ReadCommitted bool `json:"read_committed" yaml:"read_committed"`
+
+	// Partitioner 分区策略：hash（默认）、murmur2、sticky、roundrobin、random、manual，
+	// 与 Java 生产者共用主题并要求相同 Key 落在相同分区时使用 murmur2 或 sticky；
+	// 任何策略下 mq.WithPartition 指定的分区优先。顺序保证见 PartitionerHash 等常量说明
+	Partitioner string `json:"partitioner" yaml:"partitioner"`
+	// StickyBatchSize sticky 策略下无 Key 消息连续发往同一分区的条数，默认 100
+	StickyBatchSize int `json:"sticky_batch_size" yaml:"sticky_batch_size"`
 }
 
 // Client Kafka 客户端
@@ -34,10 +51,18 @@ type Client struct {
 	*mq.Base
 	config        Config
 	saramaConfig  *sarama.Config
+	client        sarama.Client // 同步生产者与元数据查询共用
 	producer      sarama.SyncProducer
 	asyncProducer sarama.AsyncProducer
 	consumerGroup sarama.ConsumerGroup
 
+	txConfig   *sarama.Config      // 事务生产者配置，未设置 TransactionalID 时为 nil
+	txMu       sync.Mutex          // 事务串行执行
+	txProducer sarama.SyncProducer // 事务生产者
+
+	asyncPending atomic.Int64  // 已提交但尚未得到结果的异步消息数
+	asyncDone    chan struct{} // 异步结果分发协程退出时关闭
+
 	mu          sync.RWMutex
 	handlers    map[string]mq.Handler
 	subscribers map[string]context.CancelFunc
@@ -76,9 +101,27 @@ func New(cfg Config) (*Client, error) {
 	saramaCfg.Producer.Return.Successes = true
 	saramaCfg.Producer.Return.Errors = true
 
+	// 分区策略
+	partitioner, err := newPartitioner(cfg.Partitioner, cfg.StickyBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	saramaCfg.Producer.Partitioner = partitioner
+
+	// 幂等与事务
+	if cfg.Idempotent || cfg.TransactionalID != "" {
+		saramaCfg.Producer.Idempotent = true
+		saramaCfg.Producer.RequiredAcks = sarama.WaitForAll
+		saramaCfg.Producer.Retry.Max = max(cfg.MaxRetries, 1)
+		saramaCfg.Net.MaxOpenRequests = 1
+	}
+
 	// 消费者配置
 	saramaCfg.Consumer.Return.Errors = true
 	saramaCfg.Consumer.Offsets.Initial = sarama.OffsetNewest
+	if cfg.ReadCommitted || cfg.TransactionalID != "" {
+		saramaCfg.Consumer.IsolationLevel = sarama.ReadCommitted
+	}
 
 	// ClientID
 	if cfg.ClientID != "" {
@@ -105,10 +148,19 @@ func New(cfg Config) (*Client, error) {
 		saramaCfg.Net.TLS.Enable = true
 	}
 
+	// 事务生产者使用独立配置，普通生产者不能带 transactional.id
+	var txCfg *sarama.Config
+	if cfg.TransactionalID != "" {
+		cp := *saramaCfg
+		cp.Producer.Transaction.ID = cfg.TransactionalID
+		txCfg = &cp
+	}
+
 	return &Client{
 		Base:         mq.NewBase(name, mq.TypeKafka),
 		config:       cfg,
 		saramaConfig: saramaCfg,
+		txConfig:     txCfg,
 		handlers:     make(map[string]mq.Handler),
 		subscribers:  make(map[string]context.CancelFunc),
 	}, nil
@@ -119,22 +171,49 @@ func (c *Client) Connect(ctx context.Context) error {
 		return fmt.Errorf("kafka: invalid state for connect")
 	}
 
+	client, err := sarama.NewClient(c.config.Brokers, c.saramaConfig)
+	if err != nil {
+		c.SetState(mq.StateDisconnected)
+		return fmt.Errorf("kafka: create client failed: %w", err)
+	}
+
 	// 创建同步生产者
-	producer, err := sarama.NewSyncProducer(c.config.Brokers, c.saramaConfig)
+	producer, err := sarama.NewSyncProducerFromClient(client)
 	if err != nil {
+		_ = client.Close()
 		c.SetState(mq.StateDisconnected)
 		return fmt.Errorf("kafka: create producer failed: %w", err)
 	}
+	c.client = client
 	c.producer = producer
 
 	// 创建异步生产者
 	asyncProducer, err := sarama.NewAsyncProducer(c.config.Brokers, c.saramaConfig)
 	if err != nil {
 		_ = producer.Close()
+		_ = client.Close()
 		c.SetState(mq.StateDisconnected)
 		return fmt.Errorf("kafka: create async producer failed: %w", err)
 	}
 	c.asyncProducer = asyncProducer
+	c.asyncDone = make(chan struct{})
+	go c.dispatchAsync(asyncProducer)
+
+	// 创建事务生产者
+	if c.txConfig != nil {
+		txProducer, err := sarama.NewSyncProducer(c.config.Brokers, c.txConfig)
+		if err != nil {
+			_ = producer.Close()
+			_ = asyncProducer.Close()
+			<-c.asyncDone
+			_ = client.Close()
+			c.SetState(mq.StateDisconnected)
+			return fmt.Errorf("kafka: create transactional producer failed: %w", err)
+		}
+		c.txMu.Lock()
+		c.txProducer = txProducer
+		c.txMu.Unlock()
+	}
 
 	c.SetState(mq.StateConnected)
 	return nil
@@ -160,6 +239,22 @@ func (c *Client) Publish(ctx context.Context, topic string, value []byte, opts .
 		return nil, fmt.Errorf("kafka: producer not initialized")
 	}
 
+	partition, offset, err := c.producer.SendMessage(producerMessage(topic, value, opts))
+	if err != nil {
+		c.IncErrors()
+		return nil, fmt.Errorf("kafka: publish failed: %w", err)
+	}
+
+	c.IncPublished()
+	return &mq.PublishResult{
+		MessageID: fmt.Sprintf("%d-%d", partition, offset),
+		Partition: partition,
+		Offset:    offset,
+	}, nil
+}
+
+// producerMessage 按发布选项构建生产者消息
+func producerMessage(topic string, value []byte, opts []mq.PublishOption) *sarama.ProducerMessage {
 	var options mq.PublishOptions
 	for _, opt := range opts {
 		opt(&options)
@@ -175,7 +270,7 @@ func (c *Client) Publish(ctx context.Context, topic string, value []byte, opts .
 	}
 
 	if options.Partition != nil {
-		msg.Partition = *options.Partition
+		msg.Metadata = &messageMeta{partition: options.Partition}
 	}
 
 	for k, v := range options.Headers {
@@ -184,19 +279,62 @@ func (c *Client) Publish(ctx context.Context, topic string, value []byte, opts .
 			Value: []byte(v),
 		})
 	}
+	return msg
+}
+
+// PublishBatch 批量同步发布，失败消息单独报告
+func (c *Client) PublishBatch(ctx context.Context, topic string, msgs []mq.BatchMessage) []mq.BatchResult {
+	results := make([]mq.BatchResult, len(msgs))
+	if c.producer == nil {
+		for i := range results {
+			results[i].Err = fmt.Errorf("kafka: producer not initialized")
+		}
+		return results
+	}
+
+	pms := make([]*sarama.ProducerMessage, len(msgs))
+	index := make(map[*sarama.ProducerMessage]int, len(msgs))
+	for i, m := range msgs {
+		pm := &sarama.ProducerMessage{Topic: topic, Value: sarama.ByteEncoder(m.Value)}
+		if m.Key != "" {
+			pm.Key = sarama.StringEncoder(m.Key)
+		}
+		for k, v := range m.Headers {
+			pm.Headers = append(pm.Headers, sarama.RecordHeader{Key: []byte(k), Value: []byte(v)})
+		}
+		pms[i] = pm
+		index[pm] = i
+	}
 
-	partition, offset, err := c.producer.SendMessage(msg)
+	err := c.producer.SendMessages(pms)
+	failed := make(map[int]error)
 	if err != nil {
-		c.IncErrors()
-		return nil, fmt.Errorf("kafka: publish failed: %w", err)
+		var perrs sarama.ProducerErrors
+		if errors.As(err, &perrs) {
+			for _, pe := range perrs {
+				failed[index[pe.Msg]] = fmt.Errorf("kafka: publish failed: %w", pe.Err)
+			}
+		} else {
+			for i := range pms {
+				failed[i] = fmt.Errorf("kafka: publish failed: %w", err)
+			}
+		}
 	}
 
-	c.IncPublished()
-	return &mq.PublishResult{
-		MessageID: fmt.Sprintf("%d-%d", partition, offset),
-		Partition: partition,
-		Offset:    offset,
-	}, nil
+	for i, pm := range pms {
+		if ferr, ok := failed[i]; ok {
+			c.IncErrors()
+			results[i].Err = ferr
+			continue
+		}
+		c.IncPublished()
+		results[i].Result = &mq.PublishResult{
+			MessageID: fmt.Sprintf("%d-%d", pm.Partition, pm.Offset),
+			Partition: pm.Partition,
+			Offset:    pm.Offset,
+		}
+	}
+	return results
 }
 
 func (c *Client) PublishAsync(ctx context.Context, topic string, value []byte, callback func(*mq.PublishResult, error), opts ...mq.PublishOption) {
@@ -207,46 +345,78 @@ func (c *Client) PublishAsync(ctx context.Context, topic string, value []byte, c
 		return
 	}
 
-	var options mq.PublishOptions
-	for _, opt := range opts {
-		opt(&options)
+	msg := producerMessage(topic, value, opts)
+	if callback != nil {
+		meta, _ := msg.Metadata.(*messageMeta)
+		if meta == nil {
+			meta = &messageMeta{}
+			msg.Metadata = meta
+		}
+		meta.callback = callback
 	}
 
-	msg := &sarama.ProducerMessage{
-		Topic: topic,
-		Value: sarama.ByteEncoder(value),
+	c.asyncPending.Add(1)
+	select {
+	case c.asyncProducer.Input() <- msg:
+	case <-ctx.Done():
+		c.asyncPending.Add(-1)
+		if callback != nil {
+			callback(nil, ctx.Err())
+		}
 	}
+}
 
-	if options.Key != "" {
-		msg.Key = sarama.StringEncoder(options.Key)
+// dispatchAsync 消费异步生产者的结果并回调，直到生产者关闭
+func (c *Client) dispatchAsync(p sarama.AsyncProducer) {
+	defer close(c.asyncDone)
+	successes, errs := p.Successes(), p.Errors()
+	for successes != nil || errs != nil {
+		select {
+		case msg, ok := <-successes:
+			if !ok {
+				successes = nil
+				continue
+			}
+			c.IncPublished()
+			c.asyncPending.Add(-1)
+			if meta, ok := msg.Metadata.(*messageMeta); ok && meta.callback != nil {
+				meta.callback(&mq.PublishResult{
+					MessageID: fmt.Sprintf("%d-%d", msg.Partition, msg.Offset),
+					Partition: msg.Partition,
+					Offset:    msg.Offset,
+				}, nil)
+			}
+		case perr, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			c.IncErrors()
+			c.asyncPending.Add(-1)
+			if meta, ok := perr.Msg.Metadata.(*messageMeta); ok && meta.callback != nil {
+				meta.callback(nil, perr.Err)
+			}
+		}
 	}
+}
 
-	for k, v := range options.Headers {
-		msg.Headers = append(msg.Headers, sarama.RecordHeader{
-			Key:   []byte(k),
-			Value: []byte(v),
-		})
+// Flush 等待已提交的异步消息得到确认，ctx 到期时返回 ctx 错误，未确认数量见 Pending
+func (c *Client) Flush(ctx context.Context) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for c.asyncPending.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
 	}
+	return nil
+}
 
-	c.asyncProducer.Input() <- msg
-
-	// 异步处理结果
-	if callback != nil {
-		go func() {
-			select {
-			case success := <-c.asyncProducer.Successes():
-				c.IncPublished()
-				callback(&mq.PublishResult{
-					MessageID: fmt.Sprintf("%d-%d", success.Partition, success.Offset),
-					Partition: success.Partition,
-					Offset:    success.Offset,
-				}, nil)
-			case err := <-c.asyncProducer.Errors():
-				c.IncErrors()
-				callback(nil, err.Err)
-			}
-		}()
-	}
+// Pending 已提交但尚未确认的异步消息数
+func (c *Client) Pending() int {
+	return int(c.asyncPending.Load())
 }
 
 func (c *Client) Subscribe(ctx context.Context, topic string, handler mq.Handler, opts ...mq.SubscribeOption) error {
@@ -254,6 +424,7 @@ func (c *Client) Subscribe(ctx context.Context, topic string, handler mq.Handler
 	for _, opt := range opts {
 		opt(&options)
 	}
+	handler = options.Wrap(handler)
 
 	if options.Group == "" {
 		options.Group = c.config.ClientID + "-group"
@@ -336,7 +507,17 @@ func (c *Client) Close() error {
 		if err := c.asyncProducer.Close(); err != nil {
 			errs = append(errs, err)
 		}
+		<-c.asyncDone
+	}
+
+	c.txMu.Lock()
+	if c.txProducer != nil {
+		if err := c.txProducer.Close(); err != nil {
+			errs = append(errs, err)
+		}
+		c.txProducer = nil
 	}
+	c.txMu.Unlock()
 
 	if c.consumerGroup != nil {
 		if err := c.consumerGroup.Close(); err != nil {
@@ -344,6 +525,13 @@ func (c *Client) Close() error {
 		}
 	}
 
+	// 由客户端创建的生产者不会关闭客户端
+	if c.client != nil {
+		if err := c.client.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
 	c.SetState(mq.StateDisconnected)
 
 	if len(errs) > 0 {
@@ -352,6 +540,66 @@ func (c *Client) Close() error {
 	return nil
 }
 
+// fetchReleases Fetch API 最高版本与 Kafka 发行版对应关系（升序）
+var fetchReleases = []struct {
+	fetch   int16
+	release string
+}{
+	{2, "0.10.0"}, {3, "0.10.1"}, {5, "0.11.0"}, {6, "1.0.0"}, {7, "1.1.0"},
+	{8, "2.0.0"}, {10, "2.1.0"}, {11, "2.3.0"}, {12, "2.7.0"}, {13, "3.1.0"}, {15, "3.5.0"},
+}
+
+// ServerVersion 返回 broker 版本
+// Kafka 协议不直接暴露发行版本，这里根据 ApiVersions 中 Fetch API 的最高版本推断下限。
+func (c *Client) ServerVersion(ctx context.Context) (string, error) {
+	if c.client == nil || c.client.Closed() {
+		return "", fmt.Errorf("kafka: not connected")
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	broker := c.client.LeastLoadedBroker()
+	if broker == nil {
+		return "", fmt.Errorf("kafka: no brokers available")
+	}
+
+	// sarama 请求不接受 ctx，超时与取消在外层等待
+	type result struct {
+		res *sarama.ApiVersionsResponse
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		res, err := broker.ApiVersions(&sarama.ApiVersionsRequest{})
+		done <- result{res, err}
+	}()
+	var res *sarama.ApiVersionsResponse
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case r := <-done:
+		if r.err != nil {
+			return "", fmt.Errorf("kafka: api versions: %w", r.err)
+		}
+		res = r.res
+	}
+
+	const fetchKey = 1
+	for _, key := range res.ApiKeys {
+		if key.ApiKey != fetchKey {
+			continue
+		}
+		version := "0.10.0"
+		for _, r := range fetchReleases {
+			if key.MaxVersion >= r.fetch {
+				version = r.release
+			}
+		}
+		return version, nil
+	}
+	return "", fmt.Errorf("kafka: fetch api not advertised")
+}
+
 // consumerGroupHandler 实现 sarama.ConsumerGroupHandler
 type consumerGroupHandler struct {
 	client  *Client
@@ -378,29 +626,18 @@ func (h *consumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession,
 			mqMsg.Headers[string(header.Key)] = string(header.Value)
 		}
 
-		var err error
-		for attempt := 0; attempt <= h.options.MaxRetries; attempt++ {
-			if attempt > 0 {
-				h.client.IncRetries()
-				time.Sleep(h.options.RetryDelay)
-			}
-
-			err = h.handler(session.Context(), mqMsg)
-			if err == nil {
-				break
-			}
-		}
-
-		if err != nil {
-			h.client.IncErrors()
-		} else {
-			h.client.IncConsumed()
-			if h.options.AutoAck {
-				session.MarkMessage(msg, "")
-			}
+		// 成功或已转入死信时提交位移，否则保留以便重新消费。
+		// Kafka 没有投递即确认，AutoAck 的两种取值都在处理成功后提交
+		ctx := withConsumerGroup(session.Context(), h.options.Group)
+		if err := h.client.Deliver(ctx, h.client, h.handler, mqMsg, h.options); err == nil {
+			session.MarkMessage(msg, "")
 		}
 	}
 	return nil
 }
 
-var _ mq.Client = (*Client)(nil)
+var (
+	_ mq.Client          = (*Client)(nil)
+	_ mq.BatchProducer   = (*Client)(nil)
+	_ mq.VersionProvider = (*Client)(nil)
+)
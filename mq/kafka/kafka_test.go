@@ -0,0 +1,172 @@
+package kafka
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"github.com/mildsunup/higo/mq"
+)
+
+func TestClient_ServerVersion(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 1)
+	defer broker.Close()
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()),
+		"ApiVersionsRequest": sarama.NewMockApiVersionsResponse(t).
+			SetApiKeys([]sarama.ApiVersionsResponseKey{{ApiKey: 1, MinVersion: 0, MaxVersion: 12}}),
+	})
+
+	c, err := New(Config{Brokers: []string{broker.Addr()}, RequiredAcks: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, err := c.ServerVersion(ctx); err == nil {
+		t.Fatal("expected error before connect")
+	}
+	if err := c.Connect(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	for range 2 {
+		v, err := c.ServerVersion(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != "2.7.0" {
+			t.Fatalf("version = %q", v)
+		}
+	}
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := c.ServerVersion(cctx); err != context.Canceled {
+		t.Fatalf("cancelled = %v", err)
+	}
+}
+
+func TestClient_FlushAsync(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 1)
+	defer broker.Close()
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("orders", 0, broker.BrokerID()),
+		"ApiVersionsRequest": sarama.NewMockApiVersionsResponse(t),
+		"ProduceRequest":     sarama.NewMockProduceResponse(t),
+	})
+
+	c, err := New(Config{Brokers: []string{broker.Addr()}, RequiredAcks: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := c.Connect(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	var acked atomic.Int32
+	for range 3 {
+		c.PublishAsync(ctx, "orders", []byte("o-1"), func(_ *mq.PublishResult, err error) {
+			if err == nil {
+				acked.Add(1)
+			}
+		})
+	}
+	fctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := c.Flush(fctx); err != nil {
+		t.Fatal(err)
+	}
+	if c.Pending() != 0 || acked.Load() != 3 {
+		t.Fatalf("pending = %d, acked = %d", c.Pending(), acked.Load())
+	}
+}
+
+// partitionLog 单分区日志，按会话提交的位移决定下一次消费的起点
+type partitionLog struct {
+	sarama.ConsumerGroupSession
+	msgs []*sarama.ConsumerMessage
+	next int64
+}
+
+func (p *partitionLog) Context() context.Context { return context.Background() }
+
+func (p *partitionLog) MarkMessage(msg *sarama.ConsumerMessage, _ string) { p.next = msg.Offset + 1 }
+
+// consume 以新一代会话消费提交位移之后的全部消息
+func (p *partitionLog) consume(t *testing.T, h sarama.ConsumerGroupHandler) {
+	t.Helper()
+	ch := make(chan *sarama.ConsumerMessage, len(p.msgs))
+	for _, m := range p.msgs {
+		if m.Offset >= p.next {
+			ch <- m
+		}
+	}
+	close(ch)
+	if err := h.ConsumeClaim(p, &partitionClaim{msgs: ch}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type partitionClaim struct {
+	sarama.ConsumerGroupClaim
+	msgs chan *sarama.ConsumerMessage
+}
+
+func (c *partitionClaim) Messages() <-chan *sarama.ConsumerMessage { return c.msgs }
+
+// countingProducer 按主题统计发布次数
+type countingProducer struct {
+	mq.Producer
+	published map[string]int
+}
+
+func (p *countingProducer) Publish(_ context.Context, topic string, _ []byte, _ ...mq.PublishOption) (*mq.PublishResult, error) {
+	p.published[topic]++
+	return &mq.PublishResult{}, nil
+}
+
+func TestConsumeClaim_ReplayCommitsOffsets(t *testing.T) {
+	c, err := New(Config{Brokers: []string{"127.0.0.1:9092"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dlq := &partitionLog{}
+	for i, topic := range []string{"orders", "payments", "orders"} {
+		dlq.msgs = append(dlq.msgs, &sarama.ConsumerMessage{
+			Topic:   "app.dlq",
+			Offset:  int64(i),
+			Value:   []byte("v"),
+			Headers: []*sarama.RecordHeader{{Key: []byte(mq.HeaderDLQOriginalTopic), Value: []byte(topic)}},
+		})
+	}
+
+	// 与 ReplayDLQ 相同的订阅选项
+	opts := mq.DefaultSubscribeOptions()
+	mq.WithGroup("dlq-replay")(&opts)
+	mq.WithAutoAck(false)(&opts)
+
+	pub := &countingProducer{published: map[string]int{}}
+	h := &consumerGroupHandler{client: c, handler: mq.ReplayHandler(pub), options: opts}
+
+	dlq.consume(t, h)
+	if pub.published["orders"] != 2 || pub.published["payments"] != 1 || dlq.next != 3 {
+		t.Fatalf("first replay: published = %v, next offset = %d", pub.published, dlq.next)
+	}
+
+	// 已回放的死信位移已提交，再次回放不发布任何消息
+	clear(pub.published)
+	dlq.consume(t, h)
+	if len(pub.published) != 0 {
+		t.Fatalf("second replay published %v", pub.published)
+	}
+}
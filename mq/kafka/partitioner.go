@@ -0,0 +1,182 @@
+package kafka
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"math/rand/v2"
+
+	"github.com/IBM/sarama"
+
+	"github.com/mildsunup/higo/mq"
+)
+
+// 分区策略，见 Config.Partitioner
+//
+// 顺序保证：Kafka 只保证同一分区内的顺序。相同 Key 的消息在分区数不变时总是落在同一分区（hash、murmur2、sticky），
+// 因此按 Key 有序；扩容分区会改变 Key 到分区的映射。生产者重试时，多个在途请求（Net.MaxOpenRequests > 1）
+// 可能导致同一分区内乱序，需要严格顺序时启用 Idempotent（或设置 MaxRetries 为 0）。
+// roundrobin、random 与无 Key 消息不保证任何顺序
+const (
+	PartitionerHash       = "hash"       // FNV-1a 哈希 Key，无 Key 时随机（sarama 默认，与 Java 客户端不兼容）
+	PartitionerMurmur2    = "murmur2"    // murmur2 哈希 Key，与 Java 客户端 DefaultPartitioner 分区一致，无 Key 时随机
+	PartitionerSticky     = "sticky"     // 有 Key 同 murmur2，无 Key 时连续 StickyBatchSize 条发往同一分区（对应 Java 的粘性分区）
+	PartitionerRoundRobin = "roundrobin" // 轮询，忽略 Key
+	PartitionerRandom     = "random"     // 随机，忽略 Key
+	PartitionerManual     = "manual"     // 只按 mq.WithPartition 指定的分区发送，未指定时为分区 0
+)
+
+// newPartitioner 按策略创建分区器构造函数，任何策略下 mq.WithPartition 指定的分区优先
+func newPartitioner(strategy string, stickyBatch int) (sarama.PartitionerConstructor, error) {
+	var ctor sarama.PartitionerConstructor
+	switch strategy {
+	case "", PartitionerHash:
+		ctor = sarama.NewHashPartitioner
+	case PartitionerMurmur2:
+		ctor = sarama.NewCustomPartitioner(sarama.WithAbsFirst(), sarama.WithCustomHashFunction(newMurmur2))
+	case PartitionerSticky:
+		if stickyBatch <= 0 {
+			stickyBatch = 100
+		}
+		ctor = func(topic string) sarama.Partitioner {
+			return &stickyPartitioner{
+				keyed: sarama.NewCustomPartitioner(sarama.WithAbsFirst(), sarama.WithCustomHashFunction(newMurmur2))(topic),
+				batch: stickyBatch,
+			}
+		}
+	case PartitionerRoundRobin:
+		ctor = sarama.NewRoundRobinPartitioner
+	case PartitionerRandom:
+		ctor = sarama.NewRandomPartitioner
+	case PartitionerManual:
+		ctor = sarama.NewManualPartitioner
+	default:
+		return nil, fmt.Errorf("kafka: unknown partitioner %q", strategy)
+	}
+	return func(topic string) sarama.Partitioner {
+		return &explicitPartitioner{next: ctor(topic)}
+	}, nil
+}
+
+// messageMeta 生产者消息附带的元数据
+type messageMeta struct {
+	partition *int32                         // mq.WithPartition 指定的分区
+	callback  func(*mq.PublishResult, error) // 异步发布回调
+}
+
+// explicitPartitioner 优先使用消息指定的分区，否则交给策略分区器
+type explicitPartitioner struct {
+	next sarama.Partitioner
+}
+
+func (p *explicitPartitioner) Partition(msg *sarama.ProducerMessage, numPartitions int32) (int32, error) {
+	if meta, ok := msg.Metadata.(*messageMeta); ok && meta.partition != nil {
+		if *meta.partition < 0 || *meta.partition >= numPartitions {
+			return -1, sarama.ErrInvalidPartition
+		}
+		return *meta.partition, nil
+	}
+	return p.next.Partition(msg, numPartitions)
+}
+
+func (p *explicitPartitioner) RequiresConsistency() bool {
+	return p.next.RequiresConsistency()
+}
+
+// MessageRequiresConsistency 指定分区的消息必须在全部分区中选择，不能退化为可用分区
+func (p *explicitPartitioner) MessageRequiresConsistency(msg *sarama.ProducerMessage) bool {
+	if meta, ok := msg.Metadata.(*messageMeta); ok && meta.partition != nil {
+		return true
+	}
+	if d, ok := p.next.(sarama.DynamicConsistencyPartitioner); ok {
+		return d.MessageRequiresConsistency(msg)
+	}
+	return p.next.RequiresConsistency()
+}
+
+// stickyPartitioner 有 Key 时按 murmur2 哈希，无 Key 时粘性分区以减少小批次。
+// sarama 每个主题一个分区器且在单个协程中调用，无需加锁
+type stickyPartitioner struct {
+	keyed     sarama.Partitioner
+	batch     int
+	partition int32
+	sent      int
+}
+
+func (p *stickyPartitioner) Partition(msg *sarama.ProducerMessage, numPartitions int32) (int32, error) {
+	if msg.Key != nil {
+		return p.keyed.Partition(msg, numPartitions)
+	}
+	if p.sent == 0 || p.partition >= numPartitions {
+		p.partition = rand.Int32N(numPartitions)
+		p.sent = 0
+	}
+	if p.sent++; p.sent >= p.batch {
+		p.sent = 0
+	}
+	return p.partition, nil
+}
+
+func (p *stickyPartitioner) RequiresConsistency() bool { return true }
+
+func (p *stickyPartitioner) MessageRequiresConsistency(msg *sarama.ProducerMessage) bool {
+	return msg.Key != nil
+}
+
+// murmur2 Java 客户端（org.apache.kafka.common.utils.Utils.murmur2）使用的 32 位 MurmurHash2
+type murmur2 struct {
+	buf []byte
+}
+
+func newMurmur2() hash.Hash32 { return &murmur2{} }
+
+func (h *murmur2) Write(p []byte) (int, error) {
+	h.buf = append(h.buf, p...)
+	return len(p), nil
+}
+
+func (h *murmur2) Sum(b []byte) []byte {
+	return binary.BigEndian.AppendUint32(b, h.Sum32())
+}
+
+func (h *murmur2) Reset()         { h.buf = h.buf[:0] }
+func (h *murmur2) Size() int      { return 4 }
+func (h *murmur2) BlockSize() int { return 4 }
+
+func (h *murmur2) Sum32() uint32 {
+	const (
+		seed = 0x9747b28c
+		m    = 0x5bd1e995
+		r    = 24
+	)
+	data := h.buf
+	length := len(data)
+	v := uint32(seed) ^ uint32(length)
+
+	for i := 0; i+4 <= length; i += 4 {
+		k := binary.LittleEndian.Uint32(data[i:])
+		k *= m
+		k ^= k >> r
+		k *= m
+		v *= m
+		v ^= k
+	}
+
+	tail := data[length&^3:]
+	switch len(tail) {
+	case 3:
+		v ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		v ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		v ^= uint32(tail[0])
+		v *= m
+	}
+
+	v ^= v >> 13
+	v *= m
+	v ^= v >> 15
+	return v
+}
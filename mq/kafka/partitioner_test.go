@@ -0,0 +1,99 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+func TestMurmur2_JavaCompatible(t *testing.T) {
+	// org.apache.kafka.common.utils.UtilsTest#testMurmur2
+	cases := map[string]int32{
+		"21":                         -973932308,
+		"foobar":                     -790332482,
+		"a-little-bit-long-string":   -985981536,
+		"a-little-bit-longer-string": -1486304829,
+		"lkjh234lh9fiuh90y23oiuhsafujhadof229phr9h19h89h8": -58897971,
+		"abc": 479470107,
+	}
+	h := newMurmur2()
+	for in, want := range cases {
+		h.Reset()
+		_, _ = h.Write([]byte(in))
+		if got := int32(h.Sum32()); got != want {
+			t.Errorf("murmur2(%q) = %d, want %d", in, got, want)
+		}
+	}
+}
+
+func TestPartitioner_KeyOrdering(t *testing.T) {
+	for _, strategy := range []string{PartitionerHash, PartitionerMurmur2, PartitionerSticky} {
+		ctor, err := newPartitioner(strategy, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := ctor("orders")
+		// 相同 Key 总是落在同一分区
+		for i := range 50 {
+			key := sarama.StringEncoder(fmt.Sprintf("order-%d", i%5))
+			first, _ := p.Partition(&sarama.ProducerMessage{Key: key}, 12)
+			for range 20 {
+				if got, _ := p.Partition(&sarama.ProducerMessage{Key: key}, 12); got != first {
+					t.Fatalf("%s: key %s moved from %d to %d", strategy, key, first, got)
+				}
+			}
+		}
+	}
+
+	// murmur2 与 Java DefaultPartitioner 一致：toPositive(murmur2(key)) % n
+	ctor, _ := newPartitioner(PartitionerMurmur2, 0)
+	p := ctor("orders")
+	h := newMurmur2()
+	for _, key := range []string{"21", "foobar", "abc", "a-little-bit-longer-string"} {
+		h.Reset()
+		_, _ = h.Write([]byte(key))
+		want := int32(h.Sum32()&0x7fffffff) % 7
+		if got, _ := p.Partition(&sarama.ProducerMessage{Key: sarama.StringEncoder(key)}, 7); got != want {
+			t.Errorf("murmur2 partition(%q) = %d, want %d", key, got, want)
+		}
+	}
+}
+
+func TestPartitioner_Explicit(t *testing.T) {
+	for _, strategy := range []string{PartitionerHash, PartitionerRoundRobin, PartitionerManual} {
+		ctor, _ := newPartitioner(strategy, 0)
+		p := ctor("orders").(sarama.DynamicConsistencyPartitioner)
+		partition := int32(3)
+		msg := &sarama.ProducerMessage{Key: sarama.StringEncoder("k"), Metadata: &messageMeta{partition: &partition}}
+		if got, err := p.Partition(msg, 8); err != nil || got != 3 {
+			t.Errorf("%s: partition = %d, %v", strategy, got, err)
+		}
+		if !p.MessageRequiresConsistency(msg) {
+			t.Errorf("%s: explicit partition must require consistency", strategy)
+		}
+		if _, err := p.Partition(msg, 2); !errors.Is(err, sarama.ErrInvalidPartition) {
+			t.Errorf("%s: expected invalid partition, got %v", strategy, err)
+		}
+	}
+
+	if _, err := New(Config{Partitioner: "crc"}); err == nil {
+		t.Fatal("expected unknown partitioner error")
+	}
+}
+
+func TestPartitioner_Sticky(t *testing.T) {
+	ctor, _ := newPartitioner(PartitionerSticky, 5)
+	p := ctor("orders")
+	var seq []int32
+	for range 10 {
+		got, _ := p.Partition(&sarama.ProducerMessage{}, 64)
+		seq = append(seq, got)
+	}
+	for i := 1; i < 5; i++ {
+		if seq[i] != seq[0] || seq[5+i] != seq[5] {
+			t.Fatalf("keyless messages should stick within a batch: %v", seq)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/IBM/sarama"
+
+	"github.com/mildsunup/higo/mq"
+)
+
+var (
+	// ErrNotTransactional 未配置 TransactionalID 或客户端未连接
+	ErrNotTransactional = errors.New("kafka: transactional producer not configured")
+	// ErrNoConsumerGroup ctx 中没有消费组（不是在 Subscribe 处理器中调用）
+	ErrNoConsumerGroup = errors.New("kafka: no consumer group in context")
+)
+
+type consumerGroupKey struct{}
+
+func withConsumerGroup(ctx context.Context, group string) context.Context {
+	return context.WithValue(ctx, consumerGroupKey{}, group)
+}
+
+// ConsumerGroup 返回 Subscribe 处理器 ctx 中的消费组
+func ConsumerGroup(ctx context.Context) (string, bool) {
+	group, ok := ctx.Value(consumerGroupKey{}).(string)
+	return group, ok && group != ""
+}
+
+// Tx Kafka 事务，仅在 WithTransaction 回调内有效
+type Tx struct {
+	ctx       context.Context
+	producer  sarama.SyncProducer
+	published int
+}
+
+// Publish 在事务中发布消息，事务提交后对 ReadCommitted 消费者可见
+func (tx *Tx) Publish(topic string, value []byte, opts ...mq.PublishOption) (*mq.PublishResult, error) {
+	partition, offset, err := tx.producer.SendMessage(producerMessage(topic, value, opts))
+	if err != nil {
+		return nil, fmt.Errorf("kafka: publish in transaction failed: %w", err)
+	}
+	tx.published++
+	return &mq.PublishResult{
+		MessageID: fmt.Sprintf("%d-%d", partition, offset),
+		Partition: partition,
+		Offset:    offset,
+	}, nil
+}
+
+// AddConsumed 将已消费消息的位移纳入事务，与事务内发布的消息原子提交。
+// msg 须来自本客户端的 Subscribe，消费组取自传给 WithTransaction 的处理器 ctx
+func (tx *Tx) AddConsumed(msg *mq.Message) error {
+	cm, ok := msg.Raw.(*sarama.ConsumerMessage)
+	if !ok {
+		return fmt.Errorf("kafka: message %s is not a kafka consumer message", msg.ID)
+	}
+	group, ok := ConsumerGroup(tx.ctx)
+	if !ok {
+		return ErrNoConsumerGroup
+	}
+	if err := tx.producer.AddMessageToTxn(cm, group, nil); err != nil {
+		return fmt.Errorf("kafka: add offset to transaction failed: %w", err)
+	}
+	return nil
+}
+
+// WithTransaction 在 Kafka 事务中执行 fn：fn 返回 nil 时提交事务内发布的消息与纳入的消费位移，
+// 返回错误或 panic 时中止事务。事务在客户端内串行执行；需要配置 TransactionalID
+//
+//	err := client.WithTransaction(ctx, func(ctx context.Context, tx *kafka.Tx) error {
+//		if _, err := tx.Publish("payments.settled", data); err != nil {
+//			return err
+//		}
+//		return tx.AddConsumed(msg)
+//	})
+func (c *Client) WithTransaction(ctx context.Context, fn func(ctx context.Context, tx *Tx) error) error {
+	c.txMu.Lock()
+	defer c.txMu.Unlock()
+
+	p := c.txProducer
+	if p == nil {
+		return ErrNotTransactional
+	}
+	if err := p.BeginTxn(); err != nil {
+		return c.txFailed(fmt.Errorf("kafka: begin transaction: %w", err))
+	}
+
+	tx := &Tx{ctx: ctx, producer: p}
+	committed := false
+	defer func() {
+		if !committed {
+			if r := recover(); r != nil {
+				_ = p.AbortTxn()
+				panic(r)
+			}
+		}
+	}()
+
+	if err := fn(ctx, tx); err != nil {
+		if aerr := p.AbortTxn(); aerr != nil {
+			return c.txFailed(errors.Join(err, fmt.Errorf("kafka: abort transaction: %w", aerr)))
+		}
+		return err
+	}
+	committed = true
+	if err := p.CommitTxn(); err != nil {
+		if p.TxnStatus()&sarama.ProducerTxnFlagAbortableError != 0 {
+			_ = p.AbortTxn()
+		}
+		return c.txFailed(fmt.Errorf("kafka: commit transaction: %w", err))
+	}
+	for range tx.published {
+		c.IncPublished()
+	}
+	return nil
+}
+
+// ExactlyOnce 返回消费-转换-生产处理器：在同一事务中执行 fn 并提交消息位移，
+// 配合 ReadCommitted 的下游消费者实现端到端恰好一次。fn 失败时事务中止，消息按订阅的重试与死信处理
+//
+//	client.Subscribe(ctx, "payments", client.ExactlyOnce(func(ctx context.Context, tx *kafka.Tx, msg *mq.Message) error {
+//		_, err := tx.Publish("payments.settled", settle(msg.Value), mq.WithKey(msg.Key))
+//		return err
+//	}), mq.WithGroup("settlement"))
+func (c *Client) ExactlyOnce(fn func(ctx context.Context, tx *Tx, msg *mq.Message) error) mq.Handler {
+	return func(ctx context.Context, msg *mq.Message) error {
+		return c.WithTransaction(ctx, func(ctx context.Context, tx *Tx) error {
+			if err := fn(ctx, tx, msg); err != nil {
+				return err
+			}
+			return tx.AddConsumed(msg)
+		})
+	}
+}
+
+// txFailed 事务生产者进入致命错误状态时重建（沿用同一 TransactionalID，broker 会中止其未完成事务），调用方需持有 txMu
+func (c *Client) txFailed(err error) error {
+	c.IncErrors()
+	if c.txProducer.TxnStatus()&sarama.ProducerTxnFlagFatalError == 0 {
+		return err
+	}
+	_ = c.txProducer.Close()
+	p, perr := sarama.NewSyncProducer(c.config.Brokers, c.txConfig)
+	if perr != nil {
+		c.txProducer = nil
+		return errors.Join(err, fmt.Errorf("kafka: recreate transactional producer: %w", perr))
+	}
+	c.txProducer = p
+	return err
+}
@@ -0,0 +1,36 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+func TestNew_Transactional(t *testing.T) {
+	c, err := New(Config{Brokers: []string{"127.0.0.1:9092"}, RequiredAcks: 1, TransactionalID: "payments-0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.saramaConfig.Validate(); err != nil {
+		t.Fatalf("producer config: %v", err)
+	}
+	if err := c.txConfig.Validate(); err != nil {
+		t.Fatalf("transactional config: %v", err)
+	}
+	if c.saramaConfig.Producer.Transaction.ID != "" || c.txConfig.Producer.Transaction.ID != "payments-0" {
+		t.Fatal("transactional id must only be set on the transactional producer")
+	}
+	if c.saramaConfig.Consumer.IsolationLevel != sarama.ReadCommitted {
+		t.Fatal("expected read committed consumer")
+	}
+
+	plain, _ := New(Config{Brokers: []string{"127.0.0.1:9092"}})
+	if plain.txConfig != nil || plain.saramaConfig.Producer.Idempotent {
+		t.Fatal("unexpected transactional config")
+	}
+	if err := plain.WithTransaction(context.Background(), nil); !errors.Is(err, ErrNotTransactional) {
+		t.Fatalf("WithTransaction = %v", err)
+	}
+}
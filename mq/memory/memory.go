@@ -3,6 +3,7 @@ package memory
 import (
 	"context"
 	"fmt"
+	"maps"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -12,27 +13,56 @@ import (
 	"github.com/mildsunup/higo/mq"
 )
 
-// Client 内存 MQ 客户端（用于测试和开发）
+// Option 内存客户端选项
+type Option func(*Client)
+
+// WithBroker 使用共享的 Broker，多个客户端共享同一 Broker 时可模拟多实例间的消费组，默认每个客户端独占一个 Broker
+func WithBroker(b *Broker) Option {
+	return func(c *Client) { c.broker = b }
+}
+
+// WithRedelivery 处理失败（重试、死信后仍返回错误）的消息在 delay 后重新投递给消费组，
+// 最多 maxRedeliveries 次（0 表示不限），模拟 RabbitMQ Nack 重新入队；默认失败后丢弃，与 Kafka 会话内语义一致
+func WithRedelivery(maxRedeliveries int, delay time.Duration) Option {
+	return func(c *Client) {
+		c.redeliver = true
+		c.maxRedeliveries = maxRedeliveries
+		c.redeliveryDelay = delay
+	}
+}
+
+// Client 内存 MQ 客户端（用于测试和开发）。
+// 同一消费组的订阅竞争消费（每条消息只投递给组内一个订阅），不同消费组各自收到全部消息；
+// 未指定消费组的订阅各自独立收到全部消息。支持 WithDelay 延迟投递与 Pause/Resume
 type Client struct {
 	*mq.Base
-	mu          sync.RWMutex
-	topics      map[string][]chan *mq.Message
-	handlers    map[string]mq.Handler
-	subscribers map[string]context.CancelFunc
-	closed      atomic.Bool
+	broker          *Broker
+	redeliver       bool
+	maxRedeliveries int
+	redeliveryDelay time.Duration
+
+	mu     sync.Mutex
+	subs   map[string][]*subscription // topic -> 本客户端的订阅
+	wg     sync.WaitGroup
+	closed atomic.Bool
 }
 
 // New 创建内存 MQ 客户端
-func New(name string) *Client {
+func New(name string, opts ...Option) *Client {
 	if name == "" {
 		name = "memory"
 	}
-	return &Client{
-		Base:        mq.NewBase(name, mq.TypeMemory),
-		topics:      make(map[string][]chan *mq.Message),
-		handlers:    make(map[string]mq.Handler),
-		subscribers: make(map[string]context.CancelFunc),
+	c := &Client{
+		Base: mq.NewBase(name, mq.TypeMemory),
+		subs: make(map[string][]*subscription),
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.broker == nil {
+		c.broker = NewBroker()
+	}
+	return c
 }
 
 func (c *Client) Connect(ctx context.Context) error {
@@ -69,23 +99,12 @@ func (c *Client) Publish(ctx context.Context, topic string, value []byte, opts .
 		Timestamp: time.Now(),
 	}
 
-	c.mu.RLock()
-	channels := c.topics[topic]
-	c.mu.RUnlock()
-
-	for _, ch := range channels {
-		select {
-		case ch <- msg:
-		default:
-			// 通道满了，跳过
-		}
-	}
-
+	offset := c.broker.publish(msg, options.Delay)
 	c.IncPublished()
 	return &mq.PublishResult{
 		MessageID: msg.ID,
 		Partition: 0,
-		Offset:    c.Stats().Published,
+		Offset:    offset,
 	}, nil
 }
 
@@ -107,72 +126,104 @@ func (c *Client) Subscribe(ctx context.Context, topic string, handler mq.Handler
 	for _, opt := range opts {
 		opt(&options)
 	}
+	handler = options.Wrap(handler)
 
-	ch := make(chan *mq.Message, 100)
-
-	c.mu.Lock()
-	c.topics[topic] = append(c.topics[topic], ch)
-	c.handlers[topic] = handler
-	c.mu.Unlock()
-
+	group := options.Group
+	if group == "" {
+		group = "anonymous." + uuid.NewString()
+	}
 	subCtx, cancel := context.WithCancel(ctx)
+	s := &subscription{
+		topic:  topic,
+		group:  group,
+		queue:  c.broker.join(topic, group),
+		cancel: cancel,
+	}
+	stop := context.AfterFunc(subCtx, s.queue.broadcast)
+
 	c.mu.Lock()
-	c.subscribers[topic] = cancel
+	c.subs[topic] = append(c.subs[topic], s)
 	c.mu.Unlock()
 
 	// 启动消费协程
-	for i := 0; i < options.Concurrency; i++ {
-		go c.consume(subCtx, topic, ch, handler, options)
+	var wg sync.WaitGroup
+	for i := 0; i < max(options.Concurrency, 1); i++ {
+		wg.Add(1)
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			defer wg.Done()
+			c.consume(subCtx, s, handler, options)
+		}()
 	}
+	go func() {
+		wg.Wait()
+		stop()
+		c.broker.leave(topic, group)
+	}()
 
 	return nil
 }
 
-func (c *Client) consume(ctx context.Context, topic string, ch chan *mq.Message, handler mq.Handler, opts mq.SubscribeOptions) {
+func (c *Client) consume(ctx context.Context, s *subscription, handler mq.Handler, opts mq.SubscribeOptions) {
 	for {
-		select {
-		case <-ctx.Done():
+		e, ok := s.queue.pop(ctx, &s.paused)
+		if !ok {
 			return
-		case msg := <-ch:
-			if msg == nil {
-				return
-			}
-
-			var err error
-			for attempt := 0; attempt <= opts.MaxRetries; attempt++ {
-				if attempt > 0 {
-					c.IncRetries()
-					time.Sleep(opts.RetryDelay)
-				}
-
-				err = handler(ctx, msg)
-				if err == nil {
-					break
-				}
-			}
-
-			if err != nil {
-				c.IncErrors()
-			} else {
-				c.IncConsumed()
-			}
 		}
+		msg := e.msg
+		if err := c.Deliver(ctx, c, handler, msg, opts); err != nil {
+			c.requeue(s.queue, e)
+		}
+	}
+}
+
+// requeue 按 WithRedelivery 重新投递失败的消息
+func (c *Client) requeue(q *queue, e *envelope) {
+	if !c.redeliver || (c.maxRedeliveries > 0 && e.redeliveries >= c.maxRedeliveries) {
+		return
 	}
+	e.redeliveries++
+	time.AfterFunc(c.redeliveryDelay, func() { q.push(e) })
+}
+
+// Pause 暂停本客户端对主题的消费，期间消息由同组其他订阅消费或在队列中等待
+func (c *Client) Pause(topic string) error {
+	return c.setPaused(topic, true)
+}
+
+// Resume 恢复本客户端对主题的消费
+func (c *Client) Resume(topic string) error {
+	return c.setPaused(topic, false)
+}
+
+func (c *Client) setPaused(topic string, paused bool) error {
+	c.mu.Lock()
+	subs := c.subs[topic]
+	c.mu.Unlock()
+	if len(subs) == 0 {
+		return fmt.Errorf("memory mq: topic %s not subscribed", topic)
+	}
+	for _, s := range subs {
+		s.paused.Store(paused)
+		s.queue.broadcast()
+	}
+	return nil
 }
 
 func (c *Client) Unsubscribe(topic string) error {
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	subs := c.subs[topic]
+	delete(c.subs, topic)
+	c.mu.Unlock()
 
-	if cancel, ok := c.subscribers[topic]; ok {
-		cancel()
-		delete(c.subscribers, topic)
+	for _, s := range subs {
+		s.cancel()
 	}
-	delete(c.handlers, topic)
-	delete(c.topics, topic)
 	return nil
 }
 
+// Close 取消全部订阅并等待处理中的消息完成
 func (c *Client) Close() error {
 	if !c.closed.CompareAndSwap(false, true) {
 		return nil
@@ -181,16 +232,170 @@ func (c *Client) Close() error {
 	c.SetState(mq.StateDisconnecting)
 
 	c.mu.Lock()
-	for _, cancel := range c.subscribers {
-		cancel()
+	for _, subs := range c.subs {
+		for _, s := range subs {
+			s.cancel()
+		}
 	}
-	c.subscribers = make(map[string]context.CancelFunc)
-	c.handlers = make(map[string]mq.Handler)
-	c.topics = make(map[string][]chan *mq.Message)
+	c.subs = make(map[string][]*subscription)
 	c.mu.Unlock()
+	c.wg.Wait()
 
 	c.SetState(mq.StateDisconnected)
 	return nil
 }
 
-var _ mq.Client = (*Client)(nil)
+// subscription 本客户端的一个订阅
+type subscription struct {
+	topic  string
+	group  string
+	queue  *queue
+	cancel context.CancelFunc
+	paused atomic.Bool
+}
+
+var (
+	_ mq.Client = (*Client)(nil)
+	_ mq.Pauser = (*Client)(nil)
+)
+
+// Broker 内存 Broker，保存主题与消费组队列
+type Broker struct {
+	mu     sync.Mutex
+	topics map[string]*topic
+}
+
+// NewBroker 创建内存 Broker
+func NewBroker() *Broker {
+	return &Broker{topics: make(map[string]*topic)}
+}
+
+// topic 主题的消费组队列，没有消费组时消息被丢弃
+type topic struct {
+	offset int64
+	groups map[string]*group
+}
+
+type group struct {
+	queue   *queue
+	members int
+}
+
+func (b *Broker) topic(name string) *topic {
+	t, ok := b.topics[name]
+	if !ok {
+		t = &topic{groups: make(map[string]*group)}
+		b.topics[name] = t
+	}
+	return t
+}
+
+// publish 分配偏移量，立即或在 delay 后投递到各消费组
+func (b *Broker) publish(msg *mq.Message, delay time.Duration) int64 {
+	b.mu.Lock()
+	t := b.topic(msg.Topic)
+	t.offset++
+	offset := t.offset
+	b.mu.Unlock()
+
+	if delay > 0 {
+		time.AfterFunc(delay, func() { b.dispatch(msg) })
+	} else {
+		b.dispatch(msg)
+	}
+	return offset
+}
+
+// dispatch 为每个消费组投递一份消息副本
+func (b *Broker) dispatch(msg *mq.Message) {
+	b.mu.Lock()
+	queues := make([]*queue, 0, len(b.topics[msg.Topic].groups))
+	for _, g := range b.topics[msg.Topic].groups {
+		queues = append(queues, g.queue)
+	}
+	b.mu.Unlock()
+
+	for _, q := range queues {
+		m := *msg
+		m.Headers = maps.Clone(msg.Headers)
+		q.push(&envelope{msg: &m})
+	}
+}
+
+// join 加入消费组，返回组内共享的队列
+func (b *Broker) join(topicName, groupName string) *queue {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	t := b.topic(topicName)
+	g, ok := t.groups[groupName]
+	if !ok {
+		g = &group{queue: newQueue()}
+		t.groups[groupName] = g
+	}
+	g.members++
+	return g.queue
+}
+
+// leave 离开消费组，最后一个成员离开时丢弃组内未消费的消息
+func (b *Broker) leave(topicName, groupName string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	t := b.topics[topicName]
+	if t == nil {
+		return
+	}
+	if g, ok := t.groups[groupName]; ok {
+		if g.members--; g.members <= 0 {
+			delete(t.groups, groupName)
+		}
+	}
+}
+
+// envelope 队列中的消息
+type envelope struct {
+	msg          *mq.Message
+	redeliveries int
+}
+
+// queue 消费组的无界消息队列，组内订阅竞争消费
+type queue struct {
+	mu    sync.Mutex
+	cond  *sync.Cond
+	items []*envelope
+}
+
+func newQueue() *queue {
+	q := &queue{}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+func (q *queue) push(e *envelope) {
+	q.mu.Lock()
+	q.items = append(q.items, e)
+	q.mu.Unlock()
+	q.cond.Broadcast()
+}
+
+// broadcast 唤醒等待的消费者重新检查暂停与退出状态
+func (q *queue) broadcast() {
+	q.mu.Lock()
+	q.cond.Broadcast()
+	q.mu.Unlock()
+}
+
+// pop 取出下一条消息，暂停时等待，ctx 结束时返回 false
+func (q *queue) pop(ctx context.Context, paused *atomic.Bool) (*envelope, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for ctx.Err() == nil {
+		if !paused.Load() && len(q.items) > 0 {
+			e := q.items[0]
+			q.items[0] = nil
+			q.items = q.items[1:]
+			return e, true
+		}
+		q.cond.Wait()
+	}
+	return nil, false
+}
@@ -0,0 +1,70 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mildsunup/higo/mq"
+	"github.com/mildsunup/higo/mq/memory"
+	"github.com/mildsunup/higo/mq/mqtest"
+)
+
+func TestConformance(t *testing.T) {
+	broker := memory.NewBroker()
+	mqtest.RunClientConformanceTests(t, func(t *testing.T) mq.Client {
+		return memory.New("test", memory.WithBroker(broker))
+	})
+}
+
+func TestConformance_Decorated(t *testing.T) {
+	broker := memory.NewBroker()
+	mqtest.RunClientConformanceTests(t, func(t *testing.T) mq.Client {
+		return mq.NewBuilder(memory.New("test", memory.WithBroker(broker))).
+			WithMiddleware(mq.TimeoutMiddleware(time.Second)).
+			WithCompression(mq.CompressionConfig{Algorithm: mq.CompressionSnappy, MinSize: 1}).
+			Build()
+	})
+}
+
+func TestClient_Redelivery(t *testing.T) {
+	client := memory.New("test", memory.WithRedelivery(2, 10*time.Millisecond))
+	ctx := context.Background()
+	_ = client.Connect(ctx)
+	defer client.Close()
+
+	var deliveries atomic.Int32
+	_ = client.Subscribe(ctx, "orders", func(context.Context, *mq.Message) error {
+		deliveries.Add(1)
+		return errors.New("nack")
+	}, mq.WithMaxRetries(0))
+
+	_, _ = client.Publish(ctx, "orders", []byte("x"))
+	time.Sleep(200 * time.Millisecond)
+	// 首次投递 + 2 次重新投递
+	if n := deliveries.Load(); n != 3 {
+		t.Fatalf("deliveries = %d, want 3", n)
+	}
+}
+
+func TestClient_IsolatedBrokers(t *testing.T) {
+	a, b := memory.New("a"), memory.New("b")
+	ctx := context.Background()
+	_ = a.Connect(ctx)
+	_ = b.Connect(ctx)
+	defer a.Close()
+	defer b.Close()
+
+	var received atomic.Int32
+	_ = b.Subscribe(ctx, "orders", func(context.Context, *mq.Message) error {
+		received.Add(1)
+		return nil
+	})
+	_, _ = a.Publish(ctx, "orders", []byte("x"))
+	time.Sleep(50 * time.Millisecond)
+	if received.Load() != 0 {
+		t.Fatal("clients without a shared broker must be isolated")
+	}
+}
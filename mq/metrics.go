@@ -46,6 +46,22 @@ func (m *Metriced) Unsubscribe(topic string) error    { return m.client.Unsubscr
 func (m *Metriced) Close() error                      { return m.client.Close() }
 func (m *Metriced) Unwrap() Client                    { return m.client }
 
+// Flush 底层客户端实现 AsyncFlusher 时等待异步消息确认
+func (m *Metriced) Flush(ctx context.Context) error {
+	if f, ok := m.client.(AsyncFlusher); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
+// Pending 底层客户端未确认的异步消息数
+func (m *Metriced) Pending() int {
+	if f, ok := m.client.(AsyncFlusher); ok {
+		return f.Pending()
+	}
+	return 0
+}
+
 func (m *Metriced) Publish(ctx context.Context, topic string, value []byte, opts ...PublishOption) (*PublishResult, error) {
 	name, typ := m.client.Name(), string(m.client.Type())
 	start := time.Now()
@@ -83,24 +99,7 @@ func (m *Metriced) PublishAsync(ctx context.Context, topic string, value []byte,
 }
 
 func (m *Metriced) Subscribe(ctx context.Context, topic string, handler Handler, opts ...SubscribeOption) error {
-	name, typ := m.client.Name(), string(m.client.Type())
-
-	wrapped := func(ctx context.Context, msg *Message) error {
-		start := time.Now()
-		err := handler(ctx, msg)
-
-		status := "success"
-		if err != nil {
-			status = "error"
-		}
-
-		m.metrics.MessagesTotal.Inc(name, typ, topic, "consume", status)
-		m.metrics.MessageDuration.Since(start, name, typ, topic, "consume")
-		m.metrics.MessageSize.Observe(float64(len(msg.Value)), name, typ, topic)
-		return err
-	}
-
-	return m.client.Subscribe(ctx, topic, wrapped, opts...)
+	return m.client.Subscribe(ctx, topic, MetricsMiddleware(m.metrics, m.client)(handler), opts...)
 }
 
 // SetConsumerLag 设置消费延迟
@@ -0,0 +1,250 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/mildsunup/higo/idempotent"
+	"github.com/mildsunup/higo/logger"
+)
+
+// ErrHandlerPanic 处理器 panic 时由 RecoveryMiddleware 返回（包装 panic 值）
+var ErrHandlerPanic = errors.New("mq: handler panic")
+
+// HeaderIdempotencyKey 业务幂等键消息头，IdempotentMiddleware 默认优先使用
+const HeaderIdempotencyKey = "x-idempotency-key"
+
+// HandlerMiddleware 消费处理器中间件，与 HTTP 中间件相同：包装下一个处理器并返回新的处理器
+type HandlerMiddleware func(next Handler) Handler
+
+// Chain 组合中间件，第一个为最外层：Chain(a, b)(h) 等价于 a(b(h))
+func Chain(mws ...HandlerMiddleware) HandlerMiddleware {
+	return func(next Handler) Handler {
+		for i := len(mws) - 1; i >= 0; i-- {
+			if mws[i] != nil {
+				next = mws[i](next)
+			}
+		}
+		return next
+	}
+}
+
+// WithMiddleware 为本次订阅添加处理器中间件，按添加顺序由外到内执行，
+// 位于客户端重试与死信之内（每次重试都会经过中间件）
+func WithMiddleware(mws ...HandlerMiddleware) SubscribeOption {
+	return func(o *SubscribeOptions) { o.Middlewares = append(o.Middlewares, mws...) }
+}
+
+// Wrap 以订阅选项中的中间件包装处理器，供各客户端实现 Subscribe 时调用
+func (o SubscribeOptions) Wrap(handler Handler) Handler {
+	if len(o.Middlewares) == 0 {
+		return handler
+	}
+	return Chain(o.Middlewares...)(handler)
+}
+
+// RecoveryMiddleware 将处理器 panic 转为错误（ErrHandlerPanic）并记录堆栈，交由重试与死信处理
+func RecoveryMiddleware(log logger.Logger) HandlerMiddleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *Message) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Error(ctx, "MQ handler panic recovered",
+						logger.Any("panic", r),
+						logger.String("stack", string(debug.Stack())),
+						logger.String("topic", msg.Topic),
+						logger.String("message_id", msg.ID),
+					)
+					err = fmt.Errorf("%w: %v", ErrHandlerPanic, r)
+				}
+			}()
+			return next(ctx, msg)
+		}
+	}
+}
+
+// LoggingMiddleware 记录每条消息的处理结果：成功为 Debug，失败为 Warn
+func LoggingMiddleware(log logger.Logger) HandlerMiddleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *Message) error {
+			start := time.Now()
+			err := next(ctx, msg)
+
+			fields := []logger.Field{
+				logger.String("topic", msg.Topic),
+				logger.String("message_id", msg.ID),
+				logger.Duration("latency", time.Since(start)),
+			}
+			if msg.Key != "" {
+				fields = append(fields, logger.String("key", msg.Key))
+			}
+			if err != nil {
+				log.Warn(ctx, "MQ consume failed", append(fields, logger.Err(err))...)
+			} else {
+				log.Debug(ctx, "MQ consume", fields...)
+			}
+			return err
+		}
+	}
+}
+
+// TracingMiddleware 从消息头提取 trace context 并为每次处理创建 mq.Consume 消费者 span
+func TracingMiddleware(tracer trace.Tracer) HandlerMiddleware {
+	return tracingMiddleware(tracer, propagation.TraceContext{})
+}
+
+func tracingMiddleware(tracer trace.Tracer, propagator propagation.TextMapPropagator, attrs ...attribute.KeyValue) HandlerMiddleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *Message) error {
+			if msg.Headers != nil {
+				ctx = propagator.Extract(ctx, propagation.MapCarrier(msg.Headers))
+			}
+			ctx, span := tracer.Start(ctx, "mq.Consume",
+				trace.WithSpanKind(trace.SpanKindConsumer),
+				trace.WithAttributes(attrs...),
+				trace.WithAttributes(
+					attribute.String("mq.topic", msg.Topic),
+					attribute.String("mq.message_id", msg.ID),
+				),
+			)
+			defer span.End()
+
+			err := next(ctx, msg)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			} else {
+				span.SetStatus(codes.Ok, "")
+			}
+			return err
+		}
+	}
+}
+
+// MetricsMiddleware 记录消费次数、耗时与消息大小（operation=consume），标签 client/type 取自 c
+func MetricsMiddleware(m *Metrics, c Client) HandlerMiddleware {
+	name, typ := c.Name(), string(c.Type())
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *Message) error {
+			start := time.Now()
+			err := next(ctx, msg)
+
+			status := "success"
+			if err != nil {
+				status = "error"
+			}
+			m.MessagesTotal.Inc(name, typ, msg.Topic, "consume", status)
+			m.MessageDuration.Since(start, name, typ, msg.Topic, "consume")
+			m.MessageSize.Observe(float64(len(msg.Value)), name, typ, msg.Topic)
+			return err
+		}
+	}
+}
+
+// TimeoutMiddleware 限制单次处理时长，超时后处理器收到取消的 ctx
+func TimeoutMiddleware(d time.Duration) HandlerMiddleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *Message) error {
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+			return next(ctx, msg)
+		}
+	}
+}
+
+// IdempotentMiddleware 消费去重：按 keyFn 返回的键记录已处理消息，重复投递直接确认；
+// 处理失败会清除记录以便重试。keyFn 为 nil 时使用 DefaultIdempotencyKey，返回空键的消息不去重
+//
+//	dedupe := idempotent.New(cache.NewRedis(redisCfg), idempotent.Config{TTL: 24 * time.Hour})
+//	client.Subscribe(ctx, "orders", handler, mq.WithMiddleware(mq.IdempotentMiddleware(dedupe, nil)))
+func IdempotentMiddleware(h *idempotent.Handler, keyFn func(*Message) string) HandlerMiddleware {
+	if keyFn == nil {
+		keyFn = DefaultIdempotencyKey
+	}
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *Message) error {
+			key := keyFn(msg)
+			if key == "" {
+				return next(ctx, msg)
+			}
+			_, err := h.Execute(ctx, key, "", func() ([]byte, error) {
+				return nil, next(ctx, msg)
+			})
+			if errors.Is(err, idempotent.ErrDuplicateRequest) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// DefaultIdempotencyKey 默认幂等键："mq:<topic>:" 加 x-idempotency-key 消息头，缺省时使用消息 ID
+func DefaultIdempotencyKey(msg *Message) string {
+	id := msg.Headers[HeaderIdempotencyKey]
+	if id == "" {
+		id = msg.ID
+	}
+	if id == "" {
+		return ""
+	}
+	return "mq:" + msg.Topic + ":" + id
+}
+
+// Intercepted 中间件装饰器：为所有订阅应用同一组处理器中间件（位于 WithMiddleware 订阅级中间件之内）
+type Intercepted struct {
+	client      Client
+	middlewares HandlerMiddleware
+}
+
+// NewIntercepted 创建中间件装饰器
+func NewIntercepted(c Client, mws ...HandlerMiddleware) *Intercepted {
+	return &Intercepted{client: c, middlewares: Chain(mws...)}
+}
+
+func (i *Intercepted) Connect(ctx context.Context) error { return i.client.Connect(ctx) }
+func (i *Intercepted) Ping(ctx context.Context) error    { return i.client.Ping(ctx) }
+func (i *Intercepted) Name() string                      { return i.client.Name() }
+func (i *Intercepted) Type() Type                        { return i.client.Type() }
+func (i *Intercepted) State() State                      { return i.client.State() }
+func (i *Intercepted) Stats() Stats                      { return i.client.Stats() }
+func (i *Intercepted) Unsubscribe(topic string) error    { return i.client.Unsubscribe(topic) }
+func (i *Intercepted) Close() error                      { return i.client.Close() }
+func (i *Intercepted) Unwrap() Client                    { return i.client }
+
+func (i *Intercepted) Publish(ctx context.Context, topic string, value []byte, opts ...PublishOption) (*PublishResult, error) {
+	return i.client.Publish(ctx, topic, value, opts...)
+}
+
+func (i *Intercepted) PublishAsync(ctx context.Context, topic string, value []byte, callback func(*PublishResult, error), opts ...PublishOption) {
+	i.client.PublishAsync(ctx, topic, value, callback, opts...)
+}
+
+func (i *Intercepted) Subscribe(ctx context.Context, topic string, handler Handler, opts ...SubscribeOption) error {
+	return i.client.Subscribe(ctx, topic, i.middlewares(handler), opts...)
+}
+
+// Flush 底层客户端实现 AsyncFlusher 时等待异步消息确认
+func (i *Intercepted) Flush(ctx context.Context) error {
+	if f, ok := i.client.(AsyncFlusher); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
+// Pending 底层客户端未确认的异步消息数
+func (i *Intercepted) Pending() int {
+	if f, ok := i.client.(AsyncFlusher); ok {
+		return f.Pending()
+	}
+	return 0
+}
+
+var _ Client = (*Intercepted)(nil)
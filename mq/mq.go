@@ -3,6 +3,7 @@ package mq
 import (
 	"context"
 	"sync/atomic"
+	"time"
 )
 
 // Producer 生产者接口
@@ -15,6 +16,35 @@ type Producer interface {
 	Close() error
 }
 
+// BatchProducer 支持批量发布的生产者（可选实现）
+type BatchProducer interface {
+	// PublishBatch 批量发布到同一主题，返回逐条结果
+	PublishBatch(ctx context.Context, topic string, msgs []BatchMessage) []BatchResult
+}
+
+// AsyncFlusher 支持刷出异步发布消息的客户端（可选实现），满足 runtime.Flusher，
+// 可通过 app.AddFlusher 在停止阶段等待已提交的异步消息确认
+type AsyncFlusher interface {
+	// Flush 等待已提交的异步消息得到确认
+	Flush(ctx context.Context) error
+	// Pending 已提交但尚未确认的异步消息数
+	Pending() int
+}
+
+// VersionProvider 服务端版本提供者（可选实现）
+type VersionProvider interface {
+	// ServerVersion 返回 broker 版本
+	ServerVersion(ctx context.Context) (string, error)
+}
+
+// Pauser 支持暂停/恢复消费的客户端（可选实现），暂停期间不再取出新消息，处理中的消息不受影响
+type Pauser interface {
+	// Pause 暂停主题的消费
+	Pause(topic string) error
+	// Resume 恢复主题的消费
+	Resume(topic string) error
+}
+
 // Consumer 消费者接口
 type Consumer interface {
 	// Subscribe 订阅主题
@@ -45,30 +75,45 @@ type Client interface {
 
 // Base 客户端基类
 type Base struct {
-	name  string
-	typ   Type
-	state atomic.Int32
-	stats Stats
+	name     string
+	typ      Type
+	state    atomic.Int32
+	stats    Stats
+	counters atomic.Pointer[statsCounters]
+	sampler  sampler
 }
 
 // NewBase 创建基类
 func NewBase(name string, typ Type) *Base {
 	b := &Base{name: name, typ: typ}
 	b.state.Store(int32(StateDisconnected))
+	b.sampler.lastAt = time.Now()
 	return b
 }
 
 func (b *Base) Name() string { return b.name }
 func (b *Base) Type() Type   { return b.typ }
 func (b *Base) State() State { return State(b.state.Load()) }
-func (b *Base) Stats() Stats { return b.stats }
 
-func (b *Base) SetState(s State)                           { b.state.Store(int32(s)) }
-func (b *Base) CompareAndSwapState(old, new State) bool    { return b.state.CompareAndSwap(int32(old), int32(new)) }
-func (b *Base) IncPublished()                              { atomic.AddInt64(&b.stats.Published, 1) }
-func (b *Base) IncConsumed()                               { atomic.AddInt64(&b.stats.Consumed, 1) }
-func (b *Base) IncErrors()                                 { atomic.AddInt64(&b.stats.Errors, 1) }
-func (b *Base) IncRetries()                                { atomic.AddInt64(&b.stats.Retries, 1) }
+// Stats 返回累计统计
+func (b *Base) Stats() Stats {
+	return Stats{
+		Published:     atomic.LoadInt64(&b.stats.Published),
+		Consumed:      atomic.LoadInt64(&b.stats.Consumed),
+		Errors:        atomic.LoadInt64(&b.stats.Errors),
+		Retries:       atomic.LoadInt64(&b.stats.Retries),
+		DeadLettered:  atomic.LoadInt64(&b.stats.DeadLettered),
+		PendingCount:  atomic.LoadInt64(&b.stats.PendingCount),
+		ConsumerCount: b.stats.ConsumerCount,
+	}
+}
+
+func (b *Base) SetState(s State)                        { b.state.Store(int32(s)) }
+func (b *Base) CompareAndSwapState(old, new State) bool { return b.state.CompareAndSwap(int32(old), int32(new)) }
+func (b *Base) IncPublished()                           { b.incr(&b.stats.Published, eventPublished) }
+func (b *Base) IncConsumed()                            { b.incr(&b.stats.Consumed, eventConsumed) }
+func (b *Base) IncErrors()                              { b.incr(&b.stats.Errors, eventErrors) }
+func (b *Base) IncRetries()                             { b.incr(&b.stats.Retries, eventRetries) }
 
 // Manager MQ 管理器接口
 type Manager interface {
@@ -2,12 +2,18 @@ package mq_test
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/mildsunup/higo/logger"
 	"github.com/mildsunup/higo/mq"
 	"github.com/mildsunup/higo/mq/memory"
+	"github.com/mildsunup/higo/resilience"
 )
 
 func TestMemoryClient_PublishSubscribe(t *testing.T) {
@@ -181,3 +187,446 @@ func TestBuilder(t *testing.T) {
 		t.Error("unwrap should return original client")
 	}
 }
+
+func TestBase_Snapshot(t *testing.T) {
+	b := mq.NewBase("test", mq.TypeMemory)
+	b.Snapshot()
+
+	for i := 0; i < 10; i++ {
+		b.IncPublished()
+	}
+	b.IncErrors()
+	time.Sleep(10 * time.Millisecond)
+
+	snap := b.Snapshot()
+	if snap.Published != 10 || snap.Errors != 1 {
+		t.Errorf("unexpected totals: %+v", snap.Stats)
+	}
+	if snap.PublishRate <= 0 || snap.PublishRate < snap.ErrorRate {
+		t.Errorf("unexpected rates: publish=%f error=%f", snap.PublishRate, snap.ErrorRate)
+	}
+
+	if next := b.Snapshot(); next.PublishRate != 0 {
+		t.Errorf("expected zero rate without new events, got %f", next.PublishRate)
+	}
+}
+
+func TestMemoryClient_DeadLetter(t *testing.T) {
+	client := memory.New("test")
+	ctx := context.Background()
+	_ = client.Connect(ctx)
+	defer client.Close()
+
+	var attempts atomic.Int32
+	failing := func(ctx context.Context, msg *mq.Message) error {
+		attempts.Add(1)
+		return errors.New("boom")
+	}
+	dlq := make(chan *mq.Message, 1)
+	_ = client.Subscribe(ctx, "orders.dlq", func(ctx context.Context, msg *mq.Message) error {
+		dlq <- msg
+		return nil
+	})
+	_ = client.Subscribe(ctx, "orders", failing,
+		mq.WithGroup("billing"),
+		mq.WithRetryDelay(time.Millisecond),
+		mq.WithDeadLetter("orders.dlq", 2),
+	)
+
+	res, _ := client.Publish(ctx, "orders", []byte("o-1"), mq.WithKey("k1"), mq.WithHeaders(map[string]string{"tenant": "acme"}))
+
+	var msg *mq.Message
+	select {
+	case msg = <-dlq:
+	case <-time.After(time.Second):
+		t.Fatal("message not dead-lettered")
+	}
+	if attempts.Load() != 2 {
+		t.Errorf("expected 2 deliveries, got %d", attempts.Load())
+	}
+	h := msg.Headers
+	if string(msg.Value) != "o-1" || msg.Key != "k1" || h["tenant"] != "acme" {
+		t.Errorf("unexpected dead letter: %+v", msg)
+	}
+	if h[mq.HeaderDLQOriginalTopic] != "orders" || h[mq.HeaderDLQMessageID] != res.MessageID ||
+		h[mq.HeaderDLQGroup] != "billing" || h[mq.HeaderDLQError] != "boom" || h[mq.HeaderDLQDeliveries] != "2" {
+		t.Errorf("unexpected dead letter headers: %v", h)
+	}
+	if s := client.Stats(); s.DeadLettered != 1 || s.Errors != 1 || s.Retries != 1 {
+		t.Errorf("unexpected stats: %+v", s)
+	}
+}
+
+func TestReplayDLQ(t *testing.T) {
+	client := memory.New("test")
+	ctx := context.Background()
+	_ = client.Connect(ctx)
+	defer client.Close()
+
+	replayed := make(chan *mq.Message, 1)
+	_ = client.Subscribe(ctx, "orders", func(ctx context.Context, msg *mq.Message) error {
+		replayed <- msg
+		return nil
+	})
+
+	replayCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() { done <- mq.ReplayDLQ(replayCtx, client, "orders.dlq") }()
+	time.Sleep(20 * time.Millisecond)
+
+	_, _ = client.Publish(ctx, "orders.dlq", []byte("o-1"), mq.WithHeaders(map[string]string{
+		"tenant":                  "acme",
+		mq.HeaderDLQOriginalTopic: "orders",
+		mq.HeaderDLQError:         "boom",
+	}))
+
+	select {
+	case msg := <-replayed:
+		if string(msg.Value) != "o-1" || msg.Headers["tenant"] != "acme" || msg.Headers[mq.HeaderDLQError] != "" {
+			t.Errorf("unexpected replayed message: %+v", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("message not replayed")
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("replay: %v", err)
+	}
+}
+
+func TestChain_Order(t *testing.T) {
+	var trace []string
+	mw := func(name string) mq.HandlerMiddleware {
+		return func(next mq.Handler) mq.Handler {
+			return func(ctx context.Context, msg *mq.Message) error {
+				trace = append(trace, name)
+				return next(ctx, msg)
+			}
+		}
+	}
+	h := mq.Chain(mw("a"), nil, mw("b"))(func(ctx context.Context, msg *mq.Message) error {
+		trace = append(trace, "handler")
+		return nil
+	})
+	_ = h(context.Background(), &mq.Message{})
+	if got := strings.Join(trace, ","); got != "a,b,handler" {
+		t.Errorf("unexpected order: %s", got)
+	}
+}
+
+func TestMemoryClient_Middleware(t *testing.T) {
+	var trace []string
+	record := func(name string) mq.HandlerMiddleware {
+		return func(next mq.Handler) mq.Handler {
+			return func(ctx context.Context, msg *mq.Message) error {
+				if msg.Topic == "orders" {
+					trace = append(trace, name)
+				}
+				return next(ctx, msg)
+			}
+		}
+	}
+	client := mq.NewBuilder(memory.New("test")).
+		WithMiddleware(record("client"), mq.RecoveryMiddleware(logger.Nop())).
+		Build()
+
+	ctx := context.Background()
+	_ = client.Connect(ctx)
+	defer client.Close()
+
+	dlq := make(chan *mq.Message, 1)
+	_ = client.Subscribe(ctx, "dlq", func(ctx context.Context, msg *mq.Message) error {
+		dlq <- msg
+		return nil
+	})
+	_ = client.Subscribe(ctx, "orders", func(ctx context.Context, msg *mq.Message) error {
+		panic("boom")
+	}, mq.WithMiddleware(record("subscription")), mq.WithDeadLetter("dlq", 1))
+
+	_, _ = client.Publish(ctx, "orders", []byte("o-1"))
+
+	select {
+	case msg := <-dlq:
+		if !strings.Contains(msg.Headers[mq.HeaderDLQError], "boom") {
+			t.Errorf("unexpected dead letter error: %q", msg.Headers[mq.HeaderDLQError])
+		}
+	case <-time.After(time.Second):
+		t.Fatal("panic not recovered into dead letter")
+	}
+	if got := strings.Join(trace, ","); got != "subscription,client" {
+		t.Errorf("unexpected order: %s", got)
+	}
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	h := mq.TimeoutMiddleware(10 * time.Millisecond)(func(ctx context.Context, msg *mq.Message) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if err := h(context.Background(), &mq.Message{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestRequestReply(t *testing.T) {
+	client := memory.New("test")
+	ctx := context.Background()
+	_ = client.Connect(ctx)
+	defer client.Close()
+
+	err := mq.ServeReply(ctx, client, "echo", func(ctx context.Context, msg *mq.Message) ([]byte, error) {
+		if string(msg.Value) == "fail" {
+			return nil, errors.New("bad request")
+		}
+		return append([]byte("echo:"), msg.Value...), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	resp, err := mq.Request(reqCtx, client, "echo", []byte("hi"))
+	if err != nil || string(resp) != "echo:hi" {
+		t.Fatalf("Request = %q, %v", resp, err)
+	}
+
+	var replyErr *mq.ReplyError
+	if _, err := mq.Request(reqCtx, client, "echo", []byte("fail")); !errors.As(err, &replyErr) || replyErr.Message != "bad request" {
+		t.Fatalf("expected reply error, got %v", err)
+	}
+
+	r := mq.NewRequester(client, mq.WithRequestTimeout(20*time.Millisecond))
+	defer r.Close()
+	if _, err := r.Request(ctx, "nobody", []byte("hi")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected timeout, got %v", err)
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	b := mq.ExponentialBackoff(100*time.Millisecond, time.Second, 2, 0)
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	for i, w := range want {
+		if got := b(i + 1); got != w {
+			t.Errorf("attempt %d: got %v, want %v", i+1, got, w)
+		}
+	}
+	j := mq.ExponentialBackoff(100*time.Millisecond, 0, 2, 0.5)
+	for range 20 {
+		if d := j(2); d < 100*time.Millisecond || d > 300*time.Millisecond {
+			t.Fatalf("jittered delay out of range: %v", d)
+		}
+	}
+}
+
+func TestSubscribeWithRetry(t *testing.T) {
+	client := memory.New("test")
+	ctx := context.Background()
+	_ = client.Connect(ctx)
+	defer client.Close()
+
+	type delivery struct {
+		value   string
+		topic   string
+		attempt int
+	}
+	deliveries := make(chan delivery, 16)
+	handler := func(ctx context.Context, msg *mq.Message) error {
+		a, _ := resilience.AttemptFromContext(ctx)
+		deliveries <- delivery{string(msg.Value), msg.Topic, a.Number}
+		if string(msg.Value) == "ok" || (string(msg.Value) == "flaky" && a.Number >= 3) {
+			return nil
+		}
+		return errors.New("boom")
+	}
+	dlq := make(chan *mq.Message, 1)
+	_ = client.Subscribe(ctx, "orders.dlq", func(ctx context.Context, msg *mq.Message) error {
+		dlq <- msg
+		return nil
+	})
+	err := mq.SubscribeWithRetry(ctx, client, "orders", handler,
+		mq.WithRetryTopics(3, mq.ConstantBackoff(30*time.Millisecond), "orders.retry"),
+		mq.WithDeadLetter("orders.dlq", 0),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _ = client.Publish(ctx, "orders", []byte("flaky"))
+	_, _ = client.Publish(ctx, "orders", []byte("ok"))
+
+	var seen []delivery
+	timeout := time.After(2 * time.Second)
+	for len(seen) < 4 {
+		select {
+		case d := <-deliveries:
+			seen = append(seen, d)
+		case <-timeout:
+			t.Fatalf("timed out, deliveries: %+v", seen)
+		}
+	}
+	// 失败消息不阻塞原主题：ok 紧随第一次失败之后处理
+	if seen[1].value != "ok" {
+		t.Errorf("original topic stalled: %+v", seen)
+	}
+	last := seen[3]
+	if last.value != "flaky" || last.topic != "orders.retry" || last.attempt != 3 {
+		t.Errorf("unexpected final delivery: %+v", last)
+	}
+
+	_, _ = client.Publish(ctx, "orders", []byte("poison"))
+	select {
+	case msg := <-dlq:
+		if msg.Headers[mq.HeaderDLQOriginalTopic] != "orders" || msg.Headers[mq.HeaderDLQDeliveries] != "3" {
+			t.Errorf("unexpected dead letter headers: %v", msg.Headers)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("poison message not dead-lettered")
+	}
+}
+
+func TestCompressed_RoundTrip(t *testing.T) {
+	raw := memory.New("test")
+	client := mq.NewBuilder(raw).WithCompression(mq.CompressionConfig{Algorithm: mq.CompressionZstd, MinSize: 16}).Build()
+	ctx := context.Background()
+	_ = client.Connect(ctx)
+	defer client.Close()
+
+	payload := []byte(strings.Repeat("compressible payload ", 100))
+	received := make(chan *mq.Message, 8)
+	_ = client.Subscribe(ctx, "orders", func(ctx context.Context, msg *mq.Message) error {
+		received <- msg
+		return nil
+	})
+	encodings := make(chan string, 8)
+	_ = raw.Subscribe(ctx, "orders", func(ctx context.Context, msg *mq.Message) error {
+		encodings <- msg.Headers[mq.HeaderContentEncoding]
+		return nil
+	}, mq.WithGroup("raw"))
+
+	for _, c := range []mq.Compression{"", mq.CompressionGzip, mq.CompressionSnappy, mq.CompressionNone} {
+		if _, err := client.Publish(ctx, "orders", payload, mq.WithCompression(c)); err != nil {
+			t.Fatal(err)
+		}
+		want := string(c)
+		if c == "" {
+			want = string(mq.CompressionZstd)
+		} else if c == mq.CompressionNone {
+			want = ""
+		}
+		select {
+		case msg := <-received:
+			if string(msg.Value) != string(payload) {
+				t.Errorf("%s: payload mismatch", want)
+			}
+			if _, ok := msg.Headers[mq.HeaderContentEncoding]; ok {
+				t.Errorf("%s: content-encoding should be removed", want)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timeout")
+		}
+		if got := <-encodings; got != want {
+			t.Errorf("content-encoding = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestCompressed_MaxMessageSize(t *testing.T) {
+	ctx := context.Background()
+	payload := make([]byte, 10_000)
+	for i := range payload {
+		payload[i] = byte(i * 7919 % 251)
+	}
+
+	reject := mq.NewCompressed(memory.New("test"), mq.CompressionConfig{MaxMessageSize: 1024})
+	_ = reject.Connect(ctx)
+	defer reject.Close()
+	if _, err := reject.Publish(ctx, "orders", payload); !errors.Is(err, mq.ErrMessageTooLarge) {
+		t.Fatalf("expected ErrMessageTooLarge, got %v", err)
+	}
+
+	raw := memory.New("test")
+	chunked := mq.NewCompressed(raw, mq.CompressionConfig{MaxMessageSize: 1024, Oversize: mq.OversizeChunk})
+	_ = chunked.Connect(ctx)
+	defer chunked.Close()
+
+	var chunks atomic.Int32
+	_ = raw.Subscribe(ctx, "orders", func(ctx context.Context, msg *mq.Message) error {
+		if len(msg.Value) > 1024 {
+			t.Errorf("chunk too large: %d", len(msg.Value))
+		}
+		chunks.Add(1)
+		return nil
+	}, mq.WithGroup("raw"))
+	received := make(chan *mq.Message, 1)
+	_ = chunked.Subscribe(ctx, "orders", func(ctx context.Context, msg *mq.Message) error {
+		received <- msg
+		return nil
+	})
+
+	if _, err := chunked.Publish(ctx, "orders", payload, mq.WithHeaders(map[string]string{"x-trace": "1"})); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case msg := <-received:
+		if string(msg.Value) != string(payload) || msg.Headers["x-trace"] != "1" {
+			t.Errorf("reassembled message mismatch: %d bytes, headers %v", len(msg.Value), msg.Headers)
+		}
+		if _, ok := msg.Headers[mq.HeaderChunkID]; ok {
+			t.Error("chunk headers should be removed")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+	deadline := time.Now().Add(time.Second)
+	for chunks.Load() < 20 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := chunks.Load(); n != 20 {
+		t.Errorf("expected 20 chunks, got %d", n)
+	}
+}
+
+func TestDecompress_Limit(t *testing.T) {
+	data, _ := mq.Compress(mq.CompressionGzip, make([]byte, 1<<20))
+	if _, err := mq.Decompress(mq.CompressionGzip, data, 1<<10); !errors.Is(err, mq.ErrMessageTooLarge) {
+		t.Fatalf("expected ErrMessageTooLarge, got %v", err)
+	}
+	if _, err := mq.Compress("lz4", nil); !errors.Is(err, mq.ErrUnsupportedCompression) {
+		t.Fatalf("expected ErrUnsupportedCompression, got %v", err)
+	}
+}
+
+func TestTraced_PublishBatchFallback(t *testing.T) {
+	ctx := context.Background()
+	client := memory.New("test")
+	defer client.Close()
+
+	got := make(chan *mq.Message, 2)
+	if err := client.Subscribe(ctx, "orders", func(_ context.Context, msg *mq.Message) error {
+		got <- msg
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 底层客户端不支持批量时逐条发布，并保留原有消息头
+	traced := mq.NewTraced(client, noop.NewTracerProvider().Tracer("test"))
+	results := traced.PublishBatch(ctx, "orders", []mq.BatchMessage{
+		{Value: []byte("a"), Key: "k1", Headers: map[string]string{"ce-type": "order.created"}},
+		{Value: []byte("b")},
+	})
+	if len(results) != 2 || results[0].Err != nil || results[1].Err != nil {
+		t.Fatalf("results = %+v", results)
+	}
+	for range 2 {
+		select {
+		case msg := <-got:
+			if string(msg.Value) == "a" && (msg.Key != "k1" || msg.Headers["ce-type"] != "order.created") {
+				t.Fatalf("message = %+v", msg)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("batch message not delivered")
+		}
+	}
+}
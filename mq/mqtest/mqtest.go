@@ -0,0 +1,387 @@
+// Package mqtest 提供 mq.Client 实现的一致性测试套件，新的后端与依赖 mq.Client 的应用代码可以用同一套用例验证。
+//
+//	func TestConformance(t *testing.T) {
+//		broker := memory.NewBroker()
+//		mqtest.RunClientConformanceTests(t, func(t *testing.T) mq.Client {
+//			return memory.New("test", memory.WithBroker(broker))
+//		})
+//	}
+//
+// 依赖真实 broker 的后端可通过 WithSettle 等待消费组加入，并用 WithSkip 跳过不支持的能力（如 Delay）
+package mqtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/mildsunup/higo/mq"
+	"github.com/mildsunup/higo/resilience"
+)
+
+// Factory 创建待测客户端（未连接）。同一用例内多次调用返回的客户端必须连接同一集群，以验证消费组语义
+type Factory func(t *testing.T) mq.Client
+
+// Option 套件选项
+type Option func(*suite)
+
+// WithTimeout 等待消息的超时，默认 5 秒
+func WithTimeout(d time.Duration) Option {
+	return func(s *suite) { s.timeout = d }
+}
+
+// WithSettle 订阅后开始发布前的等待时长，供异步加入消费组的实现（如 Kafka）使用，默认 0
+func WithSettle(d time.Duration) Option {
+	return func(s *suite) { s.settle = d }
+}
+
+// WithSkip 跳过指定用例，如 "Delay"
+func WithSkip(names ...string) Option {
+	return func(s *suite) {
+		for _, n := range names {
+			s.skip[n] = true
+		}
+	}
+}
+
+type suite struct {
+	factory Factory
+	timeout time.Duration
+	settle  time.Duration
+	skip    map[string]bool
+}
+
+// RunClientConformanceTests 运行一致性测试套件，每个用例使用独立的主题与消费组：
+//   - Lifecycle：连接、健康检查、关闭后拒绝发布
+//   - PublishSubscribe：消息内容、Key 与消息头原样送达
+//   - KeyOrdering：相同 Key 的消息按发布顺序处理
+//   - ConsumerGroups：同组竞争消费、不同组各自收到全部消息
+//   - Retry：处理失败后按 MaxRetries 重试，ctx 带有处理次数
+//   - DeadLetter：重试耗尽后转入死信主题并带有 x-dlq-* 消息头
+//   - Unsubscribe：取消订阅后不再收到消息
+//   - Delay：WithDelay 的消息不早于延迟送达
+//   - PauseResume：暂停期间不处理消息，恢复后继续（客户端未实现 mq.Pauser 时跳过）
+func RunClientConformanceTests(t *testing.T, factory Factory, opts ...Option) {
+	s := &suite{factory: factory, timeout: 5 * time.Second, skip: make(map[string]bool)}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	cases := []struct {
+		name string
+		fn   func(t *testing.T)
+	}{
+		{"Lifecycle", s.testLifecycle},
+		{"PublishSubscribe", s.testPublishSubscribe},
+		{"KeyOrdering", s.testKeyOrdering},
+		{"ConsumerGroups", s.testConsumerGroups},
+		{"Retry", s.testRetry},
+		{"DeadLetter", s.testDeadLetter},
+		{"Unsubscribe", s.testUnsubscribe},
+		{"Delay", s.testDelay},
+		{"PauseResume", s.testPauseResume},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if s.skip[tc.name] {
+				t.Skip("skipped by WithSkip")
+			}
+			tc.fn(t)
+		})
+	}
+}
+
+// client 创建并连接客户端，用例结束时关闭
+func (s *suite) client(t *testing.T) mq.Client {
+	t.Helper()
+	c := s.factory(t)
+	if err := c.Connect(context.Background()); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+// subscribe 订阅并等待 settle
+func (s *suite) subscribe(t *testing.T, c mq.Client, topic string, handler mq.Handler, opts ...mq.SubscribeOption) {
+	t.Helper()
+	if err := c.Subscribe(context.Background(), topic, handler, opts...); err != nil {
+		t.Fatalf("subscribe %s: %v", topic, err)
+	}
+	if s.settle > 0 {
+		time.Sleep(s.settle)
+	}
+}
+
+func (s *suite) publish(t *testing.T, c mq.Client, topic, value string, opts ...mq.PublishOption) {
+	t.Helper()
+	if _, err := c.Publish(context.Background(), topic, []byte(value), opts...); err != nil {
+		t.Fatalf("publish %s: %v", topic, err)
+	}
+}
+
+// receive 等待下一条消息
+func (s *suite) receive(t *testing.T, ch <-chan *mq.Message) *mq.Message {
+	t.Helper()
+	select {
+	case msg := <-ch:
+		return msg
+	case <-time.After(s.timeout):
+		t.Fatal("timed out waiting for message")
+		return nil
+	}
+}
+
+// quiet 断言 d 时间内没有消息
+func quiet(t *testing.T, ch <-chan *mq.Message, d time.Duration) {
+	t.Helper()
+	select {
+	case msg := <-ch:
+		t.Fatalf("unexpected message %q", msg.Value)
+	case <-time.After(d):
+	}
+}
+
+// names 返回用例独立的主题与消费组名
+func names(t *testing.T) (topic, group string) {
+	id := uuid.NewString()[:8]
+	base := strings.NewReplacer("/", ".", " ", "_").Replace(t.Name())
+	return fmt.Sprintf("mqtest.%s.%s", base, id), "mqtest-" + id
+}
+
+// collect 返回把消息写入通道的处理器
+func collect(n int) (chan *mq.Message, mq.Handler) {
+	ch := make(chan *mq.Message, n)
+	return ch, func(_ context.Context, msg *mq.Message) error {
+		ch <- msg
+		return nil
+	}
+}
+
+func (s *suite) testLifecycle(t *testing.T) {
+	c := s.factory(t)
+	ctx := context.Background()
+	if err := c.Connect(ctx); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	if c.State() != mq.StateConnected {
+		t.Fatalf("state = %s, want connected", c.State())
+	}
+	if err := c.Ping(ctx); err != nil {
+		t.Fatalf("ping: %v", err)
+	}
+	if c.Name() == "" || c.Type() == "" {
+		t.Fatal("name and type must be set")
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if c.State() != mq.StateDisconnected {
+		t.Fatalf("state = %s, want disconnected", c.State())
+	}
+	topic, _ := names(t)
+	if _, err := c.Publish(ctx, topic, []byte("late")); err == nil {
+		t.Fatal("publish after close should fail")
+	}
+}
+
+func (s *suite) testPublishSubscribe(t *testing.T) {
+	c := s.client(t)
+	topic, group := names(t)
+	ch, handler := collect(1)
+	s.subscribe(t, c, topic, handler, mq.WithGroup(group))
+
+	s.publish(t, c, topic, "hello", mq.WithKey("order-1"), mq.WithHeaders(map[string]string{"x-tenant": "acme"}))
+	msg := s.receive(t, ch)
+	if string(msg.Value) != "hello" || msg.Key != "order-1" || msg.Headers["x-tenant"] != "acme" {
+		t.Fatalf("message mismatch: value=%q key=%q headers=%v", msg.Value, msg.Key, msg.Headers)
+	}
+	if msg.Topic != topic || msg.ID == "" {
+		t.Fatalf("message metadata mismatch: topic=%q id=%q", msg.Topic, msg.ID)
+	}
+	if st := c.Stats(); st.Published < 1 || st.Consumed < 1 {
+		t.Fatalf("stats not updated: %+v", st)
+	}
+}
+
+func (s *suite) testKeyOrdering(t *testing.T) {
+	const n = 50
+	c := s.client(t)
+	topic, group := names(t)
+	ch, handler := collect(n)
+	s.subscribe(t, c, topic, handler, mq.WithGroup(group), mq.WithConcurrency(1))
+
+	for i := range n {
+		s.publish(t, c, topic, fmt.Sprint(i), mq.WithKey("same-key"))
+	}
+	for i := range n {
+		if got := string(s.receive(t, ch).Value); got != fmt.Sprint(i) {
+			t.Fatalf("message %d out of order: got %s", i, got)
+		}
+	}
+}
+
+func (s *suite) testConsumerGroups(t *testing.T) {
+	const n = 20
+	topic, group := names(t)
+	var (
+		mu   sync.Mutex
+		seen = make(map[string]int)
+	)
+	competing := func(_ context.Context, msg *mq.Message) error {
+		mu.Lock()
+		seen[string(msg.Value)]++
+		mu.Unlock()
+		return nil
+	}
+	s.subscribe(t, s.client(t), topic, competing, mq.WithGroup(group))
+	s.subscribe(t, s.client(t), topic, competing, mq.WithGroup(group))
+	other, handler := collect(n)
+	s.subscribe(t, s.client(t), topic, handler, mq.WithGroup(group+"-other"))
+
+	p := s.client(t)
+	for i := range n {
+		s.publish(t, p, topic, fmt.Sprint(i), mq.WithKey(fmt.Sprint(i)))
+	}
+	for range n {
+		s.receive(t, other)
+	}
+
+	deadline := time.Now().Add(s.timeout)
+	for {
+		mu.Lock()
+		done := len(seen) == n
+		mu.Unlock()
+		if done || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(seen) != n {
+		t.Fatalf("group received %d distinct messages, want %d", len(seen), n)
+	}
+	for v, count := range seen {
+		if count != 1 {
+			t.Fatalf("message %s delivered %d times within one group", v, count)
+		}
+	}
+}
+
+func (s *suite) testRetry(t *testing.T) {
+	c := s.client(t)
+	topic, group := names(t)
+	attempts := make(chan int, 4)
+	handler := func(ctx context.Context, msg *mq.Message) error {
+		a, _ := resilience.AttemptFromContext(ctx)
+		attempts <- a.Number
+		if a.Number < 3 {
+			return errors.New("transient")
+		}
+		return nil
+	}
+	s.subscribe(t, c, topic, handler, mq.WithGroup(group), mq.WithMaxRetries(3), mq.WithRetryDelay(10*time.Millisecond))
+
+	s.publish(t, c, topic, "retry-me")
+	for want := 1; want <= 3; want++ {
+		select {
+		case got := <-attempts:
+			if got != want {
+				t.Fatalf("attempt = %d, want %d", got, want)
+			}
+		case <-time.After(s.timeout):
+			t.Fatalf("timed out waiting for attempt %d", want)
+		}
+	}
+	select {
+	case got := <-attempts:
+		t.Fatalf("unexpected attempt %d after success", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if st := c.Stats(); st.Retries < 2 {
+		t.Fatalf("retries = %d, want >= 2", st.Retries)
+	}
+}
+
+func (s *suite) testDeadLetter(t *testing.T) {
+	c := s.client(t)
+	topic, group := names(t)
+	dlqTopic := topic + ".dlq"
+	dlq, collectDLQ := collect(1)
+	s.subscribe(t, c, dlqTopic, collectDLQ, mq.WithGroup(group))
+	s.subscribe(t, c, topic, func(context.Context, *mq.Message) error {
+		return errors.New("poison")
+	}, mq.WithGroup(group), mq.WithDeadLetter(dlqTopic, 2), mq.WithRetryDelay(10*time.Millisecond))
+
+	s.publish(t, c, topic, "poison", mq.WithKey("k"))
+	msg := s.receive(t, dlq)
+	if string(msg.Value) != "poison" || msg.Key != "k" {
+		t.Fatalf("dead letter mismatch: value=%q key=%q", msg.Value, msg.Key)
+	}
+	if msg.Headers[mq.HeaderDLQOriginalTopic] != topic || msg.Headers[mq.HeaderDLQError] != "poison" || msg.Headers[mq.HeaderDLQDeliveries] != "2" {
+		t.Fatalf("dead letter headers mismatch: %v", msg.Headers)
+	}
+	if st := c.Stats(); st.DeadLettered != 1 {
+		t.Fatalf("dead_lettered = %d, want 1", st.DeadLettered)
+	}
+}
+
+func (s *suite) testUnsubscribe(t *testing.T) {
+	c := s.client(t)
+	topic, group := names(t)
+	ch, handler := collect(2)
+	s.subscribe(t, c, topic, handler, mq.WithGroup(group))
+
+	s.publish(t, c, topic, "before")
+	s.receive(t, ch)
+	if err := c.Unsubscribe(topic); err != nil {
+		t.Fatalf("unsubscribe: %v", err)
+	}
+	s.publish(t, c, topic, "after")
+	quiet(t, ch, 200*time.Millisecond)
+}
+
+func (s *suite) testDelay(t *testing.T) {
+	const delay = 200 * time.Millisecond
+	c := s.client(t)
+	topic, group := names(t)
+	ch, handler := collect(1)
+	s.subscribe(t, c, topic, handler, mq.WithGroup(group))
+
+	start := time.Now()
+	s.publish(t, c, topic, "later", mq.WithDelay(delay))
+	s.receive(t, ch)
+	if elapsed := time.Since(start); elapsed < delay {
+		t.Fatalf("delayed message delivered after %v, want >= %v", elapsed, delay)
+	}
+}
+
+func (s *suite) testPauseResume(t *testing.T) {
+	c := s.client(t)
+	pauser, ok := mq.Unwrap(c).(mq.Pauser)
+	if !ok {
+		t.Skip("client does not implement mq.Pauser")
+	}
+	topic, group := names(t)
+	ch, handler := collect(2)
+	s.subscribe(t, c, topic, handler, mq.WithGroup(group))
+
+	if err := pauser.Pause(topic); err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+	s.publish(t, c, topic, "held")
+	quiet(t, ch, 200*time.Millisecond)
+	if err := pauser.Resume(topic); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if got := string(s.receive(t, ch).Value); got != "held" {
+		t.Fatalf("got %q after resume", got)
+	}
+}
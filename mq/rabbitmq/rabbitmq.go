@@ -200,6 +200,7 @@ func (c *Client) Subscribe(ctx context.Context, topic string, handler mq.Handler
 	for _, opt := range opts {
 		opt(&options)
 	}
+	handler = options.Wrap(handler)
 
 	// 队列名称
 	queueName := topic
@@ -207,13 +208,17 @@ func (c *Client) Subscribe(ctx context.Context, topic string, handler mq.Handler
 		queueName = fmt.Sprintf("%s.%s", topic, options.Group)
 	}
 
-	// 声明队列
+	// 声明队列，临时订阅使用独占、自动删除的队列
+	durable, autoDelete := c.config.Durable, c.config.AutoDelete
+	if options.Temporary {
+		durable, autoDelete = false, true
+	}
 	queue, err := c.channel.QueueDeclare(
 		queueName,
-		c.config.Durable,
-		c.config.AutoDelete,
-		false, // exclusive
-		false, // no-wait
+		durable,
+		autoDelete,
+		options.Temporary, // exclusive
+		false,             // no-wait
 		nil,
 	)
 	if err != nil {
@@ -287,29 +292,12 @@ func (c *Client) consume(ctx context.Context, deliveries <-chan amqp.Delivery, h
 				}
 			}
 
-			var err error
-			for attempt := 0; attempt <= opts.MaxRetries; attempt++ {
-				if attempt > 0 {
-					c.IncRetries()
-					time.Sleep(opts.RetryDelay)
-				}
-
-				err = handler(ctx, msg)
-				if err == nil {
-					break
-				}
-			}
-
-			if err != nil {
-				c.IncErrors()
+			if err := c.Deliver(ctx, c, handler, msg, opts); err != nil {
 				if !opts.AutoAck {
 					_ = d.Nack(false, true) // requeue
 				}
-			} else {
-				c.IncConsumed()
-				if !opts.AutoAck {
-					_ = d.Ack(false)
-				}
+			} else if !opts.AutoAck {
+				_ = d.Ack(false)
 			}
 		}
 	}
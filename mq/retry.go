@@ -0,0 +1,226 @@
+package mq
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"strconv"
+	"time"
+
+	"github.com/mildsunup/higo/resilience"
+)
+
+// 重试主题消息头，消息转入重试主题时写入
+const (
+	HeaderRetryPrefix        = "x-retry-"
+	HeaderRetryAttempt       = "x-retry-attempt"        // 已失败的处理次数
+	HeaderRetryMaxAttempts   = "x-retry-max-attempts"   // 最多处理次数
+	HeaderRetryOriginalTopic = "x-retry-original-topic" // 原主题
+	HeaderRetryNotBefore     = "x-retry-not-before"     // 最早处理时间（RFC 3339）
+	HeaderRetryError         = "x-retry-error"          // 最近一次处理错误
+)
+
+// Backoff 退避策略，返回第 attempt 次重试（从 1 开始）前的等待时长
+type Backoff func(attempt int) time.Duration
+
+// ConstantBackoff 固定间隔
+func ConstantBackoff(d time.Duration) Backoff {
+	return func(int) time.Duration { return d }
+}
+
+// ExponentialBackoff 指数退避：initial * multiplier^(attempt-1)，不超过 maxDelay（0 表示不限），
+// jitter 为随机抖动比例，实际延迟在 delay*(1±jitter) 之间
+func ExponentialBackoff(initial, maxDelay time.Duration, multiplier, jitter float64) Backoff {
+	if multiplier < 1 {
+		multiplier = 2
+	}
+	jitter = min(max(jitter, 0), 1)
+	return func(attempt int) time.Duration {
+		d := float64(initial)
+		for i := 1; i < attempt; i++ {
+			d *= multiplier
+			if maxDelay > 0 && d >= float64(maxDelay) {
+				break
+			}
+		}
+		if maxDelay > 0 {
+			d = min(d, float64(maxDelay))
+		}
+		if jitter > 0 {
+			d *= 1 + jitter*(2*rand.Float64()-1)
+		}
+		return time.Duration(d)
+	}
+}
+
+// WithBackoff 进程内重试的退避策略，默认按 RetryDelay 固定间隔
+func WithBackoff(b Backoff) SubscribeOption {
+	return func(o *SubscribeOptions) { o.Backoff = b }
+}
+
+// RetryTopicOptions 重试主题配置
+type RetryTopicOptions struct {
+	Topics      []string // 重试主题，第 n 次重试发布到 Topics[n-1]，超出时使用最后一个
+	MaxAttempts int      // 最多处理次数（含首次），达到后按死信或各实现的失败语义处理
+	Backoff     Backoff  // 第 n 次重试前的等待时长
+}
+
+// WithRetryTopics 非阻塞重试：处理失败的消息带上 x-retry-* 消息头（处理次数、最早处理时间等）发布到重试主题并确认原消息，
+// 原主题继续消费后续消息；重试主题的消费者在最早处理时间前等待，只阻塞重试主题本身。
+// 启用后不在进程内重试（MaxRetries=0），配合 SubscribeWithRetry 同时订阅原主题与重试主题。
+// 按退避时长分级使用多个重试主题可避免短延迟的消息排在长延迟消息之后
+//
+//	mq.SubscribeWithRetry(ctx, client, "orders", handler,
+//		mq.WithGroup("billing"),
+//		mq.WithRetryTopics(5, mq.ExponentialBackoff(time.Second, time.Minute, 4, 0.2), "orders.retry.1s", "orders.retry.4s", "orders.retry.1m"),
+//		mq.WithDeadLetter("orders.dlq", 0),
+//	)
+func WithRetryTopics(maxAttempts int, backoff Backoff, topics ...string) SubscribeOption {
+	return func(o *SubscribeOptions) {
+		o.RetryTopic = &RetryTopicOptions{Topics: topics, MaxAttempts: maxAttempts, Backoff: backoff}
+		o.MaxRetries = 0
+	}
+}
+
+// SubscribeWithRetry 以相同的处理器与选项订阅原主题及 WithRetryTopics 配置的全部重试主题
+func SubscribeWithRetry(ctx context.Context, c Client, topic string, handler Handler, opts ...SubscribeOption) error {
+	var o SubscribeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if err := c.Subscribe(ctx, topic, handler, opts...); err != nil {
+		return err
+	}
+	if o.RetryTopic == nil {
+		return nil
+	}
+	for _, rt := range o.RetryTopic.Topics {
+		if err := c.Subscribe(ctx, rt, handler, opts...); err != nil {
+			return fmt.Errorf("mq: subscribe retry topic %s: %w", rt, err)
+		}
+	}
+	return nil
+}
+
+// RetryAttempts 返回消息此前已失败的处理次数（来自重试主题的 x-retry-attempt 消息头）
+func RetryAttempts(msg *Message) int {
+	n, _ := strconv.Atoi(msg.Headers[HeaderRetryAttempt])
+	return n
+}
+
+// originalTopic 消息最初发布的主题（经过重试主题时取 x-retry-original-topic）
+func originalTopic(msg *Message) string {
+	if t := msg.Headers[HeaderRetryOriginalTopic]; t != "" {
+		return t
+	}
+	return msg.Topic
+}
+
+// Deliver 按订阅选项调用 handler，供各客户端实现 Subscribe 时统一重试、重试主题与死信语义：
+//   - 来自重试主题的消息先等待到 x-retry-not-before
+//   - 失败时按 Backoff（默认 RetryDelay）进程内重试至多 MaxRetries 次，ctx 带有 resilience.Attempt（跨重试主题累计）
+//   - 仍失败且配置了重试主题、未达到 MaxAttempts 时发布到重试主题
+//   - 否则配置了死信时发布到死信主题
+//
+// 返回 nil 表示消息已处理（成功、已转入重试主题或死信）可以确认；同时更新 consumed/errors/retries/dead_lettered 统计
+func (b *Base) Deliver(ctx context.Context, p Producer, handler Handler, msg *Message, opts SubscribeOptions) error {
+	if err := waitNotBefore(ctx, msg); err != nil {
+		b.IncErrors()
+		return err
+	}
+
+	backoff := opts.Backoff
+	if backoff == nil {
+		backoff = ConstantBackoff(opts.RetryDelay)
+	}
+	prior := RetryAttempts(msg)
+
+	var err error
+	deliveries := 0
+	for attempt := 0; attempt <= opts.MaxRetries; attempt++ {
+		var delay time.Duration
+		if attempt > 0 {
+			b.IncRetries()
+			delay = backoff(attempt)
+			select {
+			case <-ctx.Done():
+				b.IncErrors()
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+		deliveries++
+		actx := resilience.WithAttempt(ctx, resilience.Attempt{Number: prior + deliveries, Backoff: delay})
+		if err = handler(actx, msg); err == nil {
+			b.IncConsumed()
+			return nil
+		}
+	}
+
+	total := prior + deliveries
+	if rt := opts.RetryTopic; rt != nil && len(rt.Topics) > 0 && total < rt.MaxAttempts {
+		if perr := publishRetry(ctx, p, msg, rt, err, total); perr != nil {
+			b.IncErrors()
+			return perr
+		}
+		b.IncRetries()
+		return nil
+	}
+
+	b.IncErrors()
+	if opts.DeadLetter == nil || opts.DeadLetter.Topic == "" {
+		return err
+	}
+	if perr := deadLetter(ctx, p, msg, opts, err, total); perr != nil {
+		return perr
+	}
+	b.incr(&b.stats.DeadLettered, eventDeadLettered)
+	return nil
+}
+
+// publishRetry 将失败的消息发布到重试主题，failed 为累计失败次数
+func publishRetry(ctx context.Context, p Producer, msg *Message, rt *RetryTopicOptions, cause error, failed int) error {
+	var delay time.Duration
+	if rt.Backoff != nil {
+		delay = rt.Backoff(failed)
+	}
+	topic := rt.Topics[min(failed, len(rt.Topics))-1]
+
+	headers := make(map[string]string, len(msg.Headers)+5)
+	maps.Copy(headers, msg.Headers)
+	headers[HeaderRetryOriginalTopic] = originalTopic(msg)
+	headers[HeaderRetryAttempt] = strconv.Itoa(failed)
+	headers[HeaderRetryMaxAttempts] = strconv.Itoa(rt.MaxAttempts)
+	headers[HeaderRetryNotBefore] = time.Now().Add(delay).UTC().Format(time.RFC3339Nano)
+	headers[HeaderRetryError] = cause.Error()
+
+	pubOpts := []PublishOption{WithHeaders(headers)}
+	if msg.Key != "" {
+		pubOpts = append(pubOpts, WithKey(msg.Key))
+	}
+	if _, err := p.Publish(ctx, topic, msg.Value, pubOpts...); err != nil {
+		return fmt.Errorf("mq: retry to %s: %w (handler: %v)", topic, err, cause)
+	}
+	return nil
+}
+
+// waitNotBefore 等待到消息的最早处理时间
+func waitNotBefore(ctx context.Context, msg *Message) error {
+	notBefore, err := time.Parse(time.RFC3339Nano, msg.Headers[HeaderRetryNotBefore])
+	if err != nil {
+		return nil
+	}
+	wait := time.Until(notBefore)
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,536 @@
+package rocketmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	rmq "github.com/apache/rocketmq-client-go/v2"
+	"github.com/apache/rocketmq-client-go/v2/consumer"
+	"github.com/apache/rocketmq-client-go/v2/primitive"
+	"github.com/apache/rocketmq-client-go/v2/producer"
+	"github.com/apache/rocketmq-client-go/v2/rlog"
+
+	"github.com/mildsunup/higo/mq"
+)
+
+// Config RocketMQ 配置
+type Config struct {
+	Name          string   `json:"name" yaml:"name"`
+	NameServers   []string `json:"name_servers" yaml:"name_servers"`
+	NameServerURL string   `json:"name_server_url" yaml:"name_server_url"` // 通过 HTTP 地址发现 NameServer（阿里云等），优先于 NameServers
+	Namespace     string   `json:"namespace" yaml:"namespace"`             // 命名空间（阿里云实例 ID）
+	AccessKey     string   `json:"access_key" yaml:"access_key"`
+	SecretKey     string   `json:"secret_key" yaml:"secret_key"`
+	SecurityToken string   `json:"security_token" yaml:"security_token"`
+	ProducerGroup string   `json:"producer_group" yaml:"producer_group"` // 生产者组，默认 <Name>-producer
+	InstanceName  string   `json:"instance_name" yaml:"instance_name"`
+
+	SendTimeout       time.Duration `json:"send_timeout" yaml:"send_timeout"`
+	Retries           int           `json:"retries" yaml:"retries"`                         // 发送失败重试次数
+	ConsumeFromFirst  bool          `json:"consume_from_first" yaml:"consume_from_first"`   // 新消费组从最早的消息开始消费，默认从最新
+	MaxReconsumeTimes int32         `json:"max_reconsume_times" yaml:"max_reconsume_times"` // 消费失败后 broker 重新投递次数，超过后进入 %DLQ%<group>，默认 16
+
+	// TimerDelay 使用任意精度的定时消息（RocketMQ 5.x 的 TIMER_DELIVER_MS），
+	// 默认按 4.x 的延迟级别（1s 5s 10s 30s 1m … 2h）向上取整
+	TimerDelay bool `json:"timer_delay" yaml:"timer_delay"`
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() Config {
+	return Config{
+		SendTimeout:       3 * time.Second,
+		Retries:           2,
+		MaxReconsumeTimes: 16,
+	}
+}
+
+// Option 客户端选项
+type Option func(*Client)
+
+// WithTransactionChecker 启用事务消息并设置本地事务回查函数，见 PublishInTransaction
+func WithTransactionChecker(fn TransactionChecker) Option {
+	return func(c *Client) { c.checker = fn }
+}
+
+// Client RocketMQ 客户端，支持普通、顺序（按 Key 选择队列）、延迟、事务消息与标签过滤
+type Client struct {
+	*mq.Base
+	config   Config
+	producer rmq.Producer
+
+	checker    TransactionChecker
+	txProducer rmq.TransactionProducer
+	txCalls    sync.Map // 事务 ID -> *txCall
+
+	mu        sync.RWMutex
+	consumers map[string][]rmq.PushConsumer
+}
+
+// New 创建 RocketMQ 客户端
+func New(cfg Config, opts ...Option) *Client {
+	name := cfg.Name
+	if name == "" {
+		name = "rocketmq"
+	}
+	if cfg.ProducerGroup == "" {
+		cfg.ProducerGroup = name + "-producer"
+	}
+	if cfg.SendTimeout == 0 {
+		cfg.SendTimeout = 3 * time.Second
+	}
+	if cfg.MaxReconsumeTimes == 0 {
+		cfg.MaxReconsumeTimes = 16
+	}
+
+	c := &Client{
+		Base:      mq.NewBase(name, mq.TypeRocketMQ),
+		config:    cfg,
+		consumers: make(map[string][]rmq.PushConsumer),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// SetLogLevel 设置 rocketmq-client-go 内部日志级别（debug、info、warn、error），默认 info
+func SetLogLevel(level string) {
+	rlog.SetLogLevel(level)
+}
+
+// credentials 访问凭证
+func (c *Client) credentials() primitive.Credentials {
+	return primitive.Credentials{
+		AccessKey:     c.config.AccessKey,
+		SecretKey:     c.config.SecretKey,
+		SecurityToken: c.config.SecurityToken,
+	}
+}
+
+func (c *Client) producerOptions(group string) []producer.Option {
+	opts := []producer.Option{
+		producer.WithGroupName(group),
+		producer.WithSendMsgTimeout(c.config.SendTimeout),
+		producer.WithRetry(c.config.Retries),
+		producer.WithQueueSelector(newQueueSelector()),
+	}
+	if c.config.NameServerURL != "" {
+		opts = append(opts, producer.WithNameServerDomain(c.config.NameServerURL))
+	} else {
+		opts = append(opts, producer.WithNameServer(c.config.NameServers))
+	}
+	if c.config.Namespace != "" {
+		opts = append(opts, producer.WithNamespace(c.config.Namespace))
+	}
+	if c.config.InstanceName != "" {
+		opts = append(opts, producer.WithInstanceName(c.config.InstanceName))
+	}
+	if c.config.AccessKey != "" {
+		opts = append(opts, producer.WithCredentials(c.credentials()))
+	}
+	return opts
+}
+
+func (c *Client) Connect(ctx context.Context) error {
+	if !c.CompareAndSwapState(mq.StateDisconnected, mq.StateConnecting) {
+		return fmt.Errorf("rocketmq: invalid state for connect")
+	}
+
+	p, err := rmq.NewProducer(c.producerOptions(c.config.ProducerGroup)...)
+	if err != nil {
+		c.SetState(mq.StateDisconnected)
+		return fmt.Errorf("rocketmq: create producer failed: %w", err)
+	}
+	if err := p.Start(); err != nil {
+		c.SetState(mq.StateDisconnected)
+		return fmt.Errorf("rocketmq: start producer failed: %w", err)
+	}
+	c.producer = p
+
+	// 事务生产者使用独立的生产者组，broker 按组回查
+	if c.checker != nil {
+		tp, err := rmq.NewTransactionProducer(&txListener{client: c}, c.producerOptions(c.config.ProducerGroup+"-tx")...)
+		if err == nil {
+			err = tp.Start()
+		}
+		if err != nil {
+			_ = p.Shutdown()
+			c.SetState(mq.StateDisconnected)
+			return fmt.Errorf("rocketmq: start transactional producer failed: %w", err)
+		}
+		c.txProducer = tp
+	}
+
+	c.SetState(mq.StateConnected)
+	return nil
+}
+
+func (c *Client) Ping(ctx context.Context) error {
+	if c.State() != mq.StateConnected {
+		return fmt.Errorf("rocketmq: not connected")
+	}
+	return nil
+}
+
+// delayLevels RocketMQ 4.x 默认的延迟级别（messageDelayLevel），级别从 1 开始
+var delayLevels = []time.Duration{
+	time.Second, 5 * time.Second, 10 * time.Second, 30 * time.Second,
+	time.Minute, 2 * time.Minute, 3 * time.Minute, 4 * time.Minute, 5 * time.Minute,
+	6 * time.Minute, 7 * time.Minute, 8 * time.Minute, 9 * time.Minute, 10 * time.Minute,
+	20 * time.Minute, 30 * time.Minute, time.Hour, 2 * time.Hour,
+}
+
+// delayLevel 返回不短于 d 的最小延迟级别，超过最大级别时取最大级别
+func delayLevel(d time.Duration) int {
+	for i, l := range delayLevels {
+		if d <= l {
+			return i + 1
+		}
+	}
+	return len(delayLevels)
+}
+
+// propertyTimerDeliverMS RocketMQ 5.x 定时消息的投递时间（毫秒时间戳）
+const propertyTimerDeliverMS = "TIMER_DELIVER_MS"
+
+// newMessage 按发布选项构建消息：Key 同时作为索引 Key 与顺序分区键，消息头写入用户属性
+func (c *Client) newMessage(topic string, value []byte, opts []mq.PublishOption) *primitive.Message {
+	var options mq.PublishOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	msg := primitive.NewMessage(topic, value)
+	for k, v := range options.Headers {
+		msg.WithProperty(k, v)
+	}
+	if options.Key != "" {
+		msg.WithKeys([]string{options.Key})
+		msg.WithShardingKey(options.Key)
+	}
+	if options.Tag != "" {
+		msg.WithTag(options.Tag)
+	}
+	if options.Partition != nil {
+		msg.Queue = &primitive.MessageQueue{Topic: topic, QueueId: int(*options.Partition)}
+	}
+	if options.Delay > 0 {
+		if c.config.TimerDelay {
+			msg.WithProperty(propertyTimerDeliverMS, strconv.FormatInt(time.Now().Add(options.Delay).UnixMilli(), 10))
+		} else {
+			msg.WithDelayTimeLevel(delayLevel(options.Delay))
+		}
+	}
+	return msg
+}
+
+func publishResult(r *primitive.SendResult) *mq.PublishResult {
+	result := &mq.PublishResult{MessageID: r.MsgID, Offset: r.QueueOffset}
+	if r.MessageQueue != nil {
+		result.Partition = int32(r.MessageQueue.QueueId)
+	}
+	return result
+}
+
+// sendError 将非 SendOK 的发送状态转为错误
+func sendError(r *primitive.SendResult) error {
+	if r.Status == primitive.SendOK {
+		return nil
+	}
+	return fmt.Errorf("rocketmq: send status %d", r.Status)
+}
+
+// Publish 同步发布。WithKey 的消息按 Key 哈希到固定队列（顺序消息），WithPartition 指定队列，
+// WithDelay 为延迟消息，WithTag 设置标签
+func (c *Client) Publish(ctx context.Context, topic string, value []byte, opts ...mq.PublishOption) (*mq.PublishResult, error) {
+	if c.producer == nil {
+		return nil, fmt.Errorf("rocketmq: producer not initialized")
+	}
+
+	r, err := c.producer.SendSync(ctx, c.newMessage(topic, value, opts))
+	if err == nil {
+		err = sendError(r)
+	}
+	if err != nil {
+		c.IncErrors()
+		return nil, fmt.Errorf("rocketmq: publish failed: %w", err)
+	}
+
+	c.IncPublished()
+	return publishResult(r), nil
+}
+
+func (c *Client) PublishAsync(ctx context.Context, topic string, value []byte, callback func(*mq.PublishResult, error), opts ...mq.PublishOption) {
+	if c.producer == nil {
+		if callback != nil {
+			callback(nil, fmt.Errorf("rocketmq: producer not initialized"))
+		}
+		return
+	}
+
+	err := c.producer.SendAsync(ctx, func(_ context.Context, r *primitive.SendResult, err error) {
+		if err == nil {
+			err = sendError(r)
+		}
+		if err != nil {
+			c.IncErrors()
+			if callback != nil {
+				callback(nil, fmt.Errorf("rocketmq: publish failed: %w", err))
+			}
+			return
+		}
+		c.IncPublished()
+		if callback != nil {
+			callback(publishResult(r), nil)
+		}
+	}, c.newMessage(topic, value, opts))
+	if err != nil {
+		c.IncErrors()
+		if callback != nil {
+			callback(nil, fmt.Errorf("rocketmq: publish failed: %w", err))
+		}
+	}
+}
+
+// Subscribe 订阅主题，每次订阅创建独立的推模式消费者（集群消费）。
+// 消费组默认 <Name>-group；WithTagFilter 按标签过滤，WithOrderly 顺序消费；
+// 处理失败（进程内重试与死信之后）时由 broker 重新投递，顺序消费时暂停该队列后重试
+func (c *Client) Subscribe(ctx context.Context, topic string, handler mq.Handler, opts ...mq.SubscribeOption) error {
+	if c.State() != mq.StateConnected {
+		return fmt.Errorf("rocketmq: not connected")
+	}
+
+	options := mq.DefaultSubscribeOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+	handler = options.Wrap(handler)
+
+	if options.Group == "" {
+		options.Group = c.Name() + "-group"
+	}
+
+	from := consumer.ConsumeFromLastOffset
+	if c.config.ConsumeFromFirst {
+		from = consumer.ConsumeFromFirstOffset
+	}
+	consumerOpts := []consumer.Option{
+		consumer.WithGroupName(options.Group),
+		consumer.WithConsumerModel(consumer.Clustering),
+		consumer.WithConsumeFromWhere(from),
+		consumer.WithConsumerOrder(options.Orderly),
+		consumer.WithConsumeMessageBatchMaxSize(1),
+		consumer.WithConsumeGoroutineNums(max(options.Concurrency, 1)),
+		consumer.WithMaxReconsumeTimes(c.config.MaxReconsumeTimes),
+		consumer.WithRetry(c.config.Retries),
+	}
+	if c.config.NameServerURL != "" {
+		consumerOpts = append(consumerOpts, consumer.WithNameServerDomain(c.config.NameServerURL))
+	} else {
+		consumerOpts = append(consumerOpts, consumer.WithNameServer(c.config.NameServers))
+	}
+	if c.config.Namespace != "" {
+		consumerOpts = append(consumerOpts, consumer.WithNamespace(c.config.Namespace))
+	}
+	if c.config.InstanceName != "" {
+		consumerOpts = append(consumerOpts, consumer.WithInstance(c.config.InstanceName))
+	}
+	if c.config.AccessKey != "" {
+		consumerOpts = append(consumerOpts, consumer.WithCredentials(c.credentials()))
+	}
+
+	pc, err := rmq.NewPushConsumer(consumerOpts...)
+	if err != nil {
+		return fmt.Errorf("rocketmq: create consumer failed: %w", err)
+	}
+
+	selector := consumer.MessageSelector{Type: consumer.TAG, Expression: "*"}
+	if options.TagFilter != "" {
+		selector.Expression = options.TagFilter
+	}
+	failed := consumer.ConsumeRetryLater
+	if options.Orderly {
+		failed = consumer.SuspendCurrentQueueAMoment
+	}
+	err = pc.Subscribe(topic, selector, func(_ context.Context, msgs ...*primitive.MessageExt) (consumer.ConsumeResult, error) {
+		for _, ext := range msgs {
+			if err := c.Deliver(ctx, c, handler, toMessage(ext), options); err != nil {
+				return failed, nil
+			}
+		}
+		return consumer.ConsumeSuccess, nil
+	})
+	if err != nil {
+		return fmt.Errorf("rocketmq: subscribe failed: %w", err)
+	}
+	if err := pc.Start(); err != nil {
+		_ = pc.Shutdown()
+		return fmt.Errorf("rocketmq: start consumer failed: %w", err)
+	}
+
+	c.mu.Lock()
+	c.consumers[topic] = append(c.consumers[topic], pc)
+	c.mu.Unlock()
+	return nil
+}
+
+// systemProperties RocketMQ 系统属性，不作为消息头暴露，避免转入死信或重试主题时被重新写入
+var systemProperties = map[string]bool{
+	primitive.PropertyKeys: true, primitive.PropertyTags: true, primitive.PropertyWaitStoreMsgOk: true,
+	primitive.PropertyDelayTimeLevel: true, primitive.PropertyRetryTopic: true, primitive.PropertyRealTopic: true,
+	primitive.PropertyRealQueueId: true, primitive.PropertyTransactionPrepared: true, primitive.PropertyProducerGroup: true,
+	primitive.PropertyMinOffset: true, primitive.PropertyMaxOffset: true, primitive.PropertyBuyerId: true,
+	primitive.PropertyOriginMessageId: true, primitive.PropertyTransferFlag: true, primitive.PropertyCorrectionFlag: true,
+	primitive.PropertyMQ2Flag: true, primitive.PropertyReconsumeTime: true, primitive.PropertyMsgRegion: true,
+	primitive.PropertyTraceSwitch: true, primitive.PropertyUniqueClientMessageIdKeyIndex: true,
+	primitive.PropertyMaxReconsumeTimes: true, primitive.PropertyConsumeStartTime: true,
+	primitive.PropertyTranscationPreparedQueueOffset: true, primitive.PropertyTranscationCheckTimes: true,
+	primitive.PropertyCheckImmunityTimeInSeconds: true, primitive.PropertyShardingKey: true,
+	primitive.PropertyTransactionID: true, primitive.PropertyCorrelationID: true,
+	primitive.PropertyMessageReplyToClient: true, primitive.PropertyMessageTTL: true,
+	primitive.PropertyReplyMessageArriveTime: true, primitive.PropertyMsgType: true, primitive.PropertyCluster: true,
+	propertyTimerDeliverMS: true, propertyLocalTxID: true,
+}
+
+// toMessage 转换为 mq.Message，用户属性作为消息头，系统属性可通过 Raw（*primitive.MessageExt）获取
+func toMessage(ext *primitive.MessageExt) *mq.Message {
+	msg := &mq.Message{
+		ID:        ext.MsgId,
+		Topic:     ext.Topic,
+		Key:       ext.GetShardingKey(),
+		Value:     ext.Body,
+		Headers:   make(map[string]string),
+		Timestamp: time.UnixMilli(ext.BornTimestamp),
+		Raw:       ext,
+	}
+	if msg.Key == "" {
+		msg.Key, _, _ = strings.Cut(ext.GetKeys(), primitive.PropertyKeySeparator)
+	}
+	for k, v := range ext.GetProperties() {
+		if !systemProperties[k] {
+			msg.Headers[k] = v
+		}
+	}
+	return msg
+}
+
+// Tag 返回消息的 RocketMQ 标签，非 RocketMQ 消息返回空
+func Tag(msg *mq.Message) string {
+	if ext, ok := msg.Raw.(*primitive.MessageExt); ok {
+		return ext.GetTags()
+	}
+	return ""
+}
+
+// ReconsumeTimes 返回 broker 重新投递的次数，非 RocketMQ 消息返回 0
+func ReconsumeTimes(msg *mq.Message) int {
+	if ext, ok := msg.Raw.(*primitive.MessageExt); ok {
+		return int(ext.ReconsumeTimes)
+	}
+	return 0
+}
+
+// Pause 暂停主题上全部消费者的拉取
+func (c *Client) Pause(topic string) error {
+	return c.eachConsumer(topic, rmq.PushConsumer.Suspend)
+}
+
+// Resume 恢复主题上全部消费者的拉取
+func (c *Client) Resume(topic string) error {
+	return c.eachConsumer(topic, rmq.PushConsumer.Resume)
+}
+
+func (c *Client) eachConsumer(topic string, fn func(rmq.PushConsumer)) error {
+	c.mu.RLock()
+	consumers := c.consumers[topic]
+	c.mu.RUnlock()
+	if len(consumers) == 0 {
+		return fmt.Errorf("rocketmq: topic %s not subscribed", topic)
+	}
+	for _, pc := range consumers {
+		fn(pc)
+	}
+	return nil
+}
+
+func (c *Client) Unsubscribe(topic string) error {
+	c.mu.Lock()
+	consumers := c.consumers[topic]
+	delete(c.consumers, topic)
+	c.mu.Unlock()
+
+	var errs []error
+	for _, pc := range consumers {
+		if err := pc.Shutdown(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Client) Close() error {
+	c.SetState(mq.StateDisconnecting)
+
+	c.mu.Lock()
+	all := c.consumers
+	c.consumers = make(map[string][]rmq.PushConsumer)
+	c.mu.Unlock()
+
+	var errs []error
+	for _, consumers := range all {
+		for _, pc := range consumers {
+			if err := pc.Shutdown(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if c.producer != nil {
+		if err := c.producer.Shutdown(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if c.txProducer != nil {
+		if err := c.txProducer.Shutdown(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	c.SetState(mq.StateDisconnected)
+
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// queueSelector 指定队列（WithPartition）时选择对应队列，否则按 Key 哈希，无 Key 时随机
+type queueSelector struct {
+	hash producer.QueueSelector
+}
+
+func newQueueSelector() producer.QueueSelector {
+	return &queueSelector{hash: producer.NewHashQueueSelector()}
+}
+
+func (s *queueSelector) Select(msg *primitive.Message, queues []*primitive.MessageQueue, lastBrokerName string) *primitive.MessageQueue {
+	if msg.Queue != nil {
+		for _, q := range queues {
+			if q.QueueId == msg.Queue.QueueId {
+				return q
+			}
+		}
+		return nil
+	}
+	return s.hash.Select(msg, queues, lastBrokerName)
+}
+
+var (
+	_ mq.Client = (*Client)(nil)
+	_ mq.Pauser = (*Client)(nil)
+)
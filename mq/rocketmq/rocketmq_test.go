@@ -0,0 +1,176 @@
+package rocketmq
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/apache/rocketmq-client-go/v2/primitive"
+
+	"github.com/mildsunup/higo/mq"
+)
+
+func TestDelayLevel(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want int
+	}{
+		{time.Millisecond, 1},
+		{time.Second, 1},
+		{3 * time.Second, 2},
+		{30 * time.Second, 4},
+		{90 * time.Second, 6},
+		{time.Hour, 17},
+		{24 * time.Hour, 18},
+	}
+	for _, tt := range tests {
+		if got := delayLevel(tt.d); got != tt.want {
+			t.Errorf("delayLevel(%v) = %d, want %d", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestNewMessage(t *testing.T) {
+	c := New(DefaultConfig())
+	msg := c.newMessage("orders", []byte("v"), []mq.PublishOption{
+		mq.WithKey("order-1"),
+		mq.WithTag("created"),
+		mq.WithHeaders(map[string]string{"x-tenant": "t1"}),
+		mq.WithPartition(3),
+		mq.WithDelay(8 * time.Second),
+	})
+
+	if msg.GetKeys() != "order-1" || msg.GetShardingKey() != "order-1" {
+		t.Fatalf("keys = %q, sharding key = %q", msg.GetKeys(), msg.GetShardingKey())
+	}
+	if msg.GetTags() != "created" {
+		t.Fatalf("tags = %q", msg.GetTags())
+	}
+	if msg.GetProperty("x-tenant") != "t1" {
+		t.Fatal("header not mapped to property")
+	}
+	if msg.Queue == nil || msg.Queue.QueueId != 3 {
+		t.Fatalf("queue = %+v", msg.Queue)
+	}
+	if msg.GetProperty(primitive.PropertyDelayTimeLevel) != "3" {
+		t.Fatalf("delay level = %q", msg.GetProperty(primitive.PropertyDelayTimeLevel))
+	}
+
+	cfg := DefaultConfig()
+	cfg.TimerDelay = true
+	before := time.Now().Add(time.Minute).UnixMilli()
+	timer := New(cfg).newMessage("orders", nil, []mq.PublishOption{mq.WithDelay(time.Minute)})
+	at, err := strconv.ParseInt(timer.GetProperty(propertyTimerDeliverMS), 10, 64)
+	if err != nil || at < before {
+		t.Fatalf("timer deliver ms = %q", timer.GetProperty(propertyTimerDeliverMS))
+	}
+	if timer.GetProperty(primitive.PropertyDelayTimeLevel) != "" {
+		t.Fatal("timer delay must not set delay level")
+	}
+}
+
+func TestToMessage(t *testing.T) {
+	ext := &primitive.MessageExt{
+		Message:       primitive.Message{Topic: "orders", Body: []byte("v")},
+		MsgId:         "id-1",
+		BornTimestamp: 1700000000000,
+	}
+	ext.WithKeys([]string{"order-1", "other"})
+	ext.WithTag("paid")
+	ext.WithProperty("x-tenant", "t1")
+	ext.WithProperty(propertyLocalTxID, "tx")
+	ext.WithProperty(primitive.PropertyReconsumeTime, "2")
+	ext.ReconsumeTimes = 2
+
+	msg := toMessage(ext)
+	if msg.ID != "id-1" || msg.Topic != "orders" || string(msg.Value) != "v" || msg.Key != "order-1" {
+		t.Fatalf("message = %+v", msg)
+	}
+	if len(msg.Headers) != 1 || msg.Headers["x-tenant"] != "t1" {
+		t.Fatalf("headers = %v", msg.Headers)
+	}
+	if Tag(msg) != "paid" || ReconsumeTimes(msg) != 2 {
+		t.Fatalf("tag = %q, reconsume = %d", Tag(msg), ReconsumeTimes(msg))
+	}
+	if Tag(&mq.Message{}) != "" || ReconsumeTimes(&mq.Message{}) != 0 {
+		t.Fatal("non-rocketmq message")
+	}
+}
+
+func TestQueueSelector(t *testing.T) {
+	queues := []*primitive.MessageQueue{{QueueId: 0}, {QueueId: 1}, {QueueId: 2}}
+	s := newQueueSelector()
+
+	explicit := primitive.NewMessage("t", nil)
+	explicit.Queue = &primitive.MessageQueue{QueueId: 2}
+	if q := s.Select(explicit, queues, ""); q == nil || q.QueueId != 2 {
+		t.Fatalf("explicit queue = %+v", q)
+	}
+	explicit.Queue.QueueId = 9
+	if q := s.Select(explicit, queues, ""); q != nil {
+		t.Fatalf("missing queue = %+v", q)
+	}
+
+	keyed := primitive.NewMessage("t", nil).WithShardingKey("order-1")
+	first := s.Select(keyed, queues, "")
+	for i := 0; i < 10; i++ {
+		if q := s.Select(keyed, queues, ""); q != first {
+			t.Fatal("same key must select the same queue")
+		}
+	}
+}
+
+func TestPublishInTransaction_NotConfigured(t *testing.T) {
+	c := New(DefaultConfig())
+	_, err := c.PublishInTransaction(context.Background(), "orders", nil, func(context.Context) error { return nil })
+	if !errors.Is(err, ErrNotTransactional) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestTxListener(t *testing.T) {
+	c := New(DefaultConfig(), WithTransactionChecker(func(_ context.Context, msg *mq.Message) TransactionState {
+		if msg.Key == "order-1" {
+			return TransactionCommit
+		}
+		return TransactionRollback
+	}))
+	l := &txListener{client: c}
+
+	execute := func(fn func(context.Context) error) (primitive.LocalTransactionState, *txCall) {
+		call := &txCall{ctx: context.Background(), fn: fn}
+		c.txCalls.Store("tx", call)
+		defer c.txCalls.Delete("tx")
+		msg := primitive.NewMessage("orders", nil)
+		msg.WithProperty(propertyLocalTxID, "tx")
+		return l.ExecuteLocalTransaction(msg), call
+	}
+
+	if state, _ := execute(func(context.Context) error { return nil }); state != primitive.CommitMessageState {
+		t.Fatalf("commit state = %v", state)
+	}
+	if state, call := execute(func(context.Context) error { return errors.New("boom") }); state != primitive.RollbackMessageState || call.err == nil {
+		t.Fatalf("rollback state = %v", state)
+	}
+	if state, _ := execute(func(context.Context) error { return ErrTransactionUnknown }); state != primitive.UnknowState {
+		t.Fatalf("unknown state = %v", state)
+	}
+	if state, call := execute(func(context.Context) error { panic("boom") }); state != primitive.RollbackMessageState || call.panicked != "boom" {
+		t.Fatalf("panic state = %v", state)
+	}
+	if state := l.ExecuteLocalTransaction(primitive.NewMessage("orders", nil)); state != primitive.UnknowState {
+		t.Fatalf("unmatched state = %v", state)
+	}
+
+	ext := &primitive.MessageExt{Message: primitive.Message{Topic: "orders"}}
+	ext.WithKeys([]string{"order-1"})
+	if state := l.CheckLocalTransaction(ext); state != primitive.CommitMessageState {
+		t.Fatalf("check state = %v", state)
+	}
+	ext.WithKeys([]string{"order-2"})
+	if state := l.CheckLocalTransaction(ext); state != primitive.RollbackMessageState {
+		t.Fatalf("check state = %v", state)
+	}
+}
@@ -0,0 +1,131 @@
+package rocketmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/apache/rocketmq-client-go/v2/primitive"
+	"github.com/google/uuid"
+
+	"github.com/mildsunup/higo/mq"
+)
+
+var (
+	// ErrNotTransactional 未通过 WithTransactionChecker 启用事务消息或客户端未连接
+	ErrNotTransactional = errors.New("rocketmq: transactional producer not configured")
+	// ErrTransactionUnknown 本地事务状态未知：fn 返回该错误时半消息既不提交也不回滚，由 broker 稍后回查
+	ErrTransactionUnknown = errors.New("rocketmq: transaction state unknown")
+)
+
+// TransactionState 本地事务状态
+type TransactionState int
+
+const (
+	TransactionCommit   TransactionState = iota + 1 // 提交，消息对消费者可见
+	TransactionRollback                             // 回滚，丢弃半消息
+	TransactionUnknown                              // 未知，broker 稍后再次回查
+)
+
+// TransactionChecker 回查本地事务状态：执行本地事务后进程崩溃或提交结果丢失时，broker 对半消息回查。
+// 通常按消息 Key 或消息头中的业务 ID 查询本地事务记录
+type TransactionChecker func(ctx context.Context, msg *mq.Message) TransactionState
+
+// propertyLocalTxID 关联半消息与本次 PublishInTransaction 调用的属性
+const propertyLocalTxID = "x-local-tx-id"
+
+// txCall 一次 PublishInTransaction 调用
+type txCall struct {
+	ctx      context.Context
+	fn       func(ctx context.Context) error
+	executed bool
+	err      error
+	panicked any
+}
+
+// PublishInTransaction 发送事务消息：先发送对消费者不可见的半消息，成功后执行本地事务 fn，
+// fn 返回 nil 时提交消息，返回错误（或 panic）时回滚，返回 ErrTransactionUnknown 时交由 TransactionChecker 回查。
+// 返回 fn 的错误；需要通过 WithTransactionChecker 启用
+//
+//	_, err := client.PublishInTransaction(ctx, "orders.created", data, func(ctx context.Context) error {
+//		return db.WithContext(ctx).Create(&order).Error
+//	}, mq.WithKey(order.ID))
+func (c *Client) PublishInTransaction(ctx context.Context, topic string, value []byte, fn func(ctx context.Context) error, opts ...mq.PublishOption) (*mq.PublishResult, error) {
+	if c.txProducer == nil {
+		return nil, ErrNotTransactional
+	}
+
+	msg := c.newMessage(topic, value, opts)
+	id := uuid.NewString()
+	msg.WithProperty(propertyLocalTxID, id)
+	call := &txCall{ctx: ctx, fn: fn}
+	c.txCalls.Store(id, call)
+	defer c.txCalls.Delete(id)
+
+	r, err := c.txProducer.SendMessageInTransaction(ctx, msg)
+	if call.panicked != nil {
+		panic(call.panicked)
+	}
+	if err != nil {
+		c.IncErrors()
+		return nil, fmt.Errorf("rocketmq: send half message failed: %w", err)
+	}
+	if !call.executed {
+		c.IncErrors()
+		return nil, fmt.Errorf("rocketmq: send half message failed: %w", sendError(r.SendResult))
+	}
+	if call.err != nil {
+		return nil, call.err
+	}
+
+	c.IncPublished()
+	return publishResult(r.SendResult), nil
+}
+
+// txListener 实现 primitive.TransactionListener
+type txListener struct {
+	client *Client
+}
+
+// ExecuteLocalTransaction 半消息发送成功后在 PublishInTransaction 的调用协程中执行本地事务
+func (l *txListener) ExecuteLocalTransaction(msg *primitive.Message) (state primitive.LocalTransactionState) {
+	v, ok := l.client.txCalls.Load(msg.GetProperty(propertyLocalTxID))
+	if !ok {
+		return primitive.UnknowState
+	}
+	call := v.(*txCall)
+	call.executed = true
+	defer func() {
+		if r := recover(); r != nil {
+			call.panicked = r
+			state = primitive.RollbackMessageState
+		}
+	}()
+
+	call.err = call.fn(call.ctx)
+	switch {
+	case call.err == nil:
+		return primitive.CommitMessageState
+	case errors.Is(call.err, ErrTransactionUnknown):
+		return primitive.UnknowState
+	default:
+		return primitive.RollbackMessageState
+	}
+}
+
+// CheckLocalTransaction broker 回查半消息时调用 TransactionChecker
+func (l *txListener) CheckLocalTransaction(ext *primitive.MessageExt) primitive.LocalTransactionState {
+	if l.client.checker == nil {
+		return primitive.UnknowState
+	}
+	switch l.client.checker(context.Background(), toMessage(ext)) {
+	case TransactionCommit:
+		return primitive.CommitMessageState
+	case TransactionRollback:
+		return primitive.RollbackMessageState
+	default:
+		return primitive.UnknowState
+	}
+}
+
+var _ primitive.TransactionListener = (*txListener)(nil)
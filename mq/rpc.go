@@ -0,0 +1,211 @@
+package mq
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 请求-应答消息头
+const (
+	HeaderCorrelationID   = "x-correlation-id"   // 关联请求与应答
+	HeaderReplyTo         = "x-reply-to"         // 应答主题
+	HeaderReplyError      = "x-reply-error"      // 服务端处理错误
+	HeaderRequestDeadline = "x-request-deadline" // 请求截止时间（RFC 3339），过期请求不再处理
+)
+
+// ReplyError 服务端处理请求返回的错误
+type ReplyError struct {
+	Topic   string
+	Message string
+}
+
+func (e *ReplyError) Error() string {
+	return fmt.Sprintf("mq: reply from %s: %s", e.Topic, e.Message)
+}
+
+// RequestOption 请求方选项
+type RequestOption func(*Requester)
+
+// WithReplyTopic 应答主题，默认 "<client>.reply.<uuid>"（每个实例唯一）。
+// Kafka 需确保主题存在或允许自动创建，可按实例固定名称以免主题膨胀
+func WithReplyTopic(topic string) RequestOption {
+	return func(r *Requester) { r.replyTopic = topic }
+}
+
+// WithRequestTimeout ctx 没有截止时间时的默认超时，默认 30 秒
+func WithRequestTimeout(d time.Duration) RequestOption {
+	return func(r *Requester) { r.timeout = d }
+}
+
+// Requester 请求方：发布带关联 ID 与应答主题的请求，并在应答主题上等待对应的应答。
+// 首次请求时订阅应答主题（临时队列），可并发使用
+type Requester struct {
+	client     Client
+	replyTopic string
+	timeout    time.Duration
+
+	mu         sync.Mutex
+	subscribed bool
+	pending    map[string]chan *Message
+}
+
+// NewRequester 创建请求方
+func NewRequester(c Client, opts ...RequestOption) *Requester {
+	r := &Requester{
+		client:     c,
+		replyTopic: c.Name() + ".reply." + uuid.NewString(),
+		timeout:    30 * time.Second,
+		pending:    make(map[string]chan *Message),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// ReplyTopic 返回应答主题
+func (r *Requester) ReplyTopic() string { return r.replyTopic }
+
+// Request 发布请求并等待应答，服务端返回错误时为 *ReplyError，超时返回包装 ctx 错误的错误
+func (r *Requester) Request(ctx context.Context, topic string, payload []byte, opts ...PublishOption) ([]byte, error) {
+	if err := r.subscribe(); err != nil {
+		return nil, err
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+	deadline, _ := ctx.Deadline()
+
+	id := uuid.NewString()
+	ch := make(chan *Message, 1)
+	r.mu.Lock()
+	r.pending[id] = ch
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		delete(r.pending, id)
+		r.mu.Unlock()
+	}()
+
+	opts = append(opts, WithHeaders(map[string]string{
+		HeaderCorrelationID:   id,
+		HeaderReplyTo:         r.replyTopic,
+		HeaderRequestDeadline: deadline.UTC().Format(time.RFC3339Nano),
+	}))
+	if _, err := r.client.Publish(ctx, topic, payload, opts...); err != nil {
+		return nil, fmt.Errorf("mq: request %s: %w", topic, err)
+	}
+
+	select {
+	case reply := <-ch:
+		if msg, ok := reply.Headers[HeaderReplyError]; ok {
+			return reply.Value, &ReplyError{Topic: topic, Message: msg}
+		}
+		return reply.Value, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("mq: request %s: %w", topic, ctx.Err())
+	}
+}
+
+func (r *Requester) subscribe() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.subscribed {
+		return nil
+	}
+	if err := r.client.Subscribe(context.Background(), r.replyTopic, r.onReply, WithTemporary(), WithMaxRetries(0)); err != nil {
+		return fmt.Errorf("mq: subscribe reply topic %s: %w", r.replyTopic, err)
+	}
+	r.subscribed = true
+	return nil
+}
+
+// onReply 分发应答，未知或已超时的应答直接丢弃
+func (r *Requester) onReply(_ context.Context, msg *Message) error {
+	r.mu.Lock()
+	ch, ok := r.pending[msg.Headers[HeaderCorrelationID]]
+	r.mu.Unlock()
+	if ok {
+		select {
+		case ch <- msg:
+		default:
+		}
+	}
+	return nil
+}
+
+// Close 取消订阅应答主题
+func (r *Requester) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.subscribed {
+		return nil
+	}
+	r.subscribed = false
+	return r.client.Unsubscribe(r.replyTopic)
+}
+
+// 每个客户端共享的默认请求方
+var requesters sync.Map // Client -> *Requester
+
+// Request 使用客户端共享的默认请求方（见 NewRequester）发布请求并等待应答
+//
+//	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+//	defer cancel()
+//	resp, err := mq.Request(ctx, client, "inventory.reserve", payload)
+func Request(ctx context.Context, c Client, topic string, payload []byte, opts ...PublishOption) ([]byte, error) {
+	r, ok := requesters.Load(c)
+	if !ok {
+		r, _ = requesters.LoadOrStore(c, NewRequester(c))
+	}
+	return r.(*Requester).Request(ctx, topic, payload, opts...)
+}
+
+// ReplyHandler 请求处理器，返回应答内容；返回错误时以 x-reply-error 应答给请求方
+type ReplyHandler func(ctx context.Context, msg *Message) ([]byte, error)
+
+// Replier 将请求处理器包装为消息处理器：处理结果（含错误）发布到请求的应答主题，
+// 只有应答发布失败才返回错误（按订阅的重试与死信处理）。
+// 过期请求直接确认不再处理；没有应答主题的消息按普通消息处理，错误照常返回
+func Replier(p Producer, handler ReplyHandler) Handler {
+	return func(ctx context.Context, msg *Message) error {
+		replyTo := msg.Headers[HeaderReplyTo]
+		if replyTo == "" {
+			_, err := handler(ctx, msg)
+			return err
+		}
+		if deadline, err := time.Parse(time.RFC3339Nano, msg.Headers[HeaderRequestDeadline]); err == nil {
+			if time.Now().After(deadline) {
+				return nil
+			}
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithDeadline(ctx, deadline)
+			defer cancel()
+		}
+
+		resp, err := handler(ctx, msg)
+		headers := map[string]string{HeaderCorrelationID: msg.Headers[HeaderCorrelationID]}
+		if err != nil {
+			headers[HeaderReplyError] = err.Error()
+		}
+		if _, perr := p.Publish(context.WithoutCancel(ctx), replyTo, resp, WithHeaders(headers)); perr != nil {
+			return fmt.Errorf("mq: reply to %s: %w", replyTo, perr)
+		}
+		return nil
+	}
+}
+
+// ServeReply 订阅请求主题并以 handler 的结果应答
+//
+//	mq.ServeReply(ctx, client, "inventory.reserve", func(ctx context.Context, msg *mq.Message) ([]byte, error) {
+//		return reserve(ctx, msg.Value)
+//	}, mq.WithGroup("inventory"))
+func ServeReply(ctx context.Context, c Client, topic string, handler ReplyHandler, opts ...SubscribeOption) error {
+	return c.Subscribe(ctx, topic, Replier(c, handler), opts...)
+}
@@ -0,0 +1,453 @@
+package mq
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/mildsunup/higo/resilience"
+)
+
+// SchemaType schema 类型，与 Confluent Schema Registry 的 schemaType 一致
+type SchemaType string
+
+const (
+	SchemaAvro     SchemaType = "AVRO"
+	SchemaProtobuf SchemaType = "PROTOBUF"
+	SchemaJSON     SchemaType = "JSON"
+)
+
+// Schema 消息 schema
+type Schema struct {
+	Type       SchemaType
+	Definition string // Avro schema JSON、.proto 源码或 JSON Schema
+}
+
+var (
+	protoPackageRe = regexp.MustCompile(`(?m)^\s*package\s+([\w.]+)\s*;`)
+	protoTokenRe   = regexp.MustCompile(`[{}]|[\w.]+`)
+)
+
+// RecordName 记录全名：Avro 为 namespace.name，Protobuf 为 package 加第一个顶层 message，JSON Schema 为 title
+func (s Schema) RecordName() string {
+	switch s.Type {
+	case SchemaAvro:
+		var rec struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		}
+		if json.Unmarshal([]byte(s.Definition), &rec) != nil || rec.Name == "" {
+			return ""
+		}
+		if rec.Namespace == "" || strings.Contains(rec.Name, ".") {
+			return rec.Name
+		}
+		return rec.Namespace + "." + rec.Name
+	case SchemaProtobuf:
+		msgs := protoMessages(s.Definition)
+		if len(msgs) == 0 {
+			return ""
+		}
+		if m := protoPackageRe.FindStringSubmatch(s.Definition); m != nil {
+			return m[1] + "." + msgs[0]
+		}
+		return msgs[0]
+	case SchemaJSON:
+		var rec struct {
+			Title string `json:"title"`
+		}
+		_ = json.Unmarshal([]byte(s.Definition), &rec)
+		return rec.Title
+	}
+	return ""
+}
+
+// protoMessages 按声明顺序返回 .proto 源码中的顶层 message 名称
+func protoMessages(def string) []string {
+	var (
+		names []string
+		depth int
+	)
+	tokens := protoTokenRe.FindAllString(stripProtoComments(def), -1)
+	for i, tok := range tokens {
+		switch tok {
+		case "{":
+			depth++
+		case "}":
+			depth--
+		case "message":
+			if depth == 0 && i+1 < len(tokens) {
+				names = append(names, tokens[i+1])
+			}
+		}
+	}
+	return names
+}
+
+var protoCommentRe = regexp.MustCompile(`//[^\n]*|/\*(?s:.*?)\*/`)
+
+func stripProtoComments(def string) string {
+	return protoCommentRe.ReplaceAllString(def, "")
+}
+
+// SubjectNameStrategy 根据主题与记录全名生成 subject
+type SubjectNameStrategy func(topic, record string) (string, error)
+
+// TopicNameStrategy subject 为 <topic>-value（默认），同一主题只允许一种消息类型
+func TopicNameStrategy(topic, _ string) (string, error) {
+	return topic + "-value", nil
+}
+
+// RecordNameStrategy subject 为记录全名，同一类型可发布到多个主题
+func RecordNameStrategy(_, record string) (string, error) {
+	if record == "" {
+		return "", errors.New("mq: record name strategy: schema has no record name")
+	}
+	return record, nil
+}
+
+// TopicRecordNameStrategy subject 为 <topic>-<记录全名>，同一主题可包含多种消息类型
+func TopicRecordNameStrategy(topic, record string) (string, error) {
+	if record == "" {
+		return "", errors.New("mq: topic record name strategy: schema has no record name")
+	}
+	return topic + "-" + record, nil
+}
+
+// SchemaRegistryConfig Schema Registry 客户端配置
+type SchemaRegistryConfig struct {
+	URL        string       // 如 http://schema-registry:8081
+	Username   string       // Basic 认证（Confluent Cloud 为 API Key）
+	Password   string       // Basic 认证（Confluent Cloud 为 API Secret）
+	HTTPClient *http.Client // 自定义 HTTP 客户端，默认客户端按重试信息注入 X-Attempt
+	Timeout    time.Duration
+}
+
+// SchemaRegistry Confluent Schema Registry REST 客户端，缓存 schema ID 与 schema
+type SchemaRegistry struct {
+	cfg  SchemaRegistryConfig
+	http *http.Client
+
+	mu      sync.RWMutex
+	ids     map[string]int // subject + "\x00" + definition -> ID
+	schemas map[int]Schema
+}
+
+// NewSchemaRegistry 创建 Schema Registry 客户端
+func NewSchemaRegistry(cfg SchemaRegistryConfig) (*SchemaRegistry, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("mq: schema registry url is required")
+	}
+	cfg.URL = strings.TrimSuffix(cfg.URL, "/")
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	hc := cfg.HTTPClient
+	if hc == nil {
+		hc = &http.Client{Timeout: cfg.Timeout, Transport: &resilience.AttemptTransport{}}
+	}
+	return &SchemaRegistry{
+		cfg:     cfg,
+		http:    hc,
+		ids:     make(map[string]int),
+		schemas: make(map[int]Schema),
+	}, nil
+}
+
+// RegistryError Schema Registry 返回的错误
+type RegistryError struct {
+	Status  int    `json:"-"`
+	Code    int    `json:"error_code"`
+	Message string `json:"message"`
+}
+
+func (e *RegistryError) Error() string {
+	return fmt.Sprintf("mq: schema registry %d (%d): %s", e.Status, e.Code, e.Message)
+}
+
+// IsSchemaNotFound subject、版本或 schema 不存在
+func IsSchemaNotFound(err error) bool {
+	var e *RegistryError
+	return errors.As(err, &e) && e.Status == http.StatusNotFound
+}
+
+type registrySchema struct {
+	Schema     string     `json:"schema"`
+	SchemaType SchemaType `json:"schemaType,omitempty"`
+}
+
+func toRegistrySchema(s Schema) registrySchema {
+	rs := registrySchema{Schema: s.Definition, SchemaType: s.Type}
+	if s.Type == SchemaAvro {
+		rs.SchemaType = "" // AVRO 为默认类型，兼容旧版本 registry
+	}
+	return rs
+}
+
+// Register 在 subject 下注册 schema（已存在时返回已有 ID），不兼容时返回 409 错误
+func (r *SchemaRegistry) Register(ctx context.Context, subject string, s Schema) (int, error) {
+	return r.resolve(ctx, subject, s, "/subjects/"+url.PathEscape(subject)+"/versions")
+}
+
+// Lookup 查询 schema 在 subject 下的 ID，未注册时 IsSchemaNotFound 为 true
+func (r *SchemaRegistry) Lookup(ctx context.Context, subject string, s Schema) (int, error) {
+	return r.resolve(ctx, subject, s, "/subjects/"+url.PathEscape(subject))
+}
+
+func (r *SchemaRegistry) resolve(ctx context.Context, subject string, s Schema, path string) (int, error) {
+	key := subject + "\x00" + s.Definition
+	r.mu.RLock()
+	id, ok := r.ids[key]
+	r.mu.RUnlock()
+	if ok {
+		return id, nil
+	}
+
+	var out struct {
+		ID int `json:"id"`
+	}
+	if err := r.do(ctx, http.MethodPost, path, toRegistrySchema(s), &out); err != nil {
+		return 0, err
+	}
+	r.mu.Lock()
+	r.ids[key] = out.ID
+	r.schemas[out.ID] = s
+	r.mu.Unlock()
+	return out.ID, nil
+}
+
+// SchemaByID 按 ID 获取 schema
+func (r *SchemaRegistry) SchemaByID(ctx context.Context, id int) (Schema, error) {
+	r.mu.RLock()
+	s, ok := r.schemas[id]
+	r.mu.RUnlock()
+	if ok {
+		return s, nil
+	}
+
+	var out registrySchema
+	if err := r.do(ctx, http.MethodGet, fmt.Sprintf("/schemas/ids/%d", id), nil, &out); err != nil {
+		return Schema{}, err
+	}
+	s = Schema{Type: out.SchemaType, Definition: out.Schema}
+	if s.Type == "" {
+		s.Type = SchemaAvro
+	}
+	r.mu.Lock()
+	r.schemas[id] = s
+	r.mu.Unlock()
+	return s, nil
+}
+
+// Compatible 检查 schema 与 subject 最新版本是否兼容（按 subject 的兼容级别），subject 不存在时视为兼容
+func (r *SchemaRegistry) Compatible(ctx context.Context, subject string, s Schema) (bool, error) {
+	var out struct {
+		IsCompatible bool `json:"is_compatible"`
+	}
+	err := r.do(ctx, http.MethodPost, "/compatibility/subjects/"+url.PathEscape(subject)+"/versions/latest", toRegistrySchema(s), &out)
+	if IsSchemaNotFound(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return out.IsCompatible, nil
+}
+
+func (r *SchemaRegistry) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, r.cfg.URL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	}
+	if r.cfg.Username != "" {
+		req.SetBasicAuth(r.cfg.Username, r.cfg.Password)
+	}
+
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("mq: schema registry %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		regErr := &RegistryError{}
+		if json.Unmarshal(data, regErr) != nil || regErr.Message == "" {
+			regErr.Message = strings.TrimSpace(string(data))
+		}
+		regErr.Status = resp.StatusCode
+		return regErr
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// ErrInvalidWireFormat 消息不是 Schema Registry 线格式（magic byte + schema ID）
+var ErrInvalidWireFormat = errors.New("mq: invalid schema registry wire format")
+
+// RegistryOption RegistryCodec 选项
+type RegistryOption func(*RegistryCodec)
+
+// WithSchema 指定 schema，未指定时使用内层编解码器提供的 schema（如 AvroCodec）
+func WithSchema(s Schema) RegistryOption {
+	return func(c *RegistryCodec) { c.schema = s }
+}
+
+// WithSubjectNameStrategy 设置 subject 命名策略，默认 TopicNameStrategy
+func WithSubjectNameStrategy(s SubjectNameStrategy) RegistryOption {
+	return func(c *RegistryCodec) { c.strategy = s }
+}
+
+// WithAutoRegister 发布时是否自动注册 schema（默认 true）；关闭后只查询已注册的 schema，
+// 未注册或与 registry 中的定义不一致时发布失败，适合由 CI 统一管理 schema 的生产环境
+func WithAutoRegister(auto bool) RegistryOption {
+	return func(c *RegistryCodec) { c.autoRegister = auto }
+}
+
+// RegistryCodec 基于 Schema Registry 的编解码器，使用 Confluent 线格式
+// （magic byte 0 + 4 字节大端 schema ID [+ Protobuf message 索引] + 内层编码），可与其他语言的 Confluent 客户端互通
+//
+//	reg, _ := mq.NewSchemaRegistry(mq.SchemaRegistryConfig{URL: "http://schema-registry:8081"})
+//	codec, _ := mq.NewRegistryCodec(mq.ProtoCodec{}, reg,
+//		mq.WithSchema(mq.Schema{Type: mq.SchemaProtobuf, Definition: orderProto}),
+//		mq.WithSubjectNameStrategy(mq.TopicRecordNameStrategy),
+//	)
+//	mq.PublishTyped(ctx, client, codec, "orders", &pb.OrderCreated{Id: id})
+type RegistryCodec struct {
+	inner        Codec
+	registry     *SchemaRegistry
+	schema       Schema
+	strategy     SubjectNameStrategy
+	autoRegister bool
+}
+
+// NewRegistryCodec 创建 Schema Registry 编解码器
+func NewRegistryCodec(inner Codec, r *SchemaRegistry, opts ...RegistryOption) (*RegistryCodec, error) {
+	c := &RegistryCodec{inner: inner, registry: r, strategy: TopicNameStrategy, autoRegister: true}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.schema.Definition == "" {
+		sp, ok := inner.(interface{ Schema() Schema })
+		if !ok {
+			return nil, fmt.Errorf("mq: registry codec: no schema for %T, use WithSchema", inner)
+		}
+		c.schema = sp.Schema()
+	}
+	return c, nil
+}
+
+func (c *RegistryCodec) ContentType() string { return c.inner.ContentType() }
+
+// Schema 返回编解码器使用的 schema
+func (c *RegistryCodec) Schema() Schema { return c.schema }
+
+func (c *RegistryCodec) Encode(ctx context.Context, topic string, v any) ([]byte, error) {
+	record := c.schema.RecordName()
+	if m, ok := v.(proto.Message); ok {
+		record = string(proto.MessageName(m))
+	}
+	subject, err := c.strategy(topic, record)
+	if err != nil {
+		return nil, err
+	}
+
+	var id int
+	if c.autoRegister {
+		id, err = c.registry.Register(ctx, subject, c.schema)
+	} else {
+		id, err = c.registry.Lookup(ctx, subject, c.schema)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("mq: resolve schema for subject %s: %w", subject, err)
+	}
+
+	payload, err := c.inner.Encode(ctx, topic, v)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 5, 5+binary.MaxVarintLen64*2+len(payload))
+	binary.BigEndian.PutUint32(buf[1:], uint32(id))
+	if c.schema.Type == SchemaProtobuf {
+		buf, err = c.appendMessageIndex(buf, record)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return append(buf, payload...), nil
+}
+
+// appendMessageIndex 写入 Protobuf message 索引（zigzag varint 数组），[0] 按约定简写为单个 0
+func (c *RegistryCodec) appendMessageIndex(buf []byte, record string) ([]byte, error) {
+	name := record[strings.LastIndex(record, ".")+1:]
+	for i, msg := range protoMessages(c.schema.Definition) {
+		if msg != name {
+			continue
+		}
+		if i == 0 {
+			return append(buf, 0), nil
+		}
+		buf = binary.AppendVarint(buf, 1)
+		return binary.AppendVarint(buf, int64(i)), nil
+	}
+	return nil, fmt.Errorf("mq: registry codec: message %s is not a top-level message of the schema", record)
+}
+
+func (c *RegistryCodec) Decode(ctx context.Context, topic string, data []byte, v any) error {
+	if len(data) < 5 || data[0] != 0 {
+		return ErrInvalidWireFormat
+	}
+	id := int(binary.BigEndian.Uint32(data[1:5]))
+	writer, err := c.registry.SchemaByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("mq: fetch schema %d: %w", id, err)
+	}
+	if writer.Type != c.schema.Type {
+		return fmt.Errorf("mq: schema %d is %s, codec expects %s", id, writer.Type, c.schema.Type)
+	}
+
+	payload := data[5:]
+	if c.schema.Type == SchemaProtobuf {
+		n, read := binary.Varint(payload)
+		if read <= 0 || n < 0 {
+			return ErrInvalidWireFormat
+		}
+		payload = payload[read:]
+		for range n {
+			if _, read = binary.Varint(payload); read <= 0 {
+				return ErrInvalidWireFormat
+			}
+			payload = payload[read:]
+		}
+	}
+	return c.inner.Decode(ctx, topic, payload, v)
+}
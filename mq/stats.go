@@ -0,0 +1,99 @@
+package mq
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mildsunup/higo/observability"
+)
+
+// StatsSnapshot 统计快照，包含自上次快照以来的每秒速率
+type StatsSnapshot struct {
+	Stats
+	PublishRate float64       `json:"publish_rate"`
+	ConsumeRate float64       `json:"consume_rate"`
+	ErrorRate   float64       `json:"error_rate"`
+	RetryRate   float64       `json:"retry_rate"`
+	Interval    time.Duration `json:"interval"`
+	At          time.Time     `json:"at"`
+}
+
+// SnapshotProvider 提供统计快照的客户端
+type SnapshotProvider interface {
+	Snapshot() StatsSnapshot
+}
+
+// 统计事件，对应指标的 event 标签
+const (
+	eventPublished = iota
+	eventConsumed
+	eventErrors
+	eventRetries
+	eventDeadLettered
+)
+
+var eventLabels = [...]string{"published", "consumed", "errors", "retries", "dead_lettered"}
+
+// statsCounters 绑定了客户端标签的计数器，按事件索引
+type statsCounters [len(eventLabels)]observability.BoundCounter
+
+// sampler 速率采样状态
+type sampler struct {
+	mu     sync.Mutex
+	last   Stats
+	lastAt time.Time
+}
+
+// 同一 MetricsProvider 只注册一次指标，多个客户端共享
+var statsCounterVecs sync.Map // observability.MetricsProvider -> observability.Counter
+
+// SetMetricsProvider 将客户端统计同步发布到指标提供者（标签 client/type/event）
+func (b *Base) SetMetricsProvider(p observability.MetricsProvider) {
+	vec, _ := statsCounterVecs.LoadOrStore(p, sync.OnceValue(func() observability.Counter {
+		return p.Counter("mq_client_events_total", "Total MQ client events", "client", "type", "event")
+	}))
+	counter := vec.(func() observability.Counter)()
+
+	var counters statsCounters
+	for i, event := range eventLabels {
+		counters[i] = counter.With(b.name, string(b.typ), event)
+	}
+	b.counters.Store(&counters)
+}
+
+// Snapshot 返回统计快照，速率基于上一次快照（首次基于创建时间）计算
+func (b *Base) Snapshot() StatsSnapshot {
+	cur := b.Stats()
+	now := time.Now()
+
+	b.sampler.mu.Lock()
+	last, lastAt := b.sampler.last, b.sampler.lastAt
+	b.sampler.last, b.sampler.lastAt = cur, now
+	b.sampler.mu.Unlock()
+
+	snap := StatsSnapshot{Stats: cur, Interval: now.Sub(lastAt), At: now}
+	if secs := snap.Interval.Seconds(); secs > 0 {
+		snap.PublishRate = float64(cur.Published-last.Published) / secs
+		snap.ConsumeRate = float64(cur.Consumed-last.Consumed) / secs
+		snap.ErrorRate = float64(cur.Errors-last.Errors) / secs
+		snap.RetryRate = float64(cur.Retries-last.Retries) / secs
+	}
+	return snap
+}
+
+func (b *Base) incr(field *int64, event int) {
+	atomic.AddInt64(field, 1)
+	if c := b.counters.Load(); c != nil {
+		c[event].Inc()
+	}
+}
+
+// SnapshotOf 获取客户端（含装饰器）的统计快照
+func SnapshotOf(c Client) (StatsSnapshot, bool) {
+	sp, ok := Unwrap(c).(SnapshotProvider)
+	if !ok {
+		return StatsSnapshot{}, false
+	}
+	return sp.Snapshot(), true
+}
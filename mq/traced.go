@@ -2,6 +2,7 @@ package mq
 
 import (
 	"context"
+	"fmt"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -81,34 +82,56 @@ func (t *Traced) PublishAsync(ctx context.Context, topic string, value []byte, c
 	}, opts...)
 }
 
-func (t *Traced) Subscribe(ctx context.Context, topic string, handler Handler, opts ...SubscribeOption) error {
-	// 包装 handler 以提取 trace context
-	tracedHandler := func(ctx context.Context, msg *Message) error {
-		// 从消息头提取 trace context
-		ctx = t.extractTraceContext(ctx, msg.Headers)
-
-		ctx, span := t.tracer.Start(ctx, "mq.Consume",
-			trace.WithSpanKind(trace.SpanKindConsumer),
-			trace.WithAttributes(
-				attribute.String("mq.name", t.client.Name()),
-				attribute.String("mq.type", string(t.client.Type())),
-				attribute.String("mq.topic", topic),
-				attribute.String("mq.message_id", msg.ID),
-			),
-		)
-		defer span.End()
+// PublishBatch 批量发布，每条消息注入同一 trace context；底层客户端不支持批量时逐条发布
+func (t *Traced) PublishBatch(ctx context.Context, topic string, msgs []BatchMessage) []BatchResult {
+	ctx, span := t.startSpan(ctx, "PublishBatch", topic)
+	defer span.End()
+	span.SetAttributes(attribute.Int("message.count", len(msgs)))
+
+	headers := t.injectTraceContext(ctx)
+	traced := make([]BatchMessage, len(msgs))
+	for i, m := range msgs {
+		h := make(map[string]string, len(m.Headers)+len(headers))
+		for k, v := range m.Headers {
+			h[k] = v
+		}
+		for k, v := range headers {
+			h[k] = v
+		}
+		traced[i] = BatchMessage{Value: m.Value, Key: m.Key, Headers: h}
+	}
 
-		err := handler(ctx, msg)
-		if err != nil {
-			span.RecordError(err)
-			span.SetStatus(codes.Error, err.Error())
-		} else {
-			span.SetStatus(codes.Ok, "")
+	var results []BatchResult
+	if b, ok := t.client.(BatchProducer); ok {
+		results = b.PublishBatch(ctx, topic, traced)
+	} else {
+		results = make([]BatchResult, len(traced))
+		for i, m := range traced {
+			results[i].Result, results[i].Err = t.client.Publish(ctx, topic, m.Value, WithKey(m.Key), WithHeaders(m.Headers))
 		}
-		return err
 	}
 
-	return t.client.Subscribe(ctx, topic, tracedHandler, opts...)
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		span.SetAttributes(attribute.Int("message.failed", failed))
+		span.SetStatus(codes.Error, fmt.Sprintf("%d of %d messages failed", failed, len(msgs)))
+	} else {
+		span.SetStatus(codes.Ok, "")
+	}
+	return results
+}
+
+func (t *Traced) Subscribe(ctx context.Context, topic string, handler Handler, opts ...SubscribeOption) error {
+	traced := tracingMiddleware(t.tracer, t.propagator,
+		attribute.String("mq.name", t.client.Name()),
+		attribute.String("mq.type", string(t.client.Type())),
+	)
+	return t.client.Subscribe(ctx, topic, traced(handler), opts...)
 }
 
 func (t *Traced) Unsubscribe(topic string) error {
@@ -127,6 +150,22 @@ func (t *Traced) Stats() Stats  { return t.client.Stats() }
 // Unwrap 获取底层客户端
 func (t *Traced) Unwrap() Client { return t.client }
 
+// Flush 底层客户端实现 AsyncFlusher 时等待异步消息确认
+func (t *Traced) Flush(ctx context.Context) error {
+	if f, ok := t.client.(AsyncFlusher); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
+// Pending 底层客户端未确认的异步消息数
+func (t *Traced) Pending() int {
+	if f, ok := t.client.(AsyncFlusher); ok {
+		return f.Pending()
+	}
+	return 0
+}
+
 func (t *Traced) startSpan(ctx context.Context, op, topic string) (context.Context, trace.Span) {
 	attrs := []attribute.KeyValue{
 		attribute.String("mq.name", t.client.Name()),
@@ -162,15 +201,7 @@ func (t *Traced) injectTraceContext(ctx context.Context) map[string]string {
 	return result
 }
 
-func (t *Traced) extractTraceContext(ctx context.Context, headers map[string]string) context.Context {
-	if headers == nil {
-		return ctx
-	}
-	carrier := make(propagation.MapCarrier)
-	for k, v := range headers {
-		carrier[k] = v
-	}
-	return t.propagator.Extract(ctx, carrier)
-}
-
-var _ Client = (*Traced)(nil)
+var (
+	_ Client        = (*Traced)(nil)
+	_ BatchProducer = (*Traced)(nil)
+)
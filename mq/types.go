@@ -66,6 +66,19 @@ type PublishResult struct {
 	Offset    int64
 }
 
+// BatchMessage 批量发布的单条消息
+type BatchMessage struct {
+	Value   []byte
+	Key     string
+	Headers map[string]string
+}
+
+// BatchResult 批量发布中单条消息的结果，与输入顺序一致
+type BatchResult struct {
+	Result *PublishResult
+	Err    error
+}
+
 // ConsumeResult 消费结果
 type ConsumeResult struct {
 	Ack   func() error
@@ -93,6 +106,7 @@ type Stats struct {
 	Consumed      int64 `json:"consumed"`
 	Errors        int64 `json:"errors"`
 	Retries       int64 `json:"retries"`
+	DeadLettered  int64 `json:"dead_lettered"`
 	PendingCount  int64 `json:"pending_count"`
 	ConsumerCount int   `json:"consumer_count"`
 }
@@ -102,10 +116,12 @@ type PublishOption func(*PublishOptions)
 
 // PublishOptions 发布选项结构
 type PublishOptions struct {
-	Key       string
-	Headers   map[string]string
-	Partition *int32
-	Delay     time.Duration
+	Key         string
+	Headers     map[string]string
+	Partition   *int32
+	Delay       time.Duration
+	Compression Compression // 压缩算法，需要 Compressed 装饰，见 WithCompression
+	Tag         string      // 消息标签，支持的实现（RocketMQ）用于服务端过滤
 }
 
 // WithKey 设置消息 Key
@@ -135,6 +151,11 @@ func WithDelay(d time.Duration) PublishOption {
 	return func(o *PublishOptions) { o.Delay = d }
 }
 
+// WithTag 设置消息标签：RocketMQ 写入消息 Tag 供订阅方按 WithTagFilter 过滤，其他实现忽略
+func WithTag(tag string) PublishOption {
+	return func(o *PublishOptions) { o.Tag = tag }
+}
+
 // SubscribeOption 订阅选项
 type SubscribeOption func(*SubscribeOptions)
 
@@ -142,9 +163,16 @@ type SubscribeOption func(*SubscribeOptions)
 type SubscribeOptions struct {
 	Group       string
 	Concurrency int
-	AutoAck     bool
+	AutoAck     bool // 确认方式，见 WithAutoAck
 	MaxRetries  int
 	RetryDelay  time.Duration
+	Backoff     Backoff             // 进程内重试退避，nil 时按 RetryDelay 固定间隔
+	RetryTopic  *RetryTopicOptions  // 重试主题配置，nil 表示只在进程内重试
+	DeadLetter  *DeadLetterOptions  // 死信配置，nil 表示重试耗尽后按各实现的失败语义处理
+	Middlewares []HandlerMiddleware // 处理器中间件，由外到内
+	Temporary   bool                // 临时订阅（如应答队列），支持的实现使用独占、自动删除的队列
+	TagFilter   string              // 标签过滤表达式，支持的实现（RocketMQ）在服务端过滤
+	Orderly     bool                // 顺序消费，支持的实现（RocketMQ）锁定队列串行处理
 }
 
 // WithGroup 设置消费组
@@ -157,7 +185,9 @@ func WithConcurrency(n int) SubscribeOption {
 	return func(o *SubscribeOptions) { o.Concurrency = n }
 }
 
-// WithAutoAck 自动确认
+// WithAutoAck 设置确认方式：true（默认）由 broker 投递即确认（RabbitMQ），处理失败不会重投；
+// false 在处理成功（或已转入重试主题、死信）后确认，失败时重新入队。
+// Kafka 无投递即确认，始终在处理成功后提交位移；其他实现忽略
 func WithAutoAck(auto bool) SubscribeOption {
 	return func(o *SubscribeOptions) { o.AutoAck = auto }
 }
@@ -172,6 +202,22 @@ func WithRetryDelay(d time.Duration) SubscribeOption {
 	return func(o *SubscribeOptions) { o.RetryDelay = d }
 }
 
+// WithTemporary 临时订阅：RabbitMQ 声明非持久、独占且断开后自动删除的队列，其他实现忽略
+func WithTemporary() SubscribeOption {
+	return func(o *SubscribeOptions) { o.Temporary = true }
+}
+
+// WithTagFilter 按标签过滤：RocketMQ 只投递匹配的消息，如 "created || paid"，"*" 表示全部；其他实现忽略
+func WithTagFilter(expr string) SubscribeOption {
+	return func(o *SubscribeOptions) { o.TagFilter = expr }
+}
+
+// WithOrderly 顺序消费：RocketMQ 按队列加锁串行处理，失败时暂停该队列重试而不跳过；
+// Kafka 分区内本身串行（Concurrency 为 1 时），其他实现忽略
+func WithOrderly() SubscribeOption {
+	return func(o *SubscribeOptions) { o.Orderly = true }
+}
+
 // DefaultSubscribeOptions 默认订阅选项
 func DefaultSubscribeOptions() SubscribeOptions {
 	return SubscribeOptions{
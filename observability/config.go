@@ -24,6 +24,16 @@ type TracingConfig struct {
 type MetricsConfig struct {
 	Enabled bool   `yaml:"enabled" mapstructure:"enabled"`
 	Path    string `yaml:"path" mapstructure:"path"` // /metrics
+
+	// Instance 实例标识，为空时使用主机名（Kubernetes 中即 Pod 名）
+	Instance string `yaml:"instance" mapstructure:"instance"`
+	// ConstLabels 附加到所有指标的常量标签（app、env、instance 始终附加）
+	ConstLabels map[string]string `yaml:"const_labels" mapstructure:"const_labels"`
+	// Discovery 是否提供抓取目标描述（Prometheus http_sd 格式）
+	Discovery     bool   `yaml:"discovery" mapstructure:"discovery"`
+	DiscoveryPath string `yaml:"discovery_path" mapstructure:"discovery_path"` // /metrics/targets
+	// PortName 指标端口名称（ServiceMonitor endpoints[].port），默认 metrics
+	PortName string `yaml:"port_name" mapstructure:"port_name"`
 }
 
 // DefaultConfig 默认配置
@@ -38,8 +48,10 @@ func DefaultConfig() Config {
 			Ratio:    0.1,
 		},
 		Metrics: MetricsConfig{
-			Enabled: true,
-			Path:    "/metrics",
+			Enabled:       true,
+			Path:          "/metrics",
+			DiscoveryPath: "/metrics/targets",
+			PortName:      "metrics",
 		},
 	}
 }
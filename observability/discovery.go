@@ -0,0 +1,127 @@
+package observability
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// 标准目标标签
+const (
+	LabelApp      = "app"
+	LabelEnv      = "env"
+	LabelInstance = "instance"
+)
+
+// TargetGroup Prometheus http_sd 目标组（https://prometheus.io/docs/prometheus/latest/http_sd/）
+type TargetGroup struct {
+	Targets []string          `json:"targets"`
+	Labels  map[string]string `json:"labels,omitempty"`
+}
+
+// ServiceMonitorEndpoint ServiceMonitor endpoints[] 条目，用于生成 Kubernetes 清单
+type ServiceMonitorEndpoint struct {
+	Port        string `json:"port" yaml:"port"`
+	Path        string `json:"path" yaml:"path"`
+	Scheme      string `json:"scheme,omitempty" yaml:"scheme,omitempty"`
+	Interval    string `json:"interval,omitempty" yaml:"interval,omitempty"`
+	HonorLabels bool   `json:"honorLabels" yaml:"honorLabels"`
+}
+
+// TargetLabels 返回附加到所有指标的标签：app、env、instance 及 ConstLabels
+func (o *Observability) TargetLabels() map[string]string {
+	labels := make(map[string]string, len(o.cfg.Metrics.ConstLabels)+3)
+	for k, v := range o.cfg.Metrics.ConstLabels {
+		labels[k] = v
+	}
+	labels[LabelApp] = o.cfg.ServiceName
+	labels[LabelEnv] = o.cfg.Environment
+	labels[LabelInstance] = o.instance()
+	for k, v := range labels {
+		if v == "" {
+			delete(labels, k)
+		}
+	}
+	return labels
+}
+
+func (o *Observability) instance() string {
+	if o.cfg.Metrics.Instance != "" {
+		return o.cfg.Metrics.Instance
+	}
+	hostname, _ := os.Hostname()
+	return hostname
+}
+
+// ServiceMonitorEndpoint 返回与当前配置一致的 ServiceMonitor 端点描述。
+// 指标已携带 instance 等标签，因此启用 honorLabels，避免被抓取方改写为 exported_*。
+func (o *Observability) ServiceMonitorEndpoint() ServiceMonitorEndpoint {
+	return ServiceMonitorEndpoint{
+		Port:        PortName(o.cfg.Metrics.PortName),
+		Path:        o.metricsPath(),
+		Scheme:      "http",
+		HonorLabels: true,
+	}
+}
+
+// DiscoveryHandler 返回抓取目标描述（http_sd 格式），target 为本实例的指标地址（host:port）
+func (o *Observability) DiscoveryHandler(target string) http.Handler {
+	labels := o.TargetLabels()
+	labels["__metrics_path__"] = o.metricsPath()
+	body, _ := json.Marshal([]TargetGroup{{Targets: []string{target}, Labels: labels}})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	})
+}
+
+// Mount 在 mux 上挂载指标接口；启用 Discovery 时同时挂载抓取目标描述
+func (o *Observability) Mount(mux *http.ServeMux, target string) {
+	if !o.cfg.Metrics.Enabled {
+		return
+	}
+	mux.Handle(o.metricsPath(), o.MetricsHandler())
+	if o.cfg.Metrics.Discovery {
+		path := o.cfg.Metrics.DiscoveryPath
+		if path == "" {
+			path = "/metrics/targets"
+		}
+		mux.Handle(path, o.DiscoveryHandler(target))
+	}
+}
+
+func (o *Observability) metricsPath() string {
+	if o.cfg.Metrics.Path == "" {
+		return "/metrics"
+	}
+	return o.cfg.Metrics.Path
+}
+
+// PortName 将名称规范化为 Kubernetes 端口名（IANA_SVC_NAME）：
+// 小写字母、数字与连字符，至多 15 个字符，至少包含一个字母，首尾不为连字符且无连续连字符。
+// 结果为空时返回 "metrics"。
+func PortName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		default:
+			if s := b.String(); s != "" && !strings.HasSuffix(s, "-") {
+				b.WriteByte('-')
+			}
+		}
+	}
+	s := b.String()
+	if len(s) > 15 {
+		s = s[:15]
+	}
+	s = strings.Trim(s, "-")
+	if strings.IndexFunc(s, func(r rune) bool { return r >= 'a' && r <= 'z' }) < 0 {
+		return "metrics"
+	}
+	return s
+}
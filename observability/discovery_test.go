@@ -0,0 +1,63 @@
+package observability
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPortName(t *testing.T) {
+	tests := map[string]string{
+		"metrics":            "metrics",
+		"HTTP_Server":        "http-server",
+		"multiplex-server":   "multiplex-serve",
+		"--grpc--api--":      "grpc-api",
+		"8080":               "metrics",
+		"":                   "metrics",
+		"very-long-name-abc": "very-long-name",
+	}
+	for in, want := range tests {
+		if got := PortName(in); got != want {
+			t.Errorf("PortName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestObservability_MetricsDiscovery(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ServiceName = "orders"
+	cfg.Environment = "prod"
+	cfg.Metrics.Instance = "orders-0"
+	cfg.Metrics.Discovery = true
+	o, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o.Metrics().Counter("test_requests_total", "test").Inc()
+
+	mux := http.NewServeMux()
+	o.Mount(mux, "10.0.0.1:9090")
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if body := rec.Body.String(); !strings.Contains(body, `test_requests_total{app="orders",env="prod",instance="orders-0"} 1`) {
+		t.Errorf("metrics missing target labels:\n%s", body)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics/targets", nil))
+	var groups []TargetGroup
+	if err := json.Unmarshal(rec.Body.Bytes(), &groups); err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 1 || groups[0].Targets[0] != "10.0.0.1:9090" ||
+		groups[0].Labels["app"] != "orders" || groups[0].Labels["__metrics_path__"] != "/metrics" {
+		t.Errorf("unexpected discovery descriptor: %+v", groups)
+	}
+
+	if ep := o.ServiceMonitorEndpoint(); ep.Port != "metrics" || ep.Path != "/metrics" || !ep.HonorLabels {
+		t.Errorf("unexpected endpoint: %+v", ep)
+	}
+}
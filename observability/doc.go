@@ -2,8 +2,10 @@
 //
 // 核心功能：
 //   - 链路追踪（OpenTelemetry）
-//   - 指标采集（Prometheus）
+//   - 指标采集（Prometheus），所有指标统一携带 app、env、instance 标签
+//   - 抓取目标描述（Prometheus http_sd 格式）与 ServiceMonitor 端点元数据，PortName 生成合规的 Kubernetes 端口名
 //   - 自动 Span 注入
+//   - 进程内延迟分位数（t-digest 滚动窗口）
 //
 // 使用示例：
 //
@@ -11,4 +13,11 @@
 //	defer obs.Shutdown(ctx)
 //	ctx, span := obs.Tracer().Start(ctx, "operation")
 //	defer span.End()
+//
+//	mux := http.NewServeMux()
+//	obs.Mount(mux, "10.0.0.1:9090") // /metrics 与 /metrics/targets（Metrics.Discovery 启用时）
+//
+//	tracker := observability.NewLatencyTracker(observability.WithLatencyWindow(30*time.Second, 30))
+//	tracker.Since(start)
+//	p99 := tracker.Quantile(0.99)
 package observability
@@ -0,0 +1,285 @@
+package observability
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+// centroid t-digest 质心
+type centroid struct {
+	mean   float64
+	weight float64
+}
+
+// TDigest 合并式 t-digest，用于在有限内存下估计分位数
+// 尾部（p99 等）精度高于中位数附近，适合延迟统计。非并发安全。
+type TDigest struct {
+	compression float64
+	centroids   []centroid
+	buffer      []centroid
+	count       float64
+	min, max    float64
+}
+
+// NewTDigest 创建 t-digest，compression 越大精度越高、内存越多，通常取 100
+func NewTDigest(compression float64) *TDigest {
+	if compression <= 0 {
+		compression = 100
+	}
+	return &TDigest{
+		compression: compression,
+		min:         math.Inf(1),
+		max:         math.Inf(-1),
+	}
+}
+
+// Add 添加样本
+func (t *TDigest) Add(v float64) {
+	t.addWeighted(v, 1)
+}
+
+func (t *TDigest) addWeighted(v, w float64) {
+	if math.IsNaN(v) || w <= 0 {
+		return
+	}
+	t.buffer = append(t.buffer, centroid{mean: v, weight: w})
+	t.count += w
+	t.min = math.Min(t.min, v)
+	t.max = math.Max(t.max, v)
+	if len(t.buffer) >= int(5*t.compression) {
+		t.compress()
+	}
+}
+
+// Merge 合并另一个 t-digest
+func (t *TDigest) Merge(other *TDigest) {
+	other.compress()
+	for _, c := range other.centroids {
+		t.addWeighted(c.mean, c.weight)
+	}
+	if other.count > 0 {
+		t.min = math.Min(t.min, other.min)
+		t.max = math.Max(t.max, other.max)
+	}
+}
+
+// Count 样本数
+func (t *TDigest) Count() float64 { return t.count }
+
+// Reset 清空
+func (t *TDigest) Reset() {
+	t.centroids = t.centroids[:0]
+	t.buffer = t.buffer[:0]
+	t.count = 0
+	t.min, t.max = math.Inf(1), math.Inf(-1)
+}
+
+// Quantile 估计分位数（q ∈ [0,1]），无样本时返回 NaN
+func (t *TDigest) Quantile(q float64) float64 {
+	t.compress()
+	c := t.centroids
+	switch {
+	case len(c) == 0:
+		return math.NaN()
+	case len(c) == 1 || q <= 0:
+		if q >= 1 {
+			return t.max
+		}
+		if q <= 0 {
+			return t.min
+		}
+		return c[0].mean
+	case q >= 1:
+		return t.max
+	}
+
+	target := q * t.count
+	first := c[0]
+	if target < first.weight/2 {
+		return t.min + (first.mean-t.min)*target/(first.weight/2)
+	}
+
+	cum := first.weight / 2
+	for i := 0; i < len(c)-1; i++ {
+		dw := (c[i].weight + c[i+1].weight) / 2
+		if cum+dw >= target {
+			return c[i].mean + (target-cum)/dw*(c[i+1].mean-c[i].mean)
+		}
+		cum += dw
+	}
+
+	last := c[len(c)-1]
+	frac := math.Min((target-cum)/(last.weight/2), 1)
+	return last.mean + frac*(t.max-last.mean)
+}
+
+// compress 将缓冲样本与已有质心合并，质心大小受 k1 尺度函数约束
+func (t *TDigest) compress() {
+	if len(t.buffer) == 0 {
+		return
+	}
+
+	all := append(t.centroids, t.buffer...)
+	t.buffer = t.buffer[:0]
+	slices.SortFunc(all, func(a, b centroid) int {
+		switch {
+		case a.mean < b.mean:
+			return -1
+		case a.mean > b.mean:
+			return 1
+		}
+		return 0
+	})
+
+	merged := make([]centroid, 0, len(all))
+	cur := all[0]
+	var weightSoFar float64
+	kLeft := t.scale(0)
+	for _, next := range all[1:] {
+		q := (weightSoFar + cur.weight + next.weight) / t.count
+		if t.scale(q)-kLeft <= 1 {
+			cur.weight += next.weight
+			cur.mean += (next.mean - cur.mean) * next.weight / cur.weight
+			continue
+		}
+		weightSoFar += cur.weight
+		merged = append(merged, cur)
+		kLeft = t.scale(weightSoFar / t.count)
+		cur = next
+	}
+	t.centroids = append(merged, cur)
+}
+
+// scale k1 尺度函数
+func (t *TDigest) scale(q float64) float64 {
+	return t.compression / (2 * math.Pi) * math.Asin(2*math.Min(q, 1)-1)
+}
+
+// LatencySnapshot 延迟分位数快照
+type LatencySnapshot struct {
+	Count int64
+	P50   time.Duration
+	P95   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+// LatencyOption 延迟追踪选项
+type LatencyOption func(*LatencyTracker)
+
+// WithLatencyWindow 设置滚动窗口时长与分桶数，默认 10s / 10 桶
+func WithLatencyWindow(window time.Duration, buckets int) LatencyOption {
+	return func(l *LatencyTracker) {
+		if window > 0 && buckets > 0 {
+			l.span = window / time.Duration(buckets)
+			l.buckets = make([]*TDigest, buckets)
+		}
+	}
+}
+
+// WithLatencyCompression 设置 t-digest 压缩参数，默认 100
+func WithLatencyCompression(c float64) LatencyOption {
+	return func(l *LatencyTracker) {
+		l.compression = c
+	}
+}
+
+// LatencyTracker 进程内滚动窗口延迟分位数追踪
+// 无需抓取 Prometheus 即可在本地计算近期 p95/p99，供自适应限流、降级、对冲请求等策略使用。
+//
+//	tracker := observability.NewLatencyTracker()
+//	start := time.Now()
+//	call()
+//	tracker.Since(start)
+//	if tracker.Quantile(0.99) > slo { degrade() }
+type LatencyTracker struct {
+	mu          sync.Mutex
+	buckets     []*TDigest
+	span        time.Duration
+	compression float64
+	head        int
+	headStart   time.Time
+	now         func() time.Time
+}
+
+// NewLatencyTracker 创建延迟追踪器
+func NewLatencyTracker(opts ...LatencyOption) *LatencyTracker {
+	l := &LatencyTracker{
+		buckets:     make([]*TDigest, 10),
+		span:        time.Second,
+		compression: 100,
+		now:         time.Now,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	for i := range l.buckets {
+		l.buckets[i] = NewTDigest(l.compression)
+	}
+	l.headStart = l.now()
+	return l
+}
+
+// Observe 记录一次耗时
+func (l *LatencyTracker) Observe(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rotate()
+	l.buckets[l.head].Add(float64(d))
+}
+
+// Since 记录自 start 起的耗时
+func (l *LatencyTracker) Since(start time.Time) {
+	l.Observe(l.now().Sub(start))
+}
+
+// Quantile 返回窗口内的分位数，无样本时返回 0
+func (l *LatencyTracker) Quantile(q float64) time.Duration {
+	d := l.merged()
+	if d.Count() == 0 {
+		return 0
+	}
+	return time.Duration(d.Quantile(q))
+}
+
+// Snapshot 返回窗口内的常用分位数
+func (l *LatencyTracker) Snapshot() LatencySnapshot {
+	d := l.merged()
+	if d.Count() == 0 {
+		return LatencySnapshot{}
+	}
+	return LatencySnapshot{
+		Count: int64(d.Count()),
+		P50:   time.Duration(d.Quantile(0.5)),
+		P95:   time.Duration(d.Quantile(0.95)),
+		P99:   time.Duration(d.Quantile(0.99)),
+		Max:   time.Duration(d.max),
+	}
+}
+
+func (l *LatencyTracker) merged() *TDigest {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rotate()
+	d := NewTDigest(l.compression)
+	for _, b := range l.buckets {
+		d.Merge(b)
+	}
+	return d
+}
+
+// rotate 推进窗口，清空过期分桶
+func (l *LatencyTracker) rotate() {
+	elapsed := l.now().Sub(l.headStart)
+	if elapsed < l.span {
+		return
+	}
+	steps := int(elapsed / l.span)
+	for range min(steps, len(l.buckets)) {
+		l.head = (l.head + 1) % len(l.buckets)
+		l.buckets[l.head].Reset()
+	}
+	l.headStart = l.headStart.Add(time.Duration(steps) * l.span)
+}
@@ -0,0 +1,50 @@
+package observability
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestTDigest_Quantile(t *testing.T) {
+	d := NewTDigest(100)
+	values := make([]float64, 100000)
+	r := rand.New(rand.NewPCG(1, 2))
+	for i := range values {
+		values[i] = r.ExpFloat64() * 100
+		d.Add(values[i])
+	}
+	slices.Sort(values)
+
+	for _, q := range []float64{0.5, 0.95, 0.99, 0.999} {
+		want := values[int(q*float64(len(values)))]
+		got := d.Quantile(q)
+		if math.Abs(got-want)/want > 0.02 {
+			t.Errorf("Quantile(%v) = %.2f, want ~%.2f", q, got, want)
+		}
+	}
+	if d.Quantile(1) != values[len(values)-1] {
+		t.Errorf("Quantile(1) should equal max")
+	}
+}
+
+func TestLatencyTracker_Window(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := NewLatencyTracker(WithLatencyWindow(10*time.Second, 10))
+	l.now = func() time.Time { return now }
+	l.headStart = now
+
+	for i := 1; i <= 100; i++ {
+		l.Observe(time.Duration(i) * time.Millisecond)
+	}
+	if p99 := l.Quantile(0.99); p99 < 95*time.Millisecond || p99 > 100*time.Millisecond {
+		t.Errorf("p99 = %v", p99)
+	}
+
+	now = now.Add(11 * time.Second)
+	if s := l.Snapshot(); s.Count != 0 {
+		t.Errorf("expected window to expire, got %d samples", s.Count)
+	}
+}
@@ -70,29 +70,46 @@ type MetricsProvider interface {
 // --- Prometheus 实现 ---
 
 type prometheusProvider struct {
-	registry *prometheus.Registry
+	registry   *prometheus.Registry
+	registerer prometheus.Registerer
+}
+
+// PrometheusOption Prometheus 提供者选项
+type PrometheusOption func(*prometheusProvider)
+
+// WithConstLabels 为通过该提供者创建的所有指标附加常量标签
+func WithConstLabels(labels map[string]string) PrometheusOption {
+	return func(p *prometheusProvider) {
+		if len(labels) > 0 {
+			p.registerer = prometheus.WrapRegistererWith(prometheus.Labels(labels), p.registerer)
+		}
+	}
 }
 
 // NewPrometheusProvider 创建 Prometheus 指标提供者
-func NewPrometheusProvider(registry *prometheus.Registry) MetricsProvider {
-	return &prometheusProvider{registry: registry}
+func NewPrometheusProvider(registry *prometheus.Registry, opts ...PrometheusOption) MetricsProvider {
+	p := &prometheusProvider{registry: registry, registerer: registry}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
 }
 
 func (p *prometheusProvider) Counter(name, help string, labels ...string) Counter {
 	vec := prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: help}, labels)
-	p.registry.MustRegister(vec)
+	p.registerer.MustRegister(vec)
 	return &promCounter{vec: vec}
 }
 
 func (p *prometheusProvider) Gauge(name, help string, labels ...string) Gauge {
 	vec := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: name, Help: help}, labels)
-	p.registry.MustRegister(vec)
+	p.registerer.MustRegister(vec)
 	return &promGauge{vec: vec}
 }
 
 func (p *prometheusProvider) Histogram(name, help string, buckets []float64, labels ...string) Histogram {
 	vec := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: name, Help: help, Buckets: buckets}, labels)
-	p.registry.MustRegister(vec)
+	p.registerer.MustRegister(vec)
 	return &promHistogram{vec: vec}
 }
 
@@ -32,6 +32,8 @@ type Observability struct {
 	registry *prometheus.Registry
 	metrics  MetricsProvider
 	logger   Logger
+	sampler  *DynamicSampler
+	attrs    []attribute.KeyValue
 }
 
 // Option 可观测性选项
@@ -51,6 +53,13 @@ func WithRegistry(registry *prometheus.Registry) Option {
 	}
 }
 
+// WithResourceAttributes 附加资源属性（如 k8s.Metadata.Attributes() 提供的 Pod、节点信息）到全部 span
+func WithResourceAttributes(attrs ...attribute.KeyValue) Option {
+	return func(o *Observability) {
+		o.attrs = append(o.attrs, attrs...)
+	}
+}
+
 // New 创建可观测性实例
 func New(cfg Config, opts ...Option) (*Observability, error) {
 	o := &Observability{cfg: cfg}
@@ -138,30 +147,26 @@ func (o *Observability) createResource() (*resource.Resource, error) {
 	if hostname, _ := os.Hostname(); hostname != "" {
 		attrs = append(attrs, semconv.HostName(hostname))
 	}
+	attrs = append(attrs, o.attrs...)
 	return resource.NewWithAttributes(semconv.SchemaURL, attrs...), nil
 }
 
 func (o *Observability) createSampler() sdktrace.Sampler {
-	switch o.cfg.Tracing.Sampler {
-	case "always":
-		return sdktrace.AlwaysSample()
-	case "never":
-		return sdktrace.NeverSample()
-	case "parent":
-		return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(o.cfg.Tracing.Ratio))
-	default:
-		return sdktrace.TraceIDRatioBased(o.cfg.Tracing.Ratio)
-	}
+	o.sampler = NewDynamicSampler(o.cfg.Tracing.Sampler, o.cfg.Tracing.Ratio)
+	return o.sampler
 }
 
 func (o *Observability) initMetrics() {
-	o.registry = prometheus.NewRegistry()
+	if o.registry == nil {
+		o.registry = prometheus.NewRegistry()
+	}
 	if o.cfg.Metrics.Enabled {
-		o.registry.MustRegister(
+		labels := o.TargetLabels()
+		prometheus.WrapRegistererWith(prometheus.Labels(labels), o.registry).MustRegister(
 			collectors.NewGoCollector(),
 			collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
 		)
-		o.metrics = NewPrometheusProvider(o.registry)
+		o.metrics = NewPrometheusProvider(o.registry, WithConstLabels(labels))
 	} else {
 		o.metrics = NoopMetricsProvider()
 	}
@@ -172,6 +177,11 @@ func (o *Observability) TracerProvider() *sdktrace.TracerProvider {
 	return o.tp
 }
 
+// Sampler 返回可运行时调整的采样器，未启用追踪时返回 nil
+func (o *Observability) Sampler() *DynamicSampler {
+	return o.sampler
+}
+
 // Registry 返回 Prometheus Registry
 func (o *Observability) Registry() *prometheus.Registry {
 	return o.registry
@@ -0,0 +1,79 @@
+package observability
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// DynamicSampler 可在运行时调整采样率的采样器，用于不重启地临时提高或降低采样率。
+// parent 模式下仍遵循上游采样决定，仅根采样使用比例
+type DynamicSampler struct {
+	mu      sync.Mutex
+	parent  bool
+	ratio   float64
+	current atomic.Pointer[sdktrace.Sampler]
+}
+
+// NewDynamicSampler 创建动态采样器，mode 取值同 TracingConfig.Sampler：
+// always、never 分别等同比例 1 与 0，parent 为基于父 Span 的比例采样，其他为比例采样
+func NewDynamicSampler(mode string, ratio float64) *DynamicSampler {
+	switch mode {
+	case "always":
+		ratio = 1
+	case "never":
+		ratio = 0
+	}
+	s := &DynamicSampler{parent: mode == "parent"}
+	s.set(ratio)
+	return s
+}
+
+// Ratio 返回当前采样率
+func (s *DynamicSampler) Ratio() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ratio
+}
+
+// SetRatio 设置采样率，取值范围 [0, 1]
+func (s *DynamicSampler) SetRatio(ratio float64) error {
+	if ratio < 0 || ratio > 1 {
+		return fmt.Errorf("observability: sample ratio %v out of range [0, 1]", ratio)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set(ratio)
+	return nil
+}
+
+func (s *DynamicSampler) set(ratio float64) {
+	s.ratio = ratio
+	var sampler sdktrace.Sampler
+	switch {
+	case ratio >= 1:
+		sampler = sdktrace.AlwaysSample()
+	case ratio <= 0:
+		sampler = sdktrace.NeverSample()
+	default:
+		sampler = sdktrace.TraceIDRatioBased(ratio)
+	}
+	if s.parent {
+		sampler = sdktrace.ParentBased(sampler)
+	}
+	s.current.Store(&sampler)
+}
+
+// ShouldSample 实现 sdktrace.Sampler
+func (s *DynamicSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	return (*s.current.Load()).ShouldSample(p)
+}
+
+// Description 实现 sdktrace.Sampler
+func (s *DynamicSampler) Description() string {
+	return "Dynamic{" + (*s.current.Load()).Description() + "}"
+}
+
+var _ sdktrace.Sampler = (*DynamicSampler)(nil)
@@ -0,0 +1,24 @@
+// Package ops 提供可取消的长时操作注册表。
+//
+// 导出、导入、重建索引等耗时任务在开始时登记，按操作 ID 管理；运维可通过管理接口
+// 列出运行中的操作并取消失控的任务。取消通过 context 传递，使用该 context 的存储查询
+// （GORM WithContext、Redis、Elasticsearch 等）与下游调用会随之中止，context.Cause 为 ErrCanceled。
+//
+// 在请求内执行：
+//
+//	func (h *Handler) Export(c *gin.Context) {
+//	    ctx, op := h.ops.Start(c.Request.Context(), "export", ops.WithOwner(userID))
+//	    err := h.svc.Export(ctx, c.Writer) // 内部可用 ops.FromContext(ctx) 上报进度
+//	    op.Finish(err)
+//	}
+//
+// 在后台执行（不随请求结束而取消）：
+//
+//	op := registry.Go(ctx, "reindex", func(ctx context.Context, op *ops.Operation) error {
+//	    return indexer.Rebuild(ctx, op.Progress)
+//	}, ops.WithDescription("rebuild products index"))
+//
+// 挂载到管理服务：
+//
+//	runtime.WithAdmin(cfg.Admin, runtime.WithAdminOperations(registry))
+package ops
@@ -0,0 +1,57 @@
+package ops
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/mildsunup/higo/errors"
+	"github.com/mildsunup/higo/response"
+)
+
+// Handler 返回操作管理接口，挂载时需去掉前缀：
+//
+//	GET    /                 列出操作，支持 ?state=running&kind=export&owner=u1 过滤
+//	GET    /{id}             查看单个操作
+//	POST   /{id}/cancel      取消操作，可选 ?reason=...
+//	DELETE /{id}             同上
+func (r *Registry) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", r.list)
+	mux.HandleFunc("GET /{id}", r.get)
+	mux.HandleFunc("POST /{id}/cancel", r.cancel)
+	mux.HandleFunc("DELETE /{id}", r.cancel)
+	return mux
+}
+
+func (r *Registry) list(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+	list := slices.DeleteFunc(r.List(), func(info Info) bool {
+		return q.Has("state") && string(info.State) != q.Get("state") ||
+			q.Has("kind") && info.Kind != q.Get("kind") ||
+			q.Has("owner") && info.Owner != q.Get("owner")
+	})
+	response.WriteJSON(w, http.StatusOK, list)
+}
+
+func (r *Registry) get(w http.ResponseWriter, req *http.Request) {
+	info, err := r.Get(req.PathValue("id"))
+	if err != nil {
+		response.WriteError(w, req, errors.Wrap(err, errors.NotFound, err.Error()))
+		return
+	}
+	response.WriteJSON(w, http.StatusOK, info)
+}
+
+func (r *Registry) cancel(w http.ResponseWriter, req *http.Request) {
+	id := req.PathValue("id")
+	err := r.Cancel(id, req.URL.Query().Get("reason"))
+	switch {
+	case errors.Is(err, ErrNotFound):
+		response.WriteError(w, req, errors.Wrap(err, errors.NotFound, err.Error()))
+	case err != nil:
+		response.WriteError(w, req, errors.Wrap(err, errors.Aborted, err.Error()))
+	default:
+		info, _ := r.Get(id)
+		response.WriteJSON(w, http.StatusAccepted, info)
+	}
+}
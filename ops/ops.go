@@ -0,0 +1,327 @@
+package ops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/mildsunup/higo/logger"
+)
+
+var (
+	// ErrNotFound 操作不存在（或已结束并被清理）
+	ErrNotFound = errors.New("ops: operation not found")
+	// ErrCanceled 操作被取消，作为 context.Cause 传递给操作内的查询与任务
+	ErrCanceled = errors.New("ops: operation canceled")
+	// ErrFinished 操作已结束，无法取消
+	ErrFinished = errors.New("ops: operation already finished")
+)
+
+// State 操作状态
+type State string
+
+const (
+	StateRunning   State = "running"
+	StateSucceeded State = "succeeded"
+	StateFailed    State = "failed"
+	StateCanceled  State = "canceled"
+)
+
+// Info 操作快照
+type Info struct {
+	ID           string            `json:"id"`
+	Kind         string            `json:"kind"` // 如 export、import、reindex
+	Description  string            `json:"description,omitempty"`
+	Owner        string            `json:"owner,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	State        State             `json:"state"`
+	Done         int64             `json:"done"`
+	Total        int64             `json:"total,omitempty"` // 0 表示未知
+	StartedAt    time.Time         `json:"started_at"`
+	FinishedAt   time.Time         `json:"finished_at,omitzero"`
+	Error        string            `json:"error,omitempty"`
+	CancelReason string            `json:"cancel_reason,omitempty"`
+}
+
+// Operation 可取消的长时操作
+type Operation struct {
+	reg    *Registry
+	cancel context.CancelCauseFunc
+
+	mu   sync.Mutex
+	info Info
+}
+
+// Progress 更新进度，total 为 0 表示总量未知
+func (o *Operation) Progress(done, total int64) {
+	o.mu.Lock()
+	o.info.Done, o.info.Total = done, total
+	o.mu.Unlock()
+}
+
+// Add 累加已完成数量
+func (o *Operation) Add(n int64) {
+	o.mu.Lock()
+	o.info.Done += n
+	o.mu.Unlock()
+}
+
+// Finish 结束操作：err 为 nil 记为成功，被取消（ctx 因 Cancel 结束）时记为取消，其余记为失败。
+// 重复调用无效。
+func (o *Operation) Finish(err error) {
+	o.mu.Lock()
+	if o.info.State != StateRunning {
+		o.mu.Unlock()
+		return
+	}
+	switch {
+	case err == nil:
+		o.info.State = StateSucceeded
+	case o.info.CancelReason != "" || errors.Is(err, ErrCanceled):
+		o.info.State = StateCanceled
+	default:
+		o.info.State = StateFailed
+	}
+	if err != nil {
+		o.info.Error = err.Error()
+	}
+	o.info.FinishedAt = time.Now()
+	info := o.info
+	o.mu.Unlock()
+
+	o.cancel(nil) // 释放 context 资源
+	o.reg.finished(o, info)
+}
+
+// Info 返回操作快照
+func (o *Operation) Info() Info {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	info := o.info
+	info.Labels = maps.Clone(o.info.Labels)
+	return info
+}
+
+// ID 操作 ID
+func (o *Operation) ID() string { return o.info.ID }
+
+// Option 操作选项
+type Option func(*Info)
+
+// WithID 指定操作 ID（默认 UUID），便于与业务单号对应
+func WithID(id string) Option {
+	return func(i *Info) { i.ID = id }
+}
+
+// WithDescription 设置描述
+func WithDescription(desc string) Option {
+	return func(i *Info) { i.Description = desc }
+}
+
+// WithOwner 设置发起人（如用户 ID），API 可按发起人过滤
+func WithOwner(owner string) Option {
+	return func(i *Info) { i.Owner = owner }
+}
+
+// WithLabel 添加标签
+func WithLabel(key, value string) Option {
+	return func(i *Info) {
+		if i.Labels == nil {
+			i.Labels = make(map[string]string)
+		}
+		i.Labels[key] = value
+	}
+}
+
+// RegistryOption 注册表选项
+type RegistryOption func(*Registry)
+
+// WithRetention 设置保留的已结束操作数量（默认 100），便于查看最近操作的结果
+func WithRetention(n int) RegistryOption {
+	return func(r *Registry) { r.retention = n }
+}
+
+// WithLogger 设置日志
+func WithLogger(l logger.Logger) RegistryOption {
+	return func(r *Registry) { r.log = l }
+}
+
+// Registry 长时操作注册表
+//
+// 处理导出、导入、重建索引等耗时任务时登记操作，返回的 context 在操作被取消时结束，
+// 取消会传递到使用该 context 的存储查询与下游调用；运维可通过 Handler 列出并取消操作。
+type Registry struct {
+	log       logger.Logger
+	retention int
+
+	mu      sync.Mutex
+	running map[string]*Operation
+	done    []Info // 最近结束的操作，按结束顺序
+}
+
+// NewRegistry 创建注册表
+func NewRegistry(opts ...RegistryOption) *Registry {
+	r := &Registry{
+		log:       logger.Nop(),
+		retention: 100,
+		running:   make(map[string]*Operation),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Start 登记操作，返回派生的 context 与操作句柄；调用方必须在结束时调用 Finish
+//
+//	ctx, op := registry.Start(ctx, "export", ops.WithOwner(userID))
+//	defer func() { op.Finish(err) }()
+//	rows, err := db.WithContext(ctx).Rows() // 取消时查询随 ctx 中止
+func (r *Registry) Start(ctx context.Context, kind string, opts ...Option) (context.Context, *Operation) {
+	info := Info{Kind: kind, State: StateRunning, StartedAt: time.Now()}
+	for _, opt := range opts {
+		opt(&info)
+	}
+	if info.ID == "" {
+		info.ID = uuid.NewString()
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	op := &Operation{reg: r, cancel: cancel, info: info}
+	ctx = context.WithValue(ctx, operationKey{}, op)
+
+	r.mu.Lock()
+	if prev, ok := r.running[info.ID]; ok {
+		// ID 冲突时取消旧操作，避免其无法再被管理
+		prev.abort("superseded by a new operation with the same id")
+	}
+	r.running[info.ID] = op
+	r.mu.Unlock()
+
+	r.log.Info(ctx, "operation started", logger.String("id", info.ID), logger.String("kind", kind))
+	return ctx, op
+}
+
+// Go 在后台执行操作：ctx 仅用于传递值（不随请求结束而取消），fn 返回后自动 Finish
+func (r *Registry) Go(ctx context.Context, kind string, fn func(ctx context.Context, op *Operation) error, opts ...Option) *Operation {
+	ctx, op := r.Start(context.WithoutCancel(ctx), kind, opts...)
+	go func() {
+		var err error
+		defer func() {
+			if p := recover(); p != nil {
+				err = fmt.Errorf("ops: operation panic: %v", p)
+			}
+			op.Finish(err)
+		}()
+		err = fn(ctx, op)
+	}()
+	return op
+}
+
+// Cancel 取消运行中的操作，context.Cause 为 ErrCanceled
+func (r *Registry) Cancel(id, reason string) error {
+	r.mu.Lock()
+	op, ok := r.running[id]
+	r.mu.Unlock()
+	if !ok {
+		if _, err := r.Get(id); err == nil {
+			return fmt.Errorf("%w: %s", ErrFinished, id)
+		}
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if reason == "" {
+		reason = "canceled"
+	}
+	op.abort(reason)
+	r.log.Warn(context.Background(), "operation canceled", logger.String("id", id), logger.String("reason", reason))
+	return nil
+}
+
+// Get 返回操作快照（运行中或最近结束的）
+func (r *Registry) Get(id string) (Info, error) {
+	r.mu.Lock()
+	op, ok := r.running[id]
+	if !ok {
+		for _, info := range slices.Backward(r.done) {
+			if info.ID == id {
+				r.mu.Unlock()
+				return info, nil
+			}
+		}
+	}
+	r.mu.Unlock()
+	if !ok {
+		return Info{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return op.Info(), nil
+}
+
+// List 返回运行中与最近结束的操作，运行中在前，各自按开始时间倒序
+func (r *Registry) List() []Info {
+	r.mu.Lock()
+	running := slices.Collect(maps.Values(r.running))
+	done := slices.Clone(r.done)
+	r.mu.Unlock()
+
+	list := make([]Info, 0, len(running)+len(done))
+	for _, op := range running {
+		list = append(list, op.Info())
+	}
+	slices.SortFunc(list, func(a, b Info) int { return b.StartedAt.Compare(a.StartedAt) })
+	slices.Reverse(done)
+	return append(list, done...)
+}
+
+// Running 返回运行中的操作数
+func (r *Registry) Running() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.running)
+}
+
+func (r *Registry) finished(op *Operation, info Info) {
+	r.mu.Lock()
+	if r.running[info.ID] == op {
+		delete(r.running, info.ID)
+	}
+	if r.retention > 0 {
+		r.done = append(r.done, info)
+		if over := len(r.done) - r.retention; over > 0 {
+			r.done = slices.Delete(r.done, 0, over)
+		}
+	}
+	r.mu.Unlock()
+
+	fields := []logger.Field{
+		logger.String("id", info.ID), logger.String("kind", info.Kind),
+		logger.String("state", string(info.State)), logger.Duration("duration", info.FinishedAt.Sub(info.StartedAt)),
+	}
+	if info.Error != "" {
+		fields = append(fields, logger.String("error", info.Error))
+	}
+	r.log.Info(context.Background(), "operation finished", fields...)
+}
+
+// abort 记录取消原因并取消 context
+func (o *Operation) abort(reason string) {
+	o.mu.Lock()
+	if o.info.CancelReason == "" {
+		o.info.CancelReason = reason
+	}
+	o.mu.Unlock()
+	o.cancel(ErrCanceled)
+}
+
+type operationKey struct{}
+
+// FromContext 返回 context 所属的操作，用于在深层调用中上报进度
+func FromContext(ctx context.Context) (*Operation, bool) {
+	op, ok := ctx.Value(operationKey{}).(*Operation)
+	return op, ok
+}
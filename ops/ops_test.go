@@ -0,0 +1,112 @@
+package ops
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRegistry_StartFinish(t *testing.T) {
+	r := NewRegistry()
+	ctx, op := r.Start(context.Background(), "export", WithID("exp-1"), WithOwner("u1"))
+
+	if got, ok := FromContext(ctx); !ok || got != op {
+		t.Fatal("FromContext should return the operation")
+	}
+	op.Progress(5, 10)
+	if info, err := r.Get("exp-1"); err != nil || info.State != StateRunning || info.Done != 5 {
+		t.Fatalf("Get() = %+v, %v", info, err)
+	}
+
+	op.Finish(nil)
+	if r.Running() != 0 {
+		t.Errorf("Running() = %d, want 0", r.Running())
+	}
+	info, err := r.Get("exp-1")
+	if err != nil || info.State != StateSucceeded || info.FinishedAt.IsZero() {
+		t.Errorf("Get() after finish = %+v, %v", info, err)
+	}
+	if !errors.Is(r.Cancel("exp-1", ""), ErrFinished) {
+		t.Error("Cancel() on finished operation should return ErrFinished")
+	}
+	if !errors.Is(r.Cancel("missing", ""), ErrNotFound) {
+		t.Error("Cancel() on unknown operation should return ErrNotFound")
+	}
+}
+
+func TestRegistry_Cancel(t *testing.T) {
+	r := NewRegistry()
+	started := make(chan struct{})
+	op := r.Go(context.Background(), "reindex", func(ctx context.Context, op *Operation) error {
+		close(started)
+		<-ctx.Done()
+		return context.Cause(ctx)
+	})
+	<-started
+
+	if err := r.Cancel(op.ID(), "runaway"); err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for op.Info().State == StateRunning && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	info := op.Info()
+	if info.State != StateCanceled || info.CancelReason != "runaway" {
+		t.Errorf("Info() = %+v, want canceled with reason", info)
+	}
+}
+
+func TestRegistry_Retention(t *testing.T) {
+	r := NewRegistry(WithRetention(2))
+	for range 3 {
+		_, op := r.Start(context.Background(), "job")
+		op.Finish(nil)
+	}
+	if n := len(r.List()); n != 2 {
+		t.Errorf("List() len = %d, want 2", n)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	r := NewRegistry()
+	ctx, _ := r.Start(context.Background(), "export", WithID("a"), WithOwner("u1"))
+	_, b := r.Start(context.Background(), "import", WithID("b"))
+	b.Finish(errors.New("boom"))
+	h := r.Handler()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?state=running", nil))
+	var list []Info
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].ID != "a" {
+		t.Errorf("list = %+v, want only a", list)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/a/cancel?reason=stop", nil))
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("cancel status = %d, want 202", rec.Code)
+	}
+	if !errors.Is(context.Cause(ctx), ErrCanceled) {
+		t.Errorf("context.Cause = %v, want ErrCanceled", context.Cause(ctx))
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/b", nil))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("cancel finished status = %d, want 409", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("get missing status = %d, want 404", rec.Code)
+	}
+}
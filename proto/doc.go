@@ -0,0 +1,19 @@
+// Package proto 存放跨服务共享的 Protobuf 定义与生成代码。
+//
+// 约定：
+//   - 目录与 proto 包名一致：proto/higo/<模块>/v<N>/*.proto 对应 package higo.<模块>.v<N>
+//   - go_package 为 github.com/mildsunup/higo/proto/higo/<模块>/v<N>;<模块>v<N>（如 errorsv1、responsev1）
+//   - 生成代码（*.pb.go）与 .proto 同目录提交，使用 paths=source_relative；与 higo 类型的转换写在同包的 convert.go
+//   - 字段只增不改：删除字段时使用 reserved 保留字段号与名称，破坏性变更新建 v<N+1> 包
+//
+// 已定义：
+//   - higo.response.v1.Envelope：统一响应信封，与 response.MarshalProto 的编码一致（responsev1.FromEnvelope / ToEnvelope）
+//   - higo.errors.v1.ErrorDetail：应用错误详情，作为 gRPC Status 详情传递应用错误码、元数据与字段错误
+//     （errorsv1.Status / FromStatus / UnaryServerInterceptor）
+//
+// 重新生成（需要 protoc 与 protoc-gen-go）：
+//
+//	go generate ./proto
+package proto
+
+//go:generate protoc -I.. --go_out=.. --go_opt=paths=source_relative ../proto/higo/response/v1/envelope.proto ../proto/higo/errors/v1/errors.proto
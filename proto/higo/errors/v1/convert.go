@@ -0,0 +1,133 @@
+package errorsv1
+
+import (
+	"context"
+	"sort"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/mildsunup/higo/errors"
+)
+
+// FromError 将错误转换为 ErrorDetail：*errors.Error 保留错误码、元数据与重试建议，
+// 元数据 field_errors 转为 violations；其他错误使用 errors.Unknown
+func FromError(err error) *ErrorDetail {
+	if err == nil {
+		return nil
+	}
+	var e *errors.Error
+	if !errors.As(err, &e) {
+		return &ErrorDetail{Code: int32(errors.Unknown), Message: err.Error()}
+	}
+
+	d := &ErrorDetail{Code: int32(e.Code()), Message: e.Message()}
+	if e.RetryAfter() > 0 {
+		d.RetryAfter = durationpb.New(e.RetryAfter())
+	}
+	meta := make(map[string]any, len(e.Metadata()))
+	for k, v := range e.Metadata() {
+		switch k {
+		case errors.MetaFieldErrors:
+			if fields, ok := v.(map[string]string); ok {
+				d.Violations = violations(fields)
+				continue
+			}
+		case errors.MetaRetryAfter:
+			continue // 由 retry_after 表达
+		}
+		meta[k] = v
+	}
+	if len(meta) > 0 {
+		// 无法表示为 Struct 的元数据（如自定义类型）整体丢弃，不影响错误本身
+		if s, err := structpb.NewStruct(meta); err == nil {
+			d.Metadata = s
+		}
+	}
+	return d
+}
+
+func violations(fields map[string]string) []*FieldViolation {
+	names := make([]string, 0, len(fields))
+	for f := range fields {
+		names = append(names, f)
+	}
+	sort.Strings(names)
+	out := make([]*FieldViolation, len(names))
+	for i, f := range names {
+		out[i] = &FieldViolation{Field: f, Description: fields[f]}
+	}
+	return out
+}
+
+// ToError 转换为 *errors.Error，violations 还原为元数据 field_errors
+func (x *ErrorDetail) ToError() *errors.Error {
+	if x == nil {
+		return nil
+	}
+	e := errors.New(errors.Code(x.GetCode()), x.GetMessage())
+	if m := x.GetMetadata().AsMap(); len(m) > 0 {
+		e.WithMetadata(m)
+	}
+	for _, v := range x.GetViolations() {
+		e.WithFieldError(v.GetField(), v.GetDescription())
+	}
+	if d := x.GetRetryAfter(); d != nil {
+		e.WithRetryAfter(d.AsDuration())
+	}
+	return e
+}
+
+// Status 转换为 gRPC Status：状态码与 RetryInfo 同 errors.ToGRPCStatus，并附带 ErrorDetail 详情，
+// 使对端可还原应用错误码与元数据
+func Status(err error) *status.Status {
+	st := errors.ToGRPCStatus(err)
+	d := FromError(err)
+	if d == nil {
+		return st
+	}
+	if withDetail, err := st.WithDetails(d); err == nil {
+		return withDetail
+	}
+	return st
+}
+
+// FromStatus 从 gRPC Status 还原错误：优先使用 ErrorDetail 详情，否则回退到 errors.FromGRPCStatus
+func FromStatus(st *status.Status) *errors.Error {
+	if st == nil {
+		return nil
+	}
+	for _, d := range st.Details() {
+		if detail, ok := d.(*ErrorDetail); ok {
+			return detail.ToError()
+		}
+	}
+	return errors.FromGRPCStatus(st)
+}
+
+// FromGRPCError 从 gRPC 调用返回的错误还原应用错误
+func FromGRPCError(err error) *errors.Error {
+	if err == nil {
+		return nil
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return errors.Wrap(err, errors.Unknown, err.Error())
+	}
+	return FromStatus(st)
+}
+
+// UnaryServerInterceptor 将 handler 返回的错误转换为附带 ErrorDetail 的 gRPC Status，
+// 已是 gRPC Status 的错误（无 *errors.Error）保持不变
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		var e *errors.Error
+		if err != nil && errors.As(err, &e) {
+			return resp, Status(err).Err()
+		}
+		return resp, err
+	}
+}
@@ -0,0 +1,47 @@
+package errorsv1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/mildsunup/higo/errors"
+)
+
+func TestStatus_RoundTrip(t *testing.T) {
+	src := errors.New(errors.ValidationFailed, "invalid order").
+		WithMeta("order_id", "o-1").
+		WithFieldError("items[0].sku", "required").
+		WithRetryAfter(2 * time.Second)
+
+	st := Status(src)
+	if st.Code() != codes.InvalidArgument {
+		t.Errorf("code = %v", st.Code())
+	}
+
+	got := FromGRPCError(st.Err())
+	fields, _ := got.GetMeta(errors.MetaFieldErrors).(map[string]string)
+	if got.Code() != errors.ValidationFailed || got.Message() != "invalid order" ||
+		got.GetMeta("order_id") != "o-1" || fields["items[0].sku"] != "required" || got.RetryAfter() != 2*time.Second {
+		t.Errorf("FromGRPCError() = %v %v %v", got.Code(), got.Metadata(), got.RetryAfter())
+	}
+
+	// 没有 ErrorDetail 时回退到 gRPC 状态码映射
+	if e := FromGRPCError(status.Error(codes.NotFound, "gone")); e.Code() != errors.NotFound {
+		t.Errorf("fallback code = %v", e.Code())
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	intercept := UnaryServerInterceptor()
+	_, err := intercept(context.Background(), nil, &grpc.UnaryServerInfo{}, func(context.Context, any) (any, error) {
+		return nil, errors.New(errors.InsufficientBalance, "balance too low")
+	})
+	if e := FromGRPCError(err); e.Code() != errors.InsufficientBalance {
+		t.Errorf("code = %v, want InsufficientBalance", e.Code())
+	}
+}
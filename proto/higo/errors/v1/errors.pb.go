@@ -0,0 +1,254 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: proto/higo/errors/v1/errors.proto
+
+package errorsv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ErrorDetail 应用错误详情
+type ErrorDetail struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 应用错误码（errors.Code），gRPC 状态码只能表达粗粒度分类
+	Code int32 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	// 错误消息
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// 错误元数据（errors.Error.Metadata）
+	Metadata *structpb.Struct `protobuf:"bytes,3,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	// 建议的重试等待时间
+	RetryAfter *durationpb.Duration `protobuf:"bytes,4,opt,name=retry_after,json=retryAfter,proto3" json:"retry_after,omitempty"`
+	// 字段级校验错误
+	Violations []*FieldViolation `protobuf:"bytes,5,rep,name=violations,proto3" json:"violations,omitempty"`
+	// 请求 ID
+	RequestId string `protobuf:"bytes,6,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	// 链路追踪 ID
+	TraceId       string `protobuf:"bytes,7,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ErrorDetail) Reset() {
+	*x = ErrorDetail{}
+	mi := &file_proto_higo_errors_v1_errors_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ErrorDetail) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ErrorDetail) ProtoMessage() {}
+
+func (x *ErrorDetail) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_higo_errors_v1_errors_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ErrorDetail.ProtoReflect.Descriptor instead.
+func (*ErrorDetail) Descriptor() ([]byte, []int) {
+	return file_proto_higo_errors_v1_errors_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ErrorDetail) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *ErrorDetail) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ErrorDetail) GetMetadata() *structpb.Struct {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *ErrorDetail) GetRetryAfter() *durationpb.Duration {
+	if x != nil {
+		return x.RetryAfter
+	}
+	return nil
+}
+
+func (x *ErrorDetail) GetViolations() []*FieldViolation {
+	if x != nil {
+		return x.Violations
+	}
+	return nil
+}
+
+func (x *ErrorDetail) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *ErrorDetail) GetTraceId() string {
+	if x != nil {
+		return x.TraceId
+	}
+	return ""
+}
+
+// FieldViolation 字段校验错误
+type FieldViolation struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 字段路径，如 "items[0].sku"
+	Field string `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	// 错误描述
+	Description   string `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FieldViolation) Reset() {
+	*x = FieldViolation{}
+	mi := &file_proto_higo_errors_v1_errors_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FieldViolation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FieldViolation) ProtoMessage() {}
+
+func (x *FieldViolation) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_higo_errors_v1_errors_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FieldViolation.ProtoReflect.Descriptor instead.
+func (*FieldViolation) Descriptor() ([]byte, []int) {
+	return file_proto_higo_errors_v1_errors_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *FieldViolation) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *FieldViolation) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+var File_proto_higo_errors_v1_errors_proto protoreflect.FileDescriptor
+
+const file_proto_higo_errors_v1_errors_proto_rawDesc = "" +
+	"\n" +
+	"!proto/higo/errors/v1/errors.proto\x12\x0ehigo.errors.v1\x1a\x1egoogle/protobuf/duration.proto\x1a\x1cgoogle/protobuf/struct.proto\"\xa6\x02\n" +
+	"\vErrorDetail\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x123\n" +
+	"\bmetadata\x18\x03 \x01(\v2\x17.google.protobuf.StructR\bmetadata\x12:\n" +
+	"\vretry_after\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\n" +
+	"retryAfter\x12>\n" +
+	"\n" +
+	"violations\x18\x05 \x03(\v2\x1e.higo.errors.v1.FieldViolationR\n" +
+	"violations\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x06 \x01(\tR\trequestId\x12\x19\n" +
+	"\btrace_id\x18\a \x01(\tR\atraceId\"H\n" +
+	"\x0eFieldViolation\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescriptionB9Z7github.com/mildsunup/higo/proto/higo/errors/v1;errorsv1b\x06proto3"
+
+var (
+	file_proto_higo_errors_v1_errors_proto_rawDescOnce sync.Once
+	file_proto_higo_errors_v1_errors_proto_rawDescData []byte
+)
+
+func file_proto_higo_errors_v1_errors_proto_rawDescGZIP() []byte {
+	file_proto_higo_errors_v1_errors_proto_rawDescOnce.Do(func() {
+		file_proto_higo_errors_v1_errors_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_higo_errors_v1_errors_proto_rawDesc), len(file_proto_higo_errors_v1_errors_proto_rawDesc)))
+	})
+	return file_proto_higo_errors_v1_errors_proto_rawDescData
+}
+
+var file_proto_higo_errors_v1_errors_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_proto_higo_errors_v1_errors_proto_goTypes = []any{
+	(*ErrorDetail)(nil),         // 0: higo.errors.v1.ErrorDetail
+	(*FieldViolation)(nil),      // 1: higo.errors.v1.FieldViolation
+	(*structpb.Struct)(nil),     // 2: google.protobuf.Struct
+	(*durationpb.Duration)(nil), // 3: google.protobuf.Duration
+}
+var file_proto_higo_errors_v1_errors_proto_depIdxs = []int32{
+	2, // 0: higo.errors.v1.ErrorDetail.metadata:type_name -> google.protobuf.Struct
+	3, // 1: higo.errors.v1.ErrorDetail.retry_after:type_name -> google.protobuf.Duration
+	1, // 2: higo.errors.v1.ErrorDetail.violations:type_name -> higo.errors.v1.FieldViolation
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_proto_higo_errors_v1_errors_proto_init() }
+func file_proto_higo_errors_v1_errors_proto_init() {
+	if File_proto_higo_errors_v1_errors_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_higo_errors_v1_errors_proto_rawDesc), len(file_proto_higo_errors_v1_errors_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_proto_higo_errors_v1_errors_proto_goTypes,
+		DependencyIndexes: file_proto_higo_errors_v1_errors_proto_depIdxs,
+		MessageInfos:      file_proto_higo_errors_v1_errors_proto_msgTypes,
+	}.Build()
+	File_proto_higo_errors_v1_errors_proto = out.File
+	file_proto_higo_errors_v1_errors_proto_goTypes = nil
+	file_proto_higo_errors_v1_errors_proto_depIdxs = nil
+}
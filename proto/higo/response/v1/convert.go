@@ -0,0 +1,41 @@
+package responsev1
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/mildsunup/higo/response"
+)
+
+// FromEnvelope 将统一信封转换为生成的 Envelope 消息，与 response.MarshalProto 的编码一致
+func FromEnvelope(e response.Envelope) (*Envelope, error) {
+	b, err := response.MarshalProto(e)
+	if err != nil {
+		return nil, err
+	}
+	out := &Envelope{}
+	if err := proto.Unmarshal(b, out); err != nil {
+		return nil, fmt.Errorf("responsev1: decode envelope: %w", err)
+	}
+	return out, nil
+}
+
+// FromResponse 将 response.Response 转换为 Envelope 消息
+func FromResponse[T any](r response.Response[T]) (*Envelope, error) {
+	return FromEnvelope(r.Envelope())
+}
+
+// FromPage 将 response.PageResponse 转换为 Envelope 消息
+func FromPage[T any](r response.PageResponse[T]) (*Envelope, error) {
+	return FromEnvelope(r.Envelope())
+}
+
+// ToEnvelope 转换为统一信封：Any 数据保留为 *anypb.Any，Value 数据还原为通用 Go 值
+func (x *Envelope) ToEnvelope() (response.Envelope, error) {
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(x)
+	if err != nil {
+		return response.Envelope{}, fmt.Errorf("responsev1: encode envelope: %w", err)
+	}
+	return response.UnmarshalProto(b)
+}
@@ -0,0 +1,27 @@
+package responsev1
+
+import (
+	"testing"
+
+	"github.com/mildsunup/higo/response"
+)
+
+func TestEnvelope_RoundTrip(t *testing.T) {
+	r := response.Page([]map[string]any{{"id": "a"}}, 11, 2, 10).WithRequestID("req-1")
+	msg, err := FromPage(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.GetPage().GetTotal() != 11 || msg.GetRequestId() != "req-1" || msg.GetValue() == nil {
+		t.Fatalf("FromPage() = %v", msg)
+	}
+
+	e, err := msg.ToEnvelope()
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, _ := e.Data.([]any)
+	if e.Page.Page != 2 || e.RequestID != "req-1" || len(items) != 1 {
+		t.Errorf("ToEnvelope() = %+v", e)
+	}
+}
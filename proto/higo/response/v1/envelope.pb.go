@@ -0,0 +1,400 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: proto/higo/response/v1/envelope.proto
+
+package responsev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	anypb "google.golang.org/protobuf/types/known/anypb"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Envelope 统一响应信封
+type Envelope struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Code    int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*Envelope_Data
+	//	*Envelope_Value
+	Payload       isEnvelope_Payload `protobuf_oneof:"payload"`
+	Details       *structpb.Struct   `protobuf:"bytes,5,opt,name=details,proto3" json:"details,omitempty"`
+	Page          *PageInfo          `protobuf:"bytes,6,opt,name=page,proto3" json:"page,omitempty"`
+	RequestId     string             `protobuf:"bytes,7,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	TraceId       string             `protobuf:"bytes,8,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"`
+	Links         *Links             `protobuf:"bytes,9,opt,name=links,proto3" json:"links,omitempty"`
+	Meta          *structpb.Struct   `protobuf:"bytes,10,opt,name=meta,proto3" json:"meta,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Envelope) Reset() {
+	*x = Envelope{}
+	mi := &file_proto_higo_response_v1_envelope_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Envelope) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Envelope) ProtoMessage() {}
+
+func (x *Envelope) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_higo_response_v1_envelope_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Envelope.ProtoReflect.Descriptor instead.
+func (*Envelope) Descriptor() ([]byte, []int) {
+	return file_proto_higo_response_v1_envelope_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Envelope) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *Envelope) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Envelope) GetPayload() isEnvelope_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *Envelope) GetData() *anypb.Any {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_Data); ok {
+			return x.Data
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetValue() *structpb.Value {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_Value); ok {
+			return x.Value
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetDetails() *structpb.Struct {
+	if x != nil {
+		return x.Details
+	}
+	return nil
+}
+
+func (x *Envelope) GetPage() *PageInfo {
+	if x != nil {
+		return x.Page
+	}
+	return nil
+}
+
+func (x *Envelope) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *Envelope) GetTraceId() string {
+	if x != nil {
+		return x.TraceId
+	}
+	return ""
+}
+
+func (x *Envelope) GetLinks() *Links {
+	if x != nil {
+		return x.Links
+	}
+	return nil
+}
+
+func (x *Envelope) GetMeta() *structpb.Struct {
+	if x != nil {
+		return x.Meta
+	}
+	return nil
+}
+
+type isEnvelope_Payload interface {
+	isEnvelope_Payload()
+}
+
+type Envelope_Data struct {
+	// 数据本身是 Protobuf 消息时使用 Any
+	Data *anypb.Any `protobuf:"bytes,3,opt,name=data,proto3,oneof"`
+}
+
+type Envelope_Value struct {
+	// 其他数据按 JSON 语义编码为 Value
+	Value *structpb.Value `protobuf:"bytes,4,opt,name=value,proto3,oneof"`
+}
+
+func (*Envelope_Data) isEnvelope_Payload() {}
+
+func (*Envelope_Value) isEnvelope_Payload() {}
+
+// Links 资源链接
+type Links struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Self          string                 `protobuf:"bytes,1,opt,name=self,proto3" json:"self,omitempty"`
+	Next          string                 `protobuf:"bytes,2,opt,name=next,proto3" json:"next,omitempty"`
+	Prev          string                 `protobuf:"bytes,3,opt,name=prev,proto3" json:"prev,omitempty"`
+	Related       map[string]string      `protobuf:"bytes,4,rep,name=related,proto3" json:"related,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Links) Reset() {
+	*x = Links{}
+	mi := &file_proto_higo_response_v1_envelope_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Links) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Links) ProtoMessage() {}
+
+func (x *Links) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_higo_response_v1_envelope_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Links.ProtoReflect.Descriptor instead.
+func (*Links) Descriptor() ([]byte, []int) {
+	return file_proto_higo_response_v1_envelope_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Links) GetSelf() string {
+	if x != nil {
+		return x.Self
+	}
+	return ""
+}
+
+func (x *Links) GetNext() string {
+	if x != nil {
+		return x.Next
+	}
+	return ""
+}
+
+func (x *Links) GetPrev() string {
+	if x != nil {
+		return x.Prev
+	}
+	return ""
+}
+
+func (x *Links) GetRelated() map[string]string {
+	if x != nil {
+		return x.Related
+	}
+	return nil
+}
+
+// PageInfo 分页信息
+type PageInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Total         int64                  `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
+	Page          int32                  `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32                  `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PageInfo) Reset() {
+	*x = PageInfo{}
+	mi := &file_proto_higo_response_v1_envelope_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PageInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PageInfo) ProtoMessage() {}
+
+func (x *PageInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_higo_response_v1_envelope_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PageInfo.ProtoReflect.Descriptor instead.
+func (*PageInfo) Descriptor() ([]byte, []int) {
+	return file_proto_higo_response_v1_envelope_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PageInfo) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *PageInfo) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *PageInfo) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+var File_proto_higo_response_v1_envelope_proto protoreflect.FileDescriptor
+
+const file_proto_higo_response_v1_envelope_proto_rawDesc = "" +
+	"\n" +
+	"%proto/higo/response/v1/envelope.proto\x12\x10higo.response.v1\x1a\x19google/protobuf/any.proto\x1a\x1cgoogle/protobuf/struct.proto\"\x98\x03\n" +
+	"\bEnvelope\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12*\n" +
+	"\x04data\x18\x03 \x01(\v2\x14.google.protobuf.AnyH\x00R\x04data\x12.\n" +
+	"\x05value\x18\x04 \x01(\v2\x16.google.protobuf.ValueH\x00R\x05value\x121\n" +
+	"\adetails\x18\x05 \x01(\v2\x17.google.protobuf.StructR\adetails\x12.\n" +
+	"\x04page\x18\x06 \x01(\v2\x1a.higo.response.v1.PageInfoR\x04page\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\a \x01(\tR\trequestId\x12\x19\n" +
+	"\btrace_id\x18\b \x01(\tR\atraceId\x12-\n" +
+	"\x05links\x18\t \x01(\v2\x17.higo.response.v1.LinksR\x05links\x12+\n" +
+	"\x04meta\x18\n" +
+	" \x01(\v2\x17.google.protobuf.StructR\x04metaB\t\n" +
+	"\apayload\"\xbf\x01\n" +
+	"\x05Links\x12\x12\n" +
+	"\x04self\x18\x01 \x01(\tR\x04self\x12\x12\n" +
+	"\x04next\x18\x02 \x01(\tR\x04next\x12\x12\n" +
+	"\x04prev\x18\x03 \x01(\tR\x04prev\x12>\n" +
+	"\arelated\x18\x04 \x03(\v2$.higo.response.v1.Links.RelatedEntryR\arelated\x1a:\n" +
+	"\fRelatedEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"Q\n" +
+	"\bPageInfo\x12\x14\n" +
+	"\x05total\x18\x01 \x01(\x03R\x05total\x12\x12\n" +
+	"\x04page\x18\x02 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\x05R\bpageSizeB=Z;github.com/mildsunup/higo/proto/higo/response/v1;responsev1b\x06proto3"
+
+var (
+	file_proto_higo_response_v1_envelope_proto_rawDescOnce sync.Once
+	file_proto_higo_response_v1_envelope_proto_rawDescData []byte
+)
+
+func file_proto_higo_response_v1_envelope_proto_rawDescGZIP() []byte {
+	file_proto_higo_response_v1_envelope_proto_rawDescOnce.Do(func() {
+		file_proto_higo_response_v1_envelope_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_higo_response_v1_envelope_proto_rawDesc), len(file_proto_higo_response_v1_envelope_proto_rawDesc)))
+	})
+	return file_proto_higo_response_v1_envelope_proto_rawDescData
+}
+
+var file_proto_higo_response_v1_envelope_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_proto_higo_response_v1_envelope_proto_goTypes = []any{
+	(*Envelope)(nil),        // 0: higo.response.v1.Envelope
+	(*Links)(nil),           // 1: higo.response.v1.Links
+	(*PageInfo)(nil),        // 2: higo.response.v1.PageInfo
+	nil,                     // 3: higo.response.v1.Links.RelatedEntry
+	(*anypb.Any)(nil),       // 4: google.protobuf.Any
+	(*structpb.Value)(nil),  // 5: google.protobuf.Value
+	(*structpb.Struct)(nil), // 6: google.protobuf.Struct
+}
+var file_proto_higo_response_v1_envelope_proto_depIdxs = []int32{
+	4, // 0: higo.response.v1.Envelope.data:type_name -> google.protobuf.Any
+	5, // 1: higo.response.v1.Envelope.value:type_name -> google.protobuf.Value
+	6, // 2: higo.response.v1.Envelope.details:type_name -> google.protobuf.Struct
+	2, // 3: higo.response.v1.Envelope.page:type_name -> higo.response.v1.PageInfo
+	1, // 4: higo.response.v1.Envelope.links:type_name -> higo.response.v1.Links
+	6, // 5: higo.response.v1.Envelope.meta:type_name -> google.protobuf.Struct
+	3, // 6: higo.response.v1.Links.related:type_name -> higo.response.v1.Links.RelatedEntry
+	7, // [7:7] is the sub-list for method output_type
+	7, // [7:7] is the sub-list for method input_type
+	7, // [7:7] is the sub-list for extension type_name
+	7, // [7:7] is the sub-list for extension extendee
+	0, // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_proto_higo_response_v1_envelope_proto_init() }
+func file_proto_higo_response_v1_envelope_proto_init() {
+	if File_proto_higo_response_v1_envelope_proto != nil {
+		return
+	}
+	file_proto_higo_response_v1_envelope_proto_msgTypes[0].OneofWrappers = []any{
+		(*Envelope_Data)(nil),
+		(*Envelope_Value)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_higo_response_v1_envelope_proto_rawDesc), len(file_proto_higo_response_v1_envelope_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_proto_higo_response_v1_envelope_proto_goTypes,
+		DependencyIndexes: file_proto_higo_response_v1_envelope_proto_depIdxs,
+		MessageInfos:      file_proto_higo_response_v1_envelope_proto_msgTypes,
+	}.Build()
+	File_proto_higo_response_v1_envelope_proto = out.File
+	file_proto_higo_response_v1_envelope_proto_goTypes = nil
+	file_proto_higo_response_v1_envelope_proto_depIdxs = nil
+}
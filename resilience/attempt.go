@@ -0,0 +1,66 @@
+package resilience
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// HeaderAttempt 出站请求中携带重试次数的 header
+const HeaderAttempt = "X-Attempt"
+
+// Attempt 当前重试信息
+type Attempt struct {
+	Number  int           // 第几次尝试，从 1 开始
+	Backoff time.Duration // 本次尝试前的退避时长
+}
+
+// IsRetry 是否为重试（非首次尝试）
+func (a Attempt) IsRetry() bool {
+	return a.Number > 1
+}
+
+type attemptKey struct{}
+
+// WithAttempt 将重试信息写入 context，并设置到当前 Span
+func WithAttempt(ctx context.Context, a Attempt) context.Context {
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.Int("retry.attempt", a.Number),
+		attribute.Int64("retry.backoff_ms", a.Backoff.Milliseconds()),
+	)
+	return context.WithValue(ctx, attemptKey{}, a)
+}
+
+// AttemptFromContext 从 context 获取重试信息
+func AttemptFromContext(ctx context.Context) (Attempt, bool) {
+	a, ok := ctx.Value(attemptKey{}).(Attempt)
+	return a, ok
+}
+
+// AttemptTransport 为出站 HTTP 请求注入 X-Attempt header
+type AttemptTransport struct {
+	Base http.RoundTripper
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *AttemptTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	a, ok := AttemptFromContext(req.Context())
+	if !ok {
+		return base.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set(HeaderAttempt, strconv.Itoa(a.Number))
+	return base.RoundTrip(req)
+}
+
+var _ http.RoundTripper = (*AttemptTransport)(nil)
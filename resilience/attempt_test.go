@@ -0,0 +1,92 @@
+package resilience
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRetry_AttemptContext(t *testing.T) {
+	r := NewRetry(WithMaxAttempts(3), WithDelay(time.Millisecond), WithMultiplier(2))
+
+	var got []Attempt
+	_ = r.Execute(context.Background(), func(ctx context.Context) error {
+		a, ok := AttemptFromContext(ctx)
+		if !ok {
+			t.Fatal("attempt missing from context")
+		}
+		got = append(got, a)
+		return errBoom
+	})
+
+	want := []Attempt{{1, 0}, {2, time.Millisecond}, {3, 2 * time.Millisecond}}
+	if len(got) != len(want) {
+		t.Fatalf("attempts = %+v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("attempt %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if got[0].IsRetry() || !got[1].IsRetry() {
+		t.Fatalf("IsRetry = %v, %v", got[0].IsRetry(), got[1].IsRetry())
+	}
+	if _, ok := AttemptFromContext(context.Background()); ok {
+		t.Fatal("attempt found in empty context")
+	}
+}
+
+func TestWithAttempt_SpanAttributes(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	ctx, span := tp.Tracer("test").Start(context.Background(), "call")
+	WithAttempt(ctx, Attempt{Number: 2, Backoff: 150 * time.Millisecond})
+	span.End()
+
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range rec.Ended()[0].Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	if attrs["retry.attempt"].AsInt64() != 2 || attrs["retry.backoff_ms"].AsInt64() != 150 {
+		t.Fatalf("attributes = %v", attrs)
+	}
+}
+
+func TestAttemptTransport(t *testing.T) {
+	var header string
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get(HeaderAttempt)
+	}))
+	defer srv.Close()
+	client := &http.Client{Transport: &AttemptTransport{}}
+
+	do := func(ctx context.Context) *http.Request {
+		t.Helper()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		return req
+	}
+
+	if do(context.Background()); header != "" {
+		t.Fatalf("header without attempt = %q", header)
+	}
+
+	req := do(WithAttempt(context.Background(), Attempt{Number: 3}))
+	if header != "3" {
+		t.Fatalf("header = %q, want 3", header)
+	}
+	// RoundTripper 不修改调用方的请求
+	if req.Header.Get(HeaderAttempt) != "" {
+		t.Fatal("caller request mutated")
+	}
+}
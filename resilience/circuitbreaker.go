@@ -53,6 +53,7 @@ type CircuitBreaker struct {
 	failures    atomic.Int32
 	successes   atomic.Int32
 	lastFailure atomic.Int64
+	forced      atomic.Int32 // 强制状态，0 表示未强制
 	mu          sync.Mutex
 }
 
@@ -110,6 +111,41 @@ func (cb *CircuitBreaker) State() CircuitState {
 	return CircuitState(cb.state.Load())
 }
 
+// RetryAfter 熔断打开时距离进入半开状态的剩余时间，其他状态返回 0
+func (cb *CircuitBreaker) RetryAfter() time.Duration {
+	if cb.State() != CircuitOpen {
+		return 0
+	}
+	return max(cb.cfg.Timeout-time.Duration(time.Now().UnixNano()-cb.lastFailure.Load()), 0)
+}
+
+// ForceOpen 强制打开熔断，拒绝全部请求直到 ForceClose 或 Reset
+func (cb *CircuitBreaker) ForceOpen() {
+	cb.forced.Store(int32(CircuitOpen) + 1)
+	cb.transition(CircuitOpen)
+}
+
+// ForceClose 强制关闭熔断，放行全部请求且失败不再触发熔断，直到 ForceOpen 或 Reset
+func (cb *CircuitBreaker) ForceClose() {
+	cb.forced.Store(int32(CircuitClosed) + 1)
+	cb.transition(CircuitClosed)
+}
+
+// Reset 取消强制状态并恢复为关闭，重新按失败阈值自动熔断
+func (cb *CircuitBreaker) Reset() {
+	cb.forced.Store(0)
+	cb.transition(CircuitClosed)
+}
+
+// Forced 返回强制状态，未强制时 ok 为 false
+func (cb *CircuitBreaker) Forced() (state CircuitState, ok bool) {
+	f := cb.forced.Load()
+	if f == 0 {
+		return 0, false
+	}
+	return CircuitState(f - 1), true
+}
+
 // Execute 执行函数
 func (cb *CircuitBreaker) Execute(ctx context.Context, fn func(context.Context) error) error {
 	if err := cb.allow(); err != nil {
@@ -128,6 +164,12 @@ func (cb *CircuitBreaker) Execute(ctx context.Context, fn func(context.Context)
 }
 
 func (cb *CircuitBreaker) allow() error {
+	if state, ok := cb.Forced(); ok {
+		if state == CircuitOpen {
+			return ErrCircuitOpen
+		}
+		return nil
+	}
 	state := cb.State()
 
 	switch state {
@@ -146,6 +188,9 @@ func (cb *CircuitBreaker) allow() error {
 }
 
 func (cb *CircuitBreaker) recordFailure() {
+	if _, ok := cb.Forced(); ok {
+		return
+	}
 	state := cb.State()
 	switch state {
 	case CircuitClosed:
@@ -158,6 +203,9 @@ func (cb *CircuitBreaker) recordFailure() {
 }
 
 func (cb *CircuitBreaker) recordSuccess() {
+	if _, ok := cb.Forced(); ok {
+		return
+	}
 	state := cb.State()
 	switch state {
 	case CircuitClosed:
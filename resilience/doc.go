@@ -2,7 +2,7 @@
 //
 // 核心功能：
 //   - 熔断器（Circuit Breaker）
-//   - 重试策略（Retry）
+//   - 重试策略（Retry），重试次数写入 context 并透传到日志、Span 与出站请求
 //   - 限流器（Rate Limiter）
 //
 // 使用示例：
@@ -72,4 +72,21 @@ func (tb *TokenBucket) Tokens() float64 {
 	return tb.tokens
 }
 
+// Limit 返回当前速率与桶容量
+func (tb *TokenBucket) Limit() (rate float64, burst int) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	return tb.rate, int(tb.burst)
+}
+
+// SetLimit 运行时调整速率与桶容量，已有令牌按新容量截断
+func (tb *TokenBucket) SetLimit(rate float64, burst int) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.refill()
+	tb.rate = rate
+	tb.burst = float64(burst)
+	tb.tokens = min(tb.tokens, tb.burst)
+}
+
 var _ RateLimiter = (*TokenBucket)(nil)
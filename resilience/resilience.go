@@ -48,6 +48,7 @@ type RetryConfig struct {
 	Delay       time.Duration
 	MaxDelay    time.Duration
 	Multiplier  float64
+	Jitter      float64 // 随机抖动比例 [0, 1]，0 表示不抖动
 	RetryIf     func(error) bool
 }
 
@@ -2,6 +2,8 @@ package resilience
 
 import (
 	"context"
+	"fmt"
+	"math/rand/v2"
 	"time"
 )
 
@@ -41,6 +43,13 @@ func WithMultiplier(multiplier float64) RetryOption {
 	}
 }
 
+// WithJitter 设置随机抖动比例，实际延迟在 delay*(1±jitter) 之间
+func WithJitter(jitter float64) RetryOption {
+	return func(r *Retry) {
+		r.cfg.Jitter = min(max(jitter, 0), 1)
+	}
+}
+
 // WithRetryIf 设置重试条件
 func WithRetryIf(fn func(error) bool) RetryOption {
 	return func(r *Retry) {
@@ -69,24 +78,38 @@ func NewRetry(opts ...RetryOption) *Retry {
 
 func (r *Retry) Execute(ctx context.Context, fn func(context.Context) error) error {
 	var lastErr error
+	var backoff, elapsed time.Duration
 	delay := r.cfg.Delay
 
 	for attempt := 0; attempt < r.cfg.MaxAttempts; attempt++ {
-		if err := fn(ctx); err == nil {
+		actx := WithAttempt(ctx, Attempt{Number: attempt + 1, Backoff: backoff})
+		start := time.Now()
+		err := fn(actx)
+		elapsed += time.Since(start)
+		if err == nil {
 			return nil
-		} else {
-			lastErr = err
-			if !r.cfg.RetryIf(err) {
-				return err
-			}
+		}
+		lastErr = err
+		if !r.cfg.RetryIf(err) {
+			return err
 		}
 
 		if attempt < r.cfg.MaxAttempts-1 {
+			wait := r.jittered(delay)
+
+			// 剩余时间不足以完成退避和下一次尝试时提前放弃
+			expected := elapsed / time.Duration(attempt+1)
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait+expected {
+				return fmt.Errorf("resilience: retry aborted after %d attempts, deadline too close: %w: %w",
+					attempt+1, context.DeadlineExceeded, lastErr)
+			}
+
 			select {
 			case <-ctx.Done():
 				return ctx.Err()
-			case <-time.After(delay):
+			case <-time.After(wait):
 			}
+			backoff = wait
 
 			delay = time.Duration(float64(delay) * r.cfg.Multiplier)
 			if delay > r.cfg.MaxDelay {
@@ -98,4 +121,11 @@ func (r *Retry) Execute(ctx context.Context, fn func(context.Context) error) err
 	return lastErr
 }
 
+func (r *Retry) jittered(d time.Duration) time.Duration {
+	if r.cfg.Jitter <= 0 || d <= 0 {
+		return d
+	}
+	return time.Duration(float64(d) * (1 + r.cfg.Jitter*(2*rand.Float64()-1)))
+}
+
 var _ Executor = (*Retry)(nil)
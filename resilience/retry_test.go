@@ -0,0 +1,96 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var errBoom = errors.New("boom")
+
+func TestRetry_AbortsBeforeDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+	r := NewRetry(WithMaxAttempts(5), WithDelay(100*time.Millisecond), WithMultiplier(2))
+
+	// 第二次失败后退避 200ms 超出剩余预算，不等到截止时间即放弃
+	attempts, start := 0, time.Now()
+	err := r.Execute(ctx, func(context.Context) error {
+		attempts++
+		return errBoom
+	})
+	if attempts != 2 {
+		t.Fatalf("attempts = %d, want 2", attempts)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, errBoom) {
+		t.Fatalf("err = %v", err)
+	}
+	if !strings.Contains(err.Error(), "after 2 attempts") {
+		t.Fatalf("err = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= 150*time.Millisecond || ctx.Err() != nil {
+		t.Fatalf("aborted after %v, ctx err = %v", elapsed, ctx.Err())
+	}
+}
+
+func TestRetry_AbortAccountsForAttemptDuration(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	r := NewRetry(WithMaxAttempts(5), WithDelay(10*time.Millisecond), WithMultiplier(1))
+
+	// 退避本身足够，但按已观测的单次耗时下一次尝试无法在截止前完成
+	attempts := 0
+	err := r.Execute(ctx, func(context.Context) error {
+		attempts++
+		time.Sleep(80 * time.Millisecond)
+		return errBoom
+	})
+	if attempts != 2 || !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, errBoom) {
+		t.Fatalf("attempts = %d, err = %v", attempts, err)
+	}
+}
+
+func TestRetry_WithoutDeadline(t *testing.T) {
+	r := NewRetry(WithMaxAttempts(3), WithDelay(time.Millisecond))
+	attempts := 0
+	err := r.Execute(context.Background(), func(context.Context) error {
+		attempts++
+		return errBoom
+	})
+	if attempts != 3 || err != errBoom {
+		t.Fatalf("attempts = %d, err = %v", attempts, err)
+	}
+}
+
+func TestRetry_RetryIf(t *testing.T) {
+	permanent := errors.New("permanent")
+	r := NewRetry(WithMaxAttempts(5), WithDelay(time.Millisecond), WithRetryIf(func(err error) bool {
+		return !errors.Is(err, permanent)
+	}))
+
+	attempts := 0
+	err := r.Execute(context.Background(), func(context.Context) error {
+		attempts++
+		if attempts == 2 {
+			return permanent
+		}
+		return errBoom
+	})
+	if attempts != 2 || err != permanent {
+		t.Fatalf("attempts = %d, err = %v", attempts, err)
+	}
+}
+
+func TestRetry_ContextCanceledDuringBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := NewRetry(WithMaxAttempts(3), WithDelay(time.Hour))
+	err := r.Execute(ctx, func(context.Context) error {
+		cancel()
+		return errBoom
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v", err)
+	}
+}
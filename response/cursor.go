@@ -0,0 +1,149 @@
+package response
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/mildsunup/higo/errors"
+)
+
+// Cursor 游标内容：边界行的排序键与生成游标时的过滤条件
+type Cursor struct {
+	Keys     map[string]any `json:"k"`           // 排序键（列名 -> 边界值）
+	Filters  map[string]any `json:"f,omitempty"` // 过滤条件，翻页时必须保持一致
+	Backward bool           `json:"b,omitempty"` // 是否向前翻页
+}
+
+// CursorCodec 游标编解码器，使用 HMAC-SHA256 签名防篡改
+type CursorCodec struct {
+	secret []byte
+}
+
+// NewCursorCodec 创建游标编解码器
+func NewCursorCodec(secret []byte) *CursorCodec {
+	return &CursorCodec{secret: secret}
+}
+
+// Encode 将游标编码为不透明令牌
+func (c *CursorCodec) Encode(cur Cursor) (string, error) {
+	payload, err := json.Marshal(cur)
+	if err != nil {
+		return "", errors.Wrap(err, errors.Internal, "encode cursor")
+	}
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(c.sign(payload)), nil
+}
+
+// Decode 校验并解码令牌，filters 与游标中的过滤条件不一致时视为无效
+// 数值以 json.Number 返回，避免大整数精度丢失。
+func (c *CursorCodec) Decode(token string, filters map[string]any) (Cursor, error) {
+	var cur Cursor
+
+	body, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return cur, invalidCursor()
+	}
+	enc := base64.RawURLEncoding
+	payload, err := enc.DecodeString(body)
+	if err != nil {
+		return cur, invalidCursor()
+	}
+	mac, err := enc.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, c.sign(payload)) {
+		return cur, invalidCursor()
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+	if err := dec.Decode(&cur); err != nil {
+		return cur, invalidCursor()
+	}
+
+	if !sameFilters(cur.Filters, filters) {
+		return cur, errors.ErrInvalidArgument("cursor does not match filters")
+	}
+	return cur, nil
+}
+
+// Cursors 根据当前页数据生成下一页与上一页游标
+// next 基于最后一行，prev 基于第一行；数据为空时均为空字符串。
+func Cursors[T any](c *CursorCodec, rows []T, keys func(T) map[string]any, filters map[string]any) (next, prev string, err error) {
+	if len(rows) == 0 {
+		return "", "", nil
+	}
+	next, err = c.Encode(Cursor{Keys: keys(rows[len(rows)-1]), Filters: filters})
+	if err != nil {
+		return "", "", err
+	}
+	prev, err = c.Encode(Cursor{Keys: keys(rows[0]), Filters: filters, Backward: true})
+	if err != nil {
+		return "", "", err
+	}
+	return next, prev, nil
+}
+
+func (c *CursorCodec) sign(payload []byte) []byte {
+	h := hmac.New(sha256.New, c.secret)
+	h.Write(payload)
+	return h.Sum(nil)
+}
+
+func invalidCursor() *errors.Error {
+	return errors.ErrInvalidArgument("invalid cursor")
+}
+
+// sameFilters 比较过滤条件，统一经 JSON 规范化以消除数值类型差异
+func sameFilters(a, b map[string]any) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	ja, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	jb, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(ja, jb)
+}
+
+// CursorPageResponse 游标分页响应
+type CursorPageResponse[T any] struct {
+	Code       int            `json:"code"`
+	Message    string         `json:"message"`
+	Data       T              `json:"data"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+	PrevCursor string         `json:"prev_cursor,omitempty"`
+	Links      *Links         `json:"links,omitempty"`
+	Meta       map[string]any `json:"meta,omitempty"`
+	RequestID  string         `json:"request_id,omitempty"`
+	TraceID    string         `json:"trace_id,omitempty"`
+}
+
+// CursorPage 创建游标分页响应
+func CursorPage[T any](data T, next, prev string) CursorPageResponse[T] {
+	return CursorPageResponse[T]{
+		Code:       0,
+		Message:    "ok",
+		Data:       data,
+		NextCursor: next,
+		PrevCursor: prev,
+	}
+}
+
+// WithRequestID 设置请求 ID（游标分页）
+func (r CursorPageResponse[T]) WithRequestID(id string) CursorPageResponse[T] {
+	r.RequestID = id
+	return r
+}
+
+// WithTraceID 设置追踪 ID（游标分页）
+func (r CursorPageResponse[T]) WithTraceID(id string) CursorPageResponse[T] {
+	r.TraceID = id
+	return r
+}
@@ -3,7 +3,15 @@
 // 核心功能：
 //   - 泛型响应结构（类型安全）
 //   - 分页响应
+//   - 超媒体链接（WithLink：self/next/prev/关联关系）与扩展元数据（WithMeta）
 //   - 框架无关（纯数据结构）
+//   - 字段投影（?fields=id,name 或 JSON Pointer /profile/avatar），按类型与端点（FieldSet）白名单过滤
+//   - 签名游标分页（篡改或过滤条件变化时返回 InvalidArgument）
+//   - RFC 7807 问题详情（application/problem+json 与 application/problem+xml），按 Accept 协商
+//   - 信封多格式编码：JSON、Protobuf（proto/higo/response/v1/envelope.proto）、XML、MessagePack，按 Accept 协商（WriteEnvelope），
+//     RegisterEncoder 可注册其他格式；错误响应与成功响应使用同一协商结果
+//   - 流式响应：SSE（text/event-stream）与 NDJSON，支持心跳、刷新控制与取消
+//   - render 子包：render.JSON(c, data, err) 一行完成错误映射与响应写出
 //
 // 使用示例：
 //
@@ -15,7 +23,24 @@
 //	resp := response.Page(users, 100, 1, 20)
 //	c.JSON(200, resp)
 //
+//	// 游标分页
+//	codec := response.NewCursorCodec(secret)
+//	cur, err := codec.Decode(c.Query("cursor"), filters)
+//	next, prev, err := response.Cursors(codec, rows, func(u User) map[string]any {
+//	    return map[string]any{"id": u.ID}
+//	}, filters)
+//	c.JSON(200, response.CursorPage(rows, next, prev))
+//
 //	// 带追踪信息
 //	resp := response.OK(data).WithRequestID(id).WithTraceID(traceID)
 //	c.JSON(200, resp)
+//
+//	// 错误响应（协商统一信封或 problem+json）
+//	response.WriteError(w, r, err)
+//	c.JSON(404, response.Problem(err, response.WithProblemTypeBase("https://errors.example.com")))
+//
+//	// 流式推送
+//	sse, err := response.SSE(w, r)
+//	defer sse.Close()
+//	err = sse.Send(response.Event{Event: "delta", Data: chunk})
 package response
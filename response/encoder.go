@@ -0,0 +1,130 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"sync"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// FormatMsgPack MessagePack 编码
+const FormatMsgPack Format = "application/msgpack"
+
+// Encoder 信封编码器
+type Encoder interface {
+	// ContentType 响应的 Content-Type
+	ContentType() string
+	// Marshal 编码信封
+	Marshal(env Envelope) ([]byte, error)
+}
+
+type encoderFunc struct {
+	contentType string
+	marshal     func(Envelope) ([]byte, error)
+}
+
+func (e encoderFunc) ContentType() string                  { return e.contentType }
+func (e encoderFunc) Marshal(env Envelope) ([]byte, error) { return e.marshal(env) }
+
+// NewEncoder 由函数创建编码器
+func NewEncoder(contentType string, marshal func(Envelope) ([]byte, error)) Encoder {
+	return encoderFunc{contentType: contentType, marshal: marshal}
+}
+
+// encoders 已注册的编码器与 Accept 中可识别的媒体类型
+var encoders = struct {
+	sync.RWMutex
+	byFormat map[Format]Encoder
+	aliases  map[string]Format
+}{
+	byFormat: make(map[Format]Encoder),
+	aliases:  make(map[string]Format),
+}
+
+// RegisterEncoder 注册（或替换）编码器，Accept 中的 f 与 aliases 均协商到该编码器：
+//
+//	response.RegisterEncoder("application/yaml", response.NewEncoder("application/yaml", marshalYAML), "text/yaml")
+//
+// 应在初始化阶段调用。JSON 为兜底格式，其他编码器编码失败时退回 JSON。
+func RegisterEncoder(f Format, enc Encoder, aliases ...string) {
+	encoders.Lock()
+	defer encoders.Unlock()
+	encoders.byFormat[f] = enc
+	encoders.aliases[string(f)] = f
+	for _, a := range aliases {
+		encoders.aliases[a] = f
+	}
+}
+
+// lookupFormat 返回媒体类型对应的格式
+func lookupFormat(mediaType string) (Format, bool) {
+	encoders.RLock()
+	defer encoders.RUnlock()
+	f, ok := encoders.aliases[mediaType]
+	return f, ok
+}
+
+// lookupEncoder 返回格式对应的编码器
+func lookupEncoder(f Format) (Encoder, bool) {
+	encoders.RLock()
+	defer encoders.RUnlock()
+	enc, ok := encoders.byFormat[f]
+	return enc, ok
+}
+
+func init() {
+	RegisterEncoder(FormatJSON, NewEncoder("application/json; charset=utf-8", func(env Envelope) ([]byte, error) {
+		return json.Marshal(env)
+	}))
+	RegisterEncoder(FormatProtobuf, NewEncoder(string(FormatProtobuf), MarshalProto), "application/protobuf")
+	RegisterEncoder(FormatXML, NewEncoder("application/xml; charset=utf-8", func(env Envelope) ([]byte, error) {
+		body, err := xml.Marshal(env)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(xml.Header), body...), nil
+	}), "text/xml")
+	RegisterEncoder(FormatMsgPack, NewEncoder(string(FormatMsgPack), MarshalMsgPack),
+		"application/x-msgpack", "application/vnd.msgpack")
+}
+
+// MarshalMsgPack 将信封编码为 MessagePack，字段名与 JSON 一致
+func MarshalMsgPack(env Envelope) ([]byte, error) {
+	generic, err := toGeneric(env)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetSortMapKeys(true)
+	if err := enc.Encode(msgpackValue(generic)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// msgpackValue 将 json.Number 转为整数或浮点数，避免被编码为字符串
+func msgpackValue(v any) any {
+	switch val := v.(type) {
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i
+		}
+		f, _ := val.Float64()
+		return f
+	case map[string]any:
+		for k, item := range val {
+			val[k] = msgpackValue(item)
+		}
+		return val
+	case []any:
+		for i, item := range val {
+			val[i] = msgpackValue(item)
+		}
+		return val
+	default:
+		return v
+	}
+}
@@ -0,0 +1,250 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Format 响应编码格式
+type Format string
+
+const (
+	FormatJSON     Format = "application/json"
+	FormatProtobuf Format = "application/x-protobuf"
+	FormatXML      Format = "application/xml"
+)
+
+// Envelope 与编码无关的统一信封，可编码为 JSON、Protobuf（见 proto/higo/response/v1/envelope.proto）、XML 与 MessagePack
+type Envelope struct {
+	Code      int
+	Message   string
+	Data      any
+	Details   map[string]any
+	Page      *PageInfo
+	Links     *Links
+	Meta      map[string]any
+	RequestID string
+	TraceID   string
+}
+
+// PageInfo 分页信息
+type PageInfo struct {
+	Total    int64 `json:"total" xml:"total"`
+	Page     int   `json:"page" xml:"page"`
+	PageSize int   `json:"page_size" xml:"page_size"`
+}
+
+// Envelope 转换为统一信封
+func (r Response[T]) Envelope() Envelope {
+	return Envelope{
+		Code:      r.Code,
+		Message:   r.Message,
+		Data:      r.Data,
+		Links:     r.Links,
+		Meta:      r.Meta,
+		RequestID: r.RequestID,
+		TraceID:   r.TraceID,
+	}
+}
+
+// Envelope 转换为统一信封（分页）
+func (r PageResponse[T]) Envelope() Envelope {
+	return Envelope{
+		Code:      r.Code,
+		Message:   r.Message,
+		Data:      r.Data,
+		Page:      &PageInfo{Total: r.Total, Page: r.Page, PageSize: r.PageSize},
+		Links:     r.Links,
+		Meta:      r.Meta,
+		RequestID: r.RequestID,
+		TraceID:   r.TraceID,
+	}
+}
+
+// MarshalJSON 与 Response / PageResponse 的 JSON 结构保持一致
+func (e Envelope) MarshalJSON() ([]byte, error) {
+	type tail struct {
+		Links     *Links         `json:"links,omitempty"`
+		Meta      map[string]any `json:"meta,omitempty"`
+		Details   map[string]any `json:"details,omitempty"`
+		RequestID string         `json:"request_id,omitempty"`
+		TraceID   string         `json:"trace_id,omitempty"`
+	}
+	t := tail{e.Links, e.Meta, e.Details, e.RequestID, e.TraceID}
+	if e.Page != nil {
+		return json.Marshal(struct {
+			Code     int    `json:"code"`
+			Message  string `json:"message"`
+			Data     any    `json:"data"`
+			Total    int64  `json:"total"`
+			Page     int    `json:"page"`
+			PageSize int    `json:"page_size"`
+			tail
+		}{e.Code, e.Message, e.Data, e.Page.Total, e.Page.Page, e.Page.PageSize, t})
+	}
+	return json.Marshal(struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Data    any    `json:"data,omitempty"`
+		tail
+	}{e.Code, e.Message, e.Data, t})
+}
+
+// MarshalXML 编码为 <response>，数据按 JSON 字段名展开，切片元素为 <item>
+func (e Envelope) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Local: "response"}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	fields := []struct {
+		name  string
+		value any
+		skip  bool
+	}{
+		{"code", e.Code, false},
+		{"message", e.Message, false},
+		{"data", e.Data, e.Data == nil},
+		{"details", e.Details, len(e.Details) == 0},
+		{"page", e.Page, e.Page == nil},
+		{"links", e.Links, e.Links == nil},
+		{"meta", e.Meta, len(e.Meta) == 0},
+		{"request_id", e.RequestID, e.RequestID == ""},
+		{"trace_id", e.TraceID, e.TraceID == ""},
+	}
+	for _, f := range fields {
+		if f.skip {
+			continue
+		}
+		generic, err := toGeneric(f.value)
+		if err != nil {
+			return err
+		}
+		if err := encodeXMLValue(enc, f.name, generic); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// toGeneric 经 JSON 转为通用结构，使各编码格式的字段名一致
+func toGeneric(v any) (any, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("response: encode data: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var out any
+	if err := dec.Decode(&out); err != nil {
+		return nil, fmt.Errorf("response: decode data: %w", err)
+	}
+	return out, nil
+}
+
+func encodeXMLValue(enc *xml.Encoder, name string, v any) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if !validXMLName(name) {
+		start = xml.StartElement{
+			Name: xml.Name{Local: "entry"},
+			Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: name}},
+		}
+	}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+
+	switch val := v.(type) {
+	case nil:
+	case map[string]any:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := encodeXMLValue(enc, k, val[k]); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, item := range val {
+			if err := encodeXMLValue(enc, "item", item); err != nil {
+				return err
+			}
+		}
+	default:
+		if err := enc.EncodeToken(xml.CharData(fmt.Sprint(val))); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+func validXMLName(s string) bool {
+	if s == "" || strings.HasPrefix(strings.ToLower(s), "xml") {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z':
+		case i > 0 && (r == '-' || r == '.' || r >= '0' && r <= '9'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// NegotiateFormat 按 Accept 及 q 值在已注册的编码格式中选择，无匹配时返回 JSON
+func NegotiateFormat(r *http.Request) Format {
+	best, bestQ := FormatJSON, 0.0
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		f, ok := lookupFormat(mediaType)
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if s, ok := params["q"]; ok {
+			if v, err := strconv.ParseFloat(s, 64); err == nil {
+				q = v
+			}
+		}
+		if q > bestQ {
+			best, bestQ = f, q
+		}
+	}
+	return best
+}
+
+// WriteEnvelope 按内容协商将信封编码为 JSON、Protobuf、XML、MessagePack 或已注册的格式写出
+func WriteEnvelope(w http.ResponseWriter, r *http.Request, status int, env Envelope) {
+	w.Header().Add("Vary", "Accept")
+	if enc, ok := lookupEncoder(NegotiateFormat(r)); ok {
+		if body, err := enc.Marshal(env); err == nil {
+			w.Header().Set("Content-Type", enc.ContentType())
+			w.WriteHeader(status)
+			_, _ = w.Write(body)
+			return
+		}
+	}
+	// JSON 为兜底格式，其他格式编码失败时也退回 JSON
+	writeJSON(w, status, "application/json; charset=utf-8", env)
+}
+
+// WriteJSON 直接输出 JSON 且禁止缓存，不包装信封也不做内容协商，
+// 用于管理与诊断接口；错误应使用 WriteError 输出
+func WriteJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, status, "application/json; charset=utf-8", v)
+}
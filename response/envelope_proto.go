@@ -0,0 +1,350 @@
+package response
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Envelope 字段号，与 proto/higo/response/v1/envelope.proto 保持一致
+const (
+	fieldCode      protowire.Number = 1
+	fieldMessage   protowire.Number = 2
+	fieldData      protowire.Number = 3
+	fieldValue     protowire.Number = 4
+	fieldDetails   protowire.Number = 5
+	fieldPage      protowire.Number = 6
+	fieldRequestID protowire.Number = 7
+	fieldTraceID   protowire.Number = 8
+	fieldLinks     protowire.Number = 9
+	fieldMeta      protowire.Number = 10
+
+	fieldPageTotal    protowire.Number = 1
+	fieldPagePage     protowire.Number = 2
+	fieldPagePageSize protowire.Number = 3
+
+	fieldLinkSelf    protowire.Number = 1
+	fieldLinkNext    protowire.Number = 2
+	fieldLinkPrev    protowire.Number = 3
+	fieldLinkRelated protowire.Number = 4
+
+	// map<string, string> 条目
+	fieldMapKey   protowire.Number = 1
+	fieldMapValue protowire.Number = 2
+)
+
+// MarshalProto 按 proto/higo/response/v1/envelope.proto 编码信封
+// 数据为 proto.Message 时封装为 Any，其他数据经 JSON 语义转为 google.protobuf.Value。
+func MarshalProto(e Envelope) ([]byte, error) {
+	var b []byte
+	if e.Code != 0 {
+		b = protowire.AppendTag(b, fieldCode, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(int32(e.Code)))
+	}
+	b = appendString(b, fieldMessage, e.Message)
+
+	switch data := e.Data.(type) {
+	case nil:
+	case proto.Message:
+		a, err := anypb.New(data)
+		if err != nil {
+			return nil, fmt.Errorf("response: wrap proto data: %w", err)
+		}
+		if b, err = appendMessage(b, fieldData, a); err != nil {
+			return nil, err
+		}
+	default:
+		generic, err := toGeneric(data)
+		if err != nil {
+			return nil, err
+		}
+		v, err := structpb.NewValue(normalizeNumbers(generic))
+		if err != nil {
+			return nil, fmt.Errorf("response: convert data: %w", err)
+		}
+		if b, err = appendMessage(b, fieldValue, v); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	if b, err = appendStruct(b, fieldDetails, e.Details); err != nil {
+		return nil, err
+	}
+
+	if e.Page != nil {
+		var p []byte
+		p = protowire.AppendTag(p, fieldPageTotal, protowire.VarintType)
+		p = protowire.AppendVarint(p, uint64(e.Page.Total))
+		p = protowire.AppendTag(p, fieldPagePage, protowire.VarintType)
+		p = protowire.AppendVarint(p, uint64(int32(e.Page.Page)))
+		p = protowire.AppendTag(p, fieldPagePageSize, protowire.VarintType)
+		p = protowire.AppendVarint(p, uint64(int32(e.Page.PageSize)))
+		b = protowire.AppendTag(b, fieldPage, protowire.BytesType)
+		b = protowire.AppendBytes(b, p)
+	}
+
+	b = appendString(b, fieldRequestID, e.RequestID)
+	b = appendString(b, fieldTraceID, e.TraceID)
+
+	if e.Links != nil {
+		var l []byte
+		l = appendString(l, fieldLinkSelf, e.Links.Self)
+		l = appendString(l, fieldLinkNext, e.Links.Next)
+		l = appendString(l, fieldLinkPrev, e.Links.Prev)
+		rels := make([]string, 0, len(e.Links.Related))
+		for rel := range e.Links.Related {
+			rels = append(rels, rel)
+		}
+		sort.Strings(rels)
+		for _, rel := range rels {
+			var entry []byte
+			entry = appendString(entry, fieldMapKey, rel)
+			entry = appendString(entry, fieldMapValue, e.Links.Related[rel])
+			l = protowire.AppendTag(l, fieldLinkRelated, protowire.BytesType)
+			l = protowire.AppendBytes(l, entry)
+		}
+		b = protowire.AppendTag(b, fieldLinks, protowire.BytesType)
+		b = protowire.AppendBytes(b, l)
+	}
+	return appendStruct(b, fieldMeta, e.Meta)
+}
+
+// appendStruct 将 map 编码为 google.protobuf.Struct，空 map 不输出
+func appendStruct(b []byte, num protowire.Number, m map[string]any) ([]byte, error) {
+	if len(m) == 0 {
+		return b, nil
+	}
+	generic, err := toGeneric(m)
+	if err != nil {
+		return nil, err
+	}
+	s, err := structpb.NewStruct(normalizeNumbers(generic).(map[string]any))
+	if err != nil {
+		return nil, fmt.Errorf("response: convert field %d: %w", num, err)
+	}
+	return appendMessage(b, num, s)
+}
+
+// UnmarshalProto 解码信封：Any 数据保留为 *anypb.Any，Value 数据还原为通用 Go 值
+func UnmarshalProto(b []byte) (Envelope, error) {
+	var e Envelope
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return e, fmt.Errorf("response: decode envelope: %w", protowire.ParseError(n))
+		}
+		b = b[n:]
+
+		switch {
+		case num == fieldCode && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return e, fmt.Errorf("response: decode code: %w", protowire.ParseError(n))
+			}
+			e.Code, b = int(int32(v)), b[n:]
+			continue
+		case typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return e, fmt.Errorf("response: decode field %d: %w", num, protowire.ParseError(n))
+			}
+			b = b[n:]
+			if err := e.decodeBytesField(num, v); err != nil {
+				return e, err
+			}
+			continue
+		}
+
+		n = protowire.ConsumeFieldValue(num, typ, b)
+		if n < 0 {
+			return e, fmt.Errorf("response: skip field %d: %w", num, protowire.ParseError(n))
+		}
+		b = b[n:]
+	}
+	return e, nil
+}
+
+func (e *Envelope) decodeBytesField(num protowire.Number, v []byte) error {
+	switch num {
+	case fieldMessage:
+		e.Message = string(v)
+	case fieldRequestID:
+		e.RequestID = string(v)
+	case fieldTraceID:
+		e.TraceID = string(v)
+	case fieldData:
+		a := &anypb.Any{}
+		if err := proto.Unmarshal(v, a); err != nil {
+			return fmt.Errorf("response: decode data: %w", err)
+		}
+		e.Data = a
+	case fieldValue:
+		val := &structpb.Value{}
+		if err := proto.Unmarshal(v, val); err != nil {
+			return fmt.Errorf("response: decode value: %w", err)
+		}
+		e.Data = val.AsInterface()
+	case fieldDetails, fieldMeta:
+		s := &structpb.Struct{}
+		if err := proto.Unmarshal(v, s); err != nil {
+			return fmt.Errorf("response: decode field %d: %w", num, err)
+		}
+		if num == fieldDetails {
+			e.Details = s.AsMap()
+		} else {
+			e.Meta = s.AsMap()
+		}
+	case fieldLinks:
+		links, err := decodeLinks(v)
+		if err != nil {
+			return err
+		}
+		e.Links = links
+	case fieldPage:
+		page, err := decodePageInfo(v)
+		if err != nil {
+			return err
+		}
+		e.Page = page
+	}
+	return nil
+}
+
+func decodeLinks(b []byte) (*Links, error) {
+	l := &Links{}
+	err := consumeStrings(b, func(num protowire.Number, v []byte) error {
+		switch num {
+		case fieldLinkSelf:
+			l.Self = string(v)
+		case fieldLinkNext:
+			l.Next = string(v)
+		case fieldLinkPrev:
+			l.Prev = string(v)
+		case fieldLinkRelated:
+			var key, value string
+			err := consumeStrings(v, func(num protowire.Number, v []byte) error {
+				if num == fieldMapKey {
+					key = string(v)
+				} else if num == fieldMapValue {
+					value = string(v)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if l.Related == nil {
+				l.Related = make(map[string]string)
+			}
+			l.Related[key] = value
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// consumeStrings 遍历消息中的 bytes 字段，跳过其他类型字段
+func consumeStrings(b []byte, fn func(num protowire.Number, v []byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return fmt.Errorf("response: decode links: %w", protowire.ParseError(n))
+		}
+		b = b[n:]
+		if typ != protowire.BytesType {
+			if n = protowire.ConsumeFieldValue(num, typ, b); n < 0 {
+				return fmt.Errorf("response: decode links: %w", protowire.ParseError(n))
+			}
+			b = b[n:]
+			continue
+		}
+		v, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return fmt.Errorf("response: decode links: %w", protowire.ParseError(n))
+		}
+		b = b[n:]
+		if err := fn(num, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodePageInfo(b []byte) (*PageInfo, error) {
+	p := &PageInfo{}
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, fmt.Errorf("response: decode page: %w", protowire.ParseError(n))
+		}
+		b = b[n:]
+		if typ != protowire.VarintType {
+			if n = protowire.ConsumeFieldValue(num, typ, b); n < 0 {
+				return nil, fmt.Errorf("response: decode page: %w", protowire.ParseError(n))
+			}
+			b = b[n:]
+			continue
+		}
+		v, n := protowire.ConsumeVarint(b)
+		if n < 0 {
+			return nil, fmt.Errorf("response: decode page: %w", protowire.ParseError(n))
+		}
+		b = b[n:]
+		switch num {
+		case fieldPageTotal:
+			p.Total = int64(v)
+		case fieldPagePage:
+			p.Page = int(int32(v))
+		case fieldPagePageSize:
+			p.PageSize = int(int32(v))
+		}
+	}
+	return p, nil
+}
+
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+func appendMessage(b []byte, num protowire.Number, m proto.Message) ([]byte, error) {
+	raw, err := proto.MarshalOptions{Deterministic: true}.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("response: marshal field %d: %w", num, err)
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, raw), nil
+}
+
+// normalizeNumbers 将 json.Number 转为 float64，structpb 仅支持基础类型
+func normalizeNumbers(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, item := range val {
+			val[k] = normalizeNumbers(item)
+		}
+		return val
+	case []any:
+		for i, item := range val {
+			val[i] = normalizeNumbers(item)
+		}
+		return val
+	case json.Number:
+		f, _ := val.Float64()
+		return f
+	default:
+		return v
+	}
+}
@@ -0,0 +1,237 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// ErrFieldNotAllowed 请求的字段不在白名单中
+var ErrFieldNotAllowed = errors.New("response: field not allowed")
+
+var (
+	fieldsMu        sync.RWMutex
+	fieldAllowlists = make(map[reflect.Type]map[string]struct{})
+)
+
+// AllowFields 注册类型可投影的字段白名单（JSON 字段名，嵌套用 . 分隔）
+// 允许 "profile" 时，其下所有子字段均可请求。未注册的类型不做限制。
+func AllowFields[T any](paths ...string) {
+	allow := make(map[string]struct{}, len(paths))
+	for _, p := range paths {
+		allow[p] = struct{}{}
+	}
+
+	fieldsMu.Lock()
+	fieldAllowlists[baseType(reflect.TypeFor[T]())] = allow
+	fieldsMu.Unlock()
+}
+
+// FieldSet 端点级字段白名单，与 AllowFields 注册的类型白名单同时生效
+// 同一类型在不同端点暴露的字段不同时（如列表页与详情页），按端点声明 FieldSet。
+type FieldSet struct {
+	allow map[string]struct{}
+}
+
+// NewFieldSet 创建端点字段白名单（JSON 字段名，嵌套用 . 分隔）
+func NewFieldSet(paths ...string) *FieldSet {
+	allow := make(map[string]struct{}, len(paths))
+	for _, p := range ParseFields(strings.Join(paths, ",")) {
+		allow[p] = struct{}{}
+	}
+	return &FieldSet{allow: allow}
+}
+
+// Project 按字段投影数据，先校验端点白名单，再校验数据类型白名单
+func (s *FieldSet) Project(data any, fields []string) (any, error) {
+	if len(fields) == 0 {
+		return data, nil
+	}
+	if s != nil {
+		if err := checkAllowed(s.allow, fields); err != nil {
+			return nil, err
+		}
+	}
+	var t reflect.Type
+	if data != nil {
+		t = reflect.TypeOf(data)
+	}
+	return projectData(t, data, fields)
+}
+
+// ParseFields 解析 ?fields=id,name,profile.avatar
+// 也接受 JSON Pointer 形式：?fields=/id,/profile/avatar
+func ParseFields(s string) []string {
+	var fields []string
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f == "" {
+			continue
+		}
+		if strings.HasPrefix(f, "/") {
+			f = pointerToPath(f)
+		}
+		if f != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// FieldsFromRequest 读取请求的 fields 查询参数
+func FieldsFromRequest(r *http.Request) []string {
+	return ParseFields(r.URL.Query().Get("fields"))
+}
+
+var pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+// pointerToPath 将 JSON Pointer（RFC 6901）转为点分路径
+func pointerToPath(p string) string {
+	parts := strings.Split(strings.TrimPrefix(p, "/"), "/")
+	for i, part := range parts {
+		parts[i] = pointerUnescaper.Replace(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// Project 按字段投影数据，fields 为空时原样返回
+// 切片会对每个元素投影，嵌套路径如 profile.avatar 只保留对应子字段。
+func Project[T any](data T, fields []string) (any, error) {
+	if len(fields) == 0 {
+		return data, nil
+	}
+	return projectData(reflect.TypeFor[T](), data, fields)
+}
+
+func projectData(t reflect.Type, data any, fields []string) (any, error) {
+	if t != nil {
+		if err := checkFields(t, fields); err != nil {
+			return nil, err
+		}
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("response: marshal for projection: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("response: decode for projection: %w", err)
+	}
+
+	return project(v, buildFieldTree(fields)), nil
+}
+
+// OKFields 创建带字段投影的成功响应
+func OKFields[T any](data T, fields []string) (Response[any], error) {
+	projected, err := Project(data, fields)
+	if err != nil {
+		return Response[any]{}, err
+	}
+	return OK(projected), nil
+}
+
+func checkFields(t reflect.Type, fields []string) error {
+	fieldsMu.RLock()
+	allow, ok := fieldAllowlists[baseType(t)]
+	fieldsMu.RUnlock()
+	if !ok {
+		return nil
+	}
+	return checkAllowed(allow, fields)
+}
+
+func checkAllowed(allow map[string]struct{}, fields []string) error {
+	for _, f := range fields {
+		if !fieldAllowed(allow, f) {
+			return fmt.Errorf("%w: %s", ErrFieldNotAllowed, f)
+		}
+	}
+	return nil
+}
+
+// fieldAllowed 字段本身或其任一上级在白名单中即允许
+func fieldAllowed(allow map[string]struct{}, field string) bool {
+	for p := field; ; {
+		if _, ok := allow[p]; ok {
+			return true
+		}
+		i := strings.LastIndexByte(p, '.')
+		if i < 0 {
+			return false
+		}
+		p = p[:i]
+	}
+}
+
+// fieldTree 字段树，空 children 表示保留整个字段
+type fieldTree map[string]fieldTree
+
+func buildFieldTree(fields []string) fieldTree {
+	root := fieldTree{}
+	for _, f := range fields {
+		node := root
+		parts := strings.Split(f, ".")
+		for i, part := range parts {
+			child, exists := node[part]
+			if exists && child == nil {
+				break // 已请求整个字段
+			}
+			if i == len(parts)-1 {
+				node[part] = nil
+				break
+			}
+			if !exists {
+				child = fieldTree{}
+				node[part] = child
+			}
+			node = child
+		}
+	}
+	return root
+}
+
+func project(v any, tree fieldTree) any {
+	switch val := v.(type) {
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = project(item, tree)
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(tree))
+		for key, children := range tree {
+			field, ok := val[key]
+			if !ok {
+				continue
+			}
+			if children == nil {
+				out[key] = field
+			} else {
+				out[key] = project(field, children)
+			}
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// baseType 去除指针、切片、数组包装，得到元素类型
+func baseType(t reflect.Type) reflect.Type {
+	for {
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Array:
+			t = t.Elem()
+		default:
+			return t
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package response
+
+import "maps"
+
+// 常用链接关系
+const (
+	RelSelf = "self"
+	RelNext = "next"
+	RelPrev = "prev"
+)
+
+// Links 超媒体链接（HATEOAS），self/next/prev 之外的关系放入 Related
+type Links struct {
+	Self    string            `json:"self,omitempty"`
+	Next    string            `json:"next,omitempty"`
+	Prev    string            `json:"prev,omitempty"`
+	Related map[string]string `json:"related,omitempty"`
+}
+
+// withLink 返回设置了 rel 的副本，不修改原值
+func (l *Links) withLink(rel, href string) *Links {
+	out := &Links{}
+	if l != nil {
+		*out = *l
+		out.Related = maps.Clone(l.Related)
+	}
+	switch rel {
+	case RelSelf:
+		out.Self = href
+	case RelNext:
+		out.Next = href
+	case RelPrev:
+		out.Prev = href
+	default:
+		if out.Related == nil {
+			out.Related = make(map[string]string)
+		}
+		out.Related[rel] = href
+	}
+	return out
+}
+
+// withMeta 返回设置了 key 的副本，不修改原值
+func withMeta(meta map[string]any, key string, value any) map[string]any {
+	out := maps.Clone(meta)
+	if out == nil {
+		out = make(map[string]any, 1)
+	}
+	out[key] = value
+	return out
+}
+
+// WithLink 设置链接（rel 为 self/next/prev 或任意关联关系）
+//
+//	response.OK(order).
+//	    WithLink(response.RelSelf, "/orders/42").
+//	    WithLink("customer", "/customers/7").
+//	    WithMeta("cache", "hit")
+func (r Response[T]) WithLink(rel, href string) Response[T] {
+	r.Links = r.Links.withLink(rel, href)
+	return r
+}
+
+// WithMeta 设置扩展元数据
+func (r Response[T]) WithMeta(key string, value any) Response[T] {
+	r.Meta = withMeta(r.Meta, key, value)
+	return r
+}
+
+// WithLink 设置链接（分页）
+func (r PageResponse[T]) WithLink(rel, href string) PageResponse[T] {
+	r.Links = r.Links.withLink(rel, href)
+	return r
+}
+
+// WithMeta 设置扩展元数据（分页）
+func (r PageResponse[T]) WithMeta(key string, value any) PageResponse[T] {
+	r.Meta = withMeta(r.Meta, key, value)
+	return r
+}
+
+// WithLink 设置链接（游标分页）
+func (r CursorPageResponse[T]) WithLink(rel, href string) CursorPageResponse[T] {
+	r.Links = r.Links.withLink(rel, href)
+	return r
+}
+
+// WithMeta 设置扩展元数据（游标分页）
+func (r CursorPageResponse[T]) WithMeta(key string, value any) CursorPageResponse[T] {
+	r.Meta = withMeta(r.Meta, key, value)
+	return r
+}
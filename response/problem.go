@@ -0,0 +1,229 @@
+package response
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"maps"
+	"math"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mildsunup/higo/errors"
+	mw "github.com/mildsunup/higo/middleware"
+	"github.com/mildsunup/higo/observability"
+)
+
+// RFC 7807 媒体类型
+const (
+	ProblemContentType    = "application/problem+json"
+	ProblemXMLContentType = "application/problem+xml"
+)
+
+// problemNamespace RFC 7807 附录 A 定义的 XML 命名空间
+const problemNamespace = "urn:ietf:rfc:7807"
+
+// ProblemDetails RFC 7807 问题详情
+// Extensions 中的字段与标准字段平铺输出，同名时标准字段优先。
+type ProblemDetails struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]any
+}
+
+// MarshalJSON 平铺扩展字段
+func (p ProblemDetails) MarshalJSON() ([]byte, error) {
+	out := make(map[string]any, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		out[k] = v
+	}
+	out["type"] = p.Type
+	out["title"] = p.Title
+	out["status"] = p.Status
+	if p.Detail != "" {
+		out["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		out["instance"] = p.Instance
+	}
+	return json.Marshal(out)
+}
+
+// MarshalXML 按 RFC 7807 附录 A 编码为 <problem xmlns="urn:ietf:rfc:7807">，扩展字段展开为子元素
+func (p ProblemDetails) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{
+		Name: xml.Name{Local: "problem"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: problemNamespace}},
+	}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	generic, err := toGeneric(p)
+	if err != nil {
+		return err
+	}
+	fields := generic.(map[string]any)
+	keys := slices.Sorted(maps.Keys(fields))
+	for _, k := range keys {
+		if err := encodeXMLValue(enc, k, fields[k]); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// WithExtension 添加扩展字段
+func (p ProblemDetails) WithExtension(key string, value any) ProblemDetails {
+	ext := make(map[string]any, len(p.Extensions)+1)
+	for k, v := range p.Extensions {
+		ext[k] = v
+	}
+	ext[key] = value
+	p.Extensions = ext
+	return p
+}
+
+// ProblemOption 问题详情选项
+type ProblemOption func(*ProblemDetails, errors.Code)
+
+// WithProblemTypeBase 以 base/<错误码> 作为 type，默认 about:blank
+func WithProblemTypeBase(base string) ProblemOption {
+	return func(p *ProblemDetails, code errors.Code) {
+		p.Type = strings.TrimSuffix(base, "/") + "/" + strconv.Itoa(int(code))
+	}
+}
+
+// WithInstance 设置 instance（通常为请求路径）
+func WithInstance(uri string) ProblemOption {
+	return func(p *ProblemDetails, _ errors.Code) {
+		p.Instance = uri
+	}
+}
+
+// Problem 将错误转换为问题详情
+// errors.Error 的错误码决定 status，消息作为 detail，元数据作为扩展字段；
+// 其他错误按 500 处理且不暴露内部信息。
+func Problem(err error, opts ...ProblemOption) ProblemDetails {
+	code := errors.GetCode(err)
+	status := errors.GetHTTPStatus(err)
+	if status < http.StatusBadRequest {
+		status = http.StatusInternalServerError
+	}
+
+	p := ProblemDetails{
+		Type:       "about:blank",
+		Title:      http.StatusText(status),
+		Status:     status,
+		Extensions: map[string]any{"code": int(code)},
+	}
+
+	var e *errors.Error
+	if errors.As(err, &e) {
+		p.Title = code.Message()
+		p.Detail = e.Message()
+		for k, v := range e.Metadata() {
+			p.Extensions[k] = v
+		}
+	}
+
+	for _, opt := range opts {
+		opt(&p, code)
+	}
+	return p
+}
+
+// WantsProblem 请求是否通过 Accept 声明接受 problem+json 或 problem+xml
+func WantsProblem(r *http.Request) bool {
+	return problemContentType(r) != ""
+}
+
+// problemContentType 按 q 值选择问题详情的媒体类型，均未接受时返回空
+func problemContentType(r *http.Request) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || (mediaType != ProblemContentType && mediaType != ProblemXMLContentType) {
+			continue
+		}
+		q := 1.0
+		if s, ok := params["q"]; ok {
+			if v, err := strconv.ParseFloat(s, 64); err == nil {
+				q = v
+			}
+		}
+		if q > bestQ {
+			best, bestQ = mediaType, q
+		}
+	}
+	return best
+}
+
+// WriteError 按内容协商写出错误：接受 problem+json / problem+xml 时输出问题详情，
+// 否则以与成功响应相同的协商结果输出统一信封（JSON/Protobuf/XML/MessagePack）
+// 请求 ID 与追踪 ID 从请求 context 中获取。Gin 中可传入 c.Writer 与 c.Request。
+func WriteError(w http.ResponseWriter, r *http.Request, err error, opts ...ProblemOption) {
+	SetRetryAfter(w.Header(), err)
+	ctx := r.Context()
+	requestID := mw.GetRequestID(ctx)
+	traceID := mw.GetTraceID(ctx)
+	if traceID == "" {
+		traceID = observability.TraceID(ctx)
+	}
+
+	if contentType := problemContentType(r); contentType != "" {
+		p := Problem(err, append([]ProblemOption{WithInstance(r.URL.Path)}, opts...)...)
+		if requestID != "" {
+			p = p.WithExtension("request_id", requestID)
+		}
+		if traceID != "" {
+			p = p.WithExtension("trace_id", traceID)
+		}
+		w.Header().Add("Vary", "Accept")
+		if contentType == ProblemXMLContentType {
+			body, err := xml.Marshal(p)
+			if err == nil {
+				w.Header().Set("Content-Type", ProblemXMLContentType+"; charset=utf-8")
+				w.WriteHeader(p.Status)
+				_, _ = w.Write(append([]byte(xml.Header), body...))
+				return
+			}
+		}
+		writeJSON(w, p.Status, ProblemContentType, p)
+		return
+	}
+
+	status := errors.GetHTTPStatus(err)
+	if status < http.StatusBadRequest {
+		status = http.StatusInternalServerError
+	}
+	resp := errors.ToResponse(err)
+	var e *errors.Error
+	if status >= http.StatusInternalServerError && !errors.As(err, &e) {
+		resp.Message = http.StatusText(status)
+	}
+	WriteEnvelope(w, r, status, Envelope{
+		Code:      resp.Code,
+		Message:   resp.Message,
+		Details:   resp.Details,
+		RequestID: requestID,
+		TraceID:   traceID,
+	})
+}
+
+// SetRetryAfter 错误携带重试等待时间（errors.WithRetryAfter）时设置 Retry-After 头（秒，向上取整）
+func SetRetryAfter(h http.Header, err error) {
+	if d, ok := errors.GetRetryAfter(err); ok {
+		h.Set(mw.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(d.Seconds()))))
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, contentType string, v any) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
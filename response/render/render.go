@@ -0,0 +1,91 @@
+// Package render 将处理器结果写为统一响应。
+//
+// 根据错误码选择 HTTP 状态码，自动从 context 附加请求 ID 与追踪 ID，
+// 按 Accept 输出 JSON、Protobuf、XML、MessagePack（或经 response.RegisterEncoder 注册的格式）信封，
+// 客户端接受 application/problem+json 或 application/problem+xml 时错误输出 RFC 7807 问题详情。
+//
+//	func (h *Handler) GetUser(c *gin.Context) {
+//	    user, err := h.svc.Get(c.Request.Context(), c.Param("id"))
+//	    render.JSON(c, user, err)
+//	}
+//
+// 其他框架（echo 等）通过底层 ResponseWriter 与 Request 使用 Write：
+//
+//	render.Write(c.Response(), c.Request(), user, err)
+//
+// 支持 ?fields= 部分响应的端点使用 Partial，字段不在白名单内时返回 400：
+//
+//	var userFields = response.NewFieldSet("id", "name", "profile.avatar")
+//	render.Partial(c, userFields, user, err)
+package render
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mildsunup/higo/errors"
+	mw "github.com/mildsunup/higo/middleware"
+	"github.com/mildsunup/higo/observability"
+	"github.com/mildsunup/higo/response"
+)
+
+// JSON 写出 Gin 响应：err 非空时按错误码输出错误，否则输出 200 与数据
+func JSON[T any](c *gin.Context, data T, err error) {
+	if err != nil {
+		_ = c.Error(err)
+		c.Abort()
+	}
+	Write(c.Writer, c.Request, data, err)
+}
+
+// Created 写出 201 响应，err 非空时同 JSON
+func Created[T any](c *gin.Context, data T, err error) {
+	if err != nil {
+		JSON(c, data, err)
+		return
+	}
+	writeOK(c.Writer, c.Request, http.StatusCreated, data)
+}
+
+// Partial 按请求的 fields 参数投影数据后写出 Gin 响应
+func Partial[T any](c *gin.Context, fs *response.FieldSet, data T, err error) {
+	projected, err := project(c.Request, fs, data, err)
+	JSON(c, projected, err)
+}
+
+// WritePartial 按请求的 fields 参数投影数据后写出 net/http 响应
+func WritePartial[T any](w http.ResponseWriter, r *http.Request, fs *response.FieldSet, data T, err error) {
+	projected, err := project(r, fs, data, err)
+	Write(w, r, projected, err)
+}
+
+func project[T any](r *http.Request, fs *response.FieldSet, data T, err error) (any, error) {
+	if err != nil {
+		return nil, err
+	}
+	projected, err := fs.Project(data, response.FieldsFromRequest(r))
+	if err != nil {
+		return nil, errors.ErrInvalidArgument(err.Error())
+	}
+	return projected, nil
+}
+
+// Write 写出 net/http 响应
+func Write[T any](w http.ResponseWriter, r *http.Request, data T, err error) {
+	if err != nil {
+		response.WriteError(w, r, err)
+		return
+	}
+	writeOK(w, r, http.StatusOK, data)
+}
+
+func writeOK[T any](w http.ResponseWriter, r *http.Request, status int, data T) {
+	ctx := r.Context()
+	traceID := mw.GetTraceID(ctx)
+	if traceID == "" {
+		traceID = observability.TraceID(ctx)
+	}
+	resp := response.OK(data).WithRequestID(mw.GetRequestID(ctx)).WithTraceID(traceID)
+	response.WriteEnvelope(w, r, status, resp.Envelope())
+}
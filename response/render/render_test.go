@@ -0,0 +1,157 @@
+package render
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/mildsunup/higo/errors"
+	mw "github.com/mildsunup/higo/middleware"
+	"github.com/mildsunup/higo/response"
+)
+
+func init() { gin.SetMode(gin.TestMode) }
+
+type user struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+var userFields = response.NewFieldSet("id", "name")
+
+func decode(t *testing.T, w *httptest.ResponseRecorder) map[string]any {
+	t.Helper()
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("body %q: %v", w.Body.String(), err)
+	}
+	return body
+}
+
+func TestJSON(t *testing.T) {
+	var errs []*gin.Error
+	after := false
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(mw.WithValue(c.Request.Context(), mw.RequestIDKey, "req-1"))
+		c.Next()
+		errs = c.Errors
+	})
+	r.GET("/users/:id", func(c *gin.Context) {
+		if c.Param("id") != "1" {
+			JSON(c, (*user)(nil), errors.ErrNotFound("user not found"))
+			return
+		}
+		JSON(c, &user{ID: 1, Name: "alice"}, nil)
+	}, func(*gin.Context) { after = true })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	body := decode(t, w)
+	if w.Code != http.StatusOK || body["code"] != 0.0 || body["request_id"] != "req-1" {
+		t.Fatalf("ok = %d %v", w.Code, body)
+	}
+	if data := body["data"].(map[string]any); data["name"] != "alice" {
+		t.Fatalf("data = %v", data)
+	}
+
+	// 错误：按错误码映射状态码，记录到 c.Errors 并中止后续处理器
+	after = false
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/2", nil))
+	body = decode(t, w)
+	if w.Code != http.StatusNotFound || body["message"] != "user not found" || body["request_id"] != "req-1" {
+		t.Fatalf("not found = %d %v", w.Code, body)
+	}
+	if after || len(errs) != 1 {
+		t.Fatalf("after = %v, errors = %v", after, errs)
+	}
+
+	// 客户端接受问题详情时输出 RFC 7807
+	req := httptest.NewRequest(http.MethodGet, "/users/2", nil)
+	req.Header.Set("Accept", response.ProblemContentType)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	body = decode(t, w)
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), response.ProblemContentType) ||
+		body["status"] != 404.0 || body["instance"] != "/users/2" || body["request_id"] != "req-1" {
+		t.Fatalf("problem = %s %v", w.Header().Get("Content-Type"), body)
+	}
+}
+
+func TestCreated(t *testing.T) {
+	r := gin.New()
+	r.POST("/users", func(c *gin.Context) {
+		if c.Query("dup") != "" {
+			Created(c, (*user)(nil), errors.ErrAlreadyExists("email taken"))
+			return
+		}
+		Created(c, &user{ID: 7}, nil)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users", nil))
+	if w.Code != http.StatusCreated || decode(t, w)["data"].(map[string]any)["id"] != 7.0 {
+		t.Fatalf("created = %d %s", w.Code, w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users?dup=1", nil))
+	if w.Code != http.StatusConflict {
+		t.Fatalf("conflict = %d %s", w.Code, w.Body)
+	}
+}
+
+func TestPartial(t *testing.T) {
+	r := gin.New()
+	r.GET("/me", func(c *gin.Context) {
+		Partial(c, userFields, &user{ID: 1, Name: "alice", Email: "a@example.com"}, nil)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/me?fields=name", nil))
+	data := decode(t, w)["data"].(map[string]any)
+	if w.Code != http.StatusOK || len(data) != 1 || data["name"] != "alice" {
+		t.Fatalf("partial = %d %v", w.Code, data)
+	}
+
+	// 白名单外的字段返回 400，而不是泄露或静默忽略
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/me?fields=email", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("disallowed field = %d %s", w.Code, w.Body)
+	}
+}
+
+func TestWrite_TraceIDFromSpan(t *testing.T) {
+	tp := sdktrace.NewTracerProvider()
+	ctx, span := tp.Tracer("test").Start(context.Background(), "GET /me")
+	defer span.End()
+
+	req := httptest.NewRequest(http.MethodGet, "/me?fields=id", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+	WritePartial(w, req, userFields, user{ID: 3, Name: "bob"}, nil)
+
+	body := decode(t, w)
+	if body["trace_id"] != span.SpanContext().TraceID().String() {
+		t.Fatalf("trace_id = %v, want %s", body["trace_id"], span.SpanContext().TraceID())
+	}
+	if data := body["data"].(map[string]any); len(data) != 1 || data["id"] != 3.0 {
+		t.Fatalf("data = %v", data)
+	}
+
+	// 中间件写入的追踪 ID 优先
+	req = req.WithContext(mw.WithValue(ctx, mw.TraceIDKey, "from-header"))
+	w = httptest.NewRecorder()
+	Write(w, req, "pong", nil)
+	if body := decode(t, w); body["trace_id"] != "from-header" || body["data"] != "pong" {
+		t.Fatalf("body = %v", body)
+	}
+}
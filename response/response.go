@@ -2,23 +2,27 @@ package response
 
 // Response 标准响应结构
 type Response[T any] struct {
-	Code      int    `json:"code"`
-	Message   string `json:"message"`
-	Data      T      `json:"data,omitempty"`
-	RequestID string `json:"request_id,omitempty"`
-	TraceID   string `json:"trace_id,omitempty"`
+	Code      int            `json:"code"`
+	Message   string         `json:"message"`
+	Data      T              `json:"data,omitempty"`
+	Links     *Links         `json:"links,omitempty"`
+	Meta      map[string]any `json:"meta,omitempty"`
+	RequestID string         `json:"request_id,omitempty"`
+	TraceID   string         `json:"trace_id,omitempty"`
 }
 
 // PageResponse 分页响应
 type PageResponse[T any] struct {
-	Code      int    `json:"code"`
-	Message   string `json:"message"`
-	Data      T      `json:"data"`
-	Total     int64  `json:"total"`
-	Page      int    `json:"page"`
-	PageSize  int    `json:"page_size"`
-	RequestID string `json:"request_id,omitempty"`
-	TraceID   string `json:"trace_id,omitempty"`
+	Code      int            `json:"code"`
+	Message   string         `json:"message"`
+	Data      T              `json:"data"`
+	Total     int64          `json:"total"`
+	Page      int            `json:"page"`
+	PageSize  int            `json:"page_size"`
+	Links     *Links         `json:"links,omitempty"`
+	Meta      map[string]any `json:"meta,omitempty"`
+	RequestID string         `json:"request_id,omitempty"`
+	TraceID   string         `json:"trace_id,omitempty"`
 }
 
 // OK 创建成功响应
@@ -1,6 +1,16 @@
 package response
 
-import "testing"
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/mildsunup/higo/errors"
+)
 
 func TestOK(t *testing.T) {
 	resp := OK("data")
@@ -34,3 +44,246 @@ func TestWithRequestID(t *testing.T) {
 		t.Errorf("Expected 'req-123', got '%s'", resp.RequestID)
 	}
 }
+
+func TestProject(t *testing.T) {
+	type profile struct {
+		Avatar string `json:"avatar"`
+		Bio    string `json:"bio"`
+	}
+	type user struct {
+		ID      int     `json:"id"`
+		Name    string  `json:"name"`
+		Email   string  `json:"email"`
+		Profile profile `json:"profile"`
+	}
+	AllowFields[user]("id", "name", "profile")
+
+	users := []user{{ID: 1, Name: "a", Email: "a@x", Profile: profile{Avatar: "p", Bio: "b"}}}
+	got, err := Project(users, ParseFields("id, profile.avatar"))
+	if err != nil {
+		t.Fatalf("Project error: %v", err)
+	}
+
+	item := got.([]any)[0].(map[string]any)
+	if len(item) != 2 || item["email"] != nil {
+		t.Errorf("unexpected projection: %v", item)
+	}
+	if p := item["profile"].(map[string]any); len(p) != 1 || p["avatar"] != "p" {
+		t.Errorf("unexpected nested projection: %v", p)
+	}
+
+	if _, err := Project(users, []string{"email"}); err == nil {
+		t.Error("expected ErrFieldNotAllowed")
+	}
+
+	// 端点白名单比类型白名单更严格，JSON Pointer 与点分路径等价
+	fs := NewFieldSet("id", "profile.avatar")
+	got, err = fs.Project(users[0], ParseFields("/id,/profile/avatar"))
+	if err != nil {
+		t.Fatalf("FieldSet.Project error: %v", err)
+	}
+	if item := got.(map[string]any); len(item) != 2 {
+		t.Errorf("unexpected projection: %v", item)
+	}
+	if _, err := fs.Project(users[0], []string{"name"}); !errors.Is(err, ErrFieldNotAllowed) {
+		t.Errorf("expected ErrFieldNotAllowed, got %v", err)
+	}
+}
+
+func TestCursorCodec(t *testing.T) {
+	codec := NewCursorCodec([]byte("secret"))
+	filters := map[string]any{"status": "active"}
+
+	type row struct{ ID int64 }
+	rows := []row{{ID: 1}, {ID: 2}, {ID: 9007199254740993}}
+	next, prev, err := Cursors(codec, rows, func(r row) map[string]any {
+		return map[string]any{"id": r.ID}
+	}, filters)
+	if err != nil {
+		t.Fatalf("Cursors failed: %v", err)
+	}
+
+	cur, err := codec.Decode(next, filters)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if cur.Keys["id"].(json.Number).String() != "9007199254740993" || cur.Backward {
+		t.Errorf("unexpected next cursor: %+v", cur)
+	}
+
+	cur, err = codec.Decode(prev, filters)
+	if err != nil || !cur.Backward {
+		t.Errorf("unexpected prev cursor: %+v, %v", cur, err)
+	}
+
+	if _, err := codec.Decode(next, map[string]any{"status": "deleted"}); errors.GetCode(err) != errors.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for changed filters, got %v", err)
+	}
+
+	tampered := "x" + next[1:]
+	if _, err := codec.Decode(tampered, filters); errors.GetCode(err) != errors.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for tampered cursor, got %v", err)
+	}
+
+	if _, err := NewCursorCodec([]byte("other")).Decode(next, filters); err == nil {
+		t.Error("Expected error for cursor signed with another key")
+	}
+}
+
+func TestProblem(t *testing.T) {
+	err := errors.New(errors.NotFound, "user 42 not found").WithMeta("user_id", 42)
+	p := Problem(err, WithProblemTypeBase("https://errors.example.com/"), WithInstance("/users/42"))
+
+	if p.Status != 404 || p.Type != "https://errors.example.com/4" || p.Detail != "user 42 not found" {
+		t.Fatalf("unexpected problem: %+v", p)
+	}
+
+	data, _ := json.Marshal(p)
+	var out map[string]any
+	_ = json.Unmarshal(data, &out)
+	if out["user_id"] != float64(42) || out["instance"] != "/users/42" || out["code"] != float64(errors.NotFound) {
+		t.Errorf("unexpected json: %s", data)
+	}
+
+	internal := Problem(fmt.Errorf("db password leaked"))
+	if internal.Status != 500 || internal.Detail != "" {
+		t.Errorf("internal errors must not expose detail: %+v", internal)
+	}
+}
+
+func TestSSE(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/events", nil)
+
+	sse, err := SSE(rec, req, WithHeartbeat(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = sse.Send(Event{ID: "1", Event: "delta", Data: "hello\nworld"})
+	_ = sse.SendData(map[string]int{"n": 1})
+	_ = sse.Close()
+
+	want := "id: 1\nevent: delta\ndata: hello\ndata: world\n\ndata: {\"n\":1}\n\n"
+	if got := rec.Body.String(); got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+	if rec.Header().Get("Content-Type") != "text/event-stream" || !rec.Flushed {
+		t.Errorf("unexpected headers: %v", rec.Header())
+	}
+	if err := sse.SendData("late"); err == nil {
+		t.Error("send after close should fail")
+	}
+}
+
+func TestEnvelopeFormats(t *testing.T) {
+	type item struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	page := Page([]item{{1, "a"}}, 10, 1, 20).WithRequestID("req-1")
+
+	// JSON 结构与 PageResponse 一致
+	want, _ := json.Marshal(page)
+	got, _ := json.Marshal(page.Envelope())
+	if string(got) != string(want) {
+		t.Errorf("json = %s, want %s", got, want)
+	}
+
+	raw, err := MarshalProto(page.Envelope())
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, err := UnmarshalProto(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env.RequestID != "req-1" || env.Page == nil || env.Page.Total != 10 || env.Page.PageSize != 20 {
+		t.Errorf("unexpected proto envelope: %+v", env)
+	}
+	if items := env.Data.([]any); items[0].(map[string]any)["name"] != "a" {
+		t.Errorf("unexpected proto data: %v", env.Data)
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", "application/json;q=0.5, text/xml")
+	WriteEnvelope(rec, req, 200, OK(item{1, "a"}).Envelope())
+	body := rec.Body.String()
+	if rec.Header().Get("Content-Type") != "application/xml; charset=utf-8" ||
+		!strings.Contains(body, "<response><code>0</code><message>ok</message><data><id>1</id><name>a</name></data></response>") {
+		t.Errorf("unexpected xml: %s", body)
+	}
+}
+
+func TestLinksAndMeta(t *testing.T) {
+	base := OK("x").WithLink(RelSelf, "/orders/1")
+	resp := base.WithLink("customer", "/customers/7").WithMeta("cache", "hit")
+	if base.Links.Related != nil || base.Meta != nil {
+		t.Error("builders must not mutate the receiver")
+	}
+
+	raw, _ := json.Marshal(resp)
+	want := `{"code":0,"message":"ok","data":"x","links":{"self":"/orders/1","related":{"customer":"/customers/7"}},"meta":{"cache":"hit"}}`
+	if string(raw) != want {
+		t.Errorf("json = %s, want %s", raw, want)
+	}
+	if env, _ := json.Marshal(resp.Envelope()); string(env) != want {
+		t.Errorf("envelope json = %s, want %s", env, want)
+	}
+
+	b, err := MarshalProto(resp.Envelope())
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, err := UnmarshalProto(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env.Links.Self != "/orders/1" || env.Links.Related["customer"] != "/customers/7" || env.Meta["cache"] != "hit" {
+		t.Errorf("unexpected proto envelope: %+v", env)
+	}
+}
+
+func TestNegotiatedErrors(t *testing.T) {
+	err := errors.New(errors.NotFound, "user 42 not found")
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/users/42", nil)
+	req.Header.Set("Accept", "application/x-msgpack")
+	WriteError(rec, req, err)
+	if rec.Code != 404 || rec.Header().Get("Content-Type") != string(FormatMsgPack) {
+		t.Fatalf("status = %d, content-type = %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	var out map[string]any
+	if err := msgpack.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out["message"] != "user 42 not found" || fmt.Sprint(out["code"]) != fmt.Sprint(int(errors.NotFound)) {
+		t.Errorf("unexpected msgpack envelope: %v", out)
+	}
+
+	rec = httptest.NewRecorder()
+	req.Header.Set("Accept", "application/problem+json;q=0.5, application/problem+xml")
+	WriteError(rec, req, err)
+	body := rec.Body.String()
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), ProblemXMLContentType) ||
+		!strings.Contains(body, `<problem xmlns="urn:ietf:rfc:7807">`) ||
+		!strings.Contains(body, "<instance>/users/42</instance>") || !strings.Contains(body, "<status>404</status>") {
+		t.Errorf("unexpected problem xml: %s", body)
+	}
+}
+
+func TestRegisterEncoder(t *testing.T) {
+	const yaml Format = "application/x-test-yaml"
+	RegisterEncoder(yaml, NewEncoder("application/x-test-yaml", func(env Envelope) ([]byte, error) {
+		return []byte("message: " + env.Message), nil
+	}), "text/x-test-yaml")
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", "text/x-test-yaml")
+	WriteEnvelope(rec, req, 200, OK("x").Envelope())
+	if rec.Body.String() != "message: ok" || rec.Header().Get("Vary") != "Accept" {
+		t.Errorf("unexpected body %q, headers %v", rec.Body.String(), rec.Header())
+	}
+}
@@ -0,0 +1,227 @@
+package response
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StreamOption 流式响应选项
+type StreamOption func(*streamConfig)
+
+type streamConfig struct {
+	heartbeat time.Duration
+	autoFlush bool
+}
+
+// WithHeartbeat 设置心跳间隔，防止代理因空闲断开连接，0 表示关闭
+func WithHeartbeat(d time.Duration) StreamOption {
+	return func(c *streamConfig) { c.heartbeat = d }
+}
+
+// WithManualFlush 关闭每次写入后自动刷新，由调用方通过 Flush 控制
+func WithManualFlush() StreamOption {
+	return func(c *streamConfig) { c.autoFlush = false }
+}
+
+// streamWriter 流式写出基础实现：串行写入、刷新、心跳与取消
+type streamWriter struct {
+	w         http.ResponseWriter
+	rc        *http.ResponseController
+	ctx       context.Context
+	autoFlush bool
+
+	mu     sync.Mutex
+	closed bool
+	stop   chan struct{}
+}
+
+func newStreamWriter(w http.ResponseWriter, r *http.Request, contentType string, heartbeat []byte, opts []StreamOption) (*streamWriter, error) {
+	cfg := streamConfig{heartbeat: 15 * time.Second, autoFlush: true}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", contentType)
+	h.Set("Cache-Control", "no-cache")
+	h.Set("X-Accel-Buffering", "no") // 关闭 Nginx 缓冲
+	w.WriteHeader(http.StatusOK)
+
+	s := &streamWriter{
+		w:         w,
+		rc:        http.NewResponseController(w),
+		ctx:       r.Context(),
+		autoFlush: cfg.autoFlush,
+		stop:      make(chan struct{}),
+	}
+	if err := s.rc.Flush(); err != nil {
+		return nil, fmt.Errorf("response: streaming not supported: %w", err)
+	}
+	if cfg.heartbeat > 0 && heartbeat != nil {
+		go s.heartbeat(cfg.heartbeat, heartbeat)
+	}
+	return s, nil
+}
+
+func (s *streamWriter) write(b []byte, flush bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return io.ErrClosedPipe
+	}
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	if _, err := s.w.Write(b); err != nil {
+		return err
+	}
+	if flush {
+		return s.rc.Flush()
+	}
+	return nil
+}
+
+func (s *streamWriter) heartbeat(interval time.Duration, payload []byte) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.write(payload, true); err != nil {
+				return
+			}
+		case <-s.stop:
+			return
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// Flush 刷新缓冲
+func (s *streamWriter) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return io.ErrClosedPipe
+	}
+	return s.rc.Flush()
+}
+
+// Done 客户端断开或请求取消时关闭
+func (s *streamWriter) Done() <-chan struct{} { return s.ctx.Done() }
+
+// Close 停止心跳并刷新剩余数据，之后的写入返回 io.ErrClosedPipe
+func (s *streamWriter) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	close(s.stop)
+	if s.ctx.Err() != nil {
+		return nil
+	}
+	return s.rc.Flush()
+}
+
+// Event 服务端推送事件
+type Event struct {
+	ID    string
+	Event string
+	Data  any // string/[]byte 原样输出，其他类型编码为 JSON
+	Retry time.Duration
+}
+
+// SSEWriter text/event-stream 写出器
+type SSEWriter struct {
+	*streamWriter
+}
+
+// SSE 开始 Server-Sent Events 响应，默认每 15s 发送注释心跳
+//
+//	sse, err := response.SSE(c.Writer, c.Request)
+//	defer sse.Close()
+//	for chunk := range tokens {
+//	    if err := sse.Send(response.Event{Event: "delta", Data: chunk}); err != nil {
+//	        return // 客户端已断开
+//	    }
+//	}
+func SSE(w http.ResponseWriter, r *http.Request, opts ...StreamOption) (*SSEWriter, error) {
+	s, err := newStreamWriter(w, r, "text/event-stream", []byte(": ping\n\n"), opts)
+	if err != nil {
+		return nil, err
+	}
+	return &SSEWriter{s}, nil
+}
+
+// Send 发送事件
+func (s *SSEWriter) Send(e Event) error {
+	var b strings.Builder
+	if e.ID != "" {
+		b.WriteString("id: " + e.ID + "\n")
+	}
+	if e.Event != "" {
+		b.WriteString("event: " + e.Event + "\n")
+	}
+	if e.Retry > 0 {
+		fmt.Fprintf(&b, "retry: %d\n", e.Retry.Milliseconds())
+	}
+
+	var data string
+	switch v := e.Data.(type) {
+	case nil:
+	case string:
+		data = v
+	case []byte:
+		data = string(v)
+	default:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("response: encode event: %w", err)
+		}
+		data = string(raw)
+	}
+	for _, line := range strings.Split(data, "\n") {
+		b.WriteString("data: " + line + "\n")
+	}
+	b.WriteString("\n")
+
+	return s.write([]byte(b.String()), s.autoFlush)
+}
+
+// SendData 发送仅含数据的事件
+func (s *SSEWriter) SendData(data any) error {
+	return s.Send(Event{Data: data})
+}
+
+// NDJSONWriter application/x-ndjson 写出器
+type NDJSONWriter struct {
+	*streamWriter
+}
+
+// Stream 开始 NDJSON 流式响应，每行一个 JSON 值；默认不发送心跳
+// 使用 WithHeartbeat 时心跳为空行，客户端应忽略空行。
+func Stream(w http.ResponseWriter, r *http.Request, opts ...StreamOption) (*NDJSONWriter, error) {
+	s, err := newStreamWriter(w, r, "application/x-ndjson", []byte("\n"), append([]StreamOption{WithHeartbeat(0)}, opts...))
+	if err != nil {
+		return nil, err
+	}
+	return &NDJSONWriter{s}, nil
+}
+
+// Write 写出一行
+func (s *NDJSONWriter) Write(v any) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("response: encode line: %w", err)
+	}
+	return s.write(append(raw, '\n'), s.autoFlush)
+}
@@ -0,0 +1,426 @@
+package runtime
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mildsunup/higo/logger"
+	mw "github.com/mildsunup/higo/middleware"
+	"github.com/mildsunup/higo/ops"
+)
+
+var (
+	// ErrComponentNotFound 组件不存在
+	ErrComponentNotFound = errors.New("runtime: component not found")
+	// ErrAdminUnprotected 管理服务监听非回环地址但未设置 Token
+	ErrAdminUnprotected = errors.New("runtime: admin server on a non-loopback address requires a token")
+)
+
+const (
+	// DefaultAdminAddr 管理服务默认监听地址（仅本机）
+	DefaultAdminAddr = "127.0.0.1:6060"
+	// AdminSubject 通过 Token 认证的请求在 context 中的认证主体（middleware.GetSubject）
+	AdminSubject = "admin-token"
+)
+
+// AdminConfig 管理服务配置
+type AdminConfig struct {
+	Addr          string `yaml:"addr" mapstructure:"addr"`                     // 监听地址，默认 127.0.0.1:6060；非回环地址必须设置 Token
+	Token         string `yaml:"token" mapstructure:"token"`                   // 非空时所有接口要求 Authorization: Bearer <token>
+	EnableControl bool   `yaml:"enable_control" mapstructure:"enable_control"` // 允许启停单个组件、修改日志级别
+}
+
+// AdminOption 管理服务选项
+type AdminOption func(*adminServer)
+
+// WithAdminConfigDump 提供配置快照（/config），敏感字段（password、secret、token、key 等）会被脱敏
+func WithAdminConfigDump(fn func() any) AdminOption {
+	return func(s *adminServer) { s.configFn = fn }
+}
+
+// WithAdminLevelController 设置日志级别控制器，默认使用应用 Logger（实现 logger.LevelController 时）
+func WithAdminLevelController(lc logger.LevelController) AdminOption {
+	return func(s *adminServer) { s.level = lc }
+}
+
+// WithAdminHandler 挂载额外的接口，如 WithAdminHandler("/diagnostics", reporter.Handler())
+func WithAdminHandler(pattern string, h http.Handler) AdminOption {
+	return func(s *adminServer) { s.extra = append(s.extra, adminRoute{pattern, h}) }
+}
+
+// WithAdminOperations 挂载长时操作管理接口到 /operations/，运维可列出、查看并取消运行中的操作
+func WithAdminOperations(reg *ops.Registry) AdminOption {
+	return WithAdminHandler("/operations/", http.StripPrefix("/operations", reg.Handler()))
+}
+
+// WithAdmin 启用管理服务：应用启动时在独立端口监听，应用停止后关闭。
+// Addr 为空时仅监听本机；监听非回环地址（含 ":6060" 这类全部网卡）时必须设置 Token，否则启动失败。
+//
+// 接口：
+//
+//	GET  /components                 组件树与启动状态
+//	POST /components/{name}/start    启动单个组件（需 EnableControl）
+//	POST /components/{name}/stop     停止单个组件（需 EnableControl，被已启动组件依赖时拒绝）
+//	POST /reload                     重载配置，等同 SIGHUP（需 EnableControl）
+//	GET  /health                     健康检查聚合结果
+//	GET  /loglevel, PUT /loglevel    查看/修改日志级别（修改需 EnableControl）
+//	GET  /config                     配置快照（脱敏）
+//	GET  /version                    应用元数据与构建信息
+//	GET  /debug/pprof/, /debug/vars  pprof 与 expvar
+//	/operations/                     长时操作列表与取消（需 WithAdminOperations）
+func WithAdmin(cfg AdminConfig, opts ...AdminOption) Option {
+	return func(a *App) {
+		s := &adminServer{app: a, cfg: cfg}
+		for _, opt := range opts {
+			opt(s)
+		}
+		a.admin = s
+	}
+}
+
+// AdminHandler 返回管理接口的 Handler（未启用 WithAdmin 时返回 nil），可挂载到已有服务
+func (a *App) AdminHandler() http.Handler {
+	if a.admin == nil {
+		return nil
+	}
+	return a.admin.handler()
+}
+
+// StartComponent 启动单个已注册但未运行的组件，其依赖须已启动
+func (a *App) StartComponent(ctx context.Context, name string) error {
+	if a.State() != StateRunning {
+		return ErrNotRunning
+	}
+	a.ctl.Lock()
+	defer a.ctl.Unlock()
+
+	a.mu.Lock()
+	c, err := a.findComponent(name)
+	started := err == nil && c.started
+	if err == nil && !started {
+		for _, dep := range c.deps {
+			if d, _ := a.findComponent(dep); d == nil || !d.started {
+				err = fmt.Errorf("runtime: component %q requires %q, which is not running", name, dep)
+				break
+			}
+		}
+	}
+	a.mu.Unlock()
+	if err != nil || started {
+		return err
+	}
+
+	if err := a.startComponent(ctx, c, "admin"); err != nil {
+		return err
+	}
+	a.setStarted(c, true)
+	return nil
+}
+
+// StopComponent 停止单个运行中的组件，组件未运行时不做任何操作，仍被已启动组件依赖时拒绝
+func (a *App) StopComponent(ctx context.Context, name string) error {
+	if a.State() != StateRunning {
+		return ErrNotRunning
+	}
+	a.ctl.Lock()
+	defer a.ctl.Unlock()
+
+	a.mu.Lock()
+	c, err := a.findComponent(name)
+	if err == nil && !c.started {
+		a.mu.Unlock()
+		return nil
+	}
+	if err == nil {
+		var users []string
+		for _, e := range a.components {
+			if e.started && slices.Contains(e.deps, name) {
+				users = append(users, e.component.Name())
+			}
+		}
+		if len(users) > 0 {
+			err = fmt.Errorf("runtime: component %q is required by %s", name, strings.Join(users, ", "))
+		}
+	}
+	a.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	a.stopComponent(ctx, c)
+	return nil
+}
+
+// findComponent 按名称查找组件，调用方需持有 a.mu
+func (a *App) findComponent(name string) (*componentEntry, error) {
+	for i := range a.components {
+		if a.components[i].component.Name() == name {
+			return &a.components[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrComponentNotFound, name)
+}
+
+type adminRoute struct {
+	pattern string
+	handler http.Handler
+}
+
+// adminServer 管理服务
+type adminServer struct {
+	app      *App
+	cfg      AdminConfig
+	configFn func() any
+	level    logger.LevelController
+	extra    []adminRoute
+	srv      *http.Server
+}
+
+func (s *adminServer) start(ctx context.Context) error {
+	addr := s.cfg.Addr
+	if addr == "" {
+		addr = DefaultAdminAddr
+	}
+	if s.cfg.Token == "" && !isLoopback(addr) {
+		return fmt.Errorf("%w: %s", ErrAdminUnprotected, addr)
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("runtime: admin listen %s: %w", addr, err)
+	}
+	srv := &http.Server{Handler: s.handler(), ReadHeaderTimeout: 5 * time.Second}
+	s.srv = srv
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.app.log.Error(context.Background(), "admin server stopped", logger.Err(err))
+		}
+	}()
+	s.app.log.Info(ctx, "admin server listening", logger.String("addr", ln.Addr().String()))
+	return nil
+}
+
+// isLoopback 监听地址是否仅限本机，主机为空（全部网卡）视为非回环
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (s *adminServer) stop(ctx context.Context) {
+	if s.srv == nil {
+		return
+	}
+	if err := s.srv.Shutdown(ctx); err != nil {
+		_ = s.srv.Close()
+	}
+	s.srv = nil
+}
+
+func (s *adminServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.index)
+	mux.HandleFunc("GET /components", func(w http.ResponseWriter, r *http.Request) {
+		writeAdminJSON(w, http.StatusOK, s.app.Tree())
+	})
+	mux.HandleFunc("POST /components/{name}/{action}", s.control)
+	mux.HandleFunc("POST /reload", s.reload)
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		writeAdminJSON(w, http.StatusOK, s.app.CheckHealth(r.Context()))
+	})
+	mux.HandleFunc("GET /loglevel", s.getLevel)
+	mux.HandleFunc("PUT /loglevel", s.setLevel)
+	mux.HandleFunc("GET /config", s.config)
+	mux.Handle("GET /version", s.app.VersionHandler())
+
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("GET /debug/vars", expvar.Handler())
+
+	for _, r := range s.extra {
+		mux.Handle(r.pattern, r.handler)
+	}
+
+	if s.cfg.Token == "" {
+		return mux
+	}
+	want := []byte("Bearer " + s.cfg.Token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			writeAdminError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		mux.ServeHTTP(w, r.WithContext(mw.WithValue(r.Context(), mw.SubjectKey, AdminSubject)))
+	})
+}
+
+func (s *adminServer) index(w http.ResponseWriter, r *http.Request) {
+	endpoints := []string{
+		"GET /components", "POST /components/{name}/start", "POST /components/{name}/stop", "POST /reload",
+		"GET /health", "GET /loglevel", "PUT /loglevel", "GET /config", "GET /version",
+		"/debug/pprof/", "GET /debug/vars",
+	}
+	for _, route := range s.extra {
+		endpoints = append(endpoints, route.pattern)
+	}
+	writeAdminJSON(w, http.StatusOK, map[string]any{
+		"app":       s.app.cfg.Name,
+		"state":     s.app.State().String(),
+		"control":   s.cfg.EnableControl,
+		"endpoints": endpoints,
+	})
+}
+
+func (s *adminServer) control(w http.ResponseWriter, r *http.Request) {
+	if !s.cfg.EnableControl {
+		writeAdminError(w, http.StatusForbidden, "component control disabled")
+		return
+	}
+	name := r.PathValue("name")
+	var err error
+	switch r.PathValue("action") {
+	case "start":
+		err = s.app.StartComponent(r.Context(), name)
+	case "stop":
+		err = s.app.StopComponent(r.Context(), name)
+	default:
+		writeAdminError(w, http.StatusNotFound, "unknown action")
+		return
+	}
+	switch {
+	case errors.Is(err, ErrComponentNotFound):
+		writeAdminError(w, http.StatusNotFound, err.Error())
+	case err != nil:
+		writeAdminError(w, http.StatusConflict, err.Error())
+	default:
+		writeAdminJSON(w, http.StatusOK, s.app.Tree())
+	}
+}
+
+func (s *adminServer) reload(w http.ResponseWriter, r *http.Request) {
+	if !s.cfg.EnableControl {
+		writeAdminError(w, http.StatusForbidden, "component control disabled")
+		return
+	}
+	if err := s.app.Reload(r.Context()); err != nil {
+		writeAdminError(w, http.StatusConflict, err.Error())
+		return
+	}
+	writeAdminJSON(w, http.StatusOK, map[string]string{"status": "reloaded"})
+}
+
+func (s *adminServer) getLevel(w http.ResponseWriter, r *http.Request) {
+	if s.level == nil {
+		writeAdminError(w, http.StatusNotImplemented, "logger does not support level changes")
+		return
+	}
+	writeAdminJSON(w, http.StatusOK, map[string]string{"level": s.level.Level().String()})
+}
+
+func (s *adminServer) setLevel(w http.ResponseWriter, r *http.Request) {
+	if !s.cfg.EnableControl {
+		writeAdminError(w, http.StatusForbidden, "log level control disabled")
+		return
+	}
+	if s.level == nil {
+		writeAdminError(w, http.StatusNotImplemented, "logger does not support level changes")
+		return
+	}
+
+	level := r.URL.Query().Get("level")
+	if level == "" {
+		var body struct {
+			Level string `json:"level"`
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<10)).Decode(&body); err != nil {
+			writeAdminError(w, http.StatusBadRequest, "invalid body")
+			return
+		}
+		level = body.Level
+	}
+	switch level {
+	case "debug", "info", "warn", "warning", "error":
+	default:
+		writeAdminError(w, http.StatusBadRequest, "unknown level "+level)
+		return
+	}
+
+	old := s.level.Level()
+	s.level.SetLevel(logger.ParseLevel(level))
+	s.app.log.Warn(r.Context(), "log level changed",
+		logger.String("from", old.String()), logger.String("to", s.level.Level().String()), logger.String("by", "admin"))
+	writeAdminJSON(w, http.StatusOK, map[string]string{"level": s.level.Level().String()})
+}
+
+func (s *adminServer) config(w http.ResponseWriter, r *http.Request) {
+	if s.configFn == nil {
+		writeAdminError(w, http.StatusNotImplemented, "config dump not configured")
+		return
+	}
+	b, err := json.Marshal(s.configFn())
+	if err != nil {
+		writeAdminError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		writeAdminError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeAdminJSON(w, http.StatusOK, redact(v))
+}
+
+// 包含以下片段的配置键会被脱敏
+var sensitiveKeys = []string{"password", "passwd", "secret", "token", "key", "private", "credential", "dsn"}
+
+func redact(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			lk := strings.ToLower(k)
+			if slices.ContainsFunc(sensitiveKeys, func(s string) bool { return strings.Contains(lk, s) }) {
+				if val != nil && val != "" {
+					t[k] = "******"
+				}
+				continue
+			}
+			t[k] = redact(val)
+		}
+	case []any:
+		for i := range t {
+			t[i] = redact(t[i])
+		}
+	}
+	return v
+}
+
+func writeAdminJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+func writeAdminError(w http.ResponseWriter, status int, msg string) {
+	writeAdminJSON(w, status, map[string]string{"error": msg})
+}
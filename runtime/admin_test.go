@@ -0,0 +1,221 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	mw "github.com/mildsunup/higo/middleware"
+	"github.com/mildsunup/higo/ops"
+)
+
+// countingComponent 记录启停次数的组件
+type countingComponent struct {
+	name          string
+	starts, stops atomic.Int32
+}
+
+func (c *countingComponent) Name() string { return c.name }
+
+func (c *countingComponent) Start(context.Context) error {
+	c.starts.Add(1)
+	return nil
+}
+
+func (c *countingComponent) Stop(context.Context) error {
+	c.stops.Add(1)
+	return nil
+}
+
+func adminRequest(t *testing.T, h http.Handler, method, path, token string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAdmin_Token(t *testing.T) {
+	var subject string
+	app := New(DefaultConfig(), WithAdmin(AdminConfig{Token: "s3cret"},
+		WithAdminHandler("/whoami", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			subject = mw.GetSubject(r.Context())
+		}))))
+	h := app.AdminHandler()
+
+	if rec := adminRequest(t, h, http.MethodGet, "/components", ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("without token: %d", rec.Code)
+	}
+	if rec := adminRequest(t, h, http.MethodGet, "/components", "wrong"); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("wrong token: %d", rec.Code)
+	}
+	if rec := adminRequest(t, h, http.MethodGet, "/components", "s3cret"); rec.Code != http.StatusOK {
+		t.Fatalf("with token: %d", rec.Code)
+	}
+	adminRequest(t, h, http.MethodGet, "/whoami", "s3cret")
+	if subject != AdminSubject {
+		t.Fatalf("subject = %q", subject)
+	}
+}
+
+func TestAdmin_ListenAddress(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"127.0.0.1:6060": true,
+		"localhost:6060": true,
+		"[::1]:6060":     true,
+		":6060":          false,
+		"0.0.0.0:6060":   false,
+		"10.0.0.1:6060":  false,
+		"bad":            false,
+	} {
+		if got := isLoopback(addr); got != want {
+			t.Errorf("isLoopback(%q) = %v, want %v", addr, got, want)
+		}
+	}
+
+	ctx := context.Background()
+	app := New(DefaultConfig(), WithAdmin(AdminConfig{Addr: ":0"}))
+	if err := app.Start(ctx); !errors.Is(err, ErrAdminUnprotected) {
+		t.Fatalf("start on all interfaces without token = %v", err)
+	}
+
+	app = New(DefaultConfig(), WithAdmin(AdminConfig{Addr: ":0", Token: "t"}))
+	if err := app.Start(ctx); err != nil {
+		t.Fatalf("start with token: %v", err)
+	}
+	_ = app.Stop(ctx)
+
+	app = New(DefaultConfig(), WithAdmin(AdminConfig{Addr: "127.0.0.1:0"}))
+	if err := app.Start(ctx); err != nil {
+		t.Fatalf("start on loopback: %v", err)
+	}
+	_ = app.Stop(ctx)
+}
+
+func TestAdmin_ConfigRedaction(t *testing.T) {
+	cfg := map[string]any{
+		"db":       map[string]any{"dsn": "root:pw@tcp(db)/app", "max_open": 10},
+		"crypto":   map[string]any{"aes_key": "0123456789abcdef", "encryption_key": "k", "key_id": ""},
+		"redis":    []any{map[string]any{"addr": "redis:6379", "password": "pw"}},
+		"apiKey":   "abc",
+		"endpoint": "https://example.com",
+	}
+	app := New(DefaultConfig(), WithAdmin(AdminConfig{}, WithAdminConfigDump(func() any { return cfg })))
+
+	rec := adminRequest(t, app.AdminHandler(), http.MethodGet, "/config", "")
+	var got map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	db := got["db"].(map[string]any)
+	crypto := got["crypto"].(map[string]any)
+	redis := got["redis"].([]any)[0].(map[string]any)
+	for name, v := range map[string]any{
+		"db.dsn":                db["dsn"],
+		"crypto.aes_key":        crypto["aes_key"],
+		"crypto.encryption_key": crypto["encryption_key"],
+		"redis.password":        redis["password"],
+		"apiKey":                got["apiKey"],
+	} {
+		if v != "******" {
+			t.Errorf("%s = %v, want redacted", name, v)
+		}
+	}
+	if crypto["key_id"] != "" || db["max_open"] != float64(10) || got["endpoint"] != "https://example.com" {
+		t.Errorf("non-sensitive values changed: %v", got)
+	}
+}
+
+func TestAdmin_ComponentControl(t *testing.T) {
+	ctx := context.Background()
+	db := &countingComponent{name: "db"}
+	api := &countingComponent{name: "api"}
+	app := New(DefaultConfig(), WithAdmin(AdminConfig{EnableControl: true}))
+	app.Register(db)
+	app.Register(api, DependsOn("db"))
+	h := app.AdminHandler()
+
+	if rec := adminRequest(t, h, http.MethodPost, "/components/api/stop", ""); rec.Code != http.StatusConflict {
+		t.Fatalf("control before running: %d", rec.Code)
+	}
+	if err := app.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer app.Stop(ctx)
+
+	if rec := adminRequest(t, h, http.MethodPost, "/components/db/stop", ""); rec.Code != http.StatusConflict {
+		t.Fatalf("stop of a required component: %d", rec.Code)
+	}
+	if rec := adminRequest(t, h, http.MethodPost, "/components/missing/stop", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown component: %d", rec.Code)
+	}
+
+	for range 2 {
+		if rec := adminRequest(t, h, http.MethodPost, "/components/api/stop", ""); rec.Code != http.StatusOK {
+			t.Fatalf("stop api: %d %s", rec.Code, rec.Body)
+		}
+	}
+	if n := api.stops.Load(); n != 1 {
+		t.Fatalf("api stopped %d times, want 1", n)
+	}
+
+	if rec := adminRequest(t, h, http.MethodPost, "/components/db/stop", ""); rec.Code != http.StatusOK {
+		t.Fatalf("stop db after api: %d", rec.Code)
+	}
+	if rec := adminRequest(t, h, http.MethodPost, "/components/api/start", ""); rec.Code != http.StatusConflict {
+		t.Fatalf("start api without db: %d", rec.Code)
+	}
+	for _, name := range []string{"db", "api", "api"} {
+		if rec := adminRequest(t, h, http.MethodPost, "/components/"+name+"/start", ""); rec.Code != http.StatusOK {
+			t.Fatalf("start %s: %d %s", name, rec.Code, rec.Body)
+		}
+	}
+	if db.starts.Load() != 2 || api.starts.Load() != 2 {
+		t.Fatalf("starts: db=%d api=%d", db.starts.Load(), api.starts.Load())
+	}
+}
+
+func TestAdmin_ControlDisabled(t *testing.T) {
+	app := New(DefaultConfig(), WithAdmin(AdminConfig{}))
+	h := app.AdminHandler()
+	for _, path := range []string{"/components/db/stop", "/reload"} {
+		if rec := adminRequest(t, h, http.MethodPost, path, ""); rec.Code != http.StatusForbidden {
+			t.Errorf("%s: %d", path, rec.Code)
+		}
+	}
+}
+
+func TestAdmin_Operations(t *testing.T) {
+	reg := ops.NewRegistry()
+	op := reg.Go(context.Background(), "reindex", func(ctx context.Context, _ *ops.Operation) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	app := New(DefaultConfig(), WithAdmin(AdminConfig{}, WithAdminOperations(reg)))
+	h := app.AdminHandler()
+
+	rec := adminRequest(t, h, http.MethodGet, "/operations/", "")
+	var list []ops.Info
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || len(list) != 1 || list[0].ID != op.ID() {
+		t.Fatalf("list = %d %s", rec.Code, rec.Body)
+	}
+	if rec := adminRequest(t, h, http.MethodPost, "/operations/"+op.ID()+"/cancel", ""); rec.Code != http.StatusAccepted {
+		t.Fatalf("cancel = %d %s", rec.Code, rec.Body)
+	}
+	if rec := adminRequest(t, h, http.MethodGet, "/operations/missing", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("missing = %d", rec.Code)
+	}
+
+	rec = adminRequest(t, h, http.MethodGet, "/", "")
+	if !strings.Contains(rec.Body.String(), `"/operations/"`) {
+		t.Fatalf("index = %s", rec.Body)
+	}
+}
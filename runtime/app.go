@@ -2,11 +2,14 @@ package runtime
 
 import (
 	"context"
-	"sort"
+	"errors"
+	"fmt"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/mildsunup/higo/logger"
+	"github.com/mildsunup/higo/observability"
 )
 
 // App 应用实现
@@ -17,11 +20,31 @@ type App struct {
 	hooks      struct {
 		beforeStart []Hook
 		afterStart  []Hook
+		preStop     []Hook
 		beforeStop  []Hook
 		afterStop   []Hook
+		reload      []Hook
 	}
-	state atomic.Int32
-	mu    sync.Mutex
+	state    atomic.Int32
+	ready    atomic.Bool
+	draining atomic.Bool
+	mu       sync.Mutex
+	records  []ComponentReport
+	logTree  bool
+	health   healthAggregator
+	admin    *adminServer
+	leader   *leaderElector
+	startup  startupConfig
+	ctl      sync.Mutex // 串行化组件启停（应用启停与管理接口）
+
+	info      AppInfo
+	buildInfo observability.MetricsProvider
+
+	flushers []namedFlusher
+
+	services  []serviceManager
+	controls  chan serviceControl
+	reloading atomic.Bool
 }
 
 // Option 应用选项
@@ -37,21 +60,43 @@ func WithLogger(log Logger) Option {
 // New 创建应用
 func New(cfg Config, opts ...Option) *App {
 	app := &App{
-		cfg: cfg,
-		log: logger.Nop(), // 默认空日志，避免 nil 判断
+		cfg:      cfg,
+		log:      logger.Nop(), // 默认空日志，避免 nil 判断
+		health:   healthAggregator{cfg: DefaultHealthConfig()},
+		controls: make(chan serviceControl, 1),
+		info:     NewAppInfo(cfg.Name, cfg.Env, cfg.Version),
 	}
 	for _, opt := range opts {
 		opt(app)
 	}
+	app.registerBuildInfo()
+	if app.admin != nil && app.admin.level == nil {
+		app.admin.level, _ = app.log.(logger.LevelController)
+	}
 	app.state.Store(int32(StateCreated))
 	return app
 }
 
-// Register 注册组件（priority 越小越先启动，越后停止）
-func (a *App) Register(c Component, priority int) {
+// Register 注册组件，opts 为 Priority、DependsOn、LeaderOnly 等 ComponentOption：
+//
+//	app.Register(worker, runtime.Priority(10))
+//	app.Register(grpcServer, runtime.DependsOn("storage", "mq"))
+//
+// 任一组件声明依赖后按依赖图启动，互不依赖的分支并行启动；否则按优先级顺序启动。
+func (a *App) Register(c Component, opts ...ComponentOption) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
-	a.components = append(a.components, componentEntry{component: c, priority: priority})
+	e := componentEntry{component: c, seq: len(a.components) + 1}
+	for _, opt := range opts {
+		opt(&e)
+	}
+	if e.leaderOnly {
+		if a.leader == nil {
+			panic(fmt.Sprintf("runtime: LeaderOnly component %q requires WithLeaderElection", c.Name()))
+		}
+		e.component = &leaderGate{Component: c, elector: a.leader}
+	}
+	a.components = append(a.components, e)
 }
 
 // OnBeforeStart 注册启动前钩子
@@ -60,6 +105,9 @@ func (a *App) OnBeforeStart(h Hook) { a.hooks.beforeStart = append(a.hooks.befor
 // OnAfterStart 注册启动后钩子
 func (a *App) OnAfterStart(h Hook) { a.hooks.afterStart = append(a.hooks.afterStart, h) }
 
+// OnPreStop 注册预停止钩子（就绪摘除后、等待负载均衡注销前执行，如从注册中心注销）
+func (a *App) OnPreStop(h Hook) { a.hooks.preStop = append(a.hooks.preStop, h) }
+
 // OnBeforeStop 注册停止前钩子
 func (a *App) OnBeforeStop(h Hook) { a.hooks.beforeStop = append(a.hooks.beforeStop, h) }
 
@@ -81,35 +129,56 @@ func (a *App) Start(ctx context.Context) error {
 	}
 
 	a.setState(StateStarting)
+	ctx = ContextWithInfo(ctx, a.info)
+
+	// 管理服务先于组件启动，便于观察启动过程
+	if a.admin != nil {
+		if err := a.admin.start(ctx); err != nil {
+			a.setState(StateFailed)
+			return err
+		}
+	}
 
 	// 执行启动前钩子
 	for _, h := range a.hooks.beforeStart {
 		if err := h(ctx); err != nil {
 			a.setState(StateFailed)
+			a.stopAdmin(ctx)
 			return err
 		}
 	}
 
-	// 按优先级排序
+	// 按依赖与优先级排序
 	a.mu.Lock()
-	sort.Slice(a.components, func(i, j int) bool {
-		return a.components[i].priority < a.components[j].priority
-	})
+	sorted, err := resolveOrder(a.components)
+	if err == nil {
+		a.components = sorted
+	}
+	graph := a.graphMode()
 	a.mu.Unlock()
+	if err != nil {
+		a.setState(StateFailed)
+		a.stopAdmin(ctx)
+		return err
+	}
+	if a.logTree {
+		a.logComponentTree(ctx)
+	}
 
 	// 启动组件
-	for i := range a.components {
-		c := &a.components[i]
-		a.log.Info(ctx, "starting component", logger.String("name", c.component.Name()))
-
-		if err := c.component.Start(ctx); err != nil {
-			a.setState(StateFailed)
-			a.log.Error(ctx, "component start failed", logger.String("name", c.component.Name()), logger.Err(err))
-			// 回滚已启动的组件
-			a.stopStarted(ctx)
-			return err
-		}
-		c.started = true
+	a.ctl.Lock()
+	if graph {
+		err = a.startGraph(ctx)
+	} else {
+		err = a.startSequential(ctx)
+	}
+	a.ctl.Unlock()
+	if err != nil {
+		a.setState(StateFailed)
+		// 回滚已启动的组件
+		a.stopStarted(ctx)
+		a.stopAdmin(ctx)
+		return err
 	}
 
 	// 执行启动后钩子
@@ -117,11 +186,14 @@ func (a *App) Start(ctx context.Context) error {
 		if err := h(ctx); err != nil {
 			a.setState(StateFailed)
 			a.stopStarted(ctx)
+			a.stopAdmin(ctx)
 			return err
 		}
 	}
 
 	a.setState(StateRunning)
+	a.draining.Store(false)
+	a.ready.Store(true)
 	a.log.Info(ctx, "app started", logger.String("name", a.cfg.Name))
 	return nil
 }
@@ -134,13 +206,20 @@ func (a *App) Stop(ctx context.Context) error {
 
 	a.setState(StateStopping)
 
+	// 预停止：摘除就绪、排空连接，等待负载均衡注销后再停止组件
+	a.preStop(ctx)
+
+	// 刷出异步生产者、批处理器中已排队的数据
+	a.flush(ctx)
+
 	// 执行停止前钩子
 	for _, h := range a.hooks.beforeStop {
 		_ = h(ctx) // 忽略错误，继续停止
 	}
 
-	// 停止组件
+	// 停止组件，并等待启动超时后才返回的组件完成停止
 	a.stopStarted(ctx)
+	a.waitLateStarts(ctx)
 
 	// 执行停止后钩子
 	for _, h := range a.hooks.afterStop {
@@ -148,61 +227,226 @@ func (a *App) Stop(ctx context.Context) error {
 	}
 
 	a.setState(StateStopped)
+	a.stopAdmin(ctx)
 	a.log.Info(ctx, "app stopped", logger.String("name", a.cfg.Name))
 	return nil
 }
 
+func (a *App) stopAdmin(ctx context.Context) {
+	if a.admin != nil {
+		a.admin.stop(ctx)
+	}
+}
+
+// preStop 预停止：摘除就绪并进入排空状态 -> 预停止钩子 -> Drainer（如关闭 keep-alive）
+// -> 等待 PreStopDelay -> Quiescer 拒绝新工作并等待在途工作完成
+func (a *App) preStop(ctx context.Context) {
+	a.ready.Store(false)
+	a.draining.Store(true)
+
+	for _, h := range a.hooks.preStop {
+		if err := h(ctx); err != nil {
+			a.log.Warn(ctx, "pre-stop hook failed", logger.Err(err))
+		}
+	}
+
+	for _, c := range a.components {
+		if d, ok := c.component.(Drainer); ok && c.started {
+			if err := d.Drain(ctx); err != nil {
+				a.log.Warn(ctx, "component drain failed", logger.String("name", c.component.Name()), logger.Err(err))
+			}
+		}
+	}
+
+	if a.cfg.PreStopDelay > 0 {
+		a.log.Info(ctx, "waiting for load balancer deregistration", logger.Duration("delay", a.cfg.PreStopDelay))
+		select {
+		case <-time.After(a.cfg.PreStopDelay):
+		case <-ctx.Done():
+		}
+	}
+
+	a.quiesce(ctx)
+}
+
+func (a *App) startSequential(ctx context.Context) error {
+	for i := range a.components {
+		c := &a.components[i]
+		if err := a.startComponent(ctx, c, "app"); err != nil {
+			return err
+		}
+		a.setStarted(c, true)
+	}
+	return nil
+}
+
 func (a *App) stopStarted(ctx context.Context) {
+	a.ctl.Lock()
+	defer a.ctl.Unlock()
+
+	a.mu.Lock()
+	graph := a.graphMode()
+	a.mu.Unlock()
+	if graph {
+		a.stopGraph(ctx)
+		return
+	}
+
 	// 逆序停止
 	for i := len(a.components) - 1; i >= 0; i-- {
-		c := &a.components[i]
-		if !c.started {
-			continue
-		}
+		a.stopComponent(ctx, &a.components[i])
+	}
+}
+
+func (a *App) stopComponent(ctx context.Context, c *componentEntry) {
+	if !c.started {
+		return
+	}
 
-		a.log.Info(ctx, "stopping component", logger.String("name", c.component.Name()))
+	a.log.Info(ctx, "stopping component", logger.String("name", c.component.Name()))
 
-		if err := c.component.Stop(ctx); err != nil {
-			a.log.Error(ctx, "component stop failed", logger.String("name", c.component.Name()), logger.Err(err))
-		}
-		c.started = false
+	if c.stopTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.stopTimeout)
+		defer cancel()
+	}
+	start := time.Now()
+	err := c.component.Stop(ctx)
+	a.record(ctx, c.component.Name(), "stop", start, err)
+	if err != nil {
+		a.log.Error(ctx, "component stop failed", logger.String("name", c.component.Name()), logger.Err(err))
 	}
+	a.setStarted(c, false)
 }
 
-// Run 运行应用（阻塞直到收到信号）
+// setStarted 更新组件启动状态，与健康检查、组件树等读取方共用 a.mu
+func (a *App) setStarted(c *componentEntry, started bool) {
+	a.mu.Lock()
+	c.started = started
+	a.mu.Unlock()
+}
+
+// Run 运行应用（阻塞直到收到信号），正常停止返回 nil，否则返回 *ShutdownReport
 func (a *App) Run(ctx context.Context) error {
-	if err := a.Start(ctx); err != nil {
-		return err
+	report := a.RunWithReport(ctx)
+	if report.Reason == ExitClean {
+		return nil
+	}
+	return report
+}
+
+// RunWithReport 运行应用并返回运行结束报告
+// 运行期间 SIGHUP 触发 Reload；停止期间再次收到信号时不再等待组件停止，立即以 ExitForced 返回。
+func (a *App) RunWithReport(ctx context.Context) *ShutdownReport {
+	report := &ShutdownReport{Reason: ExitClean}
+	a.takeRecords()
+	a.notifyServices(func(m serviceManager) { m.begin() })
+	defer func() {
+		a.notifyServices(func(m serviceManager) { m.stopped(report.ExitCode()) })
+	}()
+
+	begin := time.Now()
+	err := a.Start(ctx)
+	report.StartDuration = time.Since(begin)
+	if err != nil {
+		report.Reason = ExitStartupFailed
+		report.Err = err
+		report.Components = a.takeRecords()
+		a.logReport(ctx, report)
+		return report
 	}
+	running := time.Now()
+	a.notifyServices(func(m serviceManager) { m.ready() })
 
 	// 等待信号
-	sig := WaitSignal(ctx)
-	a.log.Info(ctx, "received signal", logger.String("signal", sig.String()))
+	if reason := a.waitStop(ctx); reason != "" {
+		report.Signal = reason
+		a.log.Info(ctx, "received signal", logger.String("signal", report.Signal))
+	}
+	report.Uptime = time.Since(running)
+	a.notifyServices(func(m serviceManager) { m.stopping() })
 
 	// 带超时停止
 	stopCtx, cancel := context.WithTimeout(context.Background(), a.cfg.ShutdownTimeout)
 	defer cancel()
 
-	return a.Stop(stopCtx)
+	begin = time.Now()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = a.Stop(stopCtx)
+	}()
+
+	waitCtx, stopWaiting := context.WithCancel(context.Background())
+	defer stopWaiting()
+	forced := make(chan struct{})
+	go func() {
+		if WaitSignal(waitCtx) != nil {
+			close(forced)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-forced:
+		report.Reason = ExitForced
+	}
+	report.StopDuration = time.Since(begin)
+	report.Components = a.takeRecords()
+
+	if report.Reason == ExitClean {
+		switch {
+		case errors.Is(stopCtx.Err(), context.DeadlineExceeded):
+			report.Reason = ExitStopTimeout
+		case len(report.Failed()) > 0:
+			report.Reason = ExitStopFailed
+		}
+	}
+	a.logReport(ctx, report)
+	return report
 }
 
-// Health 健康检查
+func (a *App) logReport(ctx context.Context, r *ShutdownReport) {
+	fields := []logger.Field{
+		logger.String("reason", string(r.Reason)),
+		logger.Int("exit_code", r.ExitCode()),
+		logger.Duration("start_duration", r.StartDuration),
+		logger.Duration("stop_duration", r.StopDuration),
+		logger.Duration("uptime", r.Uptime),
+	}
+	for _, c := range r.Failed() {
+		fields = append(fields, logger.String("failed_"+c.Phase, c.Name))
+		if c.Abandoned > 0 {
+			fields = append(fields, logger.Int("abandoned_"+c.Name, c.Abandoned))
+		}
+		if c.Lost > 0 {
+			fields = append(fields, logger.Int("lost_"+c.Name, c.Lost))
+		}
+	}
+	if r.Reason == ExitClean {
+		a.log.Info(ctx, "shutdown report", fields...)
+		return
+	}
+	a.log.Warn(ctx, "shutdown report", fields...)
+}
+
+// Health 健康检查：聚合组件与注册的检查，返回所有失败项
 func (a *App) Health(ctx context.Context) error {
 	if a.State() != StateRunning {
 		return ErrNotRunning
 	}
+	return a.CheckHealth(ctx).Err()
+}
 
-	for _, c := range a.components {
-		if !c.started {
-			continue
-		}
-		if hc, ok := c.component.(HealthChecker); ok {
-			if err := hc.Health(ctx); err != nil {
-				return err
-			}
-		}
+// Ready 就绪检查：运行中且未进入预停止阶段
+func (a *App) Ready(ctx context.Context) error {
+	if !a.ready.Load() {
+		return ErrNotReady
 	}
 	return nil
 }
 
-var _ Application = (*App)(nil)
+var (
+	_ Application      = (*App)(nil)
+	_ ReadinessChecker = (*App)(nil)
+)
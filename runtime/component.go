@@ -1,12 +1,24 @@
 package runtime
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // componentEntry 组件条目
 type componentEntry struct {
 	component Component
 	priority  int
+	seq       int
+	deps      []string // 依赖的组件名
+	after     []int    // 解析后的前驱索引（显式依赖）
 	started   bool
+
+	startTimeout time.Duration // 启动超时，0 表示不限制
+	stopTimeout  time.Duration // 停止超时，0 表示仅受整体超时约束
+	drainTimeout time.Duration // 等待在途工作超时，0 表示仅受整体超时约束
+	flushTimeout time.Duration // 刷出排队数据超时，0 表示仅受整体超时约束
+	leaderOnly   bool          // 仅在 leader 实例上运行
 }
 
 // FuncComponent 函数式组件
@@ -0,0 +1,304 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	goruntime "runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/mildsunup/higo/lock"
+	"github.com/mildsunup/higo/logger"
+)
+
+// CronStats 定时任务运行统计
+type CronStats struct {
+	Runs      int64     `json:"runs"`
+	Failures  int64     `json:"failures"`
+	Skipped   int64     `json:"skipped"` // 因上次未结束或其他实例已执行而跳过
+	LastRun   time.Time `json:"last_run"`
+	LastError string    `json:"last_error,omitempty"`
+	Next      time.Time `json:"next"`
+}
+
+// CronOption 定时任务选项
+type CronOption func(*CronJob)
+
+// WithCronLogger 设置日志
+func WithCronLogger(l logger.Logger) CronOption {
+	return func(j *CronJob) { j.log = l }
+}
+
+// WithCronTracer 设置 Tracer（默认 otel 全局 Tracer）
+func WithCronTracer(t trace.Tracer) CronOption {
+	return func(j *CronJob) { j.tracer = t }
+}
+
+// WithCronTimeout 设置单次运行超时（默认不限制，停止时取消）
+func WithCronTimeout(d time.Duration) CronOption {
+	return func(j *CronJob) { j.timeout = d }
+}
+
+// WithCronLocker 启用分布式单次执行：同一触发时刻只有抢到锁的实例执行。
+// 锁以触发时刻为键且执行后不释放，ttl 需覆盖实例间的时钟偏差（默认 1 分钟）。
+func WithCronLocker(l lock.Locker, ttl time.Duration) CronOption {
+	return func(j *CronJob) {
+		j.locker = l
+		if ttl > 0 {
+			j.lockTTL = ttl
+		}
+	}
+}
+
+// WithCronOverlap 允许上次运行未结束时开始新的运行（默认跳过）
+func WithCronOverlap() CronOption {
+	return func(j *CronJob) { j.overlap = true }
+}
+
+// CronJob 定时任务组件，按 cron 表达式周期执行 fn，像其他组件一样注册到 App：
+//
+//	app.Register(runtime.NewCronJob("cleanup", "0 3 * * *", cleanup,
+//	    runtime.WithCronLocker(locker, time.Minute), runtime.WithCronLogger(log)))
+//
+// 默认防止重叠（上次未结束则跳过本次），每次运行创建 Span 并恢复 panic。
+type CronJob struct {
+	name    string
+	spec    string
+	fn      func(ctx context.Context) error
+	sched   Schedule
+	specErr error
+
+	log     logger.Logger
+	tracer  trace.Tracer
+	timeout time.Duration
+	locker  lock.Locker
+	lockTTL time.Duration
+	overlap bool
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	loop    sync.WaitGroup
+	runs    sync.WaitGroup
+	running int
+	stats   CronStats
+}
+
+// NewCronJob 创建定时任务，spec 语法见 ParseCron；表达式无效时 Start 返回错误
+func NewCronJob(name, spec string, fn func(ctx context.Context) error, opts ...CronOption) *CronJob {
+	j := &CronJob{
+		name:    name,
+		spec:    spec,
+		fn:      fn,
+		log:     logger.Nop(),
+		tracer:  otel.Tracer("github.com/mildsunup/higo/runtime"),
+		lockTTL: time.Minute,
+	}
+	j.sched, j.specErr = ParseCron(spec)
+	for _, opt := range opts {
+		opt(j)
+	}
+	return j
+}
+
+// Name 组件名称
+func (j *CronJob) Name() string { return j.name }
+
+// Start 启动调度（非阻塞）
+func (j *CronJob) Start(ctx context.Context) error {
+	if j.specErr != nil {
+		return fmt.Errorf("cron job %s: %w", j.name, j.specErr)
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.cancel != nil {
+		return nil
+	}
+
+	loopCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	j.cancel = cancel
+	j.stats.Next = j.sched.Next(time.Now())
+	j.loop.Go(func() { j.schedule(loopCtx) })
+	j.log.Info(ctx, "cron job scheduled",
+		logger.String("name", j.name), logger.String("spec", j.spec), logger.Time("next", j.stats.Next))
+	return nil
+}
+
+// Stop 停止调度，取消并等待进行中的运行
+func (j *CronJob) Stop(ctx context.Context) error {
+	j.mu.Lock()
+	cancel := j.cancel
+	j.cancel = nil
+	j.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+	done := make(chan struct{})
+	go func() {
+		j.loop.Wait()
+		j.runs.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("cron job %s: %w", j.name, ctx.Err())
+	}
+}
+
+// Stats 返回运行统计
+func (j *CronJob) Stats() CronStats {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.stats
+}
+
+// RunNow 立即执行一次（不经过分布式锁，遵循重叠策略）
+func (j *CronJob) RunNow(ctx context.Context) error {
+	if !j.acquire() {
+		return fmt.Errorf("cron job %s: previous run still in progress", j.name)
+	}
+	defer j.release()
+	return j.execute(ctx, time.Now())
+}
+
+func (j *CronJob) schedule(ctx context.Context) {
+	for {
+		j.mu.Lock()
+		next := j.stats.Next
+		j.mu.Unlock()
+		if next.IsZero() {
+			j.log.Warn(ctx, "cron job has no next run", logger.String("name", j.name))
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		j.mu.Lock()
+		j.stats.Next = j.sched.Next(next)
+		j.mu.Unlock()
+
+		if !j.acquire() {
+			j.skip(ctx, next, "previous run still in progress")
+			continue
+		}
+		j.runs.Go(func() {
+			defer j.release()
+			j.fire(ctx, next)
+		})
+	}
+}
+
+func (j *CronJob) acquire() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.running > 0 && !j.overlap {
+		return false
+	}
+	j.running++
+	return true
+}
+
+func (j *CronJob) release() {
+	j.mu.Lock()
+	j.running--
+	j.mu.Unlock()
+}
+
+// fire 一次调度触发：可选的分布式锁，然后执行
+func (j *CronJob) fire(ctx context.Context, at time.Time) {
+	if j.locker != nil {
+		key := "cron:" + j.name + ":" + strconv.FormatInt(at.Unix(), 10)
+		l := j.locker.NewLock(key, lock.WithTTL(j.lockTTL), lock.WithRetryCount(0))
+		ok, err := l.TryLock(ctx)
+		if err != nil {
+			j.log.Warn(ctx, "cron job lock failed", logger.String("name", j.name), logger.Err(err))
+			j.skip(ctx, at, "lock error")
+			return
+		}
+		if !ok {
+			j.skip(ctx, at, "run claimed by another instance")
+			return
+		}
+	}
+	_ = j.execute(ctx, at)
+}
+
+func (j *CronJob) execute(ctx context.Context, at time.Time) (err error) {
+	if j.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, j.timeout)
+		defer cancel()
+	}
+	ctx, span := j.tracer.Start(ctx, "cron "+j.name, trace.WithAttributes(
+		attribute.String("cron.name", j.name),
+		attribute.String("cron.spec", j.spec),
+		attribute.String("cron.scheduled_at", at.Format(time.RFC3339)),
+	))
+	defer span.End()
+
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			buf := make([]byte, 4096)
+			buf = buf[:goruntime.Stack(buf, false)]
+			err = fmt.Errorf("cron job %s panic: %v", j.name, r)
+			j.log.Error(ctx, "cron job panic", logger.String("name", j.name),
+				logger.Any("panic", r), logger.String("stack", string(buf)))
+		}
+		j.finish(ctx, span, start, err)
+	}()
+	return j.fn(ctx)
+}
+
+func (j *CronJob) finish(ctx context.Context, span trace.Span, start time.Time, err error) {
+	elapsed := time.Since(start)
+
+	j.mu.Lock()
+	j.stats.Runs++
+	j.stats.LastRun = start
+	j.stats.LastError = ""
+	if err != nil {
+		j.stats.Failures++
+		j.stats.LastError = err.Error()
+	}
+	j.mu.Unlock()
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.Is(err, context.Canceled) {
+			j.log.Warn(ctx, "cron job canceled", logger.String("name", j.name), logger.Duration("duration", elapsed))
+			return
+		}
+		j.log.Error(ctx, "cron job failed", logger.String("name", j.name),
+			logger.Duration("duration", elapsed), logger.Err(err))
+		return
+	}
+	j.log.Info(ctx, "cron job finished", logger.String("name", j.name), logger.Duration("duration", elapsed))
+}
+
+func (j *CronJob) skip(ctx context.Context, at time.Time, reason string) {
+	j.mu.Lock()
+	j.stats.Skipped++
+	j.mu.Unlock()
+	j.log.Info(ctx, "cron job skipped", logger.String("name", j.name),
+		logger.Time("scheduled_at", at), logger.String("reason", reason))
+}
+
+var _ Component = (*CronJob)(nil)
@@ -0,0 +1,200 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrDependency 组件依赖声明错误（未知依赖或循环依赖）
+var ErrDependency = errors.New("runtime: invalid component dependencies")
+
+// ComponentOption 组件注册选项
+type ComponentOption func(*componentEntry)
+
+// DependsOn 声明组件依赖（按组件 Name），依赖启动完成后才启动本组件，停止顺序相反
+//
+//	app.Register(storageComp, runtime.Priority(10))
+//	app.Register(mqComp, runtime.Priority(10))
+//	app.Register(grpcServer, runtime.DependsOn("storage", "mq"))
+func DependsOn(names ...string) ComponentOption {
+	return func(e *componentEntry) { e.deps = append(e.deps, names...) }
+}
+
+// Priority 设置优先级（越小越先启动，越后停止）。
+// 优先级只决定同时满足依赖的组件之间的先后，不构成依赖：声明了依赖的组件即使优先级更小，也在依赖之后启动
+func Priority(p int) ComponentOption {
+	return func(e *componentEntry) { e.priority = p }
+}
+
+// graphMode 是否有组件声明了依赖，调用方需持有 a.mu
+func (a *App) graphMode() bool {
+	for _, c := range a.components {
+		if len(c.deps) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveOrder 按依赖拓扑排序组件，返回记录了前驱索引的新切片。
+// 边只来自显式依赖；同时就绪的组件按优先级、注册顺序排列，因此优先级不会与依赖冲突。
+func resolveOrder(entries []componentEntry) ([]componentEntry, error) {
+	byName := make(map[string]int, len(entries))
+	for i, e := range entries {
+		byName[e.component.Name()] = i
+	}
+
+	preds := make([][]int, len(entries))
+	for i, e := range entries {
+		for _, d := range e.deps {
+			j, ok := byName[d]
+			if !ok {
+				return nil, fmt.Errorf("%w: %q depends on unknown component %q", ErrDependency, e.component.Name(), d)
+			}
+			preds[i] = append(preds[i], j)
+		}
+	}
+
+	less := func(i, j int) bool {
+		if entries[i].priority != entries[j].priority {
+			return entries[i].priority < entries[j].priority
+		}
+		return entries[i].seq < entries[j].seq
+	}
+
+	// Kahn 算法
+	indegree := make([]int, len(entries))
+	succs := make([][]int, len(entries))
+	for i, ps := range preds {
+		ps = uniqueInts(ps)
+		preds[i] = ps
+		indegree[i] = len(ps)
+		for _, p := range ps {
+			succs[p] = append(succs[p], i)
+		}
+	}
+	var ready, order []int
+	for i := range entries {
+		if indegree[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+	for len(ready) > 0 {
+		sort.Slice(ready, func(x, y int) bool { return less(ready[x], ready[y]) })
+		i := ready[0]
+		ready = ready[1:]
+		order = append(order, i)
+		for _, s := range succs[i] {
+			if indegree[s]--; indegree[s] == 0 {
+				ready = append(ready, s)
+			}
+		}
+	}
+	if len(order) != len(entries) {
+		var cyclic []string
+		for i, d := range indegree {
+			if d > 0 {
+				cyclic = append(cyclic, entries[i].component.Name())
+			}
+		}
+		return nil, fmt.Errorf("%w: dependency cycle among %s", ErrDependency, strings.Join(cyclic, ", "))
+	}
+
+	// 按拓扑序重排，并将前驱转换为新索引
+	pos := make([]int, len(entries))
+	for newIdx, oldIdx := range order {
+		pos[oldIdx] = newIdx
+	}
+	sorted := make([]componentEntry, len(entries))
+	for newIdx, oldIdx := range order {
+		e := entries[oldIdx]
+		e.after = nil
+		for _, p := range preds[oldIdx] {
+			e.after = append(e.after, pos[p])
+		}
+		sorted[newIdx] = e
+	}
+	return sorted, nil
+}
+
+func uniqueInts(s []int) []int {
+	slices.Sort(s)
+	return slices.Compact(s)
+}
+
+// startGraph 按依赖并行启动：组件在其所有前驱启动成功后立即启动
+func (a *App) startGraph(ctx context.Context) error {
+	n := len(a.components)
+	done := make([]chan struct{}, n)
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	for i := range a.components {
+		wg.Go(func() {
+			defer close(done[i])
+			c := &a.components[i]
+			for _, p := range c.after {
+				<-done[p]
+				if !a.components[p].started {
+					return // 前驱失败或被跳过
+				}
+			}
+			mu.Lock()
+			failed := firstErr != nil
+			mu.Unlock()
+			if failed {
+				return
+			}
+
+			if err := a.startComponent(ctx, c, "app"); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+				return
+			}
+			a.setStarted(c, true)
+		})
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// stopGraph 按依赖逆序并行停止：组件在所有依赖它的组件停止后才停止
+func (a *App) stopGraph(ctx context.Context) {
+	n := len(a.components)
+	succs := make([][]int, n)
+	for i, c := range a.components {
+		for _, p := range c.after {
+			succs[p] = append(succs[p], i)
+		}
+	}
+	done := make([]chan struct{}, n)
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+
+	var wg sync.WaitGroup
+	for i := range a.components {
+		wg.Go(func() {
+			defer close(done[i])
+			for _, s := range succs[i] {
+				<-done[s]
+			}
+			a.stopComponent(ctx, &a.components[i])
+		})
+	}
+	wg.Wait()
+}
@@ -0,0 +1,131 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func names(entries []componentEntry) []string {
+	out := make([]string, len(entries))
+	for i, e := range entries {
+		out[i] = e.component.Name()
+	}
+	return out
+}
+
+func entry(name string, seq int, opts ...ComponentOption) componentEntry {
+	e := componentEntry{component: &countingComponent{name: name}, seq: seq}
+	for _, opt := range opts {
+		opt(&e)
+	}
+	return e
+}
+
+func TestResolveOrder_PriorityTiebreak(t *testing.T) {
+	sorted, err := resolveOrder([]componentEntry{
+		entry("http", 1, Priority(100)),
+		entry("worker", 2, Priority(10)),
+		entry("grpc", 3, Priority(100)),
+		entry("cache", 4),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := names(sorted), []string{"cache", "worker", "http", "grpc"}; !slices.Equal(got, want) {
+		t.Fatalf("order = %v, want %v", got, want)
+	}
+	for _, e := range sorted {
+		if len(e.after) != 0 {
+			t.Fatalf("%s has predecessors %v without dependencies", e.component.Name(), e.after)
+		}
+	}
+}
+
+func TestResolveOrder_DependencyOverridesPriority(t *testing.T) {
+	// 文档示例：依赖方优先级更小也不应与依赖冲突
+	sorted, err := resolveOrder([]componentEntry{
+		entry("storage", 1, Priority(10)),
+		entry("mq", 2, Priority(10)),
+		entry("grpc", 3, DependsOn("storage", "mq")),
+		entry("metrics", 4, Priority(5)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := names(sorted), []string{"metrics", "storage", "mq", "grpc"}; !slices.Equal(got, want) {
+		t.Fatalf("order = %v, want %v", got, want)
+	}
+	if got := sorted[3].after; !slices.Equal(got, []int{1, 2}) {
+		t.Fatalf("grpc after = %v", got)
+	}
+}
+
+func TestResolveOrder_Errors(t *testing.T) {
+	_, err := resolveOrder([]componentEntry{entry("a", 1, DependsOn("missing"))})
+	if !errors.Is(err, ErrDependency) {
+		t.Fatalf("unknown dependency: %v", err)
+	}
+	_, err = resolveOrder([]componentEntry{
+		entry("a", 1, DependsOn("b")),
+		entry("b", 2, DependsOn("a")),
+	})
+	if !errors.Is(err, ErrDependency) {
+		t.Fatalf("cycle: %v", err)
+	}
+}
+
+// orderComponent 记录启停顺序的组件
+type orderComponent struct {
+	name string
+	mu   *sync.Mutex
+	log  *[]string
+}
+
+func (c *orderComponent) Name() string { return c.name }
+
+func (c *orderComponent) Start(context.Context) error {
+	c.mu.Lock()
+	*c.log = append(*c.log, "start:"+c.name)
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *orderComponent) Stop(context.Context) error {
+	c.mu.Lock()
+	*c.log = append(*c.log, "stop:"+c.name)
+	c.mu.Unlock()
+	return nil
+}
+
+func TestApp_DependencyGraph(t *testing.T) {
+	var (
+		mu  sync.Mutex
+		log []string
+	)
+	comp := func(name string) Component { return &orderComponent{name: name, mu: &mu, log: &log} }
+
+	ctx := context.Background()
+	app := New(DefaultConfig())
+	app.Register(comp("grpc"), DependsOn("storage", "mq"), Priority(-1))
+	app.Register(comp("storage"), Priority(10))
+	app.Register(comp("mq"), Priority(10))
+	if err := app.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	index := func(s string) int { return slices.Index(log, s) }
+	for _, dep := range []string{"storage", "mq"} {
+		if index("start:"+dep) > index("start:grpc") {
+			t.Errorf("%s started after grpc: %v", dep, log)
+		}
+		if index("stop:"+dep) < index("stop:grpc") {
+			t.Errorf("%s stopped before grpc: %v", dep, log)
+		}
+	}
+}
@@ -1,16 +1,56 @@
 // Package runtime 提供应用生命周期管理。
 //
 // 核心功能：
-//   - 组件启动/停止顺序管理（按优先级）
+//   - 组件启动/停止顺序管理（按优先级，或 DependsOn 声明依赖：拓扑排序，无依赖关系的分支并行启动，逆序停止）
+//   - 启动超时与进度（StartTimeout / WithStartTimeout / WithStartupObserver / WithStartupMetrics）：
+//     每个组件的启动耗时以日志、事件与指标报告，慢启动周期提示 "component still starting"，挂起的依赖不再让应用看似卡死
+//     注意：组件 Start 收到的 ctx 不再随 App.Start 的 ctx 取消，只受启动超时约束；超时后才成功返回的组件会被自动停止
 //   - 生命周期钩子（BeforeStart/AfterStart/BeforeStop/AfterStop）
-//   - 信号处理和优雅关闭
+//   - 信号处理和优雅关闭：SIGINT/SIGTERM 触发停止，SIGHUP 触发重载（OnReload 钩子 -> 组件 Reloader），也可通过管理服务 POST /reload
+//   - 操作系统服务集成：WithSystemdNotify（sd_notify READY/RELOADING/STOPPING 与 WATCHDOG 保活）、
+//     WithWindowsService（SCM 状态上报，Stop/Shutdown 停止、ParamChange 重载）
+//   - 预停止阶段：摘除就绪并进入排空状态（Draining）-> 排空连接（Drainer）-> 等待 PreStopDelay
+//     -> 等待在途工作完成（Quiescer，各自受 DrainTimeout 约束，放弃的数量计入 ShutdownReport）
+//     -> 刷出异步生产者、转发器与批处理器中的排队数据（Flusher 组件与 AddFlusher，各自受 FlushTimeout 约束，丢失的数量计入 ShutdownReport）
+//     -> 停止组件（StopTimeout）
+//   - 在途工作跟踪（InFlight）：Begin/done 包裹请求或消息，排空期间拒绝新工作
+//   - 健康检查聚合：组件 HealthChecker 与 AddHealthCheck 并发检查（单项超时、结果缓存、状态变化日志），
+//     HealthzHandler（存活，不执行依赖检查）/ ReadyzHandler（就绪，聚合检查）直接挂载为 Kubernetes 探针
+//   - 组件树（Tree / WithTreeLogging）：启动前输出解析后的启动顺序、能力与钩子
+//   - 选主（WithLeaderElection / LeaderOnly）：标记的组件只在持有分布式租约的实例上运行，获得租约时启动、失去时停止
+//   - 定时任务组件（NewCronJob）：标准 cron 表达式，防重叠、每次运行一个 Span、panic 恢复，可选基于 lock 的分布式单次执行
+//   - 管理服务（WithAdmin）：独立端口提供组件状态、单个组件启停、健康、pprof、expvar、配置快照（脱敏）与日志级别调整
+//   - 应用元数据（AppInfo）：名称、版本、提交号、启动时间与实例 ID，注入启动 context（InfoFromContext），
+//     VersionHandler 提供 /version，WithBuildInfoMetrics 导出 build_info 指标；版本与提交号可通过 -ldflags 注入
+//   - 运行结束报告（ShutdownReport）与退出码：正常 0、启动失败 2、停止报错 3、停止超时 4、强制退出 130
 //
 // 使用示例：
 //
 //	app := runtime.New(cfg, logger)
-//	app.Register(httpServer, 100)
-//	app.Register(grpcServer, 200)
-//	if err := app.Run(ctx); err != nil {
-//	    log.Fatal(err)
-//	}
+//	app.Register(httpServer, runtime.Priority(100))
+//	app.Register(grpcServer, runtime.Priority(200))
+//
+//	// 声明依赖：storage 与 mq 并行启动，二者就绪后启动 grpcServer；停止时先停 grpcServer。
+//	// 依赖图模式下优先级不再约束启动顺序，需要先后关系的组件应声明依赖
+//	app.Register(storageComp)
+//	app.Register(mqComp)
+//	app.Register(grpcServer, runtime.DependsOn("storage", "mq"))
+//
+//	// 服务器优先级应高于后台组件：先停止服务器（排空连接），最后停止后台组件
+//	cfg.PreStopDelay = 10 * time.Second
+//	app.Register(worker, runtime.Priority(10))
+//	app.Register(httpServer, runtime.Priority(100))
+//	// 探针：router.GET("/healthz", gin.WrapH(app.HealthzHandler())); router.GET("/readyz", gin.WrapH(app.ReadyzHandler()))
+//
+//	// 管理服务：curl -X PUT -H 'Authorization: Bearer ...' localhost:6060/loglevel -d '{"level":"debug"}'
+//	app := runtime.New(cfg, runtime.WithLogger(log),
+//	    runtime.WithAdmin(runtime.AdminConfig{Addr: "127.0.0.1:6060", Token: token, EnableControl: true},
+//	        runtime.WithAdminConfigDump(func() any { return appCfg }),
+//	        runtime.WithAdminHandler("/diagnostics", reporter.Handler())))
+//	os.Exit(runtime.ExitCode(app.Run(ctx)))
+//
+//	// 作为 systemd / Windows 服务运行：kill -HUP 或 systemctl reload 重新读取配置
+//	app := runtime.New(cfg, runtime.WithSystemdNotify(), runtime.WithWindowsService())
+//	app.OnReload(func(ctx context.Context) error { return appCfg.Reload() })
+//	app.Register(tlsServer) // 实现 Reloader：重新加载证书
 package runtime
@@ -0,0 +1,149 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/mildsunup/higo/logger"
+)
+
+// ErrDraining 正在排空，拒绝新工作
+var ErrDraining = errors.New("runtime: draining")
+
+// Quiescer 支持静默的组件：停止接收新工作并等待在途请求/消息完成，
+// ctx 到期时返回仍未完成（被放弃）的数量。在预停止阶段、PreStopDelay 之后调用。
+type Quiescer interface {
+	Quiesce(ctx context.Context) (abandoned int, err error)
+}
+
+// StopTimeout 设置组件停止的超时（默认受 ShutdownTimeout 整体约束）
+func StopTimeout(d time.Duration) ComponentOption {
+	return func(e *componentEntry) { e.stopTimeout = d }
+}
+
+// DrainTimeout 设置组件等待在途工作完成的超时（Quiescer），超时后剩余工作记为放弃
+func DrainTimeout(d time.Duration) ComponentOption {
+	return func(e *componentEntry) { e.drainTimeout = d }
+}
+
+// InFlight 在途工作跟踪：Begin/done 包裹每个请求或消息，Quiesce 后拒绝新工作并等待已有工作完成
+//
+//	done, err := tracker.Begin()
+//	if err != nil {
+//	    return err // ErrDraining
+//	}
+//	defer done()
+type InFlight struct {
+	mu       sync.Mutex
+	n        int
+	draining bool
+	idle     chan struct{} // 排空期间计数归零时关闭
+}
+
+// NewInFlight 创建在途工作跟踪
+func NewInFlight() *InFlight {
+	return &InFlight{}
+}
+
+// Begin 开始一项工作，排空期间返回 ErrDraining；done 必须且只需调用一次
+func (f *InFlight) Begin() (done func(), err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.draining {
+		return nil, ErrDraining
+	}
+	f.n++
+	var once sync.Once
+	return func() { once.Do(f.end) }, nil
+}
+
+func (f *InFlight) end() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.n--
+	if f.n == 0 && f.idle != nil {
+		close(f.idle)
+		f.idle = nil
+	}
+}
+
+// Count 返回在途工作数
+func (f *InFlight) Count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.n
+}
+
+// Draining 是否处于排空状态
+func (f *InFlight) Draining() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.draining
+}
+
+// Quiesce 进入排空状态并等待在途工作完成，ctx 到期时返回剩余数量与 ctx 错误
+func (f *InFlight) Quiesce(ctx context.Context) (int, error) {
+	f.mu.Lock()
+	f.draining = true
+	if f.n == 0 {
+		f.mu.Unlock()
+		return 0, nil
+	}
+	if f.idle == nil {
+		f.idle = make(chan struct{})
+	}
+	idle := f.idle
+	f.mu.Unlock()
+
+	select {
+	case <-idle:
+		return 0, nil
+	case <-ctx.Done():
+		return f.Count(), ctx.Err()
+	}
+}
+
+// Resume 退出排空状态，重新接收工作（应用重新启动时）
+func (f *InFlight) Resume() {
+	f.mu.Lock()
+	f.draining = false
+	f.mu.Unlock()
+}
+
+// Draining 应用是否处于排空阶段（预停止开始后）
+func (a *App) Draining() bool {
+	return a.draining.Load()
+}
+
+// quiesce 并发静默所有已启动的 Quiescer 组件，各自受 DrainTimeout 约束，记录放弃的工作
+func (a *App) quiesce(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := range a.components {
+		c := &a.components[i]
+		q, ok := c.component.(Quiescer)
+		if !ok || !c.started {
+			continue
+		}
+		wg.Go(func() {
+			qctx := ctx
+			if c.drainTimeout > 0 {
+				var cancel context.CancelFunc
+				qctx, cancel = context.WithTimeout(ctx, c.drainTimeout)
+				defer cancel()
+			}
+			name := c.component.Name()
+			start := time.Now()
+			abandoned, err := q.Quiesce(qctx)
+			a.recordDrain(qctx, name, start, abandoned, err)
+			if abandoned > 0 {
+				a.log.Warn(ctx, "component drain abandoned in-flight work",
+					logger.String("name", name), logger.Int("abandoned", abandoned), logger.Err(err))
+			} else if err != nil {
+				a.log.Warn(ctx, "component drain failed", logger.String("name", name), logger.Err(err))
+			}
+		})
+	}
+	wg.Wait()
+}
@@ -0,0 +1,136 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestInFlight(t *testing.T) {
+	f := NewInFlight()
+	done1, err := f.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	done2, _ := f.Begin()
+	done2()
+	done2() // 重复调用不重复计数
+	if f.Count() != 1 {
+		t.Fatalf("count = %d", f.Count())
+	}
+
+	quiesced := make(chan int, 1)
+	go func() {
+		n, _ := f.Quiesce(context.Background())
+		quiesced <- n
+	}()
+	eventually(t, "draining", f.Draining)
+	if _, err := f.Begin(); !errors.Is(err, ErrDraining) {
+		t.Fatalf("begin while draining = %v", err)
+	}
+	select {
+	case <-quiesced:
+		t.Fatal("quiesce returned with work in flight")
+	case <-time.After(20 * time.Millisecond):
+	}
+	done1()
+	if n := <-quiesced; n != 0 {
+		t.Fatalf("abandoned = %d", n)
+	}
+
+	f.Resume()
+	if _, err := f.Begin(); err != nil {
+		t.Fatalf("begin after resume = %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if n, err := f.Quiesce(ctx); n != 1 || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("quiesce = %d, %v", n, err)
+	}
+}
+
+// quiescingComponent 持有在途工作的组件
+type quiescingComponent struct {
+	countingComponent
+	inflight *InFlight
+}
+
+func (c *quiescingComponent) Quiesce(ctx context.Context) (int, error) {
+	return c.inflight.Quiesce(ctx)
+}
+
+func TestApp_DrainTimeoutAbandons(t *testing.T) {
+	stuck := &quiescingComponent{countingComponent: countingComponent{name: "consumer"}, inflight: NewInFlight()}
+	if _, err := stuck.inflight.Begin(); err != nil {
+		t.Fatal(err)
+	}
+	idle := &quiescingComponent{countingComponent: countingComponent{name: "http"}, inflight: NewInFlight()}
+	finishing := &quiescingComponent{countingComponent: countingComponent{name: "grpc"}, inflight: NewInFlight()}
+	done, _ := finishing.inflight.Begin()
+
+	app := New(DefaultConfig())
+	app.Register(stuck, DrainTimeout(30*time.Millisecond))
+	app.Register(idle)
+	app.Register(finishing, DrainTimeout(time.Second))
+	app.OnPreStop(func(context.Context) error {
+		if !app.Draining() || !app.CheckHealth(context.Background()).Draining {
+			t.Error("app not draining during pre-stop")
+		}
+		time.AfterFunc(10*time.Millisecond, done)
+		return nil
+	})
+
+	r := runUntilCancelled(app)
+	if r.Reason != ExitStopFailed {
+		t.Fatalf("report = %+v", r)
+	}
+	drains := make(map[string]ComponentReport)
+	for _, c := range r.Components {
+		if c.Phase == "drain" {
+			drains[c.Name] = c
+		}
+	}
+	if c := drains["consumer"]; c.Abandoned != 1 || !c.TimedOut {
+		t.Fatalf("consumer drain = %+v", c)
+	}
+	if c := drains["grpc"]; c.Abandoned != 0 || c.Err != nil {
+		t.Fatalf("grpc drain = %+v", c)
+	}
+	if c := drains["http"]; c.Abandoned != 0 || c.Err != nil {
+		t.Fatalf("http drain = %+v", c)
+	}
+	if failed := r.Failed(); len(failed) != 1 || failed[0].Name != "consumer" {
+		t.Fatalf("failed = %+v", failed)
+	}
+
+	// 重新启动后退出排空状态
+	if err := app.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer app.Stop(context.Background())
+	if app.Draining() {
+		t.Fatal("still draining after restart")
+	}
+}
+
+func TestApp_StopTimeoutPerComponent(t *testing.T) {
+	hung := &faultyComponent{countingComponent: countingComponent{name: "worker"}, hang: true}
+	db := &countingComponent{name: "db"}
+	app := New(DefaultConfig())
+	app.Register(db, Priority(1))
+	app.Register(hung, Priority(2), StopTimeout(20*time.Millisecond))
+
+	begin := time.Now()
+	r := runUntilCancelled(app)
+	if elapsed := time.Since(begin); elapsed > 5*time.Second {
+		t.Fatalf("stop took %v", elapsed)
+	}
+	// 单个组件超时不影响后续组件停止，整体未超过 ShutdownTimeout
+	if r.Reason != ExitStopFailed || db.stops.Load() != 1 {
+		t.Fatalf("report = %+v, db stops = %d", r, db.stops.Load())
+	}
+	if failed := r.Failed(); len(failed) != 1 || failed[0].Name != "worker" || !failed[0].TimedOut {
+		t.Fatalf("failed = %+v", failed)
+	}
+}
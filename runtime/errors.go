@@ -4,4 +4,7 @@ import "errors"
 
 var (
 	ErrNotRunning = errors.New("app not running")
+	ErrNotReady   = errors.New("app not ready")
+	// ErrStartTimeout 组件启动超时
+	ErrStartTimeout = errors.New("runtime: component start timed out")
 )
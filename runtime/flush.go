@@ -0,0 +1,91 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/mildsunup/higo/logger"
+)
+
+// Flusher 异步生产者、转发器与批处理器：在停止阶段（Quiescer 之后、组件 Stop 之前）
+// 将已排队的数据刷出。Flush 在 ctx 到期时应尽快返回，之后 Pending 报告仍未刷出（将丢失）的数量。
+type Flusher interface {
+	// Flush 刷出已排队的数据
+	Flush(ctx context.Context) error
+	// Pending 尚未刷出的数量
+	Pending() int
+}
+
+// FlushTimeout 设置组件刷出排队数据的超时（Flusher），超时后剩余数据记为丢失
+func FlushTimeout(d time.Duration) ComponentOption {
+	return func(e *componentEntry) { e.flushTimeout = d }
+}
+
+type namedFlusher struct {
+	name    string
+	flusher Flusher
+	timeout time.Duration
+}
+
+// AddFlusher 注册非组件的 Flusher（如 Kafka 客户端、事件总线），在停止阶段与组件 Flusher 一同刷出；
+// timeout 为 0 时仅受整体停止超时约束
+func (a *App) AddFlusher(name string, f Flusher, timeout time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.flushers = append(a.flushers, namedFlusher{name: name, flusher: f, timeout: timeout})
+}
+
+// flush 并发刷出所有已启动的 Flusher 组件与注册的 Flusher，记录丢失的数据
+func (a *App) flush(ctx context.Context) {
+	a.mu.Lock()
+	targets := append([]namedFlusher(nil), a.flushers...)
+	a.mu.Unlock()
+	for i := range a.components {
+		c := &a.components[i]
+		if f, ok := c.component.(Flusher); ok && c.started {
+			targets = append(targets, namedFlusher{name: c.component.Name(), flusher: f, timeout: c.flushTimeout})
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, t := range targets {
+		wg.Go(func() {
+			fctx := ctx
+			if t.timeout > 0 {
+				var cancel context.CancelFunc
+				fctx, cancel = context.WithTimeout(ctx, t.timeout)
+				defer cancel()
+			}
+			start := time.Now()
+			err := t.flusher.Flush(fctx)
+			lost := t.flusher.Pending()
+			if err == nil && lost > 0 {
+				err = fctx.Err()
+			}
+			a.recordFlush(t.name, start, lost, err)
+			if lost > 0 {
+				a.log.Warn(ctx, "component flush lost queued data",
+					logger.String("name", t.name), logger.Int("lost", lost), logger.Err(err))
+			} else if err != nil {
+				a.log.Warn(ctx, "component flush failed", logger.String("name", t.name), logger.Err(err))
+			}
+		})
+	}
+	wg.Wait()
+}
+
+// recordFlush 记录刷出结果
+func (a *App) recordFlush(name string, start time.Time, lost int, err error) {
+	a.mu.Lock()
+	a.records = append(a.records, ComponentReport{
+		Name:     name,
+		Phase:    "flush",
+		Duration: time.Since(start),
+		Err:      err,
+		TimedOut: errors.Is(err, context.DeadlineExceeded),
+		Lost:     lost,
+	})
+	a.mu.Unlock()
+}
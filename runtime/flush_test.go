@@ -0,0 +1,136 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// queueFlusher 排队数据按 step 间隔逐条刷出；stuck 为真时不再刷出
+type queueFlusher struct {
+	pending atomic.Int32
+	step    time.Duration
+	stuck   bool
+	err     error
+}
+
+func (f *queueFlusher) Flush(ctx context.Context) error {
+	if f.err != nil {
+		return f.err
+	}
+	for f.pending.Load() > 0 {
+		if f.stuck {
+			<-ctx.Done()
+			return nil // ctx 到期后由 Pending 报告丢失数量
+		}
+		select {
+		case <-time.After(f.step):
+			f.pending.Add(-1)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (f *queueFlusher) Pending() int { return int(f.pending.Load()) }
+
+// flushingComponent 带排队数据的组件，记录各阶段的调用顺序
+type flushingComponent struct {
+	countingComponent
+	queueFlusher
+	mu     sync.Mutex
+	phases []string
+}
+
+func (c *flushingComponent) Flush(ctx context.Context) error {
+	c.mark("flush")
+	return c.queueFlusher.Flush(ctx)
+}
+
+func (c *flushingComponent) Quiesce(context.Context) (int, error) {
+	c.mark("quiesce")
+	return 0, nil
+}
+
+func (c *flushingComponent) Stop(ctx context.Context) error {
+	c.mark("stop")
+	return c.countingComponent.Stop(ctx)
+}
+
+func (c *flushingComponent) mark(phase string) {
+	c.mu.Lock()
+	c.phases = append(c.phases, phase)
+	c.mu.Unlock()
+}
+
+func TestApp_FlushBeforeStop(t *testing.T) {
+	c := &flushingComponent{countingComponent: countingComponent{name: "producer"}}
+	c.step = time.Millisecond
+	c.pending.Store(3)
+	app := New(DefaultConfig())
+	app.Register(c)
+
+	r := runUntilCancelled(app)
+	if r.Reason != ExitClean {
+		t.Fatalf("report = %+v", r)
+	}
+	if want := []string{"quiesce", "flush", "stop"}; !slices.Equal(c.phases, want) {
+		t.Fatalf("phases = %v, want %v", c.phases, want)
+	}
+	if c.Pending() != 0 {
+		t.Fatalf("pending after flush = %d", c.Pending())
+	}
+}
+
+func TestApp_FlushTimeoutLosesData(t *testing.T) {
+	c := &flushingComponent{countingComponent: countingComponent{name: "batcher"}}
+	c.stuck = true
+	c.pending.Store(5)
+	bus := &queueFlusher{err: errors.New("broker down")}
+	app := New(DefaultConfig())
+	app.Register(c, FlushTimeout(20*time.Millisecond))
+	app.AddFlusher("eventbus", bus, 0)
+
+	r := runUntilCancelled(app)
+	if r.Reason != ExitStopFailed {
+		t.Fatalf("report = %+v", r)
+	}
+	flushes := make(map[string]ComponentReport)
+	for _, cr := range r.Components {
+		if cr.Phase == "flush" {
+			flushes[cr.Name] = cr
+		}
+	}
+	if cr := flushes["batcher"]; cr.Lost != 5 || !cr.TimedOut {
+		t.Fatalf("batcher flush = %+v", cr)
+	}
+	if cr := flushes["eventbus"]; cr.Lost != 0 || cr.Err == nil {
+		t.Fatalf("eventbus flush = %+v", cr)
+	}
+	// 刷出超时后组件仍被停止
+	if c.stops.Load() != 1 {
+		t.Fatalf("stops = %d", c.stops.Load())
+	}
+}
+
+func TestApp_FlushSkipsLeaderGatedFollower(t *testing.T) {
+	locker := &fakeLocker{}
+	// 其他实例持有租约，本实例的 LeaderOnly 组件不刷出
+	if ok, _ := locker.NewLock("other").TryLock(context.Background()); !ok {
+		t.Fatal("setup lock")
+	}
+	c := &flushingComponent{countingComponent: countingComponent{name: "relay"}}
+	c.stuck = true
+	c.pending.Store(2)
+	app := leaderApp(locker, c)
+
+	r := runUntilCancelled(app)
+	if r.Reason != ExitClean || slices.Contains(c.phases, "flush") {
+		t.Fatalf("report = %+v, phases = %v", r, c.phases)
+	}
+}
@@ -0,0 +1,230 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/mildsunup/higo/logger"
+)
+
+// HealthConfig 健康检查聚合配置
+type HealthConfig struct {
+	Timeout  time.Duration // 单项检查超时
+	CacheTTL time.Duration // 结果缓存时间，避免探针高频打到存储/MQ
+}
+
+// DefaultHealthConfig 默认配置
+func DefaultHealthConfig() HealthConfig {
+	return HealthConfig{Timeout: 2 * time.Second, CacheTTL: time.Second}
+}
+
+// WithHealthConfig 设置健康检查聚合配置
+func WithHealthConfig(cfg HealthConfig) Option {
+	return func(a *App) { a.health.cfg = cfg }
+}
+
+// CheckResult 单项检查结果
+type CheckResult struct {
+	Name     string        `json:"name"`
+	Healthy  bool          `json:"healthy"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// HealthReport 聚合检查结果
+type HealthReport struct {
+	State     string        `json:"state"`
+	Healthy   bool          `json:"healthy"`
+	Ready     bool          `json:"ready"`
+	Draining  bool          `json:"draining,omitempty"`
+	Checks    []CheckResult `json:"checks,omitempty"`
+	CheckedAt time.Time     `json:"checked_at"`
+}
+
+// Err 合并失败项错误，全部通过时返回 nil
+func (r HealthReport) Err() error {
+	var errs []error
+	for _, c := range r.Checks {
+		if !c.Healthy {
+			errs = append(errs, errors.New(c.Name+": "+c.Error))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type namedCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// healthAggregator 健康检查聚合状态
+type healthAggregator struct {
+	cfg    HealthConfig
+	mu     sync.Mutex
+	checks []namedCheck
+	cached *HealthReport
+	last   map[string]bool // 各检查上一次结果，用于记录状态变化
+	flight singleflight.Group
+}
+
+// AddHealthCheck 注册非组件的健康检查（如 storage.Manager、外部依赖）
+func (a *App) AddHealthCheck(name string, check func(ctx context.Context) error) {
+	a.health.mu.Lock()
+	defer a.health.mu.Unlock()
+	a.health.checks = append(a.health.checks, namedCheck{name, check})
+}
+
+// CheckHealth 并发执行已启动组件（HealthChecker）与注册的检查，结果在 CacheTTL 内复用。
+// 缓存过期时并发调用只执行一轮检查；检查不受调用方取消影响（仅受单项 Timeout 约束），
+// 调用方 ctx 先结束时返回失败结果
+func (a *App) CheckHealth(ctx context.Context) HealthReport {
+	h := &a.health
+	h.mu.Lock()
+	if h.cached != nil && time.Since(h.cached.CheckedAt) < h.cfg.CacheTTL {
+		report := *h.cached
+		h.mu.Unlock()
+		return a.withState(report)
+	}
+	h.mu.Unlock()
+
+	ch := h.flight.DoChan("health", func() (any, error) {
+		return a.runChecks(context.WithoutCancel(ctx)), nil
+	})
+	select {
+	case res := <-ch:
+		return a.withState(res.Val.(HealthReport))
+	case <-ctx.Done():
+		return a.withState(HealthReport{
+			Checks:    []CheckResult{{Name: "health", Error: ctx.Err().Error()}},
+			CheckedAt: time.Now(),
+		})
+	}
+}
+
+// runChecks 执行一轮检查并更新缓存
+func (a *App) runChecks(ctx context.Context) HealthReport {
+	h := &a.health
+	h.mu.Lock()
+	checks := append([]namedCheck(nil), h.checks...)
+	h.mu.Unlock()
+
+	a.mu.Lock()
+	for _, c := range a.components {
+		if hc, ok := c.component.(HealthChecker); ok && c.started {
+			checks = append(checks, namedCheck{c.component.Name(), hc.Health})
+		}
+	}
+	a.mu.Unlock()
+
+	report := HealthReport{Healthy: true, Checks: make([]CheckResult, len(checks)), CheckedAt: time.Now()}
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Go(func() {
+			report.Checks[i] = a.runCheck(ctx, c)
+		})
+	}
+	wg.Wait()
+	for _, c := range report.Checks {
+		report.Healthy = report.Healthy && c.Healthy
+	}
+
+	h.mu.Lock()
+	h.cached = &report
+	a.logTransitions(ctx, report.Checks)
+	h.mu.Unlock()
+	return report
+}
+
+func (a *App) runCheck(ctx context.Context, c namedCheck) CheckResult {
+	timeout := a.health.cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultHealthConfig().Timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- c.check(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	result := CheckResult{Name: c.name, Healthy: err == nil, Duration: time.Since(start)}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// logTransitions 记录检查状态变化，调用方需持有 a.health.mu
+func (a *App) logTransitions(ctx context.Context, checks []CheckResult) {
+	if a.health.last == nil {
+		a.health.last = make(map[string]bool)
+	}
+	for _, c := range checks {
+		prev, seen := a.health.last[c.Name]
+		a.health.last[c.Name] = c.Healthy
+		switch {
+		case c.Healthy && seen && !prev:
+			a.log.Info(ctx, "health check recovered", logger.String("name", c.Name))
+		case !c.Healthy && (!seen || prev):
+			a.log.Warn(ctx, "health check failing", logger.String("name", c.Name), logger.String("error", c.Error))
+		}
+	}
+}
+
+// withState 填充实时状态（状态与就绪不缓存）
+func (a *App) withState(r HealthReport) HealthReport {
+	r.State = a.State().String()
+	r.Ready = a.ready.Load() && r.Healthy
+	r.Draining = a.draining.Load()
+	return r
+}
+
+// HealthzHandler 存活探针：只反映进程自身，不执行依赖检查，仅在应用启动失败（StateFailed）时返回 503。
+// 启动、排空与停止过程中仍返回 200，避免依赖故障或慢启动导致容器被反复重启；依赖状态由 ReadyzHandler 反映
+func (a *App) HealthzHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state := a.State()
+		report := HealthReport{
+			State:     state.String(),
+			Healthy:   state != StateFailed,
+			Ready:     a.ready.Load(),
+			Draining:  a.draining.Load(),
+			CheckedAt: time.Now(),
+		}
+		writeHealth(w, r, report, report.Healthy)
+	})
+}
+
+// ReadyzHandler 就绪探针：就绪（未进入预停止）且所有检查通过返回 200，否则 503
+func (a *App) ReadyzHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := a.CheckHealth(r.Context())
+		writeHealth(w, r, report, report.Ready)
+	})
+}
+
+func writeHealth(w http.ResponseWriter, r *http.Request, report HealthReport, ok bool) {
+	status := http.StatusOK
+	if !ok {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if r.Method == http.MethodHead {
+		return
+	}
+	_ = json.NewEncoder(w).Encode(report)
+}
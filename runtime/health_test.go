@@ -0,0 +1,118 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func probe(h http.Handler) int {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	return rec.Code
+}
+
+func TestHealthz_NoDependencyChecks(t *testing.T) {
+	ctx := context.Background()
+	var calls atomic.Int32
+	app := New(DefaultConfig())
+	app.AddHealthCheck("db", func(context.Context) error {
+		calls.Add(1)
+		return errors.New("down")
+	})
+
+	if code := probe(app.HealthzHandler()); code != http.StatusOK {
+		t.Fatalf("liveness before start = %d", code)
+	}
+	if err := app.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if code := probe(app.HealthzHandler()); code != http.StatusOK {
+		t.Fatalf("liveness with failing dependency = %d", code)
+	}
+	if n := calls.Load(); n != 0 {
+		t.Fatalf("liveness ran %d dependency checks", n)
+	}
+	if code := probe(app.ReadyzHandler()); code != http.StatusServiceUnavailable {
+		t.Fatalf("readiness with failing dependency = %d", code)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("readiness checks = %d", calls.Load())
+	}
+	_ = app.Stop(ctx)
+}
+
+func TestHealthz_FailedStart(t *testing.T) {
+	app := New(DefaultConfig())
+	app.Register(NewFuncComponent("db", func(context.Context) error { return errors.New("boom") }, nil))
+	if err := app.Start(context.Background()); err == nil {
+		t.Fatal("expected start failure")
+	}
+	if code := probe(app.HealthzHandler()); code != http.StatusServiceUnavailable {
+		t.Fatalf("liveness after failed start = %d", code)
+	}
+}
+
+func TestCheckHealth_Singleflight(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	app := New(DefaultConfig(), WithHealthConfig(HealthConfig{Timeout: time.Second, CacheTTL: time.Minute}))
+	app.AddHealthCheck("slow", func(context.Context) error {
+		calls.Add(1)
+		<-release
+		return nil
+	})
+
+	var wg sync.WaitGroup
+	reports := make([]HealthReport, 8)
+	for i := range reports {
+		wg.Go(func() { reports[i] = app.CheckHealth(context.Background()) })
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("check ran %d times, want 1", n)
+	}
+	for _, r := range reports {
+		if !r.Healthy {
+			t.Fatalf("report = %+v", r)
+		}
+	}
+}
+
+func TestCheckHealth_CallerCancellation(t *testing.T) {
+	release := make(chan struct{})
+	var sawCancel atomic.Bool
+	app := New(DefaultConfig(), WithHealthConfig(HealthConfig{Timeout: time.Second, CacheTTL: time.Minute}))
+	app.AddHealthCheck("slow", func(ctx context.Context) error {
+		select {
+		case <-release:
+			return nil
+		case <-ctx.Done():
+			sawCancel.Store(true)
+			return ctx.Err()
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if r := app.CheckHealth(ctx); r.Healthy {
+		t.Fatal("caller with expired ctx got a healthy report")
+	}
+	close(release)
+
+	// 首个调用方的取消不应污染缓存结果
+	if r := app.CheckHealth(context.Background()); !r.Healthy {
+		t.Fatalf("report after caller cancellation = %+v", r)
+	}
+	if sawCancel.Load() {
+		t.Fatal("check observed the first caller's cancellation")
+	}
+}
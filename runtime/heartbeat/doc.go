@@ -0,0 +1,32 @@
+// Package heartbeat 提供心跳发布组件与缺失检测（死人开关）。
+//
+// 后台任务、批处理 worker 等没有入口流量的进程静默退出时很难被发现，
+// Publisher 作为 runtime 组件周期性发布心跳（含应用名、版本、实例 ID），
+// 消费端通过 Monitor 在心跳超时未到达时告警。组件停止时发出 Offline 心跳，
+// 正常下线的实例不会被当作缺失。
+//
+// 内置输出：
+//   - MetricSink：设置 heartbeat_timestamp_seconds 仪表盘，配合 Prometheus 告警规则
+//   - RedisSink：写入带 TTL 的键，键过期即视为心跳缺失
+//   - MQSink：发布到消息主题，由 Monitor.Handler 消费
+//
+// 使用示例：
+//
+//	// 应用名、版本与实例 ID 默认取自 runtime.AppInfo
+//	hb := heartbeat.NewPublisher(heartbeat.Info{},
+//	    heartbeat.WithInterval(15*time.Second),
+//	    heartbeat.WithSink(heartbeat.NewRedisSink(rdb, "heartbeat:", time.Minute)),
+//	)
+//	app.Register(hb)
+//
+//	// 消费端
+//	mon := heartbeat.NewMonitor(time.Minute, func(b heartbeat.Beat, late time.Duration) {
+//	    alert.Send("heartbeat missing", b.App, b.Instance)
+//	})
+//	consumer.Subscribe(ctx, "heartbeats", mon.Handler())
+//	go mon.Run(ctx, 10*time.Second)
+//
+//	// 或直接检查 Redis
+//	beat, err := heartbeat.LastBeat(ctx, rdb, "heartbeat:", "billing-worker", app.Info().InstanceID)
+//	if errors.Is(err, heartbeat.ErrMissing) { ... }
+package heartbeat
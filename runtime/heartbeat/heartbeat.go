@@ -0,0 +1,262 @@
+package heartbeat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/mildsunup/higo/logger"
+	"github.com/mildsunup/higo/mq"
+	"github.com/mildsunup/higo/observability"
+	"github.com/mildsunup/higo/runtime"
+)
+
+// ErrMissing 心跳缺失
+var ErrMissing = errors.New("heartbeat: missing")
+
+// Info 发布方标识，为空的字段在 Start 时取自 runtime.AppInfo（App 注入到组件 context）
+type Info struct {
+	App      string `json:"app"`
+	Version  string `json:"version,omitempty"`
+	Instance string `json:"instance"` // 默认 AppInfo.InstanceID，与 /version 及日志中的实例 ID 一致
+}
+
+// Beat 一次心跳
+type Beat struct {
+	Info
+	Seq       uint64    `json:"seq"`
+	StartedAt time.Time `json:"started_at"`
+	At        time.Time `json:"at"`
+	Offline   bool      `json:"offline,omitempty"` // 正常停止时发出的最后一次心跳，消费端应停止跟踪该实例
+}
+
+// Sink 心跳输出
+type Sink interface {
+	Beat(ctx context.Context, b Beat) error
+}
+
+// SinkFunc 函数式 Sink
+type SinkFunc func(ctx context.Context, b Beat) error
+
+// Beat 实现 Sink
+func (f SinkFunc) Beat(ctx context.Context, b Beat) error { return f(ctx, b) }
+
+// Option 发布器选项
+type Option func(*Publisher)
+
+// WithInterval 设置发布间隔（默认 30s）
+func WithInterval(d time.Duration) Option {
+	return func(p *Publisher) {
+		if d > 0 {
+			p.interval = d
+		}
+	}
+}
+
+// WithSink 添加输出，可多次调用
+func WithSink(s Sink) Option {
+	return func(p *Publisher) { p.sinks = append(p.sinks, s) }
+}
+
+// WithLogger 设置日志
+func WithLogger(l logger.Logger) Option {
+	return func(p *Publisher) { p.log = l }
+}
+
+// Publisher 心跳发布组件
+type Publisher struct {
+	info     Info
+	interval time.Duration
+	sinks    []Sink
+	log      logger.Logger
+
+	seq     uint64
+	started time.Time
+	cancel  context.CancelFunc
+	done    chan struct{}
+	mu      sync.Mutex
+	lastErr error
+}
+
+// NewPublisher 创建心跳发布组件
+func NewPublisher(info Info, opts ...Option) *Publisher {
+	p := &Publisher{
+		info:     info,
+		interval: 30 * time.Second,
+		log:      logger.Nop(),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Name 实现 runtime.Component
+func (p *Publisher) Name() string { return "heartbeat" }
+
+// Start 立即发布一次心跳并启动周期发布
+func (p *Publisher) Start(ctx context.Context) error {
+	p.resolveInfo(ctx)
+	p.started = time.Now()
+	p.beat(ctx, false)
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	p.done = make(chan struct{})
+	go p.loop(runCtx)
+	return nil
+}
+
+// Stop 停止周期发布并发出一次下线心跳（Offline），避免正常停止的实例被当作心跳缺失
+func (p *Publisher) Stop(ctx context.Context) error {
+	if p.cancel == nil {
+		return nil
+	}
+	p.cancel()
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	p.cancel = nil
+	p.beat(ctx, true)
+	return nil
+}
+
+// Health 最近一次发布全部失败时返回错误
+func (p *Publisher) Health(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.lastErr
+}
+
+func (p *Publisher) loop(ctx context.Context) {
+	defer close(p.done)
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.beat(ctx, false)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (p *Publisher) beat(ctx context.Context, offline bool) {
+	p.seq++
+	b := Beat{Info: p.info, Seq: p.seq, StartedAt: p.started, At: time.Now(), Offline: offline}
+
+	ctx, cancel := context.WithTimeout(ctx, p.interval)
+	defer cancel()
+
+	var errs []error
+	for _, s := range p.sinks {
+		if err := s.Beat(ctx, b); err != nil {
+			p.log.Warn(ctx, "heartbeat publish failed", logger.String("instance", b.Instance), logger.Err(err))
+			errs = append(errs, err)
+		}
+	}
+
+	p.mu.Lock()
+	p.lastErr = nil
+	if len(errs) > 0 && len(errs) == len(p.sinks) {
+		p.lastErr = errors.Join(errs...)
+	}
+	p.mu.Unlock()
+}
+
+// resolveInfo 用应用元数据补全发布方标识；不在 App 中运行时生成新的实例 ID
+func (p *Publisher) resolveInfo(ctx context.Context) {
+	app, ok := runtime.InfoFromContext(ctx)
+	if !ok {
+		if p.info.Instance != "" {
+			return
+		}
+		app = runtime.NewAppInfo(p.info.App, "", p.info.Version)
+	}
+	if p.info.App == "" {
+		p.info.App = app.Name
+	}
+	if p.info.Version == "" {
+		p.info.Version = app.Version
+	}
+	if p.info.Instance == "" {
+		p.info.Instance = app.InstanceID
+	}
+}
+
+// NewMetricSink 以 Unix 秒设置 heartbeat_timestamp_seconds{app,instance,version}，
+// 下线心跳将 heartbeat_offline{app,instance,version} 置 1
+// 告警示例：time() - heartbeat_timestamp_seconds > 120 unless heartbeat_offline == 1
+func NewMetricSink(provider observability.MetricsProvider) Sink {
+	g := provider.Gauge("heartbeat_timestamp_seconds", "Unix time of the last heartbeat", "app", "instance", "version")
+	offline := provider.Gauge("heartbeat_offline", "1 after the instance stopped gracefully", "app", "instance", "version")
+	return SinkFunc(func(ctx context.Context, b Beat) error {
+		g.Set(float64(b.At.UnixNano())/1e9, b.App, b.Instance, b.Version)
+		if b.Offline {
+			offline.Set(1, b.App, b.Instance, b.Version)
+		} else {
+			offline.Set(0, b.App, b.Instance, b.Version)
+		}
+		return nil
+	})
+}
+
+// NewRedisSink 写入 {prefix}{app}:{instance}，TTL 过期即表示心跳缺失；
+// 下线心跳同样写入（Offline 为 true），LastBeat 的调用方据此区分正常停止
+func NewRedisSink(client redis.Cmdable, prefix string, ttl time.Duration) Sink {
+	return SinkFunc(func(ctx context.Context, b Beat) error {
+		data, err := json.Marshal(b)
+		if err != nil {
+			return err
+		}
+		return client.Set(ctx, redisKey(prefix, b.App, b.Instance), data, ttl).Err()
+	})
+}
+
+// NewMQSink 发布到指定主题，以实例 ID 作为消息键
+func NewMQSink(producer mq.Producer, topic string) Sink {
+	return SinkFunc(func(ctx context.Context, b Beat) error {
+		data, err := json.Marshal(b)
+		if err != nil {
+			return err
+		}
+		_, err = producer.Publish(ctx, topic, data, mq.WithKey(b.Instance))
+		return err
+	})
+}
+
+// LastBeat 读取 Redis 中某实例的最近心跳，键不存在时返回 ErrMissing
+func LastBeat(ctx context.Context, client redis.Cmdable, prefix, app, instance string) (Beat, error) {
+	var b Beat
+	data, err := client.Get(ctx, redisKey(prefix, app, instance)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return b, ErrMissing
+	}
+	if err != nil {
+		return b, err
+	}
+	if err := json.Unmarshal(data, &b); err != nil {
+		return b, fmt.Errorf("heartbeat: decode: %w", err)
+	}
+	return b, nil
+}
+
+func redisKey(prefix, app, instance string) string {
+	if prefix == "" {
+		prefix = "heartbeat:"
+	}
+	return prefix + app + ":" + instance
+}
+
+var (
+	_ runtime.Component     = (*Publisher)(nil)
+	_ runtime.HealthChecker = (*Publisher)(nil)
+)
@@ -0,0 +1,191 @@
+package heartbeat
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/mildsunup/higo/observability"
+	"github.com/mildsunup/higo/runtime"
+)
+
+// recorder 记录收到的心跳
+type recorder struct {
+	mu    sync.Mutex
+	beats []Beat
+}
+
+func (r *recorder) Beat(_ context.Context, b Beat) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.beats = append(r.beats, b)
+	return nil
+}
+
+func (r *recorder) last() Beat {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.beats[len(r.beats)-1]
+}
+
+func TestPublisher_UsesAppInfo(t *testing.T) {
+	ctx := context.Background()
+	rec := &recorder{}
+	info := runtime.NewAppInfo("billing", "prod", "v1.2.3")
+	app := runtime.New(runtime.DefaultConfig(), runtime.WithAppInfo(info))
+	app.Register(NewPublisher(Info{}, WithSink(rec)))
+	if err := app.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer app.Stop(ctx)
+
+	b := rec.last()
+	if b.Instance != info.InstanceID || b.App != "billing" || b.Version != "v1.2.3" {
+		t.Fatalf("beat = %+v, want instance %s", b.Info, info.InstanceID)
+	}
+}
+
+func TestPublisher_ExplicitInfo(t *testing.T) {
+	ctx := runtime.ContextWithInfo(context.Background(), runtime.NewAppInfo("billing", "", "v1"))
+	rec := &recorder{}
+	p := NewPublisher(Info{App: "worker", Instance: "w-1"}, WithSink(rec))
+	if err := p.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop(ctx)
+	if b := rec.last(); b.App != "worker" || b.Instance != "w-1" || b.Version != "v1" {
+		t.Fatalf("beat = %+v", b.Info)
+	}
+}
+
+func TestPublisher_StandaloneInstance(t *testing.T) {
+	ctx := context.Background()
+	rec := &recorder{}
+	p := NewPublisher(Info{App: "worker"}, WithSink(rec))
+	if err := p.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop(ctx)
+	if b := rec.last(); b.Instance == "" || !strings.Contains(b.Instance, "-") {
+		t.Fatalf("instance = %q", b.Instance)
+	}
+}
+
+func TestPublisher_OfflineBeatOnStop(t *testing.T) {
+	ctx := context.Background()
+	rec := &recorder{}
+	p := NewPublisher(Info{App: "worker", Instance: "w-1"}, WithSink(rec), WithInterval(time.Hour))
+	if err := p.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if rec.last().Offline {
+		t.Fatal("first beat marked offline")
+	}
+	if err := p.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if b := rec.last(); !b.Offline || b.Seq != 2 {
+		t.Fatalf("last beat = %+v", b)
+	}
+	// 重复 Stop 不再发送
+	_ = p.Stop(ctx)
+	if n := len(rec.beats); n != 2 {
+		t.Fatalf("beats = %d", n)
+	}
+}
+
+func TestMonitor_OfflineForgets(t *testing.T) {
+	var alerts []string
+	m := NewMonitor(time.Minute, func(b Beat, _ time.Duration) { alerts = append(alerts, b.Instance) })
+	now := time.Now()
+	m.Observe(Beat{Info: Info{App: "worker", Instance: "w-1"}, At: now})
+	m.Observe(Beat{Info: Info{App: "worker", Instance: "w-1"}, At: now.Add(time.Second), Offline: true})
+	if m.Len() != 0 {
+		t.Fatalf("tracked = %d after offline beat", m.Len())
+	}
+	m.Check(now.Add(time.Hour))
+	if len(alerts) != 0 {
+		t.Fatalf("alerted for a stopped instance: %v", alerts)
+	}
+}
+
+func TestMonitor_Evict(t *testing.T) {
+	var alerts []string
+	m := NewMonitor(time.Minute, func(b Beat, _ time.Duration) { alerts = append(alerts, b.Instance) }, WithEvictAfter(5*time.Minute))
+	now := time.Now()
+	m.Expect("worker", "pinned")
+	m.Observe(Beat{Info: Info{App: "worker", Instance: "w-1"}, At: now})
+
+	m.Check(now.Add(2 * time.Minute))
+	if len(alerts) != 2 || m.Len() != 2 {
+		t.Fatalf("alerts = %v, tracked = %d", alerts, m.Len())
+	}
+	m.Check(now.Add(10 * time.Minute))
+	if len(alerts) != 2 {
+		t.Fatalf("re-alerted: %v", alerts)
+	}
+	// 缺失过久的实例被移除，Expect 登记的实例保留
+	if missing := m.Missing(now.Add(10 * time.Minute)); len(missing) != 1 || missing[0].Instance != "pinned" {
+		t.Fatalf("missing = %+v", missing)
+	}
+}
+
+func TestRedisSink_LastBeat(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	p := NewPublisher(Info{App: "worker", Instance: "w-1"}, WithSink(NewRedisSink(client, "", time.Minute)), WithInterval(time.Hour))
+	if _, err := LastBeat(ctx, client, "", "worker", "w-1"); !errors.Is(err, ErrMissing) {
+		t.Fatalf("before start = %v", err)
+	}
+	if err := p.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := LastBeat(ctx, client, "", "worker", "w-1"); err != nil || b.Seq != 1 || b.Offline {
+		t.Fatalf("beat = %+v, %v", b, err)
+	}
+	if ttl := mr.TTL("heartbeat:worker:w-1"); ttl != time.Minute {
+		t.Fatalf("ttl = %v", ttl)
+	}
+	_ = p.Stop(ctx)
+	if b, err := LastBeat(ctx, client, "", "worker", "w-1"); err != nil || !b.Offline {
+		t.Fatalf("after stop = %+v, %v", b, err)
+	}
+}
+
+func TestMetricSink(t *testing.T) {
+	ctx := context.Background()
+	reg := prometheus.NewRegistry()
+	sink := NewMetricSink(observability.NewPrometheusProvider(reg))
+	at := time.Unix(1700000000, 0)
+	b := Beat{Info: Info{App: "worker", Instance: "w-1", Version: "v1"}, At: at}
+	_ = sink.Beat(ctx, b)
+
+	gauge := func(name string) float64 {
+		families, _ := reg.Gather()
+		for _, f := range families {
+			if strings.HasSuffix(f.GetName(), name) {
+				return f.GetMetric()[0].GetGauge().GetValue()
+			}
+		}
+		t.Fatalf("metric %s not found", name)
+		return 0
+	}
+	if v := gauge("heartbeat_timestamp_seconds"); v != 1700000000 {
+		t.Fatalf("timestamp = %v", v)
+	}
+	b.Offline = true
+	_ = sink.Beat(ctx, b)
+	if v := gauge("heartbeat_offline"); v != 1 {
+		t.Fatalf("offline = %v", v)
+	}
+}
@@ -0,0 +1,162 @@
+package heartbeat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mildsunup/higo/mq"
+)
+
+// AlertFunc 心跳缺失回调，late 为距最近一次心跳的时长
+type AlertFunc func(b Beat, late time.Duration)
+
+// Monitor 心跳监视器：记录各实例最近心跳，超过 timeout 未收到时告警
+// 同一实例缺失只告警一次，恢复后重新计数；收到下线心跳的实例直接移除，
+// 缺失超过 evictAfter 的实例在告警后移除（Expect 登记的实例除外），避免实例 ID 更替导致无限增长。
+type Monitor struct {
+	timeout    time.Duration
+	evictAfter time.Duration
+	alert      AlertFunc
+
+	mu       sync.Mutex
+	last     map[string]Beat
+	alerted  map[string]bool
+	expected map[string]bool
+}
+
+// MonitorOption 监视器选项
+type MonitorOption func(*Monitor)
+
+// WithEvictAfter 设置缺失实例的移除时长（默认 10 倍 timeout），不小于 timeout
+func WithEvictAfter(d time.Duration) MonitorOption {
+	return func(m *Monitor) { m.evictAfter = d }
+}
+
+// NewMonitor 创建心跳监视器
+func NewMonitor(timeout time.Duration, alert AlertFunc, opts ...MonitorOption) *Monitor {
+	m := &Monitor{
+		timeout:    timeout,
+		evictAfter: 10 * timeout,
+		alert:      alert,
+		last:       make(map[string]Beat),
+		alerted:    make(map[string]bool),
+		expected:   make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	m.evictAfter = max(m.evictAfter, timeout)
+	return m
+}
+
+// Expect 预先登记期望的实例，从未上报心跳的实例也会在超时后告警，且不会因缺失过久被移除
+func (m *Monitor) Expect(app, instance string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	k := app + ":" + instance
+	m.expected[k] = true
+	if _, ok := m.last[k]; !ok {
+		m.last[k] = Beat{Info: Info{App: app, Instance: instance}, At: time.Now()}
+	}
+}
+
+// Observe 记录心跳，乱序到达的旧心跳被忽略，下线心跳移除该实例
+func (m *Monitor) Observe(b Beat) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	k := b.App + ":" + b.Instance
+	if prev, ok := m.last[k]; ok && prev.At.After(b.At) {
+		return
+	}
+	if b.Offline {
+		m.forget(k)
+		return
+	}
+	m.last[k] = b
+	delete(m.alerted, k)
+}
+
+// Forget 移除实例（如正常下线）
+func (m *Monitor) Forget(app, instance string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.forget(app + ":" + instance)
+}
+
+func (m *Monitor) forget(k string) {
+	delete(m.last, k)
+	delete(m.alerted, k)
+	delete(m.expected, k)
+}
+
+// Len 当前跟踪的实例数
+func (m *Monitor) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.last)
+}
+
+// Missing 返回截至 now 已超时的实例最近心跳，按时间升序
+func (m *Monitor) Missing(now time.Time) []Beat {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []Beat
+	for _, b := range m.last {
+		if now.Sub(b.At) > m.timeout {
+			out = append(out, b)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].At.Before(out[j].At) })
+	return out
+}
+
+// Check 检查一次，对新出现的缺失触发告警，并移除缺失超过 evictAfter 的实例
+func (m *Monitor) Check(now time.Time) {
+	for _, b := range m.Missing(now) {
+		k := b.App + ":" + b.Instance
+		m.mu.Lock()
+		if cur, ok := m.last[k]; !ok || !cur.At.Equal(b.At) {
+			m.mu.Unlock() // 期间已收到新心跳或已移除
+			continue
+		}
+		fired := m.alerted[k]
+		m.alerted[k] = true
+		if fired && !m.expected[k] && now.Sub(b.At) > m.evictAfter {
+			m.forget(k)
+		}
+		m.mu.Unlock()
+		if !fired && m.alert != nil {
+			m.alert(b, now.Sub(b.At))
+		}
+	}
+}
+
+// Run 按 interval 周期检查，直到 ctx 结束
+func (m *Monitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			m.Check(now)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Handler 返回消费 MQSink 心跳消息的处理器
+func (m *Monitor) Handler() mq.Handler {
+	return func(ctx context.Context, msg *mq.Message) error {
+		var b Beat
+		if err := json.Unmarshal(msg.Value, &b); err != nil {
+			return fmt.Errorf("heartbeat: decode: %w", err)
+		}
+		m.Observe(b)
+		return nil
+	}
+}
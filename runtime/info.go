@@ -0,0 +1,139 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	goruntime "runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/mildsunup/higo/observability"
+)
+
+// 构建信息，通过 -ldflags 注入（未注入时从 Go 构建信息中读取 VCS 修订）：
+//
+//	go build -ldflags "-X github.com/mildsunup/higo/runtime.Version=v1.2.3 \
+//	    -X github.com/mildsunup/higo/runtime.Commit=$(git rev-parse HEAD) \
+//	    -X github.com/mildsunup/higo/runtime.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	Version   string
+	Commit    string
+	BuildTime string
+)
+
+// AppInfo 应用元数据
+type AppInfo struct {
+	Name       string    `json:"name"`
+	Env        string    `json:"env,omitempty"`
+	Version    string    `json:"version"`
+	Commit     string    `json:"commit,omitempty"`
+	Modified   bool      `json:"modified,omitempty"` // 构建时工作区有未提交的修改
+	BuildTime  string    `json:"build_time,omitempty"`
+	GoVersion  string    `json:"go_version"`
+	InstanceID string    `json:"instance_id"`
+	StartTime  time.Time `json:"start_time"`
+}
+
+// NewAppInfo 创建应用元数据：version 为空时依次使用 -ldflags 注入的 Version、模块版本，最后为 "dev"；
+// 提交号取 -ldflags 注入的 Commit 或 VCS 修订；InstanceID 为 hostname-随机后缀
+func NewAppInfo(name, env, version string) AppInfo {
+	info := AppInfo{
+		Name:       name,
+		Env:        env,
+		Version:    version,
+		Commit:     Commit,
+		BuildTime:  BuildTime,
+		GoVersion:  goruntime.Version(),
+		InstanceID: instanceID(),
+		StartTime:  time.Now(),
+	}
+	if info.Version == "" {
+		info.Version = Version
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+			info.Version = bi.Main.Version
+		}
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = s.Value
+				}
+			case "vcs.time":
+				if info.BuildTime == "" {
+					info.BuildTime = s.Value
+				}
+			case "vcs.modified":
+				info.Modified = s.Value == "true"
+			}
+		}
+	}
+	if info.Version == "" {
+		info.Version = "dev"
+	}
+	return info
+}
+
+// Uptime 自启动以来的时长
+func (i AppInfo) Uptime() time.Duration { return time.Since(i.StartTime) }
+
+func instanceID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "unknown"
+	}
+	return host + "-" + uuid.NewString()[:8]
+}
+
+// WithAppInfo 设置应用元数据，默认由 Config 的 Name、Env、Version 生成
+func WithAppInfo(info AppInfo) Option {
+	return func(a *App) { a.info = info }
+}
+
+// WithBuildInfoMetrics 导出 build_info{name,version,commit,go_version,env} 恒为 1 的指标，
+// 便于在看板中按版本聚合与关联发布
+func WithBuildInfoMetrics(p observability.MetricsProvider) Option {
+	return func(a *App) { a.buildInfo = p }
+}
+
+func (a *App) registerBuildInfo() {
+	if a.buildInfo == nil {
+		return
+	}
+	g := a.buildInfo.Gauge("build_info", "Build information, value is always 1",
+		"name", "version", "commit", "go_version", "env")
+	g.Set(1, a.info.Name, a.info.Version, a.info.Commit, a.info.GoVersion, a.info.Env)
+}
+
+// Info 返回应用元数据
+func (a *App) Info() AppInfo { return a.info }
+
+// VersionHandler 返回应用元数据（JSON），可挂载为 /version
+func (a *App) VersionHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		_ = json.NewEncoder(w).Encode(struct {
+			AppInfo
+			Uptime string `json:"uptime"`
+		}{a.info, a.info.Uptime().Truncate(time.Second).String()})
+	})
+}
+
+type appInfoKey struct{}
+
+// ContextWithInfo 将应用元数据放入 context；App 启动时自动注入到传给钩子与组件的 context
+func ContextWithInfo(ctx context.Context, info AppInfo) context.Context {
+	return context.WithValue(ctx, appInfoKey{}, info)
+}
+
+// InfoFromContext 从 context 获取应用元数据
+func InfoFromContext(ctx context.Context) (AppInfo, bool) {
+	info, ok := ctx.Value(appInfoKey{}).(AppInfo)
+	return info, ok
+}
@@ -0,0 +1,37 @@
+package runtime
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestNewAppInfo(t *testing.T) {
+	a, b := NewAppInfo("billing", "prod", ""), NewAppInfo("billing", "prod", "")
+	host, _ := os.Hostname()
+	if !strings.HasPrefix(a.InstanceID, host+"-") || a.InstanceID == b.InstanceID {
+		t.Fatalf("instance ids %q, %q", a.InstanceID, b.InstanceID)
+	}
+	if a.Version == "" || a.GoVersion == "" || a.StartTime.IsZero() {
+		t.Fatalf("info = %+v", a)
+	}
+}
+
+func TestAppInfo_InjectedIntoComponents(t *testing.T) {
+	ctx := context.Background()
+	info := NewAppInfo("billing", "prod", "v1")
+	var got AppInfo
+	app := New(DefaultConfig(), WithAppInfo(info))
+	app.Register(NewFuncComponent("probe", func(ctx context.Context) error {
+		got, _ = InfoFromContext(ctx)
+		return nil
+	}, func(context.Context) error { return nil }))
+	if err := app.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer app.Stop(ctx)
+	if got.InstanceID != info.InstanceID || app.Info().InstanceID != info.InstanceID {
+		t.Fatalf("component saw %q, app has %q, want %q", got.InstanceID, app.Info().InstanceID, info.InstanceID)
+	}
+}
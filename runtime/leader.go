@@ -0,0 +1,317 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mildsunup/higo/lock"
+	"github.com/mildsunup/higo/logger"
+)
+
+// LeaderConfig 选主配置
+type LeaderConfig struct {
+	Key           string        `yaml:"key" mapstructure:"key"`                       // 租约键，默认 leader:<应用名>
+	TTL           time.Duration `yaml:"ttl" mapstructure:"ttl"`                       // 租约时长，默认 15s
+	RenewInterval time.Duration `yaml:"renew_interval" mapstructure:"renew_interval"` // 续约间隔，默认 TTL/3
+	RetryInterval time.Duration `yaml:"retry_interval" mapstructure:"retry_interval"` // 非 leader 时的竞选间隔，默认 TTL/3
+	StopTimeout   time.Duration `yaml:"stop_timeout" mapstructure:"stop_timeout"`     // 失去租约后停止组件的超时，默认 TTL
+}
+
+// WithLeaderElection 启用选主：标记为 LeaderOnly 的组件只在持有租约的实例上运行。
+// 租约通过 lock 包获取与续约（Redis 等分布式实现），续约失败或租约被他人持有时视为失去 leader。
+func WithLeaderElection(locker lock.Locker, cfg LeaderConfig) Option {
+	return func(a *App) {
+		if cfg.Key == "" {
+			cfg.Key = "leader:" + a.cfg.Name
+		}
+		if cfg.TTL <= 0 {
+			cfg.TTL = 15 * time.Second
+		}
+		if cfg.RenewInterval <= 0 {
+			cfg.RenewInterval = cfg.TTL / 3
+		}
+		if cfg.RetryInterval <= 0 {
+			cfg.RetryInterval = cfg.TTL / 3
+		}
+		if cfg.StopTimeout <= 0 {
+			cfg.StopTimeout = cfg.TTL
+		}
+		a.leader = &leaderElector{app: a, locker: locker, cfg: cfg}
+	}
+}
+
+// LeaderOnly 标记组件仅在 leader 实例上运行（如 outbox 中继、调度器），需配合 WithLeaderElection：
+//
+//	app := runtime.New(cfg, runtime.WithLeaderElection(locker, runtime.LeaderConfig{}))
+//	app.Register(relay, runtime.LeaderOnly())
+//
+// 应用启动时组件并不立即启动，而是在获得租约后启动、失去租约后停止；应用停止时释放租约。
+// 获得租约后任一成员启动失败时让出租约，在下次竞选时重试。
+// 未启用 WithLeaderElection 时 Register 会 panic。
+func LeaderOnly() ComponentOption {
+	return func(e *componentEntry) { e.leaderOnly = true }
+}
+
+// IsLeader 当前实例是否持有 leader 租约
+func (a *App) IsLeader() bool {
+	return a.leader != nil && a.leader.leading.Load()
+}
+
+// leaderElector 竞选与续约租约，在 leader 变化时启停成员组件
+type leaderElector struct {
+	app     *App
+	locker  lock.Locker
+	cfg     LeaderConfig
+	leading atomic.Bool
+
+	mu      sync.Mutex // 保护成员列表并串行化成员启停
+	members []*leaderGate
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// join 加入成员，首个成员加入时开始竞选；已是 leader 时立即启动该成员
+func (e *leaderElector) join(ctx context.Context, g *leaderGate) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.members = append(e.members, g)
+	if e.cancel == nil {
+		loopCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		e.cancel, e.done = cancel, make(chan struct{})
+		go e.run(loopCtx, e.done)
+	}
+	if e.leading.Load() {
+		return g.activate(ctx)
+	}
+	return nil
+}
+
+// leave 移除并停止成员，最后一个成员离开时停止竞选并释放租约
+func (e *leaderElector) leave(ctx context.Context, g *leaderGate) error {
+	e.mu.Lock()
+	e.members = slices.DeleteFunc(e.members, func(m *leaderGate) bool { return m == g })
+	err := g.deactivate(ctx)
+	var (
+		cancel context.CancelFunc
+		done   chan struct{}
+	)
+	if len(e.members) == 0 && e.cancel != nil {
+		cancel, done = e.cancel, e.done
+		e.cancel, e.done = nil, nil
+	}
+	e.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		}
+	}
+	return err
+}
+
+func (e *leaderElector) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	lease := e.locker.NewLock(e.cfg.Key, lock.WithTTL(e.cfg.TTL), lock.WithRetryCount(0))
+	var renewed time.Time
+
+	for {
+		wait := e.cfg.RetryInterval
+		if e.leading.Load() {
+			wait = e.cfg.RenewInterval
+			err := lease.Refresh(ctx)
+			switch {
+			case err == nil:
+				renewed = time.Now()
+			case ctx.Err() != nil:
+			case errors.Is(err, lock.ErrLockExpired), errors.Is(err, lock.ErrNotHeld),
+				time.Since(renewed)+e.cfg.RenewInterval >= e.cfg.TTL:
+				// 租约已失效，或下次续约前必然过期：立即让出，避免两个实例同时运行
+				e.step(ctx, false, err)
+				wait = e.cfg.RetryInterval
+			default:
+				e.app.log.Warn(ctx, "leader lease renewal failed, retrying",
+					logger.String("key", e.cfg.Key), logger.Err(err))
+			}
+		} else {
+			ok, err := lease.TryLock(ctx)
+			if err != nil && ctx.Err() == nil {
+				e.app.log.Warn(ctx, "leader election failed", logger.String("key", e.cfg.Key), logger.Err(err))
+			}
+			if ok {
+				renewed = time.Now()
+				if err := e.step(ctx, true, nil); err != nil {
+					// 成员未能全部启动：让出租约，由其他实例接管或在下次竞选时重试
+					e.release(lease)
+				} else {
+					wait = e.cfg.RenewInterval
+				}
+			}
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			e.resign(lease)
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// step 切换 leader 状态并启停成员组件。
+// 成为 leader 时任一成员启动失败，则停止已启动的成员、退回非 leader 状态并返回错误，由调用方让出租约
+func (e *leaderElector) step(ctx context.Context, leading bool, cause error) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.leading.Store(leading)
+
+	if leading {
+		e.app.log.Info(ctx, "leader lease acquired", logger.String("key", e.cfg.Key))
+		var errs []error
+		for _, g := range e.members {
+			if err := g.activate(ctx); err != nil {
+				e.app.log.Error(ctx, "leader-only component failed to start",
+					logger.String("name", g.Name()), logger.Err(err))
+				errs = append(errs, err)
+			}
+		}
+		if len(errs) == 0 {
+			return nil
+		}
+		e.app.log.Warn(ctx, "resigning leadership", logger.String("key", e.cfg.Key))
+		e.leading.Store(false)
+		e.deactivateAll(ctx)
+		return errors.Join(errs...)
+	}
+
+	e.app.log.Warn(ctx, "leader lease lost", logger.String("key", e.cfg.Key), logger.Err(cause))
+	e.deactivateAll(ctx)
+	return nil
+}
+
+// deactivateAll 逆序停止全部成员，调用方需持有 e.mu
+func (e *leaderElector) deactivateAll(ctx context.Context) {
+	stopCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), e.cfg.StopTimeout)
+	defer cancel()
+	for _, g := range slices.Backward(e.members) {
+		if err := g.deactivate(stopCtx); err != nil {
+			e.app.log.Error(ctx, "leader-only component failed to stop",
+				logger.String("name", g.Name()), logger.Err(err))
+		}
+	}
+}
+
+// resign 停止竞选时释放租约（成员此时均已停止）
+func (e *leaderElector) resign(lease lock.Lock) {
+	if !e.leading.Swap(false) {
+		return
+	}
+	e.release(lease)
+}
+
+// release 释放租约
+func (e *leaderElector) release(lease lock.Lock) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := lease.Unlock(ctx); err != nil {
+		e.app.log.Warn(ctx, "leader lease release failed", logger.String("key", e.cfg.Key), logger.Err(err))
+		return
+	}
+	e.app.log.Info(ctx, "leader lease released", logger.String("key", e.cfg.Key))
+}
+
+// leaderGate 包装 LeaderOnly 组件：App 启停的是门控，实际组件随租约启停
+type leaderGate struct {
+	Component
+	elector *leaderElector
+	active  bool // 由 elector.mu 保护
+}
+
+func (g *leaderGate) Start(ctx context.Context) error { return g.elector.join(ctx, g) }
+func (g *leaderGate) Stop(ctx context.Context) error  { return g.elector.leave(ctx, g) }
+
+// activate 启动实际组件，调用方需持有 elector.mu
+func (g *leaderGate) activate(ctx context.Context) error {
+	if g.active {
+		return nil
+	}
+	start := time.Now()
+	err := g.Component.Start(ctx)
+	g.elector.app.record(ctx, g.Name(), "leader_start", start, err)
+	if err != nil {
+		return fmt.Errorf("leader-only component %s: %w", g.Name(), err)
+	}
+	g.active = true
+	return nil
+}
+
+// deactivate 停止实际组件，调用方需持有 elector.mu
+func (g *leaderGate) deactivate(ctx context.Context) error {
+	if !g.active {
+		return nil
+	}
+	g.active = false
+	start := time.Now()
+	err := g.Component.Stop(ctx)
+	g.elector.app.record(ctx, g.Name(), "leader_stop", start, err)
+	return err
+}
+
+func (g *leaderGate) isActive() bool {
+	g.elector.mu.Lock()
+	defer g.elector.mu.Unlock()
+	return g.active
+}
+
+// Health 仅在组件运行时检查；非 leader 实例上组件未运行属正常状态
+func (g *leaderGate) Health(ctx context.Context) error {
+	if hc, ok := g.Component.(HealthChecker); ok && g.isActive() {
+		return hc.Health(ctx)
+	}
+	return nil
+}
+
+func (g *leaderGate) Drain(ctx context.Context) error {
+	if d, ok := g.Component.(Drainer); ok && g.isActive() {
+		return d.Drain(ctx)
+	}
+	return nil
+}
+
+func (g *leaderGate) Quiesce(ctx context.Context) (int, error) {
+	if q, ok := g.Component.(Quiescer); ok && g.isActive() {
+		return q.Quiesce(ctx)
+	}
+	return 0, nil
+}
+
+func (g *leaderGate) Flush(ctx context.Context) error {
+	if f, ok := g.Component.(Flusher); ok && g.isActive() {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
+func (g *leaderGate) Pending() int {
+	if f, ok := g.Component.(Flusher); ok && g.isActive() {
+		return f.Pending()
+	}
+	return 0
+}
+
+// unwrapComponent 返回门控包装下的实际组件
+func unwrapComponent(c Component) Component {
+	if g, ok := c.(*leaderGate); ok {
+		return g.Component
+	}
+	return c
+}
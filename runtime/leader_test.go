@@ -0,0 +1,189 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mildsunup/higo/lock"
+)
+
+// fakeLocker 进程内租约，可注入续约错误
+type fakeLocker struct {
+	mu         sync.Mutex
+	holder     *fakeLock
+	refreshErr error
+	unlocks    int
+}
+
+func (l *fakeLocker) NewLock(string, ...lock.Option) lock.Lock { return &fakeLock{locker: l} }
+
+func (l *fakeLocker) setRefreshErr(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refreshErr = err
+	if err != nil {
+		l.holder = nil // 租约已被他人接管或过期
+	}
+}
+
+func (l *fakeLocker) state() (held bool, unlocks int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.holder != nil, l.unlocks
+}
+
+type fakeLock struct{ locker *fakeLocker }
+
+func (f *fakeLock) Lock(ctx context.Context) error { return errors.New("not supported") }
+
+func (f *fakeLock) TryLock(context.Context) (bool, error) {
+	f.locker.mu.Lock()
+	defer f.locker.mu.Unlock()
+	if f.locker.holder != nil && f.locker.holder != f {
+		return false, nil
+	}
+	if f.locker.refreshErr != nil {
+		return false, nil
+	}
+	f.locker.holder = f
+	return true, nil
+}
+
+func (f *fakeLock) Unlock(context.Context) error {
+	f.locker.mu.Lock()
+	defer f.locker.mu.Unlock()
+	if f.locker.holder != f {
+		return lock.ErrNotHeld
+	}
+	f.locker.holder = nil
+	f.locker.unlocks++
+	return nil
+}
+
+func (f *fakeLock) Refresh(context.Context) error {
+	f.locker.mu.Lock()
+	defer f.locker.mu.Unlock()
+	if f.locker.refreshErr != nil {
+		return f.locker.refreshErr
+	}
+	if f.locker.holder != f {
+		return lock.ErrNotHeld
+	}
+	return nil
+}
+
+// flakyComponent 前 failures 次启动失败
+type flakyComponent struct {
+	countingComponent
+	failures atomic.Int32
+	running  atomic.Bool
+}
+
+func (c *flakyComponent) Start(ctx context.Context) error {
+	c.starts.Add(1)
+	if c.failures.Add(-1) >= 0 {
+		return errors.New("boom")
+	}
+	c.running.Store(true)
+	return nil
+}
+
+func (c *flakyComponent) Stop(ctx context.Context) error {
+	c.stops.Add(1)
+	c.running.Store(false)
+	return nil
+}
+
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func leaderApp(locker lock.Locker, c Component) *App {
+	app := New(DefaultConfig(), WithLeaderElection(locker, LeaderConfig{
+		TTL: 300 * time.Millisecond, RenewInterval: 10 * time.Millisecond, RetryInterval: 10 * time.Millisecond,
+	}))
+	app.Register(c, LeaderOnly())
+	return app
+}
+
+func TestLeader_AcquireLoseRelease(t *testing.T) {
+	ctx := context.Background()
+	locker := &fakeLocker{}
+	c := &flakyComponent{countingComponent: countingComponent{name: "relay"}}
+	app := leaderApp(locker, c)
+	if err := app.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, "leadership", func() bool { return app.IsLeader() && c.running.Load() })
+
+	locker.setRefreshErr(lock.ErrLockExpired)
+	eventually(t, "lease loss", func() bool { return !app.IsLeader() && !c.running.Load() })
+
+	locker.setRefreshErr(nil)
+	eventually(t, "re-election", func() bool { return app.IsLeader() && c.running.Load() })
+
+	if err := app.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if held, _ := locker.state(); held || c.running.Load() || app.IsLeader() {
+		t.Fatalf("after stop: held=%v running=%v leader=%v", held, c.running.Load(), app.IsLeader())
+	}
+}
+
+func TestLeader_ActivateFailureResigns(t *testing.T) {
+	ctx := context.Background()
+	locker := &fakeLocker{}
+	c := &flakyComponent{countingComponent: countingComponent{name: "relay"}}
+	c.failures.Store(2)
+	app := leaderApp(locker, c)
+	if err := app.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer app.Stop(ctx)
+
+	eventually(t, "activation retry", func() bool { return app.IsLeader() && c.running.Load() })
+	if _, unlocks := locker.state(); unlocks != 2 {
+		t.Fatalf("lease released %d times after failed activations, want 2", unlocks)
+	}
+	if n := c.starts.Load(); n != 3 {
+		t.Fatalf("starts = %d, want 3", n)
+	}
+}
+
+func TestLeader_FailoverOnActivateFailure(t *testing.T) {
+	ctx := context.Background()
+	locker := &fakeLocker{}
+	broken := &flakyComponent{countingComponent: countingComponent{name: "relay"}}
+	broken.failures.Store(1 << 30)
+	healthy := &flakyComponent{countingComponent: countingComponent{name: "relay"}}
+
+	a := leaderApp(locker, broken)
+	if err := a.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer a.Stop(ctx)
+	eventually(t, "first election attempt", func() bool { return broken.starts.Load() > 0 })
+
+	b := leaderApp(locker, healthy)
+	if err := b.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Stop(ctx)
+
+	// 持有租约却无法启动成员的实例必须让出，健康实例接管
+	eventually(t, "failover", func() bool { return b.IsLeader() && healthy.running.Load() })
+	if a.IsLeader() {
+		t.Fatal("both instances report leadership")
+	}
+}
@@ -0,0 +1,92 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mildsunup/higo/logger"
+)
+
+// OnReload 注册重载钩子，在通知组件前执行（如重新读取配置文件）；
+// 钩子返回错误时中止本次重载，组件保持原有配置
+func (a *App) OnReload(h Hook) { a.hooks.reload = append(a.hooks.reload, h) }
+
+// Reload 重载配置：依次执行重载钩子，再按启动顺序调用已启动组件的 Reload（Reloader）。
+// 单个组件失败不影响其余组件，返回合并后的错误。与组件启停串行执行。
+func (a *App) Reload(ctx context.Context) error {
+	if a.State() != StateRunning {
+		return ErrNotRunning
+	}
+	a.ctl.Lock()
+	defer a.ctl.Unlock()
+
+	a.notifyServices(func(m serviceManager) { m.reloading() })
+	defer a.notifyServices(func(m serviceManager) { m.ready() })
+
+	begin := time.Now()
+	a.log.Info(ctx, "reloading")
+	for _, h := range a.hooks.reload {
+		if err := h(ctx); err != nil {
+			a.log.Error(ctx, "reload hook failed, reload aborted", logger.Err(err))
+			return fmt.Errorf("runtime: reload hook: %w", err)
+		}
+	}
+
+	a.mu.Lock()
+	var targets []Component
+	for _, e := range a.components {
+		if e.started {
+			targets = append(targets, e.component)
+		}
+	}
+	a.mu.Unlock()
+
+	var errs []error
+	for _, c := range targets {
+		r, ok := c.(Reloader)
+		if !ok {
+			continue
+		}
+		start := time.Now()
+		if err := r.Reload(ctx); err != nil {
+			a.log.Error(ctx, "component reload failed", logger.String("name", c.Name()), logger.Err(err))
+			errs = append(errs, fmt.Errorf("%s: %w", c.Name(), err))
+			continue
+		}
+		a.log.Info(ctx, "component reloaded", logger.String("name", c.Name()), logger.Duration("duration", time.Since(start)))
+	}
+	err := errors.Join(errs...)
+	if err == nil {
+		a.log.Info(ctx, "reload completed", logger.Duration("duration", time.Since(begin)))
+	}
+	return err
+}
+
+// reloadAsync 由信号或服务管理器触发的重载：后台执行，已有重载进行中时忽略
+func (a *App) reloadAsync(ctx context.Context, source string) {
+	if !a.reloading.CompareAndSwap(false, true) {
+		a.log.Warn(ctx, "reload already in progress, ignored", logger.String("source", source))
+		return
+	}
+	timeout := a.cfg.ReloadTimeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	go func() {
+		defer a.reloading.Store(false)
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+		defer cancel()
+		a.log.Info(ctx, "reload requested", logger.String("source", source))
+		_ = a.Reload(ctx) // 结果已记录日志
+	}()
+}
+
+// Reload 仅在组件运行时重载
+func (g *leaderGate) Reload(ctx context.Context) error {
+	if r, ok := g.Component.(Reloader); ok && g.isActive() {
+		return r.Reload(ctx)
+	}
+	return nil
+}
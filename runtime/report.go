@@ -0,0 +1,157 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ExitReason 退出原因
+type ExitReason string
+
+const (
+	ExitClean         ExitReason = "clean"          // 正常停止
+	ExitStartupFailed ExitReason = "startup_failed" // 启动失败
+	ExitStopFailed    ExitReason = "stop_failed"    // 停止过程中组件报错
+	ExitStopTimeout   ExitReason = "stop_timeout"   // 停止超过 ShutdownTimeout
+	ExitForced        ExitReason = "forced"         // 停止期间再次收到信号，强制退出
+)
+
+// 进程退出码
+const (
+	ExitCodeOK             = 0
+	ExitCodeUnknown        = 1
+	ExitCodeStartupFailure = 2
+	ExitCodeStopFailure    = 3
+	ExitCodeStopTimeout    = 4
+	ExitCodeForced         = 130
+)
+
+// ComponentReport 组件启动/排空/停止记录
+type ComponentReport struct {
+	Name      string
+	Phase     string // start / drain / flush / stop
+	Duration  time.Duration
+	Err       error
+	TimedOut  bool
+	Abandoned int // 排空超时后放弃的在途工作数
+	Lost      int // 刷出超时后丢失的排队数据数
+}
+
+// ShutdownReport 运行结束报告
+// 非正常退出时作为 Run 的错误返回，可通过 ExitCode 映射为进程退出码。
+type ShutdownReport struct {
+	Reason        ExitReason
+	Signal        string
+	StartDuration time.Duration
+	StopDuration  time.Duration
+	Uptime        time.Duration
+	Components    []ComponentReport
+	Err           error // 启动失败的原因
+}
+
+// Error 实现 error 接口
+func (r *ShutdownReport) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "app exited: %s", r.Reason)
+	if r.Err != nil {
+		fmt.Fprintf(&b, ": %v", r.Err)
+	}
+	for _, c := range r.Failed() {
+		fmt.Fprintf(&b, "; %s %s", c.Phase, c.Name)
+		if c.Abandoned > 0 {
+			fmt.Fprintf(&b, " abandoned %d in-flight", c.Abandoned)
+		} else if c.Lost > 0 {
+			fmt.Fprintf(&b, " lost %d queued", c.Lost)
+		} else if c.TimedOut {
+			b.WriteString(" timed out")
+		} else if c.Err != nil {
+			fmt.Fprintf(&b, ": %v", c.Err)
+		}
+	}
+	return b.String()
+}
+
+// Unwrap 返回启动失败原因
+func (r *ShutdownReport) Unwrap() error { return r.Err }
+
+// Failed 返回失败、超时、放弃了在途工作或丢失了排队数据的组件记录
+func (r *ShutdownReport) Failed() []ComponentReport {
+	var failed []ComponentReport
+	for _, c := range r.Components {
+		if c.Err != nil || c.TimedOut || c.Abandoned > 0 || c.Lost > 0 {
+			failed = append(failed, c)
+		}
+	}
+	return failed
+}
+
+// ExitCode 返回退出码
+func (r *ShutdownReport) ExitCode() int {
+	switch r.Reason {
+	case ExitClean:
+		return ExitCodeOK
+	case ExitStartupFailed:
+		return ExitCodeStartupFailure
+	case ExitStopFailed:
+		return ExitCodeStopFailure
+	case ExitStopTimeout:
+		return ExitCodeStopTimeout
+	case ExitForced:
+		return ExitCodeForced
+	default:
+		return ExitCodeUnknown
+	}
+}
+
+// ExitCode 将 Run 的返回值映射为进程退出码
+//
+//	os.Exit(runtime.ExitCode(app.Run(ctx)))
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitCodeOK
+	}
+	var r *ShutdownReport
+	if errors.As(err, &r) {
+		return r.ExitCode()
+	}
+	return ExitCodeUnknown
+}
+
+// record 记录组件阶段耗时与结果
+func (a *App) record(ctx context.Context, name, phase string, start time.Time, err error) {
+	timedOut := errors.Is(err, context.DeadlineExceeded) || (err == nil && errors.Is(ctx.Err(), context.DeadlineExceeded))
+	a.mu.Lock()
+	a.records = append(a.records, ComponentReport{
+		Name:     name,
+		Phase:    phase,
+		Duration: time.Since(start),
+		Err:      err,
+		TimedOut: timedOut,
+	})
+	a.mu.Unlock()
+}
+
+// recordDrain 记录排空结果
+func (a *App) recordDrain(ctx context.Context, name string, start time.Time, abandoned int, err error) {
+	a.mu.Lock()
+	a.records = append(a.records, ComponentReport{
+		Name:      name,
+		Phase:     "drain",
+		Duration:  time.Since(start),
+		Err:       err,
+		TimedOut:  errors.Is(err, context.DeadlineExceeded),
+		Abandoned: abandoned,
+	})
+	a.mu.Unlock()
+}
+
+func (a *App) takeRecords() []ComponentReport {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	records := a.records
+	a.records = nil
+	return records
+}
@@ -0,0 +1,158 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// faultyComponent 启动或停止失败的组件；hang 为真时 Stop 阻塞到 ctx 结束
+type faultyComponent struct {
+	countingComponent
+	startErr, stopErr error
+	hang              bool
+}
+
+func (c *faultyComponent) Start(ctx context.Context) error {
+	c.starts.Add(1)
+	return c.startErr
+}
+
+func (c *faultyComponent) Stop(ctx context.Context) error {
+	c.stops.Add(1)
+	if c.hang {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return c.stopErr
+}
+
+// runUntilCancelled 运行应用，启动完成后取消 ctx 触发停止
+func runUntilCancelled(app *App) *ShutdownReport {
+	ctx, cancel := context.WithCancel(context.Background())
+	app.OnAfterStart(func(context.Context) error {
+		cancel()
+		return nil
+	})
+	defer cancel()
+	return app.RunWithReport(ctx)
+}
+
+func TestRunWithReport_Clean(t *testing.T) {
+	c := &countingComponent{name: "db"}
+	app := New(DefaultConfig())
+	app.Register(c)
+
+	r := runUntilCancelled(app)
+	if r.Reason != ExitClean || r.ExitCode() != ExitCodeOK || len(r.Failed()) != 0 {
+		t.Fatalf("report = %+v", r)
+	}
+	var phases []string
+	for _, cr := range r.Components {
+		phases = append(phases, cr.Name+":"+cr.Phase)
+	}
+	if strings.Join(phases, ",") != "db:start,db:stop" {
+		t.Fatalf("components = %v", phases)
+	}
+	if c.stops.Load() != 1 {
+		t.Fatalf("stops = %d", c.stops.Load())
+	}
+
+	app2 := New(DefaultConfig())
+	app2.Register(&countingComponent{name: "db"})
+	ctx, cancel := context.WithCancel(context.Background())
+	app2.OnAfterStart(func(context.Context) error { cancel(); return nil })
+	if err := app2.Run(ctx); err != nil || ExitCode(err) != ExitCodeOK {
+		t.Fatalf("run = %v", err)
+	}
+}
+
+func TestRunWithReport_StartupFailed(t *testing.T) {
+	boom := errors.New("dial failed")
+	db := &countingComponent{name: "db"}
+	app := New(DefaultConfig())
+	app.Register(db, Priority(1))
+	app.Register(&faultyComponent{countingComponent: countingComponent{name: "mq"}, startErr: boom}, Priority(2))
+
+	err := app.Run(context.Background())
+	var r *ShutdownReport
+	if !errors.As(err, &r) {
+		t.Fatalf("run = %v", err)
+	}
+	if r.Reason != ExitStartupFailed || ExitCode(err) != ExitCodeStartupFailure || !errors.Is(err, boom) {
+		t.Fatalf("report = %+v", r)
+	}
+	if !strings.Contains(err.Error(), "startup_failed") || !strings.Contains(err.Error(), "dial failed") {
+		t.Fatalf("error = %q", err)
+	}
+	// 已启动的组件被回滚
+	if db.stops.Load() != 1 {
+		t.Fatalf("rollback stops = %d", db.stops.Load())
+	}
+}
+
+func TestRunWithReport_StopFailed(t *testing.T) {
+	app := New(DefaultConfig())
+	app.Register(&faultyComponent{countingComponent: countingComponent{name: "cache"}, stopErr: errors.New("flush failed")})
+
+	r := runUntilCancelled(app)
+	if r.Reason != ExitStopFailed || r.ExitCode() != ExitCodeStopFailure {
+		t.Fatalf("report = %+v", r)
+	}
+	failed := r.Failed()
+	if len(failed) != 1 || failed[0].Name != "cache" || failed[0].Phase != "stop" {
+		t.Fatalf("failed = %+v", failed)
+	}
+	if msg := r.Error(); !strings.Contains(msg, "stop cache: flush failed") {
+		t.Fatalf("error = %q", msg)
+	}
+}
+
+func TestRunWithReport_StopTimeout(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ShutdownTimeout = 30 * time.Millisecond
+	app := New(cfg)
+	app.Register(&faultyComponent{countingComponent: countingComponent{name: "worker"}, hang: true})
+
+	r := runUntilCancelled(app)
+	if r.Reason != ExitStopTimeout || r.ExitCode() != ExitCodeStopTimeout {
+		t.Fatalf("report = %+v", r)
+	}
+	failed := r.Failed()
+	if len(failed) != 1 || !failed[0].TimedOut {
+		t.Fatalf("failed = %+v", failed)
+	}
+	if msg := r.Error(); !strings.Contains(msg, "stop worker timed out") {
+		t.Fatalf("error = %q", msg)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, ExitCodeOK},
+		{errors.New("other"), ExitCodeUnknown},
+		{&ShutdownReport{Reason: ExitForced}, ExitCodeForced},
+		{fmt.Errorf("run: %w", &ShutdownReport{Reason: ExitStopTimeout}), ExitCodeStopTimeout},
+		{&ShutdownReport{Reason: "unknown"}, ExitCodeUnknown},
+	}
+	for _, tt := range tests {
+		if got := ExitCode(tt.err); got != tt.want {
+			t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+
+	r := &ShutdownReport{Reason: ExitStopFailed, Components: []ComponentReport{
+		{Name: "http", Phase: "drain", Abandoned: 3},
+		{Name: "kafka", Phase: "flush", Lost: 2},
+		{Name: "db", Phase: "stop"},
+	}}
+	if msg := r.Error(); msg != "app exited: stop_failed; drain http abandoned 3 in-flight; flush kafka lost 2 queued" {
+		t.Fatalf("error = %q", msg)
+	}
+}
@@ -0,0 +1,260 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSchedule cron 表达式无效
+var ErrInvalidSchedule = errors.New("runtime: invalid cron schedule")
+
+// Schedule 调度计划
+type Schedule interface {
+	// Next 返回 t 之后的下一次触发时间，不存在时返回零值
+	Next(t time.Time) time.Time
+}
+
+// cronSchedule 标准 5 字段 cron（分 时 日 月 周），各字段以位集表示
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+	loc                           *time.Location
+}
+
+// everySchedule 固定间隔（@every 1h30m）
+type everySchedule struct {
+	d time.Duration
+}
+
+func (s everySchedule) Next(t time.Time) time.Time {
+	return t.Add(s.d).Truncate(time.Second)
+}
+
+type cronField struct {
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = cronField{min: 0, max: 59}
+	hourField   = cronField{min: 0, max: 23}
+	domField    = cronField{min: 1, max: 31}
+	monthField  = cronField{min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowField = cronField{min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCron 解析 cron 表达式（本地时区）
+//
+// 支持标准 5 字段（分 时 日 月 周）：*、列表（1,15）、范围（1-5）、步长（*/10、0-30/5）、
+// 月份与星期名称（JAN、MON），以及 @yearly、@monthly、@weekly、@daily、@hourly、@every <duration>。
+// 前缀 "CRON_TZ=Asia/Shanghai " 指定时区。日与周同时受限时满足其一即触发，以 * 开头（含 */n）的字段视为不限
+// （与 Vixie cron 一致）。夏令时跳过的时刻在跳变时触发，回拨重复的时刻只触发一次。
+func ParseCron(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	loc := time.Local
+	if rest, ok := strings.CutPrefix(spec, "CRON_TZ="); ok {
+		tz, expr, _ := strings.Cut(rest, " ")
+		l, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %v", ErrInvalidSchedule, spec, err)
+		}
+		loc, spec = l, strings.TrimSpace(expr)
+	}
+
+	if d, ok := strings.CutPrefix(spec, "@every "); ok {
+		dur, err := time.ParseDuration(strings.TrimSpace(d))
+		if err != nil || dur < time.Second {
+			return nil, fmt.Errorf("%w: %q: interval must be a duration of at least 1s", ErrInvalidSchedule, spec)
+		}
+		return everySchedule{d: dur}, nil
+	}
+	if expr, ok := cronDescriptors[strings.ToLower(spec)]; ok {
+		spec = expr
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: %q: expected 5 fields, got %d", ErrInvalidSchedule, spec, len(fields))
+	}
+
+	s := &cronSchedule{loc: loc}
+	var err error
+	for i, p := range []struct {
+		dst *uint64
+		f   cronField
+	}{{&s.minute, minuteField}, {&s.hour, hourField}, {&s.dom, domField}, {&s.month, monthField}, {&s.dow, dowField}} {
+		if *p.dst, err = parseCronField(fields[i], p.f); err != nil {
+			return nil, fmt.Errorf("%w: %q: %v", ErrInvalidSchedule, spec, err)
+		}
+	}
+	// 周日可写作 0 或 7
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+	// 与 Vixie cron 一致，以 * 开头（含 */n）的字段视为不限，此时日与周须同时满足
+	s.domStar = strings.HasPrefix(fields[2], "*") || fields[2] == "?"
+	s.dowStar = strings.HasPrefix(fields[4], "*") || fields[4] == "?"
+	return s, nil
+}
+
+func parseCronField(expr string, f cronField) (uint64, error) {
+	var set uint64
+	for part := range strings.SplitSeq(expr, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", part)
+			}
+			step = n
+		}
+
+		lo, hi := f.min, f.max
+		switch {
+		case rng == "*" || rng == "?":
+		default:
+			a, b, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = f.value(a); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if isRange {
+				if hi, err = f.value(b); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				hi = f.max
+			}
+			if hi < lo {
+				return 0, fmt.Errorf("invalid range %q", part)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+func (f cronField) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("value %q out of range [%d, %d]", s, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next 在墙上时间上查找下一次匹配，再换算为 s.loc 中的时刻：
+// 落在夏令时向前跳过的区间内时在跳变时刻触发（不丢失本次运行），
+// 时钟回拨导致重复的墙上时间只触发一次。
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	orig := t.Location()
+	t = t.In(s.loc)
+	// UTC 没有跳变，用作墙上时间的载体
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC)
+	for {
+		if wall = s.nextWall(wall.Add(time.Minute)); wall.IsZero() {
+			return time.Time{}
+		}
+		next := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), 0, 0, s.loc)
+		if got := time.Date(next.Year(), next.Month(), next.Day(), next.Hour(), next.Minute(), 0, 0, time.UTC); !got.Equal(wall) {
+			// 墙上时间不存在，time.Date 可能按任一侧的偏移换算，取两者之间的跳变时刻
+			start, end := next.ZoneBounds()
+			if got.Before(wall) {
+				next = end
+			} else {
+				next = start
+			}
+		}
+		if next.After(t) {
+			return next.In(orig)
+		}
+		// 回拨后的第二次出现：time.Date 返回第一次出现的时刻
+		_, end := next.ZoneBounds()
+		_, before := next.Zone()
+		_, after := end.Zone()
+		if later := next.Add(time.Duration(before-after) * time.Second); later.After(t) {
+			return later.In(orig)
+		}
+	}
+}
+
+// nextWall 逐字段推进：月 -> 日 -> 时 -> 分，不匹配时进位并重置更低字段；t 为 UTC 表示的墙上时间
+func (s *cronSchedule) nextWall(t time.Time) time.Time {
+	limit := t.Year() + 5
+	for t.Year() <= limit {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			next := nextBit(s.hour, t.Hour()+1)
+			if next < 0 {
+				t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			} else {
+				t = time.Date(t.Year(), t.Month(), t.Day(), next, 0, 0, 0, time.UTC)
+			}
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			next := nextBit(s.minute, t.Minute()+1)
+			if next < 0 {
+				t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, time.UTC)
+			} else {
+				t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), next, 0, 0, time.UTC)
+			}
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	domOK := s.dom&(1<<uint(t.Day())) != 0
+	dowOK := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domOK && dowOK
+	}
+	return domOK || dowOK
+}
+
+// nextBit 返回 set 中不小于 from 的最小位，不存在返回 -1
+func nextBit(set uint64, from int) int {
+	if from >= 64 {
+		return -1
+	}
+	rest := set >> uint(from)
+	if rest == 0 {
+		return -1
+	}
+	return from + bits.TrailingZeros64(rest)
+}
@@ -0,0 +1,117 @@
+package runtime
+
+import (
+	"errors"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func mustParseCron(t *testing.T, spec string) Schedule {
+	t.Helper()
+	s, err := ParseCron(spec)
+	if err != nil {
+		t.Fatalf("ParseCron(%q): %v", spec, err)
+	}
+	return s
+}
+
+func TestParseCron_Next(t *testing.T) {
+	// 2026-03-04 是星期三
+	from := time.Date(2026, 3, 4, 10, 17, 30, 0, time.UTC)
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"CRON_TZ=UTC * * * * *", time.Date(2026, 3, 4, 10, 18, 0, 0, time.UTC)},
+		{"CRON_TZ=UTC */10 * * * *", time.Date(2026, 3, 4, 10, 20, 0, 0, time.UTC)},
+		{"CRON_TZ=UTC 0-30/15 * * * *", time.Date(2026, 3, 4, 10, 30, 0, 0, time.UTC)},
+		{"CRON_TZ=UTC 5,15 9-10 * * *", time.Date(2026, 3, 5, 9, 5, 0, 0, time.UTC)},
+		{"CRON_TZ=UTC 0 3 * * *", time.Date(2026, 3, 5, 3, 0, 0, 0, time.UTC)},
+		{"CRON_TZ=UTC 0 0 1 * *", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"CRON_TZ=UTC 0 0 * * MON", time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)},
+		{"CRON_TZ=UTC 0 0 * * 7", time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)},
+		{"CRON_TZ=UTC 0 0 1 JAN *", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"CRON_TZ=UTC 0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"CRON_TZ=UTC @hourly", time.Date(2026, 3, 4, 11, 0, 0, 0, time.UTC)},
+		{"CRON_TZ=UTC @weekly", time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)},
+		// 日与周都受限时满足其一即可
+		{"CRON_TZ=UTC 0 0 13 * FRI", time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)},
+		// 以 * 开头的日字段（*/n）视为不限，需同时满足周
+		{"CRON_TZ=UTC 0 0 */2 * FRI", time.Date(2026, 3, 13, 0, 0, 0, 0, time.UTC)},
+		{"CRON_TZ=UTC 0 0 1-31/2 * FRI", time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)},
+		{"CRON_TZ=Asia/Shanghai 0 9 * * *", time.Date(2026, 3, 5, 1, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got := mustParseCron(t, tt.spec).Next(from)
+		if !got.Equal(tt.want) {
+			t.Errorf("%s: Next = %v, want %v", tt.spec, got, tt.want)
+		}
+		if got.Location() != from.Location() {
+			t.Errorf("%s: location = %v", tt.spec, got.Location())
+		}
+	}
+
+	if got := mustParseCron(t, "@every 90m").Next(from); !got.Equal(from.Add(90 * time.Minute).Truncate(time.Second)) {
+		t.Errorf("@every: Next = %v", got)
+	}
+	if got := mustParseCron(t, "CRON_TZ=UTC 0 0 30 2 *").Next(from); !got.IsZero() {
+		t.Errorf("impossible date: Next = %v", got)
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, spec := range []string{
+		"", "* * * *", "* * * * * *", "60 * * * *", "* 24 * * *", "* * 0 * *",
+		"* * * 13 *", "* * * * 8", "*/0 * * * *", "5-1 * * * *", "* * * FOO *",
+		"@every 10ms", "@every soon", "CRON_TZ=Nowhere/City * * * * *",
+	} {
+		if _, err := ParseCron(spec); !errors.Is(err, ErrInvalidSchedule) {
+			t.Errorf("ParseCron(%q) = %v", spec, err)
+		}
+	}
+}
+
+func TestParseCron_DST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 2026-03-08 02:00 跳到 03:00，02:30 不存在：在跳变时刻触发而非跳过当天
+	daily := mustParseCron(t, "CRON_TZ=America/New_York 30 2 * * *")
+	got := daily.Next(time.Date(2026, 3, 8, 1, 0, 0, 0, ny))
+	if want := time.Date(2026, 3, 8, 3, 0, 0, 0, ny); !got.Equal(want) {
+		t.Fatalf("spring forward: Next = %v, want %v", got, want)
+	}
+	if got = daily.Next(got); !got.Equal(time.Date(2026, 3, 9, 2, 30, 0, 0, ny)) {
+		t.Fatalf("after gap: Next = %v", got)
+	}
+
+	// 跳过区间内的多个触发点只触发一次
+	quarter := mustParseCron(t, "CRON_TZ=America/New_York */15 2 * * *")
+	got = quarter.Next(time.Date(2026, 3, 8, 1, 59, 0, 0, ny))
+	if want := time.Date(2026, 3, 8, 3, 0, 0, 0, ny); !got.Equal(want) {
+		t.Fatalf("gap minutes: Next = %v, want %v", got, want)
+	}
+	if got = quarter.Next(got); !got.Equal(time.Date(2026, 3, 9, 2, 0, 0, 0, ny)) {
+		t.Fatalf("after gap minutes: Next = %v", got)
+	}
+
+	// 2026-11-01 02:00 回拨到 01:00，01:30 只触发一次
+	fallback := mustParseCron(t, "CRON_TZ=America/New_York 30 1 * * *")
+	first := fallback.Next(time.Date(2026, 11, 1, 0, 0, 0, 0, ny))
+	if _, off := first.Zone(); first.Hour() != 1 || first.Minute() != 30 || off != -4*3600 {
+		t.Fatalf("fall back first = %v", first)
+	}
+	if got = fallback.Next(first); !got.Equal(time.Date(2026, 11, 2, 1, 30, 0, 0, ny)) {
+		t.Fatalf("fall back repeated: Next = %v", got)
+	}
+
+	// 从回拨后的重复小时内开始时，按第二次出现计算
+	second := first.Add(time.Hour).Add(-10 * time.Minute) // 第二次出现的 01:20
+	got = mustParseCron(t, "CRON_TZ=America/New_York 40 1 * * *").Next(second)
+	if want := second.Add(20 * time.Minute); !got.Equal(want) {
+		t.Fatalf("within repeated hour: Next = %v, want %v", got, want)
+	}
+}
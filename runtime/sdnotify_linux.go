@@ -0,0 +1,12 @@
+package runtime
+
+import "golang.org/x/sys/unix"
+
+// monotonicUsec 返回 CLOCK_MONOTONIC 微秒数，供 Type=notify-reload 的 RELOADING=1 使用
+func monotonicUsec() int64 {
+	var ts unix.Timespec
+	if err := unix.ClockGettime(unix.CLOCK_MONOTONIC, &ts); err != nil {
+		return 0
+	}
+	return ts.Nano() / 1000
+}
@@ -0,0 +1,6 @@
+//go:build !linux
+
+package runtime
+
+// monotonicUsec 非 Linux 平台不使用 systemd，返回 0
+func monotonicUsec() int64 { return 0 }
@@ -0,0 +1,160 @@
+package runtime
+
+import (
+	"context"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mildsunup/higo/logger"
+)
+
+// serviceControl 服务管理器发出的控制请求
+type serviceControl int
+
+const (
+	controlStop serviceControl = iota
+	controlReload
+)
+
+// serviceManager 操作系统服务管理器集成（systemd、Windows 服务控制管理器），由 Run 驱动
+type serviceManager interface {
+	begin()           // Run 开始，启动组件之前
+	ready()           // 启动完成或重载结束
+	reloading()       // 开始重载
+	stopping()        // 开始停止
+	stopped(code int) // Run 结束，code 为退出码
+}
+
+func (a *App) notifyServices(fn func(serviceManager)) {
+	for _, m := range a.services {
+		fn(m)
+	}
+}
+
+// control 向 Run 投递控制请求，已有未处理的请求时丢弃
+func (a *App) control(c serviceControl) {
+	select {
+	case a.controls <- c:
+	default:
+	}
+}
+
+// WithSystemdNotify 启用 systemd 通知（Type=notify 或 Type=notify-reload），未设置 NOTIFY_SOCKET 时无效：
+//
+//	[Service]
+//	Type=notify-reload
+//	WatchdogSec=30s
+//
+// 启动完成发送 READY=1，重载期间 RELOADING=1，停止时 STOPPING=1。
+// 配置 WatchdogSec 时每半个周期执行一次聚合健康检查（CheckHealth），通过后才发送 WATCHDOG=1：
+// 检查持续失败或挂起超过 WatchdogSec 时由 systemd 重启；非运行状态（重载、停止中）不做检查，照常发送。
+func WithSystemdNotify() Option {
+	return func(a *App) {
+		socket := os.Getenv("NOTIFY_SOCKET")
+		if socket == "" {
+			return
+		}
+		a.services = append(a.services, &systemdNotifier{app: a, socket: socket, watchdog: watchdogInterval()})
+	}
+}
+
+// watchdogInterval 解析 WATCHDOG_USEC，未启用或 WATCHDOG_PID 不是当前进程时返回 0
+func watchdogInterval() time.Duration {
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond
+}
+
+// systemdNotifier 通过 sd_notify 协议向 systemd 报告状态
+type systemdNotifier struct {
+	app      *App
+	socket   string
+	watchdog time.Duration
+
+	mu     sync.Mutex
+	cancel context.CancelFunc // 停止看门狗
+}
+
+func (n *systemdNotifier) begin() {}
+
+func (n *systemdNotifier) ready() {
+	n.send("READY=1", "STATUS=running")
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.watchdog <= 0 || n.cancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	n.cancel = cancel
+	go n.keepalive(ctx)
+}
+
+func (n *systemdNotifier) reloading() {
+	fields := []string{"RELOADING=1", "STATUS=reloading"}
+	if usec := monotonicUsec(); usec > 0 {
+		fields = append(fields, "MONOTONIC_USEC="+strconv.FormatInt(usec, 10))
+	}
+	n.send(fields...)
+}
+
+func (n *systemdNotifier) stopping() { n.send("STOPPING=1", "STATUS=stopping") }
+
+func (n *systemdNotifier) stopped(int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.cancel != nil {
+		n.cancel()
+		n.cancel = nil
+	}
+}
+
+func (n *systemdNotifier) keepalive(ctx context.Context) {
+	ticker := time.NewTicker(n.watchdog / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n.alive(ctx) {
+				n.send("WATCHDOG=1")
+			}
+		}
+	}
+}
+
+// alive 看门狗存活判定：运行中时要求聚合健康检查在半个周期内通过
+func (n *systemdNotifier) alive(ctx context.Context) bool {
+	if n.app.State() != StateRunning {
+		return true
+	}
+	ctx, cancel := context.WithTimeout(ctx, n.watchdog/2)
+	defer cancel()
+	report := n.app.CheckHealth(ctx)
+	if !report.Healthy {
+		n.app.log.Warn(ctx, "health check failing, watchdog keepalive withheld", logger.Err(report.Err()))
+	}
+	return report.Healthy
+}
+
+func (n *systemdNotifier) send(fields ...string) {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: n.socket, Net: "unixgram"})
+	if err == nil {
+		_, err = conn.Write([]byte(strings.Join(fields, "\n")))
+		_ = conn.Close()
+	}
+	if err != nil {
+		n.app.log.Warn(context.Background(), "systemd notify failed",
+			logger.String("state", fields[0]), logger.Err(err))
+	}
+}
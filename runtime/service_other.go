@@ -0,0 +1,8 @@
+//go:build !windows
+
+package runtime
+
+// WithWindowsService 作为 Windows 服务运行时接入服务控制管理器，非 Windows 平台无效
+func WithWindowsService() Option {
+	return func(*App) {}
+}
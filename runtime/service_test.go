@@ -0,0 +1,232 @@
+//go:build linux
+
+package runtime
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// reloadComponent 记录 Reload 调用的组件
+type reloadComponent struct {
+	countingComponent
+	reloads atomic.Int32
+	err     error
+}
+
+func (c *reloadComponent) Reload(context.Context) error {
+	c.reloads.Add(1)
+	return c.err
+}
+
+func TestReload(t *testing.T) {
+	ctx := context.Background()
+	ok := &reloadComponent{countingComponent: countingComponent{name: "ok"}}
+	bad := &reloadComponent{countingComponent: countingComponent{name: "bad"}, err: errors.New("boom")}
+	app := New(DefaultConfig())
+	app.Register(ok)
+	app.Register(bad)
+
+	if err := app.Reload(ctx); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("reload before start = %v", err)
+	}
+	if err := app.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer app.Stop(ctx)
+
+	var hooks atomic.Int32
+	app.OnReload(func(context.Context) error {
+		hooks.Add(1)
+		return nil
+	})
+	err := app.Reload(ctx)
+	if err == nil || !strings.Contains(err.Error(), "bad: boom") {
+		t.Fatalf("reload = %v", err)
+	}
+	if hooks.Load() != 1 || ok.reloads.Load() != 1 || bad.reloads.Load() != 1 {
+		t.Fatalf("hooks=%d ok=%d bad=%d", hooks.Load(), ok.reloads.Load(), bad.reloads.Load())
+	}
+
+	// 钩子失败时中止，组件不被通知
+	app.OnReload(func(context.Context) error { return errors.New("bad config") })
+	if err := app.Reload(ctx); err == nil {
+		t.Fatal("expected hook error")
+	}
+	if ok.reloads.Load() != 1 {
+		t.Fatal("components reloaded after hook failure")
+	}
+
+	// 未运行的组件不重载
+	if err := app.StopComponent(ctx, "ok"); err != nil {
+		t.Fatal(err)
+	}
+	app.hooks.reload = nil
+	_ = app.Reload(ctx)
+	if ok.reloads.Load() != 1 {
+		t.Fatal("stopped component was reloaded")
+	}
+}
+
+func TestWaitStop(t *testing.T) {
+	ctx := context.Background()
+	reloaded := make(chan struct{}, 4)
+	app := New(DefaultConfig())
+	app.OnReload(func(context.Context) error {
+		reloaded <- struct{}{}
+		return nil
+	})
+	if err := app.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer app.Stop(ctx)
+
+	awaitReload := func(what string) {
+		t.Helper()
+		select {
+		case <-reloaded:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("no reload after %s", what)
+		}
+		for app.reloading.Load() {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	reason := make(chan string, 1)
+	go func() { reason <- app.waitStop(ctx) }()
+
+	// 控制请求在信号注册之后处理，收到重载即表示信号已注册
+	app.control(controlReload)
+	awaitReload("reload control")
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	awaitReload("SIGHUP")
+
+	app.control(controlStop)
+	if r := <-reason; r != "service stop" {
+		t.Fatalf("reason = %q", r)
+	}
+
+	cctx, cancel := context.WithCancel(ctx)
+	go func() { reason <- app.waitStop(cctx) }()
+	cancel()
+	if r := <-reason; r != "" {
+		t.Fatalf("reason after ctx cancel = %q", r)
+	}
+}
+
+// notifySocket 在临时目录监听 sd_notify 报文
+func notifySocket(t *testing.T) <-chan string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	t.Setenv("NOTIFY_SOCKET", path)
+
+	msgs := make(chan string, 256)
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				return
+			}
+			msgs <- string(buf[:n])
+		}
+	}()
+	return msgs
+}
+
+func expectMsg(t *testing.T, msgs <-chan string, prefix string) string {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case m := <-msgs:
+			if strings.HasPrefix(m, prefix) {
+				return m
+			}
+		case <-timeout:
+			t.Fatalf("no %q notification", prefix)
+		}
+	}
+}
+
+func TestSystemdNotify(t *testing.T) {
+	msgs := notifySocket(t)
+	t.Setenv("WATCHDOG_USEC", strconv.Itoa(int((40 * time.Millisecond).Microseconds())))
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(syscall.Getpid()))
+
+	var healthy atomic.Bool
+	healthy.Store(true)
+	app := New(DefaultConfig(), WithSystemdNotify(), WithHealthConfig(HealthConfig{Timeout: 10 * time.Millisecond}))
+	app.AddHealthCheck("dep", func(context.Context) error {
+		if healthy.Load() {
+			return nil
+		}
+		return errors.New("down")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = app.Run(ctx)
+	}()
+
+	if m := expectMsg(t, msgs, "READY=1"); !strings.Contains(m, "STATUS=running") {
+		t.Fatalf("ready = %q", m)
+	}
+	expectMsg(t, msgs, "WATCHDOG=1")
+
+	// 健康检查失败时不再发送 WATCHDOG=1
+	healthy.Store(false)
+	time.Sleep(40 * time.Millisecond)
+	for len(msgs) > 0 {
+		<-msgs
+	}
+	select {
+	case m := <-msgs:
+		t.Fatalf("unexpected notification while unhealthy: %q", m)
+	case <-time.After(100 * time.Millisecond):
+	}
+	healthy.Store(true)
+	expectMsg(t, msgs, "WATCHDOG=1")
+
+	if err := app.Reload(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if m := expectMsg(t, msgs, "RELOADING=1"); !strings.Contains(m, "MONOTONIC_USEC=") {
+		t.Fatalf("reloading = %q", m)
+	}
+	expectMsg(t, msgs, "READY=1")
+
+	cancel()
+	expectMsg(t, msgs, "STOPPING=1")
+	<-done
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	t.Setenv("WATCHDOG_USEC", "30000000")
+	t.Setenv("WATCHDOG_PID", "")
+	if d := watchdogInterval(); d != 30*time.Second {
+		t.Fatalf("interval = %v", d)
+	}
+	t.Setenv("WATCHDOG_PID", "1")
+	if d := watchdogInterval(); d != 0 {
+		t.Fatalf("interval for another pid = %v", d)
+	}
+}
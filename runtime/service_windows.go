@@ -0,0 +1,94 @@
+//go:build windows
+
+package runtime
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+
+	"github.com/mildsunup/higo/logger"
+)
+
+// WithWindowsService 作为 Windows 服务运行时（由服务控制管理器启动）接入 SCM，以控制台方式运行时无效：
+// 上报启动中/运行中/停止中状态，Stop 与 Shutdown 控制触发优雅关闭，ParamChange 控制触发重载。
+// 服务名取 Config.Name，须与安装时的服务名一致。
+func WithWindowsService() Option {
+	return func(a *App) {
+		if ok, err := svc.IsWindowsService(); err != nil || !ok {
+			return
+		}
+		a.services = append(a.services, &windowsService{
+			app:     a,
+			updates: make(chan svc.State),
+			exit:    make(chan uint32, 1),
+			done:    make(chan struct{}),
+		})
+	}
+}
+
+const windowsAccepts = svc.AcceptStop | svc.AcceptShutdown | svc.AcceptParamChange
+
+// windowsService 在 svc.Run 中转发状态与控制请求
+type windowsService struct {
+	app     *App
+	updates chan svc.State
+	exit    chan uint32   // Run 结束时的退出码
+	done    chan struct{} // svc.Run 返回
+}
+
+func (s *windowsService) begin() {
+	go func() {
+		defer close(s.done)
+		if err := svc.Run(s.app.cfg.Name, s); err != nil {
+			s.app.log.Error(context.Background(), "windows service dispatcher failed", logger.Err(err))
+		}
+	}()
+}
+
+func (s *windowsService) ready()     { s.update(svc.Running) }
+func (s *windowsService) reloading() {}
+func (s *windowsService) stopping()  { s.update(svc.StopPending) }
+
+// stopped 上报最终状态，等待 SCM 确认后再让进程退出
+func (s *windowsService) stopped(code int) {
+	s.exit <- uint32(code)
+	select {
+	case <-s.done:
+	case <-time.After(5 * time.Second):
+	}
+}
+
+func (s *windowsService) update(state svc.State) {
+	select {
+	case s.updates <- state:
+	case <-s.done:
+	}
+}
+
+// Execute 实现 svc.Handler
+func (s *windowsService) Execute(_ []string, requests <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	changes <- svc.Status{State: svc.StartPending}
+	for {
+		select {
+		case state := <-s.updates:
+			status := svc.Status{State: state}
+			if state == svc.Running {
+				status.Accepts = windowsAccepts
+			}
+			changes <- status
+		case code := <-s.exit:
+			return code != 0, code
+		case req := <-requests:
+			switch req.Cmd {
+			case svc.Interrogate:
+				changes <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				s.app.control(controlStop)
+			case svc.ParamChange:
+				s.app.control(controlReload)
+			}
+		}
+	}
+}
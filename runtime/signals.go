@@ -19,3 +19,30 @@ func WaitSignal(ctx context.Context) os.Signal {
 		return nil
 	}
 }
+
+// waitStop 等待停止请求（SIGINT/SIGTERM 或服务管理器的停止控制），期间 SIGHUP 与重载控制触发 Reload。
+// 返回停止原因，ctx 结束时返回空。
+func (a *App) waitStop(ctx context.Context) string {
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(quit)
+
+	for {
+		select {
+		case sig := <-quit:
+			if sig == syscall.SIGHUP {
+				a.reloadAsync(ctx, sig.String())
+				continue
+			}
+			return sig.String()
+		case c := <-a.controls:
+			if c == controlReload {
+				a.reloadAsync(ctx, "service manager")
+				continue
+			}
+			return "service stop"
+		case <-ctx.Done():
+			return ""
+		}
+	}
+}
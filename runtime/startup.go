@@ -0,0 +1,220 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mildsunup/higo/logger"
+	"github.com/mildsunup/higo/observability"
+)
+
+// StartupPhase 组件启动进度阶段
+type StartupPhase string
+
+const (
+	StartupStarting StartupPhase = "starting" // 开始启动
+	StartupWaiting  StartupPhase = "waiting"  // 仍在启动（按 WithStartupProgress 间隔周期发出）
+	StartupStarted  StartupPhase = "started"  // 启动成功
+	StartupFailed   StartupPhase = "failed"   // 启动失败或超时
+)
+
+// StartupEvent 组件启动进度事件
+type StartupEvent struct {
+	Component string
+	Phase     StartupPhase
+	Duration  time.Duration // 自开始启动以来的耗时
+	Err       error
+}
+
+// startupConfig 启动超时与进度报告
+type startupConfig struct {
+	timeouts  map[string]time.Duration // 组件名 -> 启动超时
+	interval  time.Duration            // 慢启动进度报告间隔
+	observers []func(StartupEvent)
+	duration  observability.Histogram
+	starting  observability.Gauge
+	late      sync.WaitGroup // 超时后仍在执行的 Start
+}
+
+// StartTimeout 设置组件启动超时，超时后启动失败并返回 ErrStartTimeout。
+// Start 的 ctx 保留调用方的值但不随其取消，只受启动超时约束，并在 Start 返回后取消，组件不应将其用于后台任务；
+// 未响应取消的 Start 不再等待，视为启动失败，其稍后成功返回时组件会被停止。
+func StartTimeout(d time.Duration) ComponentOption {
+	return func(e *componentEntry) { e.startTimeout = d }
+}
+
+// WithStartTimeout 设置指定组件的启动超时（等价于注册时使用 StartTimeout），
+// 用于组件由其他模块注册、无法修改注册选项的场景
+func WithStartTimeout(c Component, d time.Duration) Option {
+	return func(a *App) {
+		if a.startup.timeouts == nil {
+			a.startup.timeouts = make(map[string]time.Duration)
+		}
+		a.startup.timeouts[c.Name()] = d
+	}
+}
+
+// WithStartupProgress 设置慢启动进度报告间隔（默认 5s）：组件启动超过该时间仍未完成时，
+// 每个间隔记录一次 "component still starting" 日志并发出 StartupWaiting 事件
+func WithStartupProgress(interval time.Duration) Option {
+	return func(a *App) { a.startup.interval = interval }
+}
+
+// WithStartupObserver 订阅组件启动进度事件（同步调用，不应阻塞）
+func WithStartupObserver(fn func(StartupEvent)) Option {
+	return func(a *App) { a.startup.observers = append(a.startup.observers, fn) }
+}
+
+// WithStartupMetrics 记录启动指标：
+// runtime_component_start_duration_seconds{component,result} 与 runtime_component_starting{component}
+func WithStartupMetrics(p observability.MetricsProvider) Option {
+	return func(a *App) {
+		a.startup.duration = p.Histogram("runtime_component_start_duration_seconds",
+			"Component start duration", observability.DurationBuckets, "component", "result")
+		a.startup.starting = p.Gauge("runtime_component_starting",
+			"Components currently starting", "component")
+	}
+}
+
+// startComponent 启动单个组件：应用启动超时、报告进度并记录结果，by 为触发方（app、admin）
+func (a *App) startComponent(ctx context.Context, c *componentEntry, by string) error {
+	name := c.component.Name()
+	timeout := c.startTimeout
+	if d, ok := a.startup.timeouts[name]; ok {
+		timeout = d
+	}
+
+	fields := []logger.Field{logger.String("name", name)}
+	if by != "app" {
+		fields = append(fields, logger.String("by", by))
+	}
+	if timeout > 0 {
+		fields = append(fields, logger.Duration("timeout", timeout))
+	}
+	a.log.Info(ctx, "starting component", fields...)
+
+	start := time.Now()
+	a.emitStartup(StartupEvent{Component: name, Phase: StartupStarting})
+	if a.startup.starting != nil {
+		a.startup.starting.Inc(name)
+		defer a.startup.starting.Dec(name)
+	}
+
+	err := a.runStart(ctx, c.component, timeout, start)
+	elapsed := time.Since(start)
+	a.record(ctx, name, "start", start, err)
+
+	result := "ok"
+	if err != nil {
+		result = "error"
+		a.log.Error(ctx, "component start failed",
+			logger.String("name", name), logger.Duration("duration", elapsed), logger.Err(err))
+		a.emitStartup(StartupEvent{Component: name, Phase: StartupFailed, Duration: elapsed, Err: err})
+	} else {
+		a.log.Info(ctx, "component started", logger.String("name", name), logger.Duration("duration", elapsed))
+		a.emitStartup(StartupEvent{Component: name, Phase: StartupStarted, Duration: elapsed})
+	}
+	if a.startup.duration != nil {
+		a.startup.duration.Observe(elapsed.Seconds(), name, result)
+	}
+	return err
+}
+
+// runStart 执行 Start，超时后不再等待（即使组件未响应 ctx 取消），并周期报告慢启动。
+// Start 的 ctx 与调用方的取消解耦，仅受启动超时约束
+func (a *App) runStart(ctx context.Context, c Component, timeout time.Duration, start time.Time) error {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancel()
+	var expired <-chan struct{} // 未设置超时时等待 Start 返回
+	if timeout > 0 {
+		ctx, cancel = context.WithDeadline(ctx, start.Add(timeout))
+		defer cancel()
+		expired = ctx.Done()
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- c.Start(ctx) }()
+
+	interval := a.startup.interval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case err := <-done:
+			return startErr(ctx, c, timeout, err)
+		case <-ticker.C:
+			elapsed := time.Since(start)
+			a.log.Warn(ctx, "component still starting",
+				logger.String("name", c.Name()), logger.Duration("elapsed", elapsed))
+			a.emitStartup(StartupEvent{Component: c.Name(), Phase: StartupWaiting, Duration: elapsed})
+		case <-expired:
+			// 给组件短暂时间响应取消，仍未返回则视为挂起
+			select {
+			case err := <-done:
+				return startErr(ctx, c, timeout, err)
+			case <-time.After(100 * time.Millisecond):
+			}
+			a.awaitLateStart(ctx, c, done)
+			return startErr(ctx, c, timeout, ctx.Err())
+		}
+	}
+}
+
+// awaitLateStart 跟踪超时后仍在执行的 Start：稍后成功返回时停止组件，避免其在启动失败后继续运行
+func (a *App) awaitLateStart(ctx context.Context, c Component, done <-chan error) {
+	a.startup.late.Go(func() {
+		err := <-done
+		if err != nil {
+			a.log.Warn(ctx, "component start returned after timeout",
+				logger.String("name", c.Name()), logger.Err(err))
+			return
+		}
+		a.log.Warn(ctx, "component started after timeout, stopping", logger.String("name", c.Name()))
+		timeout := a.cfg.ShutdownTimeout
+		if timeout <= 0 {
+			timeout = 30 * time.Second
+		}
+		stopCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+		defer cancel()
+		start := time.Now()
+		err = c.Stop(stopCtx)
+		a.record(stopCtx, c.Name(), "late_stop", start, err)
+		if err != nil {
+			a.log.Error(ctx, "component stop failed", logger.String("name", c.Name()), logger.Err(err))
+		}
+	})
+}
+
+// waitLateStarts 等待超时后仍在执行的 Start 结束（及随后的停止），受 ctx 约束
+func (a *App) waitLateStarts(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		a.startup.late.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
+
+// startErr 启动因超时失败时包装为 ErrStartTimeout
+func startErr(ctx context.Context, c Component, timeout time.Duration, err error) error {
+	if err != nil && timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %s did not start within %s: %w", ErrStartTimeout, c.Name(), timeout, err)
+	}
+	return err
+}
+
+func (a *App) emitStartup(e StartupEvent) {
+	for _, fn := range a.startup.observers {
+		fn(e)
+	}
+}
@@ -0,0 +1,126 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// slowComponent 启动耗时 delay，可选择忽略 ctx 取消
+type slowComponent struct {
+	countingComponent
+	delay     time.Duration
+	ignoreCtx bool
+	startCtx  chan context.Context
+}
+
+func (c *slowComponent) Start(ctx context.Context) error {
+	c.starts.Add(1)
+	if c.startCtx != nil {
+		c.startCtx <- ctx
+	}
+	if c.ignoreCtx {
+		time.Sleep(c.delay)
+		return nil
+	}
+	select {
+	case <-time.After(c.delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestStartTimeout_LateStartIsStopped(t *testing.T) {
+	ctx := context.Background()
+	c := &slowComponent{countingComponent: countingComponent{name: "hung"}, delay: 300 * time.Millisecond, ignoreCtx: true}
+	app := New(DefaultConfig())
+	app.Register(c, StartTimeout(20*time.Millisecond))
+
+	err := app.Start(ctx)
+	if !errors.Is(err, ErrStartTimeout) {
+		t.Fatalf("start = %v", err)
+	}
+	if c.stops.Load() != 0 {
+		t.Fatal("component stopped before its Start returned")
+	}
+	eventually(t, "late stop", func() bool { return c.stops.Load() == 1 })
+
+	var late bool
+	for _, r := range app.takeRecords() {
+		late = late || (r.Name == "hung" && r.Phase == "late_stop")
+	}
+	if !late {
+		t.Fatal("late stop not recorded")
+	}
+}
+
+func TestStartTimeout_CancelledStartNotStopped(t *testing.T) {
+	ctx := context.Background()
+	c := &slowComponent{countingComponent: countingComponent{name: "slow"}, delay: time.Hour}
+	app := New(DefaultConfig())
+	app.Register(c, StartTimeout(20*time.Millisecond))
+
+	if err := app.Start(ctx); !errors.Is(err, ErrStartTimeout) {
+		t.Fatalf("start = %v", err)
+	}
+	app.waitLateStarts(ctx)
+	if c.stops.Load() != 0 {
+		t.Fatalf("component that failed to start was stopped %d times", c.stops.Load())
+	}
+}
+
+func TestStart_ContextDetachedFromCaller(t *testing.T) {
+	type key struct{}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "v"))
+	c := &slowComponent{countingComponent: countingComponent{name: "db"}, delay: 50 * time.Millisecond, startCtx: make(chan context.Context, 1)}
+	app := New(DefaultConfig())
+	app.Register(c)
+
+	errc := make(chan error, 1)
+	go func() { errc <- app.Start(ctx) }()
+	startCtx := <-c.startCtx
+	cancel()
+
+	if err := <-errc; err != nil {
+		t.Fatalf("start after caller cancellation = %v", err)
+	}
+	if startCtx.Value(key{}) != "v" {
+		t.Fatal("start ctx lost caller values")
+	}
+	_ = app.Stop(context.Background())
+}
+
+func TestStartupObserver(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		phases []StartupPhase
+	)
+	var waiting atomic.Int32
+	c := &slowComponent{countingComponent: countingComponent{name: "db"}, delay: 60 * time.Millisecond}
+	app := New(DefaultConfig(), WithStartupProgress(10*time.Millisecond), WithStartupObserver(func(e StartupEvent) {
+		if e.Phase == StartupWaiting {
+			waiting.Add(1)
+			return
+		}
+		mu.Lock()
+		phases = append(phases, e.Phase)
+		mu.Unlock()
+	}))
+	app.Register(c)
+
+	if err := app.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer app.Stop(context.Background())
+	if !slices.Equal(phases, []StartupPhase{StartupStarting, StartupStarted}) {
+		t.Fatalf("phases = %v", phases)
+	}
+	if waiting.Load() == 0 {
+		t.Fatal("no progress events for slow start")
+	}
+}
@@ -0,0 +1,151 @@
+package runtime
+
+import (
+	"context"
+	"reflect"
+	goruntime "runtime"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/mildsunup/higo/logger"
+)
+
+// ComponentNode 组件树节点
+type ComponentNode struct {
+	Name         string   `json:"name"`
+	Type         string   `json:"type"`
+	Priority     int      `json:"priority"`
+	DependsOn    []string `json:"depends_on,omitempty"`
+	StartOrder   int      `json:"start_order"` // 从 1 开始，停止顺序与之相反
+	Registered   int      `json:"registered"`  // 注册顺序，同优先级按注册顺序启动
+	Capabilities []string `json:"capabilities,omitempty"`
+	Started      bool     `json:"started"`
+}
+
+// ComponentTree 解析后的组件树：启动顺序、钩子与组件能力
+type ComponentTree struct {
+	App        string              `json:"app"`
+	State      string              `json:"state"`
+	Components []ComponentNode     `json:"components"`
+	Hooks      map[string][]string `json:"hooks,omitempty"` // 阶段 -> 钩子函数名
+	Error      string              `json:"error,omitempty"`
+}
+
+// StopOrder 返回停止顺序（组件名）
+func (t ComponentTree) StopOrder() []string {
+	names := make([]string, 0, len(t.Components))
+	for i := len(t.Components) - 1; i >= 0; i-- {
+		names = append(names, t.Components[i].Name)
+	}
+	return names
+}
+
+// WithTreeLogging 启动任何组件前记录完整的组件树，便于排查启动顺序问题
+func WithTreeLogging() Option {
+	return func(a *App) {
+		a.logTree = true
+	}
+}
+
+// Tree 返回当前解析出的组件树（不启动任何组件）
+// 依赖声明无效时按优先级排序，并在 Error 中给出原因。
+func (a *App) Tree() ComponentTree {
+	a.mu.Lock()
+	var resolveErr string
+	entries, err := resolveOrder(a.components)
+	if err != nil {
+		resolveErr = err.Error()
+		entries = slices.Clone(a.components)
+		sortByPriority(entries)
+	}
+	a.mu.Unlock()
+
+	tree := ComponentTree{
+		App:        a.cfg.Name,
+		State:      a.State().String(),
+		Components: make([]ComponentNode, 0, len(entries)),
+		Hooks:      make(map[string][]string),
+		Error:      resolveErr,
+	}
+	for i, e := range entries {
+		tree.Components = append(tree.Components, ComponentNode{
+			Name:         e.component.Name(),
+			Type:         reflect.TypeOf(unwrapComponent(e.component)).String(),
+			Priority:     e.priority,
+			DependsOn:    e.deps,
+			StartOrder:   i + 1,
+			Registered:   e.seq,
+			Capabilities: capabilities(e.component),
+			Started:      e.started,
+		})
+	}
+
+	for phase, hooks := range map[string][]Hook{
+		"before_start": a.hooks.beforeStart,
+		"after_start":  a.hooks.afterStart,
+		"pre_stop":     a.hooks.preStop,
+		"before_stop":  a.hooks.beforeStop,
+		"after_stop":   a.hooks.afterStop,
+	} {
+		for _, h := range hooks {
+			tree.Hooks[phase] = append(tree.Hooks[phase], funcName(h))
+		}
+	}
+	return tree
+}
+
+// sortByPriority 按优先级稳定排序
+func sortByPriority(entries []componentEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].priority < entries[j].priority
+	})
+}
+
+func (a *App) logComponentTree(ctx context.Context) {
+	tree := a.Tree()
+	for _, n := range tree.Components {
+		a.log.Info(ctx, "component tree",
+			logger.Int("order", n.StartOrder),
+			logger.String("name", n.Name),
+			logger.String("type", n.Type),
+			logger.Int("priority", n.Priority),
+			logger.String("depends_on", strings.Join(n.DependsOn, ",")),
+			logger.String("capabilities", strings.Join(n.Capabilities, ",")),
+		)
+	}
+	fields := []logger.Field{logger.String("stop_order", strings.Join(tree.StopOrder(), " -> "))}
+	for phase, hooks := range tree.Hooks {
+		fields = append(fields, logger.String("hooks_"+phase, strings.Join(hooks, ",")))
+	}
+	a.log.Info(ctx, "component lifecycle", fields...)
+}
+
+func capabilities(c Component) []string {
+	var caps []string
+	if _, ok := c.(*leaderGate); ok {
+		caps = append(caps, "leader_only")
+		c = unwrapComponent(c)
+	}
+	if _, ok := c.(HealthChecker); ok {
+		caps = append(caps, "health")
+	}
+	if _, ok := c.(Drainer); ok {
+		caps = append(caps, "drain")
+	}
+	if _, ok := c.(Flusher); ok {
+		caps = append(caps, "flush")
+	}
+	if _, ok := c.(Reloader); ok {
+		caps = append(caps, "reload")
+	}
+	return caps
+}
+
+func funcName(h Hook) string {
+	fn := goruntime.FuncForPC(reflect.ValueOf(h).Pointer())
+	if fn == nil {
+		return "unknown"
+	}
+	return fn.Name()
+}
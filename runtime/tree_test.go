@@ -0,0 +1,156 @@
+package runtime
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mildsunup/higo/logger"
+)
+
+// checkedComponent 带健康检查的组件
+type checkedComponent struct{ countingComponent }
+
+func (c *checkedComponent) Health(context.Context) error { return nil }
+
+// recordingLogger 记录 Info 日志的消息与字段
+type recordingLogger struct {
+	logger.Logger
+	mu      sync.Mutex
+	entries []string
+}
+
+func newRecordingLogger() *recordingLogger { return &recordingLogger{Logger: logger.Nop()} }
+
+func (l *recordingLogger) Info(_ context.Context, msg string, fields ...logger.Field) {
+	var b strings.Builder
+	b.WriteString(msg)
+	for _, f := range fields {
+		b.WriteString(" " + f.Key + "=")
+		if s, ok := f.Value.(string); ok {
+			b.WriteString(s)
+		}
+	}
+	l.mu.Lock()
+	l.entries = append(l.entries, b.String())
+	l.mu.Unlock()
+}
+
+func (l *recordingLogger) lines() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Clone(l.entries)
+}
+
+func treeNames(t ComponentTree) []string {
+	names := make([]string, len(t.Components))
+	for i, n := range t.Components {
+		names[i] = n.Name
+	}
+	return names
+}
+
+func appAfterStart(context.Context) error { return nil }
+
+func TestTree(t *testing.T) {
+	app := New(DefaultConfig(), WithLeaderElection(&fakeLocker{}, LeaderConfig{}))
+	app.Register(&countingComponent{name: "api"}, DependsOn("cache"))
+	app.Register(&checkedComponent{countingComponent{name: "cache"}}, DependsOn("db"))
+	app.Register(&countingComponent{name: "db"}, Priority(5))
+	app.Register(&countingComponent{name: "cron"}, LeaderOnly())
+	app.OnAfterStart(appAfterStart)
+
+	tree := app.Tree()
+	if tree.App != "app" || tree.State != StateCreated.String() || tree.Error != "" {
+		t.Fatalf("tree = %+v", tree)
+	}
+	order := treeNames(tree)
+	if idx := func(n string) int { return slices.Index(order, n) }; !(idx("db") < idx("cache") && idx("cache") < idx("api")) {
+		t.Fatalf("start order = %v", order)
+	}
+	reversed := slices.Clone(order)
+	slices.Reverse(reversed)
+	if stop := tree.StopOrder(); !slices.Equal(stop, reversed) {
+		t.Fatalf("stop order = %v, start order = %v", stop, order)
+	}
+
+	nodes := make(map[string]ComponentNode)
+	for i, n := range tree.Components {
+		if n.StartOrder != i+1 || n.Started {
+			t.Fatalf("node = %+v", n)
+		}
+		nodes[n.Name] = n
+	}
+	if n := nodes["cache"]; n.Type != "*runtime.checkedComponent" || !slices.Equal(n.Capabilities, []string{"health"}) ||
+		!slices.Equal(n.DependsOn, []string{"db"}) || n.Registered != 2 {
+		t.Fatalf("cache = %+v", n)
+	}
+	// LeaderOnly 组件报告被包装组件的类型
+	if n := nodes["cron"]; n.Type != "*runtime.countingComponent" || !slices.Equal(n.Capabilities, []string{"leader_only"}) {
+		t.Fatalf("cron = %+v", n)
+	}
+	if n := nodes["db"]; n.Priority != 5 {
+		t.Fatalf("db = %+v", n)
+	}
+	if hooks := tree.Hooks["after_start"]; len(hooks) != 1 || !strings.HasSuffix(hooks[0], ".appAfterStart") {
+		t.Fatalf("hooks = %v", tree.Hooks)
+	}
+}
+
+func TestTree_InvalidDependencies(t *testing.T) {
+	app := New(DefaultConfig())
+	app.Register(&countingComponent{name: "a"}, DependsOn("b"), Priority(2))
+	app.Register(&countingComponent{name: "b"}, DependsOn("a"), Priority(1))
+
+	tree := app.Tree()
+	if tree.Error == "" {
+		t.Fatal("expected cycle error")
+	}
+	// 依赖无效时按优先级排序
+	if order := treeNames(tree); !slices.Equal(order, []string{"b", "a"}) {
+		t.Fatalf("fallback order = %v", order)
+	}
+}
+
+func TestWithTreeLogging(t *testing.T) {
+	log := newRecordingLogger()
+	app := New(DefaultConfig(), WithLogger(log), WithTreeLogging())
+	app.Register(&countingComponent{name: "db"})
+	app.Register(&countingComponent{name: "api"}, DependsOn("db"))
+
+	if err := app.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer app.Stop(context.Background())
+
+	var tree, lifecycle []string
+	firstTree, firstStart := -1, -1
+	for i, l := range log.lines() {
+		switch {
+		case strings.HasPrefix(l, "component tree "):
+			tree = append(tree, l)
+			if firstTree < 0 {
+				firstTree = i
+			}
+		case strings.HasPrefix(l, "component lifecycle "):
+			lifecycle = append(lifecycle, l)
+		case strings.HasPrefix(l, "starting component") && firstStart < 0:
+			firstStart = i
+		}
+	}
+	if len(tree) != 2 || !strings.Contains(tree[0], "name=db") || !strings.Contains(tree[1], "depends_on=db") {
+		t.Fatalf("tree lines = %q", tree)
+	}
+	if len(lifecycle) != 1 || !strings.Contains(lifecycle[0], "stop_order=api -> db") {
+		t.Fatalf("lifecycle lines = %q", lifecycle)
+	}
+	// 组件树在任何组件启动之前输出
+	if firstStart < 0 || firstTree > firstStart {
+		t.Fatalf("tree logged at %d, first start at %d", firstTree, firstStart)
+	}
+	if n := app.Tree(); !n.Components[0].Started || n.State != StateRunning.String() {
+		t.Fatalf("tree after start = %+v", n)
+	}
+}
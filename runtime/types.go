@@ -19,6 +19,21 @@ type HealthChecker interface {
 	Health(ctx context.Context) error
 }
 
+// ReadinessChecker 就绪检查接口（可选实现），App 在预停止阶段起返回 ErrNotReady
+type ReadinessChecker interface {
+	Ready(ctx context.Context) error
+}
+
+// Drainer 支持排空的组件（如 HTTP 服务器关闭 keep-alive），在预停止阶段调用
+type Drainer interface {
+	Drain(ctx context.Context) error
+}
+
+// Reloader 支持热重载的组件（如重新读取证书、刷新限流阈值），收到 SIGHUP 或调用 App.Reload 时调用
+type Reloader interface {
+	Reload(ctx context.Context) error
+}
+
 // Hook 生命周期钩子
 type Hook func(ctx context.Context) error
 
@@ -56,7 +71,11 @@ func (s State) String() string {
 // Config 应用配置
 type Config struct {
 	Name            string        `yaml:"name" mapstructure:"name"`
+	Env             string        `yaml:"env" mapstructure:"env"`
+	Version         string        `yaml:"version" mapstructure:"version"` // 为空时使用构建信息，见 NewAppInfo
 	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" mapstructure:"shutdown_timeout"`
+	PreStopDelay    time.Duration `yaml:"pre_stop_delay" mapstructure:"pre_stop_delay"` // 就绪摘除后等待负载均衡注销的时间
+	ReloadTimeout   time.Duration `yaml:"reload_timeout" mapstructure:"reload_timeout"` // 信号触发重载的超时，默认 30s
 }
 
 // DefaultConfig 默认配置
@@ -0,0 +1,124 @@
+package security
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BreachChecker 密码泄露检查
+type BreachChecker interface {
+	// BreachCount 返回密码在已知泄露数据中出现的次数，未出现返回 0
+	BreachCount(ctx context.Context, password string) (int, error)
+}
+
+// BreachCheckerFunc 函数适配器
+type BreachCheckerFunc func(ctx context.Context, password string) (int, error)
+
+func (f BreachCheckerFunc) BreachCount(ctx context.Context, password string) (int, error) {
+	return f(ctx, password)
+}
+
+// HIBPChecker 基于 HaveIBeenPwned Pwned Passwords range API 的泄露检查。
+// 使用 k-匿名：只发送 SHA-1 的前 5 位，在本地比对返回的后缀，密码本身不会离开进程。
+type HIBPChecker struct {
+	client    *http.Client
+	endpoint  string
+	userAgent string
+	padding   bool
+}
+
+// HIBPOption HIBP 检查选项
+type HIBPOption func(*HIBPChecker)
+
+// WithHIBPClient 设置 HTTP 客户端（可注入带重试/追踪的 Transport）
+func WithHIBPClient(c *http.Client) HIBPOption {
+	return func(h *HIBPChecker) { h.client = c }
+}
+
+// WithHIBPEndpoint 设置 range API 地址（自建镜像），默认 https://api.pwnedpasswords.com/range/
+func WithHIBPEndpoint(endpoint string) HIBPOption {
+	return func(h *HIBPChecker) {
+		if !strings.HasSuffix(endpoint, "/") {
+			endpoint += "/"
+		}
+		h.endpoint = endpoint
+	}
+}
+
+// WithHIBPUserAgent 设置 User-Agent
+func WithHIBPUserAgent(ua string) HIBPOption {
+	return func(h *HIBPChecker) { h.userAgent = ua }
+}
+
+// WithHIBPPadding 是否请求填充响应（隐藏响应长度），默认开启
+func WithHIBPPadding(enabled bool) HIBPOption {
+	return func(h *HIBPChecker) { h.padding = enabled }
+}
+
+// NewHIBPChecker 创建 HIBP 泄露检查
+func NewHIBPChecker(opts ...HIBPOption) *HIBPChecker {
+	h := &HIBPChecker{
+		client:    &http.Client{Timeout: 5 * time.Second},
+		endpoint:  "https://api.pwnedpasswords.com/range/",
+		userAgent: "higo-security",
+		padding:   true,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+func (h *HIBPChecker) BreachCount(ctx context.Context, password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.endpoint+prefix, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", h.userAgent)
+	if h.padding {
+		req.Header.Set("Add-Padding", "true")
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("security: hibp request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("security: hibp status %d", resp.StatusCode)
+	}
+
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		s, c, ok := strings.Cut(strings.TrimSpace(sc.Text()), ":")
+		if !ok || !strings.EqualFold(s, suffix) {
+			continue
+		}
+		// 填充行的次数为 0
+		n, err := strconv.Atoi(c)
+		if err != nil {
+			return 0, fmt.Errorf("security: hibp malformed count: %w", err)
+		}
+		return n, nil
+	}
+	if err := sc.Err(); err != nil {
+		return 0, fmt.Errorf("security: hibp read: %w", err)
+	}
+	return 0, nil
+}
+
+var (
+	_ BreachChecker = (*HIBPChecker)(nil)
+	_ BreachChecker = BreachCheckerFunc(nil)
+)
@@ -0,0 +1,67 @@
+package security
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// "password" 的 SHA-1 为 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+const passwordSuffix = "1E4C9B93F3F0682250B6CF8331B7EE68FD8"
+
+func TestHIBPChecker(t *testing.T) {
+	var gotPath, gotPadding, gotUA string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotPadding, gotUA = r.URL.Path, r.Header.Get("Add-Padding"), r.Header.Get("User-Agent")
+		// 填充行次数为 0；后缀大小写不敏感
+		fmt.Fprint(w, "0018A45C4D1DEF81644B54AB7F969B88D65:0\r\n")
+		fmt.Fprintf(w, "%s:9545824\r\n", strings.ToLower(passwordSuffix))
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+	h := NewHIBPChecker(WithHIBPEndpoint(srv.URL+"/range"), WithHIBPUserAgent("shop-signup"))
+
+	n, err := h.BreachCount(ctx, "password")
+	if err != nil || n != 9545824 {
+		t.Fatalf("breach count = %d, %v", n, err)
+	}
+	// k-匿名：只发送哈希前 5 位
+	if gotPath != "/range/5BAA6" || gotPadding != "true" || gotUA != "shop-signup" {
+		t.Fatalf("request path = %q, padding = %q, ua = %q", gotPath, gotPadding, gotUA)
+	}
+
+	// 响应中没有对应后缀即未泄露
+	if n, err := NewHIBPChecker(WithHIBPEndpoint(srv.URL+"/range/"), WithHIBPPadding(false)).BreachCount(ctx, "x7#Kq!v9Lm2$"); err != nil || n != 0 {
+		t.Fatalf("unlisted = %d, %v", n, err)
+	}
+	if gotPadding != "" {
+		t.Fatalf("padding header sent when disabled: %q", gotPadding)
+	}
+}
+
+func TestHIBPChecker_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/range/5BAA6" {
+			fmt.Fprintf(w, "%s:many\n", passwordSuffix)
+			return
+		}
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+	h := NewHIBPChecker(WithHIBPEndpoint(srv.URL + "/range/"))
+
+	if _, err := h.BreachCount(context.Background(), "password"); err == nil || !strings.Contains(err.Error(), "malformed count") {
+		t.Fatalf("malformed = %v", err)
+	}
+	if _, err := h.BreachCount(context.Background(), "hunter2"); err == nil || !strings.Contains(err.Error(), "status 429") {
+		t.Fatalf("rate limited = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := h.BreachCount(ctx, "hunter2"); err == nil {
+		t.Fatal("expected error for cancelled context")
+	}
+}
@@ -1,8 +1,12 @@
 // Package security 提供安全工具。
 //
 // 核心功能：
-//   - 限流器（令牌桶/漏桶）
-//   - 密码强度验证
+//   - 限流器（令牌桶/漏桶），令牌桶按 key 分桶，本地为 resilience.TokenBucket；
+//     WithRedisStore 复用 middleware.RedisRateLimiter 的 GCRA 脚本（重启不丢、多实例共享），Redis 不可用时降级为本地桶
+//   - 密码策略（长度/字符类别/熵/强度评分/禁用词/常见密码），zxcvbn 风格强度估算（EstimateStrength），
+//     可选 HaveIBeenPwned k-匿名泄露检查（HIBPChecker）；Check 返回结构化违规明细，Err() 可直接写入响应
+//   - AES-GCM 加解密
+//   - 带版本的密钥环（Keyring），支持密钥轮换
 //
 // 使用示例：
 //
@@ -10,4 +14,17 @@
 //	if limiter.Allow() {
 //	    // 处理请求
 //	}
+//
+//	policy := security.DefaultPasswordPolicy()
+//	policy.MinScore, policy.DenyCommon = 3, true
+//	policy.Breach = security.NewHIBPChecker()
+//	if err := policy.Check(ctx, pwd, username, email).Err(); err != nil {
+//	    response.WriteError(w, r, err) // details.violations 列出全部违规项
+//	}
+//
+//	// 按用户限额，状态保存在 Redis
+//	quota := security.NewTokenBucket(10, 100, security.WithRedisStore(rdb, "quota:"), security.WithBucketLogger(log))
+//	if res := quota.Take(ctx, userID, 1); !res.Allowed {
+//	    // 返回 429，Retry-After: res.RetryAfter
+//	}
 package security
@@ -0,0 +1,99 @@
+package security
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrUnknownKeyVersion 密文使用的密钥版本不在密钥环中
+var ErrUnknownKeyVersion = errors.New("security: unknown key version")
+
+// Keyring 带版本的 AES-GCM 密钥环
+// 密文格式为 "v<版本>:<base64(nonce||ciphertext)>"，新数据使用当前版本加密，
+// 旧版本密钥保留用于解密，直至数据完成轮换。
+type Keyring struct {
+	current uint32
+	keys    map[uint32][]byte
+}
+
+// NewKeyring 创建密钥环，current 必须存在于 keys 中
+func NewKeyring(current uint32, keys map[uint32][]byte) (*Keyring, error) {
+	if _, ok := keys[current]; !ok {
+		return nil, fmt.Errorf("security: current key version %d not found", current)
+	}
+	for v, k := range keys {
+		if _, err := newGCM(k); err != nil {
+			return nil, fmt.Errorf("security: key version %d: %w", v, err)
+		}
+	}
+	return &Keyring{current: current, keys: keys}, nil
+}
+
+// Current 当前密钥版本
+func (k *Keyring) Current() uint32 { return k.current }
+
+// Encrypt 使用当前版本密钥加密
+func (k *Keyring) Encrypt(plaintext []byte) (string, error) {
+	ct, err := EncryptAESGCM(k.keys[k.current], plaintext, nil)
+	if err != nil {
+		return "", err
+	}
+	return "v" + strconv.FormatUint(uint64(k.current), 10) + ":" + base64.StdEncoding.EncodeToString(ct), nil
+}
+
+// Decrypt 按密文中的版本选择密钥解密
+func (k *Keyring) Decrypt(ciphertext string) ([]byte, error) {
+	version, data, err := splitVersioned(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := k.keys[version]
+	if !ok {
+		return nil, ErrUnknownKeyVersion
+	}
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	return DecryptAESGCM(key, raw, nil)
+}
+
+// NeedsRotation 判断密文是否使用了非当前版本的密钥
+func (k *Keyring) NeedsRotation(ciphertext string) bool {
+	version, _, err := splitVersioned(ciphertext)
+	return err == nil && version != k.current
+}
+
+// Rotate 使用当前版本密钥重新加密，已是当前版本时原样返回
+func (k *Keyring) Rotate(ciphertext string) (string, bool, error) {
+	if !k.NeedsRotation(ciphertext) {
+		return ciphertext, false, nil
+	}
+	plaintext, err := k.Decrypt(ciphertext)
+	if err != nil {
+		return "", false, err
+	}
+	out, err := k.Encrypt(plaintext)
+	return out, err == nil, err
+}
+
+// KeyVersion 解析密文的密钥版本
+func KeyVersion(ciphertext string) (uint32, bool) {
+	v, _, err := splitVersioned(ciphertext)
+	return v, err == nil
+}
+
+func splitVersioned(s string) (uint32, string, error) {
+	prefix, data, ok := strings.Cut(s, ":")
+	if !ok || len(prefix) < 2 || prefix[0] != 'v' {
+		return 0, "", ErrInvalidCiphertext
+	}
+	v, err := strconv.ParseUint(prefix[1:], 10, 32)
+	if err != nil {
+		return 0, "", ErrInvalidCiphertext
+	}
+	return uint32(v), data, nil
+}
@@ -0,0 +1,79 @@
+package security
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testKeys() map[uint32][]byte {
+	return map[uint32][]byte{
+		1: bytes.Repeat([]byte{1}, 32),
+		2: bytes.Repeat([]byte{2}, 32),
+	}
+}
+
+func TestNewKeyring(t *testing.T) {
+	if _, err := NewKeyring(3, testKeys()); err == nil {
+		t.Fatal("missing current version accepted")
+	}
+	if _, err := NewKeyring(1, map[uint32][]byte{1: []byte("short")}); err == nil {
+		t.Fatal("invalid key length accepted")
+	}
+}
+
+func TestKeyring_EncryptDecrypt(t *testing.T) {
+	kr, err := NewKeyring(2, testKeys())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct, err := kr.Encrypt([]byte("13800000000"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(ct, "v2:") {
+		t.Fatalf("ciphertext = %q", ct)
+	}
+	if v, ok := KeyVersion(ct); !ok || v != 2 {
+		t.Fatalf("version = %d, %v", v, ok)
+	}
+	if pt, err := kr.Decrypt(ct); err != nil || string(pt) != "13800000000" {
+		t.Fatalf("decrypt = %q, %v", pt, err)
+	}
+
+	// 只持有 v1 的密钥环无法解密 v2
+	old, _ := NewKeyring(1, map[uint32][]byte{1: testKeys()[1]})
+	if _, err := old.Decrypt(ct); !errors.Is(err, ErrUnknownKeyVersion) {
+		t.Fatalf("unknown version = %v", err)
+	}
+	for _, bad := range []string{"", "plain", "x1:abc", "v:abc", "vX:abc", "v2:!!!"} {
+		if _, err := kr.Decrypt(bad); !errors.Is(err, ErrInvalidCiphertext) {
+			t.Errorf("Decrypt(%q) = %v", bad, err)
+		}
+	}
+	// 篡改密文无法通过认证
+	if _, err := kr.Decrypt(ct[:len(ct)-4] + "AAAA"); err == nil {
+		t.Fatal("tampered ciphertext decrypted")
+	}
+}
+
+func TestKeyring_Rotate(t *testing.T) {
+	v1, _ := NewKeyring(1, testKeys())
+	v2, _ := NewKeyring(2, testKeys())
+
+	old, _ := v1.Encrypt([]byte("secret"))
+	if !v2.NeedsRotation(old) || v1.NeedsRotation(old) || v2.NeedsRotation("garbage") {
+		t.Fatal("NeedsRotation mismatch")
+	}
+	rotated, changed, err := v2.Rotate(old)
+	if err != nil || !changed || !strings.HasPrefix(rotated, "v2:") {
+		t.Fatalf("rotate = %q, %v, %v", rotated, changed, err)
+	}
+	if pt, _ := v1.Decrypt(rotated); string(pt) != "secret" {
+		t.Fatalf("rotated plaintext = %q", pt)
+	}
+	if same, changed, err := v2.Rotate(rotated); err != nil || changed || same != rotated {
+		t.Fatalf("rotate current = %q, %v, %v", same, changed, err)
+	}
+}
@@ -1,8 +1,8 @@
 package security
 
 import (
+	"context"
 	"errors"
-	"unicode"
 )
 
 var (
@@ -12,6 +12,12 @@ var (
 	ErrPasswordNoLower    = errors.New("password must contain at least one lowercase letter")
 	ErrPasswordNoDigit    = errors.New("password must contain at least one digit")
 	ErrPasswordNoSpecial  = errors.New("password must contain at least one special character")
+	ErrPasswordFewClasses = errors.New("password must contain more character classes")
+	ErrPasswordDenied     = errors.New("password contains a banned word")
+	ErrPasswordCommon     = errors.New("password is too common")
+	ErrPasswordLowEntropy = errors.New("password is too predictable")
+	ErrPasswordTooWeak    = errors.New("password is too weak")
+	ErrPasswordBreached   = errors.New("password has appeared in a data breach")
 )
 
 // PasswordPolicy 密码策略
@@ -22,6 +28,14 @@ type PasswordPolicy struct {
 	RequireLower   bool
 	RequireDigit   bool
 	RequireSpecial bool
+
+	MinClasses     int           // 至少包含的字符类别数（大写/小写/数字/符号/其他），0 不检查
+	MinEntropy     float64       // 最小估算熵（bits），0 不检查
+	MinScore       int           // 最小强度评分（0-4，见 EstimateStrength），0 不检查
+	DenyList       []string      // 禁止包含的词（大小写不敏感，识别常见 leet 替换）
+	DenyCommon     bool          // 拒绝包含常见弱密码
+	Breach         BreachChecker // 泄露检查（如 HIBP），nil 不检查
+	MaxBreachCount int           // 允许的最大泄露次数，默认 0
 }
 
 // DefaultPasswordPolicy 默认密码策略
@@ -48,42 +62,12 @@ func StrictPasswordPolicy() PasswordPolicy {
 	}
 }
 
-// Validate 验证密码强度
+// Validate 验证密码强度，返回第一项违规对应的错误（不执行泄露检查，需要时使用 Check）
 func (p PasswordPolicy) Validate(password string) error {
-	if len(password) < p.MinLength {
-		return ErrPasswordTooShort
-	}
-	if len(password) > p.MaxLength {
-		return ErrPasswordTooLong
-	}
-
-	var hasUpper, hasLower, hasDigit, hasSpecial bool
-	for _, c := range password {
-		switch {
-		case unicode.IsUpper(c):
-			hasUpper = true
-		case unicode.IsLower(c):
-			hasLower = true
-		case unicode.IsDigit(c):
-			hasDigit = true
-		case unicode.IsPunct(c) || unicode.IsSymbol(c):
-			hasSpecial = true
-		}
-	}
-
-	if p.RequireUpper && !hasUpper {
-		return ErrPasswordNoUpper
-	}
-	if p.RequireLower && !hasLower {
-		return ErrPasswordNoLower
-	}
-	if p.RequireDigit && !hasDigit {
-		return ErrPasswordNoDigit
+	r := p.check(context.Background(), password, nil, false)
+	if len(r.Violations) > 0 {
+		return r.Violations[0].err
 	}
-	if p.RequireSpecial && !hasSpecial {
-		return ErrPasswordNoSpecial
-	}
-
 	return nil
 }
 
@@ -0,0 +1,185 @@
+package security
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/mildsunup/higo/errors"
+)
+
+// 违规规则
+const (
+	RuleMinLength  = "min_length"
+	RuleMaxLength  = "max_length"
+	RuleUpper      = "upper"
+	RuleLower      = "lower"
+	RuleDigit      = "digit"
+	RuleSpecial    = "special"
+	RuleClasses    = "classes"
+	RuleDenyList   = "deny_list"
+	RuleCommon     = "common"
+	RuleMinEntropy = "min_entropy"
+	RuleMinScore   = "min_score"
+	RuleBreached   = "breached"
+)
+
+// Violation 一项策略违规
+type Violation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+	err     error
+}
+
+// Err 返回违规对应的哨兵错误（如 ErrPasswordTooShort）
+func (v Violation) Err() error {
+	return v.err
+}
+
+// PasswordReport 密码检查报告
+type PasswordReport struct {
+	Violations    []Violation `json:"violations,omitempty"`
+	Strength      Strength    `json:"strength"`
+	BreachChecked bool        `json:"breach_checked"`         // 是否完成泄露检查
+	BreachCount   int         `json:"breach_count,omitempty"` // 泄露次数
+	BreachErr     error       `json:"-"`                      // 泄露检查失败原因（检查失败时放行）
+}
+
+// OK 是否通过全部检查
+func (r PasswordReport) OK() bool {
+	return len(r.Violations) == 0
+}
+
+// Err 转换为 ValidationFailed 错误，violations 与 strength 写入 Details，可直接用于响应体；
+// 通过时返回 nil。errors.Is 可匹配第一项违规的哨兵错误。
+func (r PasswordReport) Err() error {
+	if r.OK() {
+		return nil
+	}
+	return errors.Wrap(r.Violations[0].err, errors.ValidationFailed, "password does not meet policy").
+		WithMeta("violations", r.Violations).
+		WithMeta("strength", r.Strength)
+}
+
+// Check 按策略检查密码并返回完整报告。
+// userInputs 为与用户相关的信息（用户名、邮箱等），包含这些信息会降低强度评分。
+// 配置了 Breach 时执行泄露检查；泄露检查出错时放行并记录 BreachErr。
+func (p PasswordPolicy) Check(ctx context.Context, password string, userInputs ...string) PasswordReport {
+	return p.check(ctx, password, userInputs, true)
+}
+
+func (p PasswordPolicy) check(ctx context.Context, password string, userInputs []string, breach bool) PasswordReport {
+	var r PasswordReport
+	add := func(rule string, err error) {
+		r.Violations = append(r.Violations, Violation{Rule: rule, Message: err.Error(), err: err})
+	}
+
+	if len(password) < p.MinLength {
+		add(RuleMinLength, ErrPasswordTooShort)
+	}
+	if len(password) > p.MaxLength {
+		add(RuleMaxLength, ErrPasswordTooLong)
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSpecial bool
+	for _, c := range password {
+		switch {
+		case unicode.IsUpper(c):
+			hasUpper = true
+		case unicode.IsLower(c):
+			hasLower = true
+		case unicode.IsDigit(c):
+			hasDigit = true
+		case unicode.IsPunct(c) || unicode.IsSymbol(c):
+			hasSpecial = true
+		}
+	}
+
+	if p.RequireUpper && !hasUpper {
+		add(RuleUpper, ErrPasswordNoUpper)
+	}
+	if p.RequireLower && !hasLower {
+		add(RuleLower, ErrPasswordNoLower)
+	}
+	if p.RequireDigit && !hasDigit {
+		add(RuleDigit, ErrPasswordNoDigit)
+	}
+	if p.RequireSpecial && !hasSpecial {
+		add(RuleSpecial, ErrPasswordNoSpecial)
+	}
+	if p.MinClasses > 0 && charClasses(password) < p.MinClasses {
+		add(RuleClasses, ErrPasswordFewClasses)
+	}
+
+	r.Strength = estimateStrength(password, p.DenyList, userInputs)
+	if len(p.DenyList) > 0 && containsWord(password, p.DenyList) {
+		add(RuleDenyList, ErrPasswordDenied)
+	}
+	if p.DenyCommon && isCommon(password) {
+		add(RuleCommon, ErrPasswordCommon)
+	}
+	if p.MinEntropy > 0 && r.Strength.Entropy < p.MinEntropy {
+		add(RuleMinEntropy, ErrPasswordLowEntropy)
+	}
+	if p.MinScore > 0 && r.Strength.Score < p.MinScore {
+		add(RuleMinScore, ErrPasswordTooWeak)
+	}
+
+	if breach && p.Breach != nil && password != "" {
+		n, err := p.Breach.BreachCount(ctx, password)
+		if err != nil {
+			r.BreachErr = err
+		} else {
+			r.BreachChecked = true
+			r.BreachCount = n
+			if n > p.MaxBreachCount {
+				add(RuleBreached, ErrPasswordBreached)
+			}
+		}
+	}
+	return r
+}
+
+// isCommon 判断密码（小写及 leet 还原、去掉首尾数字与符号后）是否为常见弱密码
+func isCommon(password string) bool {
+	lower, normalized := normalize(password)
+	for _, s := range []string{lower, normalized} {
+		core := strings.TrimFunc(s, func(r rune) bool { return !unicode.IsLetter(r) })
+		for _, w := range commonPasswords {
+			if s == w || core == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// normalize 返回密码的小写形式与 leet 还原形式
+func normalize(password string) (lower, normalized string) {
+	l := make([]rune, 0, len(password))
+	n := make([]rune, 0, len(password))
+	for _, r := range password {
+		c := unicode.ToLower(r)
+		l = append(l, c)
+		if x, ok := leet[r]; ok {
+			c = x
+		}
+		n = append(n, c)
+	}
+	return string(l), string(n)
+}
+
+// containsWord 判断密码（小写及 leet 还原后）是否包含 words 中任一词
+func containsWord(password string, words []string) bool {
+	ls, ns := normalize(password)
+	for _, w := range words {
+		if w == "" {
+			continue
+		}
+		w = strings.ToLower(w)
+		if strings.Contains(ls, w) || strings.Contains(ns, w) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	herrors "github.com/mildsunup/higo/errors"
+)
+
+func rules(r PasswordReport) []string {
+	var out []string
+	for _, v := range r.Violations {
+		out = append(out, v.Rule)
+	}
+	return out
+}
+
+func TestPasswordPolicy_Validate(t *testing.T) {
+	p := DefaultPasswordPolicy()
+	tests := []struct {
+		password string
+		want     error
+	}{
+		{"Ab1", ErrPasswordTooShort},
+		{"alllowercase1", ErrPasswordNoUpper},
+		{"ALLUPPERCASE1", ErrPasswordNoLower},
+		{"NoDigitsHere", ErrPasswordNoDigit},
+		{"Valid1234", nil},
+	}
+	for _, tt := range tests {
+		if err := p.Validate(tt.password); err != tt.want {
+			t.Errorf("Validate(%q) = %v, want %v", tt.password, err, tt.want)
+		}
+	}
+	if err := StrictPasswordPolicy().Validate("NoSpecial1234"); err != ErrPasswordNoSpecial {
+		t.Errorf("strict = %v", err)
+	}
+}
+
+func TestPasswordPolicy_Check(t *testing.T) {
+	ctx := context.Background()
+	p := DefaultPasswordPolicy()
+	p.MinClasses = 4
+	p.MinScore = 3
+	p.DenyList = []string{"acme"}
+	p.DenyCommon = true
+
+	r := p.Check(ctx, "Acme1")
+	if want := []string{RuleMinLength, RuleClasses, RuleDenyList, RuleMinScore}; !slices.Equal(rules(r), want) {
+		t.Fatalf("rules = %v, want %v", rules(r), want)
+	}
+	if r.OK() || r.BreachChecked {
+		t.Fatalf("report = %+v", r)
+	}
+
+	// leet 替换与首尾数字符号不能绕过拒绝列表和常见密码检查
+	if got := rules(p.Check(ctx, "Welc0me-2@CM3!")); !slices.Contains(got, RuleDenyList) {
+		t.Fatalf("leet deny list = %v", got)
+	}
+	if got := rules(p.Check(ctx, "!!Password2024")); !slices.Contains(got, RuleCommon) {
+		t.Fatalf("common = %v", got)
+	}
+
+	// 用户信息降低评分
+	if got := rules(p.Check(ctx, "Jsmith2024")); slices.Contains(got, RuleMinScore) {
+		t.Fatalf("without user inputs = %v", got)
+	}
+	if got := rules(p.Check(ctx, "Jsmith2024", "jsmith@example.com", "jsmith")); !slices.Contains(got, RuleMinScore) {
+		t.Fatalf("personal info = %v", got)
+	}
+	if r := p.Check(ctx, "x7#Kq!v9Lm2$"); !r.OK() || r.Strength.Score != 4 || r.Err() != nil {
+		t.Fatalf("strong password = %+v", r)
+	}
+}
+
+func TestPasswordReport_Err(t *testing.T) {
+	r := DefaultPasswordPolicy().Check(context.Background(), "short")
+	err := r.Err()
+
+	if !errors.Is(err, ErrPasswordTooShort) || !herrors.IsCode(err, herrors.ValidationFailed) {
+		t.Fatalf("err = %v", err)
+	}
+	var e *herrors.Error
+	if !errors.As(err, &e) {
+		t.Fatalf("err = %T", err)
+	}
+	violations, ok := e.GetMeta("violations").([]Violation)
+	if !ok || len(violations) != len(r.Violations) || violations[0].Rule != RuleMinLength {
+		t.Fatalf("violations meta = %v", e.GetMeta("violations"))
+	}
+	if _, ok := e.GetMeta("strength").(Strength); !ok {
+		t.Fatalf("strength meta = %v", e.GetMeta("strength"))
+	}
+}
+
+func TestPasswordPolicy_Breach(t *testing.T) {
+	ctx := context.Background()
+	calls := 0
+	counts := map[string]int{"Summer2024x": 3, "Winter2024x": 0}
+	p := DefaultPasswordPolicy()
+	p.Breach = BreachCheckerFunc(func(_ context.Context, password string) (int, error) {
+		calls++
+		n, ok := counts[password]
+		if !ok {
+			return 0, errors.New("hibp unavailable")
+		}
+		return n, nil
+	})
+
+	r := p.Check(ctx, "Summer2024x")
+	if !r.BreachChecked || r.BreachCount != 3 || !slices.Equal(rules(r), []string{RuleBreached}) {
+		t.Fatalf("breached = %+v", r)
+	}
+	if !errors.Is(r.Err(), ErrPasswordBreached) {
+		t.Fatalf("err = %v", r.Err())
+	}
+
+	p.MaxBreachCount = 3
+	if r := p.Check(ctx, "Summer2024x"); !r.OK() {
+		t.Fatalf("within max breach count = %+v", r)
+	}
+	if r := p.Check(ctx, "Winter2024x"); !r.OK() || !r.BreachChecked {
+		t.Fatalf("clean = %+v", r)
+	}
+
+	// 泄露检查失败时放行并记录原因
+	r = p.Check(ctx, "Autumn2024x")
+	if !r.OK() || r.BreachChecked || r.BreachErr == nil {
+		t.Fatalf("checker failure = %+v", r)
+	}
+
+	// Validate 与空密码不调用泄露检查
+	calls = 0
+	_ = p.Validate("Summer2024x")
+	_ = p.Check(ctx, "")
+	if calls != 0 {
+		t.Fatalf("breach checker called %d times", calls)
+	}
+}
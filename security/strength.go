@@ -0,0 +1,236 @@
+package security
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Strength 密码强度估算结果（zxcvbn 风格）
+type Strength struct {
+	Score    int      `json:"score"`              // 0-4，对应猜测次数 <1e3、<1e6、<1e8、<1e10、>=1e10
+	Entropy  float64  `json:"entropy"`            // 估算熵（bits）
+	Guesses  float64  `json:"guesses_log10"`      // 估算猜测次数（log10）
+	Feedback []string `json:"feedback,omitempty"` // 改进建议
+}
+
+// 常见弱密码片段（按常见程度排序，节选）
+var commonPasswords = []string{
+	"password", "123456", "qwerty", "letmein", "welcome", "admin", "iloveyou",
+	"monkey", "dragon", "football", "baseball", "master", "login", "princess",
+	"sunshine", "shadow", "superman", "trustno1", "starwars", "whatever",
+	"abc123", "111111", "000000", "654321", "666666", "888888",
+	"secret", "hello", "freedom", "computer", "michael", "charlie", "jordan",
+	"hunter", "killer", "batman", "access", "flower", "summer", "winter",
+	"spring", "autumn", "changeme", "default", "root", "test", "guest", "user",
+}
+
+// 键盘行与字母表，用于识别顺序/相邻按键
+var sequences = []string{
+	"abcdefghijklmnopqrstuvwxyz",
+	"01234567890",
+	"qwertyuiop",
+	"asdfghjkl",
+	"zxcvbnm",
+	"1qaz2wsx3edc4rfv5tgb6yhn7ujm8ik9ol0p",
+}
+
+var leet = map[rune]rune{
+	'4': 'a', '@': 'a', '3': 'e', '1': 'i', '!': 'i', '0': 'o', '$': 's', '5': 's', '7': 't', '+': 't',
+}
+
+// EstimateStrength 估算密码强度。
+// 字典词（常见密码、deny 列表、userInputs 如用户名/邮箱）、重复字符与顺序/键盘序列
+// 只计少量熵，其余字符按出现的字符类别计熵。
+func EstimateStrength(password string, userInputs ...string) Strength {
+	return estimateStrength(password, nil, userInputs)
+}
+
+func estimateStrength(password string, deny, userInputs []string) Strength {
+	runes := []rune(password)
+	if len(runes) == 0 {
+		return Strength{Feedback: []string{"password is empty"}}
+	}
+
+	lower := make([]rune, len(runes))
+	normalized := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+		normalized[i] = lower[i]
+		if l, ok := leet[r]; ok {
+			normalized[i] = l
+		}
+	}
+
+	covered := make([]bool, len(runes))
+	var entropy float64
+	var feedback []string
+
+	matchWords := func(words []string, bits float64, hint string) {
+		matched := false
+		for _, w := range words {
+			w = strings.ToLower(w)
+			wr := []rune(w)
+			if len(wr) < 3 {
+				continue
+			}
+			for i := 0; i+len(wr) <= len(normalized); i++ {
+				if anyCovered(covered[i : i+len(wr)]) {
+					continue
+				}
+				if string(lower[i:i+len(wr)]) != w && string(normalized[i:i+len(wr)]) != w {
+					continue
+				}
+				for j := i; j < i+len(wr); j++ {
+					covered[j] = true
+				}
+				entropy += bits
+				matched = true
+			}
+		}
+		if matched {
+			feedback = append(feedback, hint)
+		}
+	}
+	matchWords(userInputs, 2, "avoid using personal information")
+	matchWords(deny, 2, "avoid banned words")
+	matchWords(commonPasswords, math.Log2(float64(len(commonPasswords)))+4, "avoid common passwords")
+
+	// 重复与顺序只在连续 3 个及以上字符时计入，避免普通单词中的偶然相邻被误判
+	charset := charsetSize(runes)
+	var repeats, seqs, repeatRun, seqRun int
+	for i := range runes {
+		if covered[i] {
+			repeatRun, seqRun = 0, 0
+			continue
+		}
+		r, q := 0, 0
+		if i > 0 && !covered[i-1] {
+			if lower[i] == lower[i-1] {
+				r = repeatRun + 1
+			} else if isSequential(lower[i-1], lower[i]) {
+				q = seqRun + 1
+			}
+		}
+		repeatRun, seqRun = r, q
+		switch {
+		case repeatRun >= 2:
+			entropy++
+			repeats++
+		case seqRun >= 2:
+			entropy += 2
+			seqs++
+		default:
+			entropy += math.Log2(float64(charset))
+		}
+	}
+	if repeats > 0 {
+		feedback = append(feedback, "avoid repeated characters")
+	}
+	if seqs > 0 {
+		feedback = append(feedback, "avoid sequences and keyboard patterns")
+	}
+
+	guesses := entropy * math.Log10(2)
+	s := Strength{Entropy: entropy, Guesses: guesses, Feedback: feedback}
+	switch {
+	case guesses < 3:
+		s.Score = 0
+	case guesses < 6:
+		s.Score = 1
+	case guesses < 8:
+		s.Score = 2
+	case guesses < 10:
+		s.Score = 3
+	default:
+		s.Score = 4
+	}
+	if s.Score < 3 && len(runes) < 12 {
+		s.Feedback = append(s.Feedback, "use a longer password")
+	}
+	return s
+}
+
+func anyCovered(b []bool) bool {
+	for _, v := range b {
+		if v {
+			return true
+		}
+	}
+	return false
+}
+
+// isSequential 判断 a、b 是否为字母表/数字/键盘行上的相邻字符（正向或反向）
+func isSequential(a, b rune) bool {
+	for _, seq := range sequences {
+		i := strings.IndexRune(seq, a)
+		if i < 0 {
+			continue
+		}
+		if i+1 < len(seq) && rune(seq[i+1]) == b || i > 0 && rune(seq[i-1]) == b {
+			return true
+		}
+	}
+	return false
+}
+
+// charClasses 返回出现的字符类别数（大写、小写、数字、符号、其他）
+func charClasses(s string) int {
+	var upper, lower, digit, special, other bool
+	for _, c := range s {
+		switch {
+		case unicode.IsUpper(c):
+			upper = true
+		case unicode.IsLower(c):
+			lower = true
+		case unicode.IsDigit(c):
+			digit = true
+		case unicode.IsPunct(c) || unicode.IsSymbol(c) || unicode.IsSpace(c):
+			special = true
+		default:
+			other = true
+		}
+	}
+	n := 0
+	for _, b := range []bool{upper, lower, digit, special, other} {
+		if b {
+			n++
+		}
+	}
+	return n
+}
+
+func charsetSize(runes []rune) int {
+	var upper, lower, digit, special, other bool
+	for _, c := range runes {
+		switch {
+		case c < 128 && unicode.IsUpper(c):
+			upper = true
+		case c < 128 && unicode.IsLower(c):
+			lower = true
+		case c < 128 && unicode.IsDigit(c):
+			digit = true
+		case c < 128:
+			special = true
+		default:
+			other = true
+		}
+	}
+	n := 0
+	if upper {
+		n += 26
+	}
+	if lower {
+		n += 26
+	}
+	if digit {
+		n += 10
+	}
+	if special {
+		n += 33
+	}
+	if other {
+		n += 100
+	}
+	return max(n, 2)
+}
@@ -0,0 +1,49 @@
+package security
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEstimateStrength(t *testing.T) {
+	tests := []struct {
+		password string
+		maxScore int
+		feedback string
+	}{
+		{"", 0, "password is empty"},
+		{"password", 0, "avoid common passwords"},
+		{"P@ssw0rd", 0, "avoid common passwords"}, // leet 还原后仍是常见密码
+		{"aaaaaaaaaaaa", 1, "avoid repeated characters"},
+		{"abcdefgh", 2, "avoid sequences and keyboard patterns"},
+		{"qwertyuiop", 2, "avoid sequences and keyboard patterns"},
+	}
+	for _, tt := range tests {
+		s := EstimateStrength(tt.password)
+		if s.Score > tt.maxScore || !slices.Contains(s.Feedback, tt.feedback) {
+			t.Errorf("%q: score = %d, feedback = %v; want score <= %d with %q", tt.password, s.Score, s.Feedback, tt.maxScore, tt.feedback)
+		}
+	}
+
+	for _, p := range []string{"x7#Kq!v9Lm2$", "correct-horse-battery-staple-9"} {
+		if s := EstimateStrength(p); s.Score != 4 || len(s.Feedback) != 0 {
+			t.Errorf("%q: strength = %+v, want 4 without feedback", p, s)
+		}
+	}
+}
+
+func TestEstimateStrength_UserInputs(t *testing.T) {
+	plain := EstimateStrength("alice2024!")
+	personal := EstimateStrength("alice2024!", "alice", "al")
+
+	if personal.Entropy >= plain.Entropy || personal.Score >= plain.Score {
+		t.Fatalf("personal = %+v, plain = %+v", personal, plain)
+	}
+	if !slices.Contains(personal.Feedback, "avoid using personal information") {
+		t.Fatalf("feedback = %v", personal.Feedback)
+	}
+	// 少于 3 个字符的输入不参与匹配
+	if EstimateStrength("alice2024!", "al").Entropy != plain.Entropy {
+		t.Fatal("short user input reduced entropy")
+	}
+}
@@ -0,0 +1,198 @@
+package security
+
+import (
+	"context"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/mildsunup/higo/logger"
+	"github.com/mildsunup/higo/middleware"
+	"github.com/mildsunup/higo/resilience"
+)
+
+// TokenBucketResult 一次取令牌的结果
+type TokenBucketResult struct {
+	Allowed    bool          // 是否放行
+	Remaining  float64       // 剩余令牌数（Redis 模式下取整）
+	RetryAfter time.Duration // 被拒绝时建议的重试间隔；永远无法满足（速率为 0 或 n 超过容量）时为 -1
+	Fallback   bool          // 是否由本地桶判定（Redis 不可用）
+}
+
+// TokenBucket 按 key 分桶的令牌桶限流器
+//
+// 默认每个 key 一个本地 resilience.TokenBucket；通过 WithRedisStore 改用 middleware.RedisRateLimiter（GCRA）
+// 在 Redis 中保存状态，限额在重启后保留并在多实例间共享。Redis 不可用时降级到本地桶，恢复后自动切回。
+// 速率为 0（不补充）的桶只支持本地模式。
+type TokenBucket struct {
+	rate  float64
+	burst int
+	limit middleware.RateLimit
+
+	redis  *middleware.RedisRateLimiter
+	ttl    time.Duration
+	logger logger.Logger
+
+	mu        sync.Mutex
+	local     map[string]*resilience.TokenBucket
+	lastSweep time.Time
+
+	degraded atomic.Bool
+}
+
+// TokenBucketOption 令牌桶选项
+type TokenBucketOption func(*TokenBucket)
+
+// WithRedisStore 使用 Redis 保存令牌桶状态，prefix 默认为 "ratelimit:bucket:"
+func WithRedisStore(client redis.Cmdable, prefix string) TokenBucketOption {
+	return func(tb *TokenBucket) {
+		if prefix == "" {
+			prefix = "ratelimit:bucket:"
+		}
+		tb.redis = middleware.NewRedisRateLimiter(client, middleware.RedisRateLimiterConfig{
+			Algorithm: middleware.AlgorithmGCRA,
+			Prefix:    prefix,
+		})
+	}
+}
+
+// WithBucketTTL 设置本地桶的清理周期，默认为补满桶所需时间的两倍（至少 1 分钟）；
+// Redis 中的状态在补满时自动过期
+func WithBucketTTL(ttl time.Duration) TokenBucketOption {
+	return func(tb *TokenBucket) { tb.ttl = ttl }
+}
+
+// WithBucketLogger 设置日志（记录降级与恢复）
+func WithBucketLogger(l logger.Logger) TokenBucketOption {
+	return func(tb *TokenBucket) { tb.logger = l }
+}
+
+// NewTokenBucket 创建令牌桶，rate 为每秒补充令牌数，burst 为桶容量
+func NewTokenBucket(rate float64, burst int, opts ...TokenBucketOption) *TokenBucket {
+	tb := &TokenBucket{
+		rate:   rate,
+		burst:  burst,
+		logger: logger.Nop(),
+		local:  make(map[string]*resilience.TokenBucket),
+	}
+	for _, opt := range opts {
+		opt(tb)
+	}
+	if tb.ttl <= 0 {
+		tb.ttl = time.Minute
+		if rate > 0 {
+			tb.ttl = max(tb.ttl, 2*time.Duration(float64(burst)/rate*float64(time.Second)))
+		}
+	}
+	if rate > 0 {
+		// 以整数次/周期表示速率：rate=10 为 10 次/秒，rate=0.5 为 1 次/2 秒
+		n := max(1, int(math.Ceil(rate)))
+		tb.limit = middleware.RateLimit{
+			Limit:  n,
+			Period: time.Duration(float64(n) / rate * float64(time.Second)),
+			Burst:  burst,
+		}
+	}
+	return tb
+}
+
+// Allow 从全局桶取 1 个令牌
+func (tb *TokenBucket) Allow() bool {
+	return tb.AllowN(1)
+}
+
+// AllowN 从全局桶取 n 个令牌
+func (tb *TokenBucket) AllowN(n int) bool {
+	return tb.Take(context.Background(), "", n).Allowed
+}
+
+// AllowKey 从 key 对应的桶取 1 个令牌（如按用户限额）
+func (tb *TokenBucket) AllowKey(ctx context.Context, key string) bool {
+	return tb.Take(ctx, key, 1).Allowed
+}
+
+// Take 从 key 对应的桶取 n 个令牌
+func (tb *TokenBucket) Take(ctx context.Context, key string, n int) TokenBucketResult {
+	if n > tb.burst {
+		return TokenBucketResult{Remaining: tb.Tokens(ctx, key), RetryAfter: -1}
+	}
+	if tb.redis == nil || tb.rate <= 0 {
+		return tb.takeLocal(key, n)
+	}
+
+	q, allowed, err := tb.redis.AllowN(ctx, key, tb.limit, n)
+	if err != nil {
+		if tb.degraded.CompareAndSwap(false, true) {
+			tb.logger.Warn(ctx, "token bucket redis unavailable, falling back to local bucket",
+				logger.String("key", key), logger.Err(err))
+		}
+		r := tb.takeLocal(key, n)
+		r.Fallback = true
+		return r
+	}
+	if tb.degraded.CompareAndSwap(true, false) {
+		tb.logger.Info(ctx, "token bucket redis recovered")
+	}
+	return TokenBucketResult{Allowed: allowed, Remaining: float64(q.Remaining), RetryAfter: q.RetryAfter}
+}
+
+// Tokens 返回 key 对应桶的当前令牌数（用于监控），不消耗令牌
+func (tb *TokenBucket) Tokens(ctx context.Context, key string) float64 {
+	return tb.Take(ctx, key, 0).Remaining
+}
+
+// Degraded 是否正处于本地降级状态
+func (tb *TokenBucket) Degraded() bool {
+	return tb.degraded.Load()
+}
+
+// Reset 清除 key 对应的桶状态（本地与 Redis）
+func (tb *TokenBucket) Reset(ctx context.Context, key string) error {
+	tb.mu.Lock()
+	delete(tb.local, key)
+	tb.mu.Unlock()
+	if tb.redis == nil {
+		return nil
+	}
+	return tb.redis.Reset(ctx, key)
+}
+
+func (tb *TokenBucket) takeLocal(key string, n int) TokenBucketResult {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	now := time.Now()
+	tb.sweep(now)
+
+	b, ok := tb.local[key]
+	if !ok {
+		b = resilience.NewTokenBucket(tb.rate, tb.burst)
+		tb.local[key] = b
+	}
+	if b.AllowN(n) {
+		return TokenBucketResult{Allowed: true, Remaining: b.Tokens()}
+	}
+
+	tokens := b.Tokens()
+	res := TokenBucketResult{Remaining: tokens, RetryAfter: -1}
+	if tb.rate > 0 {
+		res.RetryAfter = time.Duration((float64(n) - tokens) / tb.rate * float64(time.Second))
+	}
+	return res
+}
+
+// sweep 清理已补满的本地桶（补满后与新建桶等价），避免按 key 限额时内存无限增长
+func (tb *TokenBucket) sweep(now time.Time) {
+	if now.Sub(tb.lastSweep) < tb.ttl {
+		return
+	}
+	tb.lastSweep = now
+	for k, b := range tb.local {
+		if b.Tokens() >= float64(tb.burst) {
+			delete(tb.local, k)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package security
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestTokenBucket_RedisRefill(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	t0 := time.Unix(1_700_000_000, 0)
+	mr.SetTime(t0)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+
+	tb := NewTokenBucket(2, 3, WithRedisStore(rdb, "quota:"))
+	for want := 2.0; want >= 0; want-- {
+		res := tb.Take(ctx, "u1", 1)
+		if !res.Allowed || res.Remaining != want || res.Fallback {
+			t.Fatalf("take = %+v, want remaining %v", res, want)
+		}
+	}
+	if res := tb.Take(ctx, "u1", 1); res.Allowed || res.RetryAfter != 500*time.Millisecond {
+		t.Fatalf("exhausted take = %+v", res)
+	}
+	if !mr.Exists("quota:u1") {
+		t.Fatal("bucket state not stored under prefix")
+	}
+
+	// 每秒补充 2 个，500ms 后恢复 1 个；查询不消耗令牌
+	mr.SetTime(t0.Add(500 * time.Millisecond))
+	if n := tb.Tokens(ctx, "u1"); n != 1 {
+		t.Fatalf("tokens after 500ms = %v", n)
+	}
+	if res := tb.Take(ctx, "u1", 1); !res.Allowed {
+		t.Fatalf("take after refill = %+v", res)
+	}
+
+	// 补充不超过容量
+	mr.SetTime(t0.Add(time.Minute))
+	if n := tb.Tokens(ctx, "u1"); n != 3 {
+		t.Fatalf("tokens after long idle = %v", n)
+	}
+	if res := tb.Take(ctx, "u1", 2); !res.Allowed || res.Remaining != 1 {
+		t.Fatalf("take 2 = %+v", res)
+	}
+	if res := tb.Take(ctx, "u1", 4); res.Allowed || res.RetryAfter != -1 {
+		t.Fatalf("take above burst = %+v", res)
+	}
+
+	// 其他 key 独立计数
+	if n := tb.Tokens(ctx, "u2"); n != 3 {
+		t.Fatalf("tokens of fresh key = %v", n)
+	}
+	if err := tb.Reset(ctx, "u1"); err != nil {
+		t.Fatal(err)
+	}
+	if n := tb.Tokens(ctx, "u1"); n != 3 {
+		t.Fatalf("tokens after reset = %v", n)
+	}
+}
+
+func TestTokenBucket_LocalFallback(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	defer rdb.Close()
+
+	tb := NewTokenBucket(0.01, 2, WithRedisStore(rdb, ""))
+	if res := tb.Take(ctx, "u1", 1); !res.Allowed || res.Fallback {
+		t.Fatalf("redis take = %+v", res)
+	}
+
+	mr.Close()
+	for range 2 {
+		if res := tb.Take(ctx, "u1", 1); !res.Allowed || !res.Fallback {
+			t.Fatalf("fallback take = %+v", res)
+		}
+	}
+	if !tb.Degraded() {
+		t.Fatal("not degraded while redis is down")
+	}
+	// 本地桶独立限额
+	if res := tb.Take(ctx, "u1", 1); res.Allowed || !res.Fallback || res.RetryAfter <= 0 {
+		t.Fatalf("local exhausted take = %+v", res)
+	}
+
+	if err := mr.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	if res := tb.Take(ctx, "u1", 1); res.Fallback {
+		t.Fatalf("take after recovery = %+v", res)
+	}
+	if tb.Degraded() {
+		t.Fatal("still degraded after recovery")
+	}
+}
+
+func TestTokenBucket_Local(t *testing.T) {
+	ctx := context.Background()
+
+	fixed := NewTokenBucket(0, 2)
+	if !fixed.Allow() || !fixed.Allow() {
+		t.Fatal("burst not available")
+	}
+	if res := fixed.Take(ctx, "", 1); res.Allowed || res.RetryAfter != -1 {
+		t.Fatalf("zero-rate take = %+v", res)
+	}
+
+	tb := NewTokenBucket(100, 1)
+	if !tb.AllowKey(ctx, "a") || !tb.AllowKey(ctx, "b") {
+		t.Fatal("keys share a bucket")
+	}
+	res := tb.Take(ctx, "a", 1)
+	if res.Allowed || res.RetryAfter <= 0 || res.RetryAfter > 10*time.Millisecond {
+		t.Fatalf("exhausted take = %+v", res)
+	}
+	time.Sleep(res.RetryAfter + 5*time.Millisecond)
+	if !tb.AllowKey(ctx, "a") {
+		t.Fatal("bucket not refilled after RetryAfter")
+	}
+}
@@ -0,0 +1,23 @@
+// Package sequence 提供分布式业务编号生成（如订单号 20240101-000123）。
+//
+// 核心功能：
+//   - 号段租用：从 Store（RedisStore/MemoryStore）原子租用连续序号，在本地逐个发放，WithSegment 控制号段大小
+//   - 周期重置：默认按天（WithPeriod、WithLocation），存储键为 name:周期
+//   - 近无空号：号段为 1 时每个序号都经过存储，仅取号后业务失败产生空号；更大的号段以少量空号换取吞吐
+//   - 崩溃恢复：WithLedger 在号段使用前写入数据库账本（GormLedger），Redis 数据丢失或从旧 RDB/AOF 回退后，
+//     与已确认区间重叠的号段被拒绝并从账本高水位重新播种；多实例乱序确认按区间判断，不会误拒
+//   - 降级发号：主存储不可用时由账本在事务内发号，恢复后主存储与降级区间重叠的号段被丢弃并重新播种，不会重复
+//
+// 使用示例：
+//
+//	ledger := sequence.NewGormLedger(db, "sequences")
+//	_ = ledger.AutoMigrate(ctx)
+//
+//	orders := sequence.New("order",
+//	    sequence.NewRedisStore(rdb, "seq:", 48*time.Hour),
+//	    sequence.WithLedger(ledger),
+//	    sequence.WithSegment(20),
+//	    sequence.WithLocation(shanghai))
+//
+//	no, err := orders.Next(ctx) // "20240101-000001"
+package sequence
@@ -0,0 +1,163 @@
+package sequence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SequenceRow 账本表结构
+type SequenceRow struct {
+	Name      string `gorm:"primaryKey;size:191"`
+	Value     int64  `gorm:"not null;default:0"` // 已确认的主存储高水位
+	Fallback  int64  `gorm:"not null;default:0"` // 降级发号高水位
+	Floor     int64  `gorm:"not null;default:0"` // 已清理的确认区间的最大终点
+	UpdatedAt time.Time
+}
+
+// SequenceRangeRow 已确认区间表结构（保留 rangeRetention，用于判断乱序确认与主存储回退）
+type SequenceRangeRow struct {
+	Name       string `gorm:"primaryKey;size:191"`
+	RangeStart int64  `gorm:"primaryKey;autoIncrement:false"`
+	RangeEnd   int64  `gorm:"not null"`
+	CreatedAt  time.Time
+}
+
+// GormLedger 基于数据库的发号账本，兼容 MySQL、PostgreSQL 与 SQLite
+type GormLedger struct {
+	db     *gorm.DB
+	table  string
+	ranges string
+	now    func() time.Time
+}
+
+var _ Ledger = (*GormLedger)(nil)
+
+// NewGormLedger 创建数据库账本，table 为空时使用 "sequences"，已确认区间存放在 table+"_ranges"
+func NewGormLedger(db *gorm.DB, table string) *GormLedger {
+	if table == "" {
+		table = "sequences"
+	}
+	return &GormLedger{db: db, table: table, ranges: table + "_ranges", now: time.Now}
+}
+
+// AutoMigrate 创建账本表
+func (l *GormLedger) AutoMigrate(ctx context.Context) error {
+	db := l.db.WithContext(ctx)
+	if err := db.Table(l.table).AutoMigrate(&SequenceRow{}); err != nil {
+		return err
+	}
+	return db.Table(l.ranges).AutoMigrate(&SequenceRangeRow{})
+}
+
+// ensure 确保键对应的行存在
+func (l *GormLedger) ensure(tx *gorm.DB, key string) error {
+	return tx.Table(l.table).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&SequenceRow{Name: key, UpdatedAt: time.Now()}).Error
+}
+
+// Lease 实现 Ledger（降级发号），在事务内从已确认与降级高水位的较大值之后租用
+func (l *GormLedger) Lease(ctx context.Context, key string, size int64) (Range, error) {
+	var end int64
+	err := l.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := l.ensure(tx, key); err != nil {
+			return err
+		}
+		err := tx.Table(l.table).Where("name = ?", key).Updates(map[string]any{
+			"fallback":   gorm.Expr("CASE WHEN value > fallback THEN value ELSE fallback END + ?", size),
+			"updated_at": time.Now(),
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Table(l.table).Where("name = ?", key).Select("fallback").Scan(&end).Error
+	})
+	if err != nil {
+		return Range{}, fmt.Errorf("sequence: ledger lease: %w", err)
+	}
+	return Range{Start: end - size + 1, End: end}, nil
+}
+
+// Current 实现 Ledger
+func (l *GormLedger) Current(ctx context.Context, key string) (int64, error) {
+	var row SequenceRow
+	err := l.db.WithContext(ctx).Table(l.table).Where("name = ?", key).Take(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("sequence: ledger current: %w", err)
+	}
+	return max(row.Value, row.Fallback), nil
+}
+
+// Commit 实现 Ledger：在事务内锁定账本行，区间起点须高于降级高水位与 floor，且不与保留期内已确认的区间重叠
+func (l *GormLedger) Commit(ctx context.Context, key string, r Range) (bool, int64, error) {
+	var (
+		ok   bool
+		high int64
+	)
+	err := l.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := l.ensure(tx, key); err != nil {
+			return err
+		}
+		var row SequenceRow
+		err := tx.Table(l.table).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("name = ?", key).Take(&row).Error
+		if err != nil {
+			return err
+		}
+		high = max(row.Value, row.Fallback)
+
+		// 超过保留期的区间并入 floor
+		now := l.now()
+		cutoff := now.Add(-rangeRetention)
+		var expired *int64
+		err = tx.Table(l.ranges).Where("name = ? AND created_at < ?", key, cutoff).
+			Select("MAX(range_end)").Scan(&expired).Error
+		if err != nil {
+			return err
+		}
+		if expired != nil {
+			if err := tx.Table(l.ranges).Where("name = ? AND created_at < ?", key, cutoff).
+				Delete(&SequenceRangeRow{}).Error; err != nil {
+				return err
+			}
+			row.Floor = max(row.Floor, *expired)
+		}
+
+		if r.Start > max(row.Fallback, row.Floor) {
+			var overlaps int64
+			err := tx.Table(l.ranges).Where("name = ? AND range_start <= ? AND range_end >= ?", key, r.End, r.Start).
+				Count(&overlaps).Error
+			if err != nil {
+				return err
+			}
+			if overlaps == 0 {
+				ok = true
+				row.Value = max(row.Value, r.End)
+				high = max(row.Value, row.Fallback)
+				err := tx.Table(l.ranges).Create(&SequenceRangeRow{
+					Name: key, RangeStart: r.Start, RangeEnd: r.End, CreatedAt: now,
+				}).Error
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return tx.Table(l.table).Where("name = ?", key).Updates(map[string]any{
+			"value":      row.Value,
+			"floor":      row.Floor,
+			"updated_at": now,
+		}).Error
+	})
+	if err != nil {
+		return false, 0, fmt.Errorf("sequence: ledger commit: %w", err)
+	}
+	return ok, high, nil
+}
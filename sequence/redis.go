@@ -0,0 +1,75 @@
+package sequence
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// 租用号段：键不存在返回 -1，否则 INCRBY 并续期
+	leaseScript = redis.NewScript(`
+		if redis.call("exists", KEYS[1]) == 0 then
+			return -1
+		end
+		local v = redis.call("incrby", KEYS[1], ARGV[1])
+		if tonumber(ARGV[2]) > 0 then
+			redis.call("pexpire", KEYS[1], ARGV[2])
+		end
+		return v
+	`)
+
+	// 播种：当前值小于 floor（或不存在）时设为 floor
+	seedScript = redis.NewScript(`
+		local cur = tonumber(redis.call("get", KEYS[1])) or -1
+		if cur < tonumber(ARGV[1]) then
+			redis.call("set", KEYS[1], ARGV[1])
+		end
+		if tonumber(ARGV[2]) > 0 then
+			redis.call("pexpire", KEYS[1], ARGV[2])
+		end
+		return 1
+	`)
+)
+
+// RedisStore 基于 Redis 的号段存储
+type RedisStore struct {
+	client redis.Cmdable
+	prefix string
+	ttl    time.Duration
+}
+
+var (
+	_ Store  = (*RedisStore)(nil)
+	_ Seeder = (*RedisStore)(nil)
+)
+
+// NewRedisStore 创建 Redis 号段存储，ttl 为键的过期时间（按天重置的序号建议 48 小时，0 表示不过期）
+func NewRedisStore(client redis.Cmdable, prefix string, ttl time.Duration) *RedisStore {
+	if prefix == "" {
+		prefix = "seq:"
+	}
+	return &RedisStore{client: client, prefix: prefix, ttl: ttl}
+}
+
+// Lease 实现 Store
+func (s *RedisStore) Lease(ctx context.Context, key string, size int64) (Range, error) {
+	v, err := leaseScript.Run(ctx, s.client, []string{s.prefix + key}, size, s.ttl.Milliseconds()).Int64()
+	if err != nil {
+		return Range{}, fmt.Errorf("sequence: redis lease: %w", err)
+	}
+	if v < 0 {
+		return Range{}, ErrNotSeeded
+	}
+	return Range{Start: v - size + 1, End: v}, nil
+}
+
+// Seed 实现 Seeder
+func (s *RedisStore) Seed(ctx context.Context, key string, floor int64) error {
+	if err := seedScript.Run(ctx, s.client, []string{s.prefix + key}, floor, s.ttl.Milliseconds()).Err(); err != nil {
+		return fmt.Errorf("sequence: redis seed: %w", err)
+	}
+	return nil
+}
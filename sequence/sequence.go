@@ -0,0 +1,211 @@
+package sequence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mildsunup/higo/logger"
+)
+
+// ErrConflict 主存储多次租用到与降级发号重叠的区间
+var ErrConflict = errors.New("sequence: lease conflicts with ledger")
+
+// maxAttempts 单次取号时主存储的最大重试次数（播种、冲突后重新租用）
+const maxAttempts = 3
+
+// FormatFunc 将周期与序号格式化为业务编号
+type FormatFunc func(period string, n int64) string
+
+// config 生成器配置
+type config struct {
+	segment int64
+	period  string
+	loc     *time.Location
+	format  FormatFunc
+	ledger  Ledger
+	log     logger.Logger
+}
+
+// Option 生成器选项
+type Option func(*config)
+
+// WithSegment 设置每次租用的号段大小（默认 1）。
+// 1 时每个序号都经过存储，仅在取号后业务失败时产生空号；更大的号段减少存储访问，进程退出时未用完的部分成为空号
+func WithSegment(n int64) Option {
+	return func(c *config) {
+		if n > 0 {
+			c.segment = n
+		}
+	}
+}
+
+// WithPeriod 设置重置周期的时间布局（默认 "20060102" 按天重置），空字符串表示从不重置
+func WithPeriod(layout string) Option {
+	return func(c *config) { c.period = layout }
+}
+
+// WithLocation 设置计算周期的时区，默认 time.Local
+func WithLocation(loc *time.Location) Option {
+	return func(c *config) { c.loc = loc }
+}
+
+// WithFormat 设置编号格式，默认 "20240101-000123"（周期为空时仅输出 6 位序号）
+func WithFormat(fn FormatFunc) Option {
+	return func(c *config) { c.format = fn }
+}
+
+// WithLedger 设置持久化账本：号段使用前写入账本确认，主存储数据丢失后从账本恢复，主存储不可用时由账本降级发号
+func WithLedger(l Ledger) Option {
+	return func(c *config) { c.ledger = l }
+}
+
+// WithLogger 设置日志，用于记录降级与账本写入失败
+func WithLogger(l logger.Logger) Option {
+	return func(c *config) { c.log = l }
+}
+
+func defaultFormat(period string, n int64) string {
+	if period == "" {
+		return fmt.Sprintf("%06d", n)
+	}
+	return fmt.Sprintf("%s-%06d", period, n)
+}
+
+// Generator 业务编号生成器，并发安全。
+// 从号段存储批量租用序号并在本地逐个发放，同一周期内序号唯一且递增（多实例间交错）。
+type Generator struct {
+	name  string
+	store Store
+	cfg   config
+	now   func() time.Time
+
+	mu     sync.Mutex
+	period string
+	next   int64
+	end    int64
+}
+
+// New 创建生成器，name 为序列名（如 "order"），与周期共同组成存储键
+func New(name string, store Store, opts ...Option) *Generator {
+	cfg := config{
+		segment: 1,
+		period:  "20060102",
+		loc:     time.Local,
+		format:  defaultFormat,
+		log:     logger.Nop(),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Generator{name: name, store: store, cfg: cfg, now: time.Now}
+}
+
+// Next 返回下一个业务编号（如 "20240101-000123"）
+func (g *Generator) Next(ctx context.Context) (string, error) {
+	period, n, err := g.NextInt(ctx)
+	if err != nil {
+		return "", err
+	}
+	return g.cfg.format(period, n), nil
+}
+
+// NextInt 返回当前周期与周期内的下一个序号（从 1 开始）
+func (g *Generator) NextInt(ctx context.Context) (string, int64, error) {
+	period := ""
+	if g.cfg.period != "" {
+		period = g.now().In(g.cfg.loc).Format(g.cfg.period)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if period != g.period || g.next > g.end {
+		r, err := g.lease(ctx, g.key(period))
+		if err != nil {
+			return "", 0, err
+		}
+		g.period, g.next, g.end = period, r.Start, r.End
+	}
+	n := g.next
+	g.next++
+	return period, n, nil
+}
+
+func (g *Generator) key(period string) string {
+	if period == "" {
+		return g.name
+	}
+	return g.name + ":" + period
+}
+
+// lease 从主存储租用并经账本确认，主存储不可用时由账本降级发号
+func (g *Generator) lease(ctx context.Context, key string) (Range, error) {
+	size := g.cfg.segment
+	for range maxAttempts {
+		r, err := g.store.Lease(ctx, key, size)
+		if errors.Is(err, ErrNotSeeded) {
+			if err := g.seed(ctx, key, -1); err != nil {
+				return Range{}, err
+			}
+			continue
+		}
+		if err != nil {
+			return g.fallback(ctx, key, err)
+		}
+		if g.cfg.ledger == nil {
+			return r, nil
+		}
+
+		ok, high, err := g.cfg.ledger.Commit(ctx, key, r)
+		if err != nil {
+			// 账本不可用时仍使用主存储的号段，主存储数据丢失前不会重复
+			g.cfg.log.Warn(ctx, "sequence ledger commit failed",
+				logger.String("key", key), logger.Err(err))
+			return r, nil
+		}
+		if ok {
+			return r, nil
+		}
+		// 区间与降级发号重叠：主存储落后于账本，提升后重新租用
+		g.cfg.log.Warn(ctx, "sequence store behind ledger, reseeding",
+			logger.String("key", key), logger.Int64("high", high))
+		if err := g.seed(ctx, key, high); err != nil {
+			return Range{}, err
+		}
+	}
+	return Range{}, fmt.Errorf("%w: %s", ErrConflict, key)
+}
+
+// seed 将主存储提升到 floor；floor < 0 时从账本读取（无账本为 0）
+func (g *Generator) seed(ctx context.Context, key string, floor int64) error {
+	s, ok := g.store.(Seeder)
+	if !ok {
+		return fmt.Errorf("sequence: store %T returned ErrNotSeeded but is not a Seeder", g.store)
+	}
+	if floor < 0 {
+		floor = 0
+		if g.cfg.ledger != nil {
+			high, err := g.cfg.ledger.Current(ctx, key)
+			if err != nil {
+				return err
+			}
+			floor = high
+		}
+	}
+	return s.Seed(ctx, key, floor)
+}
+
+func (g *Generator) fallback(ctx context.Context, key string, cause error) (Range, error) {
+	if g.cfg.ledger == nil {
+		return Range{}, cause
+	}
+	g.cfg.log.Warn(ctx, "sequence store unavailable, leasing from ledger",
+		logger.String("key", key), logger.Err(cause))
+	r, err := g.cfg.ledger.Lease(ctx, key, g.cfg.segment)
+	if err != nil {
+		return Range{}, errors.Join(cause, err)
+	}
+	return r, nil
+}
@@ -0,0 +1,207 @@
+package sequence
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// flakyStore 可切换为不可用的号段存储
+type flakyStore struct {
+	*MemoryStore
+	down bool
+}
+
+func (s *flakyStore) Lease(ctx context.Context, key string, size int64) (Range, error) {
+	if s.down {
+		return Range{}, errors.New("connection refused")
+	}
+	return s.MemoryStore.Lease(ctx, key, size)
+}
+
+func newGen(store Store, opts ...Option) *Generator {
+	g := New("order", store, append([]Option{WithLocation(time.UTC)}, opts...)...)
+	g.now = func() time.Time { return time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC) }
+	return g
+}
+
+func TestGenerator_Next(t *testing.T) {
+	ctx := context.Background()
+	g := newGen(NewMemoryStore(), WithSegment(10))
+
+	var mu sync.Mutex
+	seen := map[string]bool{}
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Go(func() {
+			no, err := g.Next(ctx)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if seen[no] {
+				t.Errorf("duplicate %s", no)
+			}
+			seen[no] = true
+		})
+	}
+	wg.Wait()
+	if !seen["20240101-000001"] || !seen["20240101-000050"] {
+		t.Errorf("numbers not contiguous: %d issued", len(seen))
+	}
+
+	// 周期切换后从 1 开始
+	g.now = func() time.Time { return time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC) }
+	if no, _ := g.Next(ctx); no != "20240102-000001" {
+		t.Errorf("Next() after period change = %s", no)
+	}
+}
+
+func TestGenerator_LedgerRecovery(t *testing.T) {
+	ctx := context.Background()
+	store := &flakyStore{MemoryStore: NewMemoryStore()}
+	ledger := NewMemoryLedger()
+	g := newGen(store, WithLedger(ledger))
+
+	for range 3 {
+		_, _, _ = g.NextInt(ctx)
+	}
+
+	// Redis 数据丢失：从账本高水位恢复
+	store.Reset("order:20240101")
+	if _, n, _ := g.NextInt(ctx); n != 4 {
+		t.Errorf("after reset n = %d, want 4", n)
+	}
+
+	// Redis 不可用：账本降级发号
+	store.down = true
+	if _, n, err := g.NextInt(ctx); err != nil || n != 5 {
+		t.Errorf("fallback n = %d, %v, want 5", n, err)
+	}
+
+	// 恢复后与降级区间重叠的号段被丢弃
+	store.down = false
+	if _, n, _ := g.NextInt(ctx); n != 6 {
+		t.Errorf("after recovery n = %d, want 6", n)
+	}
+}
+
+func TestGenerator_Errors(t *testing.T) {
+	store := &flakyStore{MemoryStore: NewMemoryStore(), down: true}
+	if _, err := newGen(store).Next(context.Background()); err == nil {
+		t.Error("Next() without ledger should fail when store is down")
+	}
+}
+
+func TestGenerator_StoreRollback(t *testing.T) {
+	for name, ledger := range map[string]Ledger{
+		"memory": NewMemoryLedger(),
+		"gorm":   newGormLedger(t),
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			store := NewMemoryStore()
+			g := newGen(store, WithLedger(ledger), WithSegment(5))
+
+			for range 12 {
+				_, _, _ = g.NextInt(ctx)
+			}
+
+			// Redis 从旧快照恢复：键仍存在但值回退到 5
+			store.Reset("order:20240101")
+			_ = store.Seed(ctx, "order:20240101", 5)
+			g.next = g.end + 1 // 丢弃本地号段，强制重新租用
+			if _, n, err := g.NextInt(ctx); err != nil || n != 16 {
+				t.Fatalf("after rollback n = %d, %v, want 16", n, err)
+			}
+		})
+	}
+}
+
+func TestLedger_OutOfOrderCommit(t *testing.T) {
+	for name, ledger := range map[string]Ledger{
+		"memory": NewMemoryLedger(),
+		"gorm":   newGormLedger(t),
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			key := "order:20240101"
+
+			// 两个实例先后租用 [1,10]、[11,20]，后者先确认
+			if ok, _, err := ledger.Commit(ctx, key, Range{Start: 11, End: 20}); !ok || err != nil {
+				t.Fatalf("commit [11,20] = %v, %v", ok, err)
+			}
+			if ok, _, err := ledger.Commit(ctx, key, Range{Start: 1, End: 10}); !ok || err != nil {
+				t.Fatalf("out-of-order commit [1,10] = %v, %v", ok, err)
+			}
+			// 重复确认（主存储回退后再次发出）被拒绝
+			ok, high, err := ledger.Commit(ctx, key, Range{Start: 6, End: 15})
+			if ok || err != nil || high != 20 {
+				t.Fatalf("overlapping commit = %v, %d, %v", ok, high, err)
+			}
+		})
+	}
+}
+
+func TestLedger_RetentionFloor(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	ledger := NewMemoryLedger()
+	ledger.now = func() time.Time { return now }
+
+	_, _, _ = ledger.Commit(ctx, "k", Range{Start: 1, End: 10})
+	now = now.Add(2 * rangeRetention)
+	_, _, _ = ledger.Commit(ctx, "k", Range{Start: 21, End: 30})
+
+	// 清理后的区间无法逐个比对，起点不高于 floor 的区间一律拒绝
+	if ok, _, _ := ledger.Commit(ctx, "k", Range{Start: 5, End: 5}); ok {
+		t.Fatal("range below floor accepted")
+	}
+	if ok, _, _ := ledger.Commit(ctx, "k", Range{Start: 11, End: 20}); !ok {
+		t.Fatal("range above floor rejected")
+	}
+}
+
+func TestGormLedger_Fallback(t *testing.T) {
+	ctx := context.Background()
+	ledger := newGormLedger(t)
+
+	if ok, _, _ := ledger.Commit(ctx, "k", Range{Start: 1, End: 3}); !ok {
+		t.Fatal("commit rejected")
+	}
+	r, err := ledger.Lease(ctx, "k", 2)
+	if err != nil || r != (Range{Start: 4, End: 5}) {
+		t.Fatalf("Lease = %+v, %v", r, err)
+	}
+	if high, _ := ledger.Current(ctx, "k"); high != 5 {
+		t.Fatalf("Current = %d", high)
+	}
+	if ok, high, _ := ledger.Commit(ctx, "k", Range{Start: 5, End: 6}); ok || high != 5 {
+		t.Fatalf("commit overlapping fallback = %v, %d", ok, high)
+	}
+}
+
+func newGormLedger(t *testing.T) *GormLedger {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	ledger := NewGormLedger(db, "")
+	if err := ledger.AutoMigrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return ledger
+}
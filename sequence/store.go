@@ -0,0 +1,173 @@
+package sequence
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNotSeeded 号段存储中不存在该键，需先通过 Seed 设置起点（新周期首次发号或数据丢失后）
+var ErrNotSeeded = errors.New("sequence: key not seeded")
+
+// Range 租用的连续序号区间 [Start, End]
+type Range struct {
+	Start int64
+	End   int64
+}
+
+// Store 号段存储，原子地为 key 租用 size 个连续序号
+type Store interface {
+	Lease(ctx context.Context, key string, size int64) (Range, error)
+}
+
+// Seeder 需要播种的号段存储（如 Redis）：键不存在时 Lease 返回 ErrNotSeeded，
+// Seed 将键的当前值提升到至少 floor（已更大时不变）
+type Seeder interface {
+	Seed(ctx context.Context, key string, floor int64) error
+}
+
+// Ledger 持久化的发号账本（数据库），记录已确认的高水位：
+//   - Commit 在号段使用前确认主存储租用的区间；区间与降级发出的序号、已确认的区间重叠，
+//     或位于已清理的确认记录之下（主存储回退到旧快照）时返回 false 与当前高水位
+//   - Current 返回已确认与降级发号中的较大值，用于主存储数据丢失或回退后重新播种
+//   - Lease 在主存储不可用时降级发号，区间位于全部已确认与已降级序号之后
+type Ledger interface {
+	Store
+	Current(ctx context.Context, key string) (int64, error)
+	Commit(ctx context.Context, key string, r Range) (ok bool, high int64, err error)
+}
+
+// --- 内存实现 ---
+
+// MemoryStore 内存号段存储，语义与 RedisStore 一致（需播种），用于测试与单进程场景
+type MemoryStore struct {
+	mu     sync.Mutex
+	values map[string]int64
+}
+
+var (
+	_ Store  = (*MemoryStore)(nil)
+	_ Seeder = (*MemoryStore)(nil)
+)
+
+// NewMemoryStore 创建内存号段存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{values: map[string]int64{}}
+}
+
+// Lease 实现 Store
+func (s *MemoryStore) Lease(_ context.Context, key string, size int64) (Range, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.values[key]
+	if !ok {
+		return Range{}, ErrNotSeeded
+	}
+	s.values[key] = v + size
+	return Range{Start: v + 1, End: v + size}, nil
+}
+
+// Seed 实现 Seeder
+func (s *MemoryStore) Seed(_ context.Context, key string, floor int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if v, ok := s.values[key]; !ok || v < floor {
+		s.values[key] = floor
+	}
+	return nil
+}
+
+// Reset 删除键，模拟数据丢失
+func (s *MemoryStore) Reset(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+}
+
+// rangeRetention 已确认区间的保留时间。
+// 多实例并发租用时确认顺序可能与租用顺序不同，保留期内逐个区间判断重叠；
+// 超过保留期的区间并入 floor，之后起点不高于 floor 的区间一律拒绝（迟到的确认只产生空号）
+const rangeRetention = time.Minute
+
+// MemoryLedger 内存账本，语义与 GormLedger 一致，用于测试
+type MemoryLedger struct {
+	mu   sync.Mutex
+	rows map[string]*ledgerRow
+	now  func() time.Time
+}
+
+type ledgerRow struct {
+	value    int64 // 已确认的主存储高水位
+	fallback int64 // 降级发号高水位
+	floor    int64 // 已清理的确认区间的最大终点
+	ranges   []committedRange
+}
+
+type committedRange struct {
+	Range
+	at time.Time
+}
+
+var _ Ledger = (*MemoryLedger)(nil)
+
+// NewMemoryLedger 创建内存账本
+func NewMemoryLedger() *MemoryLedger {
+	return &MemoryLedger{rows: map[string]*ledgerRow{}, now: time.Now}
+}
+
+func (l *MemoryLedger) row(key string) *ledgerRow {
+	r, ok := l.rows[key]
+	if !ok {
+		r = &ledgerRow{}
+		l.rows[key] = r
+	}
+	return r
+}
+
+// Lease 实现 Ledger（降级发号）
+func (l *MemoryLedger) Lease(_ context.Context, key string, size int64) (Range, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	r := l.row(key)
+	r.fallback = max(r.value, r.fallback) + size
+	return Range{Start: r.fallback - size + 1, End: r.fallback}, nil
+}
+
+// Current 实现 Ledger
+func (l *MemoryLedger) Current(_ context.Context, key string) (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	r := l.row(key)
+	return max(r.value, r.fallback), nil
+}
+
+// Commit 实现 Ledger
+func (l *MemoryLedger) Commit(_ context.Context, key string, rg Range) (bool, int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	r := l.row(key)
+	now := l.now()
+
+	kept := r.ranges[:0]
+	for _, c := range r.ranges {
+		if now.Sub(c.at) > rangeRetention {
+			r.floor = max(r.floor, c.End)
+			continue
+		}
+		kept = append(kept, c)
+	}
+	r.ranges = kept
+
+	if rg.Start <= max(r.fallback, r.floor) {
+		return false, max(r.value, r.fallback), nil
+	}
+	for _, c := range r.ranges {
+		if c.Start <= rg.End && c.End >= rg.Start {
+			return false, max(r.value, r.fallback), nil
+		}
+	}
+	r.ranges = append(r.ranges, committedRange{Range: rg, at: now})
+	r.value = max(r.value, rg.End)
+	return true, r.value, nil
+}
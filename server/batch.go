@@ -0,0 +1,233 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/mildsunup/higo/errors"
+	mw "github.com/mildsunup/higo/middleware"
+	"github.com/mildsunup/higo/observability"
+	"github.com/mildsunup/higo/response"
+)
+
+// BatchConfig 批量接口配置
+type BatchConfig struct {
+	Concurrency int   // 并发数
+	MaxItems    int   // 单次最大条目数
+	MaxBodySize int64 // 请求体上限（字节），不大于 0 时使用默认值
+	FailFast    bool  // 任一条目失败后取消未开始的条目
+}
+
+// DefaultBatchConfig 默认配置
+func DefaultBatchConfig() BatchConfig {
+	return BatchConfig{Concurrency: 8, MaxItems: 100, MaxBodySize: 4 << 20}
+}
+
+// BatchOption 批量接口选项
+type BatchOption func(*BatchConfig)
+
+// WithBatchConcurrency 设置并发数
+func WithBatchConcurrency(n int) BatchOption {
+	return func(c *BatchConfig) { c.Concurrency = n }
+}
+
+// WithBatchMaxItems 设置单次最大条目数
+func WithBatchMaxItems(n int) BatchOption {
+	return func(c *BatchConfig) { c.MaxItems = n }
+}
+
+// WithBatchMaxBodySize 设置请求体上限（字节）
+func WithBatchMaxBodySize(n int64) BatchOption {
+	return func(c *BatchConfig) { c.MaxBodySize = n }
+}
+
+// WithBatchFailFast 任一条目失败后取消未开始的条目
+func WithBatchFailFast() BatchOption {
+	return func(c *BatchConfig) { c.FailFast = true }
+}
+
+// BatchItem 单条结果
+type BatchItem[R any] struct {
+	Index   int            `json:"index"`
+	Status  int            `json:"status"`
+	Code    int            `json:"code"`
+	Message string         `json:"message"`
+	Data    *R             `json:"data,omitempty"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// BatchResult 多状态信封：整体成功返回 200，存在失败条目返回 207
+type BatchResult[R any] struct {
+	Code      int            `json:"code"`
+	Message   string         `json:"message"`
+	Total     int            `json:"total"`
+	Succeeded int            `json:"succeeded"`
+	Failed    int            `json:"failed"`
+	Items     []BatchItem[R] `json:"items"`
+	RequestID string         `json:"request_id,omitempty"`
+	TraceID   string         `json:"trace_id,omitempty"`
+}
+
+// HTTPStatus 批量结果对应的 HTTP 状态码
+func (r BatchResult[R]) HTTPStatus() int {
+	if r.Failed > 0 {
+		return http.StatusMultiStatus
+	}
+	return http.StatusOK
+}
+
+// BatchFunc 处理单个条目
+type BatchFunc[Req, R any] func(ctx context.Context, item Req) (R, error)
+
+// RunBatch 以有限并发执行批量操作，返回逐项结果与聚合错误（无失败时为 nil）。
+// 单个条目 panic 时该条目以 500 失败，不影响其余条目
+func RunBatch[Req, R any](ctx context.Context, items []Req, fn BatchFunc[Req, R], opts ...BatchOption) (BatchResult[R], error) {
+	cfg := DefaultBatchConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	concurrency := max(cfg.Concurrency, 1)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	agg := errors.NewAggregate(len(items))
+	result := BatchResult[R]{Total: len(items), Items: make([]BatchItem[R], len(items))}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, item := range items {
+		sem <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-sem }()
+
+			var (
+				data R
+				err  = ctx.Err()
+			)
+			if err == nil {
+				data, err = runItem(ctx, i, item, fn)
+			}
+			if err != nil {
+				agg.Add(i, err)
+				if cfg.FailFast {
+					cancel()
+				}
+				result.Items[i] = failedItem[R](i, err)
+				return
+			}
+			result.Items[i] = BatchItem[R]{Index: i, Status: http.StatusOK, Message: "ok", Data: &data}
+		})
+	}
+	wg.Wait()
+
+	result.Failed = agg.Failed()
+	result.Succeeded = result.Total - result.Failed
+	switch {
+	case result.Failed == 0:
+		result.Message = "ok"
+	case result.Succeeded == 0:
+		result.Message = "all items failed"
+	default:
+		result.Message = "partial failure"
+	}
+	return result, agg.ErrOrNil()
+}
+
+// runItem 执行单个条目，panic 转换为错误
+func runItem[Req, R any](ctx context.Context, index int, item Req, fn BatchFunc[Req, R]) (data R, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("batch: item %d panicked: %v", index, rec)
+		}
+	}()
+	return fn(ctx, item)
+}
+
+func failedItem[R any](index int, err error) BatchItem[R] {
+	if errors.Is(err, context.Canceled) && errors.GetCode(err) == errors.Unknown {
+		err = errors.New(errors.Canceled, "canceled after an earlier item failed")
+	}
+	resp := errors.ToResponse(err)
+	status := errors.GetHTTPStatus(err)
+	var e *errors.Error
+	if status >= http.StatusInternalServerError && !errors.As(err, &e) {
+		resp.Message = http.StatusText(status) // 不向客户端暴露内部错误
+	}
+	return BatchItem[R]{Index: index, Status: status, Code: resp.Code, Message: resp.Message, Details: resp.Details}
+}
+
+// BatchHandler 创建批量接口：请求体为 JSON 数组或 {"items": [...]}
+// Gin 中使用 gin.WrapH(server.BatchHandler(fn))。
+//
+//	router.POST("/users:batchCreate", gin.WrapH(server.BatchHandler(
+//	    func(ctx context.Context, req CreateUserReq) (User, error) { return svc.Create(ctx, req) },
+//	    server.WithBatchConcurrency(4),
+//	)))
+func BatchHandler[Req, R any](fn BatchFunc[Req, R], opts ...BatchOption) http.Handler {
+	cfg := DefaultBatchConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		items, err := decodeBatch[Req](w, r, cfg)
+		if err != nil {
+			response.WriteError(w, r, err)
+			return
+		}
+
+		result, _ := RunBatch(r.Context(), items, fn, opts...)
+		ctx := r.Context()
+		result.RequestID = mw.GetRequestID(ctx)
+		if result.TraceID = mw.GetTraceID(ctx); result.TraceID == "" {
+			result.TraceID = observability.TraceID(ctx)
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(result.HTTPStatus())
+		_ = json.NewEncoder(w).Encode(result)
+	})
+}
+
+func decodeBatch[Req any](w http.ResponseWriter, r *http.Request, cfg BatchConfig) ([]Req, error) {
+	limit := cfg.MaxBodySize
+	if limit <= 0 {
+		limit = DefaultBatchConfig().MaxBodySize
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, errors.Newf(errors.OutOfRange, "batch: request body exceeds %d bytes", limit)
+		}
+		return nil, errors.ErrInvalidArgument("batch: request body unreadable")
+	}
+
+	var items []Req
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '{' {
+		var wrapped struct {
+			Items []Req `json:"items"`
+		}
+		err = json.Unmarshal(trimmed, &wrapped)
+		items = wrapped.Items
+	} else {
+		err = json.Unmarshal(trimmed, &items)
+	}
+	if err != nil {
+		return nil, errors.ErrInvalidArgument("batch: invalid request body: " + err.Error())
+	}
+
+	switch {
+	case len(items) == 0:
+		return nil, errors.ErrInvalidArgument("batch: no items")
+	case cfg.MaxItems > 0 && len(items) > cfg.MaxItems:
+		return nil, errors.Newf(errors.OutOfRange, "batch: too many items (%d > %d)", len(items), cfg.MaxItems)
+	}
+	return items, nil
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mildsunup/higo/errors"
+)
+
+func double(_ context.Context, n int) (int, error) {
+	switch {
+	case n < 0:
+		return 0, errors.ErrInvalidArgument("negative")
+	case n == 13:
+		panic("unlucky")
+	}
+	return n * 2, nil
+}
+
+func TestRunBatch(t *testing.T) {
+	result, err := RunBatch(context.Background(), []int{1, -1, 13, 4}, double)
+	if err == nil {
+		t.Fatal("expected aggregate error")
+	}
+	if result.Total != 4 || result.Succeeded != 2 || result.Failed != 2 || result.Message != "partial failure" {
+		t.Fatalf("result = %+v", result)
+	}
+	if result.HTTPStatus() != http.StatusMultiStatus {
+		t.Fatalf("status = %d", result.HTTPStatus())
+	}
+	if it := result.Items[0]; it.Status != http.StatusOK || *it.Data != 2 {
+		t.Fatalf("item 0 = %+v", it)
+	}
+	if it := result.Items[1]; it.Status != http.StatusBadRequest || it.Message != "negative" {
+		t.Fatalf("item 1 = %+v", it)
+	}
+	// panic 只影响该条目，且不向客户端暴露 panic 内容
+	if it := result.Items[2]; it.Status != http.StatusInternalServerError || strings.Contains(it.Message, "unlucky") {
+		t.Fatalf("item 2 = %+v", it)
+	}
+	if !strings.Contains(err.Error(), "unlucky") {
+		t.Fatalf("aggregate error lost panic value: %v", err)
+	}
+	if it := result.Items[3]; it.Status != http.StatusOK || *it.Data != 8 {
+		t.Fatalf("item 3 = %+v", it)
+	}
+}
+
+func TestRunBatch_Concurrency(t *testing.T) {
+	var running, peak atomic.Int32
+	items := make([]int, 20)
+	_, err := RunBatch(context.Background(), items, func(context.Context, int) (int, error) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+		return 0, nil
+	}, WithBatchConcurrency(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := peak.Load(); p > 3 {
+		t.Fatalf("peak concurrency = %d", p)
+	}
+}
+
+func TestRunBatch_FailFast(t *testing.T) {
+	var calls atomic.Int32
+	result, _ := RunBatch(context.Background(), []int{-1, 1, 2}, func(ctx context.Context, n int) (int, error) {
+		calls.Add(1)
+		return double(ctx, n)
+	}, WithBatchConcurrency(1), WithBatchFailFast())
+	if calls.Load() != 1 {
+		t.Fatalf("fn called %d times after fail-fast", calls.Load())
+	}
+	if result.Failed != 3 || result.Message != "all items failed" {
+		t.Fatalf("result = %+v", result)
+	}
+	if it := result.Items[2]; errors.Code(it.Code) != errors.Canceled {
+		t.Fatalf("canceled item = %+v", it)
+	}
+}
+
+func TestBatchHandler(t *testing.T) {
+	h := BatchHandler(double, WithBatchMaxItems(3), WithBatchMaxBodySize(64))
+	post := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/batch", strings.NewReader(body)))
+		return rec
+	}
+
+	rec := post(`[1, 2]`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("array: %d %s", rec.Code, rec.Body)
+	}
+	rec = post(`{"items": [1, -1]}`)
+	if rec.Code != http.StatusMultiStatus {
+		t.Fatalf("wrapped: %d %s", rec.Code, rec.Body)
+	}
+	var result BatchResult[int]
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil || result.Failed != 1 {
+		t.Fatalf("body = %s, %v", rec.Body, err)
+	}
+
+	for name, body := range map[string]string{
+		"empty":     `[]`,
+		"too many":  `[1, 2, 3, 4]`,
+		"malformed": `[1,`,
+		"too large": `[` + strings.Repeat(`1, `, 40) + `1]`,
+	} {
+		if rec := post(body); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: %d %s", name, rec.Code, rec.Body)
+		}
+	}
+}
+
+func TestBatchHandler_ZeroBodyLimit(t *testing.T) {
+	h := BatchHandler(double, WithBatchMaxBodySize(0))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/batch", strings.NewReader(`[1]`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("zero limit rejected body: %d %s", rec.Code, rec.Body)
+	}
+}
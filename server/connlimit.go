@@ -0,0 +1,182 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mildsunup/higo/observability"
+)
+
+// ConnMetrics 连接级指标，每个 MetricsProvider 创建一次，由多个服务器共享（按 server 标签区分）
+type ConnMetrics struct {
+	active   observability.Gauge
+	idle     observability.Gauge
+	accepted observability.Counter
+	rejected observability.Counter
+	reaped   observability.Counter
+}
+
+// NewConnMetrics 创建连接指标
+func NewConnMetrics(p observability.MetricsProvider) *ConnMetrics {
+	return &ConnMetrics{
+		active:   p.Gauge("server_connections_active", "Currently open connections", "server"),
+		idle:     p.Gauge("server_connections_idle", "Currently idle keep-alive connections", "server"),
+		accepted: p.Counter("server_connections_accepted_total", "Accepted connections", "server"),
+		rejected: p.Counter("server_connections_rejected_total", "Connections rejected by limits", "server", "reason"),
+		reaped:   p.Counter("server_connections_idle_closed_total", "Idle keep-alive connections closed by the server", "server"),
+	}
+}
+
+// LimitListener 包装监听器：限制全局与单 IP 并发连接数，超限的连接立即关闭（而非阻塞 Accept）。
+// maxConns、maxPerIP 为 0 表示不限制；metrics 可为 nil。
+func LimitListener(l net.Listener, name string, maxConns, maxPerIP int, metrics *ConnMetrics) net.Listener {
+	return &limitListener{
+		Listener: l,
+		name:     name,
+		maxConns: maxConns,
+		maxPerIP: maxPerIP,
+		perIP:    make(map[string]int),
+		metrics:  metrics,
+	}
+}
+
+type limitListener struct {
+	net.Listener
+	name     string
+	maxConns int
+	maxPerIP int
+	metrics  *ConnMetrics
+
+	mu     sync.Mutex
+	active int
+	perIP  map[string]int
+}
+
+func (l *limitListener) Accept() (net.Conn, error) {
+	for {
+		c, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		ip := remoteIP(c)
+		if reason := l.admit(ip); reason != "" {
+			_ = c.Close()
+			if l.metrics != nil {
+				l.metrics.rejected.Inc(l.name, reason)
+			}
+			continue
+		}
+		if l.metrics != nil {
+			l.metrics.accepted.Inc(l.name)
+			l.metrics.active.Inc(l.name)
+		}
+		return &limitConn{Conn: c, release: func() { l.release(ip) }}, nil
+	}
+}
+
+// admit 占用连接名额，超限时返回拒绝原因
+func (l *limitListener) admit(ip string) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.maxConns > 0 && l.active >= l.maxConns {
+		return "max_conns"
+	}
+	if l.maxPerIP > 0 && l.perIP[ip] >= l.maxPerIP {
+		return "max_conns_per_ip"
+	}
+	l.active++
+	l.perIP[ip]++
+	return ""
+}
+
+func (l *limitListener) release(ip string) {
+	l.mu.Lock()
+	l.active--
+	if l.perIP[ip]--; l.perIP[ip] <= 0 {
+		delete(l.perIP, ip)
+	}
+	l.mu.Unlock()
+	if l.metrics != nil {
+		l.metrics.active.Dec(l.name)
+	}
+}
+
+type limitConn struct {
+	net.Conn
+	once    sync.Once
+	release func()
+}
+
+func (c *limitConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(c.release)
+	return err
+}
+
+func remoteIP(c net.Conn) string {
+	addr := c.RemoteAddr()
+	if tcp, ok := addr.(*net.TCPAddr); ok {
+		return tcp.IP.String()
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
+
+// connStateHook 返回 http.Server.ConnState 回调，统计空闲连接与被空闲超时回收的连接。
+// idleTimeout 为服务器实际生效的空闲超时（IdleTimeout，未设置时为 ReadTimeout）；
+// 空闲未满该时长即关闭的连接（客户端断开、服务器停止）不计为回收
+func connStateHook(name string, m *ConnMetrics, idleTimeout time.Duration) func(net.Conn, http.ConnState) {
+	if m == nil {
+		return nil
+	}
+	var idle sync.Map // net.Conn -> 进入空闲的时间
+	return func(c net.Conn, state http.ConnState) {
+		v, wasIdle := idle.Load(c)
+		switch state {
+		case http.StateIdle:
+			if !wasIdle {
+				idle.Store(c, time.Now())
+				m.idle.Inc(name)
+			}
+		case http.StateActive, http.StateHijacked:
+			if wasIdle {
+				idle.Delete(c)
+				m.idle.Dec(name)
+			}
+		case http.StateClosed:
+			if wasIdle {
+				idle.Delete(c)
+				m.idle.Dec(name)
+				if idleTimeout > 0 && time.Since(v.(time.Time)) >= idleTimeout {
+					m.reaped.Inc(name)
+				}
+			}
+		}
+	}
+}
+
+// idleTimeout 返回 http.Server 实际生效的空闲超时
+func (o Options) idleTimeout() time.Duration {
+	if o.IdleTimeout > 0 {
+		return o.IdleTimeout
+	}
+	return o.ReadTimeout
+}
+
+// listen 按 Options 创建监听器（连接限制）
+func listen(o Options) (net.Listener, error) {
+	ln, err := net.Listen("tcp", o.Addr)
+	if err != nil {
+		return nil, err
+	}
+	if o.MaxConns > 0 || o.MaxConnsPerIP > 0 || o.ConnMetrics != nil {
+		ln = LimitListener(ln, o.Name, o.MaxConns, o.MaxConnsPerIP, o.ConnMetrics)
+	}
+	return ln, nil
+}
@@ -0,0 +1,217 @@
+package server
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/mildsunup/higo/observability"
+)
+
+// metricValue 读取指标值，标签按 名称=值 成对给出
+func metricValue(t *testing.T, reg *prometheus.Registry, name string, labels ...string) float64 {
+	t.Helper()
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range families {
+		if f.GetName() != name {
+			continue
+		}
+	next:
+		for _, m := range f.GetMetric() {
+			got := make(map[string]string)
+			for _, l := range m.GetLabel() {
+				got[l.GetName()] = l.GetValue()
+			}
+			for i := 0; i+1 < len(labels); i += 2 {
+				if got[labels[i]] != labels[i+1] {
+					continue next
+				}
+			}
+			if m.Counter != nil {
+				return m.Counter.GetValue()
+			}
+			return m.Gauge.GetValue()
+		}
+	}
+	return 0
+}
+
+func newConnMetrics() (*ConnMetrics, *prometheus.Registry) {
+	reg := prometheus.NewRegistry()
+	return NewConnMetrics(observability.NewPrometheusProvider(reg)), reg
+}
+
+// acceptAsync 在后台 Accept，返回接受的连接
+func acceptAsync(ln net.Listener) <-chan net.Conn {
+	ch := make(chan net.Conn, 8)
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				close(ch)
+				return
+			}
+			ch <- c
+		}
+	}()
+	return ch
+}
+
+// expectClosed 断言对端已关闭连接
+func expectClosed(t *testing.T, c net.Conn) {
+	t.Helper()
+	_ = c.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := c.Read(make([]byte, 1)); err != io.EOF {
+		t.Fatalf("expected rejected connection to be closed, got %v", err)
+	}
+}
+
+func TestLimitListener(t *testing.T) {
+	m, reg := newConnMetrics()
+	raw, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln := LimitListener(raw, "api", 2, 1, m)
+	defer ln.Close()
+	accepted := acceptAsync(ln)
+	addr := ln.Addr().String()
+
+	// 同一 IP 第二个连接超过单 IP 上限
+	first, _ := net.Dial("tcp", addr)
+	defer first.Close()
+	server1 := <-accepted
+	second, _ := net.Dial("tcp", addr)
+	defer second.Close()
+	expectClosed(t, second)
+
+	if v := metricValue(t, reg, "server_connections_rejected_total", "server", "api", "reason", "max_conns_per_ip"); v != 1 {
+		t.Fatalf("rejected per ip = %v", v)
+	}
+	if v := metricValue(t, reg, "server_connections_active", "server", "api"); v != 1 {
+		t.Fatalf("active = %v", v)
+	}
+
+	// 关闭后释放名额，重复关闭只释放一次
+	_ = server1.Close()
+	_ = server1.Close()
+	if v := metricValue(t, reg, "server_connections_active", "server", "api"); v != 0 {
+		t.Fatalf("active after close = %v", v)
+	}
+	third, _ := net.Dial("tcp", addr)
+	defer third.Close()
+	select {
+	case c := <-accepted:
+		defer c.Close()
+	case <-time.After(2 * time.Second):
+		t.Fatal("connection not accepted after release")
+	}
+	if v := metricValue(t, reg, "server_connections_accepted_total", "server", "api"); v != 2 {
+		t.Fatalf("accepted = %v", v)
+	}
+
+	l := ln.(*limitListener)
+	l.mu.Lock()
+	active, perIP := l.active, len(l.perIP)
+	l.mu.Unlock()
+	if active != 1 || perIP != 1 {
+		t.Fatalf("active=%d perIP=%d", active, perIP)
+	}
+}
+
+func TestLimitListener_GlobalLimit(t *testing.T) {
+	m, reg := newConnMetrics()
+	raw, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln := LimitListener(raw, "api", 1, 0, m)
+	defer ln.Close()
+	accepted := acceptAsync(ln)
+
+	first, _ := net.Dial("tcp", ln.Addr().String())
+	defer first.Close()
+	c := <-accepted
+	defer c.Close()
+	second, _ := net.Dial("tcp", ln.Addr().String())
+	defer second.Close()
+	expectClosed(t, second)
+	if v := metricValue(t, reg, "server_connections_rejected_total", "server", "api", "reason", "max_conns"); v != 1 {
+		t.Fatalf("rejected = %v", v)
+	}
+}
+
+func TestConnStateHook_Reaped(t *testing.T) {
+	m, reg := newConnMetrics()
+	hook := connStateHook("api", m, 50*time.Millisecond)
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+
+	// 客户端在空闲超时前断开：不计为回收
+	hook(c1, http.StateIdle)
+	hook(c1, http.StateClosed)
+	if v := metricValue(t, reg, "server_connections_idle_closed_total", "server", "api"); v != 0 {
+		t.Fatalf("early close counted as reaped: %v", v)
+	}
+
+	hook(c2, http.StateIdle)
+	if v := metricValue(t, reg, "server_connections_idle", "server", "api"); v != 1 {
+		t.Fatalf("idle = %v", v)
+	}
+	time.Sleep(60 * time.Millisecond)
+	hook(c2, http.StateClosed)
+	if v := metricValue(t, reg, "server_connections_idle_closed_total", "server", "api"); v != 1 {
+		t.Fatalf("reaped = %v", v)
+	}
+	if v := metricValue(t, reg, "server_connections_idle", "server", "api"); v != 0 {
+		t.Fatalf("idle after close = %v", v)
+	}
+}
+
+func TestGRPCServer_MaxConns(t *testing.T) {
+	m, reg := newConnMetrics()
+	s := NewGRPCServer(WithGRPCAddr("127.0.0.1:0"), WithGRPCMaxConns(1), WithGRPCConnMetrics(m))
+	s.opts.Name = "grpc"
+	if err := s.Start(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop(t.Context())
+
+	if err := checkGRPC(t, s.Addr()); err != nil {
+		t.Fatal(err)
+	}
+	waitActive := func(want float64) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for metricValue(t, reg, "server_connections_active", "server", "grpc") != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("active connections never reached %v", want)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	waitActive(0)
+	hold, err := net.Dial("tcp", s.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hold.Close()
+	waitActive(1)
+	extra, err := net.Dial("tcp", s.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer extra.Close()
+	expectClosed(t, extra)
+	if v := metricValue(t, reg, "server_connections_rejected_total", "server", "grpc", "reason", "max_conns"); v != 1 {
+		t.Fatalf("rejected = %v", v)
+	}
+}
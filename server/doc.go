@@ -4,7 +4,16 @@
 //   - HTTP 服务器（基于 Gin）
 //   - gRPC 服务器
 //   - 多路复用（同端口同时支持 HTTP/gRPC）
-//   - 服务器组管理
+//   - grpc-web（浏览器直连 gRPC，含 CORS 预检）
+//   - 批量接口（BatchHandler / RunBatch）：有限并发执行，逐项结果以 207 多状态信封返回
+//   - 连接防护：全局/单 IP 并发连接上限（LimitListener，HTTP 与 gRPC 服务器均支持）、请求头读取超时（防 slowloris）、
+//     空闲连接回收及连接指标
+//   - 优雅排空（Quiesce，实现 runtime.Quiescer）：HTTP 新请求返回 503 并关闭 keep-alive，gRPC 新调用返回 Unavailable
+//     且健康状态置为 NOT_SERVING，随后等待在途请求完成，返回被放弃的数量
+//   - 端口命名（WithPortName / ServicePorts / Group.Ports）：输出符合 Kubernetes 规范的端口名，可直接用于 ServiceMonitor
+//   - 服务器组管理：逐个服务器状态（Status / Handler）、单独重启失败的服务器（Restart / RestartFailed），
+//     实现 runtime.HealthChecker，可直接注册到 runtime.App；gRPC 服务器重启时重建底层 grpc.Server
+//     并重新执行 RegisterService，MultiplexServer 使用外部 grpc.Server，停止后重启返回 ErrNotRestartable
 //
 // 使用示例：
 //
@@ -12,4 +21,14 @@
 //	grpc := server.NewGRPC(cfg)
 //	group := server.NewGroup(http, grpc)
 //	group.Start(ctx)
+//	// 端口释放后恢复失败的监听，无需重启进程
+//	if err := group.Restart(ctx, "http"); err != nil { ... }
+//
+//	public := server.NewHTTPServer(engine, server.WithAddr(":443"),
+//	    server.WithMaxConns(10000), server.WithMaxConnsPerIP(100),
+//	    server.WithReadHeaderTimeout(5*time.Second), server.WithConnMetrics(server.NewConnMetrics(metrics)))
+//
+//	mux := server.NewMultiplexServer(engine, grpcServer, server.WithGRPCWeb(server.GRPCWebConfig{
+//	    AllowOrigins: []string{"https://app.example.com"},
+//	}))
 package server
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestHTTPServer_Quiesce(t *testing.T) {
+	ctx := context.Background()
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	s := NewHTTPServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			close(entered)
+			<-release
+		}
+		w.WriteHeader(http.StatusOK)
+	}), WithAddr("127.0.0.1:0"))
+	if err := s.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop(ctx)
+	base := "http://" + s.Addr()
+
+	slow := make(chan int, 1)
+	go func() {
+		resp, err := http.Get(base + "/slow")
+		if err != nil {
+			slow <- 0
+			return
+		}
+		resp.Body.Close()
+		slow <- resp.StatusCode
+	}()
+	<-entered
+	if s.InFlight() != 1 {
+		t.Fatalf("in flight = %d", s.InFlight())
+	}
+
+	quiesced := make(chan error, 1)
+	go func() {
+		n, err := s.Quiesce(ctx)
+		if n != 0 && err == nil {
+			err = errors.New("abandoned requests")
+		}
+		quiesced <- err
+	}()
+	// 排空期间新请求返回 503，在途请求继续完成
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		resp, err := http.Get(base + "/fast")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusServiceUnavailable {
+			if !resp.Close {
+				t.Fatal("draining response keeps the connection alive")
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("new requests still accepted while draining")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	close(release)
+	if code := <-slow; code != http.StatusOK {
+		t.Fatalf("in-flight request = %d", code)
+	}
+	if err := <-quiesced; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHTTPServer_QuiesceDeadline(t *testing.T) {
+	ctx := context.Background()
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	s := NewHTTPServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(entered)
+		<-release
+	}), WithAddr("127.0.0.1:0"))
+	if err := s.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop(ctx)
+	defer close(release) // 先放行在途请求再停止
+
+	go func() {
+		if resp, err := http.Get("http://" + s.Addr()); err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-entered
+
+	qctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if n, err := s.Quiesce(qctx); n != 1 || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("quiesce = %d, %v", n, err)
+	}
+}
+
+func TestGRPCServer_Quiesce(t *testing.T) {
+	ctx := context.Background()
+	s := NewGRPCServer(WithGRPCAddr("127.0.0.1:0"))
+	g := NewGroup(s)
+	if err := g.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer g.Stop(ctx)
+	if err := checkGRPC(t, s.Addr()); err != nil {
+		t.Fatal(err)
+	}
+
+	// 组排空转发到 gRPC 服务器：健康状态置为 NOT_SERVING
+	if n, err := g.Quiesce(ctx); n != 0 || err != nil {
+		t.Fatalf("quiesce = %d, %v", n, err)
+	}
+	if err := checkGRPC(t, s.Addr()); err == nil || err.Error() != "NOT_SERVING" {
+		t.Fatalf("health while draining = %v", err)
+	}
+}
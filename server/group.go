@@ -2,18 +2,85 @@ package server
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
 	"sync"
+	"time"
 )
 
+// ServerState 服务器状态
+type ServerState string
+
+const (
+	ServerIdle     ServerState = "idle"
+	ServerStarting ServerState = "starting"
+	ServerRunning  ServerState = "running"
+	ServerFailed   ServerState = "failed"
+	ServerStopped  ServerState = "stopped"
+)
+
+// ErrorReporter 可报告运行期错误的服务器（如 Serve 异常退出），服务器组据此标记失败
+type ErrorReporter interface {
+	Err() error
+}
+
+var (
+	// ErrServerNotFound 服务器不存在
+	ErrServerNotFound = errors.New("server: server not found")
+	// ErrNotRestartable 服务器停止后无法再次启动（如底层 gRPC Server 已停止且无法重建）
+	ErrNotRestartable = errors.New("server: server cannot be restarted after stop")
+)
+
+// ServerStatus 单个服务器状态
+type ServerStatus struct {
+	Name     string      `json:"name"`
+	Addr     string      `json:"addr"`
+	State    ServerState `json:"state"`
+	Error    string      `json:"error,omitempty"`
+	Since    time.Time   `json:"since"`
+	Restarts int         `json:"restarts"`
+}
+
+// GroupStatus 服务器组状态
+type GroupStatus struct {
+	Healthy bool           `json:"healthy"` // 所有服务器均在运行
+	Servers []ServerStatus `json:"servers"`
+}
+
+// Err 合并未运行服务器的错误，全部运行时返回 nil
+func (s GroupStatus) Err() error {
+	var errs []error
+	for _, st := range s.Servers {
+		if st.State == ServerRunning {
+			continue
+		}
+		msg := string(st.State)
+		if st.Error != "" {
+			msg += ": " + st.Error
+		}
+		errs = append(errs, fmt.Errorf("%s: %s", st.Name, msg))
+	}
+	return errors.Join(errs...)
+}
+
 // Group 服务器组，管理多个服务器的生命周期
+// 记录每个服务器的状态，支持单独重启失败的服务器，并通过 Health / Handler 汇报组状态。
 type Group struct {
 	servers []Server
+	status  []ServerStatus
 	mu      sync.RWMutex
+	ops     sync.Mutex // 串行化启动、停止与重启
 }
 
 // NewGroup 创建服务器组
 func NewGroup(servers ...Server) *Group {
-	return &Group{servers: servers}
+	g := &Group{}
+	for _, s := range servers {
+		g.Add(s)
+	}
+	return g
 }
 
 // Add 添加服务器
@@ -21,18 +88,25 @@ func (g *Group) Add(s Server) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 	g.servers = append(g.servers, s)
+	g.status = append(g.status, ServerStatus{Name: s.Name(), Addr: s.Addr(), State: ServerIdle, Since: time.Now()})
 }
 
+// Name 组件名称（用于 runtime 注册）
+func (g *Group) Name() string { return "server-group" }
+
 // Start 启动所有服务器
+// 任一服务器启动失败（如端口被占用）时，停止已启动的服务器并返回错误；
+// 失败的服务器保持 failed 状态，可通过 Status 查看原因。
 func (g *Group) Start(ctx context.Context) error {
-	g.mu.RLock()
-	defer g.mu.RUnlock()
+	g.ops.Lock()
+	defer g.ops.Unlock()
 
-	for _, s := range g.servers {
-		if err := s.Start(ctx); err != nil {
+	servers := g.Servers()
+	for i, s := range servers {
+		if err := g.startServer(ctx, i, s); err != nil {
 			// 启动失败，停止已启动的服务器
-			g.stopServers(ctx, g.servers[:len(g.servers)-1])
-			return err
+			g.stopServers(ctx, servers[:i])
+			return fmt.Errorf("server %s: %w", s.Name(), err)
 		}
 	}
 	return nil
@@ -40,22 +114,172 @@ func (g *Group) Start(ctx context.Context) error {
 
 // Stop 停止所有服务器（逆序）
 func (g *Group) Stop(ctx context.Context) error {
+	g.ops.Lock()
+	defer g.ops.Unlock()
+	return g.stopServers(ctx, g.Servers())
+}
+
+// Restart 重启单个服务器（通常用于失败后恢复，如端口释放后重新绑定），不影响组内其他服务器
+func (g *Group) Restart(ctx context.Context, name string) error {
+	g.ops.Lock()
+	defer g.ops.Unlock()
+
 	g.mu.RLock()
-	defer g.mu.RUnlock()
-	return g.stopServers(ctx, g.servers)
+	idx := -1
+	for i, s := range g.servers {
+		if s.Name() == name {
+			idx = i
+			break
+		}
+	}
+	var s Server
+	if idx >= 0 {
+		s = g.servers[idx]
+	}
+	g.mu.RUnlock()
+	if idx < 0 {
+		return fmt.Errorf("%w: %s", ErrServerNotFound, name)
+	}
+
+	// 已失败的服务器也需要 Stop，以释放残留的监听与运行标记
+	_ = s.Stop(ctx)
+	g.mu.Lock()
+	g.status[idx].Restarts++
+	g.mu.Unlock()
+	return g.startServer(ctx, idx, s)
+}
+
+// RestartFailed 重启所有处于失败状态的服务器，返回仍未恢复的错误
+func (g *Group) RestartFailed(ctx context.Context) error {
+	var errs []error
+	for _, st := range g.Status().Servers {
+		if st.State == ServerFailed {
+			if err := g.Restart(ctx, st.Name); err != nil {
+				errs = append(errs, fmt.Errorf("server %s: %w", st.Name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Status 返回各服务器状态；运行中的服务器若报告了运行期错误则标记为失败
+func (g *Group) Status() GroupStatus {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	gs := GroupStatus{Healthy: true, Servers: make([]ServerStatus, len(g.servers))}
+	for i, s := range g.servers {
+		if g.status[i].State == ServerRunning {
+			if r, ok := s.(ErrorReporter); ok {
+				if err := r.Err(); err != nil {
+					g.setStatus(i, ServerFailed, err)
+				}
+			}
+		}
+		g.status[i].Addr = s.Addr()
+		gs.Servers[i] = g.status[i]
+		gs.Healthy = gs.Healthy && g.status[i].State == ServerRunning
+	}
+	return gs
+}
+
+// Health 健康检查（实现 runtime.HealthChecker），存在未运行的服务器时返回错误
+func (g *Group) Health(ctx context.Context) error {
+	return g.Status().Err()
+}
+
+// Quiesce 并发排空组内支持 runtime.Quiescer 的服务器，返回被放弃的在途请求总数
+func (g *Group) Quiesce(ctx context.Context) (int, error) {
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		abandoned int
+		errs      []error
+	)
+	for _, s := range g.Servers() {
+		q, ok := s.(interface {
+			Quiesce(context.Context) (int, error)
+		})
+		if !ok {
+			continue
+		}
+		wg.Go(func() {
+			n, err := q.Quiesce(ctx)
+			mu.Lock()
+			defer mu.Unlock()
+			abandoned += n
+			if err != nil {
+				errs = append(errs, fmt.Errorf("server %s: %w", s.Name(), err))
+			}
+		})
+	}
+	wg.Wait()
+	return abandoned, errors.Join(errs...)
+}
+
+// Handler 返回组状态接口：全部运行返回 200，否则 503
+func (g *Group) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		st := g.Status()
+		code := http.StatusOK
+		if !st.Healthy {
+			code = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(st)
+	})
+}
+
+func (g *Group) startServer(ctx context.Context, i int, s Server) error {
+	g.mu.Lock()
+	g.setStatus(i, ServerStarting, nil)
+	g.mu.Unlock()
+
+	err := s.Start(ctx)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err != nil {
+		g.setStatus(i, ServerFailed, err)
+		return err
+	}
+	g.setStatus(i, ServerRunning, nil)
+	return nil
 }
 
+// stopServers 逆序停止 servers（组内前缀），已失败的服务器保留失败原因
 func (g *Group) stopServers(ctx context.Context, servers []Server) error {
 	var lastErr error
 	// 逆序停止
 	for i := len(servers) - 1; i >= 0; i-- {
-		if err := servers[i].Stop(ctx); err != nil {
+		err := servers[i].Stop(ctx)
+		if err != nil {
 			lastErr = err
 		}
+		g.mu.Lock()
+		if g.status[i].State != ServerFailed || err != nil {
+			g.setStatus(i, ServerStopped, err)
+		}
+		g.mu.Unlock()
 	}
 	return lastErr
 }
 
+// setStatus 更新状态，调用方需持有 g.mu
+func (g *Group) setStatus(i int, state ServerState, err error) {
+	st := &g.status[i]
+	if st.State != state {
+		st.Since = time.Now()
+	}
+	st.State = state
+	st.Error = ""
+	if err != nil {
+		st.Error = err.Error()
+	}
+}
+
 // Servers 返回所有服务器
 func (g *Group) Servers() []Server {
 	g.mu.RLock()
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func checkGRPC(t *testing.T, addr string) error {
+	t.Helper()
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		return err
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		return errors.New(resp.Status.String())
+	}
+	return nil
+}
+
+func TestGroup_RestartGRPC(t *testing.T) {
+	ctx := context.Background()
+	s := NewGRPCServer(WithGRPCAddr("127.0.0.1:0"))
+	s.opts.Name = "grpc"
+	registered := 0
+	s.RegisterService(func(*grpc.Server) { registered++ })
+
+	g := NewGroup(s)
+	if err := g.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer g.Stop(ctx)
+	if err := checkGRPC(t, s.Addr()); err != nil {
+		t.Fatalf("before restart: %v", err)
+	}
+
+	if err := g.Restart(ctx, "grpc"); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkGRPC(t, s.Addr()); err != nil {
+		t.Fatalf("after restart: %v", err)
+	}
+	if registered != 2 {
+		t.Fatalf("RegisterService replayed %d times, want 2", registered)
+	}
+	st := g.Status()
+	if !st.Healthy || st.Servers[0].Restarts != 1 {
+		t.Fatalf("status = %+v", st)
+	}
+}
+
+func TestGroup_RestartGRPC_DirectRegistration(t *testing.T) {
+	ctx := context.Background()
+	s := NewGRPCServer(WithGRPCAddr("127.0.0.1:0"))
+	s.opts.Name = "grpc"
+	s.Server().RegisterService(&grpc.ServiceDesc{ServiceName: "test.Direct", HandlerType: (*any)(nil)}, struct{}{})
+
+	g := NewGroup(s)
+	if err := g.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer g.Stop(ctx)
+
+	if err := g.Restart(ctx, "grpc"); !errors.Is(err, ErrNotRestartable) {
+		t.Fatalf("Restart = %v, want ErrNotRestartable", err)
+	}
+	if st := g.Status(); st.Servers[0].State != ServerFailed {
+		t.Fatalf("state = %s, want failed", st.Servers[0].State)
+	}
+}
+
+func TestGroup_RestartMultiplex(t *testing.T) {
+	ctx := context.Background()
+	s := NewMultiplexServer(nil, grpc.NewServer(), WithAddr("127.0.0.1:0"))
+	g := NewGroup(s)
+	if err := g.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer g.Stop(ctx)
+
+	if err := g.Restart(ctx, s.Name()); !errors.Is(err, ErrNotRestartable) {
+		t.Fatalf("Restart = %v, want ErrNotRestartable", err)
+	}
+}
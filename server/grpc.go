@@ -3,15 +3,22 @@ package server
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net"
+	"strings"
 	"sync"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/health"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	"github.com/mildsunup/higo/observability"
+	"github.com/mildsunup/higo/runtime"
 )
 
 // GRPCOptions gRPC 服务器配置
@@ -42,6 +49,11 @@ func WithGRPCAddr(addr string) GRPCOption {
 	return func(o *GRPCOptions) { o.Addr = addr }
 }
 
+// WithGRPCPortName 设置 Kubernetes Service 端口名
+func WithGRPCPortName(name string) GRPCOption {
+	return func(o *GRPCOptions) { o.PortName = observability.PortName(name) }
+}
+
 // WithMaxRecvMsgSize 设置最大接收消息大小
 func WithMaxRecvMsgSize(size int) GRPCOption {
 	return func(o *GRPCOptions) { o.MaxRecvMsgSize = size }
@@ -62,6 +74,21 @@ func WithUnaryInterceptor(i grpc.UnaryServerInterceptor) GRPCOption {
 	return func(o *GRPCOptions) { o.Interceptors = append(o.Interceptors, i) }
 }
 
+// WithGRPCMaxConns 设置最大并发连接数，超出的连接直接关闭
+func WithGRPCMaxConns(n int) GRPCOption {
+	return func(o *GRPCOptions) { o.MaxConns = n }
+}
+
+// WithGRPCMaxConnsPerIP 设置单 IP 最大并发连接数
+func WithGRPCMaxConnsPerIP(n int) GRPCOption {
+	return func(o *GRPCOptions) { o.MaxConnsPerIP = n }
+}
+
+// WithGRPCConnMetrics 设置连接指标（与 HTTP 服务器共享同一 ConnMetrics，按 server 标签区分）
+func WithGRPCConnMetrics(m *ConnMetrics) GRPCOption {
+	return func(o *GRPCOptions) { o.ConnMetrics = m }
+}
+
 // WithStreamInterceptor 添加 Stream 拦截器
 func WithStreamInterceptor(i grpc.StreamServerInterceptor) GRPCOption {
 	return func(o *GRPCOptions) { o.StreamInterceptors = append(o.StreamInterceptors, i) }
@@ -75,6 +102,11 @@ type GRPCServer struct {
 	listener     net.Listener
 	mu           sync.Mutex
 	running      bool
+	stopped      bool // 底层 Server 已停止，再次 Start 时重建
+	serveErr     error
+	inflight     *runtime.InFlight
+	serverOpts   []grpc.ServerOption
+	services     []func(*grpc.Server)
 }
 
 // NewGRPCServer 创建 gRPC 服务器
@@ -84,6 +116,8 @@ func NewGRPCServer(opts ...GRPCOption) *GRPCServer {
 		opt(&o)
 	}
 
+	s := &GRPCServer{opts: o, inflight: runtime.NewInFlight()}
+
 	serverOpts := []grpc.ServerOption{
 		grpc.MaxRecvMsgSize(o.MaxRecvMsgSize),
 		grpc.MaxSendMsgSize(o.MaxSendMsgSize),
@@ -94,50 +128,84 @@ func NewGRPCServer(opts ...GRPCOption) *GRPCServer {
 		}),
 	}
 
-	if len(o.Interceptors) > 0 {
-		serverOpts = append(serverOpts, grpc.ChainUnaryInterceptor(o.Interceptors...))
-	}
-	if len(o.StreamInterceptors) > 0 {
-		serverOpts = append(serverOpts, grpc.ChainStreamInterceptor(o.StreamInterceptors...))
-	}
+	// 在途跟踪位于最外层，排空期间的新调用直接返回 Unavailable
+	serverOpts = append(serverOpts,
+		grpc.ChainUnaryInterceptor(append([]grpc.UnaryServerInterceptor{s.unaryTrack}, o.Interceptors...)...),
+		grpc.ChainStreamInterceptor(append([]grpc.StreamServerInterceptor{s.streamTrack}, o.StreamInterceptors...)...),
+	)
 
-	grpcServer := grpc.NewServer(serverOpts...)
-
-	// 健康检查
-	healthServer := health.NewServer()
-	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	s.serverOpts = serverOpts
+	s.healthServer = health.NewServer()
+	s.server = s.newServer()
+	return s
+}
 
-	// Reflection
-	if o.EnableReflection {
-		reflection.Register(grpcServer)
+// newServer 创建底层 gRPC Server，注册健康检查、reflection 与经 RegisterService 注册的服务
+func (s *GRPCServer) newServer() *grpc.Server {
+	srv := grpc.NewServer(s.serverOpts...)
+	healthpb.RegisterHealthServer(srv, s.healthServer)
+	if s.opts.EnableReflection {
+		reflection.Register(srv)
+	}
+	for _, fn := range s.services {
+		fn(srv)
 	}
+	return srv
+}
 
-	return &GRPCServer{
-		opts:         o,
-		server:       grpcServer,
-		healthServer: healthServer,
+// rebuild 重建已停止的底层 Server（gRPC Server 停止后无法再次 Serve），调用方需持有 s.mu。
+// 直接在 Server() 上注册的服务无法重放，此时返回 ErrNotRestartable
+func (s *GRPCServer) rebuild() error {
+	srv := s.newServer()
+	rebuilt := srv.GetServiceInfo()
+	var missing []string
+	for name := range s.server.GetServiceInfo() {
+		if _, ok := rebuilt[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		srv.Stop()
+		return fmt.Errorf("%w: services %v were not registered via RegisterService", ErrNotRestartable, missing)
 	}
+	s.server = srv
+	s.stopped = false
+	return nil
 }
 
 // Name 服务器名称
 func (s *GRPCServer) Name() string { return s.opts.Name }
 
+// PortName Kubernetes Service 端口名
+func (s *GRPCServer) PortName() string { return s.opts.portName("grpc") }
+
 // Addr 监听地址
 func (s *GRPCServer) Addr() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	if s.listener != nil {
 		return s.listener.Addr().String()
 	}
 	return s.opts.Addr
 }
 
-// Server 返回底层 gRPC Server
-func (s *GRPCServer) Server() *grpc.Server { return s.server }
+// Server 返回底层 gRPC Server。
+// Stop 后再次 Start 会重建底层 Server，只有经 RegisterService 注册的服务会被重新注册；
+// 直接在返回值上注册服务的服务器重启时返回 ErrNotRestartable
+func (s *GRPCServer) Server() *grpc.Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.server
+}
 
 // HealthServer 返回健康检查服务器
 func (s *GRPCServer) HealthServer() *health.Server { return s.healthServer }
 
-// RegisterService 注册服务
+// RegisterService 注册服务，重启重建底层 Server 时重新执行
 func (s *GRPCServer) RegisterService(fn func(*grpc.Server)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.services = append(s.services, fn)
 	fn(s.server)
 }
 
@@ -148,14 +216,23 @@ func (s *GRPCServer) Start(ctx context.Context) error {
 		s.mu.Unlock()
 		return errors.New("server already running")
 	}
+	if s.stopped {
+		if err := s.rebuild(); err != nil {
+			s.mu.Unlock()
+			return err
+		}
+	}
 
-	listener, err := net.Listen("tcp", s.opts.Addr)
+	listener, err := listen(s.opts.Options)
 	if err != nil {
 		s.mu.Unlock()
 		return err
 	}
 	s.listener = listener
 	s.running = true
+	s.serveErr = nil
+	s.inflight.Resume()
+	srv := s.server
 	s.mu.Unlock()
 
 	// 设置健康状态
@@ -163,12 +240,63 @@ func (s *GRPCServer) Start(ctx context.Context) error {
 
 	// 非阻塞启动
 	go func() {
-		_ = s.server.Serve(listener)
+		err := srv.Serve(listener)
+		if err == nil {
+			return
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		// ErrServerStopped 仅在主动 Stop 后属于正常退出
+		if s.server == srv && (s.running || !errors.Is(err, grpc.ErrServerStopped)) {
+			s.serveErr = err
+		}
 	}()
 
 	return nil
 }
 
+// Quiesce 排空：健康状态置为 NOT_SERVING，新调用返回 Unavailable，并等待在途调用完成（实现 runtime.Quiescer）
+func (s *GRPCServer) Quiesce(ctx context.Context) (int, error) {
+	s.healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	return s.inflight.Quiesce(ctx)
+}
+
+// InFlight 返回在途调用数
+func (s *GRPCServer) InFlight() int {
+	return s.inflight.Count()
+}
+
+func (s *GRPCServer) unaryTrack(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if strings.HasPrefix(info.FullMethod, "/grpc.health.v1.Health/") {
+		return handler(ctx, req)
+	}
+	done, err := s.inflight.Begin()
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, "server is draining")
+	}
+	defer done()
+	return handler(ctx, req)
+}
+
+func (s *GRPCServer) streamTrack(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if strings.HasPrefix(info.FullMethod, "/grpc.health.v1.Health/") {
+		return handler(srv, ss)
+	}
+	done, err := s.inflight.Begin()
+	if err != nil {
+		return status.Error(codes.Unavailable, "server is draining")
+	}
+	defer done()
+	return handler(srv, ss)
+}
+
+// Err 返回运行期间 Serve 的异常退出错误，正常运行时为 nil
+func (s *GRPCServer) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.serveErr
+}
+
 // Stop 停止服务器
 func (s *GRPCServer) Stop(ctx context.Context) error {
 	s.mu.Lock()
@@ -178,6 +306,7 @@ func (s *GRPCServer) Stop(ctx context.Context) error {
 		return nil
 	}
 	s.running = false
+	s.stopped = true
 
 	// 设置健康状态
 	s.healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
@@ -0,0 +1,251 @@
+package server
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/http2"
+	"google.golang.org/grpc"
+)
+
+const (
+	grpcWebContentType     = "application/grpc-web"
+	grpcWebTextContentType = "application/grpc-web-text"
+	grpcContentType        = "application/grpc"
+)
+
+// GRPCWebConfig grpc-web 配置
+type GRPCWebConfig struct {
+	AllowOrigins     []string // 允许的跨域来源，"*" 表示任意来源；为空时仅允许同源请求
+	AllowHeaders     []string // 额外允许的请求头（grpc-web 所需头已内置）
+	AllowCredentials bool
+	MaxAge           int // 预检缓存秒数
+}
+
+// DefaultGRPCWebConfig 默认配置：不允许跨域，需要时显式设置 AllowOrigins
+func DefaultGRPCWebConfig() GRPCWebConfig {
+	return GRPCWebConfig{MaxAge: 600}
+}
+
+var (
+	grpcWebAllowHeaders  = []string{"Content-Type", "X-Grpc-Web", "X-User-Agent", "Grpc-Timeout", "Authorization"}
+	grpcWebExposeHeaders = []string{"Grpc-Status", "Grpc-Message", "Grpc-Status-Details-Bin"}
+)
+
+// WithGRPCWeb 在 HTTP 端口上启用 grpc-web（仅 MultiplexServer 生效）
+func WithGRPCWeb(cfg GRPCWebConfig) Option {
+	return func(o *Options) { o.GRPCWeb = &cfg }
+}
+
+// GRPCWebHandler 将浏览器 grpc-web 请求转换为 gRPC 调用
+// 支持 application/grpc-web(+proto) 与 application/grpc-web-text（base64），
+// 处理 grpc-web 的 CORS 预检；其他请求交给 next。
+type GRPCWebHandler struct {
+	grpc   *grpc.Server
+	next   http.Handler
+	config GRPCWebConfig
+}
+
+// NewGRPCWebHandler 创建 grpc-web 处理器
+//
+//	handler := server.NewGRPCWebHandler(grpcServer, ginEngine, server.DefaultGRPCWebConfig())
+//	srv := server.NewHTTPServer(handler)
+func NewGRPCWebHandler(grpcServer *grpc.Server, next http.Handler, cfg GRPCWebConfig) *GRPCWebHandler {
+	return &GRPCWebHandler{grpc: grpcServer, next: next, config: cfg}
+}
+
+// ServeHTTP 实现 http.Handler
+func (h *GRPCWebHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case isGRPCWebPreflight(r):
+		h.preflight(w, r)
+	case isGRPCWebRequest(r):
+		h.serveGRPCWeb(w, r)
+	default:
+		h.next.ServeHTTP(w, r)
+	}
+}
+
+func isGRPCWebRequest(r *http.Request) bool {
+	return r.Method == http.MethodPost && strings.HasPrefix(r.Header.Get("Content-Type"), grpcWebContentType)
+}
+
+func isGRPCWebPreflight(r *http.Request) bool {
+	if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+		return false
+	}
+	for _, h := range strings.Split(r.Header.Get("Access-Control-Request-Headers"), ",") {
+		if strings.EqualFold(strings.TrimSpace(h), "x-grpc-web") {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *GRPCWebHandler) allowOrigin(w http.ResponseWriter, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || sameOrigin(r, origin) {
+		return true // 非浏览器或同源请求
+	}
+	allowed := slices.Contains(h.config.AllowOrigins, "*") || slices.Contains(h.config.AllowOrigins, origin)
+	if !allowed {
+		return false
+	}
+
+	if slices.Contains(h.config.AllowOrigins, "*") && !h.config.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	} else {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Add("Vary", "Origin")
+	}
+	if h.config.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+	return true
+}
+
+// sameOrigin 来源的主机与请求主机一致（浏览器同源 POST 也会携带 Origin）
+func sameOrigin(r *http.Request, origin string) bool {
+	u, err := url.Parse(origin)
+	return err == nil && u.Host != "" && strings.EqualFold(u.Host, r.Host)
+}
+
+func (h *GRPCWebHandler) preflight(w http.ResponseWriter, r *http.Request) {
+	if !h.allowOrigin(w, r) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", strings.Join(append(grpcWebAllowHeaders, h.config.AllowHeaders...), ", "))
+	if h.config.MaxAge > 0 {
+		w.Header().Set("Access-Control-Max-Age", strconv.Itoa(h.config.MaxAge))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *GRPCWebHandler) serveGRPCWeb(w http.ResponseWriter, r *http.Request) {
+	if !h.allowOrigin(w, r) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	w.Header().Set("Access-Control-Expose-Headers", strings.Join(grpcWebExposeHeaders, ", "))
+
+	contentType := r.Header.Get("Content-Type")
+	text := strings.HasPrefix(contentType, grpcWebTextContentType)
+
+	// 伪装为 HTTP/2 gRPC 请求交给 grpc.Server.ServeHTTP
+	req := r.Clone(r.Context())
+	req.ProtoMajor, req.ProtoMinor, req.Proto = 2, 0, "HTTP/2"
+	req.ContentLength = -1
+	req.Header.Del("Content-Length")
+	req.Header.Set("Te", "trailers")
+	if text {
+		req.Header.Set("Content-Type", grpcContentType+strings.TrimPrefix(contentType, grpcWebTextContentType))
+		req.Body = io.NopCloser(base64.NewDecoder(base64.StdEncoding, r.Body))
+	} else {
+		req.Header.Set("Content-Type", grpcContentType+strings.TrimPrefix(contentType, grpcWebContentType))
+	}
+
+	rw := newGRPCWebResponse(w, text)
+	h.grpc.ServeHTTP(rw, req)
+	rw.finish()
+}
+
+// grpcWebResponse 将 gRPC 的 HTTP/2 trailer 转换为 grpc-web 的 trailer 帧
+type grpcWebResponse struct {
+	w           http.ResponseWriter
+	header      http.Header
+	sentKeys    map[string]bool
+	wroteHeader bool
+	text        bool
+}
+
+func newGRPCWebResponse(w http.ResponseWriter, text bool) *grpcWebResponse {
+	return &grpcWebResponse{w: w, header: make(http.Header), text: text}
+}
+
+func (g *grpcWebResponse) Header() http.Header { return g.header }
+
+func (g *grpcWebResponse) WriteHeader(code int) {
+	if g.wroteHeader {
+		return
+	}
+	g.wroteHeader = true
+
+	declared := g.header.Values("Trailer")
+	g.sentKeys = make(map[string]bool, len(g.header))
+	dst := g.w.Header()
+	for k, vv := range g.header {
+		if k == "Trailer" || strings.HasPrefix(k, http2.TrailerPrefix) || slices.Contains(declared, k) {
+			continue
+		}
+		g.sentKeys[k] = true
+		if k == "Content-Type" {
+			vv = []string{g.webContentType(vv[0])}
+		}
+		dst[k] = vv
+	}
+	g.w.WriteHeader(code)
+}
+
+func (g *grpcWebResponse) Write(b []byte) (int, error) {
+	if !g.wroteHeader {
+		g.WriteHeader(http.StatusOK)
+	}
+	if g.text {
+		if _, err := io.WriteString(g.w, base64.StdEncoding.EncodeToString(b)); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	return g.w.Write(b)
+}
+
+func (g *grpcWebResponse) Flush() {
+	if !g.wroteHeader {
+		g.WriteHeader(http.StatusOK)
+	}
+	if f, ok := g.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// finish 写出 trailer 帧：0x80 标志 + 4 字节长度 + "key: value\r\n" 列表
+func (g *grpcWebResponse) finish() {
+	if !g.wroteHeader {
+		g.WriteHeader(http.StatusOK)
+	}
+
+	var trailers bytes.Buffer
+	for k, vv := range g.header {
+		if k == "Trailer" || g.sentKeys[k] {
+			continue
+		}
+		name := strings.ToLower(strings.TrimPrefix(k, http2.TrailerPrefix))
+		for _, v := range vv {
+			trailers.WriteString(name + ": " + v + "\r\n")
+		}
+	}
+
+	frame := make([]byte, 5, 5+trailers.Len())
+	frame[0] = 0x80
+	binary.BigEndian.PutUint32(frame[1:], uint32(trailers.Len()))
+	frame = append(frame, trailers.Bytes()...)
+	_, _ = g.Write(frame)
+	g.Flush()
+}
+
+func (g *grpcWebResponse) webContentType(ct string) string {
+	base := grpcWebContentType
+	if g.text {
+		base = grpcWebTextContentType
+	}
+	return base + strings.TrimPrefix(ct, grpcContentType)
+}
@@ -0,0 +1,214 @@
+package server
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func newGRPCWebServer(t *testing.T, cfg GRPCWebConfig) *httptest.Server {
+	t.Helper()
+	gs := grpc.NewServer()
+	healthpb.RegisterHealthServer(gs, health.NewServer())
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	srv := httptest.NewServer(NewGRPCWebHandler(gs, next, cfg))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// grpcWebFrames 解析 grpc-web 响应体为数据帧与 trailer
+func grpcWebFrames(t *testing.T, body []byte) (data [][]byte, trailer string) {
+	t.Helper()
+	for len(body) > 0 {
+		if len(body) < 5 {
+			t.Fatalf("truncated frame: %x", body)
+		}
+		n := binary.BigEndian.Uint32(body[1:5])
+		payload := body[5 : 5+n]
+		if body[0]&0x80 != 0 {
+			trailer += string(payload)
+		} else {
+			data = append(data, payload)
+		}
+		body = body[5+n:]
+	}
+	return data, trailer
+}
+
+func grpcWebRequest(t *testing.T, url, method, contentType string, text bool, msg proto.Message) *http.Request {
+	t.Helper()
+	payload, err := proto.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame := make([]byte, 5, 5+len(payload))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(payload)))
+	frame = append(frame, payload...)
+	if text {
+		frame = []byte(base64.StdEncoding.EncodeToString(frame))
+	}
+	req, err := http.NewRequest(http.MethodPost, url+method, bytes.NewReader(frame))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-Grpc-Web", "1")
+	return req
+}
+
+func TestGRPCWeb_Unary(t *testing.T) {
+	srv := newGRPCWebServer(t, DefaultGRPCWebConfig())
+	const method = "/grpc.health.v1.Health/Check"
+
+	for _, tc := range []struct {
+		contentType string
+		text        bool
+	}{
+		{"application/grpc-web+proto", false},
+		{"application/grpc-web", false},
+		{"application/grpc-web-text", true},
+	} {
+		t.Run(tc.contentType, func(t *testing.T) {
+			resp, err := http.DefaultClient.Do(grpcWebRequest(t, srv.URL, method, tc.contentType, tc.text, &healthpb.HealthCheckRequest{}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("status = %d: %s", resp.StatusCode, body)
+			}
+			if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, strings.SplitN(tc.contentType, "+", 2)[0]) {
+				t.Fatalf("content type = %q", ct)
+			}
+			if tc.text {
+				// 每次写入单独编码，按 4 字节分组解码
+				var decoded []byte
+				for i := 0; i < len(body); i += 4 {
+					chunk, err := base64.StdEncoding.DecodeString(string(body[i:min(i+4, len(body))]))
+					if err != nil {
+						t.Fatal(err)
+					}
+					decoded = append(decoded, chunk...)
+				}
+				body = decoded
+			}
+
+			data, trailer := grpcWebFrames(t, body)
+			if len(data) != 1 {
+				t.Fatalf("data frames = %d, trailer = %q", len(data), trailer)
+			}
+			var out healthpb.HealthCheckResponse
+			if err := proto.Unmarshal(data[0], &out); err != nil {
+				t.Fatal(err)
+			}
+			if out.Status != healthpb.HealthCheckResponse_SERVING {
+				t.Fatalf("health = %v", out.Status)
+			}
+			if !strings.Contains(trailer, "grpc-status: 0\r\n") {
+				t.Fatalf("trailer = %q", trailer)
+			}
+		})
+	}
+}
+
+func TestGRPCWeb_ErrorStatusInTrailer(t *testing.T) {
+	srv := newGRPCWebServer(t, DefaultGRPCWebConfig())
+	req := grpcWebRequest(t, srv.URL, "/grpc.health.v1.Health/Check", "application/grpc-web+proto", false,
+		&healthpb.HealthCheckRequest{Service: "missing"})
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	// 无数据帧的错误响应：状态可能在头部（trailers-only）或 trailer 帧中
+	data, trailer := grpcWebFrames(t, body)
+	if len(data) != 0 {
+		t.Fatalf("data frames = %d", len(data))
+	}
+	if resp.Header.Get("Grpc-Status") != "5" && !strings.Contains(trailer, "grpc-status: 5\r\n") {
+		t.Fatalf("header = %v, trailer = %q", resp.Header, trailer)
+	}
+}
+
+func TestGRPCWeb_CORS(t *testing.T) {
+	const method = "/grpc.health.v1.Health/Check"
+	preflight := func(url, origin string) *http.Response {
+		req, _ := http.NewRequest(http.MethodOptions, url+method, nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		req.Header.Set("Access-Control-Request-Headers", "content-type,x-grpc-web")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+	call := func(url, origin string) *http.Response {
+		req := grpcWebRequest(t, url, method, "application/grpc-web+proto", false, &healthpb.HealthCheckRequest{})
+		req.Header.Set("Origin", origin)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	// 默认不允许跨域，同源请求不受影响
+	def := newGRPCWebServer(t, DefaultGRPCWebConfig())
+	if resp := preflight(def.URL, "https://evil.example.com"); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("default preflight = %d", resp.StatusCode)
+	}
+	if resp := call(def.URL, "https://evil.example.com"); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("default cross-origin call = %d", resp.StatusCode)
+	}
+	if resp := call(def.URL, def.URL); resp.StatusCode != http.StatusOK || resp.Header.Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("same-origin call = %d %v", resp.StatusCode, resp.Header)
+	}
+
+	cfg := DefaultGRPCWebConfig()
+	cfg.AllowOrigins = []string{"https://app.example.com"}
+	cfg.AllowCredentials = true
+	srv := newGRPCWebServer(t, cfg)
+	resp := preflight(srv.URL, "https://app.example.com")
+	if resp.StatusCode != http.StatusNoContent ||
+		resp.Header.Get("Access-Control-Allow-Origin") != "https://app.example.com" ||
+		resp.Header.Get("Access-Control-Allow-Credentials") != "true" ||
+		!strings.Contains(resp.Header.Get("Access-Control-Allow-Headers"), "X-Grpc-Web") ||
+		resp.Header.Get("Access-Control-Max-Age") != "600" {
+		t.Fatalf("preflight = %d %v", resp.StatusCode, resp.Header)
+	}
+	resp = call(srv.URL, "https://app.example.com")
+	if resp.StatusCode != http.StatusOK || !strings.Contains(resp.Header.Get("Access-Control-Expose-Headers"), "Grpc-Status") {
+		t.Fatalf("call = %d %v", resp.StatusCode, resp.Header)
+	}
+	if resp := call(srv.URL, "https://other.example.com"); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("unlisted origin = %d", resp.StatusCode)
+	}
+
+	// 非 grpc-web 请求交给 next
+	plain, err := http.Get(srv.URL + "/healthz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain.Body.Close()
+	if plain.StatusCode != http.StatusTeapot {
+		t.Fatalf("fallthrough = %d", plain.StatusCode)
+	}
+}
@@ -9,6 +9,8 @@ import (
 
 	"golang.org/x/net/http2"
 	"golang.org/x/net/http2/h2c"
+
+	"github.com/mildsunup/higo/runtime"
 )
 
 // HTTPServer HTTP 服务器
@@ -19,22 +21,30 @@ type HTTPServer struct {
 	listener net.Listener
 	mu       sync.Mutex
 	running  bool
+	serveErr error
+	inflight *runtime.InFlight
 }
 
 // NewHTTPServer 创建 HTTP 服务器
 func NewHTTPServer(handler http.Handler, opts ...Option) *HTTPServer {
 	o := ApplyOptions(opts...)
 	return &HTTPServer{
-		opts:    o,
-		handler: handler,
+		opts:     o,
+		handler:  handler,
+		inflight: runtime.NewInFlight(),
 	}
 }
 
 // Name 服务器名称
 func (s *HTTPServer) Name() string { return s.opts.Name }
 
+// PortName Kubernetes Service 端口名
+func (s *HTTPServer) PortName() string { return s.opts.portName("http") }
+
 // Addr 监听地址
 func (s *HTTPServer) Addr() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	if s.listener != nil {
 		return s.listener.Addr().String()
 	}
@@ -49,7 +59,7 @@ func (s *HTTPServer) Start(ctx context.Context) error {
 		return errors.New("server already running")
 	}
 
-	listener, err := net.Listen("tcp", s.opts.Addr)
+	listener, err := listen(s.opts)
 	if err != nil {
 		s.mu.Unlock()
 		return err
@@ -58,21 +68,30 @@ func (s *HTTPServer) Start(ctx context.Context) error {
 
 	// 支持 H2C (HTTP/2 Cleartext)
 	h2s := &http2.Server{}
-	s.server = &http.Server{
-		Handler:        h2c.NewHandler(s.handler, h2s),
+	srv := &http.Server{
+		Handler:        h2c.NewHandler(s.track(s.handler), h2s),
 		ReadTimeout:    s.opts.ReadTimeout,
 		WriteTimeout:   s.opts.WriteTimeout,
 		IdleTimeout:    s.opts.IdleTimeout,
 		MaxHeaderBytes: s.opts.MaxHeaderBytes,
+
+		ReadHeaderTimeout: s.opts.ReadHeaderTimeout,
+		ConnState:         connStateHook(s.opts.Name, s.opts.ConnMetrics, s.opts.idleTimeout()),
 	}
+	s.server = srv
+	s.inflight.Resume()
 
 	s.running = true
+	s.serveErr = nil
 	s.mu.Unlock()
 
 	// 非阻塞启动
 	go func() {
-		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			// 记录错误但不阻塞
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			// 记录错误但不阻塞，通过 Err 暴露给服务器组
+			s.mu.Lock()
+			s.serveErr = err
+			s.mu.Unlock()
 		}
 	}()
 
@@ -96,6 +115,52 @@ func (s *HTTPServer) Stop(ctx context.Context) error {
 	return s.server.Shutdown(shutdownCtx)
 }
 
+// Err 返回运行期间 Serve 的异常退出错误，正常运行时为 nil
+func (s *HTTPServer) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.serveErr
+}
+
+// Drain 关闭 keep-alive，使客户端在后续请求中建立新连接（转向其他实例）
+func (s *HTTPServer) Drain(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.server != nil {
+		s.server.SetKeepAlivesEnabled(false)
+	}
+	return nil
+}
+
+// Quiesce 排空：关闭 keep-alive，新请求返回 503，并等待在途请求完成（实现 runtime.Quiescer）
+func (s *HTTPServer) Quiesce(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	if s.server != nil {
+		s.server.SetKeepAlivesEnabled(false)
+	}
+	s.mu.Unlock()
+	return s.inflight.Quiesce(ctx)
+}
+
+// InFlight 返回在途请求数
+func (s *HTTPServer) InFlight() int {
+	return s.inflight.Count()
+}
+
+// track 统计在途请求，排空期间拒绝新请求
+func (s *HTTPServer) track(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		done, err := s.inflight.Begin()
+		if err != nil {
+			w.Header().Set("Connection", "close")
+			http.Error(w, "server is draining", http.StatusServiceUnavailable)
+			return
+		}
+		defer done()
+		next.ServeHTTP(w, r)
+	})
+}
+
 // Handler 返回 HTTP Handler
 func (s *HTTPServer) Handler() http.Handler {
 	return s.handler
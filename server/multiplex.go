@@ -23,6 +23,7 @@ type MultiplexServer struct {
 	httpHandler http.Handler
 	mu         sync.Mutex
 	running    bool
+	stopped    bool
 	wg         sync.WaitGroup
 }
 
@@ -40,6 +41,9 @@ func NewMultiplexServer(httpHandler http.Handler, grpcServer *grpc.Server, opts
 // Name 服务器名称
 func (s *MultiplexServer) Name() string { return s.opts.Name }
 
+// PortName Kubernetes Service 端口名
+func (s *MultiplexServer) PortName() string { return s.opts.portName("http") }
+
 // Addr 监听地址
 func (s *MultiplexServer) Addr() string {
 	if s.listener != nil {
@@ -55,8 +59,13 @@ func (s *MultiplexServer) Start(ctx context.Context) error {
 		s.mu.Unlock()
 		return errors.New("server already running")
 	}
+	// 外部传入的 gRPC Server 停止后无法再次 Serve
+	if s.stopped {
+		s.mu.Unlock()
+		return ErrNotRestartable
+	}
 
-	listener, err := net.Listen("tcp", s.opts.Addr)
+	listener, err := listen(s.opts)
 	if err != nil {
 		s.mu.Unlock()
 		return err
@@ -64,14 +73,22 @@ func (s *MultiplexServer) Start(ctx context.Context) error {
 	s.listener = listener
 	s.mux = cmux.New(listener)
 
+	handler := s.httpHandler
+	if s.opts.GRPCWeb != nil {
+		handler = NewGRPCWebHandler(s.grpcServer, handler, *s.opts.GRPCWeb)
+	}
+
 	// HTTP/2 with H2C
 	h2s := &http2.Server{}
 	s.httpServer = &http.Server{
-		Handler:        h2c.NewHandler(s.httpHandler, h2s),
+		Handler:        h2c.NewHandler(handler, h2s),
 		ReadTimeout:    s.opts.ReadTimeout,
 		WriteTimeout:   s.opts.WriteTimeout,
 		IdleTimeout:    s.opts.IdleTimeout,
 		MaxHeaderBytes: s.opts.MaxHeaderBytes,
+
+		ReadHeaderTimeout: s.opts.ReadHeaderTimeout,
+		ConnState:         connStateHook(s.opts.Name, s.opts.ConnMetrics, s.opts.idleTimeout()),
 	}
 
 	s.running = true
@@ -115,6 +132,7 @@ func (s *MultiplexServer) Stop(ctx context.Context) error {
 		return nil
 	}
 	s.running = false
+	s.stopped = true
 	s.mu.Unlock()
 
 	// 优雅关闭 gRPC
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"net"
+	"strconv"
+
+	"github.com/mildsunup/higo/observability"
+)
+
+// PortNamer 提供 Kubernetes Service 端口名的服务器
+type PortNamer interface {
+	PortName() string
+}
+
+// ServicePort Kubernetes Service 端口描述，Name 可直接用作 ServiceMonitor endpoints[].port
+type ServicePort struct {
+	Name string `json:"name" yaml:"name"`
+	Port int    `json:"port" yaml:"port"`
+}
+
+// ServicePorts 返回服务器的端口描述（按注册顺序）；未实现 PortNamer 的服务器以 Name 推导端口名
+func ServicePorts(servers ...Server) []ServicePort {
+	ports := make([]ServicePort, 0, len(servers))
+	for _, s := range servers {
+		name := observability.PortName(s.Name())
+		if n, ok := s.(PortNamer); ok {
+			name = n.PortName()
+		}
+		ports = append(ports, ServicePort{Name: name, Port: addrPort(s.Addr())})
+	}
+	return ports
+}
+
+// Ports 返回组内服务器的端口描述
+func (g *Group) Ports() []ServicePort {
+	return ServicePorts(g.Servers()...)
+}
+
+// portName 显式配置优先，否则由服务器名称推导；名称为默认值时使用 fallback
+func (o Options) portName(fallback string) string {
+	if o.PortName != "" {
+		return o.PortName
+	}
+	if o.Name == "" || o.Name == DefaultOptions().Name {
+		return fallback
+	}
+	return observability.PortName(o.Name)
+}
+
+func addrPort(addr string) int {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(port)
+	return n
+}
@@ -4,6 +4,8 @@ package server
 import (
 	"context"
 	"time"
+
+	"github.com/mildsunup/higo/observability"
 )
 
 // Server 服务器接口
@@ -33,6 +35,14 @@ type Options struct {
 	IdleTimeout     time.Duration
 	ShutdownTimeout time.Duration
 	MaxHeaderBytes  int
+	GRPCWeb         *GRPCWebConfig // 非空时在 HTTP 端口上启用 grpc-web
+
+	ReadHeaderTimeout time.Duration // 读取请求头超时，防止慢速请求头（slowloris）占用连接
+	MaxConns          int           // 最大并发连接数，超出的连接直接关闭，0 不限制
+	MaxConnsPerIP     int           // 单 IP 最大并发连接数，0 不限制
+	ConnMetrics       *ConnMetrics  // 连接指标，nil 不采集
+
+	PortName string // Kubernetes Service 端口名（ServiceMonitor endpoints[].port），为空时由 Name 推导
 }
 
 // DefaultOptions 默认配置
@@ -45,6 +55,8 @@ func DefaultOptions() Options {
 		IdleTimeout:     120 * time.Second,
 		ShutdownTimeout: 10 * time.Second,
 		MaxHeaderBytes:  1 << 20, // 1MB
+
+		ReadHeaderTimeout: 10 * time.Second,
 	}
 }
 
@@ -71,6 +83,36 @@ func WithWriteTimeout(d time.Duration) Option {
 	return func(o *Options) { o.WriteTimeout = d }
 }
 
+// WithIdleTimeout 设置 keep-alive 空闲超时，超时的空闲连接被关闭
+func WithIdleTimeout(d time.Duration) Option {
+	return func(o *Options) { o.IdleTimeout = d }
+}
+
+// WithReadHeaderTimeout 设置读取请求头超时
+func WithReadHeaderTimeout(d time.Duration) Option {
+	return func(o *Options) { o.ReadHeaderTimeout = d }
+}
+
+// WithMaxConns 设置最大并发连接数
+func WithMaxConns(n int) Option {
+	return func(o *Options) { o.MaxConns = n }
+}
+
+// WithMaxConnsPerIP 设置单 IP 最大并发连接数
+func WithMaxConnsPerIP(n int) Option {
+	return func(o *Options) { o.MaxConnsPerIP = n }
+}
+
+// WithConnMetrics 设置连接指标（NewConnMetrics 创建，多个服务器共享）
+func WithConnMetrics(m *ConnMetrics) Option {
+	return func(o *Options) { o.ConnMetrics = m }
+}
+
+// WithPortName 设置 Kubernetes Service 端口名，按 IANA_SVC_NAME 规则规范化
+func WithPortName(name string) Option {
+	return func(o *Options) { o.PortName = observability.PortName(name) }
+}
+
 // WithShutdownTimeout 设置关闭超时
 func WithShutdownTimeout(d time.Duration) Option {
 	return func(o *Options) { o.ShutdownTimeout = d }
@@ -0,0 +1,31 @@
+// Package slo 提供进程内的 SLO 与错误预算追踪。
+//
+// 按路由或依赖声明可用性、延迟目标，在滚动窗口内统计好/坏事件并计算错误预算燃烧率：
+// 燃烧率 1 表示按当前错误率恰好在窗口结束时耗尽预算。预算耗尽（且事件数达到 MinEvents）时
+// 通过 OnChange 回调与 Exhausted/Allow 发出信号，供降级逻辑或功能开关自动关闭高成本功能。
+//
+//	tracker := slo.New(slo.WithMetrics(provider))
+//	_ = tracker.Register(
+//	    slo.Objective{Name: "api-availability", Kind: slo.KindAvailability, Match: "*", Target: 0.999},
+//	    slo.Objective{Name: "search-latency", Kind: slo.KindLatency, Match: "GET /search", Target: 0.99, Threshold: 300 * time.Millisecond},
+//	    slo.Objective{Name: "mysql", Kind: slo.KindAvailability, Match: "mysql", Target: 0.999, Window: 30 * time.Minute},
+//	)
+//
+//	// 由 HTTP 指标中间件喂入请求事件
+//	router.Use(httpmw.NewMetrics(provider).OnRequest(tracker.ObserveHTTP).Middleware())
+//
+//	// 依赖调用手动记录
+//	start := time.Now()
+//	err := repo.Save(ctx, order)
+//	tracker.Observe("mysql", err, time.Since(start))
+//
+//	// 预算耗尽时关闭高成本功能
+//	if tracker.Allow("search-latency") {
+//	    results = rerank(results)
+//	}
+//
+// 指标：slo_burn_rate{slo,window=long|short}、slo_error_budget_remaining{slo}、
+// slo_budget_exhausted{slo}、slo_events_total{slo,result}。Handler 提供状态查询接口，可挂载到管理服务。
+//
+// 状态为单实例视角；多实例的全局预算应基于 slo_events_total 在 Prometheus 中聚合计算。
+package slo
@@ -0,0 +1,28 @@
+package slo
+
+import (
+	"net/http"
+
+	"github.com/mildsunup/higo/errors"
+	"github.com/mildsunup/higo/response"
+)
+
+// Handler 返回 SLO 状态接口，挂载时需去掉前缀：
+//
+//	GET /         列出所有 SLO 状态
+//	GET /{name}   查看单个 SLO 状态
+func (t *Tracker) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, _ *http.Request) {
+		response.WriteJSON(w, http.StatusOK, t.Statuses())
+	})
+	mux.HandleFunc("GET /{name}", func(w http.ResponseWriter, r *http.Request) {
+		s, err := t.Status(r.PathValue("name"))
+		if errors.Is(err, ErrNotFound) {
+			response.WriteError(w, r, errors.Wrap(err, errors.NotFound, err.Error()))
+			return
+		}
+		response.WriteJSON(w, http.StatusOK, s)
+	})
+	return mux
+}
@@ -0,0 +1,318 @@
+package slo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mildsunup/higo/observability"
+)
+
+// ErrNotFound SLO 不存在
+var ErrNotFound = errors.New("slo: not found")
+
+// Kind SLO 类型
+type Kind string
+
+const (
+	// KindAvailability 可用性：失败（错误或 5xx）计为坏事件
+	KindAvailability Kind = "availability"
+	// KindLatency 延迟：耗时超过 Threshold 计为坏事件
+	KindLatency Kind = "latency"
+)
+
+// Objective SLO 声明
+type Objective struct {
+	Name string
+	Kind Kind
+	// Match 匹配的事件键：路由（"GET /users/:id"）、路径（"/users/:id"，匹配任意方法）、
+	// 依赖名（"mysql"），"*" 匹配全部事件
+	Match string
+	// Target 目标比例，如 0.999；错误预算为 1-Target
+	Target float64
+	// Threshold 延迟阈值（KindLatency）
+	Threshold time.Duration
+	// Window 预算窗口，默认 1h
+	Window time.Duration
+	// ShortWindow 短窗口燃烧率，用于快速发现突发消耗，默认 Window/12
+	ShortWindow time.Duration
+	// MinEvents 窗口内事件数达到该值才判定预算耗尽，避免低流量误判，默认 100
+	MinEvents int64
+}
+
+func (o Objective) matches(key string) bool {
+	if o.Match == "*" || o.Match == key {
+		return true
+	}
+	return strings.HasPrefix(o.Match, "/") && strings.HasSuffix(key, " "+o.Match)
+}
+
+// Status SLO 当前状态
+type Status struct {
+	Name            string  `json:"name"`
+	Kind            Kind    `json:"kind"`
+	Target          float64 `json:"target"`
+	Good            int64   `json:"good"`
+	Bad             int64   `json:"bad"`
+	ErrorRatio      float64 `json:"error_ratio"`
+	BurnRate        float64 `json:"burn_rate"`        // 窗口内燃烧率，1 表示恰好在窗口结束时耗尽预算
+	ShortBurnRate   float64 `json:"short_burn_rate"`  // 短窗口燃烧率
+	BudgetRemaining float64 `json:"budget_remaining"` // 剩余预算比例，≤0 表示耗尽
+	Exhausted       bool    `json:"exhausted"`
+}
+
+type objective struct {
+	Objective
+	win       *window
+	status    Status
+	evaluated time.Time
+}
+
+// Tracker 进程内错误预算追踪
+type Tracker struct {
+	mu         sync.Mutex
+	objectives []*objective
+	listeners  []func(Status)
+	interval   time.Duration
+	metrics    *metrics
+	now        func() time.Time
+}
+
+// Option 追踪器选项
+type Option func(*Tracker)
+
+// WithMetrics 导出 slo_burn_rate、slo_error_budget_remaining、slo_budget_exhausted 与 slo_events_total 指标
+func WithMetrics(p observability.MetricsProvider) Option {
+	return func(t *Tracker) {
+		t.metrics = newMetrics(p)
+	}
+}
+
+// WithEvaluateInterval 设置状态重新计算的最小间隔，默认 1s
+func WithEvaluateInterval(d time.Duration) Option {
+	return func(t *Tracker) {
+		if d > 0 {
+			t.interval = d
+		}
+	}
+}
+
+// New 创建追踪器
+func New(opts ...Option) *Tracker {
+	t := &Tracker{
+		interval: time.Second,
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Register 声明 SLO
+func (t *Tracker) Register(objs ...Objective) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, o := range objs {
+		if o.Name == "" || o.Match == "" {
+			return fmt.Errorf("slo: name and match are required")
+		}
+		if o.Target <= 0 || o.Target >= 1 {
+			return fmt.Errorf("slo: %s: target must be in (0, 1)", o.Name)
+		}
+		switch o.Kind {
+		case KindAvailability:
+		case KindLatency:
+			if o.Threshold <= 0 {
+				return fmt.Errorf("slo: %s: latency objective requires threshold", o.Name)
+			}
+		default:
+			return fmt.Errorf("slo: %s: unknown kind %q", o.Name, o.Kind)
+		}
+		if t.find(o.Name) != nil {
+			return fmt.Errorf("slo: %s: already registered", o.Name)
+		}
+		if o.Window <= 0 {
+			o.Window = time.Hour
+		}
+		if o.ShortWindow <= 0 || o.ShortWindow > o.Window {
+			o.ShortWindow = o.Window / 12
+		}
+		if o.MinEvents <= 0 {
+			o.MinEvents = 100
+		}
+		obj := &objective{Objective: o, win: newWindow(o.Window, 60, t.now())}
+		obj.status = Status{Name: o.Name, Kind: o.Kind, Target: o.Target, BudgetRemaining: 1}
+		t.objectives = append(t.objectives, obj)
+	}
+	return nil
+}
+
+// OnChange 注册预算耗尽/恢复的回调，用于降级或关闭高成本功能；回调在记录事件的协程中同步执行
+//
+//	tracker.OnChange(func(s slo.Status) {
+//	    flags.Set("recommendations", !s.Exhausted)
+//	})
+func (t *Tracker) OnChange(fn func(Status)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.listeners = append(t.listeners, fn)
+}
+
+// Observe 记录事件：key 为路由或依赖名，err 非空计为失败
+func (t *Tracker) Observe(key string, err error, elapsed time.Duration) {
+	t.record(key, err != nil, elapsed)
+}
+
+// ObserveHTTP 记录 HTTP 请求，5xx 计为失败；签名与 HTTP 指标中间件的观察者一致
+//
+//	metrics := httpmw.NewMetrics(provider).OnRequest(tracker.ObserveHTTP)
+func (t *Tracker) ObserveHTTP(method, path string, status int, elapsed time.Duration) {
+	t.record(method+" "+path, status >= 500, elapsed)
+}
+
+func (t *Tracker) record(key string, failed bool, elapsed time.Duration) {
+	now := t.now()
+	var changed []Status
+	t.mu.Lock()
+	for _, o := range t.objectives {
+		if !o.matches(key) {
+			continue
+		}
+		good := !failed
+		if o.Kind == KindLatency {
+			good = elapsed <= o.Threshold
+		}
+		o.win.add(now, good)
+		if t.metrics != nil {
+			t.metrics.observe(o.Name, good)
+		}
+		if s, ok := t.evaluate(o, now, false); ok {
+			changed = append(changed, s)
+		}
+	}
+	listeners := t.listeners
+	t.mu.Unlock()
+
+	for _, s := range changed {
+		for _, fn := range listeners {
+			fn(s)
+		}
+	}
+}
+
+// evaluate 重新计算状态（距上次计算不足 interval 且非 force 时跳过），返回耗尽状态是否变化
+func (t *Tracker) evaluate(o *objective, now time.Time, force bool) (Status, bool) {
+	if !force && now.Sub(o.evaluated) < t.interval {
+		return o.status, false
+	}
+	o.evaluated = now
+
+	budget := 1 - o.Target
+	good, bad := o.win.sum(now, 0)
+	s := Status{Name: o.Name, Kind: o.Kind, Target: o.Target, Good: good, Bad: bad, BudgetRemaining: 1}
+	if total := good + bad; total > 0 {
+		s.ErrorRatio = float64(bad) / float64(total)
+		s.BurnRate = s.ErrorRatio / budget
+		s.BudgetRemaining = 1 - s.BurnRate
+		s.Exhausted = total >= o.MinEvents && s.BudgetRemaining <= 0
+	}
+	if sg, sb := o.win.sum(now, o.ShortWindow); sg+sb > 0 {
+		s.ShortBurnRate = float64(sb) / float64(sg+sb) / budget
+	}
+
+	changed := s.Exhausted != o.status.Exhausted
+	o.status = s
+	if t.metrics != nil {
+		t.metrics.set(s)
+	}
+	return s, changed
+}
+
+// Status 返回 SLO 状态
+func (t *Tracker) Status(name string) (Status, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	o := t.find(name)
+	if o == nil {
+		return Status{}, ErrNotFound
+	}
+	s, _ := t.evaluate(o, t.now(), false)
+	return s, nil
+}
+
+// Statuses 返回所有 SLO 状态
+func (t *Tracker) Statuses() []Status {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	list := make([]Status, 0, len(t.objectives))
+	for _, o := range t.objectives {
+		s, _ := t.evaluate(o, now, false)
+		list = append(list, s)
+	}
+	return list
+}
+
+// Exhausted 错误预算是否耗尽，未声明的 SLO 返回 false
+func (t *Tracker) Exhausted(name string) bool {
+	s, err := t.Status(name)
+	return err == nil && s.Exhausted
+}
+
+// Allow 预算未耗尽时返回 true，用于在预算耗尽时自动关闭高成本功能
+//
+//	if tracker.Allow("search-latency") {
+//	    results = rerank(results)
+//	}
+func (t *Tracker) Allow(name string) bool {
+	return !t.Exhausted(name)
+}
+
+func (t *Tracker) find(name string) *objective {
+	for _, o := range t.objectives {
+		if o.Name == name {
+			return o
+		}
+	}
+	return nil
+}
+
+// --- 指标 ---
+
+type metrics struct {
+	burnRate  observability.Gauge
+	remaining observability.Gauge
+	exhausted observability.Gauge
+	events    observability.Counter
+}
+
+func newMetrics(p observability.MetricsProvider) *metrics {
+	return &metrics{
+		burnRate:  p.Gauge("slo_burn_rate", "SLO error budget burn rate", "slo", "window"),
+		remaining: p.Gauge("slo_error_budget_remaining", "Remaining SLO error budget ratio", "slo"),
+		exhausted: p.Gauge("slo_budget_exhausted", "Whether the SLO error budget is exhausted (1) or not (0)", "slo"),
+		events:    p.Counter("slo_events_total", "SLO events by result", "slo", "result"),
+	}
+}
+
+func (m *metrics) observe(name string, good bool) {
+	result := "good"
+	if !good {
+		result = "bad"
+	}
+	m.events.Inc(name, result)
+}
+
+func (m *metrics) set(s Status) {
+	m.burnRate.Set(s.BurnRate, s.Name, "long")
+	m.burnRate.Set(s.ShortBurnRate, s.Name, "short")
+	m.remaining.Set(s.BudgetRemaining, s.Name)
+	exhausted := 0.0
+	if s.Exhausted {
+		exhausted = 1
+	}
+	m.exhausted.Set(exhausted, s.Name)
+}
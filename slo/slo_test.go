@@ -0,0 +1,101 @@
+package slo
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time      { return c.t }
+func (c *clock) add(d time.Duration) { c.t = c.t.Add(d) }
+func newClock() *clock               { return &clock{t: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)} }
+func newTracker(c *clock) *Tracker {
+	t := New()
+	t.now, t.interval = c.now, 0
+	return t
+}
+func approx(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func TestTracker_Availability(t *testing.T) {
+	c := newClock()
+	tr := newTracker(c)
+	if err := tr.Register(Objective{Name: "api", Kind: KindAvailability, Match: "/orders", Target: 0.9, MinEvents: 10}); err != nil {
+		t.Fatal(err)
+	}
+	var changes []Status
+	tr.OnChange(func(s Status) { changes = append(changes, s) })
+
+	for range 95 {
+		tr.ObserveHTTP("GET", "/orders", 200, time.Millisecond)
+	}
+	tr.ObserveHTTP("GET", "/users", 500, time.Millisecond) // 不匹配
+	for range 5 {
+		tr.ObserveHTTP("POST", "/orders", 503, time.Millisecond)
+	}
+	s, _ := tr.Status("api")
+	if s.Good != 95 || s.Bad != 5 || !approx(s.BurnRate, 0.5) || !approx(s.BudgetRemaining, 0.5) || s.Exhausted {
+		t.Fatalf("status = %+v", s)
+	}
+	if !tr.Allow("api") {
+		t.Error("Allow() = false with budget remaining")
+	}
+
+	for range 10 {
+		tr.Observe("GET /orders", errors.New("boom"), 0)
+	}
+	if !tr.Exhausted("api") || len(changes) != 1 || !changes[0].Exhausted {
+		t.Fatalf("exhausted = %v, changes = %+v", tr.Exhausted("api"), changes)
+	}
+
+	// 窗口滑过后预算恢复
+	c.add(2 * time.Hour)
+	tr.ObserveHTTP("GET", "/orders", 200, time.Millisecond)
+	if tr.Exhausted("api") || len(changes) != 2 || changes[1].Exhausted {
+		t.Fatalf("after window: exhausted = %v, changes = %+v", tr.Exhausted("api"), changes)
+	}
+}
+
+func TestTracker_LatencyShortWindow(t *testing.T) {
+	c := newClock()
+	tr := newTracker(c)
+	err := tr.Register(Objective{Name: "search", Kind: KindLatency, Match: "GET /search", Target: 0.5, Threshold: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr.ObserveHTTP("GET", "/search", 200, 50*time.Millisecond)
+	c.add(30 * time.Minute)
+	tr.ObserveHTTP("GET", "/search", 200, 200*time.Millisecond)
+
+	s, _ := tr.Status("search")
+	if s.Good != 1 || s.Bad != 1 || !approx(s.BurnRate, 1) || !approx(s.ShortBurnRate, 2) {
+		t.Errorf("status = %+v", s)
+	}
+	if s.Exhausted {
+		t.Error("should not be exhausted below MinEvents")
+	}
+}
+
+func TestTracker_Register(t *testing.T) {
+	tr := New()
+	bad := []Objective{
+		{Name: "a", Kind: KindAvailability, Match: "*", Target: 1},
+		{Name: "b", Kind: KindLatency, Match: "*", Target: 0.9},
+		{Name: "c", Kind: "other", Match: "*", Target: 0.9},
+		{Kind: KindAvailability, Match: "*", Target: 0.9},
+	}
+	for _, o := range bad {
+		if err := tr.Register(o); err == nil {
+			t.Errorf("Register(%+v) should fail", o)
+		}
+	}
+	_ = tr.Register(Objective{Name: "d", Kind: KindAvailability, Match: "*", Target: 0.9})
+	if err := tr.Register(Objective{Name: "d", Kind: KindAvailability, Match: "*", Target: 0.9}); err == nil {
+		t.Error("duplicate Register() should fail")
+	}
+	if _, err := tr.Status("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Status(missing) error = %v", err)
+	}
+}
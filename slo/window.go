@@ -0,0 +1,64 @@
+package slo
+
+import "time"
+
+// window 滚动窗口内的好/坏事件计数，按固定跨度分桶
+type window struct {
+	good, bad []int64
+	span      time.Duration
+	head      int
+	headStart time.Time
+}
+
+func newWindow(size time.Duration, buckets int, now time.Time) *window {
+	span := size / time.Duration(buckets)
+	if span <= 0 {
+		span = time.Second
+	}
+	return &window{
+		good:      make([]int64, buckets),
+		bad:       make([]int64, buckets),
+		span:      span,
+		headStart: now.Truncate(span),
+	}
+}
+
+// rotate 推进到 now 所在的桶，清空过期桶
+func (w *window) rotate(now time.Time) {
+	steps := int(now.Sub(w.headStart) / w.span)
+	if steps <= 0 {
+		return
+	}
+	n := len(w.good)
+	for i := range min(steps, n) {
+		idx := (w.head + 1 + i) % n
+		w.good[idx], w.bad[idx] = 0, 0
+	}
+	w.head = (w.head + steps) % n
+	w.headStart = w.headStart.Add(time.Duration(steps) * w.span)
+}
+
+func (w *window) add(now time.Time, good bool) {
+	w.rotate(now)
+	if good {
+		w.good[w.head]++
+	} else {
+		w.bad[w.head]++
+	}
+}
+
+// sum 返回最近 d 内的好/坏事件数（d 向上取整到桶，最多整个窗口）
+func (w *window) sum(now time.Time, d time.Duration) (good, bad int64) {
+	w.rotate(now)
+	n := len(w.good)
+	k := n
+	if d > 0 {
+		k = min(n, int((d+w.span-1)/w.span))
+	}
+	for i := range k {
+		idx := (w.head - i + n) % n
+		good += w.good[idx]
+		bad += w.bad[idx]
+	}
+	return good, bad
+}
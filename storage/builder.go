@@ -1,7 +1,12 @@
 package storage
 
 import (
+	"time"
+
 	"go.opentelemetry.io/otel/trace"
+
+	"github.com/mildsunup/higo/cache"
+	"github.com/mildsunup/higo/observability"
 )
 
 // Builder 存储构建器
@@ -10,6 +15,8 @@ type Builder struct {
 	reconnect *ReconnectConfig
 	tracer    trace.Tracer
 	metrics   *Metrics
+	stats     observability.MetricsProvider
+	cache     *QueryCache
 }
 
 // NewBuilder 创建构建器
@@ -29,16 +36,46 @@ func (b *Builder) WithTracing(tracer trace.Tracer) *Builder {
 	return b
 }
 
-// WithMetrics 启用指标
+// WithMetrics 启用指标：连接、Ping、关闭的操作指标，以及支持的存储（如 MySQL）按查询名称与操作类型的查询指标
 func (b *Builder) WithMetrics(m *Metrics) *Builder {
 	b.metrics = m
 	return b
 }
 
+// WithStatsMetrics 将状态与连接池统计发布到指标提供者
+func (b *Builder) WithStatsMetrics(p observability.MetricsProvider) *Builder {
+	b.stats = p
+	return b
+}
+
+// WithCache 启用查询结果缓存，构建后通过 QueryCacheOf 获取，用于 Cached 与 NewCachedRepository；
+// keyFn 为 nil 时使用 DefaultCacheKey
+func (b *Builder) WithCache(c cache.Cache, keyFn CacheKeyFunc, ttl time.Duration) *Builder {
+	b.cache = NewQueryCache(c, keyFn, ttl)
+	return b
+}
+
 // Build 构建最终存储（装饰器顺序：Reconnectable -> Traced -> Metriced）
 func (b *Builder) Build() Storage {
 	s := b.storage
 
+	if b.stats != nil {
+		if sp, ok := s.(interface {
+			SetMetricsProvider(observability.MetricsProvider)
+		}); ok {
+			sp.SetMetricsProvider(b.stats)
+		}
+	}
+	if b.metrics != nil {
+		if sp, ok := s.(interface{ SetOperationMetrics(*Metrics) }); ok {
+			sp.SetOperationMetrics(b.metrics)
+		}
+	}
+	if b.cache != nil {
+		if sp, ok := s.(interface{ SetQueryCache(*QueryCache) }); ok {
+			sp.SetQueryCache(b.cache)
+		}
+	}
 	if b.reconnect != nil {
 		s = NewReconnectable(s, *b.reconnect)
 	}
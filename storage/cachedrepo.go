@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/mildsunup/higo/ddd"
+)
+
+// CachedRepository 带查询结果缓存的仓储装饰器。
+// FindByID、Exists 走缓存，Save、Delete 成功后失效实体标签（name:id）与集合标签（name）；
+// 自定义列表查询以 name 为标签调用 Cached 即可随写入失效。
+// 实体未实现 GetID() ID 时无法从 Save 获取 ID，按 ID 查询也关联集合标签。
+//
+//	qc := storage.QueryCacheOf(db)
+//	repo := storage.NewCachedRepository[Order, ddd.Int64ID](mysql.NewRepository[Order, ddd.Int64ID](db.DB()), qc, "orders")
+type CachedRepository[T any, ID ddd.Identifier] struct {
+	repo  ddd.Repository[T, ID]
+	cache *QueryCache
+	name  string
+	hasID bool
+}
+
+// NewCachedRepository 创建带查询结果缓存的仓储，name 作为查询名称前缀与集合标签
+func NewCachedRepository[T any, ID ddd.Identifier](repo ddd.Repository[T, ID], qc *QueryCache, name string) *CachedRepository[T, ID] {
+	_, hasID := any(new(T)).(interface{ GetID() ID })
+	return &CachedRepository[T, ID]{repo: repo, cache: qc, name: name, hasID: hasID}
+}
+
+// Unwrap 返回被装饰的仓储
+func (r *CachedRepository[T, ID]) Unwrap() ddd.Repository[T, ID] { return r.repo }
+
+// FindByID 根据 ID 查找，未找到等错误不缓存
+func (r *CachedRepository[T, ID]) FindByID(ctx context.Context, id ID) (*T, error) {
+	return Cached(ctx, r.cache, r.name+".find_by_id", id.String(), r.tags(id), func(ctx context.Context) (*T, error) {
+		return r.repo.FindByID(ctx, id)
+	})
+}
+
+// Exists 判断是否存在
+func (r *CachedRepository[T, ID]) Exists(ctx context.Context, id ID) (bool, error) {
+	return Cached(ctx, r.cache, r.name+".exists", id.String(), r.tags(id), func(ctx context.Context) (bool, error) {
+		return r.repo.Exists(ctx, id)
+	})
+}
+
+// Save 保存，成功后失效相关缓存
+func (r *CachedRepository[T, ID]) Save(ctx context.Context, entity *T) error {
+	if err := r.repo.Save(ctx, entity); err != nil {
+		return err
+	}
+	tags := []string{r.name}
+	if e, ok := any(entity).(interface{ GetID() ID }); ok {
+		tags = append(tags, r.entityTag(e.GetID()))
+	}
+	r.invalidate(ctx, tags...)
+	return nil
+}
+
+// Delete 删除，成功后失效相关缓存
+func (r *CachedRepository[T, ID]) Delete(ctx context.Context, id ID) error {
+	if err := r.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.invalidate(ctx, r.name, r.entityTag(id))
+	return nil
+}
+
+func (r *CachedRepository[T, ID]) tags(id ID) []string {
+	if r.hasID {
+		return []string{r.entityTag(id)}
+	}
+	return []string{r.name, r.entityTag(id)}
+}
+
+func (r *CachedRepository[T, ID]) entityTag(id ID) string {
+	return r.name + ":" + id.String()
+}
+
+// invalidate 失效失败时结果会在 TTL 后过期，不影响写入结果
+func (r *CachedRepository[T, ID]) invalidate(ctx context.Context, tags ...string) {
+	if r.cache != nil {
+		_ = r.cache.Invalidate(ctx, tags...)
+	}
+}
+
+var _ ddd.Repository[struct{}, ddd.Int64ID] = (*CachedRepository[struct{}, ddd.Int64ID])(nil)
@@ -110,7 +110,22 @@ func (s *Storage) Close(ctx context.Context) error {
 	return err
 }
 
+// ServerVersion 返回 ClickHouse 服务端版本
+func (s *Storage) ServerVersion(ctx context.Context) (string, error) {
+	if s.conn == nil {
+		return "", fmt.Errorf("clickhouse: not connected")
+	}
+	v, err := s.conn.ServerVersion()
+	if err != nil {
+		return "", fmt.Errorf("clickhouse: server version: %w", err)
+	}
+	return v.Version.String(), nil
+}
+
 // Conn 返回 ClickHouse 连接
 func (s *Storage) Conn() driver.Conn { return s.conn }
 
-var _ storage.Storage = (*Storage)(nil)
+var (
+	_ storage.Storage         = (*Storage)(nil)
+	_ storage.VersionProvider = (*Storage)(nil)
+)
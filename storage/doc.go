@@ -6,11 +6,25 @@
 //   - MongoDB
 //   - ClickHouse
 //   - Elasticsearch
+//   - 嵌入式键值存储（embedded，基于 bbolt，单节点边缘部署，无外部服务依赖）
+//   - SQLite（sqlite，本地开发、测试与嵌入式部署：内存模式，或 WAL 文件模式并兼容 Litestream 复制）
 //
 // 核心功能：
 //   - 统一的存储接口
 //   - 连接池管理、健康检查、重连机制
+//   - 健康监控（NewManager(WithMonitor(...))）：连续 Ping 失败标记降级，指数退避后台重连，状态变更回调与指标
+//   - 连接池饱和检测（NewManager(WithSaturation(...))）：按等待次数与平均等待时长判定连接池耗尽，告警回调与指标，可在上限内自动上调 MaxOpenConns
+//   - 读取偏好：配置只读副本时，UsePrimary/UseReplica 按 context 指定读主库或副本（MySQL）
 //   - 链路追踪和指标采集
+//   - 状态与连接池统计自动发布到 MetricsProvider，StatsSampler 按消费方计算每秒速率
+//   - 查询指标：按操作类型（query/exec/tx）与查询名称（WithQueryName，MySQL 未命名时取表名）记录耗时与错误
+//   - 后端迁移：migrate 子包提供按阶段切换的双读双写仓储装饰器
+//   - 原生 SQL：rawsql 子包提供从 .sql 文件加载的命名参数化查询，泛型扫描、按查询名称的指标与追踪
+//   - 分表/分库：mysql.Config.Sharding 配置分片键、分片数量与路由算法（mod/hash/自定义），
+//     ShardingPlugin 按条件或模型中的分片键改写表名，MigrateShards 创建全部分片表
+//   - 查询结果缓存：Builder.WithCache 配置 cache-aside 缓存，Cached 按标签缓存读取结果，写入后 Invalidate 标签失效；
+//     NewCachedRepository 为仓储的 FindByID/Exists 提供缓存并在 Save/Delete 后自动失效
+//   - MongoDB：WithSession 多文档事务，Collection[T] 泛型集合（CRUD、索引、游标流式遍历，每次操作生成追踪 span）
 //
 // 使用示例：
 //
@@ -0,0 +1,320 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mildsunup/higo/resilience"
+)
+
+// errBulkClosed 写入器已关闭
+var errBulkClosed = errors.New("elasticsearch: bulk indexer closed")
+
+// 批量操作类型
+const (
+	BulkIndex  = "index"
+	BulkCreate = "create"
+	BulkUpdate = "update"
+	BulkDelete = "delete"
+)
+
+// BulkItem 批量写入的单个操作
+type BulkItem struct {
+	Action string // 默认 BulkIndex
+	Index  string // 为空时使用 WithBulkIndex 设置的默认索引
+	ID     string
+	Doc    any // index/create 为文档，update 为更新体（如 {"doc": ...}），delete 忽略
+}
+
+// BulkStats 批量写入统计
+type BulkStats struct {
+	Succeeded int64
+	Failed    int64
+	Retried   int64 // 因 429 重试的操作数
+	Flushes   int64
+}
+
+// bulkConfig 批量写入配置
+type bulkConfig struct {
+	index         string
+	flushItems    int
+	flushBytes    int
+	flushInterval time.Duration
+	retry         []resilience.RetryOption
+	onError       func(item BulkItem, err error)
+}
+
+// BulkOption 批量写入选项
+type BulkOption func(*bulkConfig)
+
+// WithBulkIndex 设置默认索引
+func WithBulkIndex(index string) BulkOption {
+	return func(c *bulkConfig) { c.index = index }
+}
+
+// WithFlushItems 缓冲操作数达到 n 时刷新，默认 1000
+func WithFlushItems(n int) BulkOption {
+	return func(c *bulkConfig) { c.flushItems = n }
+}
+
+// WithFlushBytes 缓冲请求体达到 n 字节时刷新，默认 5MiB
+func WithFlushBytes(n int) BulkOption {
+	return func(c *bulkConfig) { c.flushBytes = n }
+}
+
+// WithFlushInterval 设置后台定时刷新间隔，默认 1 秒，0 表示不定时刷新
+func WithFlushInterval(d time.Duration) BulkOption {
+	return func(c *bulkConfig) { c.flushInterval = d }
+}
+
+// WithBulkRetry 设置 429 重试策略（默认 5 次，初始 200ms 指数退避），仅对被拒绝的操作重试
+func WithBulkRetry(opts ...resilience.RetryOption) BulkOption {
+	return func(c *bulkConfig) { c.retry = append(c.retry, opts...) }
+}
+
+// WithBulkOnError 设置操作失败回调（映射错误、版本冲突、重试耗尽等），默认丢弃
+func WithBulkOnError(fn func(item BulkItem, err error)) BulkOption {
+	return func(c *bulkConfig) { c.onError = fn }
+}
+
+type bulkEntry struct {
+	item BulkItem
+	body []byte // 操作行与文档行
+}
+
+// BulkIndexer 批量写入器，并发安全。
+// 按条数、字节数或时间间隔刷新；429 时仅重试被拒绝的操作；实现 runtime.Flusher，可在停机时刷新剩余数据
+type BulkIndexer struct {
+	s     *Storage
+	cfg   bulkConfig
+	retry *resilience.Retry
+
+	mu      sync.Mutex
+	entries []bulkEntry
+	size    int
+
+	flushing atomic.Int64 // 正在发送的操作数
+	stats    struct{ succeeded, failed, retried, flushes atomic.Int64 }
+
+	closed atomic.Bool
+	stop   chan struct{}
+	done   chan struct{}
+	once   sync.Once
+}
+
+// NewBulkIndexer 创建批量写入器，使用完毕后需调用 Close
+func (s *Storage) NewBulkIndexer(opts ...BulkOption) *BulkIndexer {
+	cfg := bulkConfig{
+		flushItems:    1000,
+		flushBytes:    5 << 20,
+		flushInterval: time.Second,
+		retry: []resilience.RetryOption{
+			resilience.WithMaxAttempts(5),
+			resilience.WithDelay(200 * time.Millisecond),
+			resilience.WithJitter(0.2),
+		},
+		onError: func(BulkItem, error) {},
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	b := &BulkIndexer{
+		s:     s,
+		cfg:   cfg,
+		retry: resilience.NewRetry(append(cfg.retry, resilience.WithRetryIf(IsTooManyRequests))...),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	if cfg.flushInterval > 0 {
+		go b.loop()
+	} else {
+		close(b.done)
+	}
+	return b
+}
+
+func (b *BulkIndexer) loop() {
+	defer close(b.done)
+	ticker := time.NewTicker(b.cfg.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+			_ = b.Flush(context.Background())
+		}
+	}
+}
+
+// Add 添加操作，达到刷新阈值时在当前 goroutine 内同步刷新
+func (b *BulkIndexer) Add(ctx context.Context, item BulkItem) error {
+	if b.closed.Load() {
+		return errBulkClosed
+	}
+	if item.Action == "" {
+		item.Action = BulkIndex
+	}
+	if item.Index == "" {
+		item.Index = b.cfg.index
+	}
+	body, err := encodeBulk(item)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	b.entries = append(b.entries, bulkEntry{item: item, body: body})
+	b.size += len(body)
+	full := len(b.entries) >= b.cfg.flushItems || b.size >= b.cfg.flushBytes
+	b.mu.Unlock()
+
+	if full {
+		return b.Flush(ctx)
+	}
+	return nil
+}
+
+func encodeBulk(item BulkItem) ([]byte, error) {
+	meta := map[string]any{}
+	if item.Index != "" {
+		meta["_index"] = item.Index
+	}
+	if item.ID != "" {
+		meta["_id"] = item.ID
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	if err := enc.Encode(map[string]any{item.Action: meta}); err != nil {
+		return nil, fmt.Errorf("elasticsearch: encode bulk action: %w", err)
+	}
+	if item.Action != BulkDelete {
+		if err := enc.Encode(item.Doc); err != nil {
+			return nil, fmt.Errorf("elasticsearch: encode bulk document %s: %w", item.ID, err)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// Flush 发送缓冲中的全部操作。请求级错误（连接失败、重试耗尽）时返回错误并回调每个操作；
+// 单个操作的失败仅通过 WithBulkOnError 回调与统计报告
+func (b *BulkIndexer) Flush(ctx context.Context) error {
+	b.mu.Lock()
+	entries := b.entries
+	b.entries, b.size = nil, 0
+	b.mu.Unlock()
+	if len(entries) == 0 {
+		return nil
+	}
+
+	b.flushing.Add(int64(len(entries)))
+	defer b.flushing.Add(-int64(len(entries)))
+	b.stats.flushes.Add(1)
+
+	attempt := 0
+	err := b.retry.Execute(ctx, func(ctx context.Context) error {
+		if attempt > 0 {
+			b.stats.retried.Add(int64(len(entries)))
+		}
+		attempt++
+		var err error
+		entries, err = b.send(ctx, entries)
+		return err
+	})
+	if err != nil {
+		for _, e := range entries {
+			b.cfg.onError(e.item, err)
+		}
+		b.stats.failed.Add(int64(len(entries)))
+		return err
+	}
+	return nil
+}
+
+// send 发送一次批量请求，返回需重试的操作与错误（存在 429 时为 ResponseError）
+func (b *BulkIndexer) send(ctx context.Context, entries []bulkEntry) ([]bulkEntry, error) {
+	if b.s.client == nil {
+		return entries, ErrNotConnected
+	}
+	var body bytes.Buffer
+	for _, e := range entries {
+		body.Write(e.body)
+	}
+	var resp struct {
+		Errors bool                          `json:"errors"`
+		Items  []map[string]bulkItemResponse `json:"items"`
+	}
+	res, err := b.s.client.Bulk(&body, b.s.client.Bulk.WithContext(ctx))
+	err = decode(res, err, "bulk", &resp)
+	if err != nil {
+		return entries, err
+	}
+
+	var retry []bulkEntry
+	for i, item := range resp.Items {
+		if i >= len(entries) {
+			break
+		}
+		for _, r := range item {
+			switch {
+			case r.Status == http.StatusTooManyRequests:
+				retry = append(retry, entries[i])
+			case r.Status >= 300:
+				b.stats.failed.Add(1)
+				b.cfg.onError(entries[i].item, &ResponseError{Status: r.Status, Type: r.Error.Type, Reason: r.Error.Reason})
+			default:
+				b.stats.succeeded.Add(1)
+			}
+		}
+	}
+	if len(retry) > 0 {
+		return retry, fmt.Errorf("bulk: %d items rejected: %w", len(retry), &ResponseError{Status: http.StatusTooManyRequests, Type: "es_rejected_execution_exception"})
+	}
+	return nil, nil
+}
+
+type bulkItemResponse struct {
+	Status int `json:"status"`
+	Error  struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error"`
+}
+
+// Pending 返回尚未确认的操作数（缓冲中与发送中）
+func (b *BulkIndexer) Pending() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.entries) + int(b.flushing.Load())
+}
+
+// Stats 返回统计
+func (b *BulkIndexer) Stats() BulkStats {
+	return BulkStats{
+		Succeeded: b.stats.succeeded.Load(),
+		Failed:    b.stats.failed.Load(),
+		Retried:   b.stats.retried.Load(),
+		Flushes:   b.stats.flushes.Load(),
+	}
+}
+
+// Close 停止定时刷新并刷新剩余操作
+func (b *BulkIndexer) Close(ctx context.Context) error {
+	b.once.Do(func() {
+		b.closed.Store(true)
+		close(b.stop)
+	})
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return b.Flush(ctx)
+}
@@ -2,6 +2,7 @@ package elasticsearch
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"github.com/elastic/go-elasticsearch/v8"
@@ -106,7 +107,35 @@ func (s *Storage) Close(ctx context.Context) error {
 	return nil
 }
 
+// ServerVersion 返回 Elasticsearch 集群版本
+func (s *Storage) ServerVersion(ctx context.Context) (string, error) {
+	if s.client == nil {
+		return "", fmt.Errorf("elasticsearch: not connected")
+	}
+	res, err := s.client.Info(s.client.Info.WithContext(ctx))
+	if err != nil {
+		return "", fmt.Errorf("elasticsearch: info: %w", err)
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return "", fmt.Errorf("elasticsearch: info error: %s", res.Status())
+	}
+
+	var info struct {
+		Version struct {
+			Number string `json:"number"`
+		} `json:"version"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&info); err != nil {
+		return "", fmt.Errorf("elasticsearch: decode info: %w", err)
+	}
+	return info.Version.Number, nil
+}
+
 // Client 返回 Elasticsearch 客户端
 func (s *Storage) Client() *elasticsearch.Client { return s.client }
 
-var _ storage.Storage = (*Storage)(nil)
+var (
+	_ storage.Storage         = (*Storage)(nil)
+	_ storage.VersionProvider = (*Storage)(nil)
+)
@@ -0,0 +1,139 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8"
+
+	"github.com/mildsunup/higo/resilience"
+)
+
+func testStorage(t *testing.T, h http.HandlerFunc) *Storage {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Header().Set("Content-Type", "application/json")
+		h(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	client, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{srv.URL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := New(Config{})
+	s.client = client
+	return s
+}
+
+func TestQuery_Source(t *testing.T) {
+	req := NewSearch().
+		Query(Bool().
+			Must(Match("title", "go").Operator("and")).
+			Filter(Term("status", "published"), Terms("tag", "a", "b"), Range("at").Gte("now-7d")).
+			MustNot(Exists("deleted_at"))).
+		Sort("at", "desc").Size(10)
+	b, _ := json.Marshal(req)
+	want := `{"query":{"bool":{"filter":[{"term":{"status":"published"}},{"terms":{"tag":["a","b"]}},{"range":{"at":{"gte":"now-7d"}}}],"must":[{"match":{"title":{"operator":"and","query":"go"}}}],"must_not":[{"exists":{"field":"deleted_at"}}]}},"size":10,"sort":[{"at":{"order":"desc"}}]}`
+	if string(b) != want {
+		t.Errorf("got  %s\nwant %s", b, want)
+	}
+}
+
+func TestBulkIndexer_Retry429(t *testing.T) {
+	var calls atomic.Int32
+	s := testStorage(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		lines := strings.Count(string(body), "\n") / 2
+		if calls.Add(1) == 1 {
+			// 第一次：第二条被拒绝，第三条映射错误
+			_, _ = io.WriteString(w, `{"errors":true,"items":[{"index":{"status":201}},{"index":{"status":429}},{"index":{"status":400,"error":{"type":"mapper_parsing_exception","reason":"bad"}}}]}`)
+			return
+		}
+		if lines != 1 {
+			t.Errorf("retry sent %d items, want 1", lines)
+		}
+		_, _ = io.WriteString(w, `{"errors":false,"items":[{"index":{"status":201}}]}`)
+	})
+
+	var failed []string
+	b := s.NewBulkIndexer(WithBulkIndex("docs"), WithFlushInterval(0),
+		WithBulkRetry(resilience.WithDelay(time.Millisecond)),
+		WithBulkOnError(func(item BulkItem, err error) { failed = append(failed, item.ID) }))
+	ctx := context.Background()
+	for _, id := range []string{"1", "2", "3"} {
+		_ = b.Add(ctx, BulkItem{ID: id, Doc: map[string]string{"id": id}})
+	}
+	if b.Pending() != 3 {
+		t.Errorf("Pending() = %d, want 3", b.Pending())
+	}
+	if err := b.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	st := b.Stats()
+	if st.Succeeded != 2 || st.Failed != 1 || st.Retried != 1 || len(failed) != 1 || failed[0] != "3" {
+		t.Errorf("stats = %+v, failed = %v", st, failed)
+	}
+	if err := b.Add(ctx, BulkItem{ID: "4"}); err == nil {
+		t.Error("Add() after Close should fail")
+	}
+}
+
+func TestStorage_SwapAlias(t *testing.T) {
+	var actions string
+	s := testStorage(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/_alias/"):
+			_, _ = io.WriteString(w, `{"products_v1":{"aliases":{"products":{}}}}`)
+		case r.URL.Path == "/_aliases":
+			b, _ := io.ReadAll(r.Body)
+			actions = string(b)
+			_, _ = io.WriteString(w, `{"acknowledged":true}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	old, err := s.SwapAlias(context.Background(), "products", "products_v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(old) != 1 || old[0] != "products_v1" ||
+		actions != `{"actions":[{"add":{"alias":"products","index":"products_v2"}},{"remove":{"alias":"products","index":"products_v1"}}]}` {
+		t.Errorf("old = %v, actions = %s", old, actions)
+	}
+	if ok, err := s.IndexExists(context.Background(), "missing"); ok || err != nil {
+		t.Errorf("IndexExists() = %v, %v", ok, err)
+	}
+}
+
+func TestStorage_SearchAfter(t *testing.T) {
+	var bodies []string
+	s := testStorage(t, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if strings.Contains(string(b), "search_after") {
+			_, _ = io.WriteString(w, `{"hits":{"total":{"value":3},"hits":[{"_id":"3","_source":{"n":3},"sort":[3]}]}}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"hits":{"total":{"value":3},"hits":[{"_id":"1","_source":{"n":1},"sort":[1]},{"_id":"2","_source":{"n":2},"sort":[9007199254740993]}]}}`)
+	})
+	var got []struct{ N int }
+	err := s.SearchAfter(context.Background(), "docs", NewSearch().Sort("n", "asc").Size(2), func(hits []Hit) error {
+		docs, err := Decode[struct{ N int }](hits)
+		got = append(got, docs...)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || !strings.Contains(bodies[1], `"search_after":[9007199254740993]`) {
+		t.Errorf("got %v, bodies %v", got, bodies)
+	}
+}
@@ -0,0 +1,129 @@
+package elasticsearch
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+)
+
+// CreateIndex 创建索引，body 为 settings/mappings/aliases 定义（可为 nil）
+func (s *Storage) CreateIndex(ctx context.Context, name string, body map[string]any) error {
+	if s.client == nil {
+		return ErrNotConnected
+	}
+	opts := []func(*esapi.IndicesCreateRequest){s.client.Indices.Create.WithContext(ctx)}
+	if body != nil {
+		r, err := jsonBody(body)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, s.client.Indices.Create.WithBody(r))
+	}
+	res, err := s.client.Indices.Create(name, opts...)
+	return decode(res, err, "create index "+name, nil)
+}
+
+// DeleteIndex 删除索引
+func (s *Storage) DeleteIndex(ctx context.Context, names ...string) error {
+	if s.client == nil {
+		return ErrNotConnected
+	}
+	res, err := s.client.Indices.Delete(names, s.client.Indices.Delete.WithContext(ctx))
+	return decode(res, err, "delete index", nil)
+}
+
+// IndexExists 索引（或别名）是否存在
+func (s *Storage) IndexExists(ctx context.Context, name string) (bool, error) {
+	if s.client == nil {
+		return false, ErrNotConnected
+	}
+	res, err := s.client.Indices.Exists([]string{name}, s.client.Indices.Exists.WithContext(ctx))
+	err = decode(res, err, "index exists", nil)
+	if IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// PutIndexTemplate 创建或更新可组合索引模板，body 包含 index_patterns、template、priority 等
+func (s *Storage) PutIndexTemplate(ctx context.Context, name string, body map[string]any) error {
+	if s.client == nil {
+		return ErrNotConnected
+	}
+	r, err := jsonBody(body)
+	if err != nil {
+		return err
+	}
+	res, err := s.client.Indices.PutIndexTemplate(name, r, s.client.Indices.PutIndexTemplate.WithContext(ctx))
+	return decode(res, err, "put index template "+name, nil)
+}
+
+// DeleteIndexTemplate 删除索引模板
+func (s *Storage) DeleteIndexTemplate(ctx context.Context, name string) error {
+	if s.client == nil {
+		return ErrNotConnected
+	}
+	res, err := s.client.Indices.DeleteIndexTemplate(name, s.client.Indices.DeleteIndexTemplate.WithContext(ctx))
+	return decode(res, err, "delete index template "+name, nil)
+}
+
+// AliasIndices 返回别名指向的索引（按名称排序），别名不存在时返回空
+func (s *Storage) AliasIndices(ctx context.Context, alias string) ([]string, error) {
+	if s.client == nil {
+		return nil, ErrNotConnected
+	}
+	var resp map[string]any
+	res, err := s.client.Indices.GetAlias(
+		s.client.Indices.GetAlias.WithContext(ctx),
+		s.client.Indices.GetAlias.WithName(alias),
+	)
+	err = decode(res, err, "get alias "+alias, &resp)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return slices.Sorted(maps.Keys(resp)), nil
+}
+
+// SwapAlias 原子地将别名切换到 index（移除别名指向的其他索引），用于零停机重建索引：
+// 写入新索引 → SwapAlias → 删除旧索引。返回原先指向的索引
+func (s *Storage) SwapAlias(ctx context.Context, alias, index string) ([]string, error) {
+	old, err := s.AliasIndices(ctx, alias)
+	if err != nil {
+		return nil, err
+	}
+	actions := []map[string]any{{"add": map[string]any{"index": index, "alias": alias}}}
+	var previous []string
+	for _, idx := range old {
+		if idx == index {
+			continue
+		}
+		previous = append(previous, idx)
+		actions = append(actions, map[string]any{"remove": map[string]any{"index": idx, "alias": alias}})
+	}
+	r, err := jsonBody(map[string]any{"actions": actions})
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.client.Indices.UpdateAliases(r, s.client.Indices.UpdateAliases.WithContext(ctx))
+	if err := decode(res, err, "update aliases "+alias, nil); err != nil {
+		return nil, err
+	}
+	return previous, nil
+}
+
+// Refresh 刷新索引使最近写入可被搜索
+func (s *Storage) Refresh(ctx context.Context, names ...string) error {
+	if s.client == nil {
+		return ErrNotConnected
+	}
+	res, err := s.client.Indices.Refresh(
+		s.client.Indices.Refresh.WithContext(ctx),
+		s.client.Indices.Refresh.WithIndex(names...),
+	)
+	return decode(res, err, "refresh", nil)
+}
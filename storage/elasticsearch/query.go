@@ -0,0 +1,253 @@
+package elasticsearch
+
+import "encoding/json"
+
+// Query 查询子句，Source 返回可直接序列化为 Query DSL 的结构
+//
+//	q := es.Bool().
+//	    Must(es.Match("title", "golang")).
+//	    Filter(es.Term("status", "published"), es.Range("created_at").Gte("now-7d"))
+type Query interface {
+	Source() map[string]any
+}
+
+// raw 原始查询子句
+type raw map[string]any
+
+func (r raw) Source() map[string]any { return r }
+
+// Raw 使用原始 DSL 构造查询子句，用于构建器未覆盖的查询类型
+func Raw(source map[string]any) Query { return raw(source) }
+
+// MatchAll 匹配全部文档
+func MatchAll() Query { return raw{"match_all": map[string]any{}} }
+
+// Term 精确匹配
+func Term(field string, value any) Query {
+	return raw{"term": map[string]any{field: value}}
+}
+
+// Terms 匹配任一值
+func Terms[T any](field string, values ...T) Query {
+	return raw{"terms": map[string]any{field: values}}
+}
+
+// IDs 按文档 ID 匹配
+func IDs(ids ...string) Query {
+	return raw{"ids": map[string]any{"values": ids}}
+}
+
+// Exists 字段存在
+func Exists(field string) Query {
+	return raw{"exists": map[string]any{"field": field}}
+}
+
+// Prefix 前缀匹配
+func Prefix(field, prefix string) Query {
+	return raw{"prefix": map[string]any{field: prefix}}
+}
+
+// Wildcard 通配符匹配
+func Wildcard(field, pattern string) Query {
+	return raw{"wildcard": map[string]any{field: pattern}}
+}
+
+// MatchQuery 全文匹配
+type MatchQuery struct {
+	kind   string
+	field  string
+	params map[string]any
+}
+
+// Match 全文匹配
+func Match(field string, text any) *MatchQuery {
+	return &MatchQuery{kind: "match", field: field, params: map[string]any{"query": text}}
+}
+
+// MatchPhrase 短语匹配
+func MatchPhrase(field string, text any) *MatchQuery {
+	return &MatchQuery{kind: "match_phrase", field: field, params: map[string]any{"query": text}}
+}
+
+// Operator 设置词项组合方式（"and"/"or"）
+func (q *MatchQuery) Operator(op string) *MatchQuery { q.params["operator"] = op; return q }
+
+// Fuzziness 设置模糊度（如 "AUTO"）
+func (q *MatchQuery) Fuzziness(f string) *MatchQuery { q.params["fuzziness"] = f; return q }
+
+// Boost 设置权重
+func (q *MatchQuery) Boost(b float64) *MatchQuery { q.params["boost"] = b; return q }
+
+// Source 实现 Query
+func (q *MatchQuery) Source() map[string]any {
+	return map[string]any{q.kind: map[string]any{q.field: q.params}}
+}
+
+// MultiMatch 多字段全文匹配，字段可带权重（如 "title^2"）
+func MultiMatch(text any, fields ...string) Query {
+	return raw{"multi_match": map[string]any{"query": text, "fields": fields}}
+}
+
+// RangeQuery 范围查询
+type RangeQuery struct {
+	field  string
+	params map[string]any
+}
+
+// Range 范围查询
+func Range(field string) *RangeQuery {
+	return &RangeQuery{field: field, params: map[string]any{}}
+}
+
+// Gt 大于
+func (q *RangeQuery) Gt(v any) *RangeQuery { q.params["gt"] = v; return q }
+
+// Gte 大于等于
+func (q *RangeQuery) Gte(v any) *RangeQuery { q.params["gte"] = v; return q }
+
+// Lt 小于
+func (q *RangeQuery) Lt(v any) *RangeQuery { q.params["lt"] = v; return q }
+
+// Lte 小于等于
+func (q *RangeQuery) Lte(v any) *RangeQuery { q.params["lte"] = v; return q }
+
+// Format 设置日期格式
+func (q *RangeQuery) Format(f string) *RangeQuery { q.params["format"] = f; return q }
+
+// Source 实现 Query
+func (q *RangeQuery) Source() map[string]any {
+	return map[string]any{"range": map[string]any{q.field: q.params}}
+}
+
+// BoolQuery 布尔组合查询
+type BoolQuery struct {
+	must, should, filter, mustNot []Query
+	minimumShouldMatch            any
+}
+
+// Bool 布尔组合查询
+func Bool() *BoolQuery { return &BoolQuery{} }
+
+// Must 必须匹配（参与评分）
+func (q *BoolQuery) Must(qs ...Query) *BoolQuery { q.must = append(q.must, qs...); return q }
+
+// Should 应当匹配
+func (q *BoolQuery) Should(qs ...Query) *BoolQuery { q.should = append(q.should, qs...); return q }
+
+// Filter 必须匹配（不参与评分，可缓存）
+func (q *BoolQuery) Filter(qs ...Query) *BoolQuery { q.filter = append(q.filter, qs...); return q }
+
+// MustNot 必须不匹配
+func (q *BoolQuery) MustNot(qs ...Query) *BoolQuery { q.mustNot = append(q.mustNot, qs...); return q }
+
+// MinimumShouldMatch 设置 should 子句最少匹配数（整数或百分比字符串）
+func (q *BoolQuery) MinimumShouldMatch(v any) *BoolQuery { q.minimumShouldMatch = v; return q }
+
+// Source 实现 Query，空子句省略
+func (q *BoolQuery) Source() map[string]any {
+	b := map[string]any{}
+	for name, qs := range map[string][]Query{"must": q.must, "should": q.should, "filter": q.filter, "must_not": q.mustNot} {
+		if len(qs) > 0 {
+			b[name] = sources(qs)
+		}
+	}
+	if q.minimumShouldMatch != nil {
+		b["minimum_should_match"] = q.minimumShouldMatch
+	}
+	return map[string]any{"bool": b}
+}
+
+// Nested 嵌套对象查询
+func Nested(path string, q Query) Query {
+	return raw{"nested": map[string]any{"path": path, "query": q.Source()}}
+}
+
+func sources(qs []Query) []map[string]any {
+	out := make([]map[string]any, len(qs))
+	for i, q := range qs {
+		out[i] = q.Source()
+	}
+	return out
+}
+
+// --- 搜索请求 ---
+
+// SearchRequest 搜索请求体
+type SearchRequest struct {
+	query       Query
+	from, size  *int
+	sort        []map[string]any
+	source      []string
+	searchAfter []any
+	aggs        map[string]any
+	trackTotal  any
+}
+
+// NewSearch 创建搜索请求
+func NewSearch() *SearchRequest { return &SearchRequest{} }
+
+// Query 设置查询
+func (r *SearchRequest) Query(q Query) *SearchRequest { r.query = q; return r }
+
+// From 设置偏移
+func (r *SearchRequest) From(n int) *SearchRequest { r.from = &n; return r }
+
+// Size 设置返回条数
+func (r *SearchRequest) Size(n int) *SearchRequest { r.size = &n; return r }
+
+// Sort 追加排序字段，order 为 "asc" 或 "desc"
+func (r *SearchRequest) Sort(field, order string) *SearchRequest {
+	r.sort = append(r.sort, map[string]any{field: map[string]any{"order": order}})
+	return r
+}
+
+// Fields 设置返回的 _source 字段
+func (r *SearchRequest) Fields(fields ...string) *SearchRequest { r.source = fields; return r }
+
+// SearchAfter 设置 search_after 游标（上一页最后一条的 sort 值）
+func (r *SearchRequest) SearchAfter(values ...any) *SearchRequest { r.searchAfter = values; return r }
+
+// Agg 添加聚合
+func (r *SearchRequest) Agg(name string, agg map[string]any) *SearchRequest {
+	if r.aggs == nil {
+		r.aggs = map[string]any{}
+	}
+	r.aggs[name] = agg
+	return r
+}
+
+// TrackTotalHits 设置总数统计（true 为精确统计，整数为统计上限）
+func (r *SearchRequest) TrackTotalHits(v any) *SearchRequest { r.trackTotal = v; return r }
+
+// Source 返回请求体
+func (r *SearchRequest) Source() map[string]any {
+	body := map[string]any{}
+	if r.query != nil {
+		body["query"] = r.query.Source()
+	}
+	if r.from != nil {
+		body["from"] = *r.from
+	}
+	if r.size != nil {
+		body["size"] = *r.size
+	}
+	if len(r.sort) > 0 {
+		body["sort"] = r.sort
+	}
+	if r.source != nil {
+		body["_source"] = r.source
+	}
+	if len(r.searchAfter) > 0 {
+		body["search_after"] = r.searchAfter
+	}
+	if r.aggs != nil {
+		body["aggs"] = r.aggs
+	}
+	if r.trackTotal != nil {
+		body["track_total_hits"] = r.trackTotal
+	}
+	return body
+}
+
+// MarshalJSON 实现 json.Marshaler
+func (r *SearchRequest) MarshalJSON() ([]byte, error) { return json.Marshal(r.Source()) }
@@ -0,0 +1,221 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+)
+
+// ErrNotConnected 未连接
+var ErrNotConnected = errors.New("elasticsearch: not connected")
+
+// ResponseError 请求返回的错误状态
+type ResponseError struct {
+	Status int
+	Type   string
+	Reason string
+}
+
+func (e *ResponseError) Error() string {
+	if e.Type == "" {
+		return fmt.Sprintf("elasticsearch: status %d", e.Status)
+	}
+	return fmt.Sprintf("elasticsearch: status %d: %s: %s", e.Status, e.Type, e.Reason)
+}
+
+// IsNotFound 是否为 404（索引或文档不存在）
+func IsNotFound(err error) bool {
+	var re *ResponseError
+	return errors.As(err, &re) && re.Status == http.StatusNotFound
+}
+
+// IsTooManyRequests 是否为 429（集群拒绝，需退避重试）
+func IsTooManyRequests(err error) bool {
+	var re *ResponseError
+	return errors.As(err, &re) && re.Status == http.StatusTooManyRequests
+}
+
+// decode 检查响应状态并解码响应体，out 为 nil 时丢弃响应体
+func decode(res *esapi.Response, err error, op string, out any) error {
+	if err != nil {
+		return fmt.Errorf("elasticsearch: %s: %w", op, err)
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		var body struct {
+			Error struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		}
+		_ = json.NewDecoder(res.Body).Decode(&body)
+		return fmt.Errorf("%s: %w", op, &ResponseError{Status: res.StatusCode, Type: body.Error.Type, Reason: body.Error.Reason})
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, res.Body)
+		return nil
+	}
+	dec := json.NewDecoder(res.Body)
+	dec.UseNumber()
+	if err := dec.Decode(out); err != nil {
+		return fmt.Errorf("elasticsearch: %s: decode: %w", op, err)
+	}
+	return nil
+}
+
+func jsonBody(v any) (io.Reader, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("elasticsearch: encode body: %w", err)
+	}
+	return bytes.NewReader(b), nil
+}
+
+// Hit 搜索命中的文档
+type Hit struct {
+	Index  string          `json:"_index"`
+	ID     string          `json:"_id"`
+	Score  *float64        `json:"_score"`
+	Source json.RawMessage `json:"_source"`
+	Sort   []any           `json:"sort,omitempty"`
+}
+
+// SearchResult 搜索结果
+type SearchResult struct {
+	Total        int64
+	Hits         []Hit
+	Aggregations map[string]json.RawMessage
+	ScrollID     string
+}
+
+type searchResponse struct {
+	ScrollID string `json:"_scroll_id"`
+	Hits     struct {
+		Total struct {
+			Value int64 `json:"value"`
+		} `json:"total"`
+		Hits []Hit `json:"hits"`
+	} `json:"hits"`
+	Aggregations map[string]json.RawMessage `json:"aggregations"`
+}
+
+func (r *searchResponse) result() *SearchResult {
+	return &SearchResult{Total: r.Hits.Total.Value, Hits: r.Hits.Hits, Aggregations: r.Aggregations, ScrollID: r.ScrollID}
+}
+
+// Decode 将命中文档的 _source 解码为目标类型
+func Decode[T any](hits []Hit) ([]T, error) {
+	out := make([]T, len(hits))
+	for i, h := range hits {
+		if err := json.Unmarshal(h.Source, &out[i]); err != nil {
+			return nil, fmt.Errorf("elasticsearch: decode hit %s: %w", h.ID, err)
+		}
+	}
+	return out, nil
+}
+
+// Search 执行搜索，index 可为逗号分隔的多个索引或别名
+func (s *Storage) Search(ctx context.Context, index string, req *SearchRequest) (*SearchResult, error) {
+	return s.search(ctx, index, req, 0)
+}
+
+func (s *Storage) search(ctx context.Context, index string, req *SearchRequest, scroll time.Duration) (*SearchResult, error) {
+	if s.client == nil {
+		return nil, ErrNotConnected
+	}
+	body, err := jsonBody(req)
+	if err != nil {
+		return nil, err
+	}
+	opts := []func(*esapi.SearchRequest){
+		s.client.Search.WithContext(ctx),
+		s.client.Search.WithIndex(index),
+		s.client.Search.WithBody(body),
+	}
+	if scroll > 0 {
+		opts = append(opts, s.client.Search.WithScroll(scroll))
+	}
+	var resp searchResponse
+	res, err := s.client.Search(opts...)
+	if err := decode(res, err, "search", &resp); err != nil {
+		return nil, err
+	}
+	return resp.result(), nil
+}
+
+// Scroll 使用 scroll 遍历全部命中文档，每批调用 fn，fn 返回错误时停止；结束后清理 scroll 上下文。
+// 适合一次性导出与重建索引，keepAlive 为批次间的最长处理时间（默认 1 分钟）
+func (s *Storage) Scroll(ctx context.Context, index string, req *SearchRequest, keepAlive time.Duration, fn func(hits []Hit) error) error {
+	if keepAlive <= 0 {
+		keepAlive = time.Minute
+	}
+	page, err := s.search(ctx, index, req, keepAlive)
+	if err != nil {
+		return err
+	}
+	scrollID := page.ScrollID
+	defer func() {
+		if scrollID != "" {
+			res, err := s.client.ClearScroll(
+				s.client.ClearScroll.WithContext(context.WithoutCancel(ctx)),
+				s.client.ClearScroll.WithScrollID(scrollID),
+			)
+			_ = decode(res, err, "clear scroll", nil)
+		}
+	}()
+
+	for len(page.Hits) > 0 {
+		if err := fn(page.Hits); err != nil {
+			return err
+		}
+		var resp searchResponse
+		res, err := s.client.Scroll(
+			s.client.Scroll.WithContext(ctx),
+			s.client.Scroll.WithScrollID(scrollID),
+			s.client.Scroll.WithScroll(keepAlive),
+		)
+		if err := decode(res, err, "scroll", &resp); err != nil {
+			return err
+		}
+		page = resp.result()
+		if page.ScrollID != "" {
+			scrollID = page.ScrollID
+		}
+	}
+	return nil
+}
+
+// SearchAfter 使用 search_after 逐页遍历命中文档，每页调用 fn，fn 返回错误时停止。
+// 请求必须设置 Sort 且包含唯一的决胜字段（如 ID），否则分页可能遗漏或重复；Size 未设置时为 1000。
+// 遍历过程中会更新 req 的 search_after 游标
+func (s *Storage) SearchAfter(ctx context.Context, index string, req *SearchRequest, fn func(hits []Hit) error) error {
+	if len(req.sort) == 0 {
+		return errors.New("elasticsearch: search_after requires sort")
+	}
+	if req.size == nil {
+		req.Size(1000)
+	}
+	for {
+		res, err := s.Search(ctx, index, req)
+		if err != nil {
+			return err
+		}
+		if len(res.Hits) == 0 {
+			return nil
+		}
+		if err := fn(res.Hits); err != nil {
+			return err
+		}
+		if len(res.Hits) < *req.size {
+			return nil
+		}
+		req.SearchAfter(res.Hits[len(res.Hits)-1].Sort...)
+	}
+}
@@ -0,0 +1,131 @@
+package embedded
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/mildsunup/higo/cache"
+)
+
+// Cache 基于嵌入式存储的 cache.Cache 适配器
+// 存储的生命周期由 storage.Manager 管理，Close 不关闭底层存储。
+type Cache struct {
+	store  *Storage
+	opts   cache.Options
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// NewCache 创建缓存适配器
+func NewCache(s *Storage, opts ...cache.Option) *Cache {
+	o := cache.Options{
+		Serializer: &cache.JSONSerializer{},
+		DefaultTTL: 5 * time.Minute,
+		NullTTL:    time.Minute,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Cache{store: s, opts: o}
+}
+
+func (c *Cache) key(k string) string {
+	if c.opts.Prefix != "" {
+		return c.opts.Prefix + ":" + k
+	}
+	return k
+}
+
+func (c *Cache) Get(ctx context.Context, key string, dest any) error {
+	data, err := c.store.Get(ctx, c.key(key))
+	if errors.Is(err, ErrNotFound) {
+		c.misses.Add(1)
+		return cache.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	c.hits.Add(1)
+	return c.opts.Serializer.Unmarshal(data, dest)
+}
+
+func (c *Cache) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	if ttl == 0 {
+		ttl = c.opts.DefaultTTL
+	}
+	data, err := c.opts.Serializer.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return c.store.Put(ctx, c.key(key), data, ttl)
+}
+
+func (c *Cache) Delete(ctx context.Context, keys ...string) error {
+	return c.MDelete(ctx, keys)
+}
+
+func (c *Cache) Exists(ctx context.Context, key string) (bool, error) {
+	_, err := c.store.Get(ctx, c.key(key))
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (c *Cache) Close() error { return nil }
+
+func (c *Cache) MGet(ctx context.Context, keys []string) (map[string][]byte, error) {
+	result := make(map[string][]byte, len(keys))
+	for _, k := range keys {
+		data, err := c.store.Get(ctx, c.key(k))
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		result[k] = data
+	}
+	return result, nil
+}
+
+func (c *Cache) MSet(ctx context.Context, items map[string]any, ttl time.Duration) error {
+	if ttl == 0 {
+		ttl = c.opts.DefaultTTL
+	}
+	return c.store.Update(ctx, func(b *Batch) error {
+		for k, v := range items {
+			data, err := c.opts.Serializer.Marshal(v)
+			if err != nil {
+				return err
+			}
+			b.Put(c.key(k), data, ttl)
+		}
+		return nil
+	})
+}
+
+func (c *Cache) MDelete(ctx context.Context, keys []string) error {
+	return c.store.Update(ctx, func(b *Batch) error {
+		for _, k := range keys {
+			b.Delete(c.key(k))
+		}
+		return nil
+	})
+}
+
+func (c *Cache) Stats() cache.Stats {
+	hits, misses := c.hits.Load(), c.misses.Load()
+	var hitRate float64
+	if total := hits + misses; total > 0 {
+		hitRate = float64(hits) / float64(total)
+	}
+	return cache.Stats{Hits: hits, Misses: misses, HitRate: hitRate, Keys: int64(c.store.Len())}
+}
+
+var (
+	_ cache.BatchCache    = (*Cache)(nil)
+	_ cache.StatsProvider = (*Cache)(nil)
+)
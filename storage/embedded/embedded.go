@@ -0,0 +1,395 @@
+// Package embedded 提供基于 bbolt 的嵌入式键值存储，适用于单节点边缘部署。
+//
+// 数据存放在单个文件中，每次写入为一个 bbolt 事务（提交时 fsync，崩溃后不会出现半写的批次）；
+// 打开时对文件加排他锁，另一进程已打开同一文件时 Connect 在 LockTimeout 后失败。
+// 过期键在读取时视为不存在，由 Connect 与 Compact 清理。
+//
+// 作为 storage.Storage 接入 Manager 与 Builder（追踪、指标、重连装饰器），
+// 并可通过 NewCache 作为 cache.Cache 使用：
+//
+//	kv := embedded.New(embedded.Config{Path: "/var/lib/app/data.db"})
+//	mgr.Register(storage.NewBuilder(kv).WithTracing(tracer).WithMetrics(m).Build())
+//
+//	_ = kv.Put(ctx, "device:1", data, 0)
+//	c := cache.NewMetriced(embedded.NewCache(kv, cache.WithPrefix("cache")), "edge", cacheMetrics)
+package embedded
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/mildsunup/higo/storage"
+)
+
+// ErrNotFound 键不存在或已过期
+var ErrNotFound = errors.New("embedded: key not found")
+
+// Config 嵌入式存储配置
+type Config struct {
+	Name string `json:"name" yaml:"name"`
+	Path string `json:"path" yaml:"path"`
+	// NoSync 关闭提交时的 fsync，仅适用于可丢弃的数据：操作系统崩溃可能丢失或损坏数据
+	NoSync bool `json:"no_sync" yaml:"no_sync"`
+	// LockTimeout 等待文件锁的时间，默认 1s；另一进程持有锁时 Connect 失败
+	LockTimeout time.Duration `json:"lock_timeout" yaml:"lock_timeout"`
+}
+
+// bucket 数据桶
+var bucket = []byte("kv")
+
+// Storage 嵌入式键值存储
+type Storage struct {
+	*storage.Base
+	config Config
+	tracer trace.Tracer
+
+	mu sync.RWMutex // 保护 db 的打开与关闭
+	db *bolt.DB
+}
+
+// Option 存储选项
+type Option func(*Storage)
+
+// WithTracer 为数据操作启用追踪
+func WithTracer(tp trace.TracerProvider) Option {
+	return func(s *Storage) {
+		s.tracer = tp.Tracer("github.com/mildsunup/higo/storage/embedded")
+	}
+}
+
+// New 创建嵌入式存储
+func New(cfg Config, opts ...Option) *Storage {
+	name := cfg.Name
+	if name == "" {
+		name = "embedded"
+	}
+	if cfg.LockTimeout == 0 {
+		cfg.LockTimeout = time.Second
+	}
+
+	s := &Storage{
+		Base:   storage.NewBase(name, storage.TypeEmbedded),
+		config: cfg,
+		tracer: otel.GetTracerProvider().Tracer("github.com/mildsunup/higo/storage/embedded"),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *Storage) Connect(ctx context.Context) error {
+	if !s.CompareAndSwapState(storage.StateDisconnected, storage.StateConnecting) {
+		return fmt.Errorf("embedded: invalid state for connect")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	db, err := bolt.Open(s.config.Path, 0o600, &bolt.Options{Timeout: s.config.LockTimeout, NoSync: s.config.NoSync})
+	if err != nil {
+		s.SetState(storage.StateDisconnected)
+		return fmt.Errorf("embedded: open %s: %w", s.config.Path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(bucket)
+		if err != nil {
+			return err
+		}
+		return purgeExpired(b, time.Now().UnixNano())
+	})
+	if err != nil {
+		_ = db.Close()
+		s.SetState(storage.StateDisconnected)
+		return fmt.Errorf("embedded: init: %w", err)
+	}
+	s.db = db
+	s.SetState(storage.StateConnected)
+	return nil
+}
+
+func (s *Storage) Ping(ctx context.Context) error {
+	db, err := s.conn()
+	if err != nil {
+		return err
+	}
+	return db.View(func(*bolt.Tx) error { return nil })
+}
+
+func (s *Storage) Close(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.db == nil {
+		return nil
+	}
+	s.SetState(storage.StateDisconnecting)
+	err := s.db.Close()
+	s.db = nil
+	s.SetState(storage.StateDisconnected)
+	return err
+}
+
+// ServerVersion 返回存储格式版本
+func (s *Storage) ServerVersion(ctx context.Context) (string, error) {
+	return fmt.Sprintf("embedded-v%d", formatVersion), nil
+}
+
+func (s *Storage) conn() (*bolt.DB, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.db == nil {
+		return nil, fmt.Errorf("embedded: not connected")
+	}
+	return s.db, nil
+}
+
+// Get 读取键值，不存在或已过期时返回 ErrNotFound
+func (s *Storage) Get(ctx context.Context, key string) ([]byte, error) {
+	_, span := s.startSpan(ctx, "Get", key)
+	defer span.End()
+
+	db, err := s.conn()
+	if err != nil {
+		return nil, endSpan(span, err)
+	}
+	var value []byte
+	err = db.View(func(tx *bolt.Tx) error {
+		v, ok := decode(tx.Bucket(bucket).Get([]byte(key)), time.Now().UnixNano())
+		if !ok {
+			return ErrNotFound
+		}
+		value = bytes.Clone(v)
+		return nil
+	})
+	if errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	return value, endSpan(span, err)
+}
+
+// Put 写入键值，ttl 为 0 表示不过期
+func (s *Storage) Put(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.Update(ctx, func(b *Batch) error {
+		b.Put(key, value, ttl)
+		return nil
+	})
+}
+
+// Delete 删除键
+func (s *Storage) Delete(ctx context.Context, keys ...string) error {
+	return s.Update(ctx, func(b *Batch) error {
+		for _, k := range keys {
+			b.Delete(k)
+		}
+		return nil
+	})
+}
+
+// Scan 按键升序遍历前缀匹配的未过期键值，fn 返回 false 时停止。
+// 匹配的键值先复制出只读事务再回调，fn 内可以写入
+func (s *Storage) Scan(ctx context.Context, prefix string, fn func(key string, value []byte) bool) error {
+	_, span := s.startSpan(ctx, "Scan", prefix)
+	defer span.End()
+
+	db, err := s.conn()
+	if err != nil {
+		return endSpan(span, err)
+	}
+	type kv struct {
+		key   string
+		value []byte
+	}
+	var items []kv
+	err = db.View(func(tx *bolt.Tx) error {
+		now := time.Now().UnixNano()
+		c := tx.Bucket(bucket).Cursor()
+		for k, raw := c.Seek([]byte(prefix)); k != nil && bytes.HasPrefix(k, []byte(prefix)); k, raw = c.Next() {
+			if v, ok := decode(raw, now); ok {
+				items = append(items, kv{string(k), bytes.Clone(v)})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return endSpan(span, err)
+	}
+
+	for _, it := range items {
+		if err := ctx.Err(); err != nil {
+			return endSpan(span, err)
+		}
+		if !fn(it.key, it.value) {
+			break
+		}
+	}
+	return nil
+}
+
+// Len 返回键数量（含未清理的过期键）
+func (s *Storage) Len() int {
+	db, err := s.conn()
+	if err != nil {
+		return 0
+	}
+	var n int
+	_ = db.View(func(tx *bolt.Tx) error {
+		n = tx.Bucket(bucket).Stats().KeyN
+		return nil
+	})
+	return n
+}
+
+// Batch 原子写入批次
+type Batch struct {
+	ops []op
+}
+
+type op struct {
+	key      string
+	value    []byte
+	expireAt int64 // UnixNano，0 表示不过期
+	delete   bool
+}
+
+// Put 在批次中写入键值
+func (b *Batch) Put(key string, value []byte, ttl time.Duration) {
+	var expireAt int64
+	if ttl > 0 {
+		expireAt = time.Now().Add(ttl).UnixNano()
+	}
+	b.ops = append(b.ops, op{key: key, value: bytes.Clone(value), expireAt: expireAt})
+}
+
+// Delete 在批次中删除键
+func (b *Batch) Delete(key string) {
+	b.ops = append(b.ops, op{key: key, delete: true})
+}
+
+// Update 原子执行一批写入：fn 返回错误或提交失败时不写入任何数据
+func (s *Storage) Update(ctx context.Context, fn func(b *Batch) error) error {
+	_, span := s.startSpan(ctx, "Update", "")
+	defer span.End()
+
+	var b Batch
+	if err := fn(&b); err != nil {
+		return endSpan(span, err)
+	}
+	if len(b.ops) == 0 {
+		return nil
+	}
+	span.SetAttributes(attribute.Int("embedded.batch_size", len(b.ops)))
+
+	db, err := s.conn()
+	if err != nil {
+		return endSpan(span, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		bk := tx.Bucket(bucket)
+		for _, o := range b.ops {
+			var err error
+			if o.delete {
+				err = bk.Delete([]byte(o.key))
+			} else {
+				err = bk.Put([]byte(o.key), encode(o.value, o.expireAt))
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return endSpan(span, fmt.Errorf("embedded: update: %w", err))
+	}
+	return nil
+}
+
+// Compact 删除已过期的键，释放的页由后续写入复用（bbolt 不收缩文件）
+func (s *Storage) Compact(ctx context.Context) error {
+	_, span := s.startSpan(ctx, "Compact", "")
+	defer span.End()
+
+	db, err := s.conn()
+	if err != nil {
+		return endSpan(span, err)
+	}
+	return endSpan(span, db.Update(func(tx *bolt.Tx) error {
+		return purgeExpired(tx.Bucket(bucket), time.Now().UnixNano())
+	}))
+}
+
+func purgeExpired(b *bolt.Bucket, now int64) error {
+	var expired [][]byte
+	err := b.ForEach(func(k, v []byte) error {
+		if _, ok := decode(v, now); !ok {
+			expired = append(expired, bytes.Clone(k))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, k := range expired {
+		if err := b.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Storage) startSpan(ctx context.Context, op, key string) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		attribute.String("db.system", "embedded"),
+		attribute.String("storage.name", s.Name()),
+	}
+	if key != "" {
+		attrs = append(attrs, attribute.String("embedded.key", key))
+	}
+	return s.tracer.Start(ctx, "embedded."+op, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+func endSpan(span trace.Span, err error) error {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+// 值格式：expireAt(8，UnixNano，0 表示不过期) | value
+const (
+	formatVersion = 2
+	headerSize    = 8
+)
+
+func encode(value []byte, expireAt int64) []byte {
+	buf := make([]byte, headerSize, headerSize+len(value))
+	binary.BigEndian.PutUint64(buf, uint64(expireAt))
+	return append(buf, value...)
+}
+
+// decode 解析存储的值，格式错误或已过期时返回 false；返回的切片仅在事务内有效
+func decode(raw []byte, now int64) ([]byte, bool) {
+	if len(raw) < headerSize {
+		return nil, false
+	}
+	if expireAt := int64(binary.BigEndian.Uint64(raw)); expireAt > 0 && now >= expireAt {
+		return nil, false
+	}
+	return raw[headerSize:], true
+}
+
+var (
+	_ storage.Storage         = (*Storage)(nil)
+	_ storage.VersionProvider = (*Storage)(nil)
+)
@@ -0,0 +1,137 @@
+package embedded
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openStorage(t *testing.T, path string) *Storage {
+	t.Helper()
+	s := New(Config{Path: path, LockTimeout: 50 * time.Millisecond})
+	if err := s.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestStorage_Persistence(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "data.db")
+
+	s := openStorage(t, path)
+	_ = s.Put(ctx, "a", []byte("1"), 0)
+	_ = s.Put(ctx, "b", []byte("2"), 0)
+	_ = s.Put(ctx, "a", []byte("3"), 0)
+	_ = s.Put(ctx, "tmp", []byte("x"), time.Millisecond)
+	_ = s.Delete(ctx, "b")
+	_ = s.Close(ctx)
+
+	time.Sleep(2 * time.Millisecond)
+	s = openStorage(t, path)
+	defer s.Close(ctx)
+
+	if v, err := s.Get(ctx, "a"); err != nil || string(v) != "3" {
+		t.Errorf("a = %q, %v", v, err)
+	}
+	for _, k := range []string{"b", "tmp"} {
+		if _, err := s.Get(ctx, k); !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: expected ErrNotFound, got %v", k, err)
+		}
+	}
+	// 过期键在 Connect 时清理
+	if s.Len() != 1 {
+		t.Errorf("len after reopen = %d, want 1", s.Len())
+	}
+}
+
+func TestStorage_UpdateAtomic(t *testing.T) {
+	ctx := context.Background()
+	s := openStorage(t, filepath.Join(t.TempDir(), "data.db"))
+	defer s.Close(ctx)
+
+	boom := errors.New("boom")
+	err := s.Update(ctx, func(b *Batch) error {
+		b.Put("x", []byte("1"), 0)
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("Update = %v", err)
+	}
+	if _, err := s.Get(ctx, "x"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("batch with error was applied: %v", err)
+	}
+
+	err = s.Update(ctx, func(b *Batch) error {
+		b.Put("user:1", []byte("a"), 0)
+		b.Put("user:2", []byte("b"), 0)
+		b.Put("order:1", []byte("c"), 0)
+		b.Put("user:3", []byte("d"), time.Nanosecond)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+
+	var keys []string
+	_ = s.Scan(ctx, "user:", func(k string, _ []byte) bool {
+		keys = append(keys, k)
+		// 回调内写入不会死锁
+		_ = s.Put(ctx, "seen:"+k, nil, 0)
+		return true
+	})
+	if len(keys) != 2 || keys[0] != "user:1" || keys[1] != "user:2" {
+		t.Errorf("scan = %v", keys)
+	}
+
+	if err := s.Compact(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if s.Len() != 5 {
+		t.Errorf("len after compact = %d, want 5", s.Len())
+	}
+}
+
+func TestStorage_FileLock(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "data.db")
+	s := openStorage(t, path)
+
+	other := New(Config{Path: path, LockTimeout: 50 * time.Millisecond})
+	if err := other.Connect(ctx); err == nil {
+		_ = other.Close(ctx)
+		t.Fatal("second Connect on a locked file succeeded")
+	}
+
+	_ = s.Close(ctx)
+	if err := other.Connect(ctx); err != nil {
+		t.Fatalf("Connect after release: %v", err)
+	}
+	_ = other.Close(ctx)
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	s := openStorage(t, filepath.Join(t.TempDir(), "data.db"))
+	defer s.Close(ctx)
+	c := NewCache(s)
+
+	if err := c.MSet(ctx, map[string]any{"a": 1, "b": "x"}, 0); err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	if err := c.Get(ctx, "a", &n); err != nil || n != 1 {
+		t.Fatalf("Get = %d, %v", n, err)
+	}
+	got, _ := c.MGet(ctx, []string{"a", "b", "missing"})
+	if len(got) != 2 {
+		t.Fatalf("MGet = %v", got)
+	}
+	_ = c.Delete(ctx, "a")
+	if ok, _ := c.Exists(ctx, "a"); ok {
+		t.Fatal("deleted key exists")
+	}
+}
@@ -0,0 +1,69 @@
+// Package geo 定义地理位置索引的通用接口，由 Redis（GEO / RediSearch）与 MongoDB 实现。
+//
+// 使用示例：
+//
+//	var idx geo.Index = rediskit.NewGeoIndex(client, "shops")
+//	idx.Add(ctx, geo.Location{Member: "shop:1", Longitude: 116.39, Latitude: 39.9})
+//	results, err := idx.SearchRadius(ctx, geo.Query{Longitude: 116.4, Latitude: 39.9, Radius: 3000, Limit: 20})
+package geo
+
+import (
+	"context"
+	"math"
+)
+
+// Location 地理位置
+type Location struct {
+	Member    string
+	Longitude float64
+	Latitude  float64
+}
+
+// Result 搜索结果
+type Result struct {
+	Location
+	Distance float64 // 距离，单位米
+}
+
+// Query 半径搜索条件
+type Query struct {
+	Longitude float64
+	Latitude  float64
+	Radius    float64 // 半径，单位米
+	Offset    int
+	Limit     int
+}
+
+// Index 地理位置索引
+type Index interface {
+	// Add 添加或更新位置
+	Add(ctx context.Context, locations ...Location) error
+	// Remove 移除位置
+	Remove(ctx context.Context, members ...string) error
+	// SearchRadius 按半径搜索，结果按距离升序分页返回
+	SearchRadius(ctx context.Context, q Query) ([]Result, error)
+}
+
+const earthRadius = 6371008.8 // 平均地球半径，单位米
+
+// Distance 计算两点间球面距离（米）
+func Distance(lon1, lat1, lon2, lat2 float64) float64 {
+	rad := math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLon := (lon2 - lon1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(a))
+}
+
+// Page 对已按距离排序的结果分页
+func Page(results []Result, offset, limit int) []Result {
+	if offset >= len(results) {
+		return nil
+	}
+	results = results[offset:]
+	if limit > 0 && limit < len(results) {
+		results = results[:limit]
+	}
+	return results
+}
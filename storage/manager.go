@@ -12,13 +12,37 @@ type manager struct {
 	mu       sync.RWMutex
 	storages map[string]Storage
 	order    []string // 保持注册顺序
+
+	monitor        *MonitorConfig
+	monitorMetrics *monitorMetrics
+	onHealthChange func(name string, from, to Health, err error)
+	monitorMu      sync.Mutex
+	stopMonitor    context.CancelFunc
+	monitorWG      sync.WaitGroup
+	healthMu       sync.Mutex
+	health         map[string]*healthEntry
+
+	saturation        *SaturationConfig
+	saturationMetrics *saturationMetrics
+	onSaturation      func(e SaturationEvent)
+	stopSaturation    context.CancelFunc
+	saturationWG      sync.WaitGroup
+	saturationMu      sync.Mutex
+	saturationState   map[string]*saturationEntry
 }
 
 // NewManager 创建存储管理器
-func NewManager() Manager {
-	return &manager{
+func NewManager(opts ...ManagerOption) Manager {
+	m := &manager{
 		storages: make(map[string]Storage),
+		health:   make(map[string]*healthEntry),
+
+		saturationState: make(map[string]*saturationEntry),
 	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
 }
 
 func (m *manager) Register(s Storage) error {
@@ -63,14 +87,20 @@ func (m *manager) GetByType(typ Type) []Storage {
 	return result
 }
 
-// ConnectAll 并发连接所有存储
-func (m *manager) ConnectAll(ctx context.Context) error {
+// ordered 按注册顺序返回存储
+func (m *manager) ordered() []Storage {
 	m.mu.RLock()
-	storages := make([]Storage, 0, len(m.storages))
+	defer m.mu.RUnlock()
+	storages := make([]Storage, 0, len(m.order))
 	for _, name := range m.order {
 		storages = append(storages, m.storages[name])
 	}
-	m.mu.RUnlock()
+	return storages
+}
+
+// ConnectAll 并发连接所有存储，启用健康监控与饱和检测时随后开始监控
+func (m *manager) ConnectAll(ctx context.Context) error {
+	storages := m.ordered()
 
 	var (
 		wg   sync.WaitGroup
@@ -91,11 +121,16 @@ func (m *manager) ConnectAll(ctx context.Context) error {
 	}
 
 	wg.Wait()
+	m.startMonitor()
+	m.startSaturation()
 	return errors.Join(errs...)
 }
 
-// CloseAll 按注册逆序关闭
+// CloseAll 停止健康监控与饱和检测并按注册逆序关闭
 func (m *manager) CloseAll(ctx context.Context) error {
+	m.stopMonitoring()
+	m.stopSaturationMonitor()
+
 	m.mu.RLock()
 	order := make([]string, len(m.order))
 	copy(order, m.order)
@@ -114,12 +149,7 @@ func (m *manager) CloseAll(ctx context.Context) error {
 }
 
 func (m *manager) HealthCheck(ctx context.Context) []HealthStatus {
-	m.mu.RLock()
-	storages := make([]Storage, 0, len(m.storages))
-	for _, name := range m.order {
-		storages = append(storages, m.storages[name])
-	}
-	m.mu.RUnlock()
+	storages := m.ordered()
 
 	results := make([]HealthStatus, len(storages))
 	var wg sync.WaitGroup
@@ -129,6 +159,8 @@ func (m *manager) HealthCheck(ctx context.Context) []HealthStatus {
 		go func(idx int, s Storage) {
 			defer wg.Done()
 			results[idx] = checkHealth(ctx, s)
+			results[idx].Degraded = m.Health(s.Name()) == HealthDegraded
+			results[idx].Saturated = m.Saturation(s.Name()).Saturated
 		}(i, s)
 	}
 
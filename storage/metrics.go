@@ -97,17 +97,51 @@ func (c *Collector) run(ctx context.Context) {
 
 func (c *Collector) collect() {
 	for _, s := range c.storages {
+		if sp, ok := s.(SnapshotProvider); ok {
+			c.metrics.Record(s.Name(), s.Type(), sp.Snapshot().Stats)
+			continue
+		}
 		if sp, ok := s.(StatsProvider); ok {
 			c.metrics.Record(s.Name(), s.Type(), sp.Stats())
 		}
 	}
 }
 
+// 查询操作类型，用于 storage_query_* 指标的 operation 标签
+const (
+	OpQuery = "query" // 读取
+	OpExec  = "exec"  // 写入与原始语句
+	OpTx    = "tx"    // 事务（开始到提交或回滚）
+)
+
+// UnnamedQuery 未命名且无法推断表名时的 query 标签
+const UnnamedQuery = "unnamed"
+
+type queryNameKey struct{}
+
+// WithQueryName 为 context 设置查询名称，作为 storage_query_* 指标的 query 标签。
+// 名称应为有限集合（如 "order.list_by_user"），不可包含 ID 等高基数值
+func WithQueryName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, queryNameKey{}, name)
+}
+
+// QueryName 获取 context 中的查询名称
+func QueryName(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	name, _ := ctx.Value(queryNameKey{}).(string)
+	return name
+}
+
 // Metrics 存储操作指标 (用于装饰器)
 type Metrics struct {
 	operations observability.Counter
 	duration   observability.Histogram
 	errors     observability.Counter
+
+	queryDuration observability.Histogram
+	queryErrors   observability.Counter
 }
 
 // NewMetrics 创建存储操作指标
@@ -116,9 +150,35 @@ func NewMetrics(mp observability.MetricsProvider) *Metrics {
 		operations: mp.Counter("storage_operations_total", "Total storage operations", "name", "type", "operation"),
 		duration:   mp.Histogram("storage_operation_seconds", "Storage operation duration", observability.DurationBuckets, "name", "type", "operation"),
 		errors:     mp.Counter("storage_errors_total", "Total storage errors", "name", "type", "operation"),
+
+		queryDuration: mp.Histogram("storage_query_seconds", "Storage query duration by operation and query name", observability.DurationBuckets, "name", "type", "operation", "query"),
+		queryErrors:   mp.Counter("storage_query_errors_total", "Total storage query errors by operation and query name", "name", "type", "operation", "query"),
 	}
 }
 
+// ObserveQuery 记录一次查询的耗时与错误，query 为空时使用 UnnamedQuery
+func (m *Metrics) ObserveQuery(name string, typ Type, op, query string, start time.Time, err error) {
+	if query == "" {
+		query = UnnamedQuery
+	}
+	labels := []string{name, string(typ), op, query}
+	m.queryDuration.Since(start, labels...)
+	if err != nil {
+		m.queryErrors.Inc(labels...)
+	}
+}
+
+// SetOperationMetrics 设置查询指标，支持的存储（如 MySQL）在连接时据此注册查询级指标采集。
+// 通过 Builder.WithMetrics 构建时自动设置
+func (b *Base) SetOperationMetrics(m *Metrics) {
+	b.ops.Store(m)
+}
+
+// OperationMetrics 返回查询指标，未设置时返回 nil
+func (b *Base) OperationMetrics() *Metrics {
+	return b.ops.Load()
+}
+
 // Metriced 带指标的存储装饰器
 type Metriced struct {
 	storage Storage
@@ -151,6 +211,18 @@ func (m *Metriced) Close(ctx context.Context) error {
 	return err
 }
 
+// Observe 执行 fn 并以 context 中的查询名称记录查询指标，用于未接入自动采集的查询路径：
+//
+//	err := metriced.Observe(storage.WithQueryName(ctx, "report.daily"), storage.OpQuery, func(ctx context.Context) error {
+//	    return db.QueryRowContext(ctx, q).Scan(&n)
+//	})
+func (m *Metriced) Observe(ctx context.Context, op string, fn func(context.Context) error) error {
+	start := time.Now()
+	err := fn(ctx)
+	m.metrics.ObserveQuery(m.storage.Name(), m.storage.Type(), op, QueryName(ctx), start, err)
+	return err
+}
+
 func (m *Metriced) Name() string  { return m.storage.Name() }
 func (m *Metriced) Type() Type    { return m.storage.Type() }
 func (m *Metriced) State() State  { return m.storage.State() }
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/mildsunup/higo/observability"
+)
+
+// sampleCount 返回指定指标中标签完全匹配的样本数（直方图为观测次数，计数器为值）
+func sampleCount(t *testing.T, reg *prometheus.Registry, name string, labels map[string]string) float64 {
+	t.Helper()
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range families {
+		if f.GetName() != name {
+			continue
+		}
+	next:
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if want, ok := labels[l.GetName()]; ok && want != l.GetValue() {
+					continue next
+				}
+			}
+			if h := m.GetHistogram(); h != nil {
+				return float64(h.GetSampleCount())
+			}
+			return m.GetCounter().GetValue()
+		}
+	}
+	return 0
+}
+
+func TestMetricedObserve(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics(observability.NewPrometheusProvider(reg))
+	s := NewBuilder(newMockStorage()).WithMetrics(m).Build().(*Metriced)
+
+	ctx := WithQueryName(context.Background(), "order.list")
+	_ = s.Observe(ctx, OpQuery, func(context.Context) error { return nil })
+	_ = s.Observe(ctx, OpQuery, func(context.Context) error { return errors.New("boom") })
+	_ = s.Observe(context.Background(), OpExec, func(context.Context) error { return nil })
+
+	named := map[string]string{"name": "mock", "operation": OpQuery, "query": "order.list"}
+	if n := sampleCount(t, reg, "storage_query_seconds", named); n != 2 {
+		t.Fatalf("named query observations = %v, want 2", n)
+	}
+	if n := sampleCount(t, reg, "storage_query_errors_total", named); n != 1 {
+		t.Fatalf("named query errors = %v, want 1", n)
+	}
+	unnamed := map[string]string{"operation": OpExec, "query": UnnamedQuery}
+	if n := sampleCount(t, reg, "storage_query_seconds", unnamed); n != 1 {
+		t.Fatalf("unnamed exec observations = %v, want 1", n)
+	}
+}
+
+func TestBuilderSetsOperationMetrics(t *testing.T) {
+	m := NewMetrics(observability.NewPrometheusProvider(prometheus.NewRegistry()))
+	mock := newMockStorage()
+	NewBuilder(mock).WithMetrics(m).Build()
+	if mock.OperationMetrics() != m {
+		t.Fatal("builder did not pass operation metrics to storage")
+	}
+}
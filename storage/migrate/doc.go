@@ -0,0 +1,32 @@
+// Package migrate 提供存储后端迁移的双读双写装饰器。
+//
+// 迁移按阶段推进，每个阶段都可随时回退（由 PhaseSource 控制，可对接配置热更新或特性开关）：
+//
+//	PhaseOld         只读写旧库
+//	PhaseDualWrite   双写，读旧库（新库开始积累数据，配合回填任务）
+//	PhaseShadowRead  双写，读旧库并与新库比对，不一致记录日志与指标
+//	PhaseNewPrimary  双写，读新库并与旧库比对（旧库仍可作为回退）
+//	PhaseNew         只读写新库
+//
+// 主库写入失败直接返回错误；副库写入失败只记录，WithStrictWrites 时返回错误。
+// 比对读取不影响请求结果。
+//
+// 使用示例：
+//
+//	phase := migrate.NewSwitch(migrate.PhaseDualWrite)
+//	repo := migrate.NewDualRepository[Order, ddd.Int64ID](mysqlRepo, mongoRepo, phase,
+//	    migrate.WithName("orders"),
+//	    migrate.WithLogger(log),
+//	    migrate.WithMetrics(metrics),
+//	    migrate.WithNotFound(func(err error) bool {
+//	        return errors.Is(err, mysql.ErrNotFound) || errors.Is(err, mongo.ErrNoDocuments)
+//	    }),
+//	)
+//
+//	// 配置变更时推进阶段，无法识别的阶段名保持当前阶段
+//	loader.OnChange(func(e config.ChangeEvent) {
+//	    if p, err := migrate.ParsePhase(cfg.Migration.Orders); err == nil {
+//	        phase.Set(p)
+//	    }
+//	})
+package migrate
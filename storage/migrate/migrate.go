@@ -0,0 +1,305 @@
+package migrate
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync/atomic"
+
+	"github.com/mildsunup/higo/ddd"
+	"github.com/mildsunup/higo/errors"
+	"github.com/mildsunup/higo/logger"
+	"github.com/mildsunup/higo/observability"
+)
+
+// Phase 迁移阶段
+type Phase int32
+
+const (
+	PhaseOld Phase = iota
+	PhaseDualWrite
+	PhaseShadowRead
+	PhaseNewPrimary
+	PhaseNew
+)
+
+var phaseNames = []string{"old", "dual_write", "shadow_read", "new_primary", "new"}
+
+func (p Phase) String() string {
+	if p < 0 || int(p) >= len(phaseNames) {
+		return "unknown"
+	}
+	return phaseNames[p]
+}
+
+// ParsePhase 解析阶段名（如 "dual_write"），无法识别时返回 InvalidArgument 错误，
+// 调用方应保留当前阶段而不是回退，避免配置笔误把流量切回旧库
+func ParsePhase(s string) (Phase, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	for i, n := range phaseNames {
+		if name == n {
+			return Phase(i), nil
+		}
+	}
+	return PhaseOld, errors.Newf(errors.InvalidArgument, "migrate: unknown phase %q", s)
+}
+
+// writesOld 是否写旧库
+func (p Phase) writesOld() bool { return p <= PhaseNewPrimary }
+
+// writesNew 是否写新库
+func (p Phase) writesNew() bool { return p >= PhaseDualWrite }
+
+// readsNew 是否以新库为主读
+func (p Phase) readsNew() bool { return p >= PhaseNewPrimary }
+
+// compares 是否比对读取
+func (p Phase) compares() bool { return p == PhaseShadowRead || p == PhaseNewPrimary }
+
+// PhaseSource 阶段来源，每次操作都会查询，以便按请求或租户灰度
+type PhaseSource interface {
+	Phase(ctx context.Context) Phase
+}
+
+// PhaseFunc 函数式 PhaseSource
+type PhaseFunc func(ctx context.Context) Phase
+
+// Phase 实现 PhaseSource
+func (f PhaseFunc) Phase(ctx context.Context) Phase { return f(ctx) }
+
+// Switch 可动态切换的全局阶段
+type Switch struct {
+	v atomic.Int32
+}
+
+// NewSwitch 创建阶段开关
+func NewSwitch(p Phase) *Switch {
+	s := &Switch{}
+	s.v.Store(int32(p))
+	return s
+}
+
+// Phase 实现 PhaseSource
+func (s *Switch) Phase(context.Context) Phase { return Phase(s.v.Load()) }
+
+// Set 切换阶段
+func (s *Switch) Set(p Phase) { s.v.Store(int32(p)) }
+
+// MismatchKind 不一致类型
+type MismatchKind string
+
+const (
+	MismatchValue      MismatchKind = "value"       // 两侧数据不同
+	MismatchMissingOld MismatchKind = "missing_old" // 旧库缺失
+	MismatchMissingNew MismatchKind = "missing_new" // 新库缺失
+	MismatchError      MismatchKind = "error"       // 比对读取失败
+)
+
+// Mismatch 比对不一致
+type Mismatch struct {
+	Name string
+	ID   string
+	Kind MismatchKind
+	Old  any
+	New  any
+	Err  error
+}
+
+// Option 装饰器选项
+type Option func(*options)
+
+type options struct {
+	name         string
+	log          logger.Logger
+	metrics      observability.MetricsProvider
+	equal        func(old, new any) bool
+	notFound     func(err error) bool
+	onMismatch   func(ctx context.Context, m Mismatch)
+	strictWrites bool
+}
+
+// WithName 设置名称（日志与指标标签）
+func WithName(name string) Option {
+	return func(o *options) { o.name = name }
+}
+
+// WithLogger 设置日志
+func WithLogger(l logger.Logger) Option {
+	return func(o *options) { o.log = l }
+}
+
+// WithMetrics 启用指标：migrate_mismatches_total、migrate_secondary_write_errors_total
+func WithMetrics(p observability.MetricsProvider) Option {
+	return func(o *options) { o.metrics = p }
+}
+
+// WithEqual 自定义比较（默认 reflect.DeepEqual），可忽略更新时间等后端差异字段
+func WithEqual(fn func(old, new any) bool) Option {
+	return func(o *options) { o.equal = fn }
+}
+
+// WithNotFound 识别后端的“不存在”错误（默认识别 errors.NotFound 错误码）
+func WithNotFound(fn func(err error) bool) Option {
+	return func(o *options) { o.notFound = fn }
+}
+
+// WithOnMismatch 设置不一致回调（如写入对账表）
+func WithOnMismatch(fn func(ctx context.Context, m Mismatch)) Option {
+	return func(o *options) { o.onMismatch = fn }
+}
+
+// WithStrictWrites 副库写入失败时返回错误（默认只记录）
+func WithStrictWrites() Option {
+	return func(o *options) { o.strictWrites = true }
+}
+
+// DualRepository 双读双写仓储装饰器
+type DualRepository[T any, ID ddd.Identifier] struct {
+	old, new ddd.Repository[T, ID]
+	phase    PhaseSource
+	opts     options
+
+	mismatches  observability.Counter
+	writeErrors observability.Counter
+}
+
+// NewDualRepository 创建双读双写仓储
+func NewDualRepository[T any, ID ddd.Identifier](old, new ddd.Repository[T, ID], phase PhaseSource, opts ...Option) *DualRepository[T, ID] {
+	o := options{
+		name:  "default",
+		log:   logger.Nop(),
+		equal: reflect.DeepEqual,
+		notFound: func(err error) bool {
+			return errors.IsCode(err, errors.NotFound)
+		},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	metrics := o.metrics
+	if metrics == nil {
+		metrics = observability.NoopMetricsProvider()
+	}
+	return &DualRepository[T, ID]{
+		old:         old,
+		new:         new,
+		phase:       phase,
+		opts:        o,
+		mismatches:  metrics.Counter("migrate_mismatches_total", "Dual-read comparison mismatches", "name", "kind"),
+		writeErrors: metrics.Counter("migrate_secondary_write_errors_total", "Secondary backend write failures", "name", "op"),
+	}
+}
+
+// FindByID 从主库读取，比对阶段同时读取副库并比较
+func (r *DualRepository[T, ID]) FindByID(ctx context.Context, id ID) (*T, error) {
+	p := r.phase.Phase(ctx)
+	primary, secondary := r.old, r.new
+	if p.readsNew() {
+		primary, secondary = r.new, r.old
+	}
+
+	entity, err := primary.FindByID(ctx, id)
+	if err != nil && !r.opts.notFound(err) {
+		return nil, err
+	}
+	if p.compares() {
+		shadow, shadowErr := secondary.FindByID(ctx, id)
+		if p.readsNew() {
+			r.compare(ctx, id, shadow, shadowErr, entity, err)
+		} else {
+			r.compare(ctx, id, entity, err, shadow, shadowErr)
+		}
+	}
+	return entity, err
+}
+
+// Exists 从主库判断
+func (r *DualRepository[T, ID]) Exists(ctx context.Context, id ID) (bool, error) {
+	if r.phase.Phase(ctx).readsNew() {
+		return r.new.Exists(ctx, id)
+	}
+	return r.old.Exists(ctx, id)
+}
+
+// Save 写入主库，成功后写入副库
+func (r *DualRepository[T, ID]) Save(ctx context.Context, entity *T) error {
+	return r.write(ctx, "save", func(repo ddd.Repository[T, ID]) error {
+		return repo.Save(ctx, entity)
+	})
+}
+
+// Delete 从主库删除，成功后从副库删除
+func (r *DualRepository[T, ID]) Delete(ctx context.Context, id ID) error {
+	return r.write(ctx, "delete", func(repo ddd.Repository[T, ID]) error {
+		err := repo.Delete(ctx, id)
+		if err != nil && r.opts.notFound(err) {
+			return nil // 副库可能尚未回填
+		}
+		return err
+	})
+}
+
+func (r *DualRepository[T, ID]) write(ctx context.Context, op string, fn func(ddd.Repository[T, ID]) error) error {
+	p := r.phase.Phase(ctx)
+	primary, secondary := r.old, r.new
+	secondaryOn := p.writesNew()
+	if p.readsNew() {
+		primary, secondary = r.new, r.old
+		secondaryOn = p.writesOld()
+	}
+
+	if err := fn(primary); err != nil {
+		return err
+	}
+	if !secondaryOn {
+		return nil
+	}
+	if err := fn(secondary); err != nil {
+		r.writeErrors.Inc(r.opts.name, op)
+		r.opts.log.Warn(ctx, "migrate: secondary write failed",
+			logger.String("name", r.opts.name),
+			logger.String("op", op),
+			logger.String("phase", p.String()),
+			logger.Err(err),
+		)
+		if r.opts.strictWrites {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *DualRepository[T, ID]) compare(ctx context.Context, id ID, old *T, oldErr error, new *T, newErr error) {
+	m := Mismatch{Name: r.opts.name, ID: id.String()}
+	oldMissing := old == nil && (oldErr == nil || r.opts.notFound(oldErr))
+	newMissing := new == nil && (newErr == nil || r.opts.notFound(newErr))
+
+	switch {
+	case oldErr != nil && !oldMissing, newErr != nil && !newMissing:
+		m.Kind, m.Err = MismatchError, errors.Join(oldErr, newErr)
+	case oldMissing && newMissing:
+		return
+	case oldMissing:
+		m.Kind, m.New = MismatchMissingOld, new
+	case newMissing:
+		m.Kind, m.Old = MismatchMissingNew, old
+	case !r.opts.equal(old, new):
+		m.Kind, m.Old, m.New = MismatchValue, old, new
+	default:
+		return
+	}
+
+	r.mismatches.Inc(r.opts.name, string(m.Kind))
+	r.opts.log.Warn(ctx, "migrate: read mismatch",
+		logger.String("name", m.Name),
+		logger.String("id", m.ID),
+		logger.String("kind", string(m.Kind)),
+		logger.Err(m.Err),
+	)
+	if r.opts.onMismatch != nil {
+		r.opts.onMismatch(ctx, m)
+	}
+}
+
+var _ ddd.Repository[struct{}, ddd.StringID] = (*DualRepository[struct{}, ddd.StringID])(nil)
@@ -0,0 +1,90 @@
+package migrate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mildsunup/higo/ddd"
+	"github.com/mildsunup/higo/errors"
+)
+
+type order struct {
+	ID     ddd.StringID
+	Amount int
+}
+
+type mapRepo map[ddd.StringID]order
+
+func (m mapRepo) FindByID(_ context.Context, id ddd.StringID) (*order, error) {
+	o, ok := m[id]
+	if !ok {
+		return nil, errors.ErrNotFound("order not found")
+	}
+	return &o, nil
+}
+func (m mapRepo) Save(_ context.Context, o *order) error { m[o.ID] = *o; return nil }
+func (m mapRepo) Delete(_ context.Context, id ddd.StringID) error {
+	delete(m, id)
+	return nil
+}
+func (m mapRepo) Exists(_ context.Context, id ddd.StringID) (bool, error) {
+	_, ok := m[id]
+	return ok, nil
+}
+
+func TestDualRepository(t *testing.T) {
+	ctx := context.Background()
+	old, neu := mapRepo{"1": {"1", 10}}, mapRepo{}
+	phase := NewSwitch(PhaseOld)
+
+	var mismatches []Mismatch
+	repo := NewDualRepository[order, ddd.StringID](old, neu, phase,
+		WithOnMismatch(func(_ context.Context, m Mismatch) { mismatches = append(mismatches, m) }))
+
+	_ = repo.Save(ctx, &order{"2", 20})
+	if len(neu) != 0 {
+		t.Fatal("PhaseOld should not write new backend")
+	}
+
+	phase.Set(PhaseDualWrite)
+	_ = repo.Save(ctx, &order{"3", 30})
+	if _, ok := neu["3"]; !ok {
+		t.Fatal("PhaseDualWrite should write new backend")
+	}
+
+	phase.Set(PhaseShadowRead)
+	neu["3"] = order{"3", 31}
+	if o, _ := repo.FindByID(ctx, "1"); o == nil || o.Amount != 10 {
+		t.Fatalf("shadow read should serve old backend, got %v", o)
+	}
+	_, _ = repo.FindByID(ctx, "3")
+	if len(mismatches) != 2 || mismatches[0].Kind != MismatchMissingNew || mismatches[1].Kind != MismatchValue {
+		t.Errorf("unexpected mismatches: %+v", mismatches)
+	}
+
+	phase.Set(PhaseNewPrimary)
+	if o, _ := repo.FindByID(ctx, "3"); o == nil || o.Amount != 31 {
+		t.Errorf("new primary should serve new backend, got %v", o)
+	}
+
+	phase.Set(PhaseNew)
+	_ = repo.Delete(ctx, "3")
+	if _, ok := old["3"]; !ok {
+		t.Error("PhaseNew should not touch old backend")
+	}
+}
+
+func TestParsePhase(t *testing.T) {
+	for in, want := range map[string]Phase{
+		"old": PhaseOld, " Shadow_Read ": PhaseShadowRead, "new_primary": PhaseNewPrimary, "new": PhaseNew,
+	} {
+		if got, err := ParsePhase(in); err != nil || got != want {
+			t.Errorf("ParsePhase(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "dualwrite", "unknown"} {
+		if _, err := ParsePhase(in); !errors.IsCode(err, errors.InvalidArgument) {
+			t.Errorf("ParsePhase(%q) err = %v", in, err)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// Collection 泛型集合，文档按 bson 标签编解码为 T，每次操作生成一个
+// "mongodb.<collection>.<operation>" 客户端 span。
+// 在 WithSession 的 fn 中使用其 context 调用时自动加入事务。
+//
+//	type Order struct {
+//		ID     primitive.ObjectID `bson:"_id,omitempty"`
+//		UserID string             `bson:"user_id"`
+//		Amount int64              `bson:"amount"`
+//	}
+//	orders := mongodb.CollectionOf[Order](store, "orders")
+//	order, err := orders.FindOne(ctx, bson.M{"user_id": uid})
+type Collection[T any] struct {
+	coll   *mongo.Collection
+	tracer trace.Tracer
+}
+
+// CollectionOption 泛型集合选项
+type CollectionOption func(*collectionOptions)
+
+type collectionOptions struct {
+	tracer trace.TracerProvider
+}
+
+// WithCollectionTracer 设置追踪
+func WithCollectionTracer(tracer trace.TracerProvider) CollectionOption {
+	return func(o *collectionOptions) {
+		o.tracer = tracer
+	}
+}
+
+// NewCollection 包装 MongoDB 集合
+func NewCollection[T any](coll *mongo.Collection, opts ...CollectionOption) *Collection[T] {
+	var o collectionOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	tp := o.tracer
+	if tp == nil {
+		tp = noop.NewTracerProvider()
+	}
+	return &Collection[T]{coll: coll, tracer: tp.Tracer(tracerName)}
+}
+
+// CollectionOf 返回存储所在数据库中的泛型集合，沿用存储的追踪配置；需在 Connect 之后调用
+func CollectionOf[T any](s *Storage, name string) *Collection[T] {
+	return NewCollection[T](s.Database().Collection(name), WithCollectionTracer(s.tracer))
+}
+
+// Raw 返回底层集合
+func (c *Collection[T]) Raw() *mongo.Collection { return c.coll }
+
+// Name 返回集合名称
+func (c *Collection[T]) Name() string { return c.coll.Name() }
+
+// Insert 插入文档，返回文档 _id
+func (c *Collection[T]) Insert(ctx context.Context, doc *T, opts ...*options.InsertOneOptions) (id any, err error) {
+	ctx, end := c.start(ctx, "insert")
+	defer func() { end(err) }()
+
+	res, err := c.coll.InsertOne(ctx, doc, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb: insert %s: %w", c.Name(), err)
+	}
+	return res.InsertedID, nil
+}
+
+// InsertMany 批量插入文档，返回各文档 _id
+func (c *Collection[T]) InsertMany(ctx context.Context, docs []T, opts ...*options.InsertManyOptions) (ids []any, err error) {
+	if len(docs) == 0 {
+		return nil, nil
+	}
+	ctx, end := c.start(ctx, "insert_many")
+	defer func() { end(err) }()
+
+	items := make([]any, len(docs))
+	for i := range docs {
+		items[i] = &docs[i]
+	}
+	res, err := c.coll.InsertMany(ctx, items, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb: insert many %s: %w", c.Name(), err)
+	}
+	return res.InsertedIDs, nil
+}
+
+// FindOne 查询单个文档，无结果时返回 mongo.ErrNoDocuments
+func (c *Collection[T]) FindOne(ctx context.Context, filter any, opts ...*options.FindOneOptions) (doc *T, err error) {
+	ctx, end := c.start(ctx, "find_one")
+	defer func() { end(err) }()
+
+	var out T
+	if err := c.coll.FindOne(ctx, filter, opts...).Decode(&out); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("mongodb: find one %s: %w", c.Name(), err)
+	}
+	return &out, nil
+}
+
+// FindByID 按 _id 查询文档，无结果时返回 mongo.ErrNoDocuments
+func (c *Collection[T]) FindByID(ctx context.Context, id any) (*T, error) {
+	return c.FindOne(ctx, bson.M{"_id": id})
+}
+
+// Find 查询全部匹配文档；结果集较大时使用 Each 流式处理
+func (c *Collection[T]) Find(ctx context.Context, filter any, opts ...*options.FindOptions) (docs []T, err error) {
+	ctx, end := c.start(ctx, "find")
+	defer func() { end(err) }()
+
+	cur, err := c.coll.Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb: find %s: %w", c.Name(), err)
+	}
+	docs = make([]T, 0)
+	if err := cur.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("mongodb: decode %s: %w", c.Name(), err)
+	}
+	return docs, nil
+}
+
+// Each 以游标流式遍历匹配文档，逐个解码后调用 fn，fn 返回错误时停止遍历并返回该错误
+//
+//	err := orders.Each(ctx, bson.M{"status": "paid"}, func(ctx context.Context, o Order) error {
+//		return exporter.Write(o)
+//	}, options.Find().SetBatchSize(500))
+func (c *Collection[T]) Each(ctx context.Context, filter any, fn func(ctx context.Context, doc T) error, opts ...*options.FindOptions) (err error) {
+	ctx, end := c.start(ctx, "each")
+	defer func() { end(err) }()
+
+	cur, err := c.coll.Find(ctx, filter, opts...)
+	if err != nil {
+		return fmt.Errorf("mongodb: find %s: %w", c.Name(), err)
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var doc T
+		if err := cur.Decode(&doc); err != nil {
+			return fmt.Errorf("mongodb: decode %s: %w", c.Name(), err)
+		}
+		if err := fn(ctx, doc); err != nil {
+			return err
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return fmt.Errorf("mongodb: cursor %s: %w", c.Name(), err)
+	}
+	return nil
+}
+
+// Count 统计匹配文档数量
+func (c *Collection[T]) Count(ctx context.Context, filter any, opts ...*options.CountOptions) (n int64, err error) {
+	ctx, end := c.start(ctx, "count")
+	defer func() { end(err) }()
+
+	n, err = c.coll.CountDocuments(ctx, filter, opts...)
+	if err != nil {
+		return 0, fmt.Errorf("mongodb: count %s: %w", c.Name(), err)
+	}
+	return n, nil
+}
+
+// UpdateOne 更新单个匹配文档
+func (c *Collection[T]) UpdateOne(ctx context.Context, filter, update any, opts ...*options.UpdateOptions) (res *mongo.UpdateResult, err error) {
+	ctx, end := c.start(ctx, "update_one")
+	defer func() { end(err) }()
+
+	res, err = c.coll.UpdateOne(ctx, filter, update, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb: update one %s: %w", c.Name(), err)
+	}
+	return res, nil
+}
+
+// UpdateByID 按 _id 更新文档
+func (c *Collection[T]) UpdateByID(ctx context.Context, id, update any, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return c.UpdateOne(ctx, bson.M{"_id": id}, update, opts...)
+}
+
+// UpdateMany 更新全部匹配文档
+func (c *Collection[T]) UpdateMany(ctx context.Context, filter, update any, opts ...*options.UpdateOptions) (res *mongo.UpdateResult, err error) {
+	ctx, end := c.start(ctx, "update_many")
+	defer func() { end(err) }()
+
+	res, err = c.coll.UpdateMany(ctx, filter, update, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb: update many %s: %w", c.Name(), err)
+	}
+	return res, nil
+}
+
+// Replace 整体替换单个匹配文档
+func (c *Collection[T]) Replace(ctx context.Context, filter any, doc *T, opts ...*options.ReplaceOptions) (res *mongo.UpdateResult, err error) {
+	ctx, end := c.start(ctx, "replace")
+	defer func() { end(err) }()
+
+	res, err = c.coll.ReplaceOne(ctx, filter, doc, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb: replace %s: %w", c.Name(), err)
+	}
+	return res, nil
+}
+
+// DeleteOne 删除单个匹配文档，返回删除数量
+func (c *Collection[T]) DeleteOne(ctx context.Context, filter any, opts ...*options.DeleteOptions) (n int64, err error) {
+	ctx, end := c.start(ctx, "delete_one")
+	defer func() { end(err) }()
+
+	res, err := c.coll.DeleteOne(ctx, filter, opts...)
+	if err != nil {
+		return 0, fmt.Errorf("mongodb: delete one %s: %w", c.Name(), err)
+	}
+	return res.DeletedCount, nil
+}
+
+// DeleteByID 按 _id 删除文档，返回删除数量
+func (c *Collection[T]) DeleteByID(ctx context.Context, id any) (int64, error) {
+	return c.DeleteOne(ctx, bson.M{"_id": id})
+}
+
+// DeleteMany 删除全部匹配文档，返回删除数量
+func (c *Collection[T]) DeleteMany(ctx context.Context, filter any, opts ...*options.DeleteOptions) (n int64, err error) {
+	ctx, end := c.start(ctx, "delete_many")
+	defer func() { end(err) }()
+
+	res, err := c.coll.DeleteMany(ctx, filter, opts...)
+	if err != nil {
+		return 0, fmt.Errorf("mongodb: delete many %s: %w", c.Name(), err)
+	}
+	return res.DeletedCount, nil
+}
+
+// EnsureIndexes 创建索引，已存在的同名同定义索引不报错；返回索引名称
+//
+//	_, err := orders.EnsureIndexes(ctx,
+//		mongo.IndexModel{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+//		mongo.IndexModel{Keys: bson.D{{Key: "order_no", Value: 1}}, Options: options.Index().SetUnique(true)},
+//	)
+func (c *Collection[T]) EnsureIndexes(ctx context.Context, models ...mongo.IndexModel) (names []string, err error) {
+	if len(models) == 0 {
+		return nil, nil
+	}
+	ctx, end := c.start(ctx, "create_indexes")
+	defer func() { end(err) }()
+
+	names, err = c.coll.Indexes().CreateMany(ctx, models)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb: create indexes %s: %w", c.Name(), err)
+	}
+	return names, nil
+}
+
+// start 开始操作 span，返回的 end 记录错误并结束 span；无结果不视为失败
+func (c *Collection[T]) start(ctx context.Context, op string) (context.Context, func(error)) {
+	ctx, span := c.tracer.Start(ctx, "mongodb."+c.Name()+"."+op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "mongodb"),
+			attribute.String("db.namespace", c.coll.Database().Name()),
+			attribute.String("db.collection.name", c.Name()),
+			attribute.String("db.operation.name", op),
+			attribute.Bool("db.mongodb.in_session", mongo.SessionFromContext(ctx) != nil),
+		),
+	)
+	return ctx, func(err error) {
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
@@ -0,0 +1,156 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type testOrder struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty"`
+	UserID string             `bson:"user_id"`
+	Amount int64              `bson:"amount"`
+}
+
+func newTestTracer() (*sdktrace.TracerProvider, *tracetest.SpanRecorder) {
+	rec := tracetest.NewSpanRecorder()
+	return sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)), rec
+}
+
+func spanAttr(s sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range s.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func orderDoc(id primitive.ObjectID, user string, amount int64) bson.D {
+	return bson.D{{Key: "_id", Value: id}, {Key: "user_id", Value: user}, {Key: "amount", Value: amount}}
+}
+
+func TestCollection(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("crud", func(mt *mtest.T) {
+		ctx := context.Background()
+		tp, rec := newTestTracer()
+		orders := NewCollection[testOrder](mt.DB.Collection("orders"), WithCollectionTracer(tp))
+		ns := mt.DB.Name() + ".orders"
+		id1, id2 := primitive.NewObjectID(), primitive.NewObjectID()
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		id, err := orders.Insert(ctx, &testOrder{UserID: "u1", Amount: 5})
+		if err != nil {
+			mt.Fatal(err)
+		}
+		if oid, ok := id.(primitive.ObjectID); !ok || oid.IsZero() {
+			mt.Fatalf("inserted id = %v", id)
+		}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, orderDoc(id1, "u1", 5)))
+		got, err := orders.FindByID(ctx, id1)
+		if err != nil || got.ID != id1 || got.UserID != "u1" || got.Amount != 5 {
+			mt.Fatalf("find by id = %+v, %v", got, err)
+		}
+		if cmd := mt.GetStartedEvent(); cmd == nil || cmd.CommandName != "insert" {
+			mt.Fatalf("first command = %+v", cmd)
+		}
+		if cmd := mt.GetStartedEvent(); cmd.CommandName != "find" ||
+			cmd.Command.Lookup("filter", "_id").ObjectID() != id1 {
+			mt.Fatalf("find command = %v", cmd.Command)
+		}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch))
+		if _, err := orders.FindOne(ctx, bson.M{"user_id": "nobody"}); !errors.Is(err, mongo.ErrNoDocuments) {
+			mt.Fatalf("find missing = %v", err)
+		}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, orderDoc(id1, "u1", 5), orderDoc(id2, "u1", 7)))
+		all, err := orders.Find(ctx, bson.M{"user_id": "u1"})
+		if err != nil || len(all) != 2 || all[1].Amount != 7 {
+			mt.Fatalf("find = %+v, %v", all, err)
+		}
+
+		// 跨批次流式遍历，fn 出错时停止
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(1, ns, mtest.FirstBatch, orderDoc(id1, "u1", 5)),
+			mtest.CreateCursorResponse(0, ns, mtest.NextBatch, orderDoc(id2, "u1", 7)),
+		)
+		var sum int64
+		if err := orders.Each(ctx, bson.M{}, func(_ context.Context, o testOrder) error {
+			sum += o.Amount
+			return nil
+		}); err != nil || sum != 12 {
+			mt.Fatalf("each = %d, %v", sum, err)
+		}
+		stop := errors.New("stop")
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(1, ns, mtest.FirstBatch, orderDoc(id1, "u1", 5)),
+			mtest.CreateSuccessResponse(), // killCursors
+		)
+		if err := orders.Each(ctx, bson.M{}, func(context.Context, testOrder) error { return stop }); !errors.Is(err, stop) {
+			mt.Fatalf("each stop = %v", err)
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+		res, err := orders.UpdateByID(ctx, id1, bson.M{"$inc": bson.M{"amount": 1}})
+		if err != nil || res.MatchedCount != 1 || res.ModifiedCount != 1 {
+			mt.Fatalf("update = %+v, %v", res, err)
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}))
+		if n, err := orders.DeleteMany(ctx, bson.M{"user_id": "u1"}); err != nil || n != 2 {
+			mt.Fatalf("delete many = %d, %v", n, err)
+		}
+
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 11000, Message: "duplicate key"}))
+		_, err = orders.Insert(ctx, &testOrder{ID: id1})
+		if !mongo.IsDuplicateKeyError(err) {
+			mt.Fatalf("duplicate insert = %v", err)
+		}
+
+		if _, err := orders.InsertMany(ctx, nil); err != nil {
+			mt.Fatalf("empty insert many = %v", err)
+		}
+
+		spans := rec.Ended()
+		names := make([]string, len(spans))
+		for i, s := range spans {
+			names[i] = s.Name()
+		}
+		want := []string{
+			"mongodb.orders.insert", "mongodb.orders.find_one", "mongodb.orders.find_one", "mongodb.orders.find",
+			"mongodb.orders.each", "mongodb.orders.each", "mongodb.orders.update_one", "mongodb.orders.delete_many",
+			"mongodb.orders.insert",
+		}
+		if len(names) != len(want) {
+			mt.Fatalf("spans = %v", names)
+		}
+		for i := range want {
+			if names[i] != want[i] {
+				mt.Fatalf("spans = %v, want %v", names, want)
+			}
+		}
+		// 无结果不视为失败，写入错误记录到 span
+		if spans[2].Status().Code == codes.Error {
+			mt.Fatal("no documents marked as span error")
+		}
+		if last := spans[len(spans)-1]; last.Status().Code != codes.Error ||
+			spanAttr(last, "db.collection.name").AsString() != "orders" ||
+			spanAttr(last, "db.operation.name").AsString() != "insert" ||
+			spanAttr(last, "db.mongodb.in_session").AsBool() {
+			mt.Fatalf("insert span = %v %v", last.Status(), last.Attributes())
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/mildsunup/higo/storage/geo"
+)
+
+// GeoIndex 基于 MongoDB 2dsphere 索引的位置索引
+// 文档结构：{_id: member, <field>: {type: "Point", coordinates: [lon, lat]}}
+type GeoIndex struct {
+	coll  *mongo.Collection
+	field string
+}
+
+// NewGeoIndex 创建位置索引
+func NewGeoIndex(coll *mongo.Collection) *GeoIndex {
+	return &GeoIndex{coll: coll, field: "location"}
+}
+
+// EnsureIndex 创建 2dsphere 索引
+func (g *GeoIndex) EnsureIndex(ctx context.Context) error {
+	_, err := g.coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: g.field, Value: "2dsphere"}},
+	})
+	if err != nil {
+		return fmt.Errorf("mongodb: create 2dsphere index: %w", err)
+	}
+	return nil
+}
+
+// Add 添加或更新位置
+func (g *GeoIndex) Add(ctx context.Context, locations ...geo.Location) error {
+	if len(locations) == 0 {
+		return nil
+	}
+	models := make([]mongo.WriteModel, len(locations))
+	for i, l := range locations {
+		models[i] = mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": l.Member}).
+			SetUpdate(bson.M{"$set": bson.M{g.field: bson.M{
+				"type":        "Point",
+				"coordinates": bson.A{l.Longitude, l.Latitude},
+			}}}).
+			SetUpsert(true)
+	}
+	if _, err := g.coll.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+		return fmt.Errorf("mongodb: geo add: %w", err)
+	}
+	return nil
+}
+
+// Remove 移除位置
+func (g *GeoIndex) Remove(ctx context.Context, members ...string) error {
+	if len(members) == 0 {
+		return nil
+	}
+	if _, err := g.coll.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": members}}); err != nil {
+		return fmt.Errorf("mongodb: geo remove: %w", err)
+	}
+	return nil
+}
+
+// SearchRadius 按半径搜索
+func (g *GeoIndex) SearchRadius(ctx context.Context, q geo.Query) ([]geo.Result, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$geoNear", Value: bson.M{
+			"near":          bson.M{"type": "Point", "coordinates": bson.A{q.Longitude, q.Latitude}},
+			"distanceField": "_dist",
+			"maxDistance":   q.Radius,
+			"spherical":     true,
+			"key":           g.field,
+		}}},
+	}
+	if q.Offset > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$skip", Value: q.Offset}})
+	}
+	if q.Limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: q.Limit}})
+	}
+
+	cur, err := g.coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb: geo search: %w", err)
+	}
+	defer cur.Close(ctx)
+
+	var results []geo.Result
+	for cur.Next(ctx) {
+		var doc struct {
+			ID       string  `bson:"_id"`
+			Distance float64 `bson:"_dist"`
+			Location struct {
+				Coordinates []float64 `bson:"coordinates"`
+			} `bson:"location"`
+		}
+		if err := cur.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("mongodb: decode geo result: %w", err)
+		}
+		r := geo.Result{Location: geo.Location{Member: doc.ID}, Distance: doc.Distance}
+		if len(doc.Location.Coordinates) == 2 {
+			r.Longitude, r.Latitude = doc.Location.Coordinates[0], doc.Location.Coordinates[1]
+		}
+		results = append(results, r)
+	}
+	if err := cur.Err(); err != nil {
+		return nil, fmt.Errorf("mongodb: geo search: %w", err)
+	}
+	return results, nil
+}
+
+var _ geo.Index = (*GeoIndex)(nil)
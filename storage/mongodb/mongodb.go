@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
@@ -124,10 +125,27 @@ func (s *Storage) Close(ctx context.Context) error {
 	return err
 }
 
+// ServerVersion 返回 MongoDB 服务端版本
+func (s *Storage) ServerVersion(ctx context.Context) (string, error) {
+	if s.client == nil {
+		return "", fmt.Errorf("mongodb: not connected")
+	}
+	var info struct {
+		Version string `bson:"version"`
+	}
+	if err := s.client.Database("admin").RunCommand(ctx, bson.D{{Key: "buildInfo", Value: 1}}).Decode(&info); err != nil {
+		return "", fmt.Errorf("mongodb: build info: %w", err)
+	}
+	return info.Version, nil
+}
+
 // Client 返回 MongoDB 客户端
 func (s *Storage) Client() *mongo.Client { return s.client }
 
 // Database 返回数据库实例
 func (s *Storage) Database() *mongo.Database { return s.database }
 
-var _ storage.Storage = (*Storage)(nil)
+var (
+	_ storage.Storage         = (*Storage)(nil)
+	_ storage.VersionProvider = (*Storage)(nil)
+)
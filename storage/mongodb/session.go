@@ -0,0 +1,71 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const tracerName = "github.com/mildsunup/higo/storage/mongodb"
+
+// WithSession 在多文档事务中执行 fn，fn 返回错误时回滚，否则提交。
+// fn 收到的 context 绑定了会话，使用该 context 的集合操作（含 Collection[T]）自动加入事务；
+// 瞬时错误（TransientTransactionError、UnknownTransactionCommitResult）由驱动自动重试，fn 需可重入。
+// context 已处于会话中时直接在当前事务内执行 fn。要求副本集或分片集群部署。
+//
+//	err := store.WithSession(ctx, func(ctx context.Context) error {
+//		if _, err := orders.Insert(ctx, &order); err != nil {
+//			return err
+//		}
+//		_, err := stocks.UpdateByID(ctx, order.SKU, bson.M{"$inc": bson.M{"qty": -order.Qty}})
+//		return err
+//	})
+func (s *Storage) WithSession(ctx context.Context, fn func(ctx context.Context) error, opts ...*options.TransactionOptions) error {
+	if s.client == nil {
+		return fmt.Errorf("mongodb: not connected")
+	}
+	if mongo.SessionFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
+	ctx, span := s.startSpan(ctx, "mongodb.transaction",
+		attribute.String("db.system", "mongodb"),
+		attribute.String("db.namespace", s.config.Database),
+	)
+	defer span.End()
+
+	sess, err := s.client.StartSession()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("mongodb: start session: %w", err)
+	}
+	defer sess.EndSession(ctx)
+
+	_, err = sess.WithTransaction(ctx, func(sc mongo.SessionContext) (any, error) {
+		return nil, fn(sc)
+	}, opts...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	return nil
+}
+
+func (s *Storage) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	tp := s.tracer
+	if tp == nil {
+		tp = noop.NewTracerProvider()
+	}
+	return tp.Tracer(tracerName).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
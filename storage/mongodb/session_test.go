@@ -0,0 +1,90 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestWithSession(t *testing.T) {
+	if err := New(Config{}).WithSession(context.Background(), func(context.Context) error { return nil }); err == nil {
+		t.Fatal("expected error before connect")
+	}
+
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("commit", func(mt *mtest.T) {
+		tp, rec := newTestTracer()
+		s := New(Config{Database: mt.DB.Name()}, WithTracer(tp))
+		s.client, s.database = mt.Client, mt.DB
+		orders := CollectionOf[testOrder](s, "orders")
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(), mtest.CreateSuccessResponse())
+		calls := 0
+		err := s.WithSession(context.Background(), func(ctx context.Context) error {
+			calls++
+			if mongo.SessionFromContext(ctx) == nil {
+				mt.Fatal("fn ctx not bound to a session")
+			}
+			// 已在会话中：嵌套调用直接加入当前事务
+			return s.WithSession(ctx, func(ctx context.Context) error {
+				_, err := orders.Insert(ctx, &testOrder{UserID: "u1"})
+				return err
+			})
+		})
+		if err != nil || calls != 1 {
+			mt.Fatalf("with session = %v, calls = %d", err, calls)
+		}
+
+		insert := mt.GetStartedEvent()
+		if insert.CommandName != "insert" || !insert.Command.Lookup("startTransaction").Boolean() {
+			mt.Fatalf("insert = %v", insert.Command)
+		}
+		lsid := insert.Command.Lookup("lsid")
+		if commit := mt.GetStartedEvent(); commit.CommandName != "commitTransaction" || !commit.Command.Lookup("lsid").Equal(lsid) {
+			mt.Fatalf("commit = %v", commit.Command)
+		}
+
+		spans := rec.Ended()
+		if len(spans) != 2 || spans[0].Name() != "mongodb.orders.insert" || spans[1].Name() != "mongodb.transaction" {
+			mt.Fatalf("spans = %v", spans)
+		}
+		if !spanAttr(spans[0], "db.mongodb.in_session").AsBool() || spans[0].Parent().SpanID() != spans[1].SpanContext().SpanID() {
+			mt.Fatal("collection span not inside the transaction span")
+		}
+	})
+
+	mt.Run("abort", func(mt *mtest.T) {
+		tp, rec := newTestTracer()
+		s := New(Config{Database: mt.DB.Name()}, WithTracer(tp))
+		s.client, s.database = mt.Client, mt.DB
+		orders := CollectionOf[testOrder](s, "orders")
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(), mtest.CreateSuccessResponse())
+		rejected := errors.New("out of stock")
+		err := s.WithSession(context.Background(), func(ctx context.Context) error {
+			if _, err := orders.UpdateOne(ctx, bson.M{"sku": "a"}, bson.M{"$inc": bson.M{"qty": -1}}); err != nil {
+				return err
+			}
+			return rejected
+		})
+		if !errors.Is(err, rejected) {
+			mt.Fatalf("with session = %v", err)
+		}
+		if update := mt.GetStartedEvent(); update.CommandName != "update" {
+			mt.Fatalf("update = %v", update.Command)
+		}
+		if abort := mt.GetStartedEvent(); abort == nil || abort.CommandName != "abortTransaction" {
+			mt.Fatalf("abort = %+v", abort)
+		}
+		spans := rec.Ended()
+		if tx := spans[len(spans)-1]; tx.Name() != "mongodb.transaction" || tx.Status().Code != codes.Error {
+			mt.Fatalf("transaction span = %s %v", tx.Name(), tx.Status())
+		}
+	})
+}
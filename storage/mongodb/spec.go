@@ -0,0 +1,98 @@
+package mongodb
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/mildsunup/higo/ddd"
+)
+
+// SpecFilter 将规约编译为 MongoDB 过滤器
+//
+//	filter, err := mongodb.SpecFilter(spec)
+//	cur, err := coll.Find(ctx, filter)
+func SpecFilter[T any](spec ddd.Specification[T]) (bson.M, error) {
+	c, ok := ddd.CriteriaOf(spec)
+	if !ok {
+		return nil, fmt.Errorf("mongodb: specification is not queryable")
+	}
+	return compileCriteria(c)
+}
+
+var mongoOps = map[ddd.Operator]string{
+	ddd.OpEq:  "$eq",
+	ddd.OpNe:  "$ne",
+	ddd.OpGt:  "$gt",
+	ddd.OpGte: "$gte",
+	ddd.OpLt:  "$lt",
+	ddd.OpLte: "$lte",
+	ddd.OpIn:  "$in",
+}
+
+func compileCriteria(c ddd.Criteria) (bson.M, error) {
+	switch c.Op {
+	case ddd.OpLike:
+		pattern, ok := c.Value.(string)
+		if !ok {
+			return nil, fmt.Errorf("mongodb: like criteria on %q requires string", c.Field)
+		}
+		return bson.M{c.Field: bson.M{"$regex": likeToRegex(pattern)}}, nil
+	case ddd.OpIsNull:
+		return bson.M{c.Field: nil}, nil
+	case ddd.OpAnd, ddd.OpOr:
+		filters, err := compileChildren(c.Children)
+		if err != nil {
+			return nil, err
+		}
+		if len(filters) == 0 {
+			// 空 And 恒真，空 Or 恒假
+			return bson.M{"$expr": c.Op == ddd.OpAnd}, nil
+		}
+		return bson.M{"$" + string(c.Op): filters}, nil
+	case ddd.OpNot:
+		filters, err := compileChildren(c.Children)
+		if err != nil {
+			return nil, err
+		}
+		return bson.M{"$nor": filters}, nil
+	}
+
+	op, ok := mongoOps[c.Op]
+	if !ok {
+		return nil, fmt.Errorf("mongodb: unsupported criteria operator %q", c.Op)
+	}
+	return bson.M{c.Field: bson.M{op: c.Value}}, nil
+}
+
+func compileChildren(children []ddd.Criteria) (bson.A, error) {
+	filters := make(bson.A, 0, len(children))
+	for _, child := range children {
+		f, err := compileCriteria(child)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
+	}
+	return filters, nil
+}
+
+// likeToRegex 将 SQL LIKE 模式转换为正则
+func likeToRegex(pattern string) string {
+	out := make([]rune, 0, len(pattern)+2)
+	out = append(out, '^')
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			out = append(out, '.', '*')
+		case '_':
+			out = append(out, '.')
+		case '.', '*', '+', '?', '(', ')', '[', ']', '{', '}', '^', '$', '|', '\\':
+			out = append(out, '\\', r)
+		default:
+			out = append(out, r)
+		}
+	}
+	out = append(out, '$')
+	return string(out)
+}
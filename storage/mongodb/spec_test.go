@@ -0,0 +1,60 @@
+package mongodb
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/mildsunup/higo/ddd"
+)
+
+func TestSpecFilter(t *testing.T) {
+	userID := func(o testOrder) string { return o.UserID }
+	amount := func(o testOrder) int64 { return o.Amount }
+	u1 := ddd.Eq("user_id", "u1", userID)
+	big := ddd.Gte("amount", int64(100), amount)
+
+	tests := []struct {
+		name string
+		spec ddd.Specification[testOrder]
+		want bson.M
+	}{
+		{"and", ddd.And(u1, big), bson.M{"$and": bson.A{
+			bson.M{"user_id": bson.M{"$eq": "u1"}},
+			bson.M{"amount": bson.M{"$gte": int64(100)}},
+		}}},
+		// $nor 对整个子条件取反，等价于 NOT (a AND b)
+		{"not and", ddd.Not(ddd.And(u1, big)), bson.M{"$nor": bson.A{
+			bson.M{"$and": bson.A{
+				bson.M{"user_id": bson.M{"$eq": "u1"}},
+				bson.M{"amount": bson.M{"$gte": int64(100)}},
+			}},
+		}}},
+		{"or of not", ddd.Or(ddd.Not(u1), big), bson.M{"$or": bson.A{
+			bson.M{"$nor": bson.A{bson.M{"user_id": bson.M{"$eq": "u1"}}}},
+			bson.M{"amount": bson.M{"$gte": int64(100)}},
+		}}},
+		{"empty in", ddd.In("user_id", []string{}, userID), bson.M{"user_id": bson.M{"$in": []any{}}}},
+		{"empty and", ddd.All[testOrder](), bson.M{"$expr": true}},
+		{"empty or", ddd.Any[testOrder](), bson.M{"$expr": false}},
+		{"like", ddd.Where("user_id", ddd.OpLike, "u_.%", func(testOrder) bool { return true }), bson.M{"user_id": bson.M{"$regex": `^u.\..*$`}}},
+		{"not deleted", ddd.Where(ddd.DeletedAtField, ddd.OpIsNull, nil, func(testOrder) bool { return true }), bson.M{"deleted_at": nil}},
+	}
+	for _, tt := range tests {
+		got, err := SpecFilter(tt.spec)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: filter = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := SpecFilter(ddd.And(u1, ddd.Spec(func(testOrder) bool { return true }))); err == nil {
+		t.Fatal("expected error for non-queryable child")
+	}
+	if _, err := SpecFilter(ddd.Where("user_id", ddd.OpLike, 42, func(testOrder) bool { return true })); err == nil {
+		t.Fatal("expected error for non-string like pattern")
+	}
+}
@@ -0,0 +1,251 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mildsunup/higo/observability"
+)
+
+// Health 存储健康状态（由 Manager 的健康监控维护，与连接状态 State 相互独立）
+type Health int32
+
+const (
+	HealthHealthy  Health = iota // 正常
+	HealthDegraded               // 连续 Ping 失败，正在后台重连
+)
+
+func (h Health) String() string {
+	switch h {
+	case HealthHealthy:
+		return "healthy"
+	case HealthDegraded:
+		return "degraded"
+	}
+	return "unknown"
+}
+
+// MonitorConfig 健康监控配置
+type MonitorConfig struct {
+	Interval         time.Duration   // Ping 间隔，默认 15 秒
+	Timeout          time.Duration   // 单次 Ping 与重连超时，默认 5 秒
+	FailureThreshold int             // 连续失败多少次标记为降级，默认 3
+	Backoff          ReconnectConfig // 重连退避，MaxRetries 不生效（持续重连直到成功或 CloseAll）
+}
+
+// ManagerOption 存储管理器选项
+type ManagerOption func(*manager)
+
+// WithMonitor 启用健康监控：ConnectAll 后定期 Ping 全部存储，连续失败达到阈值时标记为降级，
+// 并在后台按指数退避关闭、重新连接，成功后恢复为正常；CloseAll 时停止。
+// 数据库主从切换等故障后无需重启进程
+func WithMonitor(cfg MonitorConfig) ManagerOption {
+	return func(m *manager) {
+		if cfg.Interval <= 0 {
+			cfg.Interval = 15 * time.Second
+		}
+		if cfg.Timeout <= 0 {
+			cfg.Timeout = 5 * time.Second
+		}
+		if cfg.FailureThreshold <= 0 {
+			cfg.FailureThreshold = 3
+		}
+		if cfg.Backoff.InitialInterval <= 0 {
+			cfg.Backoff.InitialInterval = time.Second
+		}
+		if cfg.Backoff.MaxInterval <= 0 {
+			cfg.Backoff.MaxInterval = time.Minute
+		}
+		if cfg.Backoff.Multiplier <= 0 {
+			cfg.Backoff.Multiplier = 2
+		}
+		m.monitor = &cfg
+	}
+}
+
+// WithHealthChangeHandler 设置健康状态变更回调，err 为触发降级的最后一次 Ping 错误（恢复时为 nil）
+func WithHealthChangeHandler(fn func(name string, from, to Health, err error)) ManagerOption {
+	return func(m *manager) { m.onHealthChange = fn }
+}
+
+// WithMonitorMetrics 发布健康监控指标：
+// storage_health{name,type}（0=healthy, 1=degraded）、
+// storage_health_transitions_total{name,type,to}、storage_reconnects_total{name,type,result}
+func WithMonitorMetrics(p observability.MetricsProvider) ManagerOption {
+	return func(m *manager) {
+		m.monitorMetrics = &monitorMetrics{
+			health:      p.Gauge("storage_health", "Storage health (0=healthy, 1=degraded)", "name", "type"),
+			transitions: p.Counter("storage_health_transitions_total", "Storage health transitions", "name", "type", "to"),
+			reconnects:  p.Counter("storage_reconnects_total", "Storage reconnect attempts", "name", "type", "result"),
+		}
+	}
+}
+
+type monitorMetrics struct {
+	health      observability.Gauge
+	transitions observability.Counter
+	reconnects  observability.Counter
+}
+
+// healthEntry 单个存储的健康状态
+type healthEntry struct {
+	health   Health
+	failures int
+	lastErr  error
+}
+
+func (m *manager) startMonitor() {
+	if m.monitor == nil {
+		return
+	}
+	m.monitorMu.Lock()
+	defer m.monitorMu.Unlock()
+	if m.stopMonitor != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.stopMonitor = cancel
+	m.monitorWG.Go(func() { m.runMonitor(ctx) })
+}
+
+func (m *manager) stopMonitoring() {
+	m.monitorMu.Lock()
+	cancel := m.stopMonitor
+	m.stopMonitor = nil
+	m.monitorMu.Unlock()
+	if cancel != nil {
+		cancel()
+		m.monitorWG.Wait()
+	}
+}
+
+func (m *manager) runMonitor(ctx context.Context) {
+	ticker := time.NewTicker(m.monitor.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		var wg sync.WaitGroup
+		for _, s := range m.ordered() {
+			if m.Health(s.Name()) == HealthDegraded {
+				continue // 由重连协程负责
+			}
+			wg.Go(func() { m.probe(ctx, s) })
+		}
+		wg.Wait()
+	}
+}
+
+// probe Ping 一次，连续失败达到阈值时标记降级并启动重连
+func (m *manager) probe(ctx context.Context, s Storage) {
+	pctx, cancel := context.WithTimeout(ctx, m.monitor.Timeout)
+	err := s.Ping(pctx)
+	cancel()
+	if ctx.Err() != nil {
+		return
+	}
+
+	m.healthMu.Lock()
+	e := m.healthEntry(s.Name())
+	if err == nil {
+		e.failures, e.lastErr = 0, nil
+		m.healthMu.Unlock()
+		return
+	}
+	e.failures++
+	e.lastErr = err
+	degrade := e.failures >= m.monitor.FailureThreshold
+	m.healthMu.Unlock()
+
+	if degrade {
+		m.setHealth(s, HealthDegraded, err)
+		m.monitorWG.Go(func() { m.reconnect(ctx, s) })
+	}
+}
+
+// reconnect 按指数退避关闭并重新连接，直到 Ping 成功或监控停止
+func (m *manager) reconnect(ctx context.Context, s Storage) {
+	interval := m.monitor.Backoff.InitialInterval
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+
+		rctx, cancel := context.WithTimeout(ctx, m.monitor.Timeout)
+		_ = s.Close(rctx)
+		err := s.Connect(rctx)
+		if err == nil {
+			err = s.Ping(rctx)
+		}
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err == nil {
+			m.countReconnect(s, "success")
+			m.healthMu.Lock()
+			e := m.healthEntry(s.Name())
+			e.failures, e.lastErr = 0, nil
+			m.healthMu.Unlock()
+			m.setHealth(s, HealthHealthy, nil)
+			return
+		}
+		m.countReconnect(s, "failure")
+		m.healthMu.Lock()
+		m.healthEntry(s.Name()).lastErr = err
+		m.healthMu.Unlock()
+
+		interval = min(time.Duration(float64(interval)*m.monitor.Backoff.Multiplier), m.monitor.Backoff.MaxInterval)
+	}
+}
+
+// healthEntry 获取或创建健康状态，调用方需持有 healthMu
+func (m *manager) healthEntry(name string) *healthEntry {
+	e, ok := m.health[name]
+	if !ok {
+		e = &healthEntry{}
+		m.health[name] = e
+	}
+	return e
+}
+
+func (m *manager) setHealth(s Storage, to Health, err error) {
+	m.healthMu.Lock()
+	e := m.healthEntry(s.Name())
+	from := e.health
+	e.health = to
+	m.healthMu.Unlock()
+	if from == to {
+		return
+	}
+	if mm := m.monitorMetrics; mm != nil {
+		mm.health.Set(float64(to), s.Name(), string(s.Type()))
+		mm.transitions.Inc(s.Name(), string(s.Type()), to.String())
+	}
+	if m.onHealthChange != nil {
+		m.onHealthChange(s.Name(), from, to, err)
+	}
+}
+
+func (m *manager) countReconnect(s Storage, result string) {
+	if mm := m.monitorMetrics; mm != nil {
+		mm.reconnects.Inc(s.Name(), string(s.Type()), result)
+	}
+}
+
+// Health 返回存储的健康状态，未启用监控或未知存储时为 HealthHealthy
+func (m *manager) Health(name string) Health {
+	m.healthMu.Lock()
+	defer m.healthMu.Unlock()
+	if e, ok := m.health[name]; ok {
+		return e.health
+	}
+	return HealthHealthy
+}
@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyStorage Ping 在 down 时失败，Connect 在 down 时失败
+type flakyStorage struct {
+	*Base
+	down     atomic.Bool
+	connects atomic.Int32
+}
+
+func (f *flakyStorage) Connect(ctx context.Context) error {
+	f.connects.Add(1)
+	if f.down.Load() {
+		return errors.New("connection refused")
+	}
+	f.SetState(StateConnected)
+	return nil
+}
+
+func (f *flakyStorage) Ping(ctx context.Context) error {
+	if f.down.Load() {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func (f *flakyStorage) Close(ctx context.Context) error {
+	f.SetState(StateDisconnected)
+	return nil
+}
+
+func TestManager_MonitorReconnect(t *testing.T) {
+	var (
+		mu          sync.Mutex
+		transitions []Health
+	)
+	m := NewManager(
+		WithMonitor(MonitorConfig{
+			Interval:         5 * time.Millisecond,
+			FailureThreshold: 2,
+			Backoff:          ReconnectConfig{InitialInterval: 5 * time.Millisecond, MaxInterval: 20 * time.Millisecond},
+		}),
+		WithHealthChangeHandler(func(name string, from, to Health, err error) {
+			mu.Lock()
+			transitions = append(transitions, to)
+			mu.Unlock()
+		}),
+	)
+	s := &flakyStorage{Base: NewBase("db", TypeMySQL)}
+	_ = m.Register(s)
+	ctx := context.Background()
+	if err := m.ConnectAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer m.CloseAll(ctx)
+
+	s.down.Store(true)
+	waitUntil(t, func() bool { return m.Health("db") == HealthDegraded })
+	if st := m.HealthCheck(ctx); !st[0].Degraded || st[0].Healthy {
+		t.Fatalf("unexpected health status %+v", st[0])
+	}
+
+	connects := s.connects.Load()
+	s.down.Store(false)
+	waitUntil(t, func() bool { return m.Health("db") == HealthHealthy })
+	if s.connects.Load() <= connects {
+		t.Fatal("expected reconnect after recovery")
+	}
+	if s.State() != StateConnected {
+		t.Fatalf("state = %v", s.State())
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(transitions) != 2 || transitions[0] != HealthDegraded || transitions[1] != HealthHealthy {
+		t.Fatalf("transitions = %v", transitions)
+	}
+}
+
+func TestManager_NoMonitor(t *testing.T) {
+	m := NewManager()
+	s := &flakyStorage{Base: NewBase("db", TypeMySQL)}
+	_ = m.Register(s)
+	_ = m.ConnectAll(context.Background())
+	s.down.Store(true)
+	time.Sleep(20 * time.Millisecond)
+	if m.Health("db") != HealthHealthy {
+		t.Fatal("health must not change without monitor")
+	}
+	_ = m.CloseAll(context.Background())
+}
+
+func waitUntil(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met")
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+}
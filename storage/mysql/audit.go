@@ -0,0 +1,49 @@
+package mysql
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/mildsunup/higo/ddd"
+)
+
+// AuditPlugin 审计字段插件
+// 在创建/更新时从 context 读取操作人（ddd.WithActor）填充 CreatedBy/UpdatedBy，
+// 时间字段由 GORM 的 autoCreateTime/autoUpdateTime 维护。
+type AuditPlugin struct{}
+
+// Name 插件名称
+func (AuditPlugin) Name() string { return "higo:audit" }
+
+// Initialize 注册回调
+func (AuditPlugin) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Create().Before("gorm:create").Register("higo:audit_create", auditCreate); err != nil {
+		return err
+	}
+	return db.Callback().Update().Before("gorm:update").Register("higo:audit_update", auditUpdate)
+}
+
+func auditCreate(db *gorm.DB) {
+	actor := ddd.ActorFromContext(db.Statement.Context)
+	if actor == "" || db.Statement.Schema == nil {
+		return
+	}
+	setAuditColumn(db, "CreatedBy", actor)
+	setAuditColumn(db, "UpdatedBy", actor)
+}
+
+func auditUpdate(db *gorm.DB) {
+	actor := ddd.ActorFromContext(db.Statement.Context)
+	if actor == "" || db.Statement.Schema == nil {
+		return
+	}
+	setAuditColumn(db, "UpdatedBy", actor)
+}
+
+func setAuditColumn(db *gorm.DB, name, actor string) {
+	if db.Statement.Schema.LookUpField(name) == nil {
+		return
+	}
+	db.Statement.SetColumn(name, actor, true)
+}
+
+var _ gorm.Plugin = AuditPlugin{}
@@ -0,0 +1,94 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/mildsunup/higo/ddd"
+)
+
+type auditedOrder struct {
+	ID     int64 `gorm:"primaryKey"`
+	Status string
+	ddd.Audit
+}
+
+func newAuditFieldsDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	if err := db.Use(AuditPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&auditedOrder{}, &account{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestAuditPlugin(t *testing.T) {
+	db := newAuditFieldsDB(t)
+	alice := ddd.WithActor(context.Background(), "alice")
+	bob := ddd.WithActor(context.Background(), "bob")
+
+	load := func(id int64) auditedOrder {
+		t.Helper()
+		var o auditedOrder
+		if err := db.First(&o, id).Error; err != nil {
+			t.Fatal(err)
+		}
+		return o
+	}
+
+	o := &auditedOrder{ID: 1, Status: "new"}
+	if err := db.WithContext(alice).Create(o).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got := load(1); got.CreatedBy != "alice" || got.UpdatedBy != "alice" || got.CreatedAt.IsZero() {
+		t.Fatalf("created = %+v", got.Audit)
+	}
+
+	// 结构体、单列与 map 三种更新方式都记录修改人，创建人不变
+	updates := []struct {
+		name   string
+		actor  context.Context
+		update func(tx *gorm.DB) error
+	}{
+		{"save", bob, func(tx *gorm.DB) error { o.Status = "paid"; return tx.Save(o).Error }},
+		{"update column", alice, func(tx *gorm.DB) error { return tx.Model(&auditedOrder{ID: 1}).Update("status", "shipped").Error }},
+		{"update map", bob, func(tx *gorm.DB) error {
+			return tx.Model(&auditedOrder{}).Where("id = ?", 1).Updates(map[string]any{"status": "done"}).Error
+		}},
+	}
+	for _, u := range updates {
+		if err := u.update(db.WithContext(u.actor)); err != nil {
+			t.Fatalf("%s: %v", u.name, err)
+		}
+		got := load(1)
+		if want := ddd.ActorFromContext(u.actor); got.UpdatedBy != want || got.CreatedBy != "alice" {
+			t.Fatalf("%s: audit = %+v, want updated by %s", u.name, got.Audit, want)
+		}
+	}
+
+	// 无操作人时保留原值
+	if err := db.Model(&auditedOrder{ID: 1}).Update("status", "archived").Error; err != nil {
+		t.Fatal(err)
+	}
+	if got := load(1); got.UpdatedBy != "bob" || got.Status != "archived" {
+		t.Fatalf("anonymous update = %+v", got)
+	}
+
+	// 没有审计字段的模型不受影响
+	if err := db.WithContext(alice).Create(&account{ID: 1, Balance: 10}).Error; err != nil {
+		t.Fatal(err)
+	}
+}
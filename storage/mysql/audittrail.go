@@ -0,0 +1,392 @@
+package mysql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/mildsunup/higo/ddd"
+)
+
+// AuditAction 审计动作
+type AuditAction string
+
+const (
+	AuditInsert AuditAction = "insert"
+	AuditUpdate AuditAction = "update"
+	AuditDelete AuditAction = "delete"
+)
+
+// AuditEntry 数据变更审计记录
+type AuditEntry struct {
+	Table        string         `json:"table"`
+	Action       AuditAction    `json:"action"`
+	PrimaryKeys  map[string]any `json:"primary_keys,omitempty"`
+	Actor        string         `json:"actor,omitempty"`
+	Before       map[string]any `json:"before,omitempty"`
+	After        map[string]any `json:"after,omitempty"`
+	RowsAffected int64          `json:"rows_affected"` // 该行计入语句影响行数时为 1，同一语句各记录之和不超过语句的 RowsAffected
+	At           time.Time      `json:"at"`
+}
+
+// AuditSink 审计记录输出
+// tx 为当前语句所在会话，写入数据库的实现可借此与业务变更处于同一事务。
+type AuditSink interface {
+	Write(ctx context.Context, tx *gorm.DB, entries []AuditEntry) error
+}
+
+// AuditSinkFunc 函数式审计输出
+type AuditSinkFunc func(ctx context.Context, tx *gorm.DB, entries []AuditEntry) error
+
+func (f AuditSinkFunc) Write(ctx context.Context, tx *gorm.DB, entries []AuditEntry) error {
+	return f(ctx, tx, entries)
+}
+
+// TableAuditPolicy 表级审计策略
+type TableAuditPolicy struct {
+	Before  bool     // 记录变更前镜像（update/delete 需额外查询）
+	After   bool     // 记录变更后镜像
+	Exclude []string // 不记录的列（如密码、密文）
+}
+
+// AuditTrailConfig 审计轨迹配置
+type AuditTrailConfig struct {
+	Sink    AuditSink
+	Tables  map[string]TableAuditPolicy // 为空时审计所有表（使用 Default）
+	Default TableAuditPolicy
+	Strict  bool // 审计写入失败时使语句失败（事务中会导致回滚）
+}
+
+// AuditTrailPlugin 数据变更审计插件，记录 INSERT/UPDATE/DELETE 的表、主键、操作人与前后镜像
+type AuditTrailPlugin struct {
+	cfg AuditTrailConfig
+}
+
+// NewAuditTrailPlugin 创建审计轨迹插件
+//
+//	db.Use(mysql.NewAuditTrailPlugin(mysql.AuditTrailConfig{
+//	    Sink:   mysql.NewTableAuditSink("audit_logs"),
+//	    Tables: map[string]mysql.TableAuditPolicy{"users": {Before: true, After: true, Exclude: []string{"password"}}},
+//	}))
+func NewAuditTrailPlugin(cfg AuditTrailConfig) *AuditTrailPlugin {
+	return &AuditTrailPlugin{cfg: cfg}
+}
+
+// Name 插件名称
+func (p *AuditTrailPlugin) Name() string { return "higo:audit_trail" }
+
+// Initialize 注册回调；变更前镜像在分片路由之后查询（读取物理表），审计在提交默认事务之前写入（Strict 失败时可回滚）
+func (p *AuditTrailPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Create().After("gorm:create").Before("gorm:commit_or_rollback_transaction").Register("higo:audit_trail_create", p.afterCreate); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").After("higo:sharding").Register("higo:audit_trail_before_update", p.captureBefore); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Before("gorm:commit_or_rollback_transaction").Register("higo:audit_trail_update", p.afterUpdate); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").After("higo:sharding").Register("higo:audit_trail_before_delete", p.captureBefore); err != nil {
+		return err
+	}
+	return cb.Delete().After("gorm:delete").Before("gorm:commit_or_rollback_transaction").Register("higo:audit_trail_delete", p.afterDelete)
+}
+
+type skipAuditKey struct{}
+
+// WithoutAudit 跳过审计（审计输出自身写库时使用）
+func WithoutAudit(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipAuditKey{}, true)
+}
+
+const auditBeforeKey = "higo:audit_before"
+
+func (p *AuditTrailPlugin) policy(db *gorm.DB) (TableAuditPolicy, bool) {
+	if db.Error != nil || db.Statement.Table == "" || p.cfg.Sink == nil {
+		return TableAuditPolicy{}, false
+	}
+	if skip, _ := db.Statement.Context.Value(skipAuditKey{}).(bool); skip {
+		return TableAuditPolicy{}, false
+	}
+	if p.cfg.Tables == nil {
+		return p.cfg.Default, true
+	}
+	// 分片表按逻辑表名匹配策略
+	if t, ok := db.InstanceGet(shardTableKey); ok {
+		if policy, ok := p.cfg.Tables[t.(string)]; ok {
+			return policy, true
+		}
+	}
+	policy, ok := p.cfg.Tables[db.Statement.Table]
+	return policy, ok
+}
+
+func (p *AuditTrailPlugin) afterCreate(db *gorm.DB) {
+	policy, ok := p.policy(db)
+	if !ok {
+		return
+	}
+
+	var entries []AuditEntry
+	for i, row := range modelImages(db) {
+		entry := p.entry(db, AuditInsert, i, row)
+		if policy.After {
+			entry.After = exclude(row, policy.Exclude)
+		}
+		entries = append(entries, entry)
+	}
+	p.write(db, entries)
+}
+
+func (p *AuditTrailPlugin) captureBefore(db *gorm.DB) {
+	if _, ok := p.policy(db); !ok {
+		return
+	}
+	rows, err := loadRows(db)
+	if err != nil {
+		db.Logger.Warn(db.Statement.Context, "audit trail: load before image: %v", err)
+		return
+	}
+	db.InstanceSet(auditBeforeKey, rows)
+}
+
+func (p *AuditTrailPlugin) afterUpdate(db *gorm.DB) {
+	policy, ok := p.policy(db)
+	if !ok {
+		return
+	}
+	before, _ := db.InstanceGet(auditBeforeKey)
+	rows, _ := before.([]map[string]any)
+	if len(rows) == 0 {
+		// 无法确定受影响的行时退化为按模型记录
+		rows = modelImages(db)
+	}
+
+	var entries []AuditEntry
+	for i, row := range rows {
+		entry := p.entry(db, AuditUpdate, i, row)
+		if policy.Before {
+			entry.Before = exclude(row, policy.Exclude)
+		}
+		if policy.After {
+			if after, err := reloadRow(db, entry.PrimaryKeys); err == nil {
+				entry.After = exclude(after, policy.Exclude)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	p.write(db, entries)
+}
+
+func (p *AuditTrailPlugin) afterDelete(db *gorm.DB) {
+	policy, ok := p.policy(db)
+	if !ok {
+		return
+	}
+	before, _ := db.InstanceGet(auditBeforeKey)
+	rows, _ := before.([]map[string]any)
+	if len(rows) == 0 {
+		rows = modelImages(db)
+	}
+
+	var entries []AuditEntry
+	for i, row := range rows {
+		entry := p.entry(db, AuditDelete, i, row)
+		if policy.Before {
+			entry.Before = exclude(row, policy.Exclude)
+		}
+		entries = append(entries, entry)
+	}
+	p.write(db, entries)
+}
+
+// entry 生成第 i 行的记录；语句影响行数按行分摊，前 RowsAffected 行各计 1
+func (p *AuditTrailPlugin) entry(db *gorm.DB, action AuditAction, i int, row map[string]any) AuditEntry {
+	var affected int64
+	if int64(i) < db.RowsAffected {
+		affected = 1
+	}
+	return AuditEntry{
+		Table:        db.Statement.Table,
+		Action:       action,
+		PrimaryKeys:  primaryKeys(db, row),
+		Actor:        ddd.ActorFromContext(db.Statement.Context),
+		RowsAffected: affected,
+		At:           time.Now(),
+	}
+}
+
+func (p *AuditTrailPlugin) write(db *gorm.DB, entries []AuditEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	ctx := WithoutAudit(db.Statement.Context)
+	// 先取新语句再开会话：Sink 对 tx 的链式调用（含 WithContext）不会复用当前语句
+	tx := db.Session(&gorm.Session{NewDB: true, Context: ctx}).Clauses().Session(&gorm.Session{})
+	if err := p.cfg.Sink.Write(ctx, tx, entries); err != nil {
+		if p.cfg.Strict {
+			_ = db.AddError(fmt.Errorf("mysql: write audit trail: %w", err))
+			return
+		}
+		db.Logger.Warn(ctx, "audit trail: write failed: %v", err)
+	}
+}
+
+// modelImages 从语句模型提取行镜像（列名 -> 值）
+func modelImages(db *gorm.DB) []map[string]any {
+	stmt := db.Statement
+	if stmt.Schema == nil {
+		if m, ok := stmt.Dest.(map[string]any); ok {
+			return []map[string]any{m}
+		}
+		return nil
+	}
+
+	rv := reflect.Indirect(stmt.ReflectValue)
+	var images []map[string]any
+	appendImage := func(v reflect.Value) {
+		row := make(map[string]any, len(stmt.Schema.DBNames))
+		for _, name := range stmt.Schema.DBNames {
+			field := stmt.Schema.FieldsByDBName[name]
+			if val, zero := field.ValueOf(stmt.Context, v); !zero || !field.PrimaryKey {
+				row[name] = val
+			}
+		}
+		images = append(images, row)
+	}
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			appendImage(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		appendImage(rv)
+	}
+	return images
+}
+
+// loadRows 按当前语句的条件查询受影响行；无条件时使用模型主键
+func loadRows(db *gorm.DB) ([]map[string]any, error) {
+	stmt := db.Statement
+	q := db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Table(stmt.Table)
+
+	conds := 0
+	if where, ok := stmt.Clauses["WHERE"]; ok {
+		if w, ok := where.Expression.(clause.Where); ok && len(w.Exprs) > 0 {
+			q = q.Clauses(w)
+			conds++
+		}
+	}
+	if stmt.Schema != nil {
+		for _, row := range modelImages(db) {
+			for _, f := range stmt.Schema.PrimaryFields {
+				if v, ok := row[f.DBName]; ok {
+					q = q.Where(clause.Eq{Column: clause.Column{Name: f.DBName}, Value: v})
+					conds++
+				}
+			}
+			break // 仅单模型时可按主键定位
+		}
+	}
+	if conds == 0 {
+		return nil, nil
+	}
+
+	var rows []map[string]any
+	if err := q.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func reloadRow(db *gorm.DB, pks map[string]any) (map[string]any, error) {
+	if len(pks) == 0 {
+		return nil, fmt.Errorf("mysql: no primary key")
+	}
+	q := db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Table(db.Statement.Table)
+	for col, v := range pks {
+		q = q.Where(clause.Eq{Column: clause.Column{Name: col}, Value: v})
+	}
+	var row map[string]any
+	if err := q.Take(&row).Error; err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+func primaryKeys(db *gorm.DB, row map[string]any) map[string]any {
+	names := []string{"id"}
+	if db.Statement.Schema != nil && len(db.Statement.Schema.PrimaryFieldDBNames) > 0 {
+		names = db.Statement.Schema.PrimaryFieldDBNames
+	}
+	pks := make(map[string]any, len(names))
+	for _, name := range names {
+		if v, ok := row[name]; ok {
+			pks[name] = v
+		}
+	}
+	return pks
+}
+
+func exclude(row map[string]any, cols []string) map[string]any {
+	if len(cols) == 0 {
+		return row
+	}
+	out := make(map[string]any, len(row))
+	for k, v := range row {
+		out[k] = v
+	}
+	for _, c := range cols {
+		delete(out, c)
+	}
+	return out
+}
+
+// TableAuditSink 将审计记录写入数据库表
+// 表结构：id、table_name、action、primary_keys(JSON)、actor、before_image(JSON)、after_image(JSON)、rows_affected、created_at
+type TableAuditSink struct {
+	Table string
+}
+
+// NewTableAuditSink 创建数据库审计输出
+func NewTableAuditSink(table string) *TableAuditSink {
+	return &TableAuditSink{Table: table}
+}
+
+// Write 在当前会话（含事务）中写入审计记录
+func (s *TableAuditSink) Write(ctx context.Context, tx *gorm.DB, entries []AuditEntry) error {
+	rows := make([]map[string]any, len(entries))
+	for i, e := range entries {
+		rows[i] = map[string]any{
+			"table_name":    e.Table,
+			"action":        string(e.Action),
+			"primary_keys":  jsonText(e.PrimaryKeys),
+			"actor":         e.Actor,
+			"before_image":  jsonText(e.Before),
+			"after_image":   jsonText(e.After),
+			"rows_affected": e.RowsAffected,
+			"created_at":    e.At,
+		}
+	}
+	return tx.WithContext(ctx).Table(s.Table).Create(&rows).Error
+}
+
+func jsonText(v map[string]any) any {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return string(data)
+}
+
+var _ gorm.Plugin = (*AuditTrailPlugin)(nil)
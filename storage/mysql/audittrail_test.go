@@ -0,0 +1,231 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"github.com/mildsunup/higo/ddd"
+)
+
+type auditUser struct {
+	ID       int64 `gorm:"primaryKey"`
+	Name     string
+	Password string
+}
+
+type memAuditSink struct{ entries []AuditEntry }
+
+func (s *memAuditSink) Write(_ context.Context, _ *gorm.DB, entries []AuditEntry) error {
+	s.entries = append(s.entries, entries...)
+	return nil
+}
+
+func (s *memAuditSink) take() []AuditEntry {
+	entries := s.entries
+	s.entries = nil
+	return entries
+}
+
+func newAuditDB(t *testing.T, cfg AuditTrailConfig) *gorm.DB {
+	t.Helper()
+	db := newUoWDB(t)
+	if err := db.AutoMigrate(&auditUser{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(NewAuditTrailPlugin(cfg)); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestAuditTrail_Entries(t *testing.T) {
+	sink := &memAuditSink{}
+	db := newAuditDB(t, AuditTrailConfig{
+		Sink:   sink,
+		Tables: map[string]TableAuditPolicy{"audit_users": {Before: true, After: true, Exclude: []string{"password"}}},
+	})
+	ctx := ddd.WithActor(context.Background(), "admin")
+
+	users := []auditUser{{ID: 1, Name: "a", Password: "p"}, {ID: 2, Name: "b", Password: "p"}, {ID: 3, Name: "c", Password: "p"}}
+	if err := db.WithContext(ctx).Create(&users).Error; err != nil {
+		t.Fatal(err)
+	}
+	entries := sink.take()
+	if len(entries) != 3 {
+		t.Fatalf("insert entries = %+v", entries)
+	}
+	for _, e := range entries {
+		if e.Action != AuditInsert || e.Actor != "admin" || e.RowsAffected != 1 || e.After["name"] == nil {
+			t.Fatalf("insert entry = %+v", e)
+		}
+		if _, ok := e.After["password"]; ok {
+			t.Fatalf("excluded column recorded: %+v", e.After)
+		}
+	}
+
+	// 批量更新：每行一条记录，影响行数按行计，不重复计入语句总数
+	res := db.WithContext(ctx).Model(&auditUser{}).Where("id IN ?", []int64{1, 2}).Update("name", "x")
+	if res.Error != nil || res.RowsAffected != 2 {
+		t.Fatalf("update = %v, %d", res.Error, res.RowsAffected)
+	}
+	entries = sink.take()
+	if len(entries) != 2 {
+		t.Fatalf("update entries = %+v", entries)
+	}
+	var total int64
+	for _, e := range entries {
+		total += e.RowsAffected
+		if e.Action != AuditUpdate || e.Before["name"] == "x" || e.After["name"] != "x" {
+			t.Fatalf("update entry = %+v", e)
+		}
+		if _, ok := e.Before["password"]; ok {
+			t.Fatalf("excluded column recorded: %+v", e.Before)
+		}
+	}
+	if total != 2 {
+		t.Fatalf("summed rows affected = %d, want 2", total)
+	}
+
+	if err := db.WithContext(ctx).Where("id = ?", 3).Delete(&auditUser{}).Error; err != nil {
+		t.Fatal(err)
+	}
+	entries = sink.take()
+	if len(entries) != 1 || entries[0].Action != AuditDelete || entries[0].Before["name"] != "c" ||
+		entries[0].PrimaryKeys["id"] != int64(3) || entries[0].RowsAffected != 1 {
+		t.Fatalf("delete entries = %+v", entries)
+	}
+
+	// 未配置的表不审计
+	if err := db.Create(&account{ID: 1}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if entries := sink.take(); len(entries) != 0 {
+		t.Fatalf("unaudited table entries = %+v", entries)
+	}
+}
+
+func TestAuditTrail_UnmatchedUpdate(t *testing.T) {
+	sink := &memAuditSink{}
+	db := newAuditDB(t, AuditTrailConfig{Sink: sink})
+
+	if err := db.Model(&auditUser{ID: 9}).Update("name", "x").Error; err != nil {
+		t.Fatal(err)
+	}
+	entries := sink.take()
+	if len(entries) != 1 || entries[0].RowsAffected != 0 {
+		t.Fatalf("entries = %+v", entries)
+	}
+}
+
+func TestAuditTrail_TableSinkInTransaction(t *testing.T) {
+	db := newAuditDB(t, AuditTrailConfig{Sink: NewTableAuditSink("audit_logs"), Strict: true})
+	if err := db.Exec(`CREATE TABLE audit_logs (id INTEGER PRIMARY KEY AUTOINCREMENT, table_name TEXT, action TEXT,
+		primary_keys TEXT, actor TEXT, before_image TEXT, after_image TEXT, rows_affected INTEGER, created_at DATETIME)`).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&auditUser{ID: 1, Name: "a"}).Error; err != nil {
+			return err
+		}
+		return errors.New("abort")
+	})
+	if err == nil {
+		t.Fatal("expected abort")
+	}
+	var n int64
+	db.Table("audit_logs").Count(&n)
+	if n != 0 {
+		t.Fatalf("audit rows after rollback = %d", n)
+	}
+
+	if err := db.Create(&auditUser{ID: 1, Name: "a"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	var row struct {
+		TableName    string
+		Action       string
+		PrimaryKeys  string
+		RowsAffected int64
+	}
+	if err := db.Table("audit_logs").Take(&row).Error; err != nil {
+		t.Fatal(err)
+	}
+	if row.TableName != "audit_users" || row.Action != "insert" || row.PrimaryKeys != `{"id":1}` || row.RowsAffected != 1 {
+		t.Fatalf("audit row = %+v", row)
+	}
+}
+
+func TestAuditTrail_StrictSinkFailure(t *testing.T) {
+	failing := AuditSinkFunc(func(context.Context, *gorm.DB, []AuditEntry) error { return errors.New("sink down") })
+
+	db := newAuditDB(t, AuditTrailConfig{Sink: failing, Strict: true})
+	if err := db.Create(&auditUser{ID: 1}).Error; err == nil {
+		t.Fatal("strict audit failure should fail the statement")
+	}
+	var n int64
+	db.Model(&auditUser{}).Count(&n)
+	if n != 0 {
+		t.Fatalf("rows after failed audit = %d", n)
+	}
+
+	lenient := newAuditDB(t, AuditTrailConfig{Sink: failing})
+	if err := lenient.Create(&auditUser{ID: 1}).Error; err != nil {
+		t.Fatalf("lenient audit failure = %v", err)
+	}
+}
+
+func TestAuditTrail_ShardedBeforeImage(t *testing.T) {
+	// 无论注册顺序，变更前镜像都须在分片路由之后查询
+	for _, auditFirst := range []bool{true, false} {
+		ctx := context.Background()
+		sink := &memAuditSink{}
+		sharding, err := NewSharding([]ShardingConfig{{Table: "orders", ShardKey: "user_id", ShardCount: 16}}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		plugins := []gorm.Plugin{
+			NewAuditTrailPlugin(AuditTrailConfig{
+				Sink:   sink,
+				Tables: map[string]TableAuditPolicy{"orders": {Before: true, After: true}},
+			}),
+			ShardingPlugin{Sharding: sharding},
+		}
+		if !auditFirst {
+			plugins[0], plugins[1] = plugins[1], plugins[0]
+		}
+		db := newUoWDB(t)
+		for _, p := range plugins {
+			if err := db.Use(p); err != nil {
+				t.Fatalf("audit first %v: %v", auditFirst, err)
+			}
+		}
+		if err := sharding.Migrate(ctx, db, &shardOrder{}); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Create(&shardOrder{ID: 1, UserID: 35, Status: "new"}).Error; err != nil {
+			t.Fatal(err)
+		}
+		sink.take()
+
+		if err := db.Model(&shardOrder{}).Where("user_id = ?", 35).Update("status", "paid").Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Where("user_id = ?", 35).Delete(&shardOrder{}).Error; err != nil {
+			t.Fatal(err)
+		}
+		entries := sink.take()
+		if len(entries) != 2 {
+			t.Fatalf("audit first %v: entries = %+v", auditFirst, entries)
+		}
+		if e := entries[0]; e.Table != "orders_03" || e.Before["status"] != "new" || e.After["status"] != "paid" || e.PrimaryKeys["id"] != int64(1) {
+			t.Fatalf("audit first %v: update entry = %+v", auditFirst, e)
+		}
+		if e := entries[1]; e.Action != AuditDelete || e.Before["status"] != "paid" || e.RowsAffected != 1 {
+			t.Fatalf("audit first %v: delete entry = %+v", auditFirst, e)
+		}
+	}
+}
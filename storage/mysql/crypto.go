@@ -0,0 +1,92 @@
+package mysql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm/schema"
+
+	"github.com/mildsunup/higo/security"
+)
+
+// EncryptedSerializerName 加密序列化器名称，字段标签为 `gorm:"serializer:encrypted"`
+const EncryptedSerializerName = "encrypted"
+
+// EncryptedSerializer 字段级加密序列化器
+// string/[]byte 字段直接加密，其余类型先 JSON 编码再加密；列类型应为文本。
+type EncryptedSerializer struct {
+	Keyring *security.Keyring
+}
+
+// RegisterEncryption 注册加密序列化器
+//
+//	type User struct {
+//	    ID    int64
+//	    Phone string `gorm:"serializer:encrypted"`
+//	}
+func RegisterEncryption(kr *security.Keyring) {
+	schema.RegisterSerializer(EncryptedSerializerName, EncryptedSerializer{Keyring: kr})
+}
+
+// Scan 解密数据库值到字段
+func (s EncryptedSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue any) error {
+	var ciphertext string
+	switch v := dbValue.(type) {
+	case nil:
+		return nil
+	case string:
+		ciphertext = v
+	case []byte:
+		ciphertext = string(v)
+	default:
+		return fmt.Errorf("mysql: encrypted field %s: unsupported db value %T", field.Name, dbValue)
+	}
+	if ciphertext == "" {
+		return nil
+	}
+
+	plaintext, err := s.Keyring.Decrypt(ciphertext)
+	if err != nil {
+		return fmt.Errorf("mysql: decrypt field %s: %w", field.Name, err)
+	}
+
+	fv := reflect.New(field.FieldType)
+	switch field.FieldType.Kind() {
+	case reflect.String:
+		fv.Elem().SetString(string(plaintext))
+	case reflect.Slice:
+		if field.FieldType.Elem().Kind() == reflect.Uint8 {
+			fv.Elem().SetBytes(plaintext)
+			break
+		}
+		fallthrough
+	default:
+		if err := json.Unmarshal(plaintext, fv.Interface()); err != nil {
+			return fmt.Errorf("mysql: decode field %s: %w", field.Name, err)
+		}
+	}
+	field.ReflectValueOf(ctx, dst).Set(fv.Elem())
+	return nil
+}
+
+// Value 加密字段值
+func (s EncryptedSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue any) (any, error) {
+	var plaintext []byte
+	switch v := fieldValue.(type) {
+	case string:
+		plaintext = []byte(v)
+	case []byte:
+		plaintext = v
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("mysql: encode field %s: %w", field.Name, err)
+		}
+		plaintext = data
+	}
+	return s.Keyring.Encrypt(plaintext)
+}
+
+var _ schema.SerializerInterface = EncryptedSerializer{}
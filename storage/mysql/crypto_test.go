@@ -0,0 +1,88 @@
+package mysql
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mildsunup/higo/security"
+)
+
+func testKeyring(t *testing.T, current uint32) *security.Keyring {
+	t.Helper()
+	kr, err := security.NewKeyring(current, map[uint32][]byte{
+		1: bytes.Repeat([]byte{1}, 32),
+		2: bytes.Repeat([]byte{2}, 32),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kr
+}
+
+type address struct {
+	City string `json:"city"`
+}
+
+type customer struct {
+	ID      int64    `gorm:"primaryKey"`
+	Phone   string   `gorm:"serializer:encrypted"`
+	Token   []byte   `gorm:"serializer:encrypted"`
+	Address *address `gorm:"serializer:encrypted"`
+	Name    string
+}
+
+func TestEncryptedSerializer(t *testing.T) {
+	ctx := context.Background()
+	RegisterEncryption(testKeyring(t, 2))
+	db := newUoWDB(t)
+	if err := db.AutoMigrate(&customer{}); err != nil {
+		t.Fatal(err)
+	}
+
+	in := customer{ID: 1, Phone: "13800000000", Token: []byte{0, 1, 2}, Address: &address{City: "Hangzhou"}, Name: "alice"}
+	if err := db.WithContext(ctx).Create(&in).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// 库中为带版本的密文，明文列不受影响
+	var raw map[string]any
+	if err := db.Table("customers").Where("id = ?", 1).Take(&raw).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, col := range []string{"phone", "token", "address"} {
+		s, _ := asString(raw[col])
+		if !strings.HasPrefix(s, "v2:") || strings.Contains(s, "13800000000") || strings.Contains(s, "Hangzhou") {
+			t.Errorf("%s stored as %q", col, s)
+		}
+	}
+	if raw["name"] != "alice" {
+		t.Fatalf("name = %v", raw["name"])
+	}
+
+	var out customer
+	if err := db.WithContext(ctx).First(&out, 1).Error; err != nil {
+		t.Fatal(err)
+	}
+	if out.Phone != in.Phone || !bytes.Equal(out.Token, in.Token) || out.Address == nil || out.Address.City != "Hangzhou" {
+		t.Fatalf("decoded = %+v", out)
+	}
+
+	// NULL 与空串不解密
+	if err := db.Exec("INSERT INTO customers (id, phone, address, name) VALUES (2, '', NULL, 'bob')").Error; err != nil {
+		t.Fatal(err)
+	}
+	var empty customer
+	if err := db.WithContext(ctx).First(&empty, 2).Error; err != nil || empty.Phone != "" || empty.Address != nil {
+		t.Fatalf("empty = %+v, %v", empty, err)
+	}
+
+	// 无法解密的值返回错误
+	if err := db.Exec("UPDATE customers SET phone = 'v9:AAAA' WHERE id = 2").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.WithContext(ctx).First(&empty, 2).Error; err == nil {
+		t.Fatal("unknown key version decoded")
+	}
+}
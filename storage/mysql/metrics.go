@@ -0,0 +1,108 @@
+package mysql
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/mildsunup/higo/storage"
+)
+
+const metricsStartKey = "higo:metrics_start"
+
+// MetricsPlugin 查询指标插件
+// 按操作类型（query/exec）与查询名称记录耗时与错误；未通过 storage.WithQueryName 命名的查询使用表名作为标签，
+// 无法推断表名时为 storage.UnnamedQuery。记录未找到（gorm.ErrRecordNotFound）不计为错误。
+type MetricsPlugin struct {
+	Metrics *storage.Metrics
+	Storage string // 存储名称（name 标签）
+}
+
+// Name 插件名称
+func (MetricsPlugin) Name() string { return "higo:metrics" }
+
+// Initialize 注册回调
+func (p MetricsPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	type processor struct {
+		name   string
+		op     string
+		before func(string, func(*gorm.DB)) error
+		after  func(string, func(*gorm.DB)) error
+	}
+	processors := []processor{
+		{"create", storage.OpExec, cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", storage.OpQuery, cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", storage.OpExec, cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", storage.OpExec, cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", storage.OpQuery, cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", storage.OpExec, cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+	for _, pr := range processors {
+		if err := pr.before("higo:metrics_before_"+pr.name, before); err != nil {
+			return err
+		}
+		if err := pr.after("higo:metrics_after_"+pr.name, p.after(pr.op)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func before(db *gorm.DB) {
+	db.InstanceSet(metricsStartKey, time.Now())
+}
+
+func (p MetricsPlugin) after(op string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		v, ok := db.InstanceGet(metricsStartKey)
+		if !ok {
+			return
+		}
+		start, _ := v.(time.Time)
+
+		query := storage.QueryName(db.Statement.Context)
+		if query == "" {
+			if t, ok := db.InstanceGet(shardTableKey); ok {
+				query = "table:" + t.(string)
+			} else {
+				query = tableLabel(db.Statement)
+			}
+		}
+		err := db.Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = nil
+		}
+		p.Metrics.ObserveQuery(p.Storage, storage.TypeMySQL, op, query, start, err)
+	}
+}
+
+// tableLabel 未命名查询的标签：表名，原始语句时取 SQL 中第一个表名
+func tableLabel(stmt *gorm.Statement) string {
+	if stmt.Table != "" {
+		return "table:" + stmt.Table
+	}
+	if t := sqlTable(stmt.SQL.String()); t != "" {
+		return "table:" + t
+	}
+	return ""
+}
+
+// sqlTable 从 SQL 中提取 FROM/INTO/UPDATE 后的第一个表名
+func sqlTable(sql string) string {
+	fields := strings.Fields(sql)
+	for i, f := range fields[:max(len(fields)-1, 0)] {
+		switch strings.ToUpper(f) {
+		case "FROM", "INTO", "UPDATE", "JOIN":
+			name := strings.Trim(fields[i+1], "`\"(),;")
+			if name != "" && !strings.EqualFold(name, "select") {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+var _ gorm.Plugin = MetricsPlugin{}
@@ -24,8 +24,11 @@ type Config struct {
 	MaxIdleConns    int                 `json:"max_idle_conns" yaml:"max_idle_conns"`
 	ConnMaxLifetime time.Duration       `json:"conn_max_lifetime" yaml:"conn_max_lifetime"`
 	ConnMaxIdleTime time.Duration       `json:"conn_max_idle_time" yaml:"conn_max_idle_time"`
-	LogLevel        gormlogger.LogLevel `json:"log_level" yaml:"log_level"`           // 1=Silent, 2=Error, 3=Warn, 4=Info
-	SlowThreshold   time.Duration       `json:"slow_threshold" yaml:"slow_threshold"` // 慢查询阈值，默认 200ms
+	LogLevel        gormlogger.LogLevel `json:"log_level" yaml:"log_level"`             // 1=Silent, 2=Error, 3=Warn, 4=Info
+	SlowThreshold   time.Duration       `json:"slow_threshold" yaml:"slow_threshold"`   // 慢查询阈值，默认 200ms
+	QueryThreshold  int                 `json:"query_threshold" yaml:"query_threshold"` // 单请求查询数告警阈值，0 表示不告警
+	SQLCommenter    SQLCommenterConfig  `json:"sql_commenter" yaml:"sql_commenter"`     // SQL 注释（traceparent、route、application）
+	Sharding        []ShardingConfig    `json:"sharding" yaml:"sharding"`               // 分表/分库规则
 }
 
 // Logger 日志接口
@@ -42,6 +45,10 @@ type Storage struct {
 	config Config
 	logger Logger
 	tracer trace.TracerProvider
+	route  func(ctx context.Context) string
+
+	shardAlgorithms map[string]ShardAlgorithm
+	sharding        *Sharding
 }
 
 // Option MySQL 存储选项
@@ -61,13 +68,30 @@ func WithTracer(tracer trace.TracerProvider) Option {
 	}
 }
 
-// WithReplicas 设置只读副本
+// WithSQLCommentRoute 设置 SQL 注释中 route 标签的来源（需启用 SQLCommenter）
+func WithSQLCommentRoute(fn func(ctx context.Context) string) Option {
+	return func(s *Storage) {
+		s.route = fn
+	}
+}
+
+// WithReplicas 设置只读副本，读取默认随机发往副本，可通过 storage.UsePrimary 强制读主库
 func WithReplicas(replicas ...string) Option {
 	return func(s *Storage) {
 		s.config.Replicas = replicas
 	}
 }
 
+// WithShardAlgorithm 注册自定义分片算法，ShardingConfig.Algorithm 按名称引用
+func WithShardAlgorithm(name string, alg ShardAlgorithm) Option {
+	return func(s *Storage) {
+		if s.shardAlgorithms == nil {
+			s.shardAlgorithms = make(map[string]ShardAlgorithm)
+		}
+		s.shardAlgorithms[name] = alg
+	}
+}
+
 // New 创建 MySQL 存储
 func New(cfg Config, opts ...Option) *Storage {
 	name := cfg.Name
@@ -123,6 +147,26 @@ func (s *Storage) Connect(ctx context.Context) error {
 		}
 	}
 
+	// 审计字段
+	if err := db.Use(AuditPlugin{}); err != nil {
+		s.SetState(storage.StateDisconnected)
+		return fmt.Errorf("mysql: setup audit failed: %w", err)
+	}
+
+	// 请求级查询计数（N+1 检测）
+	if err := db.Use(QueryCountPlugin{Threshold: s.config.QueryThreshold, Logger: s.logger}); err != nil {
+		s.SetState(storage.StateDisconnected)
+		return fmt.Errorf("mysql: setup query count failed: %w", err)
+	}
+
+	// 查询指标（通过 storage.Builder.WithMetrics 启用）
+	if m := s.OperationMetrics(); m != nil {
+		if err := db.Use(MetricsPlugin{Metrics: m, Storage: s.Name()}); err != nil {
+			s.SetState(storage.StateDisconnected)
+			return fmt.Errorf("mysql: setup metrics failed: %w", err)
+		}
+	}
+
 	// 读写分离
 	if len(s.config.Replicas) > 0 {
 		replicas := make([]gorm.Dialector, len(s.config.Replicas))
@@ -137,6 +181,33 @@ func (s *Storage) Connect(ctx context.Context) error {
 			s.SetState(storage.StateDisconnected)
 			return fmt.Errorf("mysql: setup dbresolver failed: %w", err)
 		}
+		// storage.UsePrimary / storage.UseReplica
+		if err := db.Use(ReadPreferencePlugin{}); err != nil {
+			s.SetState(storage.StateDisconnected)
+			return fmt.Errorf("mysql: setup read preference failed: %w", err)
+		}
+	}
+
+	// 分表/分库
+	var sharding *Sharding
+	if len(s.config.Sharding) > 0 {
+		sharding, err = NewSharding(s.config.Sharding, s.shardAlgorithms)
+		if err != nil {
+			s.SetState(storage.StateDisconnected)
+			return err
+		}
+		if err := db.Use(ShardingPlugin{Sharding: sharding}); err != nil {
+			s.SetState(storage.StateDisconnected)
+			return fmt.Errorf("mysql: setup sharding failed: %w", err)
+		}
+	}
+
+	// SQL 注释，需在读写分离之后注册以包装最终选定的连接
+	if s.config.SQLCommenter.Enabled {
+		if err := db.Use(SQLCommenterPlugin{Application: s.config.SQLCommenter.Application, Route: s.route}); err != nil {
+			s.SetState(storage.StateDisconnected)
+			return fmt.Errorf("mysql: setup sql commenter failed: %w", err)
+		}
 	}
 
 	// 连接池配置
@@ -165,6 +236,7 @@ func (s *Storage) Connect(ctx context.Context) error {
 	}
 
 	s.db = db
+	s.sharding = sharding
 	s.SetState(storage.StateConnected)
 	return nil
 }
@@ -196,9 +268,45 @@ func (s *Storage) Close(ctx context.Context) error {
 	return err
 }
 
+// ServerVersion 返回 MySQL 服务端版本
+func (s *Storage) ServerVersion(ctx context.Context) (string, error) {
+	if s.db == nil {
+		return "", fmt.Errorf("mysql: not connected")
+	}
+	var version string
+	if err := s.db.WithContext(ctx).Raw("SELECT VERSION()").Scan(&version).Error; err != nil {
+		return "", fmt.Errorf("mysql: query version: %w", err)
+	}
+	return version, nil
+}
+
 // DB 返回 GORM 实例
 func (s *Storage) DB() *gorm.DB { return s.db }
 
+// SetMaxOpenConns 运行时调整最大连接数（storage.PoolTuner，供饱和检测自动调优）
+func (s *Storage) SetMaxOpenConns(n int) {
+	if s.db == nil {
+		return
+	}
+	if sqlDB, err := s.db.DB(); err == nil {
+		sqlDB.SetMaxOpenConns(n)
+	}
+}
+
+// Sharding 返回分片路由，未配置分片时返回 nil
+func (s *Storage) Sharding() *Sharding { return s.sharding }
+
+// MigrateShards 为模型创建（或迁移）全部分片表，未配置分片时等同 AutoMigrate
+func (s *Storage) MigrateShards(ctx context.Context, models ...any) error {
+	if s.db == nil {
+		return fmt.Errorf("mysql: not connected")
+	}
+	if s.sharding == nil {
+		return s.db.WithContext(ctx).AutoMigrate(models...)
+	}
+	return s.sharding.Migrate(ctx, s.db, models...)
+}
+
 // Stats 返回连接池统计
 func (s *Storage) Stats() storage.Stats {
 	if s.db == nil {
@@ -219,9 +327,15 @@ func (s *Storage) Stats() storage.Stats {
 	}
 }
 
+// Snapshot 返回连接池统计快照
+func (s *Storage) Snapshot() storage.StatsSnapshot { return s.RecordStats(s.Stats()) }
+
 var (
-	_ storage.Storage       = (*Storage)(nil)
-	_ storage.StatsProvider = (*Storage)(nil)
+	_ storage.Storage          = (*Storage)(nil)
+	_ storage.StatsProvider    = (*Storage)(nil)
+	_ storage.VersionProvider  = (*Storage)(nil)
+	_ storage.SnapshotProvider = (*Storage)(nil)
+	_ storage.PoolTuner        = (*Storage)(nil)
 )
 
 // --- 慢查询日志 ---
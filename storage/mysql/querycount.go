@@ -0,0 +1,174 @@
+package mysql
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// QueryCounter 请求级查询计数器
+// 记录同一 context 下执行的 SQL 数量与指纹，并将结果写入请求的 Span，用于发现 N+1 查询。
+type QueryCounter struct {
+	span trace.Span
+
+	mu           sync.Mutex
+	total        int
+	fingerprints map[string]int
+}
+
+// QueryStats 查询统计
+type QueryStats struct {
+	Total      int            // 查询总数
+	Duplicates map[string]int // 重复执行的查询指纹及次数
+}
+
+type queryCounterKey struct{}
+
+// WithQueryCounter 为请求 context 启用查询计数，统计写入当前 Span
+//
+//	r.Use(func(c *gin.Context) {
+//	    c.Request = c.Request.WithContext(mysql.WithQueryCounter(c.Request.Context()))
+//	    c.Next()
+//	})
+func WithQueryCounter(ctx context.Context) context.Context {
+	return context.WithValue(ctx, queryCounterKey{}, &QueryCounter{
+		span:         trace.SpanFromContext(ctx),
+		fingerprints: make(map[string]int),
+	})
+}
+
+// QueryStatsFromContext 获取请求的查询统计
+func QueryStatsFromContext(ctx context.Context) (QueryStats, bool) {
+	c, ok := ctx.Value(queryCounterKey{}).(*QueryCounter)
+	if !ok {
+		return QueryStats{}, false
+	}
+	return c.Stats(), true
+}
+
+// Stats 返回当前统计
+func (c *QueryCounter) Stats() QueryStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	dups := make(map[string]int)
+	for fp, n := range c.fingerprints {
+		if n > 1 {
+			dups[fp] = n
+		}
+	}
+	return QueryStats{Total: c.total, Duplicates: dups}
+}
+
+// record 记录一次查询，返回总数与该指纹的次数
+func (c *QueryCounter) record(fingerprint string) (total, same, duplicates int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.total++
+	c.fingerprints[fingerprint]++
+	for _, n := range c.fingerprints {
+		if n > 1 {
+			duplicates++
+		}
+	}
+	return c.total, c.fingerprints[fingerprint], duplicates
+}
+
+// QueryCountPlugin 查询计数插件
+// 仅统计通过 WithQueryCounter 启用计数的 context，其余查询不受影响。
+type QueryCountPlugin struct {
+	Threshold          int    // 单请求查询总数告警阈值，0 表示不告警
+	DuplicateThreshold int    // 同一指纹重复次数告警阈值，默认 3
+	Logger             Logger // 告警日志，可为空
+}
+
+// Name 插件名称
+func (QueryCountPlugin) Name() string { return "higo:query_count" }
+
+// Initialize 注册回调
+func (p QueryCountPlugin) Initialize(db *gorm.DB) error {
+	if p.DuplicateThreshold <= 0 {
+		p.DuplicateThreshold = 3
+	}
+
+	cb := db.Callback()
+	hooks := []struct {
+		name     string
+		register func(string, func(*gorm.DB)) error
+	}{
+		{"gorm:create", cb.Create().After("gorm:create").Register},
+		{"gorm:query", cb.Query().After("gorm:query").Register},
+		{"gorm:update", cb.Update().After("gorm:update").Register},
+		{"gorm:delete", cb.Delete().After("gorm:delete").Register},
+		{"gorm:row", cb.Row().After("gorm:row").Register},
+		{"gorm:raw", cb.Raw().After("gorm:raw").Register},
+	}
+	for _, h := range hooks {
+		if err := h.register("higo:query_count_"+strings.TrimPrefix(h.name, "gorm:"), p.after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p QueryCountPlugin) after(db *gorm.DB) {
+	ctx := db.Statement.Context
+	if ctx == nil {
+		return
+	}
+	c, ok := ctx.Value(queryCounterKey{}).(*QueryCounter)
+	if !ok {
+		return
+	}
+
+	fp := fingerprint(db.Statement.SQL.String())
+	total, same, duplicates := c.record(fp)
+
+	c.span.SetAttributes(
+		attribute.Int("db.query_count", total),
+		attribute.Int("db.duplicate_queries", duplicates),
+	)
+
+	if same == p.DuplicateThreshold {
+		c.span.AddEvent("db.n_plus_one", trace.WithAttributes(
+			attribute.String("db.fingerprint", fp),
+			attribute.Int("db.repeat", same),
+		))
+		if p.Logger != nil {
+			p.Logger.Warn(ctx, "possible n+1 query", "fingerprint", fp, "repeat", same)
+		}
+	}
+
+	if p.Threshold > 0 && total == p.Threshold+1 {
+		c.span.AddEvent("db.query_threshold_exceeded", trace.WithAttributes(
+			attribute.Int("db.query_count", total),
+			attribute.Int("db.query_threshold", p.Threshold),
+		))
+		if p.Logger != nil {
+			p.Logger.Warn(ctx, "too many queries in request", "count", total, "threshold", p.Threshold)
+		}
+	}
+}
+
+var (
+	fpInList  = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)*\s*\)`)
+	fpString  = regexp.MustCompile(`'(?:[^'\\]|\\.|'')*'`)
+	fpNumber  = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+	fpSpacing = regexp.MustCompile(`\s+`)
+)
+
+// fingerprint 归一化 SQL：字面量（含转义引号）替换为 ?，IN 列表折叠，空白压缩
+func fingerprint(sql string) string {
+	sql = fpString.ReplaceAllString(sql, "?")
+	sql = fpNumber.ReplaceAllString(sql, "?")
+	sql = fpInList.ReplaceAllString(sql, "(?)")
+	return strings.TrimSpace(fpSpacing.ReplaceAllString(sql, " "))
+}
+
+var _ gorm.Plugin = QueryCountPlugin{}
@@ -0,0 +1,106 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type warnLogger struct {
+	mu   sync.Mutex
+	msgs []string
+}
+
+func (l *warnLogger) Info(context.Context, string, ...any)  {}
+func (l *warnLogger) Error(context.Context, string, ...any) {}
+func (l *warnLogger) Warn(_ context.Context, msg string, _ ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.msgs = append(l.msgs, msg)
+}
+
+func TestFingerprint(t *testing.T) {
+	tests := []struct{ sql, want string }{
+		{"SELECT * FROM `users` WHERE `id` = 42", "SELECT * FROM `users` WHERE `id` = ?"},
+		{"SELECT * FROM orders WHERE user_id IN (1, 2,3)  AND note = 'it''s'", "SELECT * FROM orders WHERE user_id IN (?) AND note = ?"},
+		{"UPDATE t SET v = 'a\\'b', w = 1.5\n WHERE id = ?", "UPDATE t SET v = ?, w = ? WHERE id = ?"},
+		// 标识符中的数字不替换
+		{"SELECT c1 FROM t2 LIMIT 10", "SELECT c1 FROM t2 LIMIT ?"},
+	}
+	for _, tt := range tests {
+		if got := fingerprint(tt.sql); got != tt.want {
+			t.Errorf("fingerprint(%q) = %q, want %q", tt.sql, got, tt.want)
+		}
+	}
+}
+
+func TestQueryCountPlugin(t *testing.T) {
+	db := newUoWDB(t)
+	log := &warnLogger{}
+	if err := db.Use(QueryCountPlugin{Threshold: 4, Logger: log}); err != nil {
+		t.Fatal(err)
+	}
+	for i := range 3 {
+		if err := db.Create(&account{ID: int64(i + 1)}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rec := tracetest.NewSpanRecorder()
+	ctx, span := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)).Tracer("test").Start(context.Background(), "GET /accounts")
+	ctx = WithQueryCounter(ctx)
+
+	// 典型 N+1：先查列表，再逐条查询
+	var ids []int64
+	if err := db.WithContext(ctx).Model(&account{}).Order("id").Pluck("id", &ids).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range ids {
+		var a account
+		if err := db.WithContext(ctx).First(&a, id).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.WithContext(ctx).Exec(fmt.Sprintf("UPDATE accounts SET balance = %d", 1)).Error; err != nil {
+		t.Fatal(err)
+	}
+	span.End()
+
+	stats, ok := QueryStatsFromContext(ctx)
+	if !ok || stats.Total != 5 || len(stats.Duplicates) != 1 {
+		t.Fatalf("stats = %+v, %v", stats, ok)
+	}
+	for fp, n := range stats.Duplicates {
+		if n != 3 || fp != "SELECT * FROM `accounts` WHERE `accounts`.`id` = ? ORDER BY `accounts`.`id` LIMIT ?" {
+			t.Fatalf("duplicate %q x%d", fp, n)
+		}
+	}
+
+	ended := rec.Ended()[0]
+	attrs := map[string]int64{}
+	for _, kv := range ended.Attributes() {
+		attrs[string(kv.Key)] = kv.Value.AsInt64()
+	}
+	if attrs["db.query_count"] != 5 || attrs["db.duplicate_queries"] != 1 {
+		t.Fatalf("span attributes = %v", attrs)
+	}
+	var events []string
+	for _, e := range ended.Events() {
+		events = append(events, e.Name)
+	}
+	if fmt.Sprint(events) != "[db.n_plus_one db.query_threshold_exceeded]" {
+		t.Fatalf("span events = %v", events)
+	}
+	if fmt.Sprint(log.msgs) != "[possible n+1 query too many queries in request]" {
+		t.Fatalf("warnings = %v", log.msgs)
+	}
+
+	// 未启用计数的 context 不受影响
+	if _, ok := QueryStatsFromContext(context.Background()); ok {
+		t.Fatal("stats without counter")
+	}
+}
@@ -0,0 +1,43 @@
+package mysql
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+
+	"github.com/mildsunup/higo/storage"
+)
+
+// ReadPreferencePlugin 按 context 中的读取偏好（storage.UsePrimary / storage.UseReplica）选择连接。
+// 需在 dbresolver 之后注册（两者均为 Before("*")，后注册者先执行）；写操作与事务不受影响，原生 SQL 只响应 UsePrimary，避免写语句被发往副本
+type ReadPreferencePlugin struct{}
+
+func (ReadPreferencePlugin) Name() string { return "higo:read_preference" }
+
+func (p ReadPreferencePlugin) Initialize(db *gorm.DB) error {
+	const name = "higo:read_preference"
+	cb := db.Callback()
+	if err := cb.Query().Before("*").Register(name, p.read); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("*").Register(name, p.read); err != nil {
+		return err
+	}
+	return cb.Raw().Before("*").Register(name, p.raw)
+}
+
+func (ReadPreferencePlugin) read(db *gorm.DB) {
+	switch storage.ReadPreferenceFrom(db.Statement.Context) {
+	case storage.ReadPrimary:
+		dbresolver.Write.ModifyStatement(db.Statement)
+	case storage.ReadReplica:
+		dbresolver.Read.ModifyStatement(db.Statement)
+	}
+}
+
+func (ReadPreferencePlugin) raw(db *gorm.DB) {
+	if storage.ReadPreferenceFrom(db.Statement.Context) == storage.ReadPrimary {
+		dbresolver.Write.ModifyStatement(db.Statement)
+	}
+}
+
+var _ gorm.Plugin = ReadPreferencePlugin{}
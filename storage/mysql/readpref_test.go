@@ -0,0 +1,115 @@
+package mysql
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+	"gorm.io/plugin/dbresolver"
+
+	"github.com/mildsunup/higo/storage"
+)
+
+type node struct {
+	Name string
+}
+
+// newResolverDB 主库与副本为两个 sqlite 文件，各含一行标识自身的数据
+func newResolverDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := &gorm.Config{Logger: gormlogger.Discard}
+	for _, name := range []string{"primary", "replica"} {
+		db, err := gorm.Open(sqlite.Open(filepath.Join(dir, name+".db")), cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.AutoMigrate(&node{}); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Create(&node{Name: name}).Error; err != nil {
+			t.Fatal(err)
+		}
+		sqlDB, _ := db.DB()
+		_ = sqlDB.Close()
+	}
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(dir, "primary.db")), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(dbresolver.Register(dbresolver.Config{
+		Replicas: []gorm.Dialector{sqlite.Open(filepath.Join(dir, "replica.db"))},
+	})); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(ReadPreferencePlugin{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestReadPreferencePlugin_Routing(t *testing.T) {
+	db := newResolverDB(t)
+	ctx := context.Background()
+
+	query := func(db *gorm.DB) string {
+		t.Helper()
+		var n node
+		if err := db.First(&n).Error; err != nil {
+			t.Fatal(err)
+		}
+		return n.Name
+	}
+	row := func(db *gorm.DB) string {
+		t.Helper()
+		var name string
+		if err := db.Model(&node{}).Select("name").Row().Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+		return name
+	}
+	raw := func(db *gorm.DB) string {
+		t.Helper()
+		var name string
+		if err := db.Raw("SELECT name FROM nodes").Scan(&name).Error; err != nil {
+			t.Fatal(err)
+		}
+		return name
+	}
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"query default", query(db.WithContext(ctx)), "replica"},
+		{"query primary", query(db.WithContext(storage.UsePrimary(ctx))), "primary"},
+		{"query replica over write clause", query(db.WithContext(storage.UseReplica(ctx)).Clauses(dbresolver.Write)), "replica"},
+		{"row primary", row(db.WithContext(storage.UsePrimary(ctx))), "primary"},
+		{"row replica over write clause", row(db.WithContext(storage.UseReplica(ctx)).Clauses(dbresolver.Write)), "replica"},
+		{"raw default", raw(db.WithContext(ctx)), "replica"},
+		{"raw primary", raw(db.WithContext(storage.UsePrimary(ctx))), "primary"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %s, want %s", tt.name, tt.got, tt.want)
+		}
+	}
+
+	// 写操作与原生 SQL 不受 UseReplica 影响，避免写语句被发往副本
+	replica := db.WithContext(storage.UseReplica(ctx))
+	if err := replica.Create(&node{Name: "written"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := replica.Exec("INSERT INTO nodes (name) VALUES (?)", "written").Error; err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	if err := db.WithContext(storage.UsePrimary(ctx)).Model(&node{}).Where("name = ?", "written").Count(&count).Error; err != nil || count != 2 {
+		t.Fatalf("written rows on primary = %d, %v", count, err)
+	}
+}
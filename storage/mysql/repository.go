@@ -0,0 +1,216 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/mildsunup/higo/ddd"
+)
+
+// ErrNotFound 记录不存在
+var ErrNotFound = errors.New("mysql: record not found")
+
+// Repository 基于 GORM 的泛型仓储
+type Repository[T any, ID ddd.Identifier] struct {
+	db         *gorm.DB
+	idColumn   string
+	softDelete bool
+	deletedCol string
+}
+
+// RepositoryOption 仓储选项
+type RepositoryOption func(*repositoryOptions)
+
+type repositoryOptions struct {
+	idColumn   string
+	softDelete bool
+	deletedCol string
+}
+
+// WithIDColumn 设置主键列名，默认 id
+func WithIDColumn(column string) RepositoryOption {
+	return func(o *repositoryOptions) {
+		o.idColumn = column
+	}
+}
+
+// WithSoftDelete 启用软删除，默认使用 deleted_at 列
+func WithSoftDelete() RepositoryOption {
+	return func(o *repositoryOptions) {
+		o.softDelete = true
+	}
+}
+
+// WithSoftDeleteColumn 启用软删除并指定列名
+func WithSoftDeleteColumn(column string) RepositoryOption {
+	return func(o *repositoryOptions) {
+		o.softDelete = true
+		o.deletedCol = column
+	}
+}
+
+// NewRepository 创建仓储
+func NewRepository[T any, ID ddd.Identifier](db *gorm.DB, opts ...RepositoryOption) *Repository[T, ID] {
+	o := repositoryOptions{idColumn: "id", deletedCol: ddd.DeletedAtField}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Repository[T, ID]{
+		db:         db,
+		idColumn:   o.idColumn,
+		softDelete: o.softDelete,
+		deletedCol: o.deletedCol,
+	}
+}
+
+// conn 返回当前请求使用的连接，context 中存在事务时使用事务
+func (r *Repository[T, ID]) conn(ctx context.Context) *gorm.DB {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx.WithContext(ctx)
+	}
+	return r.db.WithContext(ctx)
+}
+
+// query 返回默认查询（排除已删除记录）
+func (r *Repository[T, ID]) query(ctx context.Context) *gorm.DB {
+	db := r.conn(ctx).Model(new(T))
+	if r.softDelete {
+		db = db.Where(clause.Expr{SQL: "? IS NULL", Vars: []any{clause.Column{Name: r.deletedCol}}})
+	}
+	return db
+}
+
+func (r *Repository[T, ID]) whereID(id ID) clause.Expression {
+	return clause.Eq{Column: clause.Column{Name: r.idColumn}, Value: id}
+}
+
+// FindByID 根据 ID 查找，已删除记录视为不存在
+func (r *Repository[T, ID]) FindByID(ctx context.Context, id ID) (*T, error) {
+	return r.first(r.query(ctx).Where(r.whereID(id)))
+}
+
+// FindIncludingDeleted 根据 ID 查找（包含已删除）
+func (r *Repository[T, ID]) FindIncludingDeleted(ctx context.Context, id ID) (*T, error) {
+	return r.first(r.conn(ctx).Model(new(T)).Where(r.whereID(id)))
+}
+
+func (r *Repository[T, ID]) first(db *gorm.DB) (*T, error) {
+	entity := new(T)
+	if err := db.Take(entity).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("mysql: find: %w", err)
+	}
+	return entity, nil
+}
+
+// Save 保存（新增或更新），聚合根会登记到工作单元以便提交后发布事件
+func (r *Repository[T, ID]) Save(ctx context.Context, entity *T) error {
+	if err := r.conn(ctx).Save(entity).Error; err != nil {
+		return fmt.Errorf("mysql: save: %w", err)
+	}
+	if src, ok := any(entity).(ddd.EventSource); ok {
+		ddd.Track(ctx, src)
+	}
+	return nil
+}
+
+// Delete 删除，启用软删除时仅标记
+func (r *Repository[T, ID]) Delete(ctx context.Context, id ID) error {
+	var err error
+	if r.softDelete {
+		err = r.query(ctx).Where(r.whereID(id)).Update(r.deletedCol, time.Now()).Error
+	} else {
+		err = r.conn(ctx).Where(r.whereID(id)).Delete(new(T)).Error
+	}
+	if err != nil {
+		return fmt.Errorf("mysql: delete: %w", err)
+	}
+	return nil
+}
+
+// Restore 恢复已软删除的记录
+func (r *Repository[T, ID]) Restore(ctx context.Context, id ID) error {
+	if !r.softDelete {
+		return fmt.Errorf("mysql: restore requires soft delete")
+	}
+	res := r.conn(ctx).Model(new(T)).Where(r.whereID(id)).Update(r.deletedCol, nil)
+	if res.Error != nil {
+		return fmt.Errorf("mysql: restore: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Exists 判断是否存在
+func (r *Repository[T, ID]) Exists(ctx context.Context, id ID) (bool, error) {
+	var count int64
+	if err := r.query(ctx).Where(r.whereID(id)).Limit(1).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("mysql: exists: %w", err)
+	}
+	return count > 0, nil
+}
+
+// FindAll 按查询选项查找
+func (r *Repository[T, ID]) FindAll(ctx context.Context, opts ...ddd.QueryOption) ([]*T, error) {
+	var entities []*T
+	if err := applyQueryOptions(r.query(ctx), ddd.ApplyQueryOptions(opts...), true).Find(&entities).Error; err != nil {
+		return nil, fmt.Errorf("mysql: find all: %w", err)
+	}
+	return entities, nil
+}
+
+// Count 按查询选项计数
+func (r *Repository[T, ID]) Count(ctx context.Context, opts ...ddd.QueryOption) (int64, error) {
+	var count int64
+	if err := applyQueryOptions(r.query(ctx), ddd.ApplyQueryOptions(opts...), false).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("mysql: count: %w", err)
+	}
+	return count, nil
+}
+
+// FindBySpec 按规约查找，规约必须可下推到数据库
+func (r *Repository[T, ID]) FindBySpec(ctx context.Context, spec ddd.Specification[T], opts ...ddd.QueryOption) ([]*T, error) {
+	expr, err := SpecExpression(spec)
+	if err != nil {
+		return nil, err
+	}
+	var entities []*T
+	db := applyQueryOptions(r.query(ctx).Where(expr), ddd.ApplyQueryOptions(opts...), true)
+	if err := db.Find(&entities).Error; err != nil {
+		return nil, fmt.Errorf("mysql: find by spec: %w", err)
+	}
+	return entities, nil
+}
+
+func applyQueryOptions(db *gorm.DB, o ddd.QueryOptions, paginate bool) *gorm.DB {
+	if len(o.Filters) > 0 {
+		db = db.Where(o.Filters)
+	}
+	if !paginate {
+		return db
+	}
+	if o.OrderBy != "" {
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: o.OrderBy}, Desc: o.Desc})
+	}
+	if o.Offset > 0 {
+		db = db.Offset(o.Offset)
+	}
+	if o.Limit > 0 {
+		db = db.Limit(o.Limit)
+	}
+	return db
+}
+
+var (
+	_ ddd.SoftDeleteRepository[struct{}, ddd.Int64ID] = (*Repository[struct{}, ddd.Int64ID])(nil)
+	_ ddd.ReadRepository[struct{}, ddd.Int64ID]       = (*Repository[struct{}, ddd.Int64ID])(nil)
+)
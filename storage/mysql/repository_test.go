@@ -0,0 +1,112 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mildsunup/higo/ddd"
+)
+
+type product struct {
+	ID    int64 `gorm:"primaryKey"`
+	Name  string
+	Price int64
+	ddd.SoftDelete
+}
+
+func TestRepository_SoftDelete(t *testing.T) {
+	ctx := context.Background()
+	db := newUoWDB(t)
+	if err := db.AutoMigrate(&product{}); err != nil {
+		t.Fatal(err)
+	}
+	repo := NewRepository[product, ddd.Int64ID](db, WithSoftDelete())
+
+	for i, name := range []string{"pen", "book", "lamp"} {
+		if err := repo.Save(ctx, &product{ID: int64(i + 1), Name: name, Price: int64(i+1) * 10}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := repo.Delete(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.FindByID(ctx, 2); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("find deleted = %v", err)
+	}
+	if ok, err := repo.Exists(ctx, 2); err != nil || ok {
+		t.Fatalf("exists deleted = %v, %v", ok, err)
+	}
+	deleted, err := repo.FindIncludingDeleted(ctx, 2)
+	if err != nil || !deleted.IsDeleted() {
+		t.Fatalf("find including deleted = %+v, %v", deleted, err)
+	}
+
+	if n, err := repo.Count(ctx); err != nil || n != 2 {
+		t.Fatalf("count = %d, %v", n, err)
+	}
+	all, err := repo.FindAll(ctx, ddd.WithOrderBy("price", true), ddd.WithPagination(0, 1))
+	if err != nil || len(all) != 1 || all[0].Name != "lamp" {
+		t.Fatalf("find all = %+v, %v", all, err)
+	}
+	cheap, err := repo.FindBySpec(ctx, ddd.Lt("price", int64(30), func(p product) int64 { return p.Price }))
+	if err != nil || len(cheap) != 1 || cheap[0].Name != "pen" {
+		t.Fatalf("find by spec = %+v, %v", cheap, err)
+	}
+
+	// 不可下推的规约直接报错，而不是退化为全表扫描
+	if _, err := repo.FindBySpec(ctx, ddd.Spec(func(product) bool { return true })); err == nil {
+		t.Fatal("expected error for non-queryable spec")
+	}
+
+	if err := repo.Restore(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := repo.FindByID(ctx, 2); err != nil || p.IsDeleted() {
+		t.Fatalf("restored = %+v, %v", p, err)
+	}
+	if err := repo.Restore(ctx, 99); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("restore missing = %v", err)
+	}
+}
+
+func TestRepository_HardDelete(t *testing.T) {
+	ctx := context.Background()
+	db := newUoWDB(t)
+	repo := NewRepository[account, ddd.Int64ID](db)
+
+	if err := repo.Save(ctx, &account{ID: 1, Balance: 5}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Delete(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.FindIncludingDeleted(ctx, 1); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("hard deleted row still present: %v", err)
+	}
+	if err := repo.Restore(ctx, 1); err == nil {
+		t.Fatal("restore without soft delete should fail")
+	}
+}
+
+func TestSpecExpression_NotDeleted(t *testing.T) {
+	db := newUoWDB(t)
+	if err := db.AutoMigrate(&product{}); err != nil {
+		t.Fatal(err)
+	}
+	gone := &product{ID: 2, Name: "book"}
+	gone.MarkDeleted(time.Now())
+	if err := db.Create([]*product{{ID: 1, Name: "pen"}, gone}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	var rows []*product
+	if err := db.Scopes(SpecScope(ddd.NotDeleted[*product]())).Find(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].Name != "pen" {
+		t.Fatalf("not deleted = %+v", rows)
+	}
+}
@@ -0,0 +1,195 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/mildsunup/higo/security"
+)
+
+// RotationCheckpoint 轮换进度存储，用于中断后续跑
+type RotationCheckpoint interface {
+	Load(ctx context.Context, key string) (lastID any, ok bool, err error)
+	Save(ctx context.Context, key string, lastID any) error
+}
+
+// RotationProgress 轮换进度
+type RotationProgress struct {
+	Table   string
+	LastID  any
+	Scanned int64 // 已扫描行数
+	Rotated int64 // 已重新加密的行数
+	Skipped int64 // 并发修改而跳过的行数
+}
+
+// KeyRotatorConfig 密钥轮换配置
+type KeyRotatorConfig struct {
+	Table      string   // 表名
+	IDColumn   string   // 单调递增主键列，默认 id
+	Columns    []string // 加密列
+	BatchSize  int      // 每批行数，默认 500
+	Pause      time.Duration
+	Checkpoint RotationCheckpoint // 为空时不支持续跑
+	OnProgress func(RotationProgress)
+}
+
+// KeyRotator 加密列密钥轮换器
+// 按主键分批扫描，找出使用旧版本密钥的值并以当前密钥重新加密；
+// 更新时以原密文为条件，避免覆盖并发写入。
+type KeyRotator struct {
+	db      *gorm.DB
+	keyring *security.Keyring
+	cfg     KeyRotatorConfig
+}
+
+// NewKeyRotator 创建密钥轮换器。Run 会扫描整表，不应放在组件 Start 中阻塞启动，
+// 以 runtime.CronJob 定期执行（多实例时加分布式锁）：
+//
+//	rotator := mysql.NewKeyRotator(db, keyring, mysql.KeyRotatorConfig{
+//	    Table: "users", Columns: []string{"phone", "id_card"}, Checkpoint: checkpoint,
+//	})
+//	app.Register(runtime.NewCronJob("key-rotation", "0 3 * * *", rotator.Run,
+//	    runtime.WithCronLocker(locker, time.Hour)))
+func NewKeyRotator(db *gorm.DB, kr *security.Keyring, cfg KeyRotatorConfig) *KeyRotator {
+	if cfg.IDColumn == "" {
+		cfg.IDColumn = "id"
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 500
+	}
+	return &KeyRotator{db: db, keyring: kr, cfg: cfg}
+}
+
+// checkpointKey 进度键，包含目标版本以便新一轮轮换重新开始
+func (r *KeyRotator) checkpointKey() string {
+	return fmt.Sprintf("mysql:key_rotation:%s:v%d", r.cfg.Table, r.keyring.Current())
+}
+
+// Run 执行轮换直至表扫描完成或 ctx 取消，可重复调用：
+// 配置 Checkpoint 时从上次进度续跑（完成后只扫描新增行），否则每次从头扫描
+func (r *KeyRotator) Run(ctx context.Context) error {
+	if len(r.cfg.Columns) == 0 {
+		return fmt.Errorf("mysql: key rotation requires columns")
+	}
+
+	progress := RotationProgress{Table: r.cfg.Table}
+	if r.cfg.Checkpoint != nil {
+		lastID, ok, err := r.cfg.Checkpoint.Load(ctx, r.checkpointKey())
+		if err != nil {
+			return fmt.Errorf("mysql: load rotation checkpoint: %w", err)
+		}
+		if ok {
+			progress.LastID = lastID
+		}
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		n, err := r.rotateBatch(ctx, &progress)
+		if err != nil {
+			return err
+		}
+		if r.cfg.Checkpoint != nil && progress.LastID != nil {
+			if err := r.cfg.Checkpoint.Save(ctx, r.checkpointKey(), progress.LastID); err != nil {
+				return fmt.Errorf("mysql: save rotation checkpoint: %w", err)
+			}
+		}
+		if r.cfg.OnProgress != nil {
+			r.cfg.OnProgress(progress)
+		}
+		if n < r.cfg.BatchSize {
+			return nil
+		}
+
+		if r.cfg.Pause > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(r.cfg.Pause):
+			}
+		}
+	}
+}
+
+func (r *KeyRotator) rotateBatch(ctx context.Context, p *RotationProgress) (int, error) {
+	idCol := clause.Column{Name: r.cfg.IDColumn}
+	q := r.db.WithContext(ctx).Table(r.cfg.Table).
+		Select(append([]string{r.cfg.IDColumn}, r.cfg.Columns...)).
+		Order(clause.OrderByColumn{Column: idCol}).
+		Limit(r.cfg.BatchSize)
+	if p.LastID != nil {
+		q = q.Where(clause.Gt{Column: idCol, Value: p.LastID})
+	}
+
+	var rows []map[string]any
+	if err := q.Find(&rows).Error; err != nil {
+		return 0, fmt.Errorf("mysql: scan %s: %w", r.cfg.Table, err)
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, row := range rows {
+			if err := r.rotateRow(tx, row, p); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	p.Scanned += int64(len(rows))
+	if len(rows) > 0 {
+		p.LastID = rows[len(rows)-1][r.cfg.IDColumn]
+	}
+	return len(rows), nil
+}
+
+func (r *KeyRotator) rotateRow(tx *gorm.DB, row map[string]any, p *RotationProgress) error {
+	updates := make(map[string]any)
+	conds := []clause.Expression{clause.Eq{Column: clause.Column{Name: r.cfg.IDColumn}, Value: row[r.cfg.IDColumn]}}
+
+	for _, col := range r.cfg.Columns {
+		old, ok := asString(row[col])
+		if !ok || !r.keyring.NeedsRotation(old) {
+			continue
+		}
+		rotated, _, err := r.keyring.Rotate(old)
+		if err != nil {
+			return fmt.Errorf("mysql: rotate %s.%s id=%v: %w", r.cfg.Table, col, row[r.cfg.IDColumn], err)
+		}
+		updates[col] = rotated
+		conds = append(conds, clause.Eq{Column: clause.Column{Name: col}, Value: old})
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+
+	res := tx.Table(r.cfg.Table).Where(clause.And(conds...)).Updates(updates)
+	if res.Error != nil {
+		return fmt.Errorf("mysql: update %s id=%v: %w", r.cfg.Table, row[r.cfg.IDColumn], res.Error)
+	}
+	if res.RowsAffected == 0 {
+		p.Skipped++ // 行已被并发修改，新值已使用当前密钥
+		return nil
+	}
+	p.Rotated++
+	return nil
+}
+
+func asString(v any) (string, bool) {
+	switch s := v.(type) {
+	case string:
+		return s, s != ""
+	case []byte:
+		return string(s), len(s) > 0
+	}
+	return "", false
+}
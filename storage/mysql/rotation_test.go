@@ -0,0 +1,112 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/mildsunup/higo/runtime"
+)
+
+// memCheckpoint 内存轮换进度
+type memCheckpoint map[string]any
+
+func (c memCheckpoint) Load(_ context.Context, key string) (any, bool, error) {
+	v, ok := c[key]
+	return v, ok, nil
+}
+
+func (c memCheckpoint) Save(_ context.Context, key string, lastID any) error {
+	c[key] = lastID
+	return nil
+}
+
+func TestKeyRotator(t *testing.T) {
+	ctx := context.Background()
+	db := newUoWDB(t)
+	if err := db.Exec("CREATE TABLE secrets (id INTEGER PRIMARY KEY, phone TEXT, note TEXT)").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	v1, v2 := testKeyring(t, 1), testKeyring(t, 2)
+	for id := 1; id <= 5; id++ {
+		phone, _ := v1.Encrypt(fmt.Appendf(nil, "phone-%d", id))
+		note, _ := v2.Encrypt(fmt.Appendf(nil, "note-%d", id))
+		if id == 3 {
+			phone = "" // 空值不处理
+		}
+		if err := db.Exec("INSERT INTO secrets (id, phone, note) VALUES (?, ?, ?)", id, phone, note).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cp := memCheckpoint{}
+	var progress []RotationProgress
+	rotator := NewKeyRotator(db, v2, KeyRotatorConfig{
+		Table:      "secrets",
+		Columns:    []string{"phone", "note"},
+		BatchSize:  2,
+		Checkpoint: cp,
+		OnProgress: func(p RotationProgress) { progress = append(progress, p) },
+	})
+
+	// 以定时任务运行，RunNow 同步执行一次
+	job := runtime.NewCronJob("key-rotation", "0 3 * * *", rotator.Run)
+	if err := job.RunNow(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	last := progress[len(progress)-1]
+	if len(progress) != 3 || last.Scanned != 5 || last.Rotated != 4 || last.Skipped != 0 {
+		t.Fatalf("progress = %+v", progress)
+	}
+	if id, ok := cp["mysql:key_rotation:secrets:v2"]; !ok || fmt.Sprint(id) != "5" {
+		t.Fatalf("checkpoint = %v", cp)
+	}
+
+	var rows []struct {
+		ID    int64
+		Phone string
+		Note  string
+	}
+	if err := db.Table("secrets").Order("id").Find(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range rows {
+		if r.ID == 3 {
+			if r.Phone != "" {
+				t.Fatalf("empty phone rewritten: %q", r.Phone)
+			}
+			continue
+		}
+		if v2.NeedsRotation(r.Phone) || v2.NeedsRotation(r.Note) {
+			t.Fatalf("row %d not rotated: %+v", r.ID, r)
+		}
+		if pt, err := v2.Decrypt(r.Phone); err != nil || string(pt) != fmt.Sprintf("phone-%d", r.ID) {
+			t.Fatalf("row %d phone = %q, %v", r.ID, pt, err)
+		}
+	}
+
+	// 再次运行从进度续跑，只扫描新增行
+	phone, _ := v1.Encrypt([]byte("phone-6"))
+	if err := db.Exec("INSERT INTO secrets (id, phone, note) VALUES (6, ?, '')", phone).Error; err != nil {
+		t.Fatal(err)
+	}
+	progress = nil
+	if err := job.RunNow(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if last := progress[len(progress)-1]; last.Scanned != 1 || last.Rotated != 1 {
+		t.Fatalf("second run = %+v", progress)
+	}
+	if st := job.Stats(); st.Runs != 2 || st.Failures != 0 {
+		t.Fatalf("job stats = %+v", st)
+	}
+}
+
+func TestKeyRotator_RequiresColumns(t *testing.T) {
+	rotator := NewKeyRotator(newUoWDB(t), testKeyring(t, 2), KeyRotatorConfig{Table: "secrets"})
+	if err := rotator.Run(context.Background()); err == nil {
+		t.Fatal("expected error without columns")
+	}
+}
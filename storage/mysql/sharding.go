@@ -0,0 +1,423 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 分片算法
+const (
+	ShardMod  = "mod"  // 整数分片键取模（默认）
+	ShardHash = "hash" // 分片键字符串形式的 CRC32 取模，适用于字符串键
+)
+
+// ErrMissingShardKey 分片表的语句无法确定分片键
+var ErrMissingShardKey = errors.New("mysql: missing shard key")
+
+// ShardingConfig 单张逻辑表的分片规则。
+// 分片序号为 0..ShardCount-1，物理表名为 <table>_<序号>（按 ShardCount 位数补零，如 orders_03）；
+// DatabaseCount > 0 时同时分库，分片 i 位于 DatabaseFormat 格式化 i % DatabaseCount 的库中（同一实例内跨库访问）
+type ShardingConfig struct {
+	Table          string `json:"table" yaml:"table"`                     // 逻辑表名
+	ShardKey       string `json:"shard_key" yaml:"shard_key"`             // 分片键列名
+	ShardCount     int    `json:"shard_count" yaml:"shard_count"`         // 分片（物理表）数量
+	Algorithm      string `json:"algorithm" yaml:"algorithm"`             // mod（默认）或 hash
+	DatabaseCount  int    `json:"database_count" yaml:"database_count"`   // 分库数量，0 表示不分库
+	DatabaseFormat string `json:"database_format" yaml:"database_format"` // 分库名格式，如 "order_db_%d"
+}
+
+// ShardAlgorithm 自定义分片算法，返回 [0, count) 内的分片序号
+type ShardAlgorithm func(key any, count int) (int, error)
+
+type shardRule struct {
+	ShardingConfig
+	algorithm ShardAlgorithm
+	suffix    string
+	keyExpr   *regexp.Regexp // 匹配 SQL 条件中的 "<shard_key> = ?"
+}
+
+// Sharding 分片路由
+type Sharding struct {
+	rules map[string]*shardRule
+}
+
+// NewSharding 校验分片规则并创建路由，algorithms 为按名称注册的自定义算法（可覆盖 mod、hash）
+func NewSharding(configs []ShardingConfig, algorithms map[string]ShardAlgorithm) (*Sharding, error) {
+	s := &Sharding{rules: make(map[string]*shardRule, len(configs))}
+	for _, cfg := range configs {
+		if cfg.Table == "" || cfg.ShardKey == "" {
+			return nil, fmt.Errorf("mysql: sharding requires table and shard_key")
+		}
+		if cfg.ShardCount <= 0 {
+			return nil, fmt.Errorf("mysql: sharding %s: shard_count must be positive", cfg.Table)
+		}
+		if cfg.DatabaseCount > 0 && !strings.Contains(cfg.DatabaseFormat, "%") {
+			return nil, fmt.Errorf("mysql: sharding %s: database_format requires a %%d verb", cfg.Table)
+		}
+		if _, ok := s.rules[cfg.Table]; ok {
+			return nil, fmt.Errorf("mysql: sharding %s: duplicate rule", cfg.Table)
+		}
+		if cfg.Algorithm == "" {
+			cfg.Algorithm = ShardMod
+		}
+		alg, ok := algorithms[cfg.Algorithm]
+		if !ok {
+			switch cfg.Algorithm {
+			case ShardMod:
+				alg = modShard
+			case ShardHash:
+				alg = hashShard
+			default:
+				return nil, fmt.Errorf("mysql: sharding %s: unknown algorithm %q", cfg.Table, cfg.Algorithm)
+			}
+		}
+		digits := len(strconv.Itoa(cfg.ShardCount - 1))
+		s.rules[cfg.Table] = &shardRule{
+			ShardingConfig: cfg,
+			algorithm:      alg,
+			suffix:         "_%0" + strconv.Itoa(digits) + "d",
+			keyExpr:        regexp.MustCompile("(?i)(?:^|[^\\w.`])(?:`?\\w+`?\\.)?`?" + regexp.QuoteMeta(cfg.ShardKey) + "`?\\s*=\\s*\\?"),
+		}
+	}
+	return s, nil
+}
+
+// Sharded 判断逻辑表是否配置了分片
+func (s *Sharding) Sharded(table string) bool {
+	_, ok := s.rules[table]
+	return ok
+}
+
+// Route 返回分片键对应的物理表名（分库时带库名前缀，如 order_db_1.orders_03）
+func (s *Sharding) Route(table string, key any) (string, error) {
+	r, ok := s.rules[table]
+	if !ok {
+		return table, nil
+	}
+	idx, err := r.algorithm(key, r.ShardCount)
+	if err != nil {
+		return "", fmt.Errorf("mysql: sharding %s: %w", table, err)
+	}
+	if idx < 0 || idx >= r.ShardCount {
+		return "", fmt.Errorf("mysql: sharding %s: shard %d out of range", table, idx)
+	}
+	return r.physical(idx), nil
+}
+
+// Tables 返回逻辑表的全部物理表名，用于建表、扫描全部分片等
+func (s *Sharding) Tables(table string) []string {
+	r, ok := s.rules[table]
+	if !ok {
+		return []string{table}
+	}
+	tables := make([]string, r.ShardCount)
+	for i := range tables {
+		tables[i] = r.physical(i)
+	}
+	return tables
+}
+
+func (r *shardRule) physical(idx int) string {
+	name := r.Table + fmt.Sprintf(r.suffix, idx)
+	if r.DatabaseCount > 0 {
+		return r.database(idx%r.DatabaseCount) + "." + name
+	}
+	return name
+}
+
+func (r *shardRule) database(idx int) string {
+	return fmt.Sprintf(r.DatabaseFormat, idx)
+}
+
+// Migrate 为模型所在的分片逻辑表创建（或迁移）全部物理表，分库时先创建库；未分片的模型直接 AutoMigrate
+//
+//	if err := sharding.Migrate(ctx, db.DB(), &Order{}, &OrderItem{}); err != nil { ... }
+func (s *Sharding) Migrate(ctx context.Context, db *gorm.DB, models ...any) error {
+	db = db.WithContext(ctx)
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return fmt.Errorf("mysql: parse model: %w", err)
+		}
+		r, ok := s.rules[stmt.Schema.Table]
+		if !ok {
+			if err := db.AutoMigrate(model); err != nil {
+				return fmt.Errorf("mysql: migrate %s: %w", stmt.Schema.Table, err)
+			}
+			continue
+		}
+		for i := 0; i < r.DatabaseCount; i++ {
+			if err := db.Exec("CREATE DATABASE IF NOT EXISTS ?", clause.Table{Name: r.database(i)}).Error; err != nil {
+				return fmt.Errorf("mysql: create shard database %s: %w", r.database(i), err)
+			}
+		}
+		for _, table := range s.Tables(r.Table) {
+			if err := db.Table(table).AutoMigrate(model); err != nil {
+				return fmt.Errorf("mysql: migrate shard %s: %w", table, err)
+			}
+		}
+	}
+	return nil
+}
+
+const shardKeySetting = "higo:shard_key"
+
+// Shard 显式指定本次语句的分片键，优先于从条件或模型中推断
+//
+//	db.Scopes(mysql.Shard(userID)).Where("status = ?", "paid").Find(&orders)
+func Shard(key any) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Set(shardKeySetting, key)
+	}
+}
+
+const shardTableKey = "higo:shard_table"
+
+// ShardingPlugin 分片路由插件
+// 语句的表为分片逻辑表时，按分片键改写为物理表。分片键依次取自 Shard 显式指定、
+// WHERE 中分片键的等值条件（Where("user_id = ?", id)、结构体或 map 条件）、写入或更新的模型字段；
+// 均无法确定时语句失败并返回 ErrMissingShardKey。WHERE 含 Or/Not 条件时可能跨分片，
+// 不做推断，须通过 Shard 显式指定。批量写入的记录须落在同一分片。
+// JOIN 等原生 SQL 片段中的逻辑表名不会被改写。
+type ShardingPlugin struct {
+	Sharding *Sharding
+}
+
+// Name 插件名称
+func (ShardingPlugin) Name() string { return "higo:sharding" }
+
+// Initialize 注册回调
+func (p ShardingPlugin) Initialize(db *gorm.DB) error {
+	const name = "higo:sharding"
+	// 审计插件已注册时排在其变更前镜像查询之前（后者又在 gorm:update/gorm:delete 之前），使其读取物理表
+	updateBefore, deleteBefore := "gorm:update", "gorm:delete"
+	if _, ok := db.Config.Plugins[(*AuditTrailPlugin)(nil).Name()]; ok {
+		updateBefore, deleteBefore = "higo:audit_trail_before_update", "higo:audit_trail_before_delete"
+	}
+	cb := db.Callback()
+	if err := cb.Create().Before("gorm:create").Register(name, p.route); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register(name, p.route); err != nil {
+		return err
+	}
+	if err := cb.Update().Before(updateBefore).Register(name, p.route); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before(deleteBefore).Register(name, p.route); err != nil {
+		return err
+	}
+	return cb.Row().Before("gorm:row").Register(name, p.route)
+}
+
+func (p ShardingPlugin) route(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	stmt := db.Statement
+	r, ok := p.Sharding.rules[stmt.Table]
+	if !ok {
+		return
+	}
+
+	key, ok := db.Get(shardKeySetting)
+	if !ok && whereHasOrNot(stmt) {
+		_ = db.AddError(fmt.Errorf("%w: %s.%s (or/not conditions require explicit Shard)", ErrMissingShardKey, r.Table, r.ShardKey))
+		return
+	}
+	if !ok {
+		key, ok = whereShardKey(stmt, r)
+	}
+	if !ok {
+		var err error
+		key, ok, err = modelShardKey(stmt, r)
+		if err != nil {
+			_ = db.AddError(err)
+			return
+		}
+	}
+	if !ok {
+		_ = db.AddError(fmt.Errorf("%w: %s.%s", ErrMissingShardKey, r.Table, r.ShardKey))
+		return
+	}
+
+	table, err := p.Sharding.Route(r.Table, key)
+	if err != nil {
+		_ = db.AddError(err)
+		return
+	}
+	// 指标使用逻辑表名，避免按分片放大标签
+	db.InstanceSet(shardTableKey, r.Table)
+	stmt.Table = table
+}
+
+// whereShardKey 从 WHERE 条件中查找分片键的等值条件
+func whereShardKey(stmt *gorm.Statement, r *shardRule) (any, bool) {
+	c, ok := stmt.Clauses["WHERE"]
+	if !ok {
+		return nil, false
+	}
+	where, ok := c.Expression.(clause.Where)
+	if !ok {
+		return nil, false
+	}
+	return exprShardKey(where.Exprs, r)
+}
+
+// whereHasOrNot 判断 WHERE 是否含 Or/Not 条件，此时等值条件不能确定唯一分片
+func whereHasOrNot(stmt *gorm.Statement) bool {
+	c, ok := stmt.Clauses["WHERE"]
+	if !ok {
+		return false
+	}
+	where, ok := c.Expression.(clause.Where)
+	return ok && exprsHaveOrNot(where.Exprs)
+}
+
+func exprsHaveOrNot(exprs []clause.Expression) bool {
+	for _, e := range exprs {
+		switch v := e.(type) {
+		case clause.OrConditions, clause.NotConditions:
+			return true
+		case clause.AndConditions:
+			if exprsHaveOrNot(v.Exprs) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func exprShardKey(exprs []clause.Expression, r *shardRule) (any, bool) {
+	for _, e := range exprs {
+		switch v := e.(type) {
+		case clause.Eq:
+			if columnName(v.Column) == r.ShardKey {
+				return v.Value, true
+			}
+		case clause.AndConditions:
+			if key, ok := exprShardKey(v.Exprs, r); ok {
+				return key, true
+			}
+		case clause.Expr:
+			if key, ok := sqlShardKey(v, r.keyExpr); ok {
+				return key, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func columnName(c any) string {
+	switch v := c.(type) {
+	case string:
+		return strings.Trim(v, "`")
+	case clause.Column:
+		return v.Name
+	}
+	return ""
+}
+
+// sqlShardKey 匹配形如 "user_id = ?" 的条件片段，按占位符位置取参数；OR 条件无法保证落在单一分片，不参与推断
+func sqlShardKey(e clause.Expr, re *regexp.Regexp) (any, bool) {
+	if strings.Contains(strings.ToUpper(e.SQL), " OR ") {
+		return nil, false
+	}
+	loc := re.FindStringIndex(e.SQL)
+	if loc == nil {
+		return nil, false
+	}
+	idx := strings.Count(e.SQL[:loc[1]], "?") - 1
+	if idx < 0 || idx >= len(e.Vars) {
+		return nil, false
+	}
+	return e.Vars[idx], true
+}
+
+// modelShardKey 从写入或更新的模型中读取分片键，批量写入时要求所有记录落在同一分片
+func modelShardKey(stmt *gorm.Statement, r *shardRule) (any, bool, error) {
+	if stmt.Schema == nil {
+		return nil, false, nil
+	}
+	field := stmt.Schema.LookUpField(r.ShardKey)
+	if field == nil {
+		return nil, false, nil
+	}
+	rv := reflect.Indirect(stmt.ReflectValue)
+	switch rv.Kind() {
+	case reflect.Struct:
+		v, zero := field.ValueOf(stmt.Context, rv)
+		if zero {
+			return nil, false, nil
+		}
+		return v, true, nil
+	case reflect.Slice, reflect.Array:
+		var (
+			key   any
+			shard = -1
+		)
+		for i := 0; i < rv.Len(); i++ {
+			v, zero := field.ValueOf(stmt.Context, reflect.Indirect(rv.Index(i)))
+			if zero {
+				return nil, false, nil
+			}
+			idx, err := r.algorithm(v, r.ShardCount)
+			if err != nil {
+				return nil, false, fmt.Errorf("mysql: sharding %s: %w", r.Table, err)
+			}
+			if shard >= 0 && idx != shard {
+				return nil, false, fmt.Errorf("mysql: sharding %s: batch spans multiple shards", r.Table)
+			}
+			key, shard = v, idx
+		}
+		return key, key != nil, nil
+	}
+	return nil, false, nil
+}
+
+func modShard(key any, count int) (int, error) {
+	v := reflect.Indirect(reflect.ValueOf(key))
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := v.Int() % int64(count)
+		if n < 0 {
+			n = -n
+		}
+		return int(n), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int(v.Uint() % uint64(count)), nil
+	case reflect.String:
+		n, err := strconv.ParseInt(v.String(), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("mod algorithm requires integer key, got %q", v.String())
+		}
+		return modShard(n, count)
+	}
+	return 0, fmt.Errorf("mod algorithm requires integer key, got %T", key)
+}
+
+func hashShard(key any, count int) (int, error) {
+	var s string
+	switch v := key.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	case fmt.Stringer:
+		s = v.String()
+	default:
+		s = fmt.Sprint(key)
+	}
+	return int(crc32.ChecksumIEEE([]byte(s)) % uint32(count)), nil
+}
+
+var _ gorm.Plugin = ShardingPlugin{}
@@ -0,0 +1,192 @@
+package mysql
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+type shardOrder struct {
+	ID     int64 `gorm:"primaryKey"`
+	UserID int64
+	Status string
+}
+
+func (shardOrder) TableName() string { return "orders" }
+
+func newShardDB(t *testing.T, cfgs ...ShardingConfig) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "user:pass@tcp(127.0.0.1:3306)/app",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sharding, err := NewSharding(cfgs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(ShardingPlugin{Sharding: sharding}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestSharding_Route(t *testing.T) {
+	s, err := NewSharding([]ShardingConfig{
+		{Table: "orders", ShardKey: "user_id", ShardCount: 16},
+		{Table: "events", ShardKey: "tenant", ShardCount: 4, Algorithm: ShardHash, DatabaseCount: 2, DatabaseFormat: "event_db_%d"},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, _ := s.Route("orders", int64(35)); got != "orders_03" {
+		t.Fatalf("orders route = %q", got)
+	}
+	if got, _ := s.Route("orders", "18"); got != "orders_02" {
+		t.Fatalf("string numeric key route = %q", got)
+	}
+	if _, err := s.Route("orders", "abc"); err == nil {
+		t.Fatal("expected error for non-integer mod key")
+	}
+	if got, _ := s.Route("users", 1); got != "users" {
+		t.Fatalf("unsharded route = %q", got)
+	}
+
+	tables := s.Tables("events")
+	want := []string{"event_db_0.events_0", "event_db_1.events_1", "event_db_0.events_2", "event_db_1.events_3"}
+	if strings.Join(tables, ",") != strings.Join(want, ",") {
+		t.Fatalf("Tables = %v", tables)
+	}
+	a, _ := s.Route("events", "acme")
+	b, _ := s.Route("events", "acme")
+	if a != b || !strings.HasPrefix(a, "event_db_") {
+		t.Fatalf("hash route not stable: %q %q", a, b)
+	}
+}
+
+func TestSharding_Config(t *testing.T) {
+	bad := []ShardingConfig{
+		{Table: "orders", ShardCount: 4},
+		{Table: "orders", ShardKey: "user_id"},
+		{Table: "orders", ShardKey: "user_id", ShardCount: 4, Algorithm: "range"},
+		{Table: "orders", ShardKey: "user_id", ShardCount: 4, DatabaseCount: 2},
+	}
+	for _, cfg := range bad {
+		if _, err := NewSharding([]ShardingConfig{cfg}, nil); err == nil {
+			t.Fatalf("expected error for %+v", cfg)
+		}
+	}
+
+	s, err := NewSharding([]ShardingConfig{{Table: "orders", ShardKey: "user_id", ShardCount: 4, Algorithm: "first"}},
+		map[string]ShardAlgorithm{"first": func(any, int) (int, error) { return 0, nil }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.Route("orders", 3); got != "orders_0" {
+		t.Fatalf("custom algorithm route = %q", got)
+	}
+}
+
+func TestShardingPlugin(t *testing.T) {
+	db := newShardDB(t, ShardingConfig{Table: "orders", ShardKey: "user_id", ShardCount: 16})
+
+	cases := []struct {
+		name  string
+		exec  func(db *gorm.DB) *gorm.DB
+		table string
+	}{
+		{"where expr", func(db *gorm.DB) *gorm.DB {
+			return db.Where("status = ? AND user_id = ?", "paid", 35).Find(&[]shardOrder{})
+		}, "`orders_03`"},
+		{"where struct", func(db *gorm.DB) *gorm.DB {
+			return db.Where(&shardOrder{UserID: 17}).Find(&[]shardOrder{})
+		}, "`orders_01`"},
+		{"where map", func(db *gorm.DB) *gorm.DB {
+			return db.Model(&shardOrder{}).Where(map[string]any{"user_id": 31}).Count(new(int64))
+		}, "`orders_15`"},
+		{"explicit", func(db *gorm.DB) *gorm.DB {
+			return db.Scopes(Shard(4)).Where("status = ?", "paid").Find(&[]shardOrder{})
+		}, "`orders_04`"},
+		{"create", func(db *gorm.DB) *gorm.DB {
+			return db.Create(&shardOrder{ID: 1, UserID: 21})
+		}, "`orders_05`"},
+		{"batch create", func(db *gorm.DB) *gorm.DB {
+			return db.Create([]shardOrder{{ID: 1, UserID: 2}, {ID: 2, UserID: 18}})
+		}, "`orders_02`"},
+		{"save", func(db *gorm.DB) *gorm.DB {
+			return db.Save(&shardOrder{ID: 9, UserID: 38, Status: "paid"})
+		}, "`orders_06`"},
+		{"delete", func(db *gorm.DB) *gorm.DB {
+			return db.Where("user_id = ?", 7).Delete(&shardOrder{}, 3)
+		}, "`orders_07`"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := tc.exec(db.Session(&gorm.Session{}))
+			if res.Error != nil {
+				t.Fatal(res.Error)
+			}
+			sql := res.Statement.SQL.String()
+			if !strings.Contains(sql, tc.table) || strings.Contains(sql, "`orders`") {
+				t.Fatalf("sql = %s, want table %s", sql, tc.table)
+			}
+		})
+	}
+}
+
+func TestShardingPlugin_Errors(t *testing.T) {
+	db := newShardDB(t, ShardingConfig{Table: "orders", ShardKey: "user_id", ShardCount: 16})
+
+	err := db.Where("status = ?", "paid").Find(&[]shardOrder{}).Error
+	if !errors.Is(err, ErrMissingShardKey) {
+		t.Fatalf("missing key err = %v", err)
+	}
+	err = db.Where("user_id = ? OR user_id = ?", 1, 2).Find(&[]shardOrder{}).Error
+	if !errors.Is(err, ErrMissingShardKey) {
+		t.Fatalf("or condition err = %v", err)
+	}
+	// Or/Not 条件与分片键等值条件并列时同样不能路由到单一分片
+	for name, q := range map[string]*gorm.DB{
+		"or":        db.Where("user_id = ?", 1).Or("user_id = ?", 2),
+		"not":       db.Not("user_id = ?", 1),
+		"nested or": db.Where(db.Where("user_id = ?", 1).Or("status = ?", "paid")).Where("id = ?", 3),
+	} {
+		err = q.Find(&[]shardOrder{}).Error
+		if !errors.Is(err, ErrMissingShardKey) {
+			t.Fatalf("%s condition err = %v", name, err)
+		}
+	}
+	// 显式 Shard 优先于条件推断
+	res := db.Scopes(Shard(1)).Where("user_id = ?", 1).Or("user_id = ?", 17).Find(&[]shardOrder{})
+	if res.Error != nil || !strings.Contains(res.Statement.SQL.String(), "`orders_01`") {
+		t.Fatalf("explicit shard = %s, %v", res.Statement.SQL.String(), res.Error)
+	}
+
+	err = db.Create([]shardOrder{{ID: 1, UserID: 1}, {ID: 2, UserID: 2}}).Error
+	if err == nil || !strings.Contains(err.Error(), "multiple shards") {
+		t.Fatalf("cross-shard batch err = %v", err)
+	}
+
+	// 未分片的表不受影响
+	res = db.Table("users").Where("id = ?", 1).Find(&[]map[string]any{})
+	if res.Error != nil || !strings.Contains(res.Statement.SQL.String(), "`users`") {
+		t.Fatalf("unsharded = %s, %v", res.Statement.SQL.String(), res.Error)
+	}
+}
+
+func TestShardingPlugin_Database(t *testing.T) {
+	db := newShardDB(t, ShardingConfig{Table: "orders", ShardKey: "user_id", ShardCount: 4, DatabaseCount: 2, DatabaseFormat: "order_db_%d"})
+	res := db.Where("user_id = ?", 3).Find(&[]shardOrder{})
+	if res.Error != nil {
+		t.Fatal(res.Error)
+	}
+	if sql := res.Statement.SQL.String(); !strings.Contains(sql, "`order_db_1`.`orders_3`") {
+		t.Fatalf("sql = %s", sql)
+	}
+}
@@ -0,0 +1,104 @@
+package mysql
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/mildsunup/higo/ddd"
+)
+
+// SpecExpression 将规约编译为 GORM 条件表达式
+func SpecExpression[T any](spec ddd.Specification[T]) (clause.Expression, error) {
+	c, ok := ddd.CriteriaOf(spec)
+	if !ok {
+		return nil, fmt.Errorf("mysql: specification is not queryable")
+	}
+	return compileCriteria(c)
+}
+
+// SpecScope 将规约作为 GORM Scope 使用
+//
+//	db.Scopes(mysql.SpecScope(spec)).Find(&users)
+func SpecScope[T any](spec ddd.Specification[T]) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		expr, err := SpecExpression(spec)
+		if err != nil {
+			_ = db.AddError(err)
+			return db
+		}
+		return db.Where(expr)
+	}
+}
+
+func compileCriteria(c ddd.Criteria) (clause.Expression, error) {
+	col := clause.Column{Name: c.Field}
+
+	switch c.Op {
+	case ddd.OpEq:
+		return clause.Eq{Column: col, Value: c.Value}, nil
+	case ddd.OpNe:
+		return clause.Neq{Column: col, Value: c.Value}, nil
+	case ddd.OpGt:
+		return clause.Gt{Column: col, Value: c.Value}, nil
+	case ddd.OpGte:
+		return clause.Gte{Column: col, Value: c.Value}, nil
+	case ddd.OpLt:
+		return clause.Lt{Column: col, Value: c.Value}, nil
+	case ddd.OpLte:
+		return clause.Lte{Column: col, Value: c.Value}, nil
+	case ddd.OpLike:
+		return clause.Like{Column: col, Value: c.Value}, nil
+	case ddd.OpIsNull:
+		return clause.Expr{SQL: "? IS NULL", Vars: []any{col}}, nil
+	case ddd.OpIn:
+		values, ok := c.Value.([]any)
+		if !ok {
+			return nil, fmt.Errorf("mysql: in criteria on %q requires []any", c.Field)
+		}
+		if len(values) == 0 {
+			return clause.Expr{SQL: "1 = 0"}, nil
+		}
+		return clause.IN{Column: col, Values: values}, nil
+	case ddd.OpAnd, ddd.OpOr:
+		exprs, err := compileChildren(c.Children)
+		if err != nil {
+			return nil, err
+		}
+		if len(exprs) == 0 {
+			if c.Op == ddd.OpOr {
+				return clause.Expr{SQL: "1 = 0"}, nil
+			}
+			return clause.Expr{SQL: "1 = 1"}, nil
+		}
+		if c.Op == ddd.OpOr {
+			return clause.Or(exprs...), nil
+		}
+		return clause.And(exprs...), nil
+	case ddd.OpNot:
+		if len(c.Children) != 1 {
+			return nil, fmt.Errorf("mysql: not criteria requires exactly one child")
+		}
+		expr, err := compileCriteria(c.Children[0])
+		if err != nil {
+			return nil, err
+		}
+		// clause.Not 对 AND 子条件逐项取反（NOT (a AND b) 会变成 a <> ? AND b <> ?），显式包裹整体取反
+		return clause.Expr{SQL: "NOT (?)", Vars: []any{expr}}, nil
+	default:
+		return nil, fmt.Errorf("mysql: unsupported criteria operator %q", c.Op)
+	}
+}
+
+func compileChildren(children []ddd.Criteria) ([]clause.Expression, error) {
+	exprs := make([]clause.Expression, 0, len(children))
+	for _, child := range children {
+		expr, err := compileCriteria(child)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+	return exprs, nil
+}
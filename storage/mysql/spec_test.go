@@ -0,0 +1,99 @@
+package mysql
+
+import (
+	"slices"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"github.com/mildsunup/higo/ddd"
+)
+
+func productName(p *product) string { return p.Name }
+func productPrice(p *product) int64 { return p.Price }
+
+func TestSpecExpression_SQL(t *testing.T) {
+	db := newUoWDB(t)
+	dry := db.Session(&gorm.Session{DryRun: true})
+	pen := ddd.Eq("name", "pen", productName)
+	cheap := ddd.Lt("price", int64(20), productPrice)
+	book := ddd.Eq("name", "book", productName)
+
+	tests := []struct {
+		name string
+		spec ddd.Specification[*product]
+		want string
+	}{
+		{"not and", ddd.Not(ddd.And(pen, cheap)), "NOT ((`name` = ? AND `price` < ?))"},
+		{"not or", ddd.Not(ddd.Or(pen, book)), "NOT ((`name` = ? OR `name` = ?))"},
+		{"or of and", ddd.Or(ddd.And(pen, cheap), book), "((`name` = ? AND `price` < ?) OR `name` = ?)"},
+		{"empty in", ddd.In("name", []string{}, productName), "1 = 0"},
+	}
+	for _, tt := range tests {
+		expr, err := SpecExpression(tt.spec)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		stmt := dry.Model(&product{}).Where(expr).Find(&[]product{}).Statement
+		want := "SELECT * FROM `products` WHERE " + tt.want
+		if got := stmt.SQL.String(); got != want {
+			t.Errorf("%s: sql = %s, want %s", tt.name, got, want)
+		}
+	}
+
+	if _, err := SpecExpression(ddd.And(pen, ddd.Spec(func(*product) bool { return true }))); err == nil {
+		t.Fatal("expected error for non-queryable child")
+	}
+}
+
+// SQL 过滤结果必须与 IsSatisfiedBy 的内存判断一致
+func TestSpecExpression_MatchesInMemory(t *testing.T) {
+	db := newUoWDB(t)
+	if err := db.AutoMigrate(&product{}); err != nil {
+		t.Fatal(err)
+	}
+	rows := []*product{
+		{ID: 1, Name: "pen", Price: 10},
+		{ID: 2, Name: "pen", Price: 30},
+		{ID: 3, Name: "book", Price: 10},
+		{ID: 4, Name: "lamp", Price: 50},
+	}
+	if err := db.Create(rows).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	pen := ddd.Eq("name", "pen", productName)
+	cheap := ddd.Lt("price", int64(20), productPrice)
+	specs := map[string]ddd.Specification[*product]{
+		"not and":      ddd.Not(ddd.And(pen, cheap)),
+		"not or":       ddd.Not(ddd.Or(pen, cheap)),
+		"not not":      ddd.Not(ddd.Not(pen)),
+		"or of and":    ddd.Or(ddd.And(pen, cheap), ddd.Gt("price", int64(40), productPrice)),
+		"and of not":   ddd.And(ddd.Not(pen), ddd.Not(cheap)),
+		"like":         ddd.Where("name", ddd.OpLike, "%o%", func(p *product) bool { return p.Name == "book" }),
+		"in":           ddd.In("name", []string{"book", "lamp"}, productName),
+		"empty in":     ddd.In("name", []string{}, productName),
+		"not in":       ddd.Not(ddd.In("name", []string{}, productName)),
+		"empty all":    ddd.All[*product](),
+		"empty any":    ddd.Any[*product](),
+		"not empty or": ddd.Not(ddd.Any[*product]()),
+	}
+	for name, spec := range specs {
+		var got []*product
+		if err := db.Scopes(SpecScope(spec)).Order("id").Find(&got).Error; err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		var gotIDs, wantIDs []int64
+		for _, p := range got {
+			gotIDs = append(gotIDs, p.ID)
+		}
+		for _, p := range rows {
+			if spec.IsSatisfiedBy(p) {
+				wantIDs = append(wantIDs, p.ID)
+			}
+		}
+		if !slices.Equal(gotIDs, wantIDs) {
+			t.Errorf("%s: sql ids = %v, in-memory ids = %v", name, gotIDs, wantIDs)
+		}
+	}
+}
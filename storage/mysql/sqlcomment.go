@@ -0,0 +1,183 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/otel/propagation"
+	"gorm.io/gorm"
+)
+
+// SQLCommenterConfig SQL 注释配置
+type SQLCommenterConfig struct {
+	Enabled     bool   `json:"enabled" yaml:"enabled"`         // 按环境开关，如仅在生产开启以关联慢查询日志
+	Application string `json:"application" yaml:"application"` // application 标签，通常为服务名
+}
+
+type sqlCommentKey struct{}
+
+// WithSQLComment 为 context 下执行的 SQL 附加注释标签（如 route、controller），与已有标签合并
+//
+//	r.Use(func(c *gin.Context) {
+//	    c.Request = c.Request.WithContext(mysql.WithSQLComment(c.Request.Context(), "route", c.FullPath()))
+//	    c.Next()
+//	})
+func WithSQLComment(ctx context.Context, kv ...string) context.Context {
+	tags := make(map[string]string)
+	if prev, ok := ctx.Value(sqlCommentKey{}).(map[string]string); ok {
+		maps.Copy(tags, prev)
+	}
+	for i := 0; i+1 < len(kv); i += 2 {
+		tags[kv[i]] = kv[i+1]
+	}
+	return context.WithValue(ctx, sqlCommentKey{}, tags)
+}
+
+// SQLCommenterPlugin 按 sqlcommenter 规范（https://google.github.io/sqlcommenter/spec/）在 SQL 末尾追加注释：
+//
+//	SELECT * FROM orders WHERE id = ? /*application='orders',route='%2Fv1%2Forders%2F%3Aid',traceparent='00-...-01'*/
+//
+// 数据库侧的慢查询日志、processlist 可据此关联到请求链路与服务。
+// 标签来源：Application、Route 回调、WithSQLComment 设置的标签，以及当前 Span 的 traceparent/tracestate。
+// 预编译语句模式（PrepareStmt）下不注入，避免注释中的 traceparent 使语句缓存失效。
+type SQLCommenterPlugin struct {
+	Application string                           // application 标签
+	Route       func(ctx context.Context) string // route 标签来源，可为空
+}
+
+// Name 插件名称
+func (SQLCommenterPlugin) Name() string { return "higo:sql_commenter" }
+
+// Initialize 注册回调：执行前包装连接池（在读写分离选定连接之后），执行后、提交默认事务前还原
+func (p SQLCommenterPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	hooks := []struct {
+		name   string
+		before func(string, func(*gorm.DB)) error
+		after  func(string, func(*gorm.DB)) error
+	}{
+		{"gorm:create", cb.Create().Before("gorm:create").After("gorm:db_resolver").Register, cb.Create().After("gorm:create").Before("gorm:commit_or_rollback_transaction").Register},
+		{"gorm:query", cb.Query().Before("gorm:query").After("gorm:db_resolver").Register, cb.Query().After("gorm:query").Before("gorm:commit_or_rollback_transaction").Register},
+		{"gorm:update", cb.Update().Before("gorm:update").After("gorm:db_resolver").Register, cb.Update().After("gorm:update").Before("gorm:commit_or_rollback_transaction").Register},
+		{"gorm:delete", cb.Delete().Before("gorm:delete").After("gorm:db_resolver").Register, cb.Delete().After("gorm:delete").Before("gorm:commit_or_rollback_transaction").Register},
+		{"gorm:row", cb.Row().Before("gorm:row").After("gorm:db_resolver").Register, cb.Row().After("gorm:row").Before("gorm:commit_or_rollback_transaction").Register},
+		{"gorm:raw", cb.Raw().Before("gorm:raw").After("gorm:db_resolver").Register, cb.Raw().After("gorm:raw").Before("gorm:commit_or_rollback_transaction").Register},
+	}
+	for _, h := range hooks {
+		op := strings.TrimPrefix(h.name, "gorm:")
+		if err := h.before("higo:sql_commenter_"+op, p.before); err != nil {
+			return err
+		}
+		if err := h.after("higo:sql_commenter_"+op+"_restore", restoreConnPool); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p SQLCommenterPlugin) before(db *gorm.DB) {
+	ctx := db.Statement.Context
+	if ctx == nil || db.Statement.ConnPool == nil {
+		return
+	}
+	switch db.Statement.ConnPool.(type) {
+	case *gorm.PreparedStmtDB, *gorm.PreparedStmtTX, *commentedPool:
+		return
+	}
+	comment := p.comment(ctx)
+	if comment == "" {
+		return
+	}
+	db.Statement.ConnPool = &commentedPool{ConnPool: db.Statement.ConnPool, comment: comment}
+}
+
+// restoreConnPool 还原被包装的连接池，避免包装泄漏到复用的 Statement（如事务提交）
+func restoreConnPool(db *gorm.DB) {
+	if cp, ok := db.Statement.ConnPool.(*commentedPool); ok {
+		db.Statement.ConnPool = cp.ConnPool
+	}
+}
+
+// comment 生成注释，无标签时返回空
+func (p SQLCommenterPlugin) comment(ctx context.Context) string {
+	tags := make(map[string]string)
+	if prev, ok := ctx.Value(sqlCommentKey{}).(map[string]string); ok {
+		maps.Copy(tags, prev)
+	}
+	if p.Application != "" {
+		tags["application"] = p.Application
+	}
+	if p.Route != nil {
+		if route := p.Route(ctx); route != "" {
+			tags["route"] = route
+		}
+	}
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	maps.Copy(tags, carrier)
+	return formatSQLComment(tags)
+}
+
+// formatSQLComment 按规范编码：键值 URL 编码、单引号转义、按键排序
+func formatSQLComment(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("/*")
+	for i, k := range slices.Sorted(maps.Keys(tags)) {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(escapeSQLComment(url.QueryEscape(k)))
+		b.WriteString("='")
+		b.WriteString(escapeSQLComment(url.PathEscape(tags[k])))
+		b.WriteByte('\'')
+	}
+	b.WriteString("*/")
+	return b.String()
+}
+
+func escapeSQLComment(s string) string {
+	return strings.ReplaceAll(s, "'", `\'`)
+}
+
+// appendSQLComment 在 SQL 末尾（分号之前）追加注释；已含注释的 SQL 保持不变
+func appendSQLComment(query, comment string) string {
+	trimmed := strings.TrimRight(query, " \t\n;")
+	if strings.HasSuffix(trimmed, "*/") {
+		return query
+	}
+	if strings.HasSuffix(strings.TrimRight(query, " \t\n"), ";") {
+		return trimmed + " " + comment + ";"
+	}
+	return trimmed + " " + comment
+}
+
+// commentedPool 为单条语句追加注释的连接池包装
+type commentedPool struct {
+	gorm.ConnPool
+	comment string
+}
+
+func (p *commentedPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return p.ConnPool.PrepareContext(ctx, appendSQLComment(query, p.comment))
+}
+
+func (p *commentedPool) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return p.ConnPool.ExecContext(ctx, appendSQLComment(query, p.comment), args...)
+}
+
+func (p *commentedPool) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return p.ConnPool.QueryContext(ctx, appendSQLComment(query, p.comment), args...)
+}
+
+func (p *commentedPool) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return p.ConnPool.QueryRowContext(ctx, appendSQLComment(query, p.comment), args...)
+}
+
+var _ gorm.Plugin = SQLCommenterPlugin{}
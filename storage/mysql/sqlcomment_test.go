@@ -0,0 +1,214 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestFormatSQLComment(t *testing.T) {
+	if got := formatSQLComment(nil); got != "" {
+		t.Fatalf("empty = %q", got)
+	}
+	got := formatSQLComment(map[string]string{
+		"route":       "/v1/orders/:id",
+		"application": "it's */ done;",
+		"a b":         "x",
+	})
+	want := `/*a+b='x',application='it%27s%20%2A%2F%20done%3B',route='%2Fv1%2Forders%2F:id'*/`
+	if got != want {
+		t.Fatalf("comment = %s\nwant      %s", got, want)
+	}
+	// 值中的引号与注释结束符不能提前闭合
+	body := strings.TrimSuffix(strings.TrimPrefix(got, "/*"), "*/")
+	if strings.Contains(body, "*/") || strings.Count(body, "'") != 6 {
+		t.Fatalf("unescaped comment body: %s", body)
+	}
+	if got := escapeSQLComment(`a'b`); got != `a\'b` {
+		t.Fatalf("escape = %s", got)
+	}
+}
+
+func TestAppendSQLComment(t *testing.T) {
+	const c = "/*k='v'*/"
+	tests := []struct{ in, want string }{
+		{"SELECT 1", "SELECT 1 /*k='v'*/"},
+		{"SELECT 1;", "SELECT 1 /*k='v'*/;"},
+		{"SELECT 1 ; \n", "SELECT 1 /*k='v'*/;"},
+		{"SELECT 1 \n", "SELECT 1 /*k='v'*/"},
+		{"SELECT 1 /*x='y'*/", "SELECT 1 /*x='y'*/"},
+		{"SELECT 1 /*x='y'*/;", "SELECT 1 /*x='y'*/;"},
+	}
+	for _, tt := range tests {
+		if got := appendSQLComment(tt.in, c); got != tt.want {
+			t.Errorf("appendSQLComment(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// recordingPool 记录实际发送到驱动的 SQL
+type recordingPool struct {
+	gorm.ConnPool
+	mu   *sync.Mutex
+	sqls *[]string
+}
+
+func (p *recordingPool) record(query string) {
+	p.mu.Lock()
+	*p.sqls = append(*p.sqls, query)
+	p.mu.Unlock()
+}
+
+func (p *recordingPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	p.record(query)
+	return p.ConnPool.PrepareContext(ctx, query)
+}
+
+func (p *recordingPool) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	p.record(query)
+	return p.ConnPool.ExecContext(ctx, query, args...)
+}
+
+func (p *recordingPool) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	p.record(query)
+	return p.ConnPool.QueryContext(ctx, query, args...)
+}
+
+func (p *recordingPool) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	p.record(query)
+	return p.ConnPool.QueryRowContext(ctx, query, args...)
+}
+
+func (p *recordingPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	db, ok := p.ConnPool.(*sql.DB)
+	if !ok {
+		return nil, gorm.ErrInvalidTransaction
+	}
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &recordingTx{recordingPool{ConnPool: tx, mu: p.mu, sqls: p.sqls}, tx}, nil
+}
+
+type recordingTx struct {
+	recordingPool
+	tx *sql.Tx
+}
+
+func (t *recordingTx) Commit() error   { return t.tx.Commit() }
+func (t *recordingTx) Rollback() error { return t.tx.Rollback() }
+
+func newCommentedDB(t *testing.T, plugin SQLCommenterPlugin) (*gorm.DB, func() []string) {
+	t.Helper()
+	sqlDB, err := sql.Open("sqlite", "file::memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	var (
+		mu   sync.Mutex
+		sqls []string
+	)
+	pool := &recordingPool{ConnPool: sqlDB, mu: &mu, sqls: &sqls}
+	db, err := gorm.Open(&sqlite.Dialector{Conn: pool}, &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&account{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(plugin); err != nil {
+		t.Fatal(err)
+	}
+	return db, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		out := sqls
+		sqls = nil
+		return out
+	}
+}
+
+func TestSQLCommenterPlugin(t *testing.T) {
+	db, taken := newCommentedDB(t, SQLCommenterPlugin{
+		Application: "orders",
+		Route:       func(context.Context) string { return "/v1/accounts" },
+	})
+	taken()
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+	ctx = WithSQLComment(ctx, "controller", "accounts")
+
+	res := db.WithContext(ctx).Create(&account{ID: 1, Balance: 10})
+	if res.Error != nil {
+		t.Fatal(res.Error)
+	}
+	if _, ok := res.Statement.ConnPool.(*commentedPool); ok {
+		t.Fatal("conn pool wrapper leaked after statement")
+	}
+	var got account
+	if err := db.WithContext(ctx).First(&got, 1).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	sqls := taken()
+	if len(sqls) != 2 {
+		t.Fatalf("sqls = %q", sqls)
+	}
+	want := "/*application='orders',controller='accounts',route='%2Fv1%2Faccounts'," +
+		"traceparent='00-01000000000000000000000000000000-0200000000000000-01'*/"
+	for _, q := range sqls {
+		if !strings.HasSuffix(q, want) {
+			t.Fatalf("sql missing comment:\n%s", q)
+		}
+	}
+
+	// 事务内的语句带注释，提交使用还原后的连接
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.Model(&account{}).Where("id = ?", 1).Update("balance", 20).Error
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sqls := taken(); len(sqls) != 1 || !strings.Contains(sqls[0], "controller='accounts'") {
+		t.Fatalf("tx sqls = %q", sqls)
+	}
+
+	// 预编译语句模式不注入
+	if err := db.Session(&gorm.Session{PrepareStmt: true}).WithContext(ctx).First(&got, 1).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, q := range taken() {
+		if strings.Contains(q, "/*") {
+			t.Fatalf("prepared statement commented: %s", q)
+		}
+	}
+}
+
+func TestSQLCommenterPlugin_NoTags(t *testing.T) {
+	db, taken := newCommentedDB(t, SQLCommenterPlugin{})
+	taken()
+	if err := db.Create(&account{ID: 1}).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, q := range taken() {
+		if strings.Contains(q, "/*") {
+			t.Fatalf("comment without tags: %s", q)
+		}
+	}
+}
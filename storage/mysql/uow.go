@@ -0,0 +1,163 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/mildsunup/higo/ddd"
+	"github.com/mildsunup/higo/storage"
+)
+
+// UnitOfWork 基于 GORM 的工作单元
+// 事务存放在 context 中，同一 context 下的所有 Repository 共享该事务；
+// 嵌套 Begin 使用保存点，最外层提交后拉取所有被触达聚合的事件并发布。
+type UnitOfWork struct {
+	db        *gorm.DB
+	publisher ddd.EventPublisher
+	metrics   *storage.Metrics
+	name      string
+}
+
+// UnitOfWorkOption 工作单元选项
+type UnitOfWorkOption func(*UnitOfWork)
+
+// WithEventPublisher 设置提交后的事件发布者
+func WithEventPublisher(p ddd.EventPublisher) UnitOfWorkOption {
+	return func(u *UnitOfWork) {
+		u.publisher = p
+	}
+}
+
+// WithTxMetrics 记录最外层事务从开始到提交或回滚的耗时（operation 标签为 tx），
+// query 标签取开始事务时 context 中的查询名称
+func WithTxMetrics(m *storage.Metrics, name string) UnitOfWorkOption {
+	return func(u *UnitOfWork) {
+		u.metrics = m
+		u.name = name
+	}
+}
+
+// NewUnitOfWork 创建工作单元
+func NewUnitOfWork(db *gorm.DB, opts ...UnitOfWorkOption) *UnitOfWork {
+	u := &UnitOfWork{db: db}
+	for _, opt := range opts {
+		opt(u)
+	}
+	return u
+}
+
+type txKey struct{}
+
+// txFrame 事务帧，最外层 savepoint 为空
+type txFrame struct {
+	tx        *gorm.DB
+	collector *ddd.EventCollector
+	seq       *atomic.Int64
+	start     time.Time
+	query     string
+	savepoint string
+	mark      ddd.CollectorMark // 进入保存点时的收集器位置
+}
+
+// TxFromContext 获取 context 中的事务
+func TxFromContext(ctx context.Context) (*gorm.DB, bool) {
+	f, ok := ctx.Value(txKey{}).(*txFrame)
+	if !ok {
+		return nil, false
+	}
+	return f.tx, true
+}
+
+// Begin 开始事务，已在事务中时创建保存点
+func (u *UnitOfWork) Begin(ctx context.Context) (context.Context, error) {
+	if parent, ok := ctx.Value(txKey{}).(*txFrame); ok {
+		name := fmt.Sprintf("sp_%d", parent.seq.Add(1))
+		if err := parent.tx.SavePoint(name).Error; err != nil {
+			return ctx, fmt.Errorf("mysql: create savepoint: %w", err)
+		}
+		return context.WithValue(ctx, txKey{}, &txFrame{
+			tx:        parent.tx,
+			collector: parent.collector,
+			seq:       parent.seq,
+			savepoint: name,
+			mark:      parent.collector.Mark(),
+		}), nil
+	}
+
+	tx := u.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return ctx, fmt.Errorf("mysql: begin transaction: %w", tx.Error)
+	}
+
+	collector := ddd.NewEventCollector()
+	ctx = ddd.WithEventCollector(ctx, collector)
+	return context.WithValue(ctx, txKey{}, &txFrame{
+		tx:        tx,
+		collector: collector,
+		seq:       new(atomic.Int64),
+		start:     time.Now(),
+		query:     storage.QueryName(ctx),
+	}), nil
+}
+
+// Commit 提交事务，保存点提交无需操作
+func (u *UnitOfWork) Commit(ctx context.Context) error {
+	f, ok := ctx.Value(txKey{}).(*txFrame)
+	if !ok {
+		return fmt.Errorf("mysql: commit without transaction")
+	}
+	if f.savepoint != "" {
+		return nil
+	}
+
+	err := f.tx.Commit().Error
+	u.observe(f, err)
+	if err != nil {
+		return fmt.Errorf("mysql: commit transaction: %w", err)
+	}
+
+	events := f.collector.Pull()
+	if u.publisher == nil || len(events) == 0 {
+		return nil
+	}
+	if err := u.publisher.Publish(context.WithoutCancel(ctx), events...); err != nil {
+		return fmt.Errorf("mysql: publish events after commit: %w", err)
+	}
+	return nil
+}
+
+// Rollback 回滚事务，嵌套时回滚到保存点
+func (u *UnitOfWork) Rollback(ctx context.Context) error {
+	f, ok := ctx.Value(txKey{}).(*txFrame)
+	if !ok {
+		return fmt.Errorf("mysql: rollback without transaction")
+	}
+
+	if f.savepoint != "" {
+		f.collector.Truncate(f.mark)
+		if err := f.tx.RollbackTo(f.savepoint).Error; err != nil {
+			return fmt.Errorf("mysql: rollback to savepoint: %w", err)
+		}
+		return nil
+	}
+
+	f.collector.Truncate(ddd.CollectorMark{})
+	err := f.tx.Rollback().Error
+	u.observe(f, err)
+	if err != nil {
+		return fmt.Errorf("mysql: rollback transaction: %w", err)
+	}
+	return nil
+}
+
+func (u *UnitOfWork) observe(f *txFrame, err error) {
+	if u.metrics != nil {
+		u.metrics.ObserveQuery(u.name, storage.TypeMySQL, storage.OpTx, f.query, f.start, err)
+	}
+}
+
+var _ ddd.UnitOfWork = (*UnitOfWork)(nil)
@@ -0,0 +1,153 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/mildsunup/higo/ddd"
+)
+
+type account struct {
+	ID      int64 `gorm:"primaryKey"`
+	Balance int64
+	events  []ddd.DomainEvent
+}
+
+func (a *account) raise(name string) {
+	a.events = append(a.events, ddd.NewEventBase(name, "1", "account"))
+}
+
+func (a *account) PullEvents() []ddd.DomainEvent {
+	events := a.events
+	a.events = nil
+	return events
+}
+
+type recordingPublisher struct{ names []string }
+
+func (p *recordingPublisher) Publish(_ context.Context, events ...ddd.DomainEvent) error {
+	for _, e := range events {
+		p.names = append(p.names, e.EventName())
+	}
+	return nil
+}
+
+func newUoWDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	if err := db.AutoMigrate(&account{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestUnitOfWork_SavepointRollbackDropsEvents(t *testing.T) {
+	ctx := context.Background()
+	db := newUoWDB(t)
+	pub := &recordingPublisher{}
+	uow := NewUnitOfWork(db, WithEventPublisher(pub))
+	repo := NewRepository[account, ddd.Int64ID](db)
+
+	a := &account{ID: 1, Balance: 100}
+	b := &account{ID: 2, Balance: 0}
+	err := ddd.Transactional(ctx, uow, func(ctx context.Context) error {
+		a.raise("opened")
+		if err := repo.Save(ctx, a); err != nil {
+			return err
+		}
+
+		// 保存点内：已登记聚合产生的新事件与新登记的聚合都应随回滚丢弃
+		inner := ddd.Transactional(ctx, uow, func(ctx context.Context) error {
+			a.Balance -= 50
+			a.raise("debited")
+			if err := repo.Save(ctx, a); err != nil {
+				return err
+			}
+			b.raise("credited")
+			if err := repo.Save(ctx, b); err != nil {
+				return err
+			}
+			return errors.New("transfer rejected")
+		})
+		if inner == nil {
+			t.Fatal("expected inner failure")
+		}
+		a.Balance = 100
+
+		a.raise("audited")
+		return repo.Save(ctx, a)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"opened", "audited"}; !slices.Equal(pub.names, want) {
+		t.Fatalf("published = %v, want %v", pub.names, want)
+	}
+	if got, err := repo.FindByID(ctx, 2); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("rolled back row persisted: %+v, %v", got, err)
+	}
+}
+
+func TestUnitOfWork_NestedSavepoints(t *testing.T) {
+	ctx := context.Background()
+	db := newUoWDB(t)
+	pub := &recordingPublisher{}
+	uow := NewUnitOfWork(db, WithEventPublisher(pub))
+	repo := NewRepository[account, ddd.Int64ID](db)
+
+	a := &account{ID: 1}
+	err := ddd.Transactional(ctx, uow, func(ctx context.Context) error {
+		a.raise("e1")
+		if err := repo.Save(ctx, a); err != nil {
+			return err
+		}
+		return ddd.Transactional(ctx, uow, func(ctx context.Context) error {
+			a.raise("e2")
+			_ = ddd.Transactional(ctx, uow, func(ctx context.Context) error {
+				a.raise("e3")
+				return errors.New("abort")
+			})
+			a.raise("e4")
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"e1", "e2", "e4"}; !slices.Equal(pub.names, want) {
+		t.Fatalf("published = %v, want %v", pub.names, want)
+	}
+}
+
+func TestUnitOfWork_RollbackPublishesNothing(t *testing.T) {
+	ctx := context.Background()
+	db := newUoWDB(t)
+	pub := &recordingPublisher{}
+	uow := NewUnitOfWork(db, WithEventPublisher(pub))
+	repo := NewRepository[account, ddd.Int64ID](db)
+
+	a := &account{ID: 1}
+	_ = ddd.Transactional(ctx, uow, func(ctx context.Context) error {
+		a.raise("opened")
+		if err := repo.Save(ctx, a); err != nil {
+			return err
+		}
+		return errors.New("abort")
+	})
+	if len(pub.names) != 0 {
+		t.Fatalf("published after rollback: %v", pub.names)
+	}
+}
@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/mildsunup/higo/cache"
+)
+
+// CacheKeyFunc 根据查询名称与参数生成缓存键
+type CacheKeyFunc func(query string, args any) (string, error)
+
+// DefaultCacheKey 默认缓存键：查询名称加参数 JSON 的 SHA-1，参数为 nil 时只使用查询名称
+func DefaultCacheKey(query string, args any) (string, error) {
+	if args == nil {
+		return query, nil
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		return "", err
+	}
+	sum := sha1.Sum(data)
+	return query + ":" + hex.EncodeToString(sum[:]), nil
+}
+
+// 标签版本键的过期时间，过期后旧版本下的结果自然失效
+const queryCacheTagTTL = 24 * time.Hour
+
+// QueryCache 查询结果缓存（cache-aside）。
+// 结果按 CacheKeyFunc 生成的键存入 cache.Cache，序列化沿用缓存自身的 Serializer；
+// 每个结果可关联若干标签，写入后 Invalidate 标签即可让关联结果失效。
+// 失效通过递增标签版本实现（版本参与结果键），因此并发读取在失效前加载的旧结果不会被后续读取命中。
+// 缓存读写失败时直接回源，不影响查询结果。
+type QueryCache struct {
+	cache cache.Cache
+	keyFn CacheKeyFunc
+	ttl   time.Duration
+	seq   atomic.Uint64
+}
+
+// NewQueryCache 创建查询结果缓存，keyFn 为 nil 时使用 DefaultCacheKey
+func NewQueryCache(c cache.Cache, keyFn CacheKeyFunc, ttl time.Duration) *QueryCache {
+	if keyFn == nil {
+		keyFn = DefaultCacheKey
+	}
+	return &QueryCache{cache: c, keyFn: keyFn, ttl: ttl}
+}
+
+// Cache 返回底层缓存
+func (q *QueryCache) Cache() cache.Cache { return q.cache }
+
+// Invalidate 使关联了任一标签的缓存结果失效
+func (q *QueryCache) Invalidate(ctx context.Context, tags ...string) error {
+	var errs []error
+	for _, tag := range tags {
+		if err := q.cache.Set(ctx, tagKey(tag), q.nextVersion(), queryCacheTagTTL); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (q *QueryCache) nextVersion() string {
+	return strconv.FormatInt(time.Now().UnixNano(), 36) + "." + strconv.FormatUint(q.seq.Add(1), 36)
+}
+
+// resultKey 生成包含标签版本的结果键，标签版本不存在时初始化
+func (q *QueryCache) resultKey(ctx context.Context, query string, args any, tags []string) (string, error) {
+	key, err := q.keyFn(query, args)
+	if err != nil {
+		return "", err
+	}
+	if len(tags) == 0 {
+		return "query:" + key, nil
+	}
+	var b strings.Builder
+	b.WriteString("query:")
+	b.WriteString(key)
+	for _, tag := range tags {
+		var version string
+		if err := q.cache.Get(ctx, tagKey(tag), &version); err != nil {
+			if !errors.Is(err, cache.ErrNotFound) {
+				return "", err
+			}
+			version = q.nextVersion()
+			if err := q.cache.Set(ctx, tagKey(tag), version, queryCacheTagTTL); err != nil {
+				return "", err
+			}
+		}
+		b.WriteString("@")
+		b.WriteString(version)
+	}
+	return b.String(), nil
+}
+
+func tagKey(tag string) string { return "query-tag:" + tag }
+
+type queryCacheBypassKey struct{}
+
+// BypassQueryCache 标记 context 内的查询跳过缓存直接回源（结果也不写入缓存），
+// 用于事务内读取或需要强一致的流程
+func BypassQueryCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, queryCacheBypassKey{}, true)
+}
+
+// QueryCacheBypassed 判断 context 是否跳过查询缓存
+func QueryCacheBypassed(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	v, _ := ctx.Value(queryCacheBypassKey{}).(bool)
+	return v
+}
+
+// Cached 读取缓存的查询结果，未命中时调用 load 并写入缓存；load 返回错误时不缓存。
+// q 为 nil 或 context 标记 BypassQueryCache 时直接调用 load
+//
+//	orders, err := storage.Cached(ctx, qc, "order.list_by_user", userID, []string{"orders"},
+//		func(ctx context.Context) ([]Order, error) {
+//			return repo.ListByUser(ctx, userID)
+//		})
+//	// 写入后
+//	_ = qc.Invalidate(ctx, "orders")
+func Cached[T any](ctx context.Context, q *QueryCache, query string, args any, tags []string, load func(ctx context.Context) (T, error)) (T, error) {
+	if q == nil || QueryCacheBypassed(ctx) {
+		return load(ctx)
+	}
+	key, err := q.resultKey(ctx, query, args, tags)
+	if err != nil {
+		return load(ctx)
+	}
+	var out T
+	if err := q.cache.Get(ctx, key, &out); err == nil {
+		return out, nil
+	}
+	out, err = load(ctx)
+	if err != nil {
+		return out, err
+	}
+	_ = q.cache.Set(ctx, key, out, q.ttl)
+	return out, nil
+}
+
+// SetQueryCache 设置查询结果缓存，通过 Builder.WithCache 构建时自动设置
+func (b *Base) SetQueryCache(q *QueryCache) {
+	b.queryCache.Store(q)
+}
+
+// QueryCache 返回查询结果缓存，未设置时返回 nil
+func (b *Base) QueryCache() *QueryCache {
+	return b.queryCache.Load()
+}
+
+// QueryCacheOf 返回存储（可为装饰后的存储）的查询结果缓存，未设置时返回 nil
+func QueryCacheOf(s Storage) *QueryCache {
+	if qp, ok := Unwrap(s).(interface{ QueryCache() *QueryCache }); ok {
+		return qp.QueryCache()
+	}
+	return nil
+}
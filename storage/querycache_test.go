@@ -0,0 +1,211 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mildsunup/higo/cache"
+	"github.com/mildsunup/higo/ddd"
+)
+
+type mapCache struct {
+	mu    sync.Mutex
+	items map[string][]byte
+}
+
+func newMapCache() *mapCache { return &mapCache{items: map[string][]byte{}} }
+
+func (c *mapCache) Get(_ context.Context, key string, dest any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, ok := c.items[key]
+	if !ok {
+		return cache.ErrNotFound
+	}
+	return json.Unmarshal(data, dest)
+}
+
+func (c *mapCache) Set(_ context.Context, key string, value any, _ time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items[key] = data
+	return nil
+}
+
+func (c *mapCache) Delete(_ context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, k := range keys {
+		delete(c.items, k)
+	}
+	return nil
+}
+
+func (c *mapCache) Exists(_ context.Context, key string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.items[key]
+	return ok, nil
+}
+
+func (c *mapCache) Close() error { return nil }
+
+func TestCached_HitAndInvalidate(t *testing.T) {
+	ctx := context.Background()
+	qc := NewQueryCache(newMapCache(), nil, time.Minute)
+	loads := 0
+	load := func(ctx context.Context) ([]string, error) {
+		loads++
+		return []string{"a", "b"}, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		got, err := Cached(ctx, qc, "list", map[string]int{"page": 1}, []string{"orders"}, load)
+		if err != nil || len(got) != 2 {
+			t.Fatalf("Cached = %v, %v", got, err)
+		}
+	}
+	if loads != 1 {
+		t.Fatalf("loads = %d, want 1", loads)
+	}
+
+	// 不同参数使用不同的键
+	_, _ = Cached(ctx, qc, "list", map[string]int{"page": 2}, []string{"orders"}, load)
+	if loads != 2 {
+		t.Fatalf("loads = %d, want 2", loads)
+	}
+
+	if err := qc.Invalidate(ctx, "orders"); err != nil {
+		t.Fatal(err)
+	}
+	_, _ = Cached(ctx, qc, "list", map[string]int{"page": 1}, []string{"orders"}, load)
+	if loads != 3 {
+		t.Fatalf("loads after invalidate = %d, want 3", loads)
+	}
+
+	// 其他标签不受影响
+	_ = qc.Invalidate(ctx, "users")
+	_, _ = Cached(ctx, qc, "list", map[string]int{"page": 1}, []string{"orders"}, load)
+	if loads != 3 {
+		t.Fatalf("loads after unrelated invalidate = %d, want 3", loads)
+	}
+}
+
+func TestCached_ErrorsAndBypass(t *testing.T) {
+	ctx := context.Background()
+	qc := NewQueryCache(newMapCache(), nil, time.Minute)
+	boom := errors.New("boom")
+	loads := 0
+	failing := func(ctx context.Context) (int, error) {
+		loads++
+		return 0, boom
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := Cached(ctx, qc, "n", nil, nil, failing); !errors.Is(err, boom) {
+			t.Fatalf("err = %v", err)
+		}
+	}
+	if loads != 2 {
+		t.Fatalf("errors must not be cached, loads = %d", loads)
+	}
+
+	ok := func(ctx context.Context) (int, error) {
+		loads++
+		return 7, nil
+	}
+	bypass := BypassQueryCache(ctx)
+	_, _ = Cached(bypass, qc, "m", nil, nil, ok)
+	_, _ = Cached(bypass, qc, "m", nil, nil, ok)
+	if loads != 4 {
+		t.Fatalf("bypass loads = %d, want 4", loads)
+	}
+	if v, _ := Cached(ctx, (*QueryCache)(nil), "m", nil, nil, ok); v != 7 {
+		t.Fatalf("nil cache = %d", v)
+	}
+}
+
+type cachedEntity struct {
+	ID   ddd.Int64ID `json:"id"`
+	Name string      `json:"name"`
+}
+
+func (e *cachedEntity) GetID() ddd.Int64ID { return e.ID }
+
+type memRepo struct {
+	items map[ddd.Int64ID]cachedEntity
+	finds int
+}
+
+func (r *memRepo) FindByID(_ context.Context, id ddd.Int64ID) (*cachedEntity, error) {
+	r.finds++
+	e, ok := r.items[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return &e, nil
+}
+
+func (r *memRepo) Save(_ context.Context, e *cachedEntity) error {
+	r.items[e.ID] = *e
+	return nil
+}
+
+func (r *memRepo) Delete(_ context.Context, id ddd.Int64ID) error {
+	delete(r.items, id)
+	return nil
+}
+
+func (r *memRepo) Exists(_ context.Context, id ddd.Int64ID) (bool, error) {
+	_, ok := r.items[id]
+	return ok, nil
+}
+
+func TestCachedRepository(t *testing.T) {
+	ctx := context.Background()
+	inner := &memRepo{items: map[ddd.Int64ID]cachedEntity{1: {ID: 1, Name: "a"}, 2: {ID: 2, Name: "b"}}}
+	repo := NewCachedRepository[cachedEntity, ddd.Int64ID](inner, NewQueryCache(newMapCache(), nil, time.Minute), "items")
+
+	_, _ = repo.FindByID(ctx, 1)
+	_, _ = repo.FindByID(ctx, 2)
+	e, err := repo.FindByID(ctx, 1)
+	if err != nil || e.Name != "a" || inner.finds != 2 {
+		t.Fatalf("FindByID = %+v, %v, finds = %d", e, err, inner.finds)
+	}
+
+	if err := repo.Save(ctx, &cachedEntity{ID: 1, Name: "a2"}); err != nil {
+		t.Fatal(err)
+	}
+	e, _ = repo.FindByID(ctx, 1)
+	if e.Name != "a2" || inner.finds != 3 {
+		t.Fatalf("after save = %+v, finds = %d", e, inner.finds)
+	}
+	// 其他实体仍命中缓存
+	_, _ = repo.FindByID(ctx, 2)
+	if inner.finds != 3 {
+		t.Fatalf("unrelated entity reloaded, finds = %d", inner.finds)
+	}
+
+	_ = repo.Delete(ctx, 2)
+	if _, err := repo.FindByID(ctx, 2); err == nil {
+		t.Fatal("expected not found after delete")
+	}
+}
+
+func TestBuilder_WithCache(t *testing.T) {
+	mock := newMockStorage()
+	s := NewBuilder(mock).
+		WithReconnect(DefaultReconnectConfig()).
+		WithCache(newMapCache(), nil, time.Minute).
+		Build()
+	if QueryCacheOf(s) == nil {
+		t.Fatal("expected query cache on built storage")
+	}
+}
@@ -0,0 +1,32 @@
+// Package rawsql 提供命名原生 SQL 仓储，用于 GORM 查询构造器表达力或性能不足的报表等场景。
+//
+// 查询写在 .sql 文件中，通过 embed 打包并在启动时加载，以 "-- name: xxx" 分隔：
+//
+//	-- name: report.daily_orders
+//	SELECT date(created_at) AS day, count(*) AS orders
+//	FROM orders
+//	WHERE created_at >= :from AND status IN (:statuses)
+//	GROUP BY day
+//
+// 参数使用 :name 命名占位，绑定时转换为驱动占位符（? 或 $n），切片参数展开为 IN 列表；
+// 字符串、注释与 PostgreSQL 的 :: 类型转换不会被识别为参数。
+//
+// 每次执行按查询名称记录 storage_query_* 指标并生成追踪 span，结果通过泛型扫描为结构体或标量。
+//
+// 使用示例：
+//
+//	//go:embed queries
+//	var queryFS embed.FS
+//
+//	queries, err := rawsql.Load(queryFS, "queries")
+//	repo := rawsql.New(sqlDB, queries,
+//	    rawsql.WithMetrics(metrics, "main", storage.TypeMySQL),
+//	    rawsql.WithTracer(tracer),
+//	)
+//	rows, err := rawsql.Query[DailyOrders](ctx, repo, "report.daily_orders", rawsql.Args{
+//	    "from":     from,
+//	    "statuses": []string{"paid", "shipped"},
+//	})
+//
+// 在事务中执行时使用 repo.WithConn(tx)，或通过 WithConnResolver 从 context 中取出事务连接。
+package rawsql
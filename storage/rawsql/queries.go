@@ -0,0 +1,292 @@
+package rawsql
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrQueryNotFound 查询未定义
+var ErrQueryNotFound = errors.New("rawsql: query not found")
+
+// Placeholder 占位符风格
+type Placeholder int
+
+const (
+	Question Placeholder = iota // ?（MySQL、SQLite、ClickHouse）
+	Dollar                      // $1, $2（PostgreSQL）
+)
+
+// Statement 解析后的命名查询语句
+type Statement struct {
+	Name   string
+	SQL    string   // 原始 SQL（含 :param）
+	params []string // 按出现顺序的参数名
+	parts  []string // 参数之间的 SQL 片段，len(parts) == len(params)+1
+}
+
+// Params 返回参数名（按首次出现顺序去重）
+func (q *Statement) Params() []string {
+	var out []string
+	for _, p := range q.params {
+		if !slices.Contains(out, p) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// Queries 命名查询集合
+type Queries struct {
+	queries map[string]*Statement
+}
+
+// Load 从 fs.FS 加载 dir 下（递归）全部 .sql 文件。
+// 文件中以 "-- name: xxx" 行分隔多个查询；没有 name 注释的文件整体为一个查询，名称为去掉扩展名的相对路径。
+// 名称重复时返回错误
+//
+//	-- name: report.daily_orders
+//	SELECT date(created_at) AS day, count(*) AS orders
+//	FROM orders
+//	WHERE created_at >= :from AND status IN (:statuses)
+//	GROUP BY day
+func Load(fsys fs.FS, dir string) (*Queries, error) {
+	qs := &Queries{queries: make(map[string]*Statement)}
+	err := fs.WalkDir(fsys, dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || path.Ext(p) != ".sql" {
+			return err
+		}
+		b, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		rel := strings.TrimPrefix(strings.TrimPrefix(p, dir), "/")
+		return qs.parseFile(strings.TrimSuffix(rel, ".sql"), string(b))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("rawsql: load %s: %w", dir, err)
+	}
+	return qs, nil
+}
+
+// Parse 从字符串解析查询，格式同 Load，defaultName 用于没有 name 注释的内容
+func Parse(defaultName, content string) (*Queries, error) {
+	qs := &Queries{queries: make(map[string]*Statement)}
+	if err := qs.parseFile(defaultName, content); err != nil {
+		return nil, err
+	}
+	return qs, nil
+}
+
+func (qs *Queries) parseFile(defaultName, content string) error {
+	name := defaultName
+	var body strings.Builder
+	flush := func() error {
+		sql := strings.TrimSpace(body.String())
+		body.Reset()
+		if sql == "" {
+			return nil
+		}
+		return qs.add(name, sql)
+	}
+
+	sc := bufio.NewScanner(strings.NewReader(content))
+	sc.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	for sc.Scan() {
+		line := sc.Text()
+		if n, ok := nameDirective(line); ok {
+			if err := flush(); err != nil {
+				return err
+			}
+			name = n
+			continue
+		}
+		body.WriteString(line)
+		body.WriteByte('\n')
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	return flush()
+}
+
+func nameDirective(line string) (string, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(line), "--")
+	if !ok {
+		return "", false
+	}
+	rest, ok = strings.CutPrefix(strings.TrimSpace(rest), "name:")
+	if !ok {
+		return "", false
+	}
+	return strings.TrimSpace(rest), true
+}
+
+func (qs *Queries) add(name, sql string) error {
+	if _, exists := qs.queries[name]; exists {
+		return fmt.Errorf("rawsql: duplicate query %q", name)
+	}
+	q := &Statement{Name: name, SQL: sql}
+	q.parts, q.params = parseParams(sql)
+	qs.queries[name] = q
+	return nil
+}
+
+// Get 获取查询
+func (qs *Queries) Get(name string) (*Statement, error) {
+	q, ok := qs.queries[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrQueryNotFound, name)
+	}
+	return q, nil
+}
+
+// Names 返回全部查询名称（排序）
+func (qs *Queries) Names() []string {
+	names := make([]string, 0, len(qs.queries))
+	for name := range qs.queries {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// parseParams 拆分 :name 参数，跳过字符串、引用标识符、注释与 :: 类型转换
+func parseParams(sql string) (parts, params []string) {
+	var cur strings.Builder
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := i + 1
+			for end < len(sql) && sql[end] != c {
+				if sql[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end, len(sql)-1)
+			cur.WriteString(sql[i : end+1])
+			i = end
+		case c == '-' && i+1 < len(sql) && sql[i+1] == '-':
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				end = len(sql) - i
+			}
+			cur.WriteString(sql[i : i+end])
+			i += end - 1
+		case c == '/' && i+1 < len(sql) && sql[i+1] == '*':
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				end = len(sql) - i - 2
+			} else {
+				end += 2
+			}
+			cur.WriteString(sql[i : i+2+end])
+			i += 1 + end
+		case c == ':' && i+1 < len(sql) && sql[i+1] == ':':
+			cur.WriteString("::")
+			i++
+		case c == ':' && i+1 < len(sql) && isIdentStart(sql[i+1]):
+			end := i + 1
+			for end < len(sql) && isIdent(sql[end]) {
+				end++
+			}
+			parts = append(parts, cur.String())
+			cur.Reset()
+			params = append(params, sql[i+1:end])
+			i = end - 1
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	parts = append(parts, cur.String())
+	return parts, params
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isIdent(c byte) bool {
+	return isIdentStart(c) || c >= '0' && c <= '9'
+}
+
+// Bind 将命名参数绑定为占位符 SQL 与位置参数。
+// args 为 map[string]any 或带 db 标签的结构体；切片（[]byte 除外）展开为多个占位符，用于 IN 列表
+func (q *Statement) Bind(style Placeholder, args any) (string, []any, error) {
+	lookup, err := argLookup(args)
+	if err != nil {
+		return "", nil, fmt.Errorf("rawsql: %s: %w", q.Name, err)
+	}
+	var (
+		sb   strings.Builder
+		vals []any
+	)
+	sb.Grow(len(q.SQL))
+	placeholder := func() {
+		if style == Dollar {
+			sb.WriteString("$" + strconv.Itoa(len(vals)))
+		} else {
+			sb.WriteByte('?')
+		}
+	}
+	for i, name := range q.params {
+		sb.WriteString(q.parts[i])
+		v, ok := lookup(name)
+		if !ok {
+			return "", nil, fmt.Errorf("rawsql: %s: missing parameter :%s", q.Name, name)
+		}
+		rv := reflect.ValueOf(v)
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() != reflect.Uint8 {
+			if rv.Len() == 0 {
+				return "", nil, fmt.Errorf("rawsql: %s: empty list for parameter :%s", q.Name, name)
+			}
+			for j := range rv.Len() {
+				if j > 0 {
+					sb.WriteString(", ")
+				}
+				vals = append(vals, rv.Index(j).Interface())
+				placeholder()
+			}
+			continue
+		}
+		vals = append(vals, v)
+		placeholder()
+	}
+	sb.WriteString(q.parts[len(q.params)])
+	return sb.String(), vals, nil
+}
+
+// Args 命名参数
+type Args = map[string]any
+
+func argLookup(args any) (func(string) (any, bool), error) {
+	switch a := args.(type) {
+	case nil:
+		return func(string) (any, bool) { return nil, false }, nil
+	case map[string]any:
+		return func(name string) (any, bool) { v, ok := a[name]; return v, ok }, nil
+	}
+	rv := reflect.ValueOf(args)
+	for rv.Kind() == reflect.Pointer {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("unsupported args type %T", args)
+	}
+	fields := fieldIndex(rv.Type())
+	return func(name string) (any, bool) {
+		idx, ok := fields[normalize(name)]
+		if !ok {
+			return nil, false
+		}
+		return rv.FieldByIndex(idx).Interface(), true
+	}, nil
+}
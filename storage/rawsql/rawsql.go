@@ -0,0 +1,199 @@
+package rawsql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/mildsunup/higo/storage"
+)
+
+// Conn 执行 SQL 的连接，*sql.DB、*sql.Tx、*sql.Conn 均满足
+type Conn interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+var (
+	_ Conn = (*sql.DB)(nil)
+	_ Conn = (*sql.Tx)(nil)
+	_ Conn = (*sql.Conn)(nil)
+)
+
+// Option 仓储选项
+type Option func(*Repository)
+
+// WithPlaceholder 设置占位符风格，默认 Question
+func WithPlaceholder(p Placeholder) Option {
+	return func(r *Repository) { r.style = p }
+}
+
+// WithMetrics 按查询名称记录 storage_query_* 指标，name、typ 为指标的存储名称与类型标签
+func WithMetrics(m *storage.Metrics, name string, typ storage.Type) Option {
+	return func(r *Repository) {
+		r.metrics = m
+		r.storage = name
+		r.typ = typ
+	}
+}
+
+// WithTracer 设置链路追踪，每次执行生成一个 "rawsql.<query>" 客户端 span
+func WithTracer(tracer trace.Tracer) Option {
+	return func(r *Repository) { r.tracer = tracer }
+}
+
+// WithConnResolver 按 context 选择连接（如工作单元中的事务），返回 false 时使用默认连接
+func WithConnResolver(fn func(ctx context.Context) (Conn, bool)) Option {
+	return func(r *Repository) { r.resolve = fn }
+}
+
+// Repository 命名原生 SQL 仓储，用于 ORM 查询构造器表达力或性能不足的报表等场景
+type Repository struct {
+	conn    Conn
+	queries *Queries
+	style   Placeholder
+	resolve func(ctx context.Context) (Conn, bool)
+
+	metrics *storage.Metrics
+	storage string
+	typ     storage.Type
+	tracer  trace.Tracer
+}
+
+// New 创建命名原生 SQL 仓储
+func New(conn Conn, queries *Queries, opts ...Option) *Repository {
+	r := &Repository{
+		conn:    conn,
+		queries: queries,
+		tracer:  noop.NewTracerProvider().Tracer(""),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// WithConn 返回使用指定连接（如 *sql.Tx）的副本，查询、指标与追踪配置不变
+func (r *Repository) WithConn(conn Conn) *Repository {
+	cp := *r
+	cp.conn = conn
+	cp.resolve = nil
+	return &cp
+}
+
+// Queries 返回查询集合
+func (r *Repository) Queries() *Queries { return r.queries }
+
+// Exec 执行命名写入语句
+func (r *Repository) Exec(ctx context.Context, name string, args any) (sql.Result, error) {
+	var res sql.Result
+	err := r.run(ctx, name, storage.OpExec, args, func(ctx context.Context, conn Conn, query string, vals []any) error {
+		var err error
+		res, err = conn.ExecContext(ctx, query, vals...)
+		return err
+	})
+	return res, err
+}
+
+// Rows 执行命名查询并返回原始结果集，由调用方关闭；耗时只统计到查询返回
+func (r *Repository) Rows(ctx context.Context, name string, args any) (*sql.Rows, error) {
+	var rows *sql.Rows
+	err := r.run(ctx, name, storage.OpQuery, args, func(ctx context.Context, conn Conn, query string, vals []any) error {
+		var err error
+		rows, err = conn.QueryContext(ctx, query, vals...)
+		return err
+	})
+	return rows, err
+}
+
+// Query 执行命名查询并扫描为 []T。
+// T 为结构体时按列名映射字段（db 标签优先，否则忽略大小写与下划线匹配字段名）；
+// T 为标量、time.Time 或 sql.Scanner 时要求结果只有一列
+//
+//	type DailyOrders struct {
+//		Day    time.Time `db:"day"`
+//		Orders int64     `db:"orders"`
+//	}
+//	rows, err := rawsql.Query[DailyOrders](ctx, repo, "report.daily_orders", rawsql.Args{
+//		"from":     from,
+//		"statuses": []string{"paid", "shipped"},
+//	})
+func Query[T any](ctx context.Context, r *Repository, name string, args any) ([]T, error) {
+	var out []T
+	err := r.run(ctx, name, storage.OpQuery, args, func(ctx context.Context, conn Conn, query string, vals []any) error {
+		rows, err := conn.QueryContext(ctx, query, vals...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		out, err = scanRows[T](rows)
+		return err
+	})
+	return out, err
+}
+
+// QueryOne 执行命名查询并返回第一行，无结果时返回 sql.ErrNoRows
+func QueryOne[T any](ctx context.Context, r *Repository, name string, args any) (T, error) {
+	rows, err := Query[T](ctx, r, name, args)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	if len(rows) == 0 {
+		var zero T
+		return zero, sql.ErrNoRows
+	}
+	return rows[0], nil
+}
+
+func (r *Repository) run(ctx context.Context, name, op string, args any, fn func(ctx context.Context, conn Conn, query string, vals []any) error) (err error) {
+	q, err := r.queries.Get(name)
+	if err != nil {
+		return err
+	}
+	query, vals, err := q.Bind(r.style, args)
+	if err != nil {
+		return err
+	}
+
+	ctx, span := r.tracer.Start(ctx, "rawsql."+name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("storage.name", r.storage),
+			attribute.String("storage.type", string(r.typ)),
+			attribute.String("db.operation.name", op),
+			attribute.String("db.query.name", name),
+			attribute.String("db.query.text", query),
+		),
+	)
+	start := time.Now()
+	defer func() {
+		// 无结果不视为查询失败
+		failed := err
+		if errors.Is(failed, sql.ErrNoRows) {
+			failed = nil
+		}
+		if failed != nil {
+			span.RecordError(failed)
+			span.SetStatus(codes.Error, failed.Error())
+		}
+		span.End()
+		if r.metrics != nil {
+			r.metrics.ObserveQuery(r.storage, r.typ, op, name, start, failed)
+		}
+	}()
+
+	conn := r.conn
+	if r.resolve != nil {
+		if c, ok := r.resolve(ctx); ok {
+			conn = c
+		}
+	}
+	return fn(storage.WithQueryName(ctx, name), conn, query, vals)
+}
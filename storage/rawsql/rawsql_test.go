@@ -0,0 +1,274 @@
+package rawsql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/mildsunup/higo/observability"
+	"github.com/mildsunup/higo/storage"
+)
+
+// fakeDriver 按 SQL 返回预设结果，并记录收到的语句与参数
+type fakeDriver struct {
+	mu      sync.Mutex
+	queries []string
+	args    [][]any
+	results map[string]fakeResult
+}
+
+type fakeResult struct {
+	cols []string
+	rows [][]driver.Value
+	err  error
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return &fakeConn{d: d}, nil }
+
+func (d *fakeDriver) record(query string, args []driver.NamedValue) fakeResult {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	vals := make([]any, len(args))
+	for i, a := range args {
+		vals[i] = a.Value
+	}
+	d.queries = append(d.queries, query)
+	d.args = append(d.args, vals)
+	return d.results[query]
+}
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	r := c.d.record(query, args)
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &fakeRows{cols: r.cols, rows: r.rows}, nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	r := c.d.record(query, args)
+	if r.err != nil {
+		return nil, r.err
+	}
+	return driver.RowsAffected(int64(len(args))), nil
+}
+
+type fakeRows struct {
+	cols []string
+	rows [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.cols }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+var fake = &fakeDriver{results: make(map[string]fakeResult)}
+
+func init() { sql.Register("higo-fake-rawsql", fake) }
+
+const reportSQL = `
+-- name: report.daily_orders
+SELECT day, orders, total_amount FROM daily
+WHERE day >= :from AND status IN (:statuses) -- :ignored
+  AND note <> ':literal' AND created_at::date = day
+
+-- name: report.count
+SELECT count(*) FROM orders WHERE user_id = :user_id
+
+-- name: report.refresh
+UPDATE daily SET orders = :orders WHERE day = :day
+`
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"queries/report.sql":      {Data: []byte(reportSQL)},
+		"queries/users/stale.sql": {Data: []byte("DELETE FROM users WHERE seen_at < :before")},
+		"queries/README.md":       {Data: []byte("ignored")},
+	}
+	qs, err := Load(fsys, "queries")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"report.count", "report.daily_orders", "report.refresh", "users/stale"}
+	if got := qs.Names(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("names = %v, want %v", got, want)
+	}
+	s, _ := qs.Get("report.daily_orders")
+	if got := s.Params(); !reflect.DeepEqual(got, []string{"from", "statuses"}) {
+		t.Fatalf("params = %v", got)
+	}
+	if _, err := qs.Get("missing"); !errors.Is(err, ErrQueryNotFound) {
+		t.Fatalf("err = %v, want ErrQueryNotFound", err)
+	}
+
+	if _, err := Parse("dup", "-- name: a\nSELECT 1\n-- name: a\nSELECT 2"); err == nil {
+		t.Fatal("expected duplicate query error")
+	}
+}
+
+func TestStatement_Bind(t *testing.T) {
+	qs, _ := Parse("", reportSQL)
+	s, _ := qs.Get("report.daily_orders")
+
+	query, args, err := s.Bind(Dollar, Args{"from": "2024-01-01", "statuses": []string{"paid", "shipped"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "SELECT day, orders, total_amount FROM daily\nWHERE day >= $1 AND status IN ($2, $3) -- :ignored\n  AND note <> ':literal' AND created_at::date = day"
+	if query != want {
+		t.Fatalf("query = %q", query)
+	}
+	if !reflect.DeepEqual(args, []any{"2024-01-01", "paid", "shipped"}) {
+		t.Fatalf("args = %v", args)
+	}
+
+	type countArgs struct {
+		UserID int64 `db:"user_id"`
+	}
+	c, _ := qs.Get("report.count")
+	query, args, err = c.Bind(Question, &countArgs{UserID: 7})
+	if err != nil || query != "SELECT count(*) FROM orders WHERE user_id = ?" || !reflect.DeepEqual(args, []any{int64(7)}) {
+		t.Fatalf("query = %q, args = %v, err = %v", query, args, err)
+	}
+
+	if _, _, err := s.Bind(Question, Args{"from": 1}); err == nil || !strings.Contains(err.Error(), ":statuses") {
+		t.Fatalf("missing parameter err = %v", err)
+	}
+	if _, _, err := s.Bind(Question, Args{"from": 1, "statuses": []string{}}); err == nil {
+		t.Fatal("expected empty list error")
+	}
+}
+
+type dailyOrders struct {
+	Day         string `db:"day"`
+	Orders      int64
+	TotalAmount float64
+	Skipped     string `db:"-"`
+}
+
+func TestRepository(t *testing.T) {
+	db, err := sql.Open("higo-fake-rawsql", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	qs, _ := Parse("", reportSQL)
+	reg := prometheus.NewRegistry()
+	metrics := storage.NewMetrics(observability.NewPrometheusProvider(reg))
+	repo := New(db, qs, WithMetrics(metrics, "report", storage.TypeMySQL))
+	ctx := context.Background()
+
+	daily, _ := qs.Get("report.daily_orders")
+	dailySQL, _, _ := daily.Bind(Question, Args{"from": 0, "statuses": []string{"paid"}})
+	count, _ := qs.Get("report.count")
+	countSQL, _, _ := count.Bind(Question, Args{"user_id": 0})
+	fake.mu.Lock()
+	fake.results[dailySQL] = fakeResult{
+		cols: []string{"day", "orders", "total_amount"},
+		rows: [][]driver.Value{{"2024-01-01", int64(3), 9.5}, {"2024-01-02", int64(1), 2.0}},
+	}
+	fake.results[countSQL] = fakeResult{cols: []string{"count(*)"}, rows: [][]driver.Value{{int64(42)}}}
+	fake.mu.Unlock()
+
+	rows, err := Query[dailyOrders](ctx, repo, "report.daily_orders", Args{"from": "2024-01-01", "statuses": []string{"paid"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0] != (dailyOrders{Day: "2024-01-01", Orders: 3, TotalAmount: 9.5}) || rows[1].Orders != 1 {
+		t.Fatalf("rows = %+v", rows)
+	}
+
+	n, err := QueryOne[int64](ctx, repo, "report.count", Args{"user_id": 7})
+	if err != nil || n != 42 {
+		t.Fatalf("count = %d, err = %v", n, err)
+	}
+
+	res, err := repo.Exec(ctx, "report.refresh", Args{"orders": 3, "day": "2024-01-01"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if affected, _ := res.RowsAffected(); affected != 2 {
+		t.Fatalf("affected = %d", affected)
+	}
+
+	// 列无法映射到字段
+	if _, err := Query[struct{ Day string }](ctx, repo, "report.daily_orders", Args{"from": "x", "statuses": []string{"paid"}}); err == nil {
+		t.Fatal("expected unmapped column error")
+	}
+	if _, err := Query[int64](ctx, repo, "missing", nil); !errors.Is(err, ErrQueryNotFound) {
+		t.Fatalf("err = %v", err)
+	}
+
+	families, _ := reg.Gather()
+	counts := make(map[string]uint64)
+	var errorQueries []string
+	for _, f := range families {
+		for _, m := range f.GetMetric() {
+			var query, op string
+			for _, l := range m.GetLabel() {
+				switch l.GetName() {
+				case "query":
+					query = l.GetValue()
+				case "operation":
+					op = l.GetValue()
+				}
+			}
+			switch f.GetName() {
+			case "storage_query_seconds":
+				counts[op+"/"+query] = m.GetHistogram().GetSampleCount()
+			case "storage_query_errors_total":
+				errorQueries = append(errorQueries, query)
+			}
+		}
+	}
+	if counts["query/report.daily_orders"] != 2 || counts["query/report.count"] != 1 || counts["exec/report.refresh"] != 1 {
+		t.Fatalf("query counts = %v", counts)
+	}
+	if !reflect.DeepEqual(errorQueries, []string{"report.daily_orders"}) {
+		t.Fatalf("error queries = %v", errorQueries)
+	}
+}
+
+func TestRepository_WithConn(t *testing.T) {
+	db, _ := sql.Open("higo-fake-rawsql", "")
+	defer db.Close()
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	qs, _ := Parse("empty", "SELECT id FROM empty WHERE id = :id")
+	fake.mu.Lock()
+	fake.results["SELECT id FROM empty WHERE id = ?"] = fakeResult{cols: []string{"id"}}
+	fake.mu.Unlock()
+
+	repo := New(db, qs).WithConn(conn)
+	if _, err := QueryOne[int64](context.Background(), repo, "empty", Args{"id": 1}); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("err = %v, want sql.ErrNoRows", err)
+	}
+}
@@ -0,0 +1,106 @@
+package rawsql
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	scannerType = reflect.TypeFor[sql.Scanner]()
+	timeType    = reflect.TypeFor[time.Time]()
+	fieldCache  sync.Map // reflect.Type -> map[string][]int
+)
+
+// isScalar T 按单列扫描：非结构体、time.Time 或实现 sql.Scanner 的类型
+func isScalar(t reflect.Type) bool {
+	return t.Kind() != reflect.Struct || t == timeType || reflect.PointerTo(t).Implements(scannerType)
+}
+
+// fieldIndex 返回列名到字段索引的映射（含嵌入结构体）。
+// 优先使用 db 标签，"-" 忽略；否则按字段名匹配（忽略大小写与下划线）
+func fieldIndex(t reflect.Type) map[string][]int {
+	if v, ok := fieldCache.Load(t); ok {
+		return v.(map[string][]int)
+	}
+	fields := make(map[string][]int)
+	var walk func(t reflect.Type, prefix []int)
+	walk = func(t reflect.Type, prefix []int) {
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			tag := f.Tag.Get("db")
+			if tag == "-" {
+				continue
+			}
+			idx := append(append([]int(nil), prefix...), i)
+			if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct && !isScalar(f.Type) {
+				walk(f.Type, idx)
+				continue
+			}
+			name := tag
+			if name == "" {
+				name = f.Name
+			}
+			if _, exists := fields[normalize(name)]; !exists {
+				fields[normalize(name)] = idx
+			}
+		}
+	}
+	walk(t, nil)
+	fieldCache.Store(t, fields)
+	return fields
+}
+
+func normalize(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
+
+// scanRows 将结果集扫描为 []T，结构体按列名映射字段，结果中存在无法映射的列时返回错误
+func scanRows[T any](rows *sql.Rows) ([]T, error) {
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	t := reflect.TypeFor[T]()
+	scalar := isScalar(t)
+	if scalar && len(cols) != 1 {
+		return nil, fmt.Errorf("scan %s: expected 1 column, got %d", t, len(cols))
+	}
+	var indexes [][]int
+	if !scalar {
+		fields := fieldIndex(t)
+		indexes = make([][]int, len(cols))
+		for i, col := range cols {
+			idx, ok := fields[normalize(col)]
+			if !ok {
+				return nil, fmt.Errorf("scan %s: no field for column %q", t, col)
+			}
+			indexes[i] = idx
+		}
+	}
+
+	var out []T
+	dest := make([]any, len(cols))
+	for rows.Next() {
+		var v T
+		if scalar {
+			dest[0] = &v
+		} else {
+			rv := reflect.ValueOf(&v).Elem()
+			for i, idx := range indexes {
+				dest[i] = rv.FieldByIndex(idx).Addr().Interface()
+			}
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,47 @@
+package storage
+
+import "context"
+
+// ReadPreference 读取偏好，配置只读副本时决定读请求发往主库还是副本
+type ReadPreference int
+
+const (
+	ReadDefault ReadPreference = iota // 由存储自行决定（读副本、写主库）
+	ReadPrimary                       // 强制读主库，避免副本延迟
+	ReadReplica                       // 强制读副本（写操作与事务仍在主库）
+)
+
+func (p ReadPreference) String() string {
+	switch p {
+	case ReadPrimary:
+		return "primary"
+	case ReadReplica:
+		return "replica"
+	}
+	return "default"
+}
+
+type readPreferenceKey struct{}
+
+// UsePrimary 标记 context 内的读取走主库，用于写后立即读取（read-your-own-write）的流程
+//
+//	if err := repo.Create(ctx, order); err != nil { ... }
+//	ctx = storage.UsePrimary(ctx)
+//	order, err = repo.FindByID(ctx, order.ID) // 不受副本延迟影响
+func UsePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readPreferenceKey{}, ReadPrimary)
+}
+
+// UseReplica 标记 context 内的读取走副本，覆盖外层的 UsePrimary（如报表等可容忍延迟的子流程）
+func UseReplica(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readPreferenceKey{}, ReadReplica)
+}
+
+// ReadPreferenceFrom 获取 context 中的读取偏好，未设置时为 ReadDefault
+func ReadPreferenceFrom(ctx context.Context) ReadPreference {
+	if ctx == nil {
+		return ReadDefault
+	}
+	p, _ := ctx.Value(readPreferenceKey{}).(ReadPreference)
+	return p
+}
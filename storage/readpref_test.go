@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"context"
+	"testing"
+)
+
+func TestReadPreference(t *testing.T) {
+	ctx := context.Background()
+	if p := ReadPreferenceFrom(ctx); p != ReadDefault {
+		t.Fatalf("default = %v", p)
+	}
+	primary := UsePrimary(ctx)
+	if p := ReadPreferenceFrom(primary); p != ReadPrimary || p.String() != "primary" {
+		t.Fatalf("UsePrimary = %v", p)
+	}
+	if p := ReadPreferenceFrom(UseReplica(primary)); p != ReadReplica {
+		t.Fatalf("UseReplica over UsePrimary = %v", p)
+	}
+}
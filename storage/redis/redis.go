@@ -3,6 +3,7 @@ package redis
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/extra/redisotel/v9"
@@ -120,6 +121,23 @@ func (s *Storage) Close(ctx context.Context) error {
 	return err
 }
 
+// ServerVersion 返回 Redis 服务端版本
+func (s *Storage) ServerVersion(ctx context.Context) (string, error) {
+	if s.client == nil {
+		return "", fmt.Errorf("redis: not connected")
+	}
+	info, err := s.client.Info(ctx, "server").Result()
+	if err != nil {
+		return "", fmt.Errorf("redis: info server: %w", err)
+	}
+	for _, line := range strings.Split(info, "\n") {
+		if v, ok := strings.CutPrefix(strings.TrimSpace(line), "redis_version:"); ok {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("redis: version not found in info")
+}
+
 // Client 返回 Redis 客户端
 func (s *Storage) Client() *redis.Client { return s.client }
 
@@ -138,7 +156,12 @@ func (s *Storage) Stats() storage.Stats {
 	}
 }
 
+// Snapshot 返回连接池统计快照
+func (s *Storage) Snapshot() storage.StatsSnapshot { return s.RecordStats(s.Stats()) }
+
 var (
-	_ storage.Storage       = (*Storage)(nil)
-	_ storage.StatsProvider = (*Storage)(nil)
+	_ storage.Storage          = (*Storage)(nil)
+	_ storage.StatsProvider    = (*Storage)(nil)
+	_ storage.VersionProvider  = (*Storage)(nil)
+	_ storage.SnapshotProvider = (*Storage)(nil)
 )
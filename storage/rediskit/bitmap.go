@@ -0,0 +1,104 @@
+package rediskit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// DailyActive 基于 Bitmap 的日活标记，offset 为用户数字 ID
+// key 形如 "{<prefix>}20060102"，所有日期共用前缀哈希标签，CountRange 的 BITOP 在 Redis Cluster 下落在同一槽；
+// 前缀已含哈希标签时原样使用。
+type DailyActive struct {
+	client redis.Cmdable
+	prefix string
+	ttl    time.Duration
+}
+
+// NewDailyActive 创建日活标记，ttl 为 0 表示不过期
+func NewDailyActive(client redis.Cmdable, prefix string, ttl time.Duration) *DailyActive {
+	if prefix == "" {
+		prefix = "dau:"
+	}
+	return &DailyActive{client: client, prefix: hashTag(prefix), ttl: ttl}
+}
+
+// Mark 标记用户在某天活跃
+func (d *DailyActive) Mark(ctx context.Context, day time.Time, userID int64) error {
+	k := d.key(day)
+	_, err := d.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SetBit(ctx, k, userID, 1)
+		if d.ttl > 0 {
+			pipe.Expire(ctx, k, d.ttl)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("rediskit: setbit: %w", err)
+	}
+	return nil
+}
+
+// IsActive 用户在某天是否活跃
+func (d *DailyActive) IsActive(ctx context.Context, day time.Time, userID int64) (bool, error) {
+	bit, err := d.client.GetBit(ctx, d.key(day), userID).Result()
+	if err != nil {
+		return false, fmt.Errorf("rediskit: getbit: %w", err)
+	}
+	return bit == 1, nil
+}
+
+// Count 统计某天活跃用户数
+func (d *DailyActive) Count(ctx context.Context, day time.Time) (int64, error) {
+	n, err := d.client.BitCount(ctx, d.key(day), nil).Result()
+	if err != nil {
+		return 0, fmt.Errorf("rediskit: bitcount: %w", err)
+	}
+	return n, nil
+}
+
+// CountRange 统计 [from, to] 期间活跃过的用户数
+func (d *DailyActive) CountRange(ctx context.Context, from, to time.Time) (int64, error) {
+	var keys []string
+	for day := truncateDay(from); !day.After(truncateDay(to)); day = day.AddDate(0, 0, 1) {
+		keys = append(keys, d.key(day))
+	}
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	tmp := d.prefix + "tmp:" + uuid.NewString()
+	defer d.client.Del(context.WithoutCancel(ctx), tmp)
+
+	if err := d.client.BitOpOr(ctx, tmp, keys...).Err(); err != nil {
+		return 0, fmt.Errorf("rediskit: bitop: %w", err)
+	}
+	n, err := d.client.BitCount(ctx, tmp, nil).Result()
+	if err != nil {
+		return 0, fmt.Errorf("rediskit: bitcount: %w", err)
+	}
+	return n, nil
+}
+
+func (d *DailyActive) key(day time.Time) string {
+	return d.prefix + day.Format("20060102")
+}
+
+// hashTag 用 {} 包裹 s 作为 Redis Cluster 哈希标签，已含非空哈希标签时原样返回
+func hashTag(s string) string {
+	if i := strings.IndexByte(s, '{'); i >= 0 {
+		if j := strings.IndexByte(s[i+1:], '}'); j > 0 {
+			return s
+		}
+	}
+	return "{" + s + "}"
+}
+
+func truncateDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
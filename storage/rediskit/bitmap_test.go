@@ -0,0 +1,53 @@
+package rediskit
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDailyActive_CountRange(t *testing.T) {
+	ctx := context.Background()
+	mr, client := newRedis(t)
+	d := NewDailyActive(client, "", 0)
+	day := time.Date(2024, 3, 1, 15, 0, 0, 0, time.UTC)
+
+	for i, users := range [][]int64{{1, 2}, {2, 3}, {9}} {
+		for _, u := range users {
+			if err := d.Mark(ctx, day.AddDate(0, 0, i), u); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if ok, _ := d.IsActive(ctx, day, 2); !ok {
+		t.Fatal("user 2 not active")
+	}
+	if n, _ := d.Count(ctx, day.AddDate(0, 0, 1)); n != 2 {
+		t.Fatalf("count = %d", n)
+	}
+	if n, err := d.CountRange(ctx, day, day.AddDate(0, 0, 1)); err != nil || n != 3 {
+		t.Fatalf("count range = %d, %v", n, err)
+	}
+	if n, err := d.CountRange(ctx, day.AddDate(0, 0, 1), day); err != nil || n != 0 {
+		t.Fatalf("empty range = %d, %v", n, err)
+	}
+
+	// 所有日期共用哈希标签，BITOP 在 Redis Cluster 下不跨槽；临时 key 已删除
+	want := []string{"{dau:}20240301", "{dau:}20240302", "{dau:}20240303"}
+	if keys := mr.Keys(); !slices.Equal(keys, want) {
+		t.Fatalf("keys = %v", keys)
+	}
+}
+
+func TestHashTag(t *testing.T) {
+	for in, want := range map[string]string{
+		"dau:":        "{dau:}",
+		"app:{dau}:":  "app:{dau}:",
+		"app:{}:dau:": "{app:{}:dau:}",
+	} {
+		if got := hashTag(in); got != want {
+			t.Errorf("hashTag(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package rediskit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return mr, client
+}
+
+func TestCounter_TTLFromMaxSpan(t *testing.T) {
+	ctx := context.Background()
+	mr, client := newRedis(t)
+
+	for _, tt := range []struct {
+		opts []CounterOption
+		want time.Duration
+	}{
+		{nil, 3 * time.Minute},
+		{[]CounterOption{WithMaxSpan(60)}, 61 * time.Minute},
+		{[]CounterOption{WithMaxSpan(0)}, 2 * time.Minute},
+	} {
+		mr.FlushAll()
+		c := NewCounter(client, "c:", tt.opts...)
+		if _, err := c.Incr(ctx, "k", time.Minute); err != nil {
+			t.Fatal(err)
+		}
+		key := c.windowKey("k", time.Minute, time.Now())
+		if ttl := mr.TTL(key); ttl != tt.want {
+			t.Errorf("ttl = %v, want %v", ttl, tt.want)
+		}
+	}
+}
+
+func TestCounter_Sum(t *testing.T) {
+	ctx := context.Background()
+	mr, client := newRedis(t)
+	const window = 50 * time.Millisecond
+	c := NewCounter(client, "c:", WithMaxSpan(3))
+
+	if _, err := c.IncrBy(ctx, "k", 2, window); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(window)
+	if n, err := c.Incr(ctx, "k", window); err != nil || n != 1 {
+		t.Fatalf("incr in new window = %d, %v", n, err)
+	}
+	if n, _ := c.Get(ctx, "k", window); n != 1 {
+		t.Fatalf("current window = %d", n)
+	}
+	if n, err := c.Sum(ctx, "k", window, 3); err != nil || n != 3 {
+		t.Fatalf("sum = %d, %v", n, err)
+	}
+	if _, err := c.Sum(ctx, "k", window, 4); !errors.Is(err, ErrSpanTooLarge) {
+		t.Fatalf("sum beyond max span = %v", err)
+	}
+
+	// 各窗口共用哈希标签，Sum 的 MGET 在 Redis Cluster 下不跨槽
+	if keys := mr.Keys(); len(keys) != 2 || !strings.HasPrefix(keys[0], "c:{k}:") || !strings.HasPrefix(keys[1], "c:{k}:") {
+		t.Fatalf("window keys = %v", keys)
+	}
+
+	// 窗口 key 在 maxSpan+1 个窗口后过期
+	mr.FastForward(4 * window)
+	if len(mr.Keys()) != 0 {
+		t.Fatalf("keys not expired: %v", mr.Keys())
+	}
+}
+
+func TestCounter_NoWindow(t *testing.T) {
+	ctx := context.Background()
+	mr, client := newRedis(t)
+	c := NewCounter(client, "")
+	for range 2 {
+		if _, err := c.Incr(ctx, "total", 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n, _ := c.Get(ctx, "total", 0); n != 2 {
+		t.Fatalf("total = %d", n)
+	}
+	if ttl := mr.TTL("counter:total"); ttl != 0 {
+		t.Fatalf("unwindowed counter has ttl %v", ttl)
+	}
+}
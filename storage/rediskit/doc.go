@@ -0,0 +1,19 @@
+// Package rediskit 提供基于 Redis 的常用数据结构封装。
+//
+// 核心功能：
+//   - Counter：按时间窗口的原子计数器
+//   - Leaderboard：基于有序集合的排行榜
+//   - UniqueCounter：基于 HyperLogLog 的去重计数
+//   - DailyActive：基于 Bitmap 的日活标记
+//   - GeoIndex / SearchGeoIndex：基于 GEO / RediSearch 的位置索引（实现 geo.Index）
+//
+// 使用示例：
+//
+//	counter := rediskit.NewCounter(client, "api:calls:", rediskit.WithMaxSpan(60))
+//	n, err := counter.Incr(ctx, "user:1", time.Minute)
+//	lastHour, err := counter.Sum(ctx, "user:1", time.Minute, 60)
+//
+//	board := rediskit.NewLeaderboard(client, "game:score")
+//	board.Incr(ctx, "alice", 10)
+//	top, err := board.Top(ctx, 10)
+package rediskit
@@ -0,0 +1,224 @@
+package rediskit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/mildsunup/higo/storage/geo"
+)
+
+// GeoIndex 基于 Redis GEO 命令的位置索引
+// GEOSEARCH 不支持 offset，分页时取前 offset+limit 条后截取。
+type GeoIndex struct {
+	client redis.Cmdable
+	key    string
+}
+
+// NewGeoIndex 创建 GEO 位置索引
+func NewGeoIndex(client redis.Cmdable, key string) *GeoIndex {
+	return &GeoIndex{client: client, key: key}
+}
+
+// Add 添加或更新位置
+func (g *GeoIndex) Add(ctx context.Context, locations ...geo.Location) error {
+	if len(locations) == 0 {
+		return nil
+	}
+	locs := make([]*redis.GeoLocation, len(locations))
+	for i, l := range locations {
+		locs[i] = &redis.GeoLocation{Name: l.Member, Longitude: l.Longitude, Latitude: l.Latitude}
+	}
+	if err := g.client.GeoAdd(ctx, g.key, locs...).Err(); err != nil {
+		return fmt.Errorf("rediskit: geoadd: %w", err)
+	}
+	return nil
+}
+
+// Remove 移除位置
+func (g *GeoIndex) Remove(ctx context.Context, members ...string) error {
+	if len(members) == 0 {
+		return nil
+	}
+	args := make([]any, len(members))
+	for i, m := range members {
+		args[i] = m
+	}
+	if err := g.client.ZRem(ctx, g.key, args...).Err(); err != nil {
+		return fmt.Errorf("rediskit: geo remove: %w", err)
+	}
+	return nil
+}
+
+// SearchRadius 按半径搜索
+func (g *GeoIndex) SearchRadius(ctx context.Context, q geo.Query) ([]geo.Result, error) {
+	count := 0
+	if q.Limit > 0 {
+		count = q.Offset + q.Limit
+	}
+
+	locs, err := g.client.GeoSearchLocation(ctx, g.key, &redis.GeoSearchLocationQuery{
+		GeoSearchQuery: redis.GeoSearchQuery{
+			Longitude:  q.Longitude,
+			Latitude:   q.Latitude,
+			Radius:     q.Radius,
+			RadiusUnit: "m",
+			Sort:       "ASC",
+			Count:      count,
+		},
+		WithCoord: true,
+		WithDist:  true,
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("rediskit: geosearch: %w", err)
+	}
+
+	results := make([]geo.Result, len(locs))
+	for i, l := range locs {
+		results[i] = geo.Result{
+			Location: geo.Location{Member: l.Name, Longitude: l.Longitude, Latitude: l.Latitude},
+			Distance: l.Dist,
+		}
+	}
+	return geo.Page(results, q.Offset, q.Limit), nil
+}
+
+// SearchGeoIndex 基于 RediSearch 的位置索引
+// 位置以 Hash 存储（key 为 prefix+member），支持服务端分页。
+type SearchGeoIndex struct {
+	client redis.Cmdable
+	index  string
+	prefix string
+	field  string
+}
+
+// NewSearchGeoIndex 创建 RediSearch 位置索引
+func NewSearchGeoIndex(client redis.Cmdable, index, prefix string) *SearchGeoIndex {
+	return &SearchGeoIndex{client: client, index: index, prefix: prefix, field: "location"}
+}
+
+// HasRediSearch 检测服务端是否加载了 RediSearch 模块
+func HasRediSearch(ctx context.Context, client redis.Cmdable) bool {
+	return client.FT_List(ctx).Err() == nil
+}
+
+// NewGeo 创建位置索引，RediSearch 可用时优先使用
+func NewGeo(ctx context.Context, client redis.Cmdable, name string) (geo.Index, error) {
+	if !HasRediSearch(ctx, client) {
+		return NewGeoIndex(client, name), nil
+	}
+	idx := NewSearchGeoIndex(client, "idx:"+name, name+":")
+	if err := idx.EnsureIndex(ctx); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// EnsureIndex 创建索引（已存在时忽略）
+func (s *SearchGeoIndex) EnsureIndex(ctx context.Context) error {
+	if err := s.client.FTInfo(ctx, s.index).Err(); err == nil {
+		return nil
+	}
+	err := s.client.FTCreate(ctx, s.index,
+		&redis.FTCreateOptions{OnHash: true, Prefix: []any{s.prefix}},
+		&redis.FieldSchema{FieldName: s.field, FieldType: redis.SearchFieldTypeGeo},
+	).Err()
+	if err != nil {
+		return fmt.Errorf("rediskit: ft.create %s: %w", s.index, err)
+	}
+	return nil
+}
+
+// Add 添加或更新位置
+func (s *SearchGeoIndex) Add(ctx context.Context, locations ...geo.Location) error {
+	_, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, l := range locations {
+			pipe.HSet(ctx, s.prefix+l.Member, s.field, formatLonLat(l.Longitude, l.Latitude))
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("rediskit: search geo add: %w", err)
+	}
+	return nil
+}
+
+// Remove 移除位置
+func (s *SearchGeoIndex) Remove(ctx context.Context, members ...string) error {
+	if len(members) == 0 {
+		return nil
+	}
+	keys := make([]string, len(members))
+	for i, m := range members {
+		keys[i] = s.prefix + m
+	}
+	if err := s.client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("rediskit: search geo remove: %w", err)
+	}
+	return nil
+}
+
+// SearchRadius 按半径搜索，结果由近到远。
+// 使用 FT.AGGREGATE 在服务端计算 geodistance 并 SORTBY 排序后分页，各页之间顺序一致。
+func (s *SearchGeoIndex) SearchRadius(ctx context.Context, q geo.Query) ([]geo.Result, error) {
+	limit := q.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	lon := strconv.FormatFloat(q.Longitude, 'f', -1, 64)
+	lat := strconv.FormatFloat(q.Latitude, 'f', -1, 64)
+	query := fmt.Sprintf("@%s:[%s %s %s m]", s.field, lon, lat, strconv.FormatFloat(q.Radius, 'f', -1, 64))
+
+	res, err := s.client.FTAggregateWithArgs(ctx, s.index, query, &redis.FTAggregateOptions{
+		Load:           []redis.FTAggregateLoad{{Field: "@__key"}, {Field: "@" + s.field}},
+		Apply:          []redis.FTAggregateApply{{Field: fmt.Sprintf("geodistance(@%s, %s, %s)", s.field, lon, lat), As: "dist"}},
+		SortBy:         []redis.FTAggregateSortBy{{FieldName: "@dist", Asc: true}},
+		LimitOffset:    q.Offset,
+		Limit:          limit,
+		DialectVersion: 2,
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("rediskit: ft.aggregate: %w", err)
+	}
+
+	results := make([]geo.Result, 0, len(res.Rows))
+	for _, row := range res.Rows {
+		key, _ := row.Fields["__key"].(string)
+		loc, _ := row.Fields[s.field].(string)
+		lon, lat, ok := parseLonLat(loc)
+		if !ok {
+			continue
+		}
+		dist, err := strconv.ParseFloat(fmt.Sprint(row.Fields["dist"]), 64)
+		if err != nil {
+			dist = geo.Distance(q.Longitude, q.Latitude, lon, lat)
+		}
+		results = append(results, geo.Result{
+			Location: geo.Location{Member: strings.TrimPrefix(key, s.prefix), Longitude: lon, Latitude: lat},
+			Distance: dist,
+		})
+	}
+	return results, nil
+}
+
+func formatLonLat(lon, lat float64) string {
+	return strconv.FormatFloat(lon, 'f', -1, 64) + "," + strconv.FormatFloat(lat, 'f', -1, 64)
+}
+
+func parseLonLat(s string) (lon, lat float64, ok bool) {
+	lonStr, latStr, found := strings.Cut(s, ",")
+	if !found {
+		return 0, 0, false
+	}
+	lon, err1 := strconv.ParseFloat(lonStr, 64)
+	lat, err2 := strconv.ParseFloat(latStr, 64)
+	return lon, lat, err1 == nil && err2 == nil
+}
+
+var (
+	_ geo.Index = (*GeoIndex)(nil)
+	_ geo.Index = (*SearchGeoIndex)(nil)
+)
@@ -0,0 +1,114 @@
+package rediskit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Entry 排行榜条目
+type Entry struct {
+	Member string
+	Score  float64
+	Rank   int64 // 从 1 开始
+}
+
+// Leaderboard 排行榜（分数从高到低）
+type Leaderboard struct {
+	client redis.Cmdable
+	key    string
+}
+
+// NewLeaderboard 创建排行榜
+func NewLeaderboard(client redis.Cmdable, key string) *Leaderboard {
+	return &Leaderboard{client: client, key: key}
+}
+
+// Set 设置成员分数
+func (l *Leaderboard) Set(ctx context.Context, member string, score float64) error {
+	if err := l.client.ZAdd(ctx, l.key, redis.Z{Score: score, Member: member}).Err(); err != nil {
+		return fmt.Errorf("rediskit: set score: %w", err)
+	}
+	return nil
+}
+
+// Incr 增加成员分数，返回新分数
+func (l *Leaderboard) Incr(ctx context.Context, member string, delta float64) (float64, error) {
+	score, err := l.client.ZIncrBy(ctx, l.key, delta, member).Result()
+	if err != nil {
+		return 0, fmt.Errorf("rediskit: incr score: %w", err)
+	}
+	return score, nil
+}
+
+// Score 获取成员分数
+func (l *Leaderboard) Score(ctx context.Context, member string) (float64, bool, error) {
+	score, err := l.client.ZScore(ctx, l.key, member).Result()
+	if err == redis.Nil {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("rediskit: get score: %w", err)
+	}
+	return score, true, nil
+}
+
+// Rank 获取成员排名（从 1 开始），不存在返回 0
+func (l *Leaderboard) Rank(ctx context.Context, member string) (int64, error) {
+	rank, err := l.client.ZRevRank(ctx, l.key, member).Result()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("rediskit: get rank: %w", err)
+	}
+	return rank + 1, nil
+}
+
+// Top 获取前 n 名
+func (l *Leaderboard) Top(ctx context.Context, n int64) ([]Entry, error) {
+	return l.Range(ctx, 0, n)
+}
+
+// Range 分页获取排名，offset 从 0 开始
+func (l *Leaderboard) Range(ctx context.Context, offset, limit int64) ([]Entry, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+	zs, err := l.client.ZRevRangeWithScores(ctx, l.key, offset, offset+limit-1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("rediskit: range leaderboard: %w", err)
+	}
+
+	entries := make([]Entry, len(zs))
+	for i, z := range zs {
+		entries[i] = Entry{
+			Member: fmt.Sprint(z.Member),
+			Score:  z.Score,
+			Rank:   offset + int64(i) + 1,
+		}
+	}
+	return entries, nil
+}
+
+// Remove 移除成员
+func (l *Leaderboard) Remove(ctx context.Context, members ...string) error {
+	args := make([]any, len(members))
+	for i, m := range members {
+		args[i] = m
+	}
+	if err := l.client.ZRem(ctx, l.key, args...).Err(); err != nil {
+		return fmt.Errorf("rediskit: remove member: %w", err)
+	}
+	return nil
+}
+
+// Size 成员数量
+func (l *Leaderboard) Size(ctx context.Context) (int64, error) {
+	n, err := l.client.ZCard(ctx, l.key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("rediskit: leaderboard size: %w", err)
+	}
+	return n, nil
+}
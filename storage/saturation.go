@@ -0,0 +1,250 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/mildsunup/higo/observability"
+)
+
+// SaturationConfig 连接池饱和检测配置
+type SaturationConfig struct {
+	Interval    time.Duration // 采样间隔，默认 10 秒
+	MaxWaitRate float64       // 每秒等待连接次数达到该值视为饱和，默认 1
+	MaxAvgWait  time.Duration // 平均每次等待时长达到该值视为饱和，0 表示不按等待时长判断
+	Consecutive int           // 连续饱和多少次采样后告警，默认 3
+	AutoTune    PoolAutoTune  // 饱和时自动上调 MaxOpenConns
+}
+
+// PoolAutoTune 连接池自动调优，Max 为 0 时不调整。
+// 只作用于已设置 MaxOpenConns 且实现 PoolTuner 的存储；每次上调后需再次连续饱和 Consecutive 次才会继续上调，
+// 从不自动下调（连接数回落由 MaxIdleConns 与 ConnMaxIdleTime 负责）
+type PoolAutoTune struct {
+	Max  int // MaxOpenConns 上限
+	Step int // 每次上调数量，默认当前值的 1/4（至少 1）
+}
+
+// PoolTuner 支持运行时调整最大连接数的存储（可选实现）
+type PoolTuner interface {
+	SetMaxOpenConns(n int)
+}
+
+// SaturationStatus 连接池饱和状态
+type SaturationStatus struct {
+	Saturated    bool          `json:"saturated"`
+	Since        time.Time     `json:"since,omitzero"` // 进入饱和的时间
+	WaitRate     float64       `json:"wait_rate"`      // 最近一次采样的每秒等待次数
+	AvgWait      time.Duration `json:"avg_wait"`       // 最近一次采样的平均等待时长
+	MaxOpenConns int           `json:"max_open_conns"`
+}
+
+// SaturationEvent 饱和事件
+type SaturationEvent struct {
+	Name   string
+	Type   Type
+	Status SaturationStatus
+	// Raised 为自动调优上调 MaxOpenConns 时的原值（新值见 Status.MaxOpenConns），未调整时为 0
+	Raised int
+}
+
+// WithSaturation 启用连接池饱和检测：ConnectAll 后定期采样实现 SnapshotProvider 的存储，
+// 按等待次数与等待时长判断连接池是否耗尽，连续饱和达到阈值时告警，配置 AutoTune 时在上限内上调 MaxOpenConns；
+// CloseAll 时停止
+func WithSaturation(cfg SaturationConfig) ManagerOption {
+	return func(m *manager) {
+		if cfg.Interval <= 0 {
+			cfg.Interval = 10 * time.Second
+		}
+		if cfg.MaxWaitRate <= 0 {
+			cfg.MaxWaitRate = 1
+		}
+		if cfg.Consecutive <= 0 {
+			cfg.Consecutive = 3
+		}
+		m.saturation = &cfg
+	}
+}
+
+// WithSaturationHandler 设置饱和事件回调：进入饱和、自动上调与恢复时调用，用于记录告警日志
+func WithSaturationHandler(fn func(e SaturationEvent)) ManagerOption {
+	return func(m *manager) { m.onSaturation = fn }
+}
+
+// WithSaturationMetrics 发布饱和检测指标：
+// storage_pool_saturated{name,type}（0/1）、storage_pool_saturation_total{name,type}、
+// storage_pool_autotune_total{name,type}
+func WithSaturationMetrics(p observability.MetricsProvider) ManagerOption {
+	return func(m *manager) {
+		m.saturationMetrics = &saturationMetrics{
+			saturated: p.Gauge("storage_pool_saturated", "Storage connection pool saturated (0/1)", "name", "type"),
+			events:    p.Counter("storage_pool_saturation_total", "Storage connection pool saturation events", "name", "type"),
+			tuned:     p.Counter("storage_pool_autotune_total", "Storage connection pool MaxOpenConns raises", "name", "type"),
+		}
+	}
+}
+
+type saturationMetrics struct {
+	saturated observability.Gauge
+	events    observability.Counter
+	tuned     observability.Counter
+}
+
+// saturationEntry 单个存储的饱和状态
+type saturationEntry struct {
+	status SaturationStatus
+	streak int // 连续饱和的采样次数
+}
+
+func (m *manager) startSaturation() {
+	if m.saturation == nil {
+		return
+	}
+	m.monitorMu.Lock()
+	defer m.monitorMu.Unlock()
+	if m.stopSaturation != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.stopSaturation = cancel
+	m.saturationWG.Go(func() { m.runSaturation(ctx) })
+}
+
+func (m *manager) stopSaturationMonitor() {
+	m.monitorMu.Lock()
+	cancel := m.stopSaturation
+	m.stopSaturation = nil
+	m.monitorMu.Unlock()
+	if cancel != nil {
+		cancel()
+		m.saturationWG.Wait()
+	}
+}
+
+func (m *manager) runSaturation(ctx context.Context) {
+	ticker := time.NewTicker(m.saturation.Interval)
+	defer ticker.Stop()
+	// 速率基线归饱和检测独有，其他消费方调用 Snapshot 不影响采样间隔
+	samplers := make(map[string]*StatsSampler)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for _, s := range m.ordered() {
+			sp, ok := Unwrap(s).(SnapshotProvider)
+			if !ok {
+				continue
+			}
+			sampler := samplers[s.Name()]
+			if sampler == nil {
+				sampler = &StatsSampler{}
+				samplers[s.Name()] = sampler
+			}
+			m.sample(s, sampler.Sample(sp.Snapshot()))
+		}
+	}
+}
+
+// sample 根据一次快照更新饱和状态
+func (m *manager) sample(s Storage, snap StatsSnapshot) {
+	cfg := m.saturation
+	var avgWait time.Duration
+	if snap.WaitRate > 0 {
+		avgWait = time.Duration(snap.WaitDurationRate / snap.WaitRate * float64(time.Second))
+	}
+	saturated := snap.WaitRate >= cfg.MaxWaitRate || (cfg.MaxAvgWait > 0 && snap.WaitRate > 0 && avgWait >= cfg.MaxAvgWait)
+
+	m.saturationMu.Lock()
+	e, ok := m.saturationState[s.Name()]
+	if !ok {
+		e = &saturationEntry{}
+		m.saturationState[s.Name()] = e
+	}
+	e.status.WaitRate = snap.WaitRate
+	e.status.AvgWait = avgWait
+	e.status.MaxOpenConns = snap.MaxOpenConnections
+
+	var (
+		events []SaturationEvent
+		enter  bool
+	)
+	switch {
+	case !saturated:
+		e.streak = 0
+		if e.status.Saturated {
+			e.status.Saturated = false
+			e.status.Since = time.Time{}
+			events = append(events, SaturationEvent{Name: s.Name(), Type: s.Type(), Status: e.status})
+		}
+	default:
+		e.streak++
+		if e.streak < cfg.Consecutive {
+			break
+		}
+		if !e.status.Saturated {
+			e.status.Saturated = true
+			e.status.Since = snap.At
+			enter = true
+			events = append(events, SaturationEvent{Name: s.Name(), Type: s.Type(), Status: e.status})
+		}
+		if from, to := m.autoTune(s, e.status.MaxOpenConns); to > from {
+			e.status.MaxOpenConns = to
+			e.streak = 0
+			events = append(events, SaturationEvent{Name: s.Name(), Type: s.Type(), Status: e.status, Raised: from})
+		}
+	}
+	current := e.status.Saturated
+	m.saturationMu.Unlock()
+
+	if mm := m.saturationMetrics; mm != nil {
+		labels := []string{s.Name(), string(s.Type())}
+		if current {
+			mm.saturated.Set(1, labels...)
+		} else {
+			mm.saturated.Set(0, labels...)
+		}
+		if enter {
+			mm.events.Inc(labels...)
+		}
+		for _, ev := range events {
+			if ev.Raised > 0 {
+				mm.tuned.Inc(labels...)
+			}
+		}
+	}
+	if m.onSaturation != nil {
+		for _, ev := range events {
+			m.onSaturation(ev)
+		}
+	}
+}
+
+// autoTune 在上限内上调最大连接数，返回调整前后的值
+func (m *manager) autoTune(s Storage, current int) (int, int) {
+	tune := m.saturation.AutoTune
+	if tune.Max <= 0 || current <= 0 || current >= tune.Max {
+		return current, current
+	}
+	pt, ok := Unwrap(s).(PoolTuner)
+	if !ok {
+		return current, current
+	}
+	step := tune.Step
+	if step <= 0 {
+		step = max(current/4, 1)
+	}
+	next := min(current+step, tune.Max)
+	pt.SetMaxOpenConns(next)
+	return current, next
+}
+
+// Saturation 返回连接池饱和状态，未启用饱和检测或未采样时为零值
+func (m *manager) Saturation(name string) SaturationStatus {
+	m.saturationMu.Lock()
+	defer m.saturationMu.Unlock()
+	if e, ok := m.saturationState[name]; ok {
+		return e.status
+	}
+	return SaturationStatus{}
+}
@@ -0,0 +1,165 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// poolStorage 可控等待次数的连接池存储
+type poolStorage struct {
+	*Base
+	mu    sync.Mutex
+	stats Stats
+}
+
+func (p *poolStorage) Connect(ctx context.Context) error { p.SetState(StateConnected); return nil }
+func (p *poolStorage) Ping(ctx context.Context) error    { return nil }
+func (p *poolStorage) Close(ctx context.Context) error   { p.SetState(StateDisconnected); return nil }
+
+func (p *poolStorage) Snapshot() StatsSnapshot {
+	p.mu.Lock()
+	stats := p.stats
+	p.mu.Unlock()
+	return p.RecordStats(stats)
+}
+
+func (p *poolStorage) SetMaxOpenConns(n int) {
+	p.mu.Lock()
+	p.stats.MaxOpenConnections = n
+	p.mu.Unlock()
+}
+
+// wait 模拟 n 次等待连接
+func (p *poolStorage) wait(n int64) {
+	p.mu.Lock()
+	p.stats.WaitCount += n
+	p.stats.WaitDuration += time.Duration(n) * 10 * time.Millisecond
+	p.mu.Unlock()
+}
+
+func (p *poolStorage) maxOpen() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stats.MaxOpenConnections
+}
+
+func TestManager_Saturation(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events []SaturationEvent
+	)
+	m := NewManager(
+		WithSaturation(SaturationConfig{
+			Interval:    5 * time.Millisecond,
+			MaxWaitRate: 100,
+			Consecutive: 2,
+			AutoTune:    PoolAutoTune{Max: 12, Step: 4},
+		}),
+		WithSaturationHandler(func(e SaturationEvent) {
+			mu.Lock()
+			events = append(events, e)
+			mu.Unlock()
+		}),
+	)
+	s := &poolStorage{Base: NewBase("db", TypeMySQL), stats: Stats{MaxOpenConnections: 4}}
+	_ = m.Register(NewBuilder(s).WithReconnect(DefaultReconnectConfig()).Build())
+	ctx := context.Background()
+	if err := m.ConnectAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// 持续等待：判定饱和并上调至上限
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(time.Millisecond):
+				s.wait(10)
+			}
+		}
+	}()
+	waitUntil(t, func() bool { return m.Saturation("db").Saturated && s.maxOpen() == 12 })
+	if st := m.HealthCheck(ctx); !st[0].Saturated {
+		t.Fatalf("unexpected health status %+v", st[0])
+	}
+	close(stop)
+	<-done
+
+	waitUntil(t, func() bool { return !m.Saturation("db").Saturated })
+	_ = m.CloseAll(ctx)
+
+	mu.Lock()
+	defer mu.Unlock()
+	var entered, recovered bool
+	var raises []int
+	for _, e := range events {
+		switch {
+		case e.Raised > 0:
+			raises = append(raises, e.Raised, e.Status.MaxOpenConns)
+		case e.Status.Saturated:
+			entered = true
+		default:
+			recovered = true
+		}
+	}
+	if !entered || !recovered {
+		t.Fatalf("events = %+v", events)
+	}
+	if len(raises) != 4 || raises[0] != 4 || raises[1] != 8 || raises[2] != 8 || raises[3] != 12 {
+		t.Fatalf("raises = %v", raises)
+	}
+}
+
+func TestManager_SaturationAvgWait(t *testing.T) {
+	m := NewManager(WithSaturation(SaturationConfig{MaxWaitRate: 1e9, MaxAvgWait: 5 * time.Millisecond, Consecutive: 1})).(*manager)
+	s := &poolStorage{Base: NewBase("db", TypeMySQL), stats: Stats{MaxOpenConnections: 4}}
+	_ = m.Register(s)
+
+	m.sample(s, StatsSnapshot{WaitRate: 10, WaitDurationRate: 0.01})
+	if m.Saturation("db").Saturated {
+		t.Fatal("1ms average wait should not saturate")
+	}
+	m.sample(s, StatsSnapshot{WaitRate: 10, WaitDurationRate: 0.1})
+	if st := m.Saturation("db"); !st.Saturated || st.AvgWait != 10*time.Millisecond {
+		t.Fatalf("status = %+v", st)
+	}
+	// 未配置 AutoTune 时不调整
+	if s.maxOpen() != 4 {
+		t.Fatalf("max open = %d", s.maxOpen())
+	}
+}
+
+func TestStatsSampler_PerConsumerBaseline(t *testing.T) {
+	s := &poolStorage{Base: NewBase("db", TypeMySQL)}
+	var a, b StatsSampler
+	t0 := time.Now()
+	at := func(d time.Duration) StatsSnapshot {
+		snap := s.Snapshot()
+		snap.At = t0.Add(d)
+		return snap
+	}
+
+	if snap := a.Sample(at(0)); snap.WaitRate != 0 {
+		t.Fatalf("first sample rate = %v", snap.WaitRate)
+	}
+	_ = b.Sample(at(0))
+
+	s.wait(10)
+	// 其他消费方频繁采样不改变 a 的基线
+	for i := 1; i <= 5; i++ {
+		_ = b.Sample(at(time.Duration(i) * 100 * time.Millisecond))
+	}
+	snap := a.Sample(at(time.Second))
+	if snap.Interval != time.Second || snap.WaitRate != 10 || snap.WaitDurationRate != 0.1 {
+		t.Fatalf("snapshot = %+v", snap)
+	}
+	if snap := b.Sample(at(2 * time.Second)); snap.WaitRate != 0 {
+		t.Fatalf("b rate = %v", snap.WaitRate)
+	}
+}
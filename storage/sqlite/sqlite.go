@@ -0,0 +1,309 @@
+// Package sqlite 提供 SQLite 存储，用于本地开发、测试与单节点嵌入式部署，无需外部依赖。
+//
+// 包本身不绑定驱动，应用按需导入并设置 Config.Driver（默认 "sqlite"）：
+//
+//	import _ "modernc.org/sqlite"      // 纯 Go，驱动名 "sqlite"
+//	import _ "github.com/mattn/go-sqlite3" // cgo，驱动名 "sqlite3"
+//
+// 两种模式：
+//   - 内存模式（Path 为空或 ":memory:"）：单连接持有数据库，关闭后数据丢失，适合测试
+//   - 文件模式：每个连接设置 WAL、busy_timeout、synchronous=NORMAL 与外键约束；
+//     Litestream 为 true 时关闭自动检查点（wal_autocheckpoint=0），由 Litestream 负责检查点与复制
+//
+// 需要 GORM 时通过 WithDialector 传入方言（如 gorm.io/driver/sqlite），GORM 复用同一连接池：
+//
+//	s := sqlite.New(sqlite.Config{Path: "data/app.db"}, sqlite.WithDialector(func(conn gorm.ConnPool) gorm.Dialector {
+//	    return gormsqlite.New(gormsqlite.Config{Conn: conn})
+//	}))
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/mildsunup/higo/storage"
+)
+
+// MemoryPath 内存数据库路径
+const MemoryPath = ":memory:"
+
+// Config SQLite 配置
+type Config struct {
+	Name            string              `json:"name" yaml:"name"`
+	Driver          string              `json:"driver" yaml:"driver"`                         // database/sql 驱动名，默认 "sqlite"
+	Path            string              `json:"path" yaml:"path"`                             // 数据库文件路径，空或 ":memory:" 为内存模式
+	DSN             string              `json:"dsn" yaml:"dsn"`                               // 完整 DSN（驱动相关参数），非空时优先于 Path
+	BusyTimeout     time.Duration       `json:"busy_timeout" yaml:"busy_timeout"`             // 锁等待超时，默认 5s
+	Synchronous     string              `json:"synchronous" yaml:"synchronous"`               // 文件模式同步级别，默认 NORMAL
+	Litestream      bool                `json:"litestream" yaml:"litestream"`                 // 关闭自动检查点，由 Litestream 执行检查点
+	NoForeignKeys   bool                `json:"no_foreign_keys" yaml:"no_foreign_keys"`       // 不启用外键约束
+	Pragmas         []string            `json:"pragmas" yaml:"pragmas"`                       // 额外的连接级 PRAGMA，如 "cache_size = -20000"
+	MaxOpenConns    int                 `json:"max_open_conns" yaml:"max_open_conns"`         // 文件模式最大连接数，内存模式固定为 1
+	ConnMaxIdleTime time.Duration       `json:"conn_max_idle_time" yaml:"conn_max_idle_time"` // 文件模式连接最大空闲时间
+	LogLevel        gormlogger.LogLevel `json:"log_level" yaml:"log_level"`                   // GORM 日志级别
+}
+
+// Memory 是否为内存模式
+func (c Config) Memory() bool {
+	return c.DSN == "" && (c.Path == "" || c.Path == MemoryPath)
+}
+
+// Storage SQLite 存储
+type Storage struct {
+	*storage.Base
+	db        *sql.DB
+	gdb       *gorm.DB
+	config    Config
+	dialector func(gorm.ConnPool) gorm.Dialector
+}
+
+// Option SQLite 存储选项
+type Option func(*Storage)
+
+// WithDialector 设置 GORM 方言，连接后可通过 GORM 获取实例
+func WithDialector(fn func(conn gorm.ConnPool) gorm.Dialector) Option {
+	return func(s *Storage) {
+		s.dialector = fn
+	}
+}
+
+// New 创建 SQLite 存储
+func New(cfg Config, opts ...Option) *Storage {
+	name := cfg.Name
+	if name == "" {
+		name = "sqlite"
+	}
+	if cfg.Driver == "" {
+		cfg.Driver = "sqlite"
+	}
+	if cfg.BusyTimeout <= 0 {
+		cfg.BusyTimeout = 5 * time.Second
+	}
+	if cfg.Synchronous == "" {
+		cfg.Synchronous = "NORMAL"
+	}
+
+	s := &Storage{
+		Base:   storage.NewBase(name, storage.TypeSQLite),
+		config: cfg,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+func (s *Storage) Connect(ctx context.Context) error {
+	if !s.CompareAndSwapState(storage.StateDisconnected, storage.StateConnecting) {
+		return fmt.Errorf("sqlite: invalid state for connect")
+	}
+
+	drv, err := lookupDriver(s.config.Driver)
+	if err != nil {
+		s.SetState(storage.StateDisconnected)
+		return err
+	}
+
+	db := sql.OpenDB(&connector{driver: drv, dsn: s.dsn(), pragmas: s.pragmas()})
+	if s.config.Memory() {
+		// 内存数据库随连接销毁，固定单连接且不回收
+		db.SetMaxOpenConns(1)
+		db.SetMaxIdleConns(1)
+		db.SetConnMaxLifetime(0)
+		db.SetConnMaxIdleTime(0)
+	} else {
+		if s.config.MaxOpenConns > 0 {
+			db.SetMaxOpenConns(s.config.MaxOpenConns)
+		}
+		if s.config.ConnMaxIdleTime > 0 {
+			db.SetConnMaxIdleTime(s.config.ConnMaxIdleTime)
+		}
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		s.SetState(storage.StateDisconnected)
+		return fmt.Errorf("sqlite: open %s failed: %w", s.dsn(), err)
+	}
+
+	if s.dialector != nil {
+		gdb, err := gorm.Open(s.dialector(db), &gorm.Config{Logger: gormlogger.Default.LogMode(s.config.LogLevel)})
+		if err != nil {
+			_ = db.Close()
+			s.SetState(storage.StateDisconnected)
+			return fmt.Errorf("sqlite: open gorm failed: %w", err)
+		}
+		s.gdb = gdb
+	}
+
+	s.db = db
+	s.SetState(storage.StateConnected)
+	return nil
+}
+
+func (s *Storage) dsn() string {
+	switch {
+	case s.config.DSN != "":
+		return s.config.DSN
+	case s.config.Memory():
+		return MemoryPath
+	default:
+		return s.config.Path
+	}
+}
+
+// pragmas 每个新连接执行的 PRAGMA
+func (s *Storage) pragmas() []string {
+	pragmas := []string{fmt.Sprintf("PRAGMA busy_timeout = %d", s.config.BusyTimeout.Milliseconds())}
+	if !s.config.NoForeignKeys {
+		pragmas = append(pragmas, "PRAGMA foreign_keys = ON")
+	}
+	if !s.config.Memory() {
+		pragmas = append(pragmas,
+			"PRAGMA journal_mode = WAL",
+			"PRAGMA synchronous = "+s.config.Synchronous,
+		)
+		if s.config.Litestream {
+			pragmas = append(pragmas, "PRAGMA wal_autocheckpoint = 0")
+		}
+	}
+	for _, p := range s.config.Pragmas {
+		pragmas = append(pragmas, "PRAGMA "+p)
+	}
+	return pragmas
+}
+
+func (s *Storage) Ping(ctx context.Context) error {
+	if s.db == nil {
+		return fmt.Errorf("sqlite: not connected")
+	}
+	return s.db.PingContext(ctx)
+}
+
+func (s *Storage) Close(ctx context.Context) error {
+	if s.db == nil {
+		return nil
+	}
+	s.SetState(storage.StateDisconnecting)
+	err := s.db.Close()
+	s.SetState(storage.StateDisconnected)
+	s.db = nil
+	s.gdb = nil
+	return err
+}
+
+// ServerVersion 返回 SQLite 库版本
+func (s *Storage) ServerVersion(ctx context.Context) (string, error) {
+	if s.db == nil {
+		return "", fmt.Errorf("sqlite: not connected")
+	}
+	var version string
+	if err := s.db.QueryRowContext(ctx, "SELECT sqlite_version()").Scan(&version); err != nil {
+		return "", fmt.Errorf("sqlite: query version: %w", err)
+	}
+	return version, nil
+}
+
+// Checkpoint 执行 WAL 检查点（mode 为 PASSIVE/FULL/RESTART/TRUNCATE，空为 PASSIVE），
+// 关闭自动检查点且未运行 Litestream 时（如测试、备份前）由应用调用
+func (s *Storage) Checkpoint(ctx context.Context, mode string) error {
+	if s.db == nil {
+		return fmt.Errorf("sqlite: not connected")
+	}
+	if mode == "" {
+		mode = "PASSIVE"
+	}
+	if _, err := s.db.ExecContext(ctx, "PRAGMA wal_checkpoint("+mode+")"); err != nil {
+		return fmt.Errorf("sqlite: checkpoint: %w", err)
+	}
+	return nil
+}
+
+// DB 返回 database/sql 实例
+func (s *Storage) DB() *sql.DB { return s.db }
+
+// GORM 返回 GORM 实例，未设置 WithDialector 时为 nil
+func (s *Storage) GORM() *gorm.DB { return s.gdb }
+
+// Stats 返回连接池统计
+func (s *Storage) Stats() storage.Stats {
+	if s.db == nil {
+		return storage.Stats{}
+	}
+	stats := s.db.Stats()
+	return storage.Stats{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDuration:       stats.WaitDuration,
+	}
+}
+
+// Snapshot 返回连接池统计快照
+func (s *Storage) Snapshot() storage.StatsSnapshot { return s.RecordStats(s.Stats()) }
+
+var (
+	_ storage.Storage          = (*Storage)(nil)
+	_ storage.StatsProvider    = (*Storage)(nil)
+	_ storage.VersionProvider  = (*Storage)(nil)
+	_ storage.SnapshotProvider = (*Storage)(nil)
+)
+
+// --- 连接初始化 ---
+
+// lookupDriver 按名称获取已注册的 database/sql 驱动
+func lookupDriver(name string) (driver.Driver, error) {
+	db, err := sql.Open(name, "")
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: driver %q not registered, import a SQLite driver such as modernc.org/sqlite: %w", name, err)
+	}
+	defer db.Close()
+	return db.Driver(), nil
+}
+
+// connector 在每个新连接上执行 PRAGMA
+type connector struct {
+	driver  driver.Driver
+	dsn     string
+	pragmas []string
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.driver.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range c.pragmas {
+		if err := execConn(ctx, conn, p); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("sqlite: %s: %w", p, err)
+		}
+	}
+	return conn, nil
+}
+
+func (c *connector) Driver() driver.Driver { return c.driver }
+
+func execConn(ctx context.Context, conn driver.Conn, query string) error {
+	if execer, ok := conn.(driver.ExecerContext); ok {
+		_, err := execer.ExecContext(ctx, query, nil)
+		return err
+	}
+	stmt, err := conn.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	_, err = stmt.Exec(nil)
+	return err
+}
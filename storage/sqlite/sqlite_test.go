@@ -0,0 +1,105 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mildsunup/higo/storage"
+)
+
+// fakeDriver 记录每个连接执行的语句
+type fakeDriver struct {
+	mu    sync.Mutex
+	dsns  []string
+	execs []string
+}
+
+func (d *fakeDriver) Open(dsn string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.dsns = append(d.dsns, dsn)
+	return &fakeConn{d: d}, nil
+}
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	c.d.execs = append(c.d.execs, query)
+	return driver.RowsAffected(0), nil
+}
+
+var fake = &fakeDriver{}
+
+func init() { sql.Register("higo-fake-sqlite", fake) }
+
+func TestStorage_FileMode(t *testing.T) {
+	ctx := context.Background()
+	s := New(Config{Driver: "higo-fake-sqlite", Path: "data/app.db", Litestream: true, Pragmas: []string{"cache_size = -20000"}})
+	if err := s.Connect(ctx); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer s.Close(ctx)
+
+	if s.State() != storage.StateConnected || s.Type() != storage.TypeSQLite {
+		t.Errorf("state = %v, type = %v", s.State(), s.Type())
+	}
+	want := []string{
+		"PRAGMA busy_timeout = 5000",
+		"PRAGMA foreign_keys = ON",
+		"PRAGMA journal_mode = WAL",
+		"PRAGMA synchronous = NORMAL",
+		"PRAGMA wal_autocheckpoint = 0",
+		"PRAGMA cache_size = -20000",
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if !slices.Equal(fake.execs, want) {
+		t.Errorf("pragmas = %v, want %v", fake.execs, want)
+	}
+	if fake.dsns[len(fake.dsns)-1] != "data/app.db" {
+		t.Errorf("dsn = %v", fake.dsns)
+	}
+	fake.execs = nil
+}
+
+func TestStorage_MemoryMode(t *testing.T) {
+	ctx := context.Background()
+	s := New(Config{Driver: "higo-fake-sqlite"})
+	if err := s.Connect(ctx); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer s.Close(ctx)
+
+	if got := s.Stats().MaxOpenConnections; got != 1 {
+		t.Errorf("MaxOpenConnections = %d, want 1", got)
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if slices.Contains(fake.execs, "PRAGMA journal_mode = WAL") {
+		t.Errorf("memory mode should not enable WAL: %v", fake.execs)
+	}
+	fake.execs = nil
+}
+
+func TestStorage_UnknownDriver(t *testing.T) {
+	s := New(Config{Driver: "missing"})
+	err := s.Connect(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "not registered") {
+		t.Errorf("Connect() error = %v", err)
+	}
+	if s.State() != storage.StateDisconnected {
+		t.Errorf("state = %v, want disconnected", s.State())
+	}
+}
@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mildsunup/higo/observability"
+)
+
+// StatsSnapshot 连接池统计快照
+// 速率字段由 StatsSampler 相对调用方自己的上一次采样填充，Snapshot 本身不计算速率。
+type StatsSnapshot struct {
+	Stats
+	WaitRate         float64       `json:"wait_rate"`          // 每秒等待连接次数
+	WaitDurationRate float64       `json:"wait_duration_rate"` // 每秒累计等待时长（秒）
+	Interval         time.Duration `json:"interval"`
+	At               time.Time     `json:"at"`
+}
+
+// StatsSampler 按调用方维护速率基线：每个消费方（饱和检测、管理端点等）持有各自的采样器，
+// 互不干扰对方的采样间隔。零值可用。
+type StatsSampler struct {
+	mu   sync.Mutex
+	last StatsSnapshot
+}
+
+// Sample 以 snap 为新基线，返回填充了相对上一次采样速率的快照；首次采样只记录基线，速率为 0
+func (s *StatsSampler) Sample(snap StatsSnapshot) StatsSnapshot {
+	s.mu.Lock()
+	last := s.last
+	s.last = snap
+	s.mu.Unlock()
+
+	if last.At.IsZero() || !snap.At.After(last.At) {
+		return snap
+	}
+	snap.Interval = snap.At.Sub(last.At)
+	secs := snap.Interval.Seconds()
+	snap.WaitRate = float64(snap.WaitCount-last.WaitCount) / secs
+	snap.WaitDurationRate = (snap.WaitDuration - last.WaitDuration).Seconds() / secs
+	return snap
+}
+
+// SnapshotProvider 提供统计快照的存储
+type SnapshotProvider interface {
+	Snapshot() StatsSnapshot
+}
+
+// baseMetrics 同一 MetricsProvider 共享的存储基础指标
+type baseMetrics struct {
+	state observability.Gauge
+	pool  *PoolMetrics
+}
+
+var baseMetricsByProvider sync.Map // observability.MetricsProvider -> func() baseMetrics
+
+func metricsFor(p observability.MetricsProvider) baseMetrics {
+	fn, _ := baseMetricsByProvider.LoadOrStore(p, sync.OnceValue(func() baseMetrics {
+		state := p.Gauge("storage_state", "Storage connection state (0=disconnected, 1=connecting, 2=connected, 3=disconnecting)", "name", "type")
+		return baseMetrics{state: state, pool: NewPoolMetrics(p, "")}
+	}))
+	return fn.(func() baseMetrics)()
+}
+
+// SetMetricsProvider 将状态与连接池统计自动发布到指标提供者（标签 name/type）
+func (b *Base) SetMetricsProvider(p observability.MetricsProvider) {
+	m := metricsFor(p)
+	b.metrics.Store(&m)
+	m.state.Set(float64(b.State()), b.name, string(b.typ))
+}
+
+// RecordStats 发布连接池统计指标并返回不含速率的快照，可被任意多个消费方调用而互不影响
+// 实现 StatsProvider 的存储通过该方法提供 Snapshot：
+//
+//	func (s *Storage) Snapshot() storage.StatsSnapshot { return s.RecordStats(s.Stats()) }
+func (b *Base) RecordStats(stats Stats) StatsSnapshot {
+	if m := b.metrics.Load(); m != nil {
+		m.pool.Record(b.name, b.typ, stats)
+
+		// 计数器增量只依赖已发布的累计值，与调用方无关
+		b.sampleMu.Lock()
+		d := stats.WaitCount - b.publishedWaits
+		b.publishedWaits = stats.WaitCount
+		b.sampleMu.Unlock()
+		if d > 0 {
+			m.pool.waitCount.Add(float64(d), b.name, string(b.typ))
+		}
+	}
+	return StatsSnapshot{Stats: stats, At: time.Now()}
+}
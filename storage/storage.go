@@ -2,14 +2,22 @@ package storage
 
 import (
 	"context"
+	"sync"
 	"sync/atomic"
 )
 
 // Base 存储基类，提供通用功能
 type Base struct {
-	name  string
-	typ   Type
-	state atomic.Int32
+	name    string
+	typ     Type
+	state   atomic.Int32
+	metrics atomic.Pointer[baseMetrics]
+	ops     atomic.Pointer[Metrics]
+
+	queryCache atomic.Pointer[QueryCache]
+
+	sampleMu       sync.Mutex
+	publishedWaits int64 // 已计入 storage_pool_wait_total 的等待次数
 }
 
 // NewBase 创建基类
@@ -29,11 +37,24 @@ func (b *Base) Type() Type { return b.typ }
 func (b *Base) State() State { return State(b.state.Load()) }
 
 // SetState 设置状态
-func (b *Base) SetState(s State) { b.state.Store(int32(s)) }
+func (b *Base) SetState(s State) {
+	b.state.Store(int32(s))
+	b.recordState(s)
+}
 
 // CompareAndSwapState CAS 状态
 func (b *Base) CompareAndSwapState(old, new State) bool {
-	return b.state.CompareAndSwap(int32(old), int32(new))
+	if !b.state.CompareAndSwap(int32(old), int32(new)) {
+		return false
+	}
+	b.recordState(new)
+	return true
+}
+
+func (b *Base) recordState(s State) {
+	if m := b.metrics.Load(); m != nil {
+		m.state.Set(float64(s), b.name, string(b.typ))
+	}
 }
 
 // Manager 存储管理器接口
@@ -52,6 +73,10 @@ type Manager interface {
 	CloseAll(ctx context.Context) error
 	// HealthCheck 健康检查
 	HealthCheck(ctx context.Context) []HealthStatus
+	// Health 健康监控维护的健康状态（见 WithMonitor）
+	Health(name string) Health
+	// Saturation 饱和检测维护的连接池饱和状态（见 WithSaturation）
+	Saturation(name string) SaturationStatus
 	// List 列出所有存储名称
 	List() []string
 }
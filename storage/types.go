@@ -15,6 +15,8 @@ const (
 	TypeClickHouse    Type = "clickhouse"
 	TypeElasticsearch Type = "elasticsearch"
 	TypePostgreSQL    Type = "postgresql"
+	TypeEmbedded      Type = "embedded"
+	TypeSQLite        Type = "sqlite"
 	TypeUnknown       Type = "unknown"
 )
 
@@ -73,12 +75,19 @@ type StatsProvider interface {
 	Stats() Stats
 }
 
+// VersionProvider 服务端版本提供者（可选实现）
+type VersionProvider interface {
+	ServerVersion(ctx context.Context) (string, error)
+}
+
 // HealthStatus 健康状态
 type HealthStatus struct {
 	Name      string        `json:"name"`
 	Type      Type          `json:"type"`
 	State     string        `json:"state"`
 	Healthy   bool          `json:"healthy"`
+	Degraded  bool          `json:"degraded,omitempty"` // 健康监控判定为降级，正在后台重连
+	Saturated bool          `json:"saturated,omitempty"` // 饱和检测判定连接池耗尽
 	Latency   time.Duration `json:"latency"`
 	Error     string        `json:"error,omitempty"`
 	Stats     *Stats        `json:"stats,omitempty"`
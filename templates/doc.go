@@ -0,0 +1,36 @@
+// Package templates 提供服务端模板渲染（邮件、回执、导出文件等）。
+//
+// 核心功能：
+//   - 嵌入式模板：从 fs.FS（通常为 go:embed）加载，layouts/ 下为布局、partials/ 下为片段，其余文件为页面
+//   - 布局与片段：页面内容作为 "content" 模板嵌入布局，页面可通过 {{define "subject"}} 等定义附加块
+//   - 格式：.html/.htm 使用 html/template 自动转义，其他扩展名（.txt/.tmpl/.md）使用 text/template
+//   - 国际化：t/locale/number/date 函数按渲染语言翻译与格式化，语言按支持列表匹配（zh-CN → zh → 默认）
+//   - 严格模式（默认开启）：缺失的 map 键或翻译键返回错误而不是输出空值
+//   - 缓存：页面在创建时解析，按语言克隆后缓存，渲染不再解析或转义分析
+//   - 沙箱：模板只能调用内置与显式注册的函数，只能引用同一文件系统中的模板，输出大小受限且随 context 取消
+//
+// 目录结构：
+//
+//	mail/
+//	    layouts/base.html   {{template "content" .}}
+//	    layouts/base.txt
+//	    partials/footer.html
+//	    receipt.html        {{define "subject"}}{{t "receipt.subject" .OrderNo}}{{end}} ...
+//	    receipt.txt
+//
+// 使用示例：
+//
+//	//go:embed mail
+//	var mailFS embed.FS
+//
+//	sub, _ := fs.Sub(mailFS, "mail")
+//	engine, err := templates.New(sub,
+//	    templates.WithTranslator(templates.MapTranslator{
+//	        "en": {"receipt.subject": "Your receipt for order %s"},
+//	        "zh": {"receipt.subject": "订单 %s 的收据"},
+//	    }),
+//	    templates.WithDefaultLocale("en"))
+//
+//	msg, err := engine.RenderEmail(ctx, "receipt", order, templates.WithLocale(user.Locale))
+//	// msg.Subject, msg.Text, msg.HTML
+package templates
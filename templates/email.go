@@ -0,0 +1,51 @@
+package templates
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// subjectBlock 邮件主题块名
+const subjectBlock = "subject"
+
+// Email 渲染后的邮件内容
+type Email struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// RenderEmail 渲染邮件：name 不含扩展名，分别渲染 name.txt 与 name.html（至少存在其一），
+// 主题取自页面中的 {{define "subject"}} 块（优先纯文本页面），并去除首尾空白与换行
+func (e *Engine) RenderEmail(ctx context.Context, name string, data any, opts ...RenderOption) (Email, error) {
+	var msg Email
+	textName, htmlName := name+".txt", name+".html"
+	if !e.Has(textName) && !e.Has(htmlName) {
+		return msg, fmt.Errorf("%w: %s.txt or %s.html", ErrNotFound, name, name)
+	}
+
+	var err error
+	for _, p := range []struct {
+		name string
+		body *string
+	}{{textName, &msg.Text}, {htmlName, &msg.HTML}} {
+		if !e.Has(p.name) {
+			continue
+		}
+		if *p.body, err = e.Render(ctx, p.name, data, opts...); err != nil {
+			return Email{}, err
+		}
+		if msg.Subject != "" {
+			continue
+		}
+		if e.pages[p.name].sets[""].has(subjectBlock) {
+			subject, err := e.RenderBlock(ctx, p.name, subjectBlock, data, opts...)
+			if err != nil {
+				return Email{}, err
+			}
+			msg.Subject = strings.Join(strings.Fields(subject), " ")
+		}
+	}
+	return msg, nil
+}
@@ -0,0 +1,221 @@
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Translator 翻译器，按语言查找文案，未找到时返回 false
+type Translator interface {
+	Translate(locale, key string) (string, bool)
+}
+
+// MapTranslator 基于内存映射的翻译器：语言 -> 键 -> 文案（可包含 fmt 占位符）
+type MapTranslator map[string]map[string]string
+
+// Translate 实现 Translator
+func (m MapTranslator) Translate(locale, key string) (string, bool) {
+	s, ok := m[locale][key]
+	return s, ok
+}
+
+// Locales 返回全部语言，作为引擎支持的语言列表
+func (m MapTranslator) Locales() []string {
+	return slices.Sorted(maps.Keys(m))
+}
+
+// matchLocale 将请求的语言匹配到支持列表：精确匹配（大小写不敏感）→ 主语言 → 默认语言。
+// 未配置支持列表时取主语言子标签，使缓存键有界。
+func (e *Engine) matchLocale(locale string) string {
+	locale = strings.ReplaceAll(strings.TrimSpace(locale), "_", "-")
+	if locale == "" {
+		return e.cfg.defaultLocale
+	}
+	base, _, _ := strings.Cut(locale, "-")
+	if len(e.cfg.locales) == 0 {
+		if len(base) < 2 || len(base) > 3 || strings.IndexFunc(base, func(r rune) bool { return r < 'A' || r > 'z' || (r > 'Z' && r < 'a') }) >= 0 {
+			return e.cfg.defaultLocale
+		}
+		return strings.ToLower(base)
+	}
+	for _, l := range e.cfg.locales {
+		if strings.EqualFold(l, locale) {
+			return l
+		}
+	}
+	for _, l := range e.cfg.locales {
+		if strings.EqualFold(l, base) {
+			return l
+		}
+	}
+	return e.cfg.defaultLocale
+}
+
+// funcs 返回绑定语言的模板函数：
+//
+//	t KEY ARGS...          翻译，文案含占位符时按 fmt.Sprintf 格式化；严格模式下缺失键返回错误，否则输出键名
+//	locale                 当前语言
+//	number V [DECIMALS]    按语言千分位与小数点格式化数字，默认保留 0 位小数
+//	date T [LAYOUT]        按语言默认格式（或指定 Go 布局）格式化 time.Time
+//	upper/lower/trim       字符串大小写与去空白
+//	join LIST SEP          连接字符串切片
+//	default DEF V          V 为零值时返回 DEF
+//	dict K V ...           构造 map，用于向片段传递多个值
+func (e *Engine) funcs(locale string) map[string]any {
+	f := map[string]any{
+		"upper":   strings.ToUpper,
+		"lower":   strings.ToLower,
+		"trim":    strings.TrimSpace,
+		"join":    strings.Join,
+		"default": defaultValue,
+		"dict":    dict,
+	}
+	for k, v := range e.cfg.funcs {
+		f[k] = v
+	}
+	format := formatFor(locale)
+	f["locale"] = func() string { return locale }
+	f["t"] = func(key string, args ...any) (string, error) {
+		return e.translate(locale, key, args)
+	}
+	f["number"] = func(v any, decimals ...int) (string, error) {
+		n, err := toFloat(v)
+		if err != nil {
+			return "", err
+		}
+		d := 0
+		if len(decimals) > 0 {
+			d = decimals[0]
+		}
+		return format.number(n, d), nil
+	}
+	f["date"] = func(t time.Time, layout ...string) string {
+		if len(layout) > 0 {
+			return t.Format(layout[0])
+		}
+		return t.Format(format.date)
+	}
+	return f
+}
+
+func (e *Engine) translate(locale, key string, args []any) (string, error) {
+	var s string
+	ok := false
+	if e.cfg.translator != nil {
+		if s, ok = e.cfg.translator.Translate(locale, key); !ok && locale != e.cfg.defaultLocale {
+			s, ok = e.cfg.translator.Translate(e.cfg.defaultLocale, key)
+		}
+	}
+	if !ok {
+		if e.cfg.strict {
+			return "", fmt.Errorf("missing translation %q for locale %q", key, locale)
+		}
+		return key, nil
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(s, args...), nil
+	}
+	return s, nil
+}
+
+// localeFormat 语言的数字与日期格式
+type localeFormat struct {
+	group   string
+	decimal string
+	date    string
+}
+
+// localeFormats 按主语言的常用格式，未列出的语言使用 "1,234.5" 与 "2006-01-02"
+var localeFormats = map[string]localeFormat{
+	"en": {",", ".", "Jan 2, 2006"},
+	"zh": {",", ".", "2006年1月2日"},
+	"ja": {",", ".", "2006年1月2日"},
+	"ko": {",", ".", "2006년 1월 2일"},
+	"de": {".", ",", "02.01.2006"},
+	"es": {".", ",", "02/01/2006"},
+	"it": {".", ",", "02/01/2006"},
+	"pt": {".", ",", "02/01/2006"},
+	"nl": {".", ",", "02-01-2006"},
+	"id": {".", ",", "02/01/2006"},
+	"tr": {".", ",", "02.01.2006"},
+	"fr": {" ", ",", "02/01/2006"},
+	"ru": {" ", ",", "02.01.2006"},
+	"pl": {" ", ",", "02.01.2006"},
+	"sv": {" ", ",", "2006-01-02"},
+}
+
+func formatFor(locale string) localeFormat {
+	base, _, _ := strings.Cut(strings.ToLower(locale), "-")
+	if f, ok := localeFormats[base]; ok {
+		return f
+	}
+	return localeFormat{",", ".", "2006-01-02"}
+}
+
+func (f localeFormat) number(n float64, decimals int) string {
+	s := strconv.FormatFloat(math.Abs(n), 'f', max(decimals, 0), 64)
+	intPart, frac, _ := strings.Cut(s, ".")
+	var b strings.Builder
+	if n < 0 && strings.Trim(s, "0.") != "" {
+		b.WriteByte('-')
+	}
+	for i, c := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteString(f.group)
+		}
+		b.WriteRune(c)
+	}
+	if frac != "" {
+		b.WriteString(f.decimal)
+		b.WriteString(frac)
+	}
+	return b.String()
+}
+
+func toFloat(v any) (float64, error) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	case reflect.String:
+		return strconv.ParseFloat(rv.String(), 64)
+	default:
+		return 0, fmt.Errorf("number: unsupported type %T", v)
+	}
+}
+
+func defaultValue(def, v any) any {
+	if v == nil {
+		return def
+	}
+	if rv := reflect.ValueOf(v); rv.IsZero() {
+		return def
+	}
+	return v
+}
+
+func dict(kv ...any) (map[string]any, error) {
+	if len(kv)%2 != 0 {
+		return nil, errors.New("dict: odd number of arguments")
+	}
+	m := make(map[string]any, len(kv)/2)
+	for i := 0; i < len(kv); i += 2 {
+		k, ok := kv[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict: key %v is not a string", kv[i])
+		}
+		m[k] = kv[i+1]
+	}
+	return m, nil
+}
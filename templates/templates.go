@@ -0,0 +1,389 @@
+package templates
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+)
+
+var (
+	// ErrNotFound 模板不存在
+	ErrNotFound = errors.New("templates: template not found")
+	// ErrOutputTooLarge 渲染输出超过上限
+	ErrOutputTooLarge = errors.New("templates: output too large")
+)
+
+const (
+	layoutDir  = "layouts"
+	partialDir = "partials"
+	// contentName 页面内容在布局中的模板名
+	contentName = "content"
+	// layoutRoot 布局在模板集合中的名称，模板中无法引用
+	layoutRoot = "\x00layout"
+	// setRoot 模板集合的空根模板，只承载函数与选项
+	setRoot = "\x00root"
+)
+
+// config 引擎配置
+type config struct {
+	translator    Translator
+	locales       []string
+	defaultLocale string
+	defaultLayout string
+	strict        bool
+	maxOutput     int
+	funcs         map[string]any
+}
+
+// Option 引擎选项
+type Option func(*config)
+
+// WithTranslator 设置翻译器，实现 Locales() []string 时自动作为支持的语言列表
+func WithTranslator(t Translator) Option {
+	return func(c *config) { c.translator = t }
+}
+
+// WithLocales 设置支持的语言列表，渲染语言按此列表匹配，未匹配时使用默认语言
+func WithLocales(locales ...string) Option {
+	return func(c *config) { c.locales = locales }
+}
+
+// WithDefaultLocale 设置默认语言，默认 "en"
+func WithDefaultLocale(locale string) Option {
+	return func(c *config) { c.defaultLocale = locale }
+}
+
+// WithDefaultLayout 设置默认布局名（不含扩展名），默认 "base"；布局文件不存在时页面单独渲染
+func WithDefaultLayout(name string) Option {
+	return func(c *config) { c.defaultLayout = name }
+}
+
+// WithStrict 设置严格模式（默认开启）：缺失的 map 键与翻译键返回错误
+func WithStrict(on bool) Option {
+	return func(c *config) { c.strict = on }
+}
+
+// WithMaxOutput 设置单次渲染输出的最大字节数，默认 1MiB
+func WithMaxOutput(n int) Option {
+	return func(c *config) {
+		if n > 0 {
+			c.maxOutput = n
+		}
+	}
+}
+
+// WithFuncs 注册额外的模板函数，同名时覆盖内置函数（locale 相关函数除外）
+func WithFuncs(funcs map[string]any) Option {
+	return func(c *config) {
+		for k, v := range funcs {
+			c.funcs[k] = v
+		}
+	}
+}
+
+// renderConfig 单次渲染配置
+type renderConfig struct {
+	locale string
+	layout *string
+}
+
+// RenderOption 渲染选项
+type RenderOption func(*renderConfig)
+
+// WithLocale 设置渲染语言（如 "zh-CN"），为空时使用默认语言
+func WithLocale(locale string) RenderOption {
+	return func(c *renderConfig) { c.locale = locale }
+}
+
+// WithLayout 指定本次渲染的布局名，空字符串表示不使用布局
+func WithLayout(name string) RenderOption {
+	return func(c *renderConfig) { c.layout = &name }
+}
+
+// page 已解析的页面，按布局保存未执行的模板集合，渲染时按语言克隆
+type page struct {
+	html bool
+	sets map[string]*set // 布局名 -> 模板集合，"" 表示无布局
+}
+
+// set html/template 与 text/template 的统一封装
+type set struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+func (s *set) clone(funcs map[string]any) (*set, error) {
+	if s.html != nil {
+		t, err := s.html.Clone()
+		if err != nil {
+			return nil, err
+		}
+		return &set{html: t.Funcs(funcs)}, nil
+	}
+	t, err := s.text.Clone()
+	if err != nil {
+		return nil, err
+	}
+	return &set{text: t.Funcs(funcs)}, nil
+}
+
+func (s *set) execute(w io.Writer, name string, data any) error {
+	if s.html != nil {
+		return s.html.ExecuteTemplate(w, name, data)
+	}
+	return s.text.ExecuteTemplate(w, name, data)
+}
+
+func (s *set) has(name string) bool {
+	if s.html != nil {
+		return s.html.Lookup(name) != nil
+	}
+	return s.text.Lookup(name) != nil
+}
+
+// Engine 模板引擎，并发安全
+type Engine struct {
+	cfg   config
+	pages map[string]*page
+	cache sync.Map // name|layout|locale -> *set
+}
+
+// New 从文件系统加载并解析全部模板，任一模板解析失败时返回错误
+func New(fsys fs.FS, opts ...Option) (*Engine, error) {
+	cfg := config{
+		defaultLocale: "en",
+		defaultLayout: "base",
+		strict:        true,
+		maxOutput:     1 << 20,
+		funcs:         map[string]any{},
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if len(cfg.locales) == 0 {
+		if l, ok := cfg.translator.(interface{ Locales() []string }); ok {
+			cfg.locales = l.Locales()
+		}
+	}
+
+	e := &Engine{cfg: cfg, pages: map[string]*page{}}
+	files := map[string]string{}
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		files[p] = string(b)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("templates: load: %w", err)
+	}
+
+	names := make([]string, 0, len(files))
+	for p := range files {
+		names = append(names, p)
+	}
+	sort.Strings(names)
+
+	var layouts, partials, pages []string
+	for _, p := range names {
+		switch dir, _, _ := strings.Cut(p, "/"); {
+		case dir == layoutDir && strings.Contains(p, "/"):
+			layouts = append(layouts, p)
+		case dir == partialDir && strings.Contains(p, "/"):
+			partials = append(partials, p)
+		default:
+			pages = append(pages, p)
+		}
+	}
+
+	for _, p := range pages {
+		ext := path.Ext(p)
+		pg := &page{html: isHTML(ext), sets: map[string]*set{}}
+		// 同一格式的片段与布局
+		var parts []string
+		for _, f := range partials {
+			if isHTML(path.Ext(f)) == pg.html {
+				parts = append(parts, f)
+			}
+		}
+		s, err := e.parse(pg.html, "", parts, p, files)
+		if err != nil {
+			return nil, err
+		}
+		pg.sets[""] = s
+		for _, l := range layouts {
+			if path.Ext(l) != ext {
+				continue
+			}
+			s, err := e.parse(pg.html, l, parts, p, files)
+			if err != nil {
+				return nil, err
+			}
+			pg.sets[strings.TrimSuffix(strings.TrimPrefix(l, layoutDir+"/"), ext)] = s
+		}
+		e.pages[p] = pg
+	}
+	return e, nil
+}
+
+func isHTML(ext string) bool {
+	return ext == ".html" || ext == ".htm"
+}
+
+// parse 解析页面集合：布局（可选）作为根模板，页面作为 content，片段按文件名（不含目录与扩展名）命名
+func (e *Engine) parse(html bool, layout string, partials []string, pagePath string, files map[string]string) (*set, error) {
+	funcs := e.funcs(e.cfg.defaultLocale)
+	option := "missingkey=default"
+	if e.cfg.strict {
+		option = "missingkey=error"
+	}
+	var s set
+	add := func(name, src string) error {
+		if html {
+			if s.html == nil {
+				s.html = htmltemplate.New(setRoot).Funcs(funcs).Option(option)
+			}
+			_, err := s.html.New(name).Parse(src)
+			return err
+		}
+		if s.text == nil {
+			s.text = texttemplate.New(setRoot).Funcs(funcs).Option(option)
+		}
+		_, err := s.text.New(name).Parse(src)
+		return err
+	}
+	if layout != "" {
+		if err := add(layoutRoot, files[layout]); err != nil {
+			return nil, fmt.Errorf("templates: parse %s: %w", layout, err)
+		}
+	}
+	for _, p := range partials {
+		name := strings.TrimSuffix(path.Base(p), path.Ext(p))
+		if err := add(name, files[p]); err != nil {
+			return nil, fmt.Errorf("templates: parse %s: %w", p, err)
+		}
+	}
+	if err := add(contentName, files[pagePath]); err != nil {
+		return nil, fmt.Errorf("templates: parse %s: %w", pagePath, err)
+	}
+	return &s, nil
+}
+
+// Has 模板是否存在
+func (e *Engine) Has(name string) bool {
+	_, ok := e.pages[name]
+	return ok
+}
+
+// Names 返回全部页面名（按字典序）
+func (e *Engine) Names() []string {
+	names := make([]string, 0, len(e.pages))
+	for n := range e.pages {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render 渲染页面（名称含扩展名，如 "receipt.html"），出错时不返回部分输出
+func (e *Engine) Render(ctx context.Context, name string, data any, opts ...RenderOption) (string, error) {
+	return e.render(ctx, name, "", data, opts)
+}
+
+// RenderBlock 渲染页面中定义的块（如 "subject"），不使用布局
+func (e *Engine) RenderBlock(ctx context.Context, name, block string, data any, opts ...RenderOption) (string, error) {
+	return e.render(ctx, name, block, data, append(opts, WithLayout("")))
+}
+
+func (e *Engine) render(ctx context.Context, name, block string, data any, opts []RenderOption) (string, error) {
+	var rc renderConfig
+	for _, opt := range opts {
+		opt(&rc)
+	}
+	s, err := e.instance(name, rc)
+	if err != nil {
+		return "", err
+	}
+	root := contentName
+	switch {
+	case block != "":
+		if !s.has(block) {
+			return "", fmt.Errorf("%w: %s block %q", ErrNotFound, name, block)
+		}
+		root = block
+	case s.has(layoutRoot):
+		root = layoutRoot
+	}
+	w := &limitedWriter{ctx: ctx, max: e.cfg.maxOutput}
+	if err := s.execute(w, root, data); err != nil {
+		if w.err != nil {
+			return "", w.err
+		}
+		return "", fmt.Errorf("templates: render %s: %w", name, err)
+	}
+	return w.buf.String(), nil
+}
+
+// instance 返回页面在指定布局与语言下的模板集合（带缓存）
+func (e *Engine) instance(name string, rc renderConfig) (*set, error) {
+	pg, ok := e.pages[name]
+	if !ok || !fs.ValidPath(name) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	layout := e.cfg.defaultLayout
+	if rc.layout != nil {
+		layout = *rc.layout
+	}
+	base, ok := pg.sets[layout]
+	if !ok {
+		if rc.layout != nil && layout != "" {
+			return nil, fmt.Errorf("%w: layout %s%s", ErrNotFound, layout, path.Ext(name))
+		}
+		layout, base = "", pg.sets[""]
+	}
+	locale := e.matchLocale(rc.locale)
+	key := name + "|" + layout + "|" + locale
+	if s, ok := e.cache.Load(key); ok {
+		return s.(*set), nil
+	}
+	s, err := base.clone(e.funcs(locale))
+	if err != nil {
+		return nil, fmt.Errorf("templates: clone %s: %w", name, err)
+	}
+	actual, _ := e.cache.LoadOrStore(key, s)
+	return actual.(*set), nil
+}
+
+// limitedWriter 限制输出大小并在 context 取消后中止渲染
+type limitedWriter struct {
+	ctx context.Context
+	buf bytes.Buffer
+	max int
+	err error
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if err := w.ctx.Err(); err != nil {
+		w.err = err
+		return 0, err
+	}
+	if w.buf.Len()+len(p) > w.max {
+		w.err = ErrOutputTooLarge
+		return 0, w.err
+	}
+	return w.buf.Write(p)
+}
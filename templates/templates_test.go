@@ -0,0 +1,122 @@
+package templates
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func testFS() fstest.MapFS {
+	file := func(s string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(s)} }
+	return fstest.MapFS{
+		"layouts/base.html":    file(`<html><body>{{template "content" .}}{{template "footer" .}}</body></html>`),
+		"layouts/base.txt":     file("{{template \"content\" .}}\n--\n{{t \"footer\"}}"),
+		"partials/footer.html": file(`<p>{{t "footer"}}</p>`),
+		"receipt.html":         file(`{{define "subject"}}{{t "subject" .OrderNo}}{{end}}<h1>{{.Name}}</h1><p>{{number .Total 2}} · {{date .At}}</p>`),
+		"receipt.txt": file(`{{define "subject"}}
+  {{t "subject" .OrderNo}}
+{{end}}Hi {{.Name}}, total {{number .Total 2}}`),
+		"export.csv": file(`{{range .}}{{.}},{{end}}`),
+	}
+}
+
+func testEngine(t *testing.T, opts ...Option) *Engine {
+	t.Helper()
+	e, err := New(testFS(), append([]Option{WithTranslator(MapTranslator{
+		"en": {"subject": "Receipt %s", "footer": "Thanks"},
+		"de": {"subject": "Beleg %s"},
+	})}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+type order struct {
+	OrderNo string
+	Name    string
+	Total   float64
+	At      time.Time
+}
+
+func TestEngine_Render(t *testing.T) {
+	e := testEngine(t)
+	ctx := context.Background()
+	o := order{OrderNo: "A1", Name: "<Ada>", Total: 1234.5, At: time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)}
+
+	got, err := e.Render(ctx, "receipt.html", o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<html><body><h1>&lt;Ada&gt;</h1><p>1,234.50 · Mar 4, 2026</p><p>Thanks</p></body></html>`
+	if got != want {
+		t.Errorf("Render() = %s, want %s", got, want)
+	}
+
+	// 语言匹配（de-AT → de），缺失翻译回退到默认语言
+	got, _ = e.Render(ctx, "receipt.html", o, WithLocale("de-AT"), WithLayout(""))
+	if got != `<h1>&lt;Ada&gt;</h1><p>1.234,50 · 04.03.2026</p>` {
+		t.Errorf("Render(de) = %s", got)
+	}
+
+	got, _ = e.Render(ctx, "export.csv", []int{1, 2})
+	if got != "1,2," {
+		t.Errorf("Render(csv) = %q", got)
+	}
+
+	if _, err := e.Render(ctx, "../secret.html", nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Render(missing) error = %v", err)
+	}
+}
+
+func TestEngine_RenderEmail(t *testing.T) {
+	e := testEngine(t)
+	msg, err := e.RenderEmail(context.Background(), "receipt", order{OrderNo: "A1", Name: "Ada", Total: 5}, WithLocale("de"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Subject != "Beleg A1" || msg.Text != "Hi Ada, total 5,00\n--\nThanks" || !strings.Contains(msg.HTML, "<h1>Ada</h1>") {
+		t.Errorf("RenderEmail() = %+v", msg)
+	}
+}
+
+func TestEngine_Strict(t *testing.T) {
+	ctx := context.Background()
+	fsys := fstest.MapFS{
+		"a.txt": &fstest.MapFile{Data: []byte(`{{.missing}}`)},
+		"b.txt": &fstest.MapFile{Data: []byte(`{{t "nope"}}`)},
+	}
+	strict, _ := New(fsys)
+	if _, err := strict.Render(ctx, "a.txt", map[string]any{}); err == nil {
+		t.Error("strict: missing key should fail")
+	}
+	if _, err := strict.Render(ctx, "b.txt", nil); err == nil {
+		t.Error("strict: missing translation should fail")
+	}
+
+	lenient, _ := New(fsys, WithStrict(false))
+	if got, err := lenient.Render(ctx, "b.txt", nil); err != nil || got != "nope" {
+		t.Errorf("lenient: Render() = %q, %v", got, err)
+	}
+}
+
+func TestEngine_Limits(t *testing.T) {
+	fsys := fstest.MapFS{"big.txt": &fstest.MapFile{Data: []byte(`{{range .}}xxxxxxxxxx{{end}}`)}}
+	e, _ := New(fsys, WithMaxOutput(50))
+	if _, err := e.Render(context.Background(), "big.txt", make([]int, 10)); !errors.Is(err, ErrOutputTooLarge) {
+		t.Errorf("error = %v, want ErrOutputTooLarge", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := e.Render(ctx, "big.txt", make([]int, 1)); !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want context.Canceled", err)
+	}
+
+	if _, err := New(fstest.MapFS{"x.txt": &fstest.MapFile{Data: []byte(`{{exec "rm"}}`)}}); err == nil {
+		t.Error("unknown function should fail to parse")
+	}
+}
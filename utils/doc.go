@@ -3,8 +3,8 @@
 // 核心功能：
 //   - 字符串、切片、Map 操作
 //   - 时间、数字处理
-//   - 指针辅助、异步工具
-//   - ID 生成（UUID/Snowflake）
+//   - 指针辅助、异步工具、并发任务组（Group）
+//   - ID 生成（UUID/Snowflake/ULID），带前缀的类型化 ID（ID[P]，如 "ord_01HV..."，支持 JSON 与 GORM）
 //
 // 使用示例：
 //
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"golang.org/x/sync/errgroup"
+)
+
+const tracerName = "github.com/mildsunup/higo/utils"
+
+// Result 任务结果
+type Result[T any] struct {
+	Label string
+	Value T
+	Err   error
+}
+
+// Group 并发任务组
+// 基于 errgroup，支持并发上限、panic 转错误、按 label 创建 Span，并收集每个任务的结果。
+type Group[T any] struct {
+	g       *errgroup.Group
+	ctx     context.Context
+	mu      sync.Mutex
+	results []Result[T]
+}
+
+// GroupOption 任务组选项
+type GroupOption func(*errgroup.Group)
+
+// WithLimit 设置最大并发数
+func WithLimit(n int) GroupOption {
+	return func(g *errgroup.Group) {
+		g.SetLimit(n)
+	}
+}
+
+// NewGroup 创建任务组，返回的 ctx 在首个任务失败时取消
+func NewGroup[T any](ctx context.Context, opts ...GroupOption) (*Group[T], context.Context) {
+	g, gctx := errgroup.WithContext(ctx)
+	for _, opt := range opts {
+		opt(g)
+	}
+	return &Group[T]{g: g, ctx: gctx}, gctx
+}
+
+// Go 提交任务，达到并发上限时阻塞
+func (g *Group[T]) Go(label string, fn func(ctx context.Context) (T, error)) {
+	g.mu.Lock()
+	idx := len(g.results)
+	g.results = append(g.results, Result[T]{Label: label})
+	g.mu.Unlock()
+
+	g.g.Go(func() error {
+		value, err := g.run(label, fn)
+
+		g.mu.Lock()
+		g.results[idx].Value = value
+		g.results[idx].Err = err
+		g.mu.Unlock()
+
+		return err
+	})
+}
+
+func (g *Group[T]) run(label string, fn func(ctx context.Context) (T, error)) (value T, err error) {
+	ctx, span := otel.Tracer(tracerName).Start(g.ctx, label)
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("utils: task %s panic: %v\n%s", label, r, debug.Stack())
+		}
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	return fn(ctx)
+}
+
+// Wait 等待所有任务完成，返回按提交顺序排列的结果和首个错误
+// 失败任务的结果同样保留，便于使用部分成功的数据。
+func (g *Group[T]) Wait() ([]Result[T], error) {
+	err := g.g.Wait()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	results := make([]Result[T], len(g.results))
+	copy(results, g.results)
+	return results, err
+}
+
+// SuccessValues 提取成功任务的值
+func SuccessValues[T any](results []Result[T]) []T {
+	values := make([]T, 0, len(results))
+	for _, r := range results {
+		if r.Err == nil {
+			values = append(values, r.Value)
+		}
+	}
+	return values
+}
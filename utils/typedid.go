@@ -0,0 +1,196 @@
+package utils
+
+import (
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// --- ULID ---
+
+// crockford Crockford Base32 字母表（去掉 I、L、O、U）
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+const ulidLen = 26
+
+// ULID 生成 ULID：48 位毫秒时间戳 + 80 位随机数，Crockford Base32 编码为 26 个字符，按时间字典序有序
+func ULID() string {
+	return ulidAt(time.Now())
+}
+
+func ulidAt(t time.Time) string {
+	var b [16]byte
+	ms := uint64(t.UnixMilli())
+	binary.BigEndian.PutUint16(b[0:2], uint16(ms>>32))
+	binary.BigEndian.PutUint32(b[2:6], uint32(ms))
+	_, _ = rand.Read(b[6:])
+
+	// 128 位按 5 位一组编码，首字符仅 3 位
+	hi := binary.BigEndian.Uint64(b[0:8])
+	lo := binary.BigEndian.Uint64(b[8:16])
+	var out [ulidLen]byte
+	for i := ulidLen - 1; i >= 0; i-- {
+		out[i] = crockford[lo&0x1F]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// ulidTime 解析 ULID 中的时间戳，body 需已校验
+func ulidTime(body string) time.Time {
+	var ms uint64
+	for i := range 10 {
+		ms = ms<<5 | uint64(strings.IndexByte(crockford, body[i]))
+	}
+	return time.UnixMilli(int64(ms))
+}
+
+func validULID(body string) bool {
+	if len(body) != ulidLen || body[0] > '7' {
+		return false
+	}
+	for i := range len(body) {
+		if strings.IndexByte(crockford, body[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// --- 带前缀的类型化 ID ---
+
+// ErrInvalidID ID 格式无效
+var ErrInvalidID = errors.New("utils: invalid id")
+
+// IDPrefixer 类型化 ID 的种类标记，返回小写字母与数字组成的前缀
+type IDPrefixer interface {
+	IDPrefix() string
+}
+
+// ID 带前缀的类型化 ID（如 "ord_01HV6Z3K8Q5N7W2XJ4RD9T0BME"），格式为 前缀_ULID。
+// 作为值对象使用：不同种类的 ID 类型不同、不可互相赋值；零值表示未设置。
+// 支持 JSON/文本编解码（解码时校验前缀与格式）与 GORM/database/sql 读写（存为字符串）。
+//
+//	type orderKind struct{}
+//	func (orderKind) IDPrefix() string { return "ord" }
+//	type OrderID = utils.ID[orderKind]
+//
+//	id := utils.NewID[orderKind]()            // ord_01HV...
+//	id, err := utils.ParseID[orderKind](raw)  // 前缀不符或格式错误时返回 ErrInvalidID
+type ID[P IDPrefixer] struct {
+	s string
+}
+
+// NewID 生成新的类型化 ID
+func NewID[P IDPrefixer]() ID[P] {
+	return ID[P]{s: idPrefix[P]() + "_" + ULID()}
+}
+
+// ParseID 解析并校验类型化 ID，ULID 部分大小写不敏感
+func ParseID[P IDPrefixer](s string) (ID[P], error) {
+	prefix := idPrefix[P]()
+	body, ok := strings.CutPrefix(s, prefix+"_")
+	if !ok {
+		return ID[P]{}, fmt.Errorf("%w: %q: expected prefix %q", ErrInvalidID, s, prefix)
+	}
+	body = strings.ToUpper(body)
+	if !validULID(body) {
+		return ID[P]{}, fmt.Errorf("%w: %q: malformed body", ErrInvalidID, s)
+	}
+	return ID[P]{s: prefix + "_" + body}, nil
+}
+
+// MustParseID 解析类型化 ID，失败时 panic
+func MustParseID[P IDPrefixer](s string) ID[P] {
+	id, err := ParseID[P](s)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+func idPrefix[P IDPrefixer]() string {
+	var p P
+	return p.IDPrefix()
+}
+
+// String 返回完整 ID
+func (id ID[P]) String() string { return id.s }
+
+// Prefix 返回种类前缀
+func (id ID[P]) Prefix() string { return idPrefix[P]() }
+
+// IsZero 是否未设置
+func (id ID[P]) IsZero() bool { return id.s == "" }
+
+// Equal 判断是否相等
+func (id ID[P]) Equal(other ID[P]) bool { return id.s == other.s }
+
+// Time 返回生成时间（毫秒精度），零值返回零时间
+func (id ID[P]) Time() time.Time {
+	if id.s == "" {
+		return time.Time{}
+	}
+	return ulidTime(id.s[len(id.s)-ulidLen:])
+}
+
+// Validate 校验 ID（满足 ddd.Validatable），零值视为无效
+func (id ID[P]) Validate() error {
+	_, err := ParseID[P](id.s)
+	return err
+}
+
+// MarshalText 实现 encoding.TextMarshaler（JSON 编码为字符串）
+func (id ID[P]) MarshalText() ([]byte, error) {
+	return []byte(id.s), nil
+}
+
+// UnmarshalText 实现 encoding.TextUnmarshaler，空字符串解码为零值
+func (id *ID[P]) UnmarshalText(b []byte) error {
+	if len(b) == 0 {
+		*id = ID[P]{}
+		return nil
+	}
+	parsed, err := ParseID[P](string(b))
+	if err != nil {
+		return err
+	}
+	*id = parsed
+	return nil
+}
+
+// Value 实现 driver.Valuer，零值存为 NULL
+func (id ID[P]) Value() (driver.Value, error) {
+	if id.s == "" {
+		return nil, nil
+	}
+	return id.s, nil
+}
+
+// Scan 实现 sql.Scanner
+func (id *ID[P]) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*id = ID[P]{}
+		return nil
+	case string:
+		return id.UnmarshalText([]byte(v))
+	case []byte:
+		return id.UnmarshalText(v)
+	default:
+		return fmt.Errorf("%w: cannot scan %T", ErrInvalidID, src)
+	}
+}
+
+// GormDataType GORM 列类型
+func (ID[P]) GormDataType() string { return "string" }
+
+// PrefixedID 生成不绑定类型的带前缀 ID（前缀_ULID）
+func PrefixedID(prefix string) string {
+	return prefix + "_" + ULID()
+}
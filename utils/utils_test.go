@@ -2,7 +2,9 @@ package utils
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 )
@@ -269,6 +271,24 @@ func TestParallel(t *testing.T) {
 	}
 }
 
+func TestGroup(t *testing.T) {
+	g, _ := NewGroup[int](context.Background(), WithLimit(2))
+	g.Go("one", func(ctx context.Context) (int, error) { return 1, nil })
+	g.Go("panic", func(ctx context.Context) (int, error) { panic("boom") })
+	g.Go("three", func(ctx context.Context) (int, error) { return 3, nil })
+
+	results, err := g.Wait()
+	if err == nil {
+		t.Fatal("expected panic error")
+	}
+	if len(results) != 3 || results[1].Err == nil {
+		t.Fatalf("results = %+v", results)
+	}
+	if values := SuccessValues(results); len(values) != 2 {
+		t.Errorf("SuccessValues = %v, want 2 values", values)
+	}
+}
+
 // --- ID Tests ---
 
 func TestUUID(t *testing.T) {
@@ -299,3 +319,69 @@ func TestNanoID(t *testing.T) {
 		t.Errorf("NanoID length = %d, want 21", len(id))
 	}
 }
+
+type orderKind struct{}
+
+func (orderKind) IDPrefix() string { return "ord" }
+
+type userKind struct{}
+
+func (userKind) IDPrefix() string { return "usr" }
+
+func TestTypedID(t *testing.T) {
+	before := time.Now().Truncate(time.Millisecond)
+	id := NewID[orderKind]()
+	if !strings.HasPrefix(id.String(), "ord_") || len(id.String()) != 4+26 || id.Validate() != nil {
+		t.Fatalf("NewID() = %q", id)
+	}
+	if ts := id.Time(); ts.Before(before) || ts.After(time.Now()) {
+		t.Errorf("Time() = %v", ts)
+	}
+
+	parsed, err := ParseID[orderKind](strings.ToLower(id.String()))
+	if err != nil || !parsed.Equal(id) {
+		t.Errorf("ParseID(lower) = %v, %v", parsed, err)
+	}
+	for _, raw := range []string{"", id.String()[4:], "usr_" + id.String()[4:], "ord_" + "8" + id.String()[5:], "ord_01HV"} {
+		if _, err := ParseID[orderKind](raw); !errors.Is(err, ErrInvalidID) {
+			t.Errorf("ParseID(%q) error = %v", raw, err)
+		}
+	}
+	if _, err := ParseID[userKind](id.String()); err == nil {
+		t.Error("ParseID with wrong kind should fail")
+	}
+}
+
+func TestTypedID_Encoding(t *testing.T) {
+	type order struct {
+		ID    ID[orderKind] `json:"id"`
+		Buyer ID[userKind]  `json:"buyer"`
+	}
+	in := order{ID: NewID[orderKind]()}
+	b, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"id":"` + in.ID.String() + `","buyer":""}`; string(b) != want {
+		t.Errorf("Marshal() = %s, want %s", b, want)
+	}
+	var out order
+	if err := json.Unmarshal(b, &out); err != nil || out != in {
+		t.Errorf("Unmarshal() = %+v, %v", out, err)
+	}
+	if err := json.Unmarshal([]byte(`{"id":"usr_01HV6Z3K8Q5N7W2XJ4RD9T0BME"}`), &out); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("Unmarshal(wrong prefix) error = %v", err)
+	}
+
+	v, _ := in.ID.Value()
+	var scanned ID[orderKind]
+	if err := scanned.Scan([]byte(v.(string))); err != nil || scanned != in.ID {
+		t.Errorf("Scan() = %v, %v", scanned, err)
+	}
+	if v, _ := in.Buyer.Value(); v != nil {
+		t.Errorf("zero Value() = %v, want nil", v)
+	}
+	if err := scanned.Scan(nil); err != nil || !scanned.IsZero() {
+		t.Errorf("Scan(nil) = %v, %v", scanned, err)
+	}
+}